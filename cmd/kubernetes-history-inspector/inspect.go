@@ -0,0 +1,233 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/model/enum"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/history"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/history/reader"
+)
+
+// inspectCommandName is the first argument to run KHI as the .khi file inspection command instead of the server or the job mode.
+const inspectCommandName = "inspect"
+
+type inspectSummary struct {
+	Version       string `json:"version"`
+	LogCount      int    `json:"logCount"`
+	TimelineCount int    `json:"timelineCount"`
+	ResourceCount int    `json:"resourceCount"`
+	ChunkCount    int    `json:"chunkCount"`
+}
+
+type inspectResource struct {
+	Path          string `json:"path"`
+	Name          string `json:"name"`
+	Depth         int    `json:"depth"`
+	Relationship  string `json:"relationship"`
+	Timeline      string `json:"timeline"`
+	RevisionCount int    `json:"revisionCount"`
+	EventCount    int    `json:"eventCount"`
+}
+
+type inspectTimeline struct {
+	ID            string `json:"id"`
+	RevisionCount int    `json:"revisionCount"`
+	EventCount    int    `json:"eventCount"`
+}
+
+type inspectLog struct {
+	ID        string    `json:"id"`
+	Timestamp time.Time `json:"timestamp"`
+	Type      string    `json:"type"`
+	Severity  string    `json:"severity"`
+	Summary   string    `json:"summary"`
+	Body      string    `json:"body,omitempty"`
+}
+
+type inspectOutput struct {
+	Summary   *inspectSummary    `json:"summary"`
+	Resources []*inspectResource `json:"resources,omitempty"`
+	Timelines []*inspectTimeline `json:"timelines,omitempty"`
+	Logs      []*inspectLog      `json:"logs,omitempty"`
+}
+
+// runInspectCommand reads a .khi file and prints the timelines, resources and logs contained in it.
+func runInspectCommand(args []string, stdout io.Writer, stderr io.Writer) int {
+	flagSet := flag.NewFlagSet(inspectCommandName, flag.ContinueOnError)
+	flagSet.SetOutput(stderr)
+	showResources := flagSet.Bool("resources", false, "List the resources in the resource tree.")
+	showTimelines := flagSet.Bool("timelines", false, "List the timelines.")
+	showLogs := flagSet.Bool("logs", false, "List the logs.")
+	includeBody := flagSet.Bool("include-body", false, "Include the log bodies in the log list.")
+	outputFormat := flagSet.String("output", "text", "Output format. `text` or `json`.")
+	flagSet.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: %s %s [flags] <file.khi>\n\nLists the timelines, resources and logs contained in a .khi file. All of them are listed when no list flag is given.\n\n", os.Args[0], inspectCommandName)
+		flagSet.PrintDefaults()
+	}
+	if err := flagSet.Parse(args); err != nil {
+		return 2
+	}
+	if flagSet.NArg() != 1 {
+		flagSet.Usage()
+		return 2
+	}
+	if *outputFormat != "text" && *outputFormat != "json" {
+		fmt.Fprintf(stderr, "unsupported output format %q\n", *outputFormat)
+		return 2
+	}
+	if !*showResources && !*showTimelines && !*showLogs {
+		*showResources = true
+		*showTimelines = true
+		*showLogs = true
+	}
+
+	khiFile, err := reader.Open(flagSet.Arg(0))
+	if err != nil {
+		fmt.Fprintf(stderr, "failed to open %s\n%v\n", flagSet.Arg(0), err)
+		return 1
+	}
+	defer khiFile.Close()
+
+	output, err := buildInspectOutput(khiFile, *showResources, *showTimelines, *showLogs, *includeBody)
+	if err != nil {
+		fmt.Fprintf(stderr, "failed to read %s\n%v\n", flagSet.Arg(0), err)
+		return 1
+	}
+
+	if *outputFormat == "json" {
+		encoder := json.NewEncoder(stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(output); err != nil {
+			fmt.Fprintf(stderr, "failed to write the output\n%v\n", err)
+			return 1
+		}
+		return 0
+	}
+	if err := writeInspectOutputAsText(stdout, output); err != nil {
+		fmt.Fprintf(stderr, "failed to write the output\n%v\n", err)
+		return 1
+	}
+	return 0
+}
+
+func buildInspectOutput(khiFile *reader.Reader, showResources, showTimelines, showLogs, includeBody bool) (*inspectOutput, error) {
+	h := khiFile.History
+	output := &inspectOutput{
+		Summary: &inspectSummary{
+			Version:       h.Version,
+			LogCount:      len(h.Logs),
+			TimelineCount: len(h.Timelines),
+			ChunkCount:    khiFile.ChunkCount(),
+		},
+	}
+	resources := []*inspectResource{}
+	err := khiFile.WalkResources(func(resource *history.Resource, depth int) error {
+		r := &inspectResource{
+			Path:         resource.FullResourcePath,
+			Name:         resource.ResourceName,
+			Depth:        depth,
+			Relationship: enum.ParentRelationships[resource.Relationship].EnumKeyName,
+			Timeline:     resource.Timeline,
+		}
+		if timeline := khiFile.Timeline(resource.Timeline); timeline != nil {
+			r.RevisionCount = len(timeline.Revisions)
+			r.EventCount = len(timeline.Events)
+		}
+		resources = append(resources, r)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	output.Summary.ResourceCount = len(resources)
+	if showResources {
+		output.Resources = resources
+	}
+	if showTimelines {
+		output.Timelines = []*inspectTimeline{}
+		for _, timeline := range h.Timelines {
+			output.Timelines = append(output.Timelines, &inspectTimeline{
+				ID:            timeline.ID,
+				RevisionCount: len(timeline.Revisions),
+				EventCount:    len(timeline.Events),
+			})
+		}
+	}
+	if showLogs {
+		output.Logs = []*inspectLog{}
+		for _, l := range h.Logs {
+			summary, err := khiFile.ReadString(l.Summary)
+			if err != nil {
+				return nil, err
+			}
+			il := &inspectLog{
+				ID:        l.ID,
+				Timestamp: l.Timestamp,
+				Type:      enum.LogTypes[l.Type].Label,
+				Severity:  enum.Severities[l.Severity].Label,
+				Summary:   summary,
+			}
+			if includeBody {
+				body, err := khiFile.ReadString(l.Body)
+				if err != nil {
+					return nil, err
+				}
+				il.Body = body
+			}
+			output.Logs = append(output.Logs, il)
+		}
+	}
+	return output, nil
+}
+
+func writeInspectOutputAsText(writer io.Writer, output *inspectOutput) error {
+	w := tabwriter.NewWriter(writer, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(w, "Version:\t%s\n", output.Summary.Version)
+	fmt.Fprintf(w, "Logs:\t%d\n", output.Summary.LogCount)
+	fmt.Fprintf(w, "Timelines:\t%d\n", output.Summary.TimelineCount)
+	fmt.Fprintf(w, "Resources:\t%d\n", output.Summary.ResourceCount)
+	fmt.Fprintf(w, "Binary chunks:\t%d\n", output.Summary.ChunkCount)
+	if output.Resources != nil {
+		fmt.Fprintf(w, "\nRESOURCE\tRELATIONSHIP\tTIMELINE\tREVISIONS\tEVENTS\n")
+		for _, r := range output.Resources {
+			fmt.Fprintf(w, "%s%s\t%s\t%s\t%d\t%d\n", strings.Repeat("  ", r.Depth), r.Name, r.Relationship, r.Timeline, r.RevisionCount, r.EventCount)
+		}
+	}
+	if output.Timelines != nil {
+		fmt.Fprintf(w, "\nTIMELINE\tREVISIONS\tEVENTS\n")
+		for _, t := range output.Timelines {
+			fmt.Fprintf(w, "%s\t%d\t%d\n", t.ID, t.RevisionCount, t.EventCount)
+		}
+	}
+	if output.Logs != nil {
+		fmt.Fprintf(w, "\nTIMESTAMP\tTYPE\tSEVERITY\tID\tSUMMARY\n")
+		for _, l := range output.Logs {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", l.Timestamp.Format(time.RFC3339Nano), l.Type, l.Severity, l.ID, l.Summary)
+			if l.Body != "" {
+				fmt.Fprintf(w, "%s\n", l.Body)
+			}
+		}
+	}
+	return w.Flush()
+}
@@ -0,0 +1,100 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"time"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/inspection"
+	"github.com/GoogleCloudPlatform/khi/pkg/inspection/jobspec"
+	inspection_task "github.com/GoogleCloudPlatform/khi/pkg/inspection/task"
+)
+
+// runJobSpec runs the inspection declared in the job spec file and writes its outputs.
+// The validation report of the form values is written to stdout before running. Returns the exit code.
+func runJobSpec(ctx context.Context, inspectionServer *inspection.InspectionTaskServer, specPath string, stdout io.Writer) int {
+	spec, err := jobspec.Load(specPath)
+	if err != nil {
+		slog.Error(fmt.Sprintf("Failed to load the job spec\n%v", err))
+		return 1
+	}
+	values, err := jobspec.ResolveValues(spec.Values, time.Now())
+	if err != nil {
+		slog.Error(err.Error())
+		return 1
+	}
+	inspectionID, err := inspectionServer.CreateInspection(spec.InspectionType)
+	if err != nil {
+		slog.Error(fmt.Sprintf("Failed to create an inspection with type %s\n%v", spec.InspectionType, err))
+		return 1
+	}
+	runner := inspectionServer.GetInspection(inspectionID)
+	availableFeatures, err := runner.FeatureList()
+	if err != nil {
+		slog.Error(fmt.Sprintf("Failed to obtain current feature list\n%v", err))
+		return 1
+	}
+	features, err := spec.SelectFeatures(availableFeatures)
+	if err != nil {
+		slog.Error(err.Error())
+		return 1
+	}
+	err = runner.SetFeatureList(features)
+	if err != nil {
+		slog.Error(fmt.Sprintf("Failed to set features %v\n%v", features, err))
+		return 1
+	}
+
+	report, err := jobspec.Validate(ctx, runner, values)
+	if err != nil {
+		slog.Error(err.Error())
+		return 1
+	}
+	fmt.Fprintf(stdout, "Validation report of %s (features: %v)\n", specPath, features)
+	if err := report.WriteText(stdout); err != nil {
+		slog.Error(err.Error())
+		return 1
+	}
+	if report.HasError() {
+		slog.Error("The job spec has invalid values. Fix the fields with the error result in the validation report.")
+		return 1
+	}
+
+	err = runner.Run(ctx, &inspection_task.InspectionRequest{
+		Values: values,
+	})
+	if err != nil {
+		slog.Error(fmt.Sprintf("Failed to run inspection task \n%v", err))
+		return 1
+	}
+	<-runner.Wait()
+	result, err := runner.Result()
+	if err != nil {
+		slog.Error(fmt.Sprintf("Failed to get inspection result \n%v", err))
+		return 1
+	}
+	for _, output := range spec.Outputs {
+		if err := output.Write(result.ResultStore); err != nil {
+			slog.Error(err.Error())
+			return 1
+		}
+		slog.Info(fmt.Sprintf("Wrote the %s output to %s", output.Format, output.Path))
+	}
+	return 0
+}
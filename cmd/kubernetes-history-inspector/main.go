@@ -22,26 +22,36 @@ import (
 	"log/slog"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"strings"
 	"syscall"
 
+	"github.com/GoogleCloudPlatform/khi/pkg/common/cache"
 	"github.com/GoogleCloudPlatform/khi/pkg/common/errorreport"
 	"github.com/GoogleCloudPlatform/khi/pkg/common/flag"
 	"github.com/GoogleCloudPlatform/khi/pkg/inspection"
+	"github.com/GoogleCloudPlatform/khi/pkg/inspection/budget"
 	inspection_common "github.com/GoogleCloudPlatform/khi/pkg/inspection/common"
 	"github.com/GoogleCloudPlatform/khi/pkg/inspection/logger"
+	"github.com/GoogleCloudPlatform/khi/pkg/inspection/registry"
+	"github.com/GoogleCloudPlatform/khi/pkg/inspection/retention"
+	"github.com/GoogleCloudPlatform/khi/pkg/inspection/scheduler"
 	inspection_task "github.com/GoogleCloudPlatform/khi/pkg/inspection/task"
 	"github.com/GoogleCloudPlatform/khi/pkg/lifecycle"
 	"github.com/GoogleCloudPlatform/khi/pkg/model/k8s"
 	"github.com/GoogleCloudPlatform/khi/pkg/parameters"
 	"github.com/GoogleCloudPlatform/khi/pkg/server"
+	"github.com/GoogleCloudPlatform/khi/pkg/server/auth"
 	"github.com/GoogleCloudPlatform/khi/pkg/server/upload"
 	common "github.com/GoogleCloudPlatform/khi/pkg/source/common/k8s_audit"
 	"github.com/GoogleCloudPlatform/khi/pkg/source/gcp"
 	"github.com/GoogleCloudPlatform/khi/pkg/source/gcp/api"
 	"github.com/GoogleCloudPlatform/khi/pkg/source/gcp/api/accesstoken"
 	"github.com/GoogleCloudPlatform/khi/pkg/source/gcp/api/quotaproject"
+	"github.com/GoogleCloudPlatform/khi/pkg/source/gcp/query"
+	"github.com/GoogleCloudPlatform/khi/pkg/source/gcp/query/querycache"
 	"github.com/GoogleCloudPlatform/khi/pkg/source/oss"
+	"github.com/GoogleCloudPlatform/khi/pkg/tracing"
 
 	"cloud.google.com/go/profiler"
 )
@@ -83,6 +93,13 @@ func init() {
 	parameters.AddStore(parameters.Job)
 	parameters.AddStore(parameters.Auth)
 	parameters.AddStore(parameters.Debug)
+	parameters.AddStore(parameters.Retention)
+	parameters.AddStore(parameters.Endpoint)
+	parameters.AddStore(parameters.QueryCache)
+	parameters.AddStore(parameters.Budget)
+	parameters.AddStore(parameters.Scheduler)
+	parameters.AddStore(parameters.ServerAuth)
+	parameters.AddStore(parameters.Tracing)
 
 	taskSetRegistrer = append(taskSetRegistrer, inspection_common.PrepareInspectionServer)
 	taskSetRegistrer = append(taskSetRegistrer, gcp.PrepareInspectionServer)
@@ -107,6 +124,15 @@ func main() {
 
 func run() int {
 	defer errorreport.CheckAndReportPanic()
+	if len(os.Args) > 1 && os.Args[1] == inspectCommandName {
+		return runInspectCommand(os.Args[2:], os.Stdout, os.Stderr)
+	}
+	if len(os.Args) > 1 && os.Args[1] == mergeCommandName {
+		return runMergeCommand(os.Args[2:], os.Stdout, os.Stderr)
+	}
+	if len(os.Args) > 1 && os.Args[1] == diffCommandName {
+		return runDiffCommand(os.Args[2:], os.Stdout, os.Stderr)
+	}
 	logger.InitGlobalKHILogger()
 	err := parameters.Parse()
 	if err != nil {
@@ -127,13 +153,44 @@ func run() int {
 		}
 		slog.Info("Cloud Profiler is enabled")
 	}
-	lifecycle.Default.NotifyInit()
+	shutdownTracing, err := tracing.Setup(context.Background(), &tracing.Config{
+		OTLPEndpoint: *parameters.Tracing.OTLPEndpoint,
+		FilePath:     *parameters.Tracing.TraceFile,
+	})
+	if err != nil {
+		slog.Error(fmt.Sprintf("Failed to set up tracing\n%s", err.Error()))
+		return 1
+	}
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			slog.Warn(fmt.Sprintf("Failed to flush traces\n%s", err.Error()))
+		}
+	}()
 	slog.Info("Initializing Kubernetes History Inspector...")
 
 	k8s.GenerateDefaultMergeConfig()
+	api.DefaultGCPClientFactory.Endpoints = api.NewEndpointsFromParameters(parameters.Endpoint)
 	if *parameters.Auth.QuotaProjectID != "" {
 		api.DefaultGCPClientFactory.RegisterHeaderProvider(quotaproject.NewHeaderProvider(*parameters.Auth.QuotaProjectID))
 	}
+	if parameters.QueryCache.Enabled() {
+		queryCacheFolder := *parameters.QueryCache.Folder
+		if queryCacheFolder == "" {
+			queryCacheFolder = filepath.Join(*parameters.Common.DataDestinationFolder, "query-cache")
+		}
+		storage, err := cache.NewFileSystemCacheItemStorageProvider(filepath.Join(queryCacheFolder, querycache.FormatVersion), int64(*parameters.QueryCache.MaxBytes))
+		if err != nil {
+			slog.Error(fmt.Sprintf("Failed to initialize the query cache. Logs are always queried from Cloud Logging.\n%v", err))
+		} else {
+			query.DefaultQueryResultCache = querycache.NewQueryResultCache(cache.NewGZipCacheItemStorageProvider(storage))
+		}
+	}
+	budget.Default = &budget.Budget{
+		MaxLogsPerQuery: *parameters.Budget.MaxLogsPerQuery,
+		LogCountAction:  budget.LogCountAction(*parameters.Budget.LogCountAction),
+		MaxBinaryBytes:  *parameters.Budget.MaxBinaryBytes,
+		MaxWallTime:     *parameters.Budget.MaxWallTime,
+	}
 	inspectionServer, err := inspection.NewServer()
 	if err != nil {
 		slog.Error(fmt.Sprintf("Failed to construct the inspection server due to unexpected error\n%v", err))
@@ -146,6 +203,18 @@ func run() int {
 				slog.Error(fmt.Sprintf("Failed to call initialize calls for taskSetRegistrer(#%d)\n%v", i, err))
 			}
 		}
+		if !*parameters.Job.JobMode {
+			inspectionRegistry, err := registry.NewFileSystemInspectionRegistry(filepath.Join(*parameters.Common.DataDestinationFolder, "registry"))
+			if err != nil {
+				slog.Error(fmt.Sprintf("Failed to initialize the inspection registry. Completed inspections won't be restored after restarting.\n%v", err))
+			} else {
+				inspectionServer.SetRegistry(inspectionRegistry)
+				err = inspectionServer.RestoreInspections()
+				if err != nil {
+					slog.Error(fmt.Sprintf("Failed to restore inspections from the registry\n%v", err))
+				}
+			}
+		}
 	}
 
 	// Channel to receive exit codes from concurrent goroutines
@@ -171,6 +240,41 @@ func run() int {
 			ResourceMonitor:  &server.ResourceMonitorImpl{},
 			ServerBasePath:   *parameters.Server.BasePath,
 			UploadFileStore:  upload.DefaultUploadFileStore,
+			AuthAdmins:       parameters.ServerAuth.AdminList(),
+			APIOnly:          parameters.ServerAuth.APIOnly(),
+			MetricsPublic:    *parameters.ServerAuth.MetricsPublic,
+		}
+		if config.APIOnly {
+			slog.Warn(fmt.Sprintf("The web UI is not served because --server-auth-mode %s requires bearer tokens the web UI can't send. Use --server-auth-mode header behind an authenticating proxy to use the web UI with authentication.", *parameters.ServerAuth.Mode))
+		}
+		authenticator, err := auth.NewAuthenticatorFromParameters()
+		if err != nil {
+			slog.Error(fmt.Sprintf("Failed to configure the server authentication\n%v", err))
+			return 1
+		}
+		config.Authenticator = authenticator
+
+		if !*parameters.Server.ViewerMode {
+			config.RetentionCollector = retention.NewCollector(retention.Policy{
+				MaxAge:             *parameters.Retention.MaxAge,
+				MaxTotalBytes:      int64(*parameters.Retention.MaxTotalBytes),
+				MaxInspectionCount: *parameters.Retention.MaxInspectionCount,
+			}, inspectionServer, *parameters.Common.DataDestinationFolder, upload.DefaultUploadFileStore, *parameters.Retention.Interval)
+			config.RetentionCollector.RegisterLifecycleHandler(lifecycle.Default)
+			if parameters.Scheduler.Enabled() {
+				specs, err := scheduler.LoadJobSpecs(*parameters.Scheduler.ScheduleFile)
+				if err != nil {
+					slog.Error(fmt.Sprintf("Failed to load the schedule file\n%v", err))
+					return 1
+				}
+				config.Scheduler, err = scheduler.NewScheduler(specs, inspectionServer, filepath.Join(*parameters.Common.DataDestinationFolder, "scheduler"))
+				if err != nil {
+					slog.Error(fmt.Sprintf("Failed to initialize the inspection scheduler\n%v", err))
+					return 1
+				}
+				config.Scheduler.RegisterLifecycleHandler(lifecycle.Default)
+				slog.Info(fmt.Sprintf("Scheduled %d inspection jobs", len(specs)))
+			}
 		}
 		engine := server.CreateKHIServer(inspectionServer, &config)
 
@@ -194,6 +298,12 @@ func run() int {
 		}()
 
 		displayStartMessage(*parameters.Server.Host, *parameters.Server.Port)
+	} else if *parameters.Job.Spec != "" {
+		slog.Info(fmt.Sprintf("Starting Kubernetes History Inspector as job mode with the job spec %s...", *parameters.Job.Spec))
+
+		go func() {
+			exitCh <- runJobSpec(context.Background(), inspectionServer, *parameters.Job.Spec, os.Stdout)
+		}()
 	} else {
 		slog.Info("Starting Kubernetes History Inspector as job mode...")
 
@@ -273,6 +383,9 @@ func run() int {
 		}()
 	}
 
+	// Notify after every lifecycle handler is registered. The retention collector starts collecting on this event.
+	lifecycle.Default.NotifyInit()
+
 	// Wait for exit code from any source
 	code := <-exitCh
 	return code
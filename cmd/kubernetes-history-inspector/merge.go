@@ -0,0 +1,165 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/model/enum"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/history/merge"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/history/reader"
+)
+
+// mergeCommandName is the first argument to run KHI as the command merging .khi files.
+const mergeCommandName = "merge"
+
+// diffCommandName is the first argument to run KHI as the command comparing revisions in 2 .khi files.
+const diffCommandName = "diff"
+
+// runMergeCommand merges the given .khi files into a .khi file.
+func runMergeCommand(args []string, stdout io.Writer, stderr io.Writer) int {
+	flagSet := flag.NewFlagSet(mergeCommandName, flag.ContinueOnError)
+	flagSet.SetOutput(stderr)
+	outputPath := flagSet.String("o", "", "Path of the merged .khi file.")
+	temporaryFolder := flagSet.String("temporary-folder", os.TempDir(), "Folder to store the temporary files used while merging.")
+	flagSet.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: %s %s -o <merged.khi> [flags] <file.khi> <file.khi>...\n\nMerges the logs, resources and timelines in .khi files into a .khi file. Logs with the same ID are deduplicated.\n\n", os.Args[0], mergeCommandName)
+		flagSet.PrintDefaults()
+	}
+	if err := flagSet.Parse(args); err != nil {
+		return 2
+	}
+	if *outputPath == "" || flagSet.NArg() < 2 {
+		flagSet.Usage()
+		return 2
+	}
+
+	sources := []*reader.Reader{}
+	defer func() {
+		for _, source := range sources {
+			source.Close()
+		}
+	}()
+	for _, path := range flagSet.Args() {
+		source, err := reader.Open(path)
+		if err != nil {
+			fmt.Fprintf(stderr, "failed to open %s\n%v\n", path, err)
+			return 1
+		}
+		sources = append(sources, source)
+	}
+
+	outputFile, err := os.Create(*outputPath)
+	if err != nil {
+		fmt.Fprintf(stderr, "failed to create %s\n%v\n", *outputPath, err)
+		return 1
+	}
+	result, err := merge.Merge(context.Background(), sources, *temporaryFolder, outputFile)
+	if closeErr := outputFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(*outputPath)
+		fmt.Fprintf(stderr, "failed to merge .khi files\n%v\n", err)
+		return 1
+	}
+	fmt.Fprintf(stdout, "Merged %d files into %s (%d logs, %d duplicated logs dropped, %d bytes)\n", len(sources), *outputPath, result.LogCount, result.DuplicatedLogCount, result.FileSize)
+	return 0
+}
+
+// runDiffCommand prints the resources whose revisions differ between the base and the target .khi files.
+// It returns 1 when any difference was found like the diff command.
+func runDiffCommand(args []string, stdout io.Writer, stderr io.Writer) int {
+	flagSet := flag.NewFlagSet(diffCommandName, flag.ContinueOnError)
+	flagSet.SetOutput(stderr)
+	outputFormat := flagSet.String("output", "text", "Output format. `text` or `json`.")
+	flagSet.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: %s %s [flags] <base.khi> <target.khi>\n\nLists the resources whose revisions differ between 2 .khi files.\n\n", os.Args[0], diffCommandName)
+		flagSet.PrintDefaults()
+	}
+	if err := flagSet.Parse(args); err != nil {
+		return 2
+	}
+	if flagSet.NArg() != 2 {
+		flagSet.Usage()
+		return 2
+	}
+	if *outputFormat != "text" && *outputFormat != "json" {
+		fmt.Fprintf(stderr, "unsupported output format %q\n", *outputFormat)
+		return 2
+	}
+
+	khiFiles := []*reader.Reader{}
+	defer func() {
+		for _, khiFile := range khiFiles {
+			khiFile.Close()
+		}
+	}()
+	for _, path := range flagSet.Args() {
+		khiFile, err := reader.Open(path)
+		if err != nil {
+			fmt.Fprintf(stderr, "failed to open %s\n%v\n", path, err)
+			return 2
+		}
+		khiFiles = append(khiFiles, khiFile)
+	}
+
+	result, err := merge.Diff(khiFiles[0], khiFiles[1])
+	if err != nil {
+		fmt.Fprintf(stderr, "failed to compare .khi files\n%v\n", err)
+		return 2
+	}
+	if *outputFormat == "json" {
+		encoder := json.NewEncoder(stdout)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(result)
+	} else {
+		err = writeDiffResultAsText(stdout, result)
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "failed to write the output\n%v\n", err)
+		return 2
+	}
+	if len(result.Resources) > 0 {
+		return 1
+	}
+	return 0
+}
+
+func writeDiffResultAsText(writer io.Writer, result *merge.DiffResult) error {
+	w := tabwriter.NewWriter(writer, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(w, "Resources with different revisions:\t%d\n", len(result.Resources))
+	for _, resource := range result.Resources {
+		fmt.Fprintf(w, "\n%s\n", resource.ResourcePath)
+		for _, revision := range resource.OnlyInBase {
+			writeDiffRevisionAsText(w, "-", revision)
+		}
+		for _, revision := range resource.OnlyInTarget {
+			writeDiffRevisionAsText(w, "+", revision)
+		}
+	}
+	return w.Flush()
+}
+
+func writeDiffRevisionAsText(w io.Writer, mark string, revision *merge.RevisionSummary) {
+	fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", mark, revision.ChangeTime.Format(time.RFC3339Nano), enum.RevisionVerbs[revision.Verb].Label, enum.RevisionStates[revision.State].EnumKeyName, revision.Log)
+}
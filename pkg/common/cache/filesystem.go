@@ -0,0 +1,186 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const fileSystemCacheItemSuffix = ".cache"
+
+type fileSystemCacheItem struct {
+	size     int64
+	lastUsed time.Time
+}
+
+// FileSystemCacheItemStorageProvider is a CacheItemStorageProvider storing each item as a file in a folder.
+// The least recently used items are removed when the total size of the items exceeds maxBytes.
+type FileSystemCacheItemStorageProvider struct {
+	folder     string
+	maxBytes   int64
+	lock       sync.Mutex
+	items      map[string]*fileSystemCacheItem
+	totalBytes int64
+}
+
+// NewFileSystemCacheItemStorageProvider returns a FileSystemCacheItemStorageProvider storing items in the given folder.
+// The folder is created when it doesn't exist and the items already stored in the folder are reused.
+func NewFileSystemCacheItemStorageProvider(folder string, maxBytes int64) (*FileSystemCacheItemStorageProvider, error) {
+	if err := os.MkdirAll(folder, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create the cache folder %s\n%w", folder, err)
+	}
+	entries, err := os.ReadDir(folder)
+	if err != nil {
+		return nil, err
+	}
+	provider := &FileSystemCacheItemStorageProvider{
+		folder:   folder,
+		maxBytes: maxBytes,
+		items:    map[string]*fileSystemCacheItem{},
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), fileSystemCacheItemSuffix) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		provider.items[entry.Name()] = &fileSystemCacheItem{
+			size:     info.Size(),
+			lastUsed: info.ModTime(),
+		}
+		provider.totalBytes += info.Size()
+	}
+	provider.lock.Lock()
+	defer provider.lock.Unlock()
+	provider.evict()
+	return provider, nil
+}
+
+// Get implements CacheItemStorageProvider.
+func (f *FileSystemCacheItemStorageProvider) Get(key string) ([]byte, error) {
+	fileName := f.fileName(key)
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	item, found := f.items[fileName]
+	if !found {
+		return nil, ErrNotFoundInStorageErr
+	}
+	data, err := os.ReadFile(filepath.Join(f.folder, fileName))
+	if errors.Is(err, os.ErrNotExist) {
+		f.remove(fileName)
+		return nil, ErrNotFoundInStorageErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	// The modification time is used as the last used time of the item to keep the order after restarting.
+	item.lastUsed = time.Now()
+	os.Chtimes(filepath.Join(f.folder, fileName), item.lastUsed, item.lastUsed)
+	return data, nil
+}
+
+// Set implements CacheItemStorageProvider.
+func (f *FileSystemCacheItemStorageProvider) Set(key string, value []byte) error {
+	fileName := f.fileName(key)
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if int64(len(value)) > f.maxBytes {
+		f.remove(fileName)
+		return fmt.Errorf("the cache item %s is larger than the cache size limit %d bytes", key, f.maxBytes)
+	}
+	// Write a temporary file and rename it not to leave a broken item when KHI is killed in the middle of writing.
+	tmpFile, err := os.CreateTemp(f.folder, "tmp-item-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpFile.Name())
+	if _, err := tmpFile.Write(value); err != nil {
+		tmpFile.Close()
+		return err
+	}
+	if err := tmpFile.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpFile.Name(), filepath.Join(f.folder, fileName)); err != nil {
+		return err
+	}
+	if item, found := f.items[fileName]; found {
+		f.totalBytes -= item.size
+	}
+	f.items[fileName] = &fileSystemCacheItem{
+		size:     int64(len(value)),
+		lastUsed: time.Now(),
+	}
+	f.totalBytes += int64(len(value))
+	f.evict()
+	return nil
+}
+
+// TotalBytes returns the total size of the stored items.
+func (f *FileSystemCacheItemStorageProvider) TotalBytes() int64 {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return f.totalBytes
+}
+
+// evict removes the least recently used items until the total size fits in maxBytes. The caller must hold the lock.
+func (f *FileSystemCacheItemStorageProvider) evict() {
+	if f.totalBytes <= f.maxBytes {
+		return
+	}
+	fileNames := make([]string, 0, len(f.items))
+	for fileName := range f.items {
+		fileNames = append(fileNames, fileName)
+	}
+	sort.Slice(fileNames, func(i, j int) bool {
+		return f.items[fileNames[i]].lastUsed.Before(f.items[fileNames[j]].lastUsed)
+	})
+	for _, fileName := range fileNames {
+		if f.totalBytes <= f.maxBytes {
+			return
+		}
+		f.remove(fileName)
+	}
+}
+
+// remove deletes the item file. The caller must hold the lock.
+func (f *FileSystemCacheItemStorageProvider) remove(fileName string) {
+	item, found := f.items[fileName]
+	if !found {
+		return
+	}
+	os.Remove(filepath.Join(f.folder, fileName))
+	f.totalBytes -= item.size
+	delete(f.items, fileName)
+}
+
+// fileName returns the file name of the key. Keys are hashed because they can contain any character.
+func (f *FileSystemCacheItemStorageProvider) fileName(key string) string {
+	hash := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(hash[:]) + fileSystemCacheItemSuffix
+}
+
+var _ CacheItemStorageProvider = (*FileSystemCacheItemStorageProvider)(nil)
@@ -0,0 +1,98 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	_ "github.com/GoogleCloudPlatform/khi/internal/testflags"
+)
+
+func TestFileSystemCacheItemStorageProvider(t *testing.T) {
+	folder := t.TempDir()
+	provider, err := NewFileSystemCacheItemStorageProvider(folder, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := provider.Get("foo"); !errors.Is(err, ErrNotFoundInStorageErr) {
+		t.Errorf("Get() for a missing key returned %v, want ErrNotFoundInStorageErr", err)
+	}
+	if err := provider.Set("foo", []byte("bar")); err != nil {
+		t.Fatal(err)
+	}
+	if err := provider.Set("foo", []byte("baz")); err != nil {
+		t.Fatal(err)
+	}
+	got, err := provider.Get("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "baz" {
+		t.Errorf("Get() = %q, want %q", string(got), "baz")
+	}
+	if provider.TotalBytes() != 3 {
+		t.Errorf("TotalBytes() = %d, want 3", provider.TotalBytes())
+	}
+
+	// Items must be restored from the folder.
+	restored, err := NewFileSystemCacheItemStorageProvider(folder, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err = restored.Get("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "baz" {
+		t.Errorf("Get() after restoring = %q, want %q", string(got), "baz")
+	}
+}
+
+func TestFileSystemCacheItemStorageProviderEvictsLeastRecentlyUsed(t *testing.T) {
+	provider, err := NewFileSystemCacheItemStorageProvider(t.TempDir(), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"a", "b"} {
+		if err := provider.Set(key, []byte("1234")); err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	// Use "a" to make "b" the least recently used item.
+	if _, err := provider.Get("a"); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(10 * time.Millisecond)
+	if err := provider.Set("c", []byte("1234")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := provider.Get("b"); !errors.Is(err, ErrNotFoundInStorageErr) {
+		t.Errorf("Get(\"b\") returned %v, want ErrNotFoundInStorageErr", err)
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, err := provider.Get(key); err != nil {
+			t.Errorf("Get(%q) returned an unexpected error %v", key, err)
+		}
+	}
+	if provider.TotalBytes() != 8 {
+		t.Errorf("TotalBytes() = %d, want 8", provider.TotalBytes())
+	}
+	if err := provider.Set("large", make([]byte, 11)); err == nil {
+		t.Errorf("Set() with an item larger than the limit returned no error")
+	}
+}
@@ -0,0 +1,205 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxSearchYears is the limit to search the next activation time. Schedules like `0 0 30 2 *` never activate.
+const maxSearchYears = 5
+
+// field is the range of a field in cron expressions.
+type field struct {
+	name string
+	min  int
+	max  int
+}
+
+var (
+	minuteField     = field{name: "minute", min: 0, max: 59}
+	hourField       = field{name: "hour", min: 0, max: 23}
+	dayOfMonthField = field{name: "day of month", min: 1, max: 31}
+	monthField      = field{name: "month", min: 1, max: 12}
+	dayOfWeekField  = field{name: "day of week", min: 0, max: 6}
+)
+
+var descriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// Schedule is a parsed cron schedule.
+type Schedule struct {
+	minutes     uint64
+	hours       uint64
+	daysOfMonth uint64
+	months      uint64
+	daysOfWeek  uint64
+	// domRestricted and dowRestricted are true when the field is not `*`. A day matches when either of them matches if both are restricted.
+	domRestricted bool
+	dowRestricted bool
+	// every is the interval given with `@every <duration>`. The other fields are ignored when it's not 0.
+	every time.Duration
+}
+
+// Parse parses the standard 5 fields cron expression `minute hour day-of-month month day-of-week`.
+// Each field supports `*`, numbers, ranges (`1-5`), steps (`*/15`, `0-30/10`) and lists (`1,15`).
+// Descriptors like `@hourly`, `@daily` and `@every 30m` are also supported.
+func Parse(expression string) (*Schedule, error) {
+	expression = strings.TrimSpace(expression)
+	if after, found := strings.CutPrefix(expression, "@every "); found {
+		every, err := time.ParseDuration(strings.TrimSpace(after))
+		if err != nil {
+			return nil, fmt.Errorf("invalid duration in %q\n%w", expression, err)
+		}
+		if every < time.Second {
+			return nil, fmt.Errorf("the interval of %q must be 1 second or longer", expression)
+		}
+		return &Schedule{every: every}, nil
+	}
+	if descriptor, found := descriptors[expression]; found {
+		expression = descriptor
+	}
+	fields := strings.Fields(expression)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron expression %q must have 5 fields but it has %d fields", expression, len(fields))
+	}
+	schedule := &Schedule{
+		domRestricted: fields[2] != "*",
+		dowRestricted: fields[4] != "*",
+	}
+	var err error
+	for i, target := range []struct {
+		field field
+		bits  *uint64
+	}{
+		{minuteField, &schedule.minutes},
+		{hourField, &schedule.hours},
+		{dayOfMonthField, &schedule.daysOfMonth},
+		{monthField, &schedule.months},
+		{dayOfWeekField, &schedule.daysOfWeek},
+	} {
+		*target.bits, err = parseField(fields[i], target.field)
+		if err != nil {
+			return nil, err
+		}
+	}
+	// 7 is also accepted as Sunday.
+	if schedule.daysOfWeek&(1<<7) != 0 {
+		schedule.daysOfWeek |= 1
+	}
+	return schedule, nil
+}
+
+// parseField returns the bit set of values matching the field expression.
+func parseField(expression string, f field) (uint64, error) {
+	max := f.max
+	if f == dayOfWeekField {
+		max = 7
+	}
+	var bits uint64
+	for _, part := range strings.Split(expression, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			var err error
+			step, err = strconv.Atoi(stepPart)
+			if err != nil || step <= 0 {
+				return 0, fmt.Errorf("invalid step %q in the %s field", stepPart, f.name)
+			}
+		}
+		begin, end := f.min, max
+		if rangePart != "*" {
+			beginPart, endPart, isRange := strings.Cut(rangePart, "-")
+			var err error
+			begin, err = parseValue(beginPart, f.min, max, f.name)
+			if err != nil {
+				return 0, err
+			}
+			end = begin
+			if isRange {
+				end, err = parseValue(endPart, f.min, max, f.name)
+				if err != nil {
+					return 0, err
+				}
+			} else if hasStep {
+				end = max
+			}
+			if begin > end {
+				return 0, fmt.Errorf("invalid range %q in the %s field", rangePart, f.name)
+			}
+		}
+		for value := begin; value <= end; value += step {
+			bits |= 1 << value
+		}
+	}
+	return bits, nil
+}
+
+func parseValue(expression string, min int, max int, name string) (int, error) {
+	value, err := strconv.Atoi(expression)
+	if err != nil || value < min || value > max {
+		return 0, fmt.Errorf("invalid value %q in the %s field. It must be in [%d, %d]", expression, name, min, max)
+	}
+	return value, nil
+}
+
+// Next returns the earliest activation time after the given time. It returns the zero time when the schedule never activates.
+// The fields are evaluated in the location of the given time.
+func (s *Schedule) Next(after time.Time) time.Time {
+	if s.every > 0 {
+		return after.Truncate(time.Second).Add(s.every)
+	}
+	current := after.Truncate(time.Minute).Add(time.Minute)
+	limit := after.AddDate(maxSearchYears, 0, 0)
+	for current.Before(limit) {
+		if s.months&(1<<int(current.Month())) == 0 {
+			current = time.Date(current.Year(), current.Month()+1, 1, 0, 0, 0, 0, current.Location())
+			continue
+		}
+		if !s.matchesDay(current) {
+			current = time.Date(current.Year(), current.Month(), current.Day()+1, 0, 0, 0, 0, current.Location())
+			continue
+		}
+		if s.hours&(1<<current.Hour()) == 0 {
+			current = time.Date(current.Year(), current.Month(), current.Day(), current.Hour()+1, 0, 0, 0, current.Location())
+			continue
+		}
+		if s.minutes&(1<<current.Minute()) == 0 {
+			current = current.Add(time.Minute)
+			continue
+		}
+		return current
+	}
+	return time.Time{}
+}
+
+func (s *Schedule) matchesDay(t time.Time) bool {
+	domMatched := s.daysOfMonth&(1<<t.Day()) != 0
+	dowMatched := s.daysOfWeek&(1<<int(t.Weekday())) != 0
+	if s.domRestricted && s.dowRestricted {
+		return domMatched || dowMatched
+	}
+	return domMatched && dowMatched
+}
@@ -0,0 +1,82 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cron
+
+import (
+	"testing"
+	"time"
+
+	_ "github.com/GoogleCloudPlatform/khi/internal/testflags"
+)
+
+func TestScheduleNext(t *testing.T) {
+	// 2025-01-01 is Wednesday.
+	base := time.Date(2025, time.January, 1, 10, 20, 30, 0, time.UTC)
+	testCases := []struct {
+		expression string
+		after      time.Time
+		want       time.Time
+	}{
+		{expression: "* * * * *", after: base, want: time.Date(2025, time.January, 1, 10, 21, 0, 0, time.UTC)},
+		{expression: "*/15 * * * *", after: base, want: time.Date(2025, time.January, 1, 10, 30, 0, 0, time.UTC)},
+		{expression: "0 */6 * * *", after: base, want: time.Date(2025, time.January, 1, 12, 0, 0, 0, time.UTC)},
+		{expression: "5,10 9-11 * * *", after: base, want: time.Date(2025, time.January, 1, 11, 5, 0, 0, time.UTC)},
+		{expression: "0 0 * * 1-5", after: base, want: time.Date(2025, time.January, 2, 0, 0, 0, 0, time.UTC)},
+		{expression: "0 0 * * 0", after: base, want: time.Date(2025, time.January, 5, 0, 0, 0, 0, time.UTC)},
+		{expression: "0 0 * * 7", after: base, want: time.Date(2025, time.January, 5, 0, 0, 0, 0, time.UTC)},
+		{expression: "0 0 29 2 *", after: base, want: time.Date(2028, time.February, 29, 0, 0, 0, 0, time.UTC)},
+		// Either of day of month and day of week must match when both are restricted.
+		{expression: "0 0 15 * 5", after: base, want: time.Date(2025, time.January, 3, 0, 0, 0, 0, time.UTC)},
+		{expression: "@daily", after: base, want: time.Date(2025, time.January, 2, 0, 0, 0, 0, time.UTC)},
+		{expression: "@hourly", after: base, want: time.Date(2025, time.January, 1, 11, 0, 0, 0, time.UTC)},
+		{expression: "@every 90m", after: base, want: time.Date(2025, time.January, 1, 11, 50, 30, 0, time.UTC)},
+		{expression: "0 0 30 2 *", after: base, want: time.Time{}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.expression, func(t *testing.T) {
+			schedule, err := Parse(tc.expression)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := schedule.Next(tc.after)
+			if !got.Equal(tc.want) {
+				t.Errorf("Next(%s) = %s, want %s", tc.after, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestParseInvalidExpression(t *testing.T) {
+	for _, expression := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"10-5 * * * *",
+		"a * * * *",
+		"@every 1ms",
+		"@every tomorrow",
+	} {
+		t.Run(expression, func(t *testing.T) {
+			if _, err := Parse(expression); err == nil {
+				t.Errorf("Parse(%q) returned nil error, want an error", expression)
+			}
+		})
+	}
+}
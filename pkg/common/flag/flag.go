@@ -22,6 +22,7 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"time"
 )
 
 // This package provides helper functions to parse command line arguments and environment variables.
@@ -163,6 +164,38 @@ func Int(name string, value int, usage string, envKey string) *int {
 	return resultPtr
 }
 
+// Duration is a similar to flag.Duration but it also parse value from specified environment variable when the parameter was not provided explicitly on command line argument.
+// Environment variables are ignored when the given envKey is an empty string.
+func Duration(name string, value time.Duration, usage string, envKey string) *time.Duration {
+	result := value
+	resultPtr := &result
+	if envKey != "" {
+		usage = fmt.Sprintf("%s [environment variable key: \"%s\"]", usage, envKey)
+	}
+	fromCmdArgs := flag.Duration(name, value, usage)
+	flagParsers = append(flagParsers, func() error {
+		providedFromCmdArgs, err := isProvidedFromCommandlineArgs(name)
+		if err != nil {
+			return err
+		}
+		if providedFromCmdArgs {
+			*resultPtr = *fromCmdArgs
+		} else if isProvidedFromEnvironmentVariable(envKey) {
+			envValue := os.Getenv(envKey)
+			durationEnv, err := time.ParseDuration(envValue)
+			if err != nil {
+				return err
+			}
+			*resultPtr = durationEnv
+		}
+		return nil
+	})
+	flagValueDumper = append(flagValueDumper, func() string {
+		return fmt.Sprintf("%s: %v", name, *resultPtr)
+	})
+	return resultPtr
+}
+
 func isProvidedFromCommandlineArgs(key string) (bool, error) {
 	if !flag.Parsed() {
 		return false, fmt.Errorf("command line arguments are not yet parsed")
@@ -18,6 +18,7 @@ import (
 	"flag"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 
@@ -354,3 +355,100 @@ func TestInt(t *testing.T) {
 		})
 	}
 }
+
+func TestDuration(t *testing.T) {
+	testCases := []struct {
+		name           string
+		cmdArgKey      string
+		envKey         string
+		value          time.Duration
+		cmdArgs        []string
+		before         func()
+		after          func()
+		want           time.Duration
+		wantErrOnParse bool
+	}{
+		{
+			name:      "from command line argument",
+			cmdArgKey: "foo",
+			envKey:    "",
+			value:     time.Minute,
+			cmdArgs:   []string{"--foo=2h"},
+			before:    func() {},
+			after:     func() {},
+			want:      2 * time.Hour,
+		},
+		{
+			name:      "from environment variable",
+			cmdArgKey: "foo",
+			envKey:    "FOO",
+			value:     time.Minute,
+			cmdArgs:   []string{},
+			before: func() {
+				os.Setenv("FOO", "30s")
+			},
+			after: func() {
+				os.Unsetenv("FOO")
+			},
+			want: 30 * time.Second,
+		},
+		{
+			name:      "both provided, command line argument is prioritized",
+			cmdArgKey: "foo",
+			envKey:    "FOO",
+			value:     time.Minute,
+			cmdArgs:   []string{"--foo=2h"},
+			before: func() {
+				os.Setenv("FOO", "30s")
+			},
+			after: func() {
+				os.Unsetenv("FOO")
+			},
+			want: 2 * time.Hour,
+		},
+		{
+			name:      "default value",
+			cmdArgKey: "foo",
+			envKey:    "",
+			value:     time.Minute,
+			cmdArgs:   []string{},
+			before:    func() {},
+			after:     func() {},
+			want:      time.Minute,
+		},
+		{
+			name:      "invalid environment variable",
+			cmdArgKey: "foo",
+			envKey:    "FOO",
+			value:     time.Minute,
+			cmdArgs:   []string{},
+			before: func() {
+				os.Setenv("FOO", "invalid")
+			},
+			after: func() {
+				os.Unsetenv("FOO")
+			},
+			want:           time.Minute,
+			wantErrOnParse: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.before()
+			defer tc.after()
+			defer Reset()
+			setCommandlineArguments(t, tc.cmdArgs)
+			gotPointer := Duration(tc.cmdArgKey, tc.value, "", tc.envKey)
+			err := Parse()
+			if tc.wantErrOnParse && err == nil {
+				t.Errorf("unexpected error, got nil, want error")
+			}
+			if !tc.wantErrOnParse && err != nil {
+				t.Errorf("unexpected error, got %v, want nil", err)
+			}
+			if *gotPointer != tc.want {
+				t.Errorf("unexpected result, got %v, want %v", *gotPointer, tc.want)
+			}
+		})
+	}
+}
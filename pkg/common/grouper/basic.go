@@ -22,7 +22,7 @@ type BasicGrouper[T any, K comparable] struct {
 func (b *BasicGrouper[T, K]) Group(input []T) map[K][]T {
 	result := map[K][]T{}
 	for _, v := range input {
-		key := b.GroupingFunc(v)
+		key := b.GroupKey(v)
 		if _, found := result[key]; !found {
 			result[key] = []T{}
 		}
@@ -31,6 +31,11 @@ func (b *BasicGrouper[T, K]) Group(input []T) map[K][]T {
 	return result
 }
 
+// GroupKey implements Grouper.
+func (b *BasicGrouper[T, K]) GroupKey(input T) K {
+	return b.GroupingFunc(input)
+}
+
 func NewBasicGrouper[T any, K comparable](groupingFunction func(input T) K) *BasicGrouper[T, K] {
 	return &BasicGrouper[T, K]{
 		GroupingFunc: groupingFunction,
@@ -21,6 +21,8 @@ import (
 
 type BasicHttpClient struct {
 	HeaderProvider []HTTPHeaderProvider
+	// Transport is the RoundTripper used to send requests. http.DefaultTransport is used when it's nil.
+	Transport http.RoundTripper
 }
 
 // BasicHttpClient implements HttpClient interface
@@ -49,6 +51,8 @@ func (b *BasicHttpClient) DoWithContext(ctx context.Context, request *http.Reque
 		}
 	}
 	req := request.WithContext(ctx)
-	client := new(http.Client)
+	client := &http.Client{
+		Transport: b.Transport,
+	}
 	return client.Do(req)
 }
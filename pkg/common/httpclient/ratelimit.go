@@ -0,0 +1,43 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpclient
+
+import (
+	"context"
+	"net/http"
+)
+
+// RateLimitListener receives notifications of rate limited responses received by RetryHttpClient.
+type RateLimitListener interface {
+	// OnRateLimited is called every time a request is answered with 429 before it's retried.
+	OnRateLimited()
+}
+
+type rateLimitListenerContextKey struct{}
+
+// WithRateLimitListener returns a context notifying the listener when requests sent with the context are rate limited.
+func WithRateLimitListener(ctx context.Context, listener RateLimitListener) context.Context {
+	return context.WithValue(ctx, rateLimitListenerContextKey{}, listener)
+}
+
+// notifyRateLimited calls the RateLimitListener in the context when the status code means the request was rate limited.
+func notifyRateLimited(ctx context.Context, statusCode int) {
+	if statusCode != http.StatusTooManyRequests {
+		return
+	}
+	if listener, ok := ctx.Value(rateLimitListenerContextKey{}).(RateLimitListener); ok {
+		listener.OnRateLimited()
+	}
+}
@@ -21,9 +21,13 @@ import (
 	"io"
 	"log/slog"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/GoogleCloudPlatform/khi/pkg/common/token"
+	"github.com/GoogleCloudPlatform/khi/pkg/metrics"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type RetryHttpClient struct {
@@ -70,10 +74,15 @@ func (r *RetryHttpClient) DoWithContext(ctx context.Context, originalRequest *ht
 			return nil, err
 		}
 		request.Header = originalRequest.Header.Clone()
+		host := request.URL.Host
+		requestStartTime := time.Now()
 		response, err := r.Client.DoWithContext(ctx, request)
+		metrics.APIRequestDuration.WithLabelValues(host).Observe(time.Since(requestStartTime).Seconds())
 		if err != nil {
+			metrics.APIRequests.WithLabelValues(host, "error").Inc()
 			return nil, err
 		}
+		metrics.APIRequests.WithLabelValues(host, strconv.Itoa(response.StatusCode)).Inc()
 		if response.StatusCode < 400 {
 			r.currentWaitSeconds = r.MinWaitSeconds
 			// Treat this response is ok not to retry
@@ -87,8 +96,11 @@ func (r *RetryHttpClient) DoWithContext(ctx context.Context, originalRequest *ht
 			return response, fmt.Errorf("unretriable error returned(%d):%s\nBODY:%s", response.StatusCode, response.Status, string(body))
 		} else {
 			statusCodes = append(statusCodes, response.StatusCode)
+			notifyRateLimited(ctx, response.StatusCode)
 			if r.isRetriableWithRefreshingToken(response.StatusCode) {
 				slog.DebugContext(ctx, fmt.Sprintf("Previous request to %s got %d response. Attempting retrying with refreshing the token.", request.RequestURI, response.StatusCode))
+				metrics.APIRequestRetries.WithLabelValues(host, "refresh-token").Inc()
+				recordRetryEvent(ctx, response.StatusCode, "refresh-token")
 				r.tokenRefresher.Refresh(ctx)
 				r.currentWaitSeconds = r.MinWaitSeconds
 			} else {
@@ -97,6 +109,8 @@ func (r *RetryHttpClient) DoWithContext(ctx context.Context, originalRequest *ht
 					r.currentWaitSeconds = r.MaxWaitSeconds
 				}
 				slog.DebugContext(ctx, fmt.Sprintf("Previous request to %s got %d response. Next retry after %d seconds", request.RequestURI, response.StatusCode, r.currentWaitSeconds))
+				metrics.APIRequestRetries.WithLabelValues(host, "backoff").Inc()
+				recordRetryEvent(ctx, response.StatusCode, "backoff")
 				time.Sleep(r.timeUnit * time.Duration(r.currentWaitSeconds))
 			}
 		}
@@ -123,3 +137,11 @@ func (r *RetryHttpClient) isRetriableWithRefreshingToken(code int) bool {
 }
 
 var _ (HTTPClient[*http.Response]) = (*RetryHttpClient)(nil)
+
+// recordRetryEvent adds an event on the span in the context when a request is retried.
+func recordRetryEvent(ctx context.Context, statusCode int, reason string) {
+	trace.SpanFromContext(ctx).AddEvent("retry", trace.WithAttributes(
+		attribute.Int("http.response.status_code", statusCode),
+		attribute.String("khi.retry.reason", reason),
+	))
+}
@@ -24,6 +24,9 @@ import (
 	"time"
 
 	"github.com/GoogleCloudPlatform/khi/pkg/common/token"
+	"github.com/GoogleCloudPlatform/khi/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 
 	_ "github.com/GoogleCloudPlatform/khi/internal/testflags"
 )
@@ -293,3 +296,84 @@ func TestRetryBehavior(t *testing.T) {
 		})
 	}
 }
+
+type rateLimitListenerSpy struct {
+	CallCount int
+}
+
+// OnRateLimited implements RateLimitListener.
+func (r *rateLimitListenerSpy) OnRateLimited() {
+	r.CallCount++
+}
+
+func TestRetryNotifiesRateLimitListener(t *testing.T) {
+	responses := []*http.Response{}
+	for _, respCode := range []int{429, 500, 429, 200} {
+		responses = append(responses, &http.Response{ // nolint:bodyclose // the mock responses have no resource to be released.
+			StatusCode: respCode,
+			Body:       io.NopCloser(bytes.NewBufferString("")),
+		})
+	}
+	baseClient := mockFailClient{
+		Responses: responses,
+		Requests:  make([]*http.Request, 0),
+	}
+	retryClient := NewRetryHttpClient(&baseClient, 0, 0, 5, []int{429, 500}, []int{}, &tokenRefresherClientSpy{})
+	req, err := http.NewRequest("GET", "https://google.com", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	listener := &rateLimitListenerSpy{}
+	response, err := retryClient.DoWithContext(WithRateLimitListener(context.Background(), listener), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer response.Body.Close()
+	if listener.CallCount != 2 {
+		t.Errorf("got OnRateLimited call count %d, want 2", listener.CallCount)
+	}
+}
+
+func TestRetryRecordsMetrics(t *testing.T) {
+	responses := []*http.Response{}
+	for _, respCode := range []int{500, 401, 200} {
+		responses = append(responses, &http.Response{ // nolint:bodyclose // the mock responses have no resource to be released.
+			StatusCode: respCode,
+			Body:       io.NopCloser(bytes.NewBufferString("")),
+		})
+	}
+	baseClient := mockFailClient{
+		Responses: responses,
+		Requests:  make([]*http.Request, 0),
+	}
+	retryClient := NewRetryHttpClient(&baseClient, 0, 0, 5, []int{500}, []int{401}, &tokenRefresherClientSpy{})
+	host := "metrics.example.com"
+	req, err := http.NewRequest("GET", "https://"+host, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	response, err := retryClient.DoWithContext(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer response.Body.Close()
+
+	testCases := []struct {
+		name    string
+		counter prometheus.Counter
+		want    float64
+	}{
+		{name: "requests with 500", counter: metrics.APIRequests.WithLabelValues(host, "500"), want: 1},
+		{name: "requests with 401", counter: metrics.APIRequests.WithLabelValues(host, "401"), want: 1},
+		{name: "requests with 200", counter: metrics.APIRequests.WithLabelValues(host, "200"), want: 1},
+		{name: "retries with backoff", counter: metrics.APIRequestRetries.WithLabelValues(host, "backoff"), want: 1},
+		{name: "retries with token refresh", counter: metrics.APIRequestRetries.WithLabelValues(host, "refresh-token"), want: 1},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := testutil.ToFloat64(tc.counter); got != tc.want {
+				t.Errorf("got %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
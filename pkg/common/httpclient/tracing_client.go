@@ -0,0 +1,57 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpclient
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracingHttpClient records each request sent with the underlying client as a span.
+type TracingHttpClient struct {
+	client HTTPClient[*http.Response]
+}
+
+var _ HTTPClient[*http.Response] = (*TracingHttpClient)(nil)
+
+func NewTracingHttpClient(client HTTPClient[*http.Response]) *TracingHttpClient {
+	return &TracingHttpClient{
+		client: client,
+	}
+}
+
+// DoWithContext implements HTTPClient.
+func (t *TracingHttpClient) DoWithContext(ctx context.Context, request *http.Request) (*http.Response, error) {
+	ctx, span := tracing.Start(ctx, fmt.Sprintf("%s %s", request.Method, request.URL.Host), trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(
+		attribute.String("http.request.method", request.Method),
+		attribute.String("server.address", request.URL.Host),
+		attribute.String("url.path", request.URL.Path),
+	))
+	response, err := t.client.DoWithContext(ctx, request)
+	if response != nil {
+		span.SetAttributes(attribute.Int("http.response.status_code", response.StatusCode))
+		if err == nil && response.StatusCode >= 400 {
+			span.SetStatus(codes.Error, response.Status)
+		}
+	}
+	tracing.EndSpan(span, err)
+	return response, err
+}
@@ -0,0 +1,107 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace/noop"
+
+	_ "github.com/GoogleCloudPlatform/khi/internal/testflags"
+)
+
+func TestTracingHttpClient(t *testing.T) {
+	testCases := []struct {
+		name           string
+		responseCodes  []int
+		wantStatus     codes.Code
+		wantEventNames []string
+	}{
+		{
+			name:           "succeeded request",
+			responseCodes:  []int{200},
+			wantStatus:     codes.Unset,
+			wantEventNames: []string{},
+		},
+		{
+			name:           "retried request",
+			responseCodes:  []int{500, 200},
+			wantStatus:     codes.Unset,
+			wantEventNames: []string{"retry"},
+		},
+		{
+			name:           "failed request",
+			responseCodes:  []int{404},
+			wantStatus:     codes.Error,
+			wantEventNames: []string{"exception"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := tracetest.NewSpanRecorder()
+			otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+			t.Cleanup(func() {
+				otel.SetTracerProvider(noop.NewTracerProvider())
+			})
+			responses := []*http.Response{}
+			for _, respCode := range tc.responseCodes {
+				responses = append(responses, &http.Response{ // nolint:bodyclose // the mock responses have no resource to be released.
+					StatusCode: respCode,
+					Body:       io.NopCloser(bytes.NewBufferString("")),
+				})
+			}
+			baseClient := mockFailClient{
+				Responses: responses,
+				Requests:  make([]*http.Request, 0),
+			}
+			client := NewTracingHttpClient(NewRetryHttpClient(&baseClient, 0, 0, 5, []int{500}, []int{}, &tokenRefresherClientSpy{}))
+			req, err := http.NewRequest("POST", "https://logging.googleapis.com/v2/entries:list", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			response, _ := client.DoWithContext(context.Background(), req)
+			if response != nil {
+				defer response.Body.Close()
+			}
+
+			spans := recorder.Ended()
+			if len(spans) != 1 {
+				t.Fatalf("got %d ended spans, want 1", len(spans))
+			}
+			if diff := cmp.Diff("POST logging.googleapis.com", spans[0].Name()); diff != "" {
+				t.Errorf("span name mismatch (-want +got)\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.wantStatus, spans[0].Status().Code); diff != "" {
+				t.Errorf("status mismatch (-want +got)\n%s", diff)
+			}
+			eventNames := []string{}
+			for _, event := range spans[0].Events() {
+				eventNames = append(eventNames, event.Name)
+			}
+			if diff := cmp.Diff(tc.wantEventNames, eventNames); diff != "" {
+				t.Errorf("events mismatch (-want +got)\n%s", diff)
+			}
+		})
+	}
+}
@@ -120,6 +120,21 @@ func (m *ReadonlyTypedMap) Keys() []string {
 	return m.source.Keys()
 }
 
+// Values returns all values in the map as a slice. The order of values is not guaranteed.
+func (m *TypedMap) Values() []any {
+	var values []any
+	m.container.Range(func(key, value interface{}) bool {
+		values = append(values, value)
+		return true
+	})
+	return values
+}
+
+// Values returns all values in the map as a slice. The order of values is not guaranteed.
+func (m *ReadonlyTypedMap) Values() []any {
+	return m.source.Values()
+}
+
 // Get retrieves a value in a type-safe way.
 // Works with both TypedMap and ReadonlyTypedMap.
 func Get[T any](m ReadableTypedMap, key TypedKey[T]) (T, bool) {
@@ -400,6 +400,23 @@ func TestReadonlyTypedMapKeys(t *testing.T) {
 	})
 }
 
+func TestTypedMapValues(t *testing.T) {
+	tm := NewTypedMap()
+	Set(tm, StringKey, "string value")
+	Set(tm, NewTypedKey[int]("int-key"), 42)
+
+	for _, values := range [][]any{tm.Values(), tm.AsReadonly().Values()} {
+		got := map[any]bool{}
+		for _, value := range values {
+			got[value] = true
+		}
+		want := map[any]bool{"string value": true, 42: true}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("Values() mismatch (-want +got):\n%s", diff)
+		}
+	}
+}
+
 func TestGetOrSetFuncIsThreadSafe(t *testing.T) {
 	waitAttempts := sync.WaitGroup{}
 	for i := 0; i < 100; i++ {
@@ -0,0 +1,155 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package budget
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	error_metadata "github.com/GoogleCloudPlatform/khi/pkg/inspection/metadata/error"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/binarychunk"
+)
+
+// ErrLogCountExceeded is returned when a query task receives more logs than the budget with LogCountActionStop.
+var ErrLogCountExceeded = errors.New("log count exceeded the budget")
+
+// ErrWallTimeExceeded is returned when an inspection takes longer than the budget.
+var ErrWallTimeExceeded = errors.New("inspection time exceeded the budget")
+
+// LogCountAction is what to do when a query task receives more logs than the budget.
+type LogCountAction string
+
+const (
+	// LogCountActionSample keeps sampled logs under the limit and continues the inspection.
+	LogCountActionSample LogCountAction = "sample"
+	// LogCountActionStop fails the inspection.
+	LogCountActionStop LogCountAction = "stop"
+)
+
+const (
+	errorIDLogCountExceeded    = 3
+	errorIDLogSampled          = 4
+	errorIDBinaryBytesExceeded = 5
+	errorIDWallTimeExceeded    = 6
+)
+
+// Default is the budget applied to inspections not specifying their own budget. It's unlimited unless the server configures it.
+var Default = &Budget{LogCountAction: LogCountActionSample}
+
+// Budget is the limits of resources a single inspection can consume. A zero value limit means unlimited.
+type Budget struct {
+	// MaxLogsPerQuery is the maximum count of logs a single query task can hold.
+	MaxLogsPerQuery int
+	// LogCountAction is what to do when a query task exceeds MaxLogsPerQuery.
+	LogCountAction LogCountAction
+	// MaxBinaryBytes is the maximum total size of log bodies and manifests stored in the inspection result.
+	MaxBinaryBytes int
+	// MaxWallTime is the maximum duration of the inspection.
+	MaxWallTime time.Duration
+}
+
+// Override is the budget given in a request to run an inspection. Nil fields are left as the server-wide budget.
+type Override struct {
+	MaxLogsPerQuery *int            `json:"maxLogsPerQuery"`
+	LogCountAction  *LogCountAction `json:"logCountAction"`
+	MaxBinaryBytes  *int            `json:"maxBinaryBytes"`
+	// MaxWallTime is the duration in the format of time.ParseDuration like `30m`.
+	MaxWallTime *string `json:"maxWallTime"`
+}
+
+// WithOverride returns a new Budget overridden with the given Override.
+// A request can only tighten the server-wide limits so that a single user can't take the resources of a shared server.
+func (b *Budget) WithOverride(override *Override) (*Budget, error) {
+	result := *b
+	if override == nil {
+		return &result, nil
+	}
+	var err error
+	if override.MaxLogsPerQuery != nil {
+		result.MaxLogsPerQuery, err = tighten("maxLogsPerQuery", b.MaxLogsPerQuery, *override.MaxLogsPerQuery)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if override.LogCountAction != nil {
+		switch *override.LogCountAction {
+		case LogCountActionSample, LogCountActionStop:
+			result.LogCountAction = *override.LogCountAction
+		default:
+			return nil, fmt.Errorf("logCountAction must be %q or %q but %q was given", LogCountActionSample, LogCountActionStop, *override.LogCountAction)
+		}
+	}
+	if override.MaxBinaryBytes != nil {
+		result.MaxBinaryBytes, err = tighten("maxBinaryBytes", b.MaxBinaryBytes, *override.MaxBinaryBytes)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if override.MaxWallTime != nil {
+		maxWallTime, err := time.ParseDuration(*override.MaxWallTime)
+		if err != nil {
+			return nil, fmt.Errorf("maxWallTime is not a valid duration\n%w", err)
+		}
+		wallTimeInNanos, err := tighten("maxWallTime", int(b.MaxWallTime), int(maxWallTime))
+		if err != nil {
+			return nil, err
+		}
+		result.MaxWallTime = time.Duration(wallTimeInNanos)
+	}
+	return &result, nil
+}
+
+// tighten returns the requested limit when it doesn't loosen the server limit. 0 means unlimited for both limits.
+func tighten(name string, serverLimit int, requested int) (int, error) {
+	if requested < 0 {
+		return 0, fmt.Errorf("%s must not be negative", name)
+	}
+	if serverLimit > 0 && (requested == 0 || requested > serverLimit) {
+		return 0, fmt.Errorf("%s can't exceed the server limit %d", name, serverLimit)
+	}
+	return requested, nil
+}
+
+// ErrorMessage returns the message shown on the frontend for the error failed the inspection.
+// It returns nil when the error wasn't caused by the budget.
+func (b *Budget) ErrorMessage(err error) *error_metadata.ErrorMessage {
+	switch {
+	case errors.Is(err, ErrLogCountExceeded):
+		return &error_metadata.ErrorMessage{
+			ErrorId: errorIDLogCountExceeded,
+			Message: fmt.Sprintf("The inspection was stopped because a query returned more than %d logs. Narrow down the time range or the query, or set a larger maxLogsPerQuery budget.", b.MaxLogsPerQuery),
+		}
+	case errors.Is(err, binarychunk.ErrTotalSizeExceeded):
+		return &error_metadata.ErrorMessage{
+			ErrorId: errorIDBinaryBytesExceeded,
+			Message: fmt.Sprintf("The inspection was stopped because log bodies and manifests exceeded %d bytes. Narrow down the time range or disable some features.", b.MaxBinaryBytes),
+		}
+	case errors.Is(err, ErrWallTimeExceeded):
+		return &error_metadata.ErrorMessage{
+			ErrorId: errorIDWallTimeExceeded,
+			Message: fmt.Sprintf("The inspection was stopped because it took longer than %s. Narrow down the time range or disable some features.", b.MaxWallTime),
+		}
+	}
+	return nil
+}
+
+// newLogSampledErrorMessage returns the message shown on the frontend when logs of a query task were sampled.
+func newLogSampledErrorMessage(maxLogs int) *error_metadata.ErrorMessage {
+	return &error_metadata.ErrorMessage{
+		ErrorId: errorIDLogSampled,
+		Message: fmt.Sprintf("Some queries returned more than %d logs and only sampled logs were used for them. The timelines may lack some changes.", maxLogs),
+	}
+}
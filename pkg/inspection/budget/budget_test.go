@@ -0,0 +1,134 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package budget
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/model/binarychunk"
+	"github.com/GoogleCloudPlatform/khi/pkg/testutil"
+	"github.com/google/go-cmp/cmp"
+
+	_ "github.com/GoogleCloudPlatform/khi/internal/testflags"
+)
+
+func TestWithOverride(t *testing.T) {
+	server := &Budget{
+		MaxLogsPerQuery: 1000,
+		LogCountAction:  LogCountActionSample,
+		MaxWallTime:     time.Hour,
+	}
+	testCases := []struct {
+		name     string
+		override *Override
+		want     *Budget
+		wantErr  bool
+	}{
+		{
+			name:     "without override",
+			override: nil,
+			want:     server,
+		},
+		{
+			name: "tighten limits",
+			override: &Override{
+				MaxLogsPerQuery: testutil.P(100),
+				LogCountAction:  testutil.P(LogCountActionStop),
+				MaxBinaryBytes:  testutil.P(1 << 20),
+				MaxWallTime:     testutil.P("10m"),
+			},
+			want: &Budget{
+				MaxLogsPerQuery: 100,
+				LogCountAction:  LogCountActionStop,
+				MaxBinaryBytes:  1 << 20,
+				MaxWallTime:     10 * time.Minute,
+			},
+		},
+		{
+			name:     "loosen the server limit",
+			override: &Override{MaxLogsPerQuery: testutil.P(10000)},
+			wantErr:  true,
+		},
+		{
+			name:     "remove the server limit",
+			override: &Override{MaxWallTime: testutil.P("0s")},
+			wantErr:  true,
+		},
+		{
+			name:     "negative limit",
+			override: &Override{MaxBinaryBytes: testutil.P(-1)},
+			wantErr:  true,
+		},
+		{
+			name:     "unknown action",
+			override: &Override{LogCountAction: testutil.P(LogCountAction("drop"))},
+			wantErr:  true,
+		},
+		{
+			name:     "invalid duration",
+			override: &Override{MaxWallTime: testutil.P("an hour")},
+			wantErr:  true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := server.WithOverride(tc.override)
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("WithOverride() returned nil error, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("WithOverride() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestErrorMessage(t *testing.T) {
+	b := &Budget{MaxLogsPerQuery: 10, MaxBinaryBytes: 20, MaxWallTime: time.Minute}
+	testCases := []struct {
+		name    string
+		err     error
+		wantID  int
+		wantNil bool
+	}{
+		{name: "log count", err: fmt.Errorf("query failed\n%w", ErrLogCountExceeded), wantID: errorIDLogCountExceeded},
+		{name: "binary size", err: fmt.Errorf("parser failed\n%w", binarychunk.ErrTotalSizeExceeded), wantID: errorIDBinaryBytesExceeded},
+		{name: "wall time", err: ErrWallTimeExceeded, wantID: errorIDWallTimeExceeded},
+		{name: "unrelated error", err: errors.New("foo"), wantNil: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := b.ErrorMessage(tc.err)
+			if tc.wantNil {
+				if got != nil {
+					t.Errorf("ErrorMessage() = %v, want nil", got)
+				}
+				return
+			}
+			if got == nil || got.ErrorId != tc.wantID {
+				t.Errorf("ErrorMessage() = %v, want the message with ID %d", got, tc.wantID)
+			}
+		})
+	}
+}
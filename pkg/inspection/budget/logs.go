@@ -0,0 +1,96 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package budget
+
+import (
+	"fmt"
+	"sync"
+
+	error_metadata "github.com/GoogleCloudPlatform/khi/pkg/inspection/metadata/error"
+	"github.com/GoogleCloudPlatform/khi/pkg/log"
+	"github.com/GoogleCloudPlatform/khi/pkg/log/logstore"
+)
+
+// maxSamplingLevel is the maximum level of sampling. The sampling rate can't be lower than 1/2^63.
+const maxSamplingLevel = 63
+
+// LogLimiter appends logs to a logstore.Store within the MaxLogsPerQuery budget.
+// With LogCountActionSample, logs are sampled by the hash of their IDs and the sampling rate is halved every time the store reaches the limit.
+// Logs from a query arrive in any order, thus this keeps sampled logs spread over the whole time range instead of keeping the first logs.
+type LogLimiter struct {
+	store   *logstore.Store
+	maxLogs int
+	action  LogCountAction
+	lock    sync.Mutex
+	// level is the current level of sampling. Logs are kept only when the lowest `level` bits of their IDHash are zero.
+	level uint
+}
+
+// NewLogLimiter returns a LogLimiter appending logs to the given store.
+func (b *Budget) NewLogLimiter(store *logstore.Store) *LogLimiter {
+	return &LogLimiter{
+		store:   store,
+		maxLogs: b.MaxLogsPerQuery,
+		action:  b.LogCountAction,
+	}
+}
+
+// Append appends the log to the store unless it's dropped by the sampling.
+// It returns ErrLogCountExceeded when the store is full with LogCountActionStop.
+func (l *LogLimiter) Append(lg *log.Log) error {
+	if l.maxLogs <= 0 {
+		return l.store.Append(lg)
+	}
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	idHash := logstore.IDHash(lg.ID)
+	if !l.keeps(idHash) {
+		return nil
+	}
+	if l.store.Len() >= l.maxLogs {
+		if l.action == LogCountActionStop {
+			return fmt.Errorf("%w: a query returned more than %d logs", ErrLogCountExceeded, l.maxLogs)
+		}
+		for l.store.Len() >= l.maxLogs && l.level < maxSamplingLevel {
+			l.level++
+			l.store.Retain(l.keeps)
+		}
+		if !l.keeps(idHash) || l.store.Len() >= l.maxLogs {
+			return nil
+		}
+	}
+	return l.store.Append(lg)
+}
+
+// SamplingRate returns the expected ratio of logs kept in the store. It returns 1 when no log was dropped.
+func (l *LogLimiter) SamplingRate() float64 {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	return 1 / float64(uint64(1)<<l.level)
+}
+
+// ErrorMessage returns the message shown on the frontend when logs were sampled. It returns nil when no log was dropped.
+func (l *LogLimiter) ErrorMessage() *error_metadata.ErrorMessage {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if l.level == 0 {
+		return nil
+	}
+	return newLogSampledErrorMessage(l.maxLogs)
+}
+
+func (l *LogLimiter) keeps(idHash uint64) bool {
+	return idHash&(uint64(1)<<l.level-1) == 0
+}
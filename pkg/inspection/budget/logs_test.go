@@ -0,0 +1,95 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package budget
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/log"
+	"github.com/GoogleCloudPlatform/khi/pkg/log/logstore"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/history/grouper"
+	gcp_log "github.com/GoogleCloudPlatform/khi/pkg/source/gcp/log"
+
+	_ "github.com/GoogleCloudPlatform/khi/internal/testflags"
+)
+
+func newTestLog(t *testing.T, index int) *log.Log {
+	t.Helper()
+	timestamp := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC).Add(time.Duration(index) * time.Second)
+	l, err := log.NewLogFromYAMLString(fmt.Sprintf("insertId: id-%d\ntimestamp: %s\n", index, timestamp.Format(time.RFC3339)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := l.SetFieldSetReader(&gcp_log.GCPCommonFieldSetReader{}); err != nil {
+		t.Fatal(err)
+	}
+	return l
+}
+
+func TestLogLimiter(t *testing.T) {
+	t.Run("unlimited", func(t *testing.T) {
+		store := logstore.NewStore(t.TempDir(), grouper.AllDependentLogGrouper)
+		defer store.Close()
+		limiter := (&Budget{}).NewLogLimiter(store)
+		for i := 0; i < 100; i++ {
+			if err := limiter.Append(newTestLog(t, i)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if store.Len() != 100 {
+			t.Errorf("Len() = %d, want 100", store.Len())
+		}
+		if limiter.ErrorMessage() != nil {
+			t.Errorf("ErrorMessage() returned a message without sampling")
+		}
+	})
+	t.Run("sample", func(t *testing.T) {
+		store := logstore.NewStore(t.TempDir(), grouper.AllDependentLogGrouper)
+		defer store.Close()
+		limiter := (&Budget{MaxLogsPerQuery: 100, LogCountAction: LogCountActionSample}).NewLogLimiter(store)
+		for i := 0; i < 1000; i++ {
+			if err := limiter.Append(newTestLog(t, i)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if store.Len() > 100 || store.Len() == 0 {
+			t.Errorf("Len() = %d, want a count within (0,100]", store.Len())
+		}
+		if rate := limiter.SamplingRate(); rate >= 1 {
+			t.Errorf("SamplingRate() = %f, want less than 1", rate)
+		}
+		if limiter.ErrorMessage() == nil {
+			t.Errorf("ErrorMessage() returned nil after sampling")
+		}
+	})
+	t.Run("stop", func(t *testing.T) {
+		store := logstore.NewStore(t.TempDir(), grouper.AllDependentLogGrouper)
+		defer store.Close()
+		limiter := (&Budget{MaxLogsPerQuery: 10, LogCountAction: LogCountActionStop}).NewLogLimiter(store)
+		var err error
+		for i := 0; i < 11 && err == nil; i++ {
+			err = limiter.Append(newTestLog(t, i))
+		}
+		if !errors.Is(err, ErrLogCountExceeded) {
+			t.Errorf("Append() over the limit returned %v, want ErrLogCountExceeded", err)
+		}
+		if store.Len() != 10 {
+			t.Errorf("Len() = %d, want 10", store.Len())
+		}
+	})
+}
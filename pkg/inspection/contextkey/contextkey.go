@@ -16,7 +16,10 @@ package inspection_task_contextkey
 
 import (
 	"github.com/GoogleCloudPlatform/khi/pkg/common/typedmap"
+	"github.com/GoogleCloudPlatform/khi/pkg/inspection/budget"
+	"github.com/GoogleCloudPlatform/khi/pkg/inspection/credential"
 	inspection_task_interface "github.com/GoogleCloudPlatform/khi/pkg/inspection/interface"
+	"github.com/GoogleCloudPlatform/khi/pkg/log/logstore"
 )
 
 // InspectionTaskMode is the context key to access the execution mode of the inspection task.
@@ -39,6 +42,10 @@ var GlobalSharedMap = typedmap.NewTypedKey[*typedmap.TypedMap]("khi.google.com/i
 // This ID remains the same for all runs within a single inspection session.
 var InspectionTaskInspectionID = typedmap.NewTypedKey[string]("khi.google.com/inspection/inspection-id")
 
+// InspectionTaskOwner is the context key to access the name of the principal who owns the current inspection.
+// It is empty when the inspection has no owner such as inspections run by the scheduler or the server without authentication.
+var InspectionTaskOwner = typedmap.NewTypedKey[string]("khi.google.com/inspection/owner")
+
 // InspectionTaskRunID is the context key to access the unique identifier for the current task run.
 // A new run ID is generated each time an inspection task is executed, allowing differentiation
 // between multiple executions of the same inspection.
@@ -48,3 +55,20 @@ var InspectionTaskRunID = typedmap.NewTypedKey[string]("khi.google.com/inspectio
 // This map stores supplementary data beyond the main task results, such as logs and progress information.
 // It is expected to be serialized and passed to the frontend for display.
 var InspectionRunMetadata = typedmap.NewTypedKey[*typedmap.ReadonlyTypedMap]("khi.google.com/inspection/metadata-map")
+
+// InspectionBudget is the context key to access the resource budget of the current inspection run.
+var InspectionBudget = typedmap.NewTypedKey[*budget.Budget]("khi.google.com/inspection/budget")
+
+// InspectionVolumeEstimation is the context key to access whether the dry run should estimate the count of logs returned from queries.
+var InspectionVolumeEstimation = typedmap.NewTypedKey[bool]("khi.google.com/inspection/volume-estimation")
+
+// InspectionSampling is the context key to access the sampling settings for each feature task ID.
+var InspectionSampling = typedmap.NewTypedKey[map[string]*logstore.Sampling]("khi.google.com/inspection/sampling")
+
+// InspectionCredential is the context key to access the GCP credential given by the user starting the current inspection run.
+// The value is absent when the request has no credential.
+var InspectionCredential = typedmap.NewTypedKey[*credential.Credential]("khi.google.com/inspection/credential")
+
+// InspectionCredentialCache is the context key to access the cache of values derived from credentials like token stores.
+// The cache is cleared when the inspection run ends.
+var InspectionCredentialCache = typedmap.NewTypedKey[*credential.Cache]("khi.google.com/inspection/credential-cache")
@@ -0,0 +1,95 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package credential
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// ErrUserCredentialRequired is returned when an inspection needs GCP credential but it was started without any credential of the user while the server doesn't allow using its own credential.
+var ErrUserCredentialRequired = errors.New("the inspection must be started with a GCP credential of the user")
+
+// Credential is the GCP credential given by the user starting an inspection.
+// Requests to GCP APIs in the inspection are sent with this credential instead of the credential of the KHI process.
+type Credential struct {
+	// AccessToken is an access token of the user.
+	AccessToken string `json:"accessToken,omitempty"`
+	// ImpersonateServiceAccount is the email of the service account to impersonate.
+	// The service account token is obtained with AccessToken. The credential of the KHI process is used only when AccessToken is empty and the server allows it explicitly.
+	ImpersonateServiceAccount string `json:"impersonateServiceAccount,omitempty"`
+}
+
+// Validate returns an error when the credential can't be used to authenticate requests.
+func (c *Credential) Validate() error {
+	if c.AccessToken == "" && c.ImpersonateServiceAccount == "" {
+		return fmt.Errorf("credential must have accessToken or impersonateServiceAccount")
+	}
+	if c.ImpersonateServiceAccount != "" && !strings.Contains(c.ImpersonateServiceAccount, "@") {
+		return fmt.Errorf("impersonateServiceAccount must be an email of a service account but %q was given", c.ImpersonateServiceAccount)
+	}
+	return nil
+}
+
+// Identity returns a string identifying who sends requests with this credential. The access token itself is never included in the result.
+// The identity of an impersonation includes the access token used to impersonate, so users who can't impersonate the service account never share results cached for the ones who can.
+func (c *Credential) Identity() string {
+	tokenIdentity := "process"
+	if c.AccessToken != "" {
+		sum := sha256.Sum256([]byte(c.AccessToken))
+		tokenIdentity = fmt.Sprintf("token:%x", sum[:8])
+	}
+	if c.ImpersonateServiceAccount != "" {
+		return fmt.Sprintf("impersonate:%s#%s", c.ImpersonateServiceAccount, tokenIdentity)
+	}
+	return tokenIdentity
+}
+
+// Cache holds values derived from credentials like token stores only during the lifetime of an inspection.
+type Cache struct {
+	lock   sync.Mutex
+	values map[string]any
+}
+
+// NewCache returns an empty Cache.
+func NewCache() *Cache {
+	return &Cache{
+		values: map[string]any{},
+	}
+}
+
+// GetOrCreate returns the value cached with the key. The value is created with the given function when it's not cached yet.
+func GetOrCreate[T any](c *Cache, key string, create func() T) T {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if value, found := c.values[key]; found {
+		if typed, ok := value.(T); ok {
+			return typed
+		}
+	}
+	value := create()
+	c.values[key] = value
+	return value
+}
+
+// Clear discards every cached value. Values created after calling Clear are cached again.
+func (c *Cache) Clear() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.values = map[string]any{}
+}
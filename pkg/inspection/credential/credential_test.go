@@ -0,0 +1,103 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package credential
+
+import (
+	"strings"
+	"testing"
+
+	_ "github.com/GoogleCloudPlatform/khi/internal/testflags"
+)
+
+func TestCredentialValidate(t *testing.T) {
+	testCases := []struct {
+		name       string
+		credential *Credential
+		wantErr    bool
+	}{
+		{
+			name:       "access token",
+			credential: &Credential{AccessToken: "foo"},
+		},
+		{
+			name:       "impersonation",
+			credential: &Credential{ImpersonateServiceAccount: "khi@project.iam.gserviceaccount.com"},
+		},
+		{
+			name:       "impersonation with access token",
+			credential: &Credential{AccessToken: "foo", ImpersonateServiceAccount: "khi@project.iam.gserviceaccount.com"},
+		},
+		{
+			name:       "empty",
+			credential: &Credential{},
+			wantErr:    true,
+		},
+		{
+			name:       "malformed service account",
+			credential: &Credential{ImpersonateServiceAccount: "khi"},
+			wantErr:    true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.credential.Validate()
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestCredentialIdentity(t *testing.T) {
+	tokenA := (&Credential{AccessToken: "token-a"}).Identity()
+	tokenB := (&Credential{AccessToken: "token-b"}).Identity()
+	if tokenA == tokenB {
+		t.Errorf("Identity() returned the same identity %q for different access tokens", tokenA)
+	}
+	if !strings.HasPrefix(tokenA, "token:") || strings.Contains(tokenA, "token-a") {
+		t.Errorf("Identity() = %q, want a digest of the access token", tokenA)
+	}
+	impersonationA := (&Credential{AccessToken: "token-a", ImpersonateServiceAccount: "khi@project.iam.gserviceaccount.com"}).Identity()
+	if impersonationA != "impersonate:khi@project.iam.gserviceaccount.com#"+tokenA {
+		t.Errorf("Identity() = %q, want the impersonated service account with the identity of the access token", impersonationA)
+	}
+	impersonationB := (&Credential{AccessToken: "token-b", ImpersonateServiceAccount: "khi@project.iam.gserviceaccount.com"}).Identity()
+	if impersonationA == impersonationB {
+		t.Errorf("Identity() returned the same identity %q for impersonations with different access tokens", impersonationA)
+	}
+	processImpersonation := (&Credential{ImpersonateServiceAccount: "khi@project.iam.gserviceaccount.com"}).Identity()
+	if processImpersonation != "impersonate:khi@project.iam.gserviceaccount.com#process" {
+		t.Errorf("Identity() = %q, want the impersonation with the credential of the KHI process", processImpersonation)
+	}
+}
+
+func TestCache(t *testing.T) {
+	cache := NewCache()
+	createCount := 0
+	create := func() int {
+		createCount++
+		return createCount
+	}
+	if got := GetOrCreate(cache, "foo", create); got != 1 {
+		t.Errorf("GetOrCreate() = %d, want 1", got)
+	}
+	if got := GetOrCreate(cache, "foo", create); got != 1 {
+		t.Errorf("GetOrCreate() = %d, want the cached value 1", got)
+	}
+	cache.Clear()
+	if got := GetOrCreate(cache, "foo", create); got != 2 {
+		t.Errorf("GetOrCreate() after Clear() = %d, want 2", got)
+	}
+}
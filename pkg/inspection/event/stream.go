@@ -0,0 +1,195 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package event
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+
+	error_metadata "github.com/GoogleCloudPlatform/khi/pkg/inspection/metadata/error"
+	"github.com/GoogleCloudPlatform/khi/pkg/inspection/metadata/logger"
+	"github.com/GoogleCloudPlatform/khi/pkg/inspection/metadata/progress"
+)
+
+// Type is the kind of an Event.
+type Type string
+
+const (
+	// TypeProgress is the event of an updated task progress including the total progress. Its data is progress.TaskProgress.
+	TypeProgress Type = "progress"
+	// TypeTaskResolved is the event of a task finished and removed from the progress. Its data is TaskResolvedData.
+	TypeTaskResolved Type = "task-resolved"
+	// TypeLog is the event of a new log line of a task. Its data is LogData.
+	TypeLog Type = "log"
+	// TypeError is the event of a new error message shown on the frontend. Its data is error_metadata.ErrorMessage.
+	TypeError Type = "error"
+	// TypePhase is the event of a phase transition of the inspection. Its data is PhaseData.
+	TypePhase Type = "phase"
+)
+
+// Event is a change of an inspection run pushed to clients.
+type Event struct {
+	// ID is the sequential ID of the event in the stream starting from 1. Clients resume the stream after this ID.
+	ID   uint64
+	Type Type
+	// Data is the JSON serializable payload of the event.
+	Data any
+}
+
+// TaskResolvedData is the payload of TypeTaskResolved events.
+type TaskResolvedData struct {
+	TaskID string `json:"taskId"`
+}
+
+// LogData is the payload of TypeLog events.
+type LogData struct {
+	TaskID  string    `json:"taskId"`
+	Time    time.Time `json:"time"`
+	Level   string    `json:"level"`
+	Message string    `json:"message"`
+}
+
+// PhaseData is the payload of TypePhase events.
+type PhaseData struct {
+	Phase string `json:"phase"`
+}
+
+// Batch is the result of Stream.Since.
+type Batch struct {
+	// Events is the list of buffered events after the requested ID.
+	Events []*Event
+	// Truncated is true when some events after the requested ID were already dropped from the buffer.
+	// Clients need to read the whole state from the metadata again.
+	Truncated bool
+	// Closed is true when no more events will be published to the stream.
+	Closed bool
+	// Changed is closed when a new event is published or the stream is closed.
+	Changed <-chan struct{}
+}
+
+// Stream is the buffer of events published during an inspection run.
+// It receives the changes from the progress, the task loggers and the error message set of the run.
+type Stream struct {
+	lock              sync.Mutex
+	maxBufferedEvents int
+	events            []*Event
+	lastID            uint64
+	closed            bool
+	changed           chan struct{}
+}
+
+var _ progress.Listener = (*Stream)(nil)
+var _ logger.Listener = (*Stream)(nil)
+var _ error_metadata.Listener = (*Stream)(nil)
+
+// NewStream returns a Stream keeping the latest events up to the given count.
+func NewStream(maxBufferedEvents int) *Stream {
+	return &Stream{
+		maxBufferedEvents: maxBufferedEvents,
+		events:            []*Event{},
+		changed:           make(chan struct{}),
+	}
+}
+
+// Publish adds a new event to the stream. Events published after closing the stream are ignored.
+func (s *Stream) Publish(eventType Type, data any) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.closed {
+		return
+	}
+	s.lastID++
+	s.events = append(s.events, &Event{
+		ID:   s.lastID,
+		Type: eventType,
+		Data: data,
+	})
+	if len(s.events) > s.maxBufferedEvents {
+		s.events = s.events[len(s.events)-s.maxBufferedEvents:]
+	}
+	s.notifyChangedWithoutLock()
+}
+
+// Close marks the end of the stream. Clients receive the buffered events and then the stream ends.
+func (s *Stream) Close() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.closed {
+		return
+	}
+	s.closed = true
+	s.notifyChangedWithoutLock()
+}
+
+// Since returns the buffered events published after the event with the given ID. Giving 0 returns all the buffered events.
+func (s *Stream) Since(lastID uint64) *Batch {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	batch := &Batch{
+		Events:  []*Event{},
+		Closed:  s.closed,
+		Changed: s.changed,
+	}
+	// An ID newer than the stream means the client read another stream before.
+	if lastID > s.lastID {
+		lastID = 0
+		batch.Truncated = true
+	}
+	if len(s.events) > 0 && s.events[0].ID > lastID+1 {
+		batch.Truncated = true
+	}
+	for _, event := range s.events {
+		if event.ID > lastID {
+			batch.Events = append(batch.Events, event)
+		}
+	}
+	return batch
+}
+
+func (s *Stream) notifyChangedWithoutLock() {
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
+
+// OnTaskProgressUpdated implements progress.Listener.
+func (s *Stream) OnTaskProgressUpdated(taskProgress progress.TaskProgress) {
+	s.Publish(TypeProgress, &taskProgress)
+}
+
+// OnTaskResolved implements progress.Listener.
+func (s *Stream) OnTaskResolved(id string) {
+	s.Publish(TypeTaskResolved, &TaskResolvedData{TaskID: id})
+}
+
+// OnPhaseChanged implements progress.Listener.
+func (s *Stream) OnPhaseChanged(phase string) {
+	s.Publish(TypePhase, &PhaseData{Phase: phase})
+}
+
+// OnTaskLog implements logger.Listener.
+func (s *Stream) OnTaskLog(taskID string, record slog.Record) {
+	s.Publish(TypeLog, &LogData{
+		TaskID:  taskID,
+		Time:    record.Time,
+		Level:   record.Level.String(),
+		Message: record.Message,
+	})
+}
+
+// OnErrorMessageAdded implements error_metadata.Listener.
+func (s *Stream) OnErrorMessageAdded(message *error_metadata.ErrorMessage) {
+	s.Publish(TypeError, message)
+}
@@ -0,0 +1,120 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package event
+
+import (
+	"log/slog"
+	"testing"
+	"time"
+
+	error_metadata "github.com/GoogleCloudPlatform/khi/pkg/inspection/metadata/error"
+	"github.com/GoogleCloudPlatform/khi/pkg/inspection/metadata/progress"
+	"github.com/google/go-cmp/cmp"
+
+	_ "github.com/GoogleCloudPlatform/khi/internal/testflags"
+)
+
+func eventIDs(events []*Event) []uint64 {
+	result := []uint64{}
+	for _, event := range events {
+		result = append(result, event.ID)
+	}
+	return result
+}
+
+func TestStreamSince(t *testing.T) {
+	testCases := []struct {
+		name          string
+		publishCount  int
+		lastID        uint64
+		wantIDs       []uint64
+		wantTruncated bool
+	}{
+		{
+			name:         "from the beginning",
+			publishCount: 3,
+			lastID:       0,
+			wantIDs:      []uint64{1, 2, 3},
+		},
+		{
+			name:         "resume after an event",
+			publishCount: 3,
+			lastID:       2,
+			wantIDs:      []uint64{3},
+		},
+		{
+			name:         "resume after the last event",
+			publishCount: 3,
+			lastID:       3,
+			wantIDs:      []uint64{},
+		},
+		{
+			name:          "resume after dropped events",
+			publishCount:  10,
+			lastID:        2,
+			wantIDs:       []uint64{6, 7, 8, 9, 10},
+			wantTruncated: true,
+		},
+		{
+			name:          "resume with an ID from another stream",
+			publishCount:  3,
+			lastID:        100,
+			wantIDs:       []uint64{1, 2, 3},
+			wantTruncated: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			stream := NewStream(5)
+			for i := 0; i < tc.publishCount; i++ {
+				stream.OnPhaseChanged(progress.TASK_PHASE_RUNNING)
+			}
+			batch := stream.Since(tc.lastID)
+			if diff := cmp.Diff(tc.wantIDs, eventIDs(batch.Events)); diff != "" {
+				t.Errorf("event IDs mismatch (-want +got):\n%s", diff)
+			}
+			if batch.Truncated != tc.wantTruncated {
+				t.Errorf("Truncated = %v, want %v", batch.Truncated, tc.wantTruncated)
+			}
+		})
+	}
+}
+
+func TestStreamNotifiesChanges(t *testing.T) {
+	stream := NewStream(10)
+	batch := stream.Since(0)
+	go stream.OnErrorMessageAdded(&error_metadata.ErrorMessage{ErrorId: 1, Message: "foo"})
+	select {
+	case <-batch.Changed:
+	case <-time.After(time.Second):
+		t.Fatal("Changed channel was not closed after publishing an event")
+	}
+	batch = stream.Since(0)
+	if diff := cmp.Diff([]*Event{{ID: 1, Type: TypeError, Data: &error_metadata.ErrorMessage{ErrorId: 1, Message: "foo"}}}, batch.Events); diff != "" {
+		t.Errorf("events mismatch (-want +got):\n%s", diff)
+	}
+
+	stream.Close()
+	select {
+	case <-batch.Changed:
+	case <-time.After(time.Second):
+		t.Fatal("Changed channel was not closed after closing the stream")
+	}
+	stream.OnTaskLog("task-a", slog.NewRecord(time.Now(), slog.LevelInfo, "ignored", 0))
+	batch = stream.Since(1)
+	if !batch.Closed || len(batch.Events) != 0 {
+		t.Errorf("Since() after closing = %+v, want a closed batch without events", batch)
+	}
+}
@@ -30,6 +30,7 @@ import (
 type FileFormTaskBuilder struct {
 	FormTaskBuilderBase[upload.UploadResult]
 	verifier upload.UploadFileVerifier
+	multiple bool
 }
 
 func NewFileFormTaskBuilder(id taskid.TaskImplementationID[upload.UploadResult], priority int, label string, verifier upload.UploadFileVerifier) *FileFormTaskBuilder {
@@ -51,6 +52,12 @@ func (b *FileFormTaskBuilder) WithDescription(description string) *FileFormTaskB
 	return b
 }
 
+// WithMultipleFiles allows the client to upload several files at once. The verifier and the reader of the file must accept the archive written from the uploaded files.
+func (b *FileFormTaskBuilder) WithMultipleFiles() *FileFormTaskBuilder {
+	b.multiple = true
+	return b
+}
+
 func (b *FileFormTaskBuilder) Build(labelOpts ...common_task.LabelOpt) common_task.Task[upload.UploadResult] {
 	return common_task.NewTask(b.id, b.dependencies, func(ctx context.Context) (upload.UploadResult, error) {
 		metadata := khictx.MustGetValue(ctx, inspection_task_contextkey.InspectionRunMetadata)
@@ -66,8 +73,9 @@ func (b *FileFormTaskBuilder) Build(labelOpts ...common_task.LabelOpt) common_ta
 				HintType: form_metadata.None,
 				Hint:     "",
 			},
-			Token:  token,
-			Status: uploadResult.Status,
+			Token:    token,
+			Status:   uploadResult.Status,
+			Multiple: b.multiple,
 		}
 		b.SetupBaseFormField(&field.ParameterFormFieldBase)
 
@@ -0,0 +1,121 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jobspec
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/inspection/inspectiondata"
+	"github.com/GoogleCloudPlatform/khi/pkg/inspection/task/exporter"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/history/reader"
+)
+
+// OutputFormat is the format of an output file.
+type OutputFormat string
+
+const (
+	// OutputFormatKHI writes the .khi file as is.
+	OutputFormatKHI OutputFormat = "khi"
+	// OutputFormatJSONL writes normalized tables in JSON lines.
+	OutputFormatJSONL OutputFormat = OutputFormat(exporter.FormatJSONL)
+	// OutputFormatCSV writes normalized tables in CSV.
+	OutputFormatCSV OutputFormat = OutputFormat(exporter.FormatCSV)
+)
+
+// Output is a file written from the inspection result.
+type Output struct {
+	// Path is the destination file path.
+	Path string `yaml:"path" json:"path"`
+	// Format is the format of the file. The default is `khi`.
+	Format OutputFormat `yaml:"format" json:"format"`
+	// Table is the normalized table written for `jsonl` or `csv` format. Every table is written in a zip archive when it's empty.
+	Table string `yaml:"table" json:"table"`
+}
+
+// Validate checks the fields of the output and fills the default format.
+func (o *Output) Validate() error {
+	if o.Path == "" {
+		return fmt.Errorf("path is required")
+	}
+	if o.Format == "" {
+		o.Format = OutputFormatKHI
+	}
+	o.Format = OutputFormat(strings.ToLower(string(o.Format)))
+	switch o.Format {
+	case OutputFormatKHI:
+		if o.Table != "" {
+			return fmt.Errorf("table is not supported for the %s format", OutputFormatKHI)
+		}
+	case OutputFormatJSONL, OutputFormatCSV:
+		if o.Table != "" {
+			if _, err := exporter.ParseTable(o.Table); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("unsupported output format %q. supported formats are %q, %q and %q", o.Format, OutputFormatKHI, OutputFormatJSONL, OutputFormatCSV)
+	}
+	return nil
+}
+
+// Write writes the inspection result in the store to the output path.
+func (o *Output) Write(store inspectiondata.Store) error {
+	if err := os.MkdirAll(filepath.Dir(o.Path), 0755); err != nil {
+		return fmt.Errorf("failed to create the output folder\n%w", err)
+	}
+	file, err := os.Create(o.Path)
+	if err != nil {
+		return fmt.Errorf("failed to open the output file %s\n%w", o.Path, err)
+	}
+	defer file.Close()
+	if err := o.write(store, file); err != nil {
+		return fmt.Errorf("failed to write the output file %s\n%w", o.Path, err)
+	}
+	return file.Close()
+}
+
+func (o *Output) write(store inspectiondata.Store, writer io.Writer) error {
+	if o.Format == OutputFormatKHI {
+		resultReader, err := store.GetReader()
+		if err != nil {
+			return err
+		}
+		defer resultReader.Close()
+		_, err = io.Copy(writer, resultReader)
+		return err
+	}
+	khiFile, err := reader.OpenStore(store)
+	if err != nil {
+		return err
+	}
+	defer khiFile.Close()
+	tableExporter, err := exporter.NewExporter(khiFile)
+	if err != nil {
+		return err
+	}
+	format := exporter.Format(o.Format)
+	if o.Table == "" {
+		return tableExporter.ExportArchive(format, writer)
+	}
+	table, err := exporter.ParseTable(o.Table)
+	if err != nil {
+		return err
+	}
+	return tableExporter.ExportTable(table, format, writer)
+}
@@ -0,0 +1,122 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jobspec
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/inspection/inspectiondata"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/binarychunk"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/enum"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/history"
+	"github.com/GoogleCloudPlatform/khi/pkg/testutil/khifile"
+	"github.com/google/go-cmp/cmp"
+
+	_ "github.com/GoogleCloudPlatform/khi/internal/testflags"
+)
+
+func newTestResultStore(t *testing.T) (inspectiondata.Store, []byte) {
+	t.Helper()
+	chunk := binarychunk.NewBuilder(binarychunk.NewFileSystemGzipCompressor("/tmp"), "/tmp")
+	body, err := chunk.Write([]byte("foo: bar"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	summary, err := chunk.Write([]byte("create pod"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := history.NewHistory()
+	h.Logs = []*history.SerializableLog{
+		{
+			ID:        "log-1",
+			DisplayId: "insert-1",
+			Timestamp: time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC),
+			Body:      body,
+			Summary:   summary,
+			Type:      enum.LogTypeAudit,
+			Severity:  enum.SeverityInfo,
+		},
+	}
+	data := khifile.MustGenerateKHIFile(h, chunk)
+	resultPath := filepath.Join(t.TempDir(), "result.khi")
+	if err := os.WriteFile(resultPath, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return inspectiondata.NewFileSystemInspectionResultRepository(resultPath), data
+}
+
+func TestOutputWrite(t *testing.T) {
+	store, khiData := newTestResultStore(t)
+	outputFolder := t.TempDir()
+	testCases := []struct {
+		name   string
+		output *Output
+		want   string
+	}{
+		{
+			name:   "khi",
+			output: &Output{Path: filepath.Join(outputFolder, "nested", "out.khi")},
+			want:   string(khiData),
+		},
+		{
+			name:   "csv table",
+			output: &Output{Path: filepath.Join(outputFolder, "logs.csv"), Format: "csv", Table: "logs"},
+			want:   "id,display_id,timestamp,type,severity,summary,body\nlog-1,insert-1,2025-01-01T00:00:00Z,LogTypeAudit,SeverityInfo,create pod,foo: bar\n",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := tc.output.Validate(); err != nil {
+				t.Fatal(err)
+			}
+			if err := tc.output.Write(store); err != nil {
+				t.Fatal(err)
+			}
+			got, err := os.ReadFile(tc.output.Path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.want, string(got)); diff != "" {
+				t.Errorf("output mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestOutputValidate(t *testing.T) {
+	testCases := []struct {
+		name    string
+		output  *Output
+		wantErr bool
+	}{
+		{name: "default format", output: &Output{Path: "/tmp/a.khi"}},
+		{name: "jsonl archive", output: &Output{Path: "/tmp/a.zip", Format: "JSONL"}},
+		{name: "no path", output: &Output{Format: "khi"}, wantErr: true},
+		{name: "unknown format", output: &Output{Path: "/tmp/a", Format: "parquet"}, wantErr: true},
+		{name: "unknown table", output: &Output{Path: "/tmp/a", Format: "csv", Table: "pods"}, wantErr: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.output.Validate()
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
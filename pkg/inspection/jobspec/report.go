@@ -0,0 +1,131 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jobspec
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/inspection"
+	form_metadata "github.com/GoogleCloudPlatform/khi/pkg/inspection/metadata/form"
+	inspection_task "github.com/GoogleCloudPlatform/khi/pkg/inspection/task"
+)
+
+// FieldReport is the validation result of a form value.
+type FieldReport struct {
+	// ID is the ID of the form field or the key of the value given in the spec.
+	ID string `json:"id"`
+	// Label is the label of the form field. It's empty when no form field uses the value.
+	Label string `json:"label"`
+	// Value is the value given in the spec. It's empty when the default value is used.
+	Value string `json:"value"`
+	// HintType is the type of the hint generated by the form task.
+	HintType form_metadata.ParameterHintType `json:"hintType"`
+	// Hint is the hint message generated by the form task.
+	Hint string `json:"hint"`
+}
+
+// ValidationReport is the result of validating form values against the form tasks used by the selected features.
+type ValidationReport struct {
+	Fields []FieldReport `json:"fields"`
+}
+
+// Validate runs the inspection in dry run mode with the values to validate them with the validators of the form tasks.
+func Validate(ctx context.Context, runner *inspection.InspectionTaskRunner, values map[string]any) (*ValidationReport, error) {
+	result, err := runner.DryRun(ctx, &inspection_task.InspectionRequest{Values: values})
+	if err != nil {
+		return nil, fmt.Errorf("failed to run the inspection in dry run mode\n%w", err)
+	}
+	dryRunMetadata, ok := result.Metadata.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("unexpected dry run metadata type %T", result.Metadata)
+	}
+	fields, _ := dryRunMetadata[form_metadata.FormFieldSetMetadataKey.Key()].([]form_metadata.ParameterFormField)
+	return newValidationReport(fields, values), nil
+}
+
+// newValidationReport generates the report from the form fields generated in a dry run.
+// Values not used by any form field are reported as errors because they are likely typos of form IDs.
+func newValidationReport(fields []form_metadata.ParameterFormField, values map[string]any) *ValidationReport {
+	report := &ValidationReport{Fields: []FieldReport{}}
+	usedValues := map[string]struct{}{}
+	var addFields func(fields []form_metadata.ParameterFormField)
+	addFields = func(fields []form_metadata.ParameterFormField) {
+		for _, field := range fields {
+			if group, ok := field.(form_metadata.GroupParameterFormField); ok {
+				addFields(group.Children)
+				continue
+			}
+			base := form_metadata.GetParameterFormFieldBase(field)
+			fieldReport := FieldReport{
+				ID:       base.ID,
+				Label:    base.Label,
+				HintType: base.HintType,
+				Hint:     base.Hint,
+			}
+			if value, found := values[base.ID]; found {
+				usedValues[base.ID] = struct{}{}
+				fieldReport.Value = fmt.Sprint(value)
+			}
+			report.Fields = append(report.Fields, fieldReport)
+		}
+	}
+	addFields(fields)
+
+	unusedKeys := []string{}
+	for key := range values {
+		if _, found := usedValues[key]; !found {
+			unusedKeys = append(unusedKeys, key)
+		}
+	}
+	sort.Strings(unusedKeys)
+	for _, key := range unusedKeys {
+		report.Fields = append(report.Fields, FieldReport{
+			ID:       key,
+			Value:    fmt.Sprint(values[key]),
+			HintType: form_metadata.Error,
+			Hint:     "no form of the selected features uses this value",
+		})
+	}
+	return report
+}
+
+// HasError returns true when any field has an error hint.
+func (r *ValidationReport) HasError() bool {
+	for _, field := range r.Fields {
+		if field.HintType == form_metadata.Error {
+			return true
+		}
+	}
+	return false
+}
+
+// WriteText writes the report in a human readable table.
+func (r *ValidationReport) WriteText(writer io.Writer) error {
+	tw := tabwriter.NewWriter(writer, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "RESULT\tID\tLABEL\tVALUE\tHINT")
+	for _, field := range r.Fields {
+		value := field.Value
+		if value == "" {
+			value = "(default)"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", field.HintType, field.ID, field.Label, value, strings.ReplaceAll(field.Hint, "\n", " "))
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,89 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jobspec
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	form_metadata "github.com/GoogleCloudPlatform/khi/pkg/inspection/metadata/form"
+	"github.com/google/go-cmp/cmp"
+
+	_ "github.com/GoogleCloudPlatform/khi/internal/testflags"
+)
+
+func TestNewValidationReport(t *testing.T) {
+	fields := []form_metadata.ParameterFormField{
+		form_metadata.TextParameterFormField{
+			ParameterFormFieldBase: form_metadata.ParameterFormFieldBase{ID: "project_id", Label: "Project ID", HintType: form_metadata.None},
+		},
+		form_metadata.GroupParameterFormField{
+			Children: []form_metadata.ParameterFormField{
+				form_metadata.TextParameterFormField{
+					ParameterFormFieldBase: form_metadata.ParameterFormFieldBase{ID: "end_time", Label: "End time", HintType: form_metadata.Error, Hint: "invalid time format"},
+				},
+			},
+		},
+		form_metadata.TextParameterFormField{
+			ParameterFormFieldBase: form_metadata.ParameterFormFieldBase{ID: "duration", Label: "Duration", HintType: form_metadata.Warning, Hint: "long duration"},
+		},
+	}
+	values := map[string]any{
+		"project_id": "my-project",
+		"end_time":   "yesterday",
+		"projectid":  "typo",
+	}
+
+	report := newValidationReport(fields, values)
+
+	want := &ValidationReport{
+		Fields: []FieldReport{
+			{ID: "project_id", Label: "Project ID", Value: "my-project", HintType: form_metadata.None},
+			{ID: "end_time", Label: "End time", Value: "yesterday", HintType: form_metadata.Error, Hint: "invalid time format"},
+			{ID: "duration", Label: "Duration", HintType: form_metadata.Warning, Hint: "long duration"},
+			{ID: "projectid", Value: "typo", HintType: form_metadata.Error, Hint: "no form of the selected features uses this value"},
+		},
+	}
+	if diff := cmp.Diff(want, report); diff != "" {
+		t.Errorf("newValidationReport() mismatch (-want +got):\n%s", diff)
+	}
+	if !report.HasError() {
+		t.Errorf("HasError() = false, want true")
+	}
+
+	var buf bytes.Buffer
+	if err := report.WriteText(&buf); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 5 {
+		t.Fatalf("WriteText() wrote %d lines, want 5\n%s", len(lines), buf.String())
+	}
+	if !strings.HasPrefix(lines[3], "warning") || !strings.Contains(lines[3], "(default)") {
+		t.Errorf("WriteText() line for the default value = %q", lines[3])
+	}
+}
+
+func TestValidationReportHasNoError(t *testing.T) {
+	report := newValidationReport([]form_metadata.ParameterFormField{
+		form_metadata.TextParameterFormField{
+			ParameterFormFieldBase: form_metadata.ParameterFormFieldBase{ID: "project_id", HintType: form_metadata.Warning, Hint: "check the project"},
+		},
+	}, map[string]any{"project_id": "foo"})
+	if report.HasError() {
+		t.Errorf("HasError() = true, want false")
+	}
+}
@@ -0,0 +1,192 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jobspec
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/inspection"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/enum"
+	"gopkg.in/yaml.v3"
+)
+
+// VersionV1 is the first version of the job spec format.
+const VersionV1 = "v1"
+
+// supportedVersions is the list of spec versions this KHI can read.
+var supportedVersions = []string{VersionV1}
+
+// Spec is a declarative definition of an inspection run in job mode.
+// It is read from a YAML file. JSON is also accepted because it's a subset of YAML.
+type Spec struct {
+	// Version is the version of the spec format. It must be one of the supported versions.
+	Version string `yaml:"version" json:"version"`
+	// InspectionSpec is the inspection to run.
+	InspectionSpec `yaml:",inline"`
+	// Outputs is the list of files written from the inspection result.
+	Outputs []*Output `yaml:"outputs" json:"outputs"`
+}
+
+// InspectionSpec is the inspection to run. It is shared by the job spec of job mode and the jobs of the scheduler.
+type InspectionSpec struct {
+	// InspectionType is the ID of the inspection type to run.
+	InspectionType string `yaml:"inspectionType" json:"inspectionType"`
+	// Features selects the features to enable.
+	Features FeatureSelector `yaml:"features" json:"features"`
+	// Values is the form values of the inspection keyed by the form ID. Non string scalar values are converted to strings.
+	// Relative time expressions like `now-2h` are resolved when the inspection runs. See ResolveValues.
+	Values map[string]any `yaml:"values" json:"values"`
+}
+
+// FeatureSelector selects features available in the inspection type. The union of the selected features is enabled.
+type FeatureSelector struct {
+	// All enables every feature available in the inspection type.
+	All bool `yaml:"all" json:"all"`
+	// IDs is the list of feature task IDs.
+	IDs []string `yaml:"ids" json:"ids"`
+	// LogTypes selects features querying the log types. A log type is given with its label (e.g. `k8s_audit`) or its enum name (e.g. `LogTypeAudit`).
+	LogTypes []string `yaml:"logTypes" json:"logTypes"`
+}
+
+// Load reads and validates the spec file at the given path.
+func Load(path string) (*Spec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the job spec file %s\n%w", path, err)
+	}
+	return Parse(data)
+}
+
+// Parse parses and validates the spec from YAML or JSON data.
+func Parse(data []byte) (*Spec, error) {
+	var spec Spec
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&spec); err != nil {
+		return nil, fmt.Errorf("failed to parse the job spec\n%w", err)
+	}
+	if err := spec.Validate(); err != nil {
+		return nil, err
+	}
+	return &spec, nil
+}
+
+// Validate checks the fields of the spec not depending on the registered inspection types and normalizes its values.
+func (s *Spec) Validate() error {
+	versionSupported := false
+	for _, version := range supportedVersions {
+		if s.Version == version {
+			versionSupported = true
+		}
+	}
+	if !versionSupported {
+		return fmt.Errorf("unsupported job spec version %q. supported versions are %v", s.Version, supportedVersions)
+	}
+	if err := s.InspectionSpec.Validate(); err != nil {
+		return err
+	}
+	if len(s.Outputs) == 0 {
+		return fmt.Errorf("outputs must have at least an output")
+	}
+	for i, output := range s.Outputs {
+		if err := output.Validate(); err != nil {
+			return fmt.Errorf("output #%d is invalid\n%w", i, err)
+		}
+	}
+	return nil
+}
+
+// Validate checks the fields of the inspection not depending on the registered inspection types.
+// Non string scalar values are converted to strings because form tasks only accept strings.
+func (s *InspectionSpec) Validate() error {
+	if s.InspectionType == "" {
+		return fmt.Errorf("inspectionType is required")
+	}
+	if !s.Features.All && len(s.Features.IDs) == 0 && len(s.Features.LogTypes) == 0 {
+		return fmt.Errorf("features must select at least a feature with `all`, `ids` or `logTypes`")
+	}
+	for _, logType := range s.Features.LogTypes {
+		if _, err := parseLogType(logType); err != nil {
+			return err
+		}
+	}
+	values, err := normalizeValues(s.Values)
+	if err != nil {
+		return err
+	}
+	s.Values = values
+	return nil
+}
+
+// SelectFeatures returns the IDs of the features selected from the available features.
+func (s *InspectionSpec) SelectFeatures(availableFeatures []inspection.FeatureListItem) ([]string, error) {
+	available := map[string]inspection.FeatureListItem{}
+	for _, feature := range availableFeatures {
+		available[feature.Id] = feature
+	}
+	selected := map[string]struct{}{}
+	result := []string{}
+	selectFeature := func(id string) {
+		if _, found := selected[id]; found {
+			return
+		}
+		selected[id] = struct{}{}
+		result = append(result, id)
+	}
+	if s.Features.All {
+		for _, feature := range availableFeatures {
+			selectFeature(feature.Id)
+		}
+	}
+	for _, id := range s.Features.IDs {
+		if _, found := available[id]; !found {
+			return nil, fmt.Errorf("feature %s is not available in the inspection type %s", id, s.InspectionType)
+		}
+		selectFeature(id)
+	}
+	for _, logTypeStr := range s.Features.LogTypes {
+		logType, err := parseLogType(logTypeStr)
+		if err != nil {
+			return nil, err
+		}
+		found := false
+		for _, feature := range availableFeatures {
+			if feature.LogType == logType {
+				selectFeature(feature.Id)
+				found = true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("no feature for the log type %s is available in the inspection type %s", logTypeStr, s.InspectionType)
+		}
+	}
+	return result, nil
+}
+
+// parseLogType returns the LogType from its label or its enum name.
+func parseLogType(logType string) (enum.LogType, error) {
+	for value, metadata := range enum.LogTypes {
+		if value == enum.LogTypeUnknown {
+			continue
+		}
+		if strings.EqualFold(metadata.Label, logType) || strings.EqualFold(metadata.EnumKeyName, logType) {
+			return value, nil
+		}
+	}
+	return enum.LogTypeUnknown, fmt.Errorf("unknown log type %q", logType)
+}
@@ -0,0 +1,232 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jobspec
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/inspection"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/enum"
+	"github.com/google/go-cmp/cmp"
+
+	_ "github.com/GoogleCloudPlatform/khi/internal/testflags"
+)
+
+func TestParse(t *testing.T) {
+	testCases := []struct {
+		name        string
+		input       string
+		want        *Spec
+		wantErrPart string
+	}{
+		{
+			name: "yaml",
+			input: `
+version: v1
+inspectionType: gcp-gke
+features:
+  ids: [foo]
+  logTypes: [k8s_audit, LogTypeEvent]
+values:
+  project_id: my-project
+  end_time: now
+  limit: 10
+  enabled: true
+outputs:
+- path: /tmp/out.khi
+- path: /tmp/logs.csv
+  format: CSV
+  table: logs
+`,
+			want: &Spec{
+				Version: VersionV1,
+				InspectionSpec: InspectionSpec{
+					InspectionType: "gcp-gke",
+					Features: FeatureSelector{
+						IDs:      []string{"foo"},
+						LogTypes: []string{"k8s_audit", "LogTypeEvent"},
+					},
+					Values: map[string]any{
+						"project_id": "my-project",
+						"end_time":   "now",
+						"limit":      "10",
+						"enabled":    "true",
+					},
+				},
+				Outputs: []*Output{
+					{Path: "/tmp/out.khi", Format: OutputFormatKHI},
+					{Path: "/tmp/logs.csv", Format: OutputFormatCSV, Table: "logs"},
+				},
+			},
+		},
+		{
+			name:  "json",
+			input: `{"version":"v1","inspectionType":"gcp-gke","features":{"all":true},"values":{},"outputs":[{"path":"/tmp/all.zip","format":"jsonl"}]}`,
+			want: &Spec{
+				Version: VersionV1,
+				InspectionSpec: InspectionSpec{
+					InspectionType: "gcp-gke",
+					Features:       FeatureSelector{All: true},
+					Values:         map[string]any{},
+				},
+				Outputs: []*Output{
+					{Path: "/tmp/all.zip", Format: OutputFormatJSONL},
+				},
+			},
+		},
+		{
+			name: "unsupported version",
+			input: `
+version: v2
+inspectionType: gcp-gke
+features: {all: true}
+outputs: [{path: /tmp/out.khi}]
+`,
+			wantErrPart: "unsupported job spec version",
+		},
+		{
+			name: "no feature",
+			input: `
+version: v1
+inspectionType: gcp-gke
+outputs: [{path: /tmp/out.khi}]
+`,
+			wantErrPart: "at least a feature",
+		},
+		{
+			name: "unknown log type",
+			input: `
+version: v1
+inspectionType: gcp-gke
+features: {logTypes: [k8s_foo]}
+outputs: [{path: /tmp/out.khi}]
+`,
+			wantErrPart: "unknown log type",
+		},
+		{
+			name: "no output",
+			input: `
+version: v1
+inspectionType: gcp-gke
+features: {all: true}
+`,
+			wantErrPart: "at least an output",
+		},
+		{
+			name: "table for khi output",
+			input: `
+version: v1
+inspectionType: gcp-gke
+features: {all: true}
+outputs: [{path: /tmp/out.khi, table: logs}]
+`,
+			wantErrPart: "table is not supported",
+		},
+		{
+			name: "unknown field",
+			input: `
+version: v1
+inspectionType: gcp-gke
+feature: {all: true}
+outputs: [{path: /tmp/out.khi}]
+`,
+			wantErrPart: "feature",
+		},
+		{
+			name: "non scalar value",
+			input: `
+version: v1
+inspectionType: gcp-gke
+features: {all: true}
+values:
+  project_id: [a, b]
+outputs: [{path: /tmp/out.khi}]
+`,
+			wantErrPart: "must be a scalar",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Parse([]byte(tc.input))
+			if tc.wantErrPart != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErrPart) {
+					t.Fatalf("Parse() error = %v, want an error containing %q", err, tc.wantErrPart)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Parse() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestInspectionSpecSelectFeatures(t *testing.T) {
+	availableFeatures := []inspection.FeatureListItem{
+		{Id: "audit", LogType: enum.LogTypeAudit},
+		{Id: "event", LogType: enum.LogTypeEvent},
+		{Id: "node", LogType: enum.LogTypeNode},
+		{Id: "container", LogType: enum.LogTypeContainer},
+	}
+	testCases := []struct {
+		name        string
+		selector    FeatureSelector
+		want        []string
+		wantErrPart string
+	}{
+		{
+			name:     "all",
+			selector: FeatureSelector{All: true},
+			want:     []string{"audit", "event", "node", "container"},
+		},
+		{
+			name:     "ids and log types without duplicates",
+			selector: FeatureSelector{IDs: []string{"node", "audit"}, LogTypes: []string{"k8s_audit", "LogTypeContainer"}},
+			want:     []string{"node", "audit", "container"},
+		},
+		{
+			name:        "unavailable id",
+			selector:    FeatureSelector{IDs: []string{"serialport"}},
+			wantErrPart: "not available",
+		},
+		{
+			name:        "unavailable log type",
+			selector:    FeatureSelector{LogTypes: []string{"compute_api"}},
+			wantErrPart: "no feature for the log type",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			spec := &InspectionSpec{InspectionType: "test", Features: tc.selector}
+			got, err := spec.SelectFeatures(availableFeatures)
+			if tc.wantErrPart != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErrPart) {
+					t.Fatalf("SelectFeatures() error = %v, want an error containing %q", err, tc.wantErrPart)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("SelectFeatures() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
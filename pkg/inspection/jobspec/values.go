@@ -0,0 +1,106 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jobspec
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// relativeTimePattern matches relative time expressions like `now`, `now-2h` or `now-1d12h`.
+var relativeTimePattern = regexp.MustCompile(`^now(?:([+-])([0-9][0-9a-z.]*))?$`)
+
+// ResolveValues returns a copy of the values with relative time expressions replaced with RFC3339 time relative to now.
+func ResolveValues(values map[string]any, now time.Time) (map[string]any, error) {
+	result := make(map[string]any, len(values))
+	for key, value := range values {
+		str, ok := value.(string)
+		if !ok {
+			result[key] = value
+			continue
+		}
+		resolved, matched, err := resolveRelativeTime(str, now)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve the value of %s\n%w", key, err)
+		}
+		if matched {
+			result[key] = resolved
+		} else {
+			result[key] = str
+		}
+	}
+	return result, nil
+}
+
+func resolveRelativeTime(expression string, now time.Time) (string, bool, error) {
+	match := relativeTimePattern.FindStringSubmatch(strings.TrimSpace(expression))
+	if match == nil {
+		return "", false, nil
+	}
+	offset := time.Duration(0)
+	if match[1] != "" {
+		d, err := parseDuration(match[2])
+		if err != nil {
+			return "", true, err
+		}
+		if match[1] == "-" {
+			d = -d
+		}
+		offset = d
+	}
+	return now.Add(offset).UTC().Format(time.RFC3339), true, nil
+}
+
+// parseDuration parses a duration accepting the `d` unit as 24 hours in addition to the units of time.ParseDuration.
+func parseDuration(value string) (time.Duration, error) {
+	days, rest, found := strings.Cut(value, "d")
+	if !found {
+		return time.ParseDuration(value)
+	}
+	dayCount, err := strconv.Atoi(days)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration %q", value)
+	}
+	d := time.Duration(dayCount) * 24 * time.Hour
+	if rest != "" {
+		restDuration, err := time.ParseDuration(rest)
+		if err != nil {
+			return 0, err
+		}
+		d += restDuration
+	}
+	return d, nil
+}
+
+// normalizeValues converts non string scalar values to strings because form tasks only accept strings.
+func normalizeValues(values map[string]any) (map[string]any, error) {
+	result := make(map[string]any, len(values))
+	for key, value := range values {
+		switch v := value.(type) {
+		case string:
+			result[key] = v
+		case int, int64, uint64, float64, bool:
+			result[key] = fmt.Sprint(v)
+		case nil:
+			result[key] = ""
+		default:
+			return nil, fmt.Errorf("value of %s must be a scalar but %T was given", key, value)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,56 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jobspec
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	_ "github.com/GoogleCloudPlatform/khi/internal/testflags"
+)
+
+func TestResolveValues(t *testing.T) {
+	now := time.Date(2025, time.January, 2, 3, 4, 5, 0, time.FixedZone("JST", 9*60*60))
+	got, err := ResolveValues(map[string]any{
+		"end_time":    "now",
+		"start_time":  "now-2h",
+		"future_time": "now+30m",
+		"days_ago":    "now-1d12h",
+		"duration":    "2h",
+		"project_id":  "now-project",
+		"count":       3,
+	}, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]any{
+		"end_time":    "2025-01-01T18:04:05Z",
+		"start_time":  "2025-01-01T16:04:05Z",
+		"future_time": "2025-01-01T18:34:05Z",
+		"days_ago":    "2024-12-31T06:04:05Z",
+		"duration":    "2h",
+		"project_id":  "now-project",
+		"count":       3,
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("ResolveValues() mismatch (-want +got):\n%s", diff)
+	}
+
+	if _, err := ResolveValues(map[string]any{"end_time": "now-2x"}, now); err == nil {
+		t.Errorf("ResolveValues() with an invalid duration returned nil error")
+	}
+}
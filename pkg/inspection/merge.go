@@ -0,0 +1,299 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inspection
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/inspection/inspectiondata"
+	error_metadata "github.com/GoogleCloudPlatform/khi/pkg/inspection/metadata/error"
+	"github.com/GoogleCloudPlatform/khi/pkg/inspection/metadata/header"
+	"github.com/GoogleCloudPlatform/khi/pkg/inspection/metadata/progress"
+	"github.com/GoogleCloudPlatform/khi/pkg/inspection/registry"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/history/merge"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/history/reader"
+	"github.com/GoogleCloudPlatform/khi/pkg/parameters"
+)
+
+// ErrNotEnoughInspectionsToMerge is returned when less than 2 inspections are given to merge.
+var ErrNotEnoughInspectionsToMerge = errors.New("at least 2 inspections are required to merge")
+
+// MergeInspections merges the results of the given finished inspections into a new inspection owned by the given principal and returns its ID.
+// The merged inspection is read-only and persisted in the registry when the registry is set.
+func (s *InspectionTaskServer) MergeInspections(ctx context.Context, inspectionIDs []string, owner string) (string, error) {
+	if len(inspectionIDs) < 2 {
+		return "", ErrNotEnoughInspectionsToMerge
+	}
+	sources := []*reader.Reader{}
+	defer func() {
+		for _, source := range sources {
+			source.Close()
+		}
+	}()
+	sourceRecords := []*registry.InspectionRecord{}
+	var firstStore *inspectiondata.FileSystemStore
+	for _, inspectionID := range inspectionIDs {
+		inspection := s.GetInspection(inspectionID)
+		if inspection == nil {
+			return "", fmt.Errorf("%w: %s", ErrInspectionNotFound, inspectionID)
+		}
+		if !inspection.Finished() {
+			return "", fmt.Errorf("%w: %s must be finished before merging it", ErrInspectionRunning, inspectionID)
+		}
+		result, err := inspection.Result()
+		if err != nil {
+			return "", fmt.Errorf("failed to get the result of the inspection %s\n%w", inspectionID, err)
+		}
+		store, ok := result.ResultStore.(*inspectiondata.FileSystemStore)
+		if !ok {
+			return "", fmt.Errorf("the result of the inspection %s is not persisted in the file system", inspectionID)
+		}
+		source, err := reader.Open(store.FilePath())
+		if err != nil {
+			return "", fmt.Errorf("failed to open the result of the inspection %s\n%w", inspectionID, err)
+		}
+		sources = append(sources, source)
+		sourceRecord, err := inspection.sourceRecordForMerge(store)
+		if err != nil {
+			return "", fmt.Errorf("failed to read the metadata of the inspection %s\n%w", inspectionID, err)
+		}
+		sourceRecords = append(sourceRecords, sourceRecord)
+		if firstStore == nil {
+			firstStore = store
+		}
+	}
+
+	mergedID := generateRandomString()
+	mergedStore := inspectiondata.NewFileSystemInspectionResultRepository(filepath.Join(filepath.Dir(firstStore.FilePath()), mergedID+".khi"))
+	writer, err := mergedStore.GetWriter()
+	if err != nil {
+		return "", err
+	}
+	temporaryFolder := "/tmp"
+	if parameters.Common.TemporaryFolder != nil {
+		temporaryFolder = *parameters.Common.TemporaryFolder
+	}
+	mergeResult, err := merge.Merge(ctx, sources, temporaryFolder, writer)
+	if closeErr := writer.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(mergedStore.FilePath())
+		return "", fmt.Errorf("failed to merge inspections\n%w", err)
+	}
+
+	record, err := newMergedInspectionRecord(mergedID, owner, sourceRecords, mergedStore.FilePath(), mergeResult.FileSize, time.Now())
+	if err != nil {
+		os.Remove(mergedStore.FilePath())
+		return "", err
+	}
+	s.addMergedInspection(record)
+	return mergedID, nil
+}
+
+// addMergedInspection registers the merged inspection as a restored read-only inspection and saves its record.
+func (s *InspectionTaskServer) addMergedInspection(record *registry.InspectionRecord) {
+	s.inspectionsLock.Lock()
+	s.inspections[record.ID] = newRestoredInspectionRunner(s, record)
+	s.inspectionsLock.Unlock()
+	if s.registry != nil {
+		if err := s.registry.Save(record); err != nil {
+			slog.Error(fmt.Sprintf("Failed to save the record of the merged inspection\n%v", err))
+		}
+	}
+}
+
+// mergedSourcesMetadataKey is the key of the metadata listing the inspections merged into a merged inspection.
+const mergedSourcesMetadataKey = "mergedSources"
+
+// mergedSource is an inspection merged into a merged inspection.
+type mergedSource struct {
+	InspectionID         string `json:"inspectionId"`
+	InspectionType       string `json:"inspectionType"`
+	SuggestedFileName    string `json:"suggestedFilename"`
+	StartTimeUnixSeconds int64  `json:"startTimeUnixSeconds"`
+	EndTimeUnixSeconds   int64  `json:"endTimeUnixSeconds"`
+}
+
+// sourceRecordForMerge returns the record describing this finished inspection as a source of a merge.
+func (i *InspectionTaskRunner) sourceRecordForMerge(store *inspectiondata.FileSystemStore) (*registry.InspectionRecord, error) {
+	if i.restoredRecord != nil {
+		return i.restoredRecord, nil
+	}
+	return i.newInspectionRecord(map[string]any{}, store)
+}
+
+// newMergedInspectionRecord generates the record of the merged inspection from the records of its sources.
+// The header covers the time range of every source, the features are the union of the sources and the run result combines the errors, queries and logs of the sources.
+func newMergedInspectionRecord(id string, owner string, sources []*registry.InspectionRecord, resultPath string, fileSize int, now time.Time) (*registry.InspectionRecord, error) {
+	if len(sources) == 0 {
+		return nil, ErrNotEnoughInspectionsToMerge
+	}
+	inspectionIDs := []string{}
+	inspectionTypeNames := []string{}
+	sourceNames := []string{}
+	features := map[string]struct{}{}
+	mergedSources := []*mergedSource{}
+	var mergedHeader *header.Header
+	for _, source := range sources {
+		inspectionIDs = append(inspectionIDs, source.ID)
+		for _, feature := range source.Features {
+			features[feature] = struct{}{}
+		}
+		item := &mergedSource{
+			InspectionID:   source.ID,
+			InspectionType: source.InspectionType,
+		}
+		mergedSources = append(mergedSources, item)
+		if source.Header == nil {
+			continue
+		}
+		item.InspectionType = source.Header.InspectionType
+		item.SuggestedFileName = source.Header.SuggestedFileName
+		item.StartTimeUnixSeconds = source.Header.StartTimeUnixSeconds
+		item.EndTimeUnixSeconds = source.Header.EndTimeUnixSeconds
+		if !slices.Contains(inspectionTypeNames, source.Header.InspectionType) {
+			inspectionTypeNames = append(inspectionTypeNames, source.Header.InspectionType)
+		}
+		sourceNames = append(sourceNames, strings.TrimSuffix(source.Header.SuggestedFileName, ".khi"))
+		if mergedHeader == nil {
+			mergedHeader = &header.Header{
+				InspectionTypeIconPath: source.Header.InspectionTypeIconPath,
+				StartTimeUnixSeconds:   source.Header.StartTimeUnixSeconds,
+				EndTimeUnixSeconds:     source.Header.EndTimeUnixSeconds,
+			}
+			continue
+		}
+		mergedHeader.StartTimeUnixSeconds = min(mergedHeader.StartTimeUnixSeconds, source.Header.StartTimeUnixSeconds)
+		mergedHeader.EndTimeUnixSeconds = max(mergedHeader.EndTimeUnixSeconds, source.Header.EndTimeUnixSeconds)
+	}
+	if mergedHeader == nil {
+		mergedHeader = &header.Header{}
+	}
+	mergedHeader.InspectionType = strings.Join(inspectionTypeNames, " + ")
+	mergedHeader.InspectTimeUnixSeconds = now.Unix()
+	mergedHeader.SuggestedFileName = fmt.Sprintf("merged-%s.khi", strings.Join(sourceNames, "+"))
+	mergedHeader.FileSize = fileSize
+
+	featureList := make([]string, 0, len(features))
+	for feature := range features {
+		featureList = append(featureList, feature)
+	}
+	sort.Strings(featureList)
+
+	serializedSources, err := json.Marshal(mergedSources)
+	if err != nil {
+		return nil, err
+	}
+	completedProgress := progress.NewProgress()
+	completedProgress.SetTotalTaskCount(len(sources))
+	completedProgress.Done()
+	serializedProgress, err := json.Marshal(completedProgress)
+	if err != nil {
+		return nil, err
+	}
+	runResultMetadata, err := mergeSerializedMetadata(sources, func(record *registry.InspectionRecord) map[string]json.RawMessage { return record.RunResultMetadata })
+	if err != nil {
+		return nil, err
+	}
+	runResultMetadata[mergedSourcesMetadataKey] = serializedSources
+	taskListMetadata, err := mergeSerializedMetadata(sources, func(record *registry.InspectionRecord) map[string]json.RawMessage { return record.TaskListMetadata })
+	if err != nil {
+		return nil, err
+	}
+	taskListMetadata[mergedSourcesMetadataKey] = serializedSources
+	taskListMetadata[progress.ProgressMetadataKey.Key()] = serializedProgress
+
+	return &registry.InspectionRecord{
+		ID:                id,
+		InspectionType:    sources[0].InspectionType,
+		Features:          featureList,
+		Owner:             owner,
+		Values:            map[string]any{"mergedInspectionIds": inspectionIDs},
+		ResultPath:        resultPath,
+		Header:            mergedHeader,
+		TaskListMetadata:  taskListMetadata,
+		RunResultMetadata: runResultMetadata,
+		CompletedAt:       now,
+	}, nil
+}
+
+// mergeSerializedMetadata combines the serialized metadata of the sources returned from the given function.
+// Error messages are combined without duplicates and list metadata like queries and logs are concatenated.
+// Other metadata like the progress or the plan describes a single run and it's dropped.
+func mergeSerializedMetadata(sources []*registry.InspectionRecord, metadataOf func(record *registry.InspectionRecord) map[string]json.RawMessage) (map[string]json.RawMessage, error) {
+	errorKey := error_metadata.ErrorMessageSetMetadataKey.Key()
+	errorMessages := &error_metadata.ErrorMessageSet{ErrorMessages: []*error_metadata.ErrorMessage{}}
+	hasErrorMetadata := false
+	lists := map[string][]json.RawMessage{}
+	droppedKeys := map[string]struct{}{}
+	for _, source := range sources {
+		for key, value := range metadataOf(source) {
+			if key == errorKey {
+				var sourceErrors error_metadata.ErrorMessageSet
+				if err := json.Unmarshal(value, &sourceErrors); err != nil {
+					return nil, fmt.Errorf("failed to read the error messages of the inspection %s\n%w", source.ID, err)
+				}
+				hasErrorMetadata = true
+				for _, message := range sourceErrors.ErrorMessages {
+					if !slices.ContainsFunc(errorMessages.ErrorMessages, func(m *error_metadata.ErrorMessage) bool { return m.Message == message.Message }) {
+						errorMessages.ErrorMessages = append(errorMessages.ErrorMessages, &error_metadata.ErrorMessage{
+							ErrorId: len(errorMessages.ErrorMessages),
+							Message: message.Message,
+							Link:    message.Link,
+						})
+					}
+				}
+				continue
+			}
+			if _, dropped := droppedKeys[key]; dropped {
+				continue
+			}
+			var items []json.RawMessage
+			if err := json.Unmarshal(value, &items); err != nil {
+				droppedKeys[key] = struct{}{}
+				delete(lists, key)
+				continue
+			}
+			lists[key] = append(lists[key], items...)
+		}
+	}
+	result := map[string]json.RawMessage{}
+	for key, items := range lists {
+		serialized, err := json.Marshal(items)
+		if err != nil {
+			return nil, err
+		}
+		result[key] = serialized
+	}
+	if hasErrorMetadata {
+		serialized, err := json.Marshal(errorMessages)
+		if err != nil {
+			return nil, err
+		}
+		result[errorKey] = serialized
+	}
+	return result, nil
+}
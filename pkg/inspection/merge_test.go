@@ -0,0 +1,127 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inspection
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/inspection/metadata/header"
+	"github.com/GoogleCloudPlatform/khi/pkg/inspection/registry"
+	"github.com/google/go-cmp/cmp"
+
+	_ "github.com/GoogleCloudPlatform/khi/internal/testflags"
+)
+
+func TestNewMergedInspectionRecord(t *testing.T) {
+	now := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+	sources := []*registry.InspectionRecord{
+		{
+			ID:             "a",
+			InspectionType: "gcp-gke",
+			Features:       []string{"audit", "event"},
+			Header: &header.Header{
+				InspectionType:       "Google Kubernetes Engine",
+				StartTimeUnixSeconds: 100,
+				EndTimeUnixSeconds:   200,
+				SuggestedFileName:    "cluster-a.khi",
+			},
+			TaskListMetadata: map[string]json.RawMessage{
+				"progress": json.RawMessage(`{"phase":"DONE"}`),
+				"error":    json.RawMessage(`{"errorMessages":[{"errorId":0,"message":"quota exceeded","link":""}]}`),
+			},
+			RunResultMetadata: map[string]json.RawMessage{
+				"error": json.RawMessage(`{"errorMessages":[{"errorId":0,"message":"quota exceeded","link":""}]}`),
+				"query": json.RawMessage(`[{"id":"audit-query"}]`),
+				"plan":  json.RawMessage(`{"taskGraph":"a"}`),
+			},
+		},
+		{
+			ID:             "b",
+			InspectionType: "gcp-gke",
+			Features:       []string{"event", "node"},
+			Header: &header.Header{
+				InspectionType:       "Google Kubernetes Engine",
+				StartTimeUnixSeconds: 50,
+				EndTimeUnixSeconds:   150,
+				SuggestedFileName:    "cluster-b.khi",
+			},
+			TaskListMetadata: map[string]json.RawMessage{
+				"error": json.RawMessage(`{"errorMessages":[{"errorId":0,"message":"quota exceeded","link":""},{"errorId":1,"message":"permission denied","link":""}]}`),
+			},
+			RunResultMetadata: map[string]json.RawMessage{
+				"query": json.RawMessage(`[{"id":"node-query"}]`),
+				"plan":  json.RawMessage(`{"taskGraph":"b"}`),
+			},
+		},
+	}
+
+	got, err := newMergedInspectionRecord("merged", "alice", sources, "/data/merged.khi", 1234, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantHeader := &header.Header{
+		InspectionType:         "Google Kubernetes Engine",
+		StartTimeUnixSeconds:   50,
+		EndTimeUnixSeconds:     200,
+		InspectTimeUnixSeconds: now.Unix(),
+		SuggestedFileName:      "merged-cluster-a+cluster-b.khi",
+		FileSize:               1234,
+	}
+	if diff := cmp.Diff(wantHeader, got.Header); diff != "" {
+		t.Errorf("header mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"audit", "event", "node"}, got.Features); diff != "" {
+		t.Errorf("features mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(map[string]any{"mergedInspectionIds": []string{"a", "b"}}, got.Values); diff != "" {
+		t.Errorf("values mismatch (-want +got):\n%s", diff)
+	}
+	if got.ID != "merged" || got.Owner != "alice" || got.ResultPath != "/data/merged.khi" {
+		t.Errorf("got ID %q, owner %q and result path %q", got.ID, got.Owner, got.ResultPath)
+	}
+
+	wantRunResult := map[string]string{
+		"error":         `{"errorMessages":[{"errorId":0,"message":"quota exceeded","link":""}]}`,
+		"query":         `[{"id":"audit-query"},{"id":"node-query"}]`,
+		"mergedSources": `[{"inspectionId":"a","inspectionType":"Google Kubernetes Engine","suggestedFilename":"cluster-a.khi","startTimeUnixSeconds":100,"endTimeUnixSeconds":200},{"inspectionId":"b","inspectionType":"Google Kubernetes Engine","suggestedFilename":"cluster-b.khi","startTimeUnixSeconds":50,"endTimeUnixSeconds":150}]`,
+	}
+	if diff := cmp.Diff(wantRunResult, stringifyRawMessages(got.RunResultMetadata)); diff != "" {
+		t.Errorf("run result metadata mismatch (-want +got):\n%s", diff)
+	}
+	taskList := stringifyRawMessages(got.TaskListMetadata)
+	if want := `{"errorMessages":[{"errorId":0,"message":"quota exceeded","link":""},{"errorId":1,"message":"permission denied","link":""}]}`; taskList["error"] != want {
+		t.Errorf("error in the task list = %s, want %s", taskList["error"], want)
+	}
+	var gotProgress struct {
+		Phase string `json:"phase"`
+	}
+	if err := json.Unmarshal(got.TaskListMetadata["progress"], &gotProgress); err != nil {
+		t.Fatal(err)
+	}
+	if gotProgress.Phase != "DONE" {
+		t.Errorf("progress phase = %q, want DONE", gotProgress.Phase)
+	}
+}
+
+func stringifyRawMessages(messages map[string]json.RawMessage) map[string]string {
+	result := map[string]string{}
+	for key, message := range messages {
+		result[key] = string(message)
+	}
+	return result
+}
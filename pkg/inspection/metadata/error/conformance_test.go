@@ -24,7 +24,7 @@ import (
 
 func TestProgressConformance(t *testing.T) {
 	metadata_test.ConformanceMetadataTypeTest(t, &ErrorMessageSet{
-		[]*ErrorMessage{
+		ErrorMessages: []*ErrorMessage{
 			{},
 		},
 	})
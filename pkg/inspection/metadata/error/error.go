@@ -28,9 +28,16 @@ type ErrorMessage struct {
 	Link    string `json:"link"`
 }
 
+// Listener receives error messages added to an ErrorMessageSet.
+type Listener interface {
+	// OnErrorMessageAdded is called when a new error message was added.
+	OnErrorMessageAdded(message *ErrorMessage)
+}
+
 // ErrorMessageSet is a metadata type containing errors exposed to frontend.
 type ErrorMessageSet struct {
 	ErrorMessages []*ErrorMessage `json:"errorMessages"`
+	listener      Listener
 }
 
 // Labels implements metadata.Metadata.
@@ -53,6 +60,14 @@ func (e *ErrorMessageSet) AddErrorMessage(newError *ErrorMessage) {
 		}
 	}
 	e.ErrorMessages = append(e.ErrorMessages, newError)
+	if e.listener != nil {
+		e.listener.OnErrorMessageAdded(newError)
+	}
+}
+
+// SetListener sets the Listener receiving error messages added after calling this.
+func (e *ErrorMessageSet) SetListener(listener Listener) {
+	e.listener = listener
 }
 
 func NewUnauthorizedErrorMessage() *ErrorMessage {
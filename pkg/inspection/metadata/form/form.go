@@ -104,6 +104,8 @@ type FileParameterFormField struct {
 	Token upload.UploadToken `json:"token"`
 	// Status is the current status of the file.
 	Status upload.UploadStatus `json:"status"`
+	// Multiple is true when the client can upload several files at once for the token.
+	Multiple bool `json:"multiple"`
 }
 
 // FormFieldSet is a metadata type used in frontend to generate the form fields.
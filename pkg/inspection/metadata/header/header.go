@@ -34,6 +34,8 @@ type Header struct {
 	// KHI frontend uses this metadata value for the default value of khi file name on download.
 	SuggestedFileName string `json:"suggestedFilename"`
 	FileSize          int    `json:"fileSize,omitempty"`
+	// TraceID is the OpenTelemetry trace ID of the inspection. It's empty when tracing is not enabled.
+	TraceID string `json:"traceId,omitempty"`
 }
 
 var _ metadata.Metadata = (*Header)(nil)
@@ -37,12 +37,20 @@ var similarLogThrottlingLogCount = 10
 
 var _ slog.Handler = (*TaskSlogHandler)(nil)
 
+// Listener receives logs stored for tasks as they are written.
+type Listener interface {
+	// OnTaskLog is called with the record stored in the log of the task.
+	OnTaskLog(taskID string, record slog.Record)
+}
+
 type TaskSlogHandler struct {
 	enableStdout  bool
 	stdoutHandler slog.Handler
 	stringHandler slog.Handler
 	minLogLevel   slog.Level
 	throttle      LogThrottler
+	taskID        string
+	listener      Listener
 }
 
 type SerializableLogItem struct {
@@ -77,7 +85,11 @@ func (t *TaskSlogHandler) Handle(ctx context.Context, r slog.Record) error {
 	}
 	if r.Level >= slog.LevelInfo {
 		// store string log only for >= info logs.
-		return t.stringHandler.Handle(ctx, r)
+		err := t.stringHandler.Handle(ctx, r)
+		if err == nil && t.listener != nil {
+			t.listener.OnTaskLog(t.taskID, r)
+		}
+		return err
 	}
 	return nil
 }
@@ -90,6 +102,8 @@ func (t *TaskSlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
 		stdoutHandler: t.stdoutHandler.WithAttrs(attrs),
 		stringHandler: t.stringHandler.WithAttrs(attrs),
 		throttle:      t.throttle,
+		taskID:        t.taskID,
+		listener:      t.listener,
 	}
 }
 
@@ -101,6 +115,8 @@ func (t *TaskSlogHandler) WithGroup(name string) slog.Handler {
 		stdoutHandler: t.stdoutHandler.WithGroup(name),
 		stringHandler: t.stringHandler.WithGroup(name),
 		throttle:      t.throttle,
+		taskID:        t.taskID,
+		listener:      t.listener,
 	}
 }
 
@@ -137,7 +153,8 @@ func (t *TaskLogger) AsSerializableLogItem() *SerializableLogItem {
 }
 
 type Logger struct {
-	loggers []*TaskLogger
+	loggers  []*TaskLogger
+	listener Listener
 }
 
 var _ metadata.Metadata = (*Logger)(nil)
@@ -158,6 +175,11 @@ func (l *Logger) ToSerializable() interface{} {
 	return result
 }
 
+// SetListener sets the Listener receiving logs of task loggers made after calling this.
+func (l *Logger) SetListener(listener Listener) {
+	l.listener = listener
+}
+
 func (l *Logger) MakeTaskLogger(ctx context.Context, minLevel slog.Level) *TaskLogger {
 	stdoutWithColor := true
 	if parameters.Debug.NoColor != nil && *parameters.Debug.NoColor {
@@ -176,6 +198,8 @@ func (l *Logger) MakeTaskLogger(ctx context.Context, minLevel slog.Level) *TaskL
 					stdoutHandler: logger.NewKHIFormatLogger(os.Stdout, stdoutWithColor),
 					stringHandler: logger.NewKHIFormatLogger(lb, false),
 					throttle:      NewConstantLogThrottle(similarLogThrottlingLogCount),
+					taskID:        tid.String(),
+					listener:      l.listener,
 				}
 				tl := &TaskLogger{
 					id:         tid.String(),
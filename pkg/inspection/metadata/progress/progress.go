@@ -30,12 +30,23 @@ const TASK_PHASE_DONE = "DONE"
 const TASK_PHASE_ERROR = "ERROR"
 const TASK_PHASE_CANCELLED = "CANCELLED"
 
+// Listener receives changes of a Progress as they happen.
+type Listener interface {
+	// OnTaskProgressUpdated is called with a copy of the task progress after it's updated.
+	OnTaskProgressUpdated(progress TaskProgress)
+	// OnTaskResolved is called when the task was finished and its progress was removed.
+	OnTaskResolved(id string)
+	// OnPhaseChanged is called when the phase of the progress was changed.
+	OnPhaseChanged(phase string)
+}
+
 type TaskProgress struct {
-	Id            string  `json:"id"`
-	Label         string  `json:"label"`
-	Message       string  `json:"message"`
-	Percentage    float32 `json:"percentage"`
-	Indeterminate bool    `json:"indeterminate"`
+	Id            string   `json:"id"`
+	Label         string   `json:"label"`
+	Message       string   `json:"message"`
+	Percentage    float32  `json:"percentage"`
+	Indeterminate bool     `json:"indeterminate"`
+	listener      Listener `json:"-"`
 }
 
 func NewTaskProgress(id string) *TaskProgress {
@@ -53,12 +64,22 @@ func (tp *TaskProgress) Update(percentage float32, message string) {
 	tp.Percentage = percentage
 	tp.Message = message
 	tp.Indeterminate = false
+	tp.notifyUpdated()
 }
 
 // MarkIndeterminate updates TaskProgress field to be indeterminate mode
 func (tp *TaskProgress) MarkIndeterminate() {
 	tp.Indeterminate = true
 	tp.Percentage = 0
+	tp.notifyUpdated()
+}
+
+func (tp *TaskProgress) notifyUpdated() {
+	if tp.listener != nil {
+		copied := *tp
+		copied.listener = nil
+		tp.listener.OnTaskProgressUpdated(copied)
+	}
 }
 
 type Progress struct {
@@ -68,6 +89,7 @@ type Progress struct {
 	totalTaskCount    int             `json:"-"`
 	resolvedTaskCount int             `json:"-"`
 	lock              sync.Mutex      `json:"-"`
+	listener          Listener        `json:"-"`
 }
 
 func NewProgress() *Progress {
@@ -93,6 +115,17 @@ func (p *Progress) ToSerializable() interface{} {
 	return p
 }
 
+// SetListener sets the Listener receiving changes of this progress. It must be called before the progress is shared with tasks.
+func (p *Progress) SetListener(listener Listener) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.listener = listener
+	p.TotalProgress.listener = listener
+	for _, progress := range p.TaskProgresses {
+		progress.listener = listener
+	}
+}
+
 func (p *Progress) SetTotalTaskCount(count int) {
 	p.totalTaskCount = count
 	p.updateTotalTaskProgress()
@@ -110,6 +143,7 @@ func (p *Progress) GetTaskProgress(id string) (*TaskProgress, error) {
 		}
 	}
 	taskProgress := NewTaskProgress(id)
+	taskProgress.listener = p.listener
 	p.TaskProgresses = append(p.TaskProgresses, taskProgress)
 	return taskProgress, nil
 }
@@ -128,6 +162,9 @@ func (p *Progress) ResolveTask(id string) error {
 	}
 	p.TaskProgresses = newTaskProgress
 	p.resolvedTaskCount += 1
+	if p.listener != nil {
+		p.listener.OnTaskResolved(id)
+	}
 	p.updateTotalTaskProgress()
 	return nil
 }
@@ -142,6 +179,7 @@ func (p *Progress) Done() error {
 	p.resolvedTaskCount = p.totalTaskCount
 	p.TaskProgresses = make([]*TaskProgress, 0)
 	p.updateTotalTaskProgress()
+	p.notifyPhaseChanged()
 	return nil
 }
 
@@ -153,6 +191,7 @@ func (p *Progress) Cancel() error {
 	}
 	p.Phase = TASK_PHASE_CANCELLED
 	p.TaskProgresses = make([]*TaskProgress, 0)
+	p.notifyPhaseChanged()
 	return nil
 }
 
@@ -164,10 +203,18 @@ func (p *Progress) Error() error {
 	}
 	p.Phase = TASK_PHASE_ERROR
 	p.TaskProgresses = make([]*TaskProgress, 0)
+	p.notifyPhaseChanged()
 	return nil
 }
 
 func (p *Progress) updateTotalTaskProgress() {
 	p.TotalProgress.Message = fmt.Sprintf("%d of %d tasks complete", p.resolvedTaskCount, p.totalTaskCount)
 	p.TotalProgress.Percentage = float32(p.resolvedTaskCount) / float32(p.totalTaskCount)
+	p.TotalProgress.notifyUpdated()
+}
+
+func (p *Progress) notifyPhaseChanged() {
+	if p.listener != nil {
+		p.listener.OnPhaseChanged(p.Phase)
+	}
 }
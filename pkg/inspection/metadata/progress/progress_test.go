@@ -15,6 +15,7 @@
 package progress
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -37,7 +38,7 @@ func TestGetTaskProgress(t *testing.T) {
 		Label:      "foo",
 	}
 
-	if diff := cmp.Diff(expected, tp); diff != "" {
+	if diff := cmp.Diff(expected, tp, cmpopts.IgnoreUnexported(TaskProgress{})); diff != "" {
 		t.Errorf("generated task progress is not containing the expected state\n%s", diff)
 	}
 
@@ -67,7 +68,7 @@ func TestResolveTasks(t *testing.T) {
 				Label: "bar",
 			},
 		},
-	}, progress, cmpopts.IgnoreUnexported(Progress{})); diff != "" {
+	}, progress, cmpopts.IgnoreUnexported(Progress{}, TaskProgress{})); diff != "" {
 		t.Errorf("The result status is not in the expected status\n%s", diff)
 	}
 }
@@ -83,7 +84,7 @@ func TestDoneClearTasks(t *testing.T) {
 		Phase:          "DONE",
 		TotalProgress:  &TaskProgress{Id: "Total", Label: "Total", Message: "2 of 2 tasks complete", Percentage: 1},
 		TaskProgresses: []*TaskProgress{},
-	}, progress, cmpopts.IgnoreUnexported(Progress{})); diff != "" {
+	}, progress, cmpopts.IgnoreUnexported(Progress{}, TaskProgress{})); diff != "" {
 		t.Errorf("The result status is not in the expected status\n%s", diff)
 	}
 }
@@ -99,7 +100,49 @@ func TestCancelClearTasks(t *testing.T) {
 		Phase:          "CANCELLED",
 		TaskProgresses: []*TaskProgress{},
 		TotalProgress:  &TaskProgress{Id: "Total", Label: "Total", Message: "0 of 2 tasks complete"},
-	}, progress, cmpopts.IgnoreUnexported(Progress{})); diff != "" {
+	}, progress, cmpopts.IgnoreUnexported(Progress{}, TaskProgress{})); diff != "" {
 		t.Errorf("The result status is not in the expected status\n%s", diff)
 	}
 }
+
+type spyListener struct {
+	changes []string
+}
+
+func (s *spyListener) OnTaskProgressUpdated(progress TaskProgress) {
+	s.changes = append(s.changes, fmt.Sprintf("update %s %s", progress.Id, progress.Message))
+}
+
+func (s *spyListener) OnTaskResolved(id string) {
+	s.changes = append(s.changes, "resolve "+id)
+}
+
+func (s *spyListener) OnPhaseChanged(phase string) {
+	s.changes = append(s.changes, "phase "+phase)
+}
+
+func TestProgressListener(t *testing.T) {
+	listener := &spyListener{}
+	progress := NewProgress()
+	progress.SetListener(listener)
+	progress.SetTotalTaskCount(1)
+	tp, err := progress.GetTaskProgress("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tp.Update(0.5, "half")
+	progress.ResolveTask("foo")
+	progress.Done()
+
+	want := []string{
+		"update Total 0 of 1 tasks complete",
+		"update foo half",
+		"resolve foo",
+		"update Total 1 of 1 tasks complete",
+		"update Total 1 of 1 tasks complete",
+		"phase DONE",
+	}
+	if diff := cmp.Diff(want, listener.changes); diff != "" {
+		t.Errorf("notified changes mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -30,6 +30,8 @@ type QueryItem struct {
 	Id    string `json:"id"`
 	Name  string `json:"name"`
 	Query string `json:"query"`
+	// EstimatedLogCount is the count of logs the query is estimated to return. It's set only in the dry run requested to estimate the volume.
+	EstimatedLogCount *int `json:"estimatedLogCount,omitempty"`
 }
 
 type QueryMetadata struct {
@@ -67,6 +69,22 @@ func (q *QueryMetadata) SetQuery(id string, name string, queryString string) {
 	})
 }
 
+// SetEstimatedLogCount sets the estimated count of logs returned from the query with the given id.
+func (q *QueryMetadata) SetEstimatedLogCount(id string, count int) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	for _, qi := range q.Queries {
+		if qi.Id == id {
+			qi.EstimatedLogCount = &count
+			return
+		}
+	}
+	q.Queries = append(q.Queries, &QueryItem{
+		Id:                id,
+		EstimatedLogCount: &count,
+	})
+}
+
 var _ metadata.Metadata = (*QueryMetadata)(nil)
 
 func NewQueryMetadata() *QueryMetadata {
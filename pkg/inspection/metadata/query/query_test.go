@@ -60,3 +60,20 @@ func TestQuerySerializeInSortedOrder(t *testing.T) {
 		t.Errorf("Query info serialization result was not in the sorted order\n%s", diff)
 	}
 }
+
+func TestQuerySetEstimatedLogCount(t *testing.T) {
+	query := NewQueryMetadata()
+	query.SetQuery("a", "query-a", "foo")
+	query.SetEstimatedLogCount("a", 100)
+	query.SetEstimatedLogCount("b", 200)
+
+	count100 := 100
+	count200 := 200
+	expected := []*QueryItem{
+		{Id: "a", Name: "query-a", Query: "foo", EstimatedLogCount: &count100},
+		{Id: "b", EstimatedLogCount: &count200},
+	}
+	if diff := cmp.Diff(expected, query.ToSerializable()); diff != "" {
+		t.Errorf("ToSerializable() mismatch (-want +got):\n%s", diff)
+	}
+}
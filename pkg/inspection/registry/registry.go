@@ -0,0 +1,171 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/inspection/metadata/header"
+)
+
+// ErrRecordNotFound is returned when the requested inspection record doesn't exist in the registry.
+var ErrRecordNotFound = errors.New("inspection record not found")
+
+const recordFileSuffix = ".json"
+
+// InspectionRecord is the persisted information of a completed inspection.
+type InspectionRecord struct {
+	// ID is the inspection ID.
+	ID string `json:"id"`
+	// InspectionType is the ID of the inspection type used in the inspection.
+	InspectionType string `json:"inspectionType"`
+	// Features is the list of feature task IDs enabled in the inspection.
+	Features []string `json:"features"`
+	// Owner is the name of the principal who created the inspection. It is empty when the inspection has no owner.
+	Owner string `json:"owner,omitempty"`
+	// Values is the input values given to run the inspection.
+	Values map[string]any `json:"values"`
+	// ResultPath is the file path of the .khi file generated by the inspection.
+	ResultPath string `json:"resultPath"`
+	// Header is the header metadata of the inspection.
+	Header *header.Header `json:"header"`
+	// TaskListMetadata is the serialized metadata included in the inspection list except the header.
+	TaskListMetadata map[string]json.RawMessage `json:"taskListMetadata"`
+	// RunResultMetadata is the serialized metadata included in the run result except the header.
+	RunResultMetadata map[string]json.RawMessage `json:"runResultMetadata"`
+	// CompletedAt is the time when the inspection was completed.
+	CompletedAt time.Time `json:"completedAt"`
+}
+
+// InspectionRegistry persists records of completed inspections to restore them after restarting KHI.
+type InspectionRegistry interface {
+	// Save stores the record. A record with the same ID is overwritten.
+	Save(record *InspectionRecord) error
+	// Delete removes the record with the given ID. Returns ErrRecordNotFound when it doesn't exist.
+	Delete(id string) error
+	// List returns every stored record sorted by CompletedAt.
+	List() ([]*InspectionRecord, error)
+}
+
+// FileSystemInspectionRegistry is an InspectionRegistry storing each record as a JSON file in a folder.
+type FileSystemInspectionRegistry struct {
+	folder string
+	lock   sync.Mutex
+}
+
+var _ InspectionRegistry = (*FileSystemInspectionRegistry)(nil)
+
+// NewFileSystemInspectionRegistry returns a FileSystemInspectionRegistry storing records in the given folder. The folder is created when it doesn't exist.
+func NewFileSystemInspectionRegistry(folder string) (*FileSystemInspectionRegistry, error) {
+	if err := os.MkdirAll(folder, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create the inspection registry folder %s\n%w", folder, err)
+	}
+	return &FileSystemInspectionRegistry{
+		folder: folder,
+	}, nil
+}
+
+// Save implements InspectionRegistry.
+func (r *FileSystemInspectionRegistry) Save(record *InspectionRecord) error {
+	if err := validateID(record.ID); err != nil {
+		return err
+	}
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	// Write a temporary file and rename it not to leave a broken record when KHI is killed in the middle of writing.
+	tmpFile, err := os.CreateTemp(r.folder, "tmp-record-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpFile.Name())
+	if _, err := tmpFile.Write(data); err != nil {
+		tmpFile.Close()
+		return err
+	}
+	if err := tmpFile.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmpFile.Name(), r.recordPath(record.ID))
+}
+
+// Delete implements InspectionRegistry.
+func (r *FileSystemInspectionRegistry) Delete(id string) error {
+	if err := validateID(id); err != nil {
+		return err
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	err := os.Remove(r.recordPath(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("%w: %s", ErrRecordNotFound, id)
+	}
+	return err
+}
+
+// List implements InspectionRegistry.
+func (r *FileSystemInspectionRegistry) List() ([]*InspectionRecord, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	entries, err := os.ReadDir(r.folder)
+	if err != nil {
+		return nil, err
+	}
+	records := []*InspectionRecord{}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), recordFileSuffix) {
+			continue
+		}
+		filePath := filepath.Join(r.folder, entry.Name())
+		data, err := os.ReadFile(filePath)
+		if err != nil {
+			return nil, err
+		}
+		var record InspectionRecord
+		if err := json.Unmarshal(data, &record); err != nil {
+			// A broken record must not prevent the other records from being restored.
+			slog.Warn(fmt.Sprintf("ignoring a broken inspection record %s\n%v", filePath, err))
+			continue
+		}
+		records = append(records, &record)
+	}
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].CompletedAt.Before(records[j].CompletedAt)
+	})
+	return records, nil
+}
+
+func (r *FileSystemInspectionRegistry) recordPath(id string) string {
+	return filepath.Join(r.folder, id+recordFileSuffix)
+}
+
+func validateID(id string) error {
+	if id == "" || strings.ContainsAny(id, `/\.`) {
+		return fmt.Errorf("invalid inspection ID %q", id)
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/inspection/metadata/header"
+	"github.com/google/go-cmp/cmp"
+
+	_ "github.com/GoogleCloudPlatform/khi/internal/testflags"
+)
+
+func TestFileSystemInspectionRegistry(t *testing.T) {
+	registry, err := NewFileSystemInspectionRegistry(filepath.Join(t.TempDir(), "registry"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	older := &InspectionRecord{
+		ID:                "older",
+		InspectionType:    "gcp-gke",
+		Features:          []string{"feature-a"},
+		Values:            map[string]any{"foo": "bar"},
+		ResultPath:        "/tmp/older.khi",
+		Header:            &header.Header{InspectionType: "Google Kubernetes Engine", FileSize: 100},
+		TaskListMetadata:  map[string]json.RawMessage{"progress": json.RawMessage(`{"phase":"DONE"}`)},
+		RunResultMetadata: map[string]json.RawMessage{"log": json.RawMessage(`[]`)},
+		CompletedAt:       time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC),
+	}
+	newer := &InspectionRecord{
+		ID:          "newer",
+		ResultPath:  "/tmp/newer.khi",
+		CompletedAt: time.Date(2025, time.January, 2, 0, 0, 0, 0, time.UTC),
+	}
+	for _, record := range []*InspectionRecord{newer, older} {
+		if err := registry.Save(record); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := registry.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]*InspectionRecord{older, newer}, got); diff != "" {
+		t.Errorf("List() mismatch (-want +got):\n%s", diff)
+	}
+
+	if err := registry.Delete("older"); err != nil {
+		t.Fatal(err)
+	}
+	if err := registry.Delete("older"); !errors.Is(err, ErrRecordNotFound) {
+		t.Errorf("Delete() for a missing record returned %v, want ErrRecordNotFound", err)
+	}
+	got, err = registry.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]*InspectionRecord{newer}, got); diff != "" {
+		t.Errorf("List() after Delete() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestFileSystemInspectionRegistryIgnoresBrokenRecord(t *testing.T) {
+	folder := t.TempDir()
+	registry, err := NewFileSystemInspectionRegistry(folder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(folder, "broken.json"), []byte("{not a json"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	valid := &InspectionRecord{ID: "valid", CompletedAt: time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)}
+	if err := registry.Save(valid); err != nil {
+		t.Fatal(err)
+	}
+	got, err := registry.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]*InspectionRecord{valid}, got); diff != "" {
+		t.Errorf("List() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestFileSystemInspectionRegistryRejectsInvalidID(t *testing.T) {
+	registry, err := NewFileSystemInspectionRegistry(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"", "../escape", `a\b`} {
+		if err := registry.Save(&InspectionRecord{ID: id}); err == nil {
+			t.Errorf("Save() with ID %q succeeded unexpectedly", id)
+		}
+	}
+}
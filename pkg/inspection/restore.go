@@ -0,0 +1,152 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inspection
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/common/filter"
+	"github.com/GoogleCloudPlatform/khi/pkg/common/typedmap"
+	"github.com/GoogleCloudPlatform/khi/pkg/inspection/inspectiondata"
+	"github.com/GoogleCloudPlatform/khi/pkg/inspection/metadata"
+	"github.com/GoogleCloudPlatform/khi/pkg/inspection/metadata/header"
+	"github.com/GoogleCloudPlatform/khi/pkg/inspection/registry"
+	"github.com/GoogleCloudPlatform/khi/pkg/task"
+)
+
+// restoredMetadata is a Metadata restored from an InspectionRecord. It returns the serialized value as is.
+type restoredMetadata struct {
+	value  json.RawMessage
+	labels *typedmap.ReadonlyTypedMap
+}
+
+var _ metadata.Metadata = (*restoredMetadata)(nil)
+
+// Labels implements metadata.Metadata.
+func (r *restoredMetadata) Labels() *typedmap.ReadonlyTypedMap {
+	return r.labels
+}
+
+// ToSerializable implements metadata.Metadata.
+func (r *restoredMetadata) ToSerializable() interface{} {
+	return r.value
+}
+
+// newRestoredInspectionRunner returns a read-only InspectionTaskRunner for a completed inspection restored from the given record.
+func newRestoredInspectionRunner(server *InspectionTaskServer, record *registry.InspectionRecord) *InspectionTaskRunner {
+	runner := NewInspectionRunner(server)
+	runner.ID = record.ID
+	runner.currentInspectionType = record.InspectionType
+	runner.owner = record.Owner
+	for _, feature := range record.Features {
+		runner.enabledFeatures[feature] = true
+	}
+	runner.restoredRecord = record
+	runner.metadata = restoreMetadataSet(record)
+	return runner
+}
+
+// restoreMetadataSet generates the metadata set from the serialized metadata in the given record.
+func restoreMetadataSet(record *registry.InspectionRecord) *typedmap.ReadonlyTypedMap {
+	writableMetadata := typedmap.NewTypedMap()
+	if record.Header != nil {
+		typedmap.Set(writableMetadata, header.HeaderMetadataKey, record.Header)
+	}
+	keys := map[string]struct{}{}
+	for key := range record.TaskListMetadata {
+		keys[key] = struct{}{}
+	}
+	for key := range record.RunResultMetadata {
+		keys[key] = struct{}{}
+	}
+	for key := range keys {
+		labelOpts := []task.LabelOpt{}
+		value, inTaskList := record.TaskListMetadata[key]
+		if inTaskList {
+			labelOpts = append(labelOpts, metadata.IncludeInTaskList())
+		}
+		if runResultValue, inRunResult := record.RunResultMetadata[key]; inRunResult {
+			labelOpts = append(labelOpts, metadata.IncludeInRunResult())
+			value = runResultValue
+		}
+		typedmap.Set(writableMetadata, metadata.NewMetadataKey[*restoredMetadata](key), &restoredMetadata{
+			value:  value,
+			labels: task.NewLabelSet(labelOpts...),
+		})
+	}
+	return writableMetadata.AsReadonly()
+}
+
+// newInspectionRecord generates the record of this inspection to be persisted in the registry.
+func (i *InspectionTaskRunner) newInspectionRecord(values map[string]any, store inspectiondata.Store) (*registry.InspectionRecord, error) {
+	fileSystemStore, ok := store.(*inspectiondata.FileSystemStore)
+	if !ok {
+		return nil, fmt.Errorf("the inspection result store is not persisted in the file system")
+	}
+	inspectionHeader, found := typedmap.Get(i.metadata, header.HeaderMetadataKey)
+	if !found {
+		return nil, fmt.Errorf("header metadata was not found")
+	}
+	taskListMetadata, err := serializeMetadataSubset(i.metadata, metadata.LabelKeyIncludedInTaskListFlag)
+	if err != nil {
+		return nil, err
+	}
+	runResultMetadata, err := serializeMetadataSubset(i.metadata, metadata.LabelKeyIncludedInRunResultFlag)
+	if err != nil {
+		return nil, err
+	}
+	features := []string{}
+	for feature, enabled := range i.enabledFeatures {
+		if enabled {
+			features = append(features, feature)
+		}
+	}
+	sort.Strings(features)
+	return &registry.InspectionRecord{
+		ID:                i.ID,
+		InspectionType:    i.currentInspectionType,
+		Features:          features,
+		Owner:             i.owner,
+		Values:            values,
+		ResultPath:        fileSystemStore.FilePath(),
+		Header:            inspectionHeader,
+		TaskListMetadata:  taskListMetadata,
+		RunResultMetadata: runResultMetadata,
+		CompletedAt:       time.Now(),
+	}, nil
+}
+
+// serializeMetadataSubset serializes the metadata having the given label flag except the header.
+func serializeMetadataSubset(metadataSet *typedmap.ReadonlyTypedMap, flagKey metadata.MetadataLabelsKey[bool]) (map[string]json.RawMessage, error) {
+	subset, err := metadata.GetSerializableSubsetMapFromMetadataSet(metadataSet, filter.NewEnabledFilter(flagKey, false))
+	if err != nil {
+		return nil, err
+	}
+	result := map[string]json.RawMessage{}
+	for key, value := range subset {
+		if key == header.HeaderMetadataKey.Key() {
+			continue
+		}
+		serialized, err := json.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize metadata %s\n%w", key, err)
+		}
+		result[key] = serialized
+	}
+	return result, nil
+}
@@ -0,0 +1,340 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package retention
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/inspection"
+	"github.com/GoogleCloudPlatform/khi/pkg/lifecycle"
+	"github.com/GoogleCloudPlatform/khi/pkg/server/upload"
+)
+
+// resultFileSuffix is the suffix of inspection result files written by the serializer task.
+const resultFileSuffix = ".khi"
+
+// maxRecentDecisions is the count of eviction decisions kept for the status.
+const maxRecentDecisions = 100
+
+// Policy is the limits of the stored inspection results. A zero value field means unlimited.
+type Policy struct {
+	// MaxAge is the maximum age of inspection results.
+	MaxAge time.Duration
+	// MaxTotalBytes is the maximum total size of inspection results in bytes.
+	MaxTotalBytes int64
+	// MaxInspectionCount is the maximum count of inspection results.
+	MaxInspectionCount int
+}
+
+// DecisionTarget is the kind of the file evicted by the Collector.
+type DecisionTarget string
+
+const (
+	DecisionTargetResult DecisionTarget = "result"
+	DecisionTargetUpload DecisionTarget = "upload"
+)
+
+// Decision is an eviction decision made by the Collector.
+type Decision struct {
+	Time   time.Time      `json:"time"`
+	Target DecisionTarget `json:"target"`
+	// ID is the inspection ID for results and the upload token ID for uploads.
+	ID     string `json:"id"`
+	Reason string `json:"reason"`
+	Size   int64  `json:"size"`
+	// Error is the error message when the eviction failed.
+	Error string `json:"error,omitempty"`
+}
+
+// Status is the current storage usage and the recent decisions made by the Collector.
+type Status struct {
+	MaxAgeSeconds      int64      `json:"maxAgeSeconds"`
+	MaxTotalBytes      int64      `json:"maxTotalBytes"`
+	MaxInspectionCount int        `json:"maxInspectionCount"`
+	LastCollectedAt    time.Time  `json:"lastCollectedAt"`
+	ResultCount        int        `json:"resultCount"`
+	ResultBytes        int64      `json:"resultBytes"`
+	UploadCount        int        `json:"uploadCount"`
+	UploadBytes        int64      `json:"uploadBytes"`
+	RecentDecisions    []Decision `json:"recentDecisions"`
+}
+
+// storedResult is an inspection result file found in the result folder.
+type storedResult struct {
+	id      string
+	path    string
+	size    int64
+	modTime time.Time
+}
+
+// Collector removes inspection results exceeding the Policy and uploaded files not associated with any inspection.
+type Collector struct {
+	policy           Policy
+	inspectionServer *inspection.InspectionTaskServer
+	resultFolder     string
+	uploadStore      *upload.UploadFileStore
+	interval         time.Duration
+	now              func() time.Time
+
+	// collectLock serializes collections and guards status.
+	collectLock sync.Mutex
+	status      Status
+
+	trigger  chan struct{}
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewCollector returns a Collector for results stored in resultFolder. uploadStore can be nil when uploaded files are not managed.
+func NewCollector(policy Policy, inspectionServer *inspection.InspectionTaskServer, resultFolder string, uploadStore *upload.UploadFileStore, interval time.Duration) *Collector {
+	return &Collector{
+		policy:           policy,
+		inspectionServer: inspectionServer,
+		resultFolder:     resultFolder,
+		uploadStore:      uploadStore,
+		interval:         interval,
+		now:              time.Now,
+		status: Status{
+			MaxAgeSeconds:      int64(policy.MaxAge / time.Second),
+			MaxTotalBytes:      policy.MaxTotalBytes,
+			MaxInspectionCount: policy.MaxInspectionCount,
+			RecentDecisions:    []Decision{},
+		},
+		trigger: make(chan struct{}, 1),
+		stop:    make(chan struct{}),
+	}
+}
+
+// RegisterLifecycleHandler makes the Collector run periodically after the init event until the terminate event.
+// A collection is also requested at the end of every inspection.
+func (c *Collector) RegisterLifecycleHandler(notifier *lifecycle.LifecycleEventNotifier) {
+	notifier.AddHandler(&lifecycle.LifecycleEventHandler{
+		OnInit: func() {
+			go c.run()
+		},
+		OnTerminate: func(s os.Signal) {
+			c.stopOnce.Do(func() { close(c.stop) })
+		},
+		OnInspectionEnd: func(runId string, inspectionType string, status string, size int) {
+			select {
+			case c.trigger <- struct{}{}:
+			default:
+			}
+		},
+	})
+}
+
+func (c *Collector) run() {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+	for {
+		err := c.Collect(context.Background())
+		if err != nil {
+			slog.Error(fmt.Sprintf("Failed to collect inspection results and uploaded files\n%v", err))
+		}
+		select {
+		case <-c.stop:
+			return
+		case <-ticker.C:
+		case <-c.trigger:
+		}
+	}
+}
+
+// Status returns the storage status at the last collection.
+func (c *Collector) Status() Status {
+	c.collectLock.Lock()
+	defer c.collectLock.Unlock()
+	status := c.status
+	status.RecentDecisions = append([]Decision{}, c.status.RecentDecisions...)
+	return status
+}
+
+// Collect evicts inspection results exceeding the policy from the oldest one, and then removes uploaded files of inspections no longer existing.
+// Results of running inspections are never evicted.
+func (c *Collector) Collect(ctx context.Context) error {
+	c.collectLock.Lock()
+	defer c.collectLock.Unlock()
+	now := c.now()
+	decisions := []Decision{}
+
+	results, err := c.listResults()
+	if err != nil {
+		return err
+	}
+	resultCount := len(results)
+	var resultBytes int64
+	for _, result := range results {
+		resultBytes += result.size
+	}
+	for _, result := range results {
+		if c.isRunning(result.id) {
+			continue
+		}
+		reason := ""
+		switch {
+		case c.policy.MaxAge > 0 && now.Sub(result.modTime) > c.policy.MaxAge:
+			reason = fmt.Sprintf("older than the max age %s", c.policy.MaxAge)
+		case c.policy.MaxInspectionCount > 0 && resultCount > c.policy.MaxInspectionCount:
+			reason = fmt.Sprintf("exceeding the max inspection count %d", c.policy.MaxInspectionCount)
+		case c.policy.MaxTotalBytes > 0 && resultBytes > c.policy.MaxTotalBytes:
+			reason = fmt.Sprintf("exceeding the max total bytes %d", c.policy.MaxTotalBytes)
+		default:
+			continue
+		}
+		decision := Decision{Time: now, Target: DecisionTargetResult, ID: result.id, Reason: reason, Size: result.size}
+		if err := c.evictResult(result); err != nil {
+			decision.Error = err.Error()
+		} else {
+			resultCount--
+			resultBytes -= result.size
+		}
+		decisions = append(decisions, decision)
+	}
+
+	uploadCount, uploadBytes := 0, int64(0)
+	if c.uploadStore != nil {
+		uploadDecisions, count, bytes, err := c.collectUploads(now)
+		if err != nil {
+			return err
+		}
+		decisions = append(decisions, uploadDecisions...)
+		uploadCount, uploadBytes = count, bytes
+	}
+
+	for _, decision := range decisions {
+		if decision.Error != "" {
+			slog.ErrorContext(ctx, fmt.Sprintf("Failed to remove the %s %s (%s)\n%s", decision.Target, decision.ID, decision.Reason, decision.Error))
+		} else {
+			slog.InfoContext(ctx, fmt.Sprintf("Removed the %s %s (%d bytes) because it was %s", decision.Target, decision.ID, decision.Size, decision.Reason))
+		}
+	}
+	c.status.LastCollectedAt = now
+	c.status.ResultCount = resultCount
+	c.status.ResultBytes = resultBytes
+	c.status.UploadCount = uploadCount
+	c.status.UploadBytes = uploadBytes
+	c.status.RecentDecisions = append(c.status.RecentDecisions, decisions...)
+	if len(c.status.RecentDecisions) > maxRecentDecisions {
+		c.status.RecentDecisions = c.status.RecentDecisions[len(c.status.RecentDecisions)-maxRecentDecisions:]
+	}
+	return nil
+}
+
+// listResults returns the result files in the result folder sorted from the oldest.
+func (c *Collector) listResults() ([]storedResult, error) {
+	entries, err := os.ReadDir(c.resultFolder)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return []storedResult{}, nil
+		}
+		return nil, err
+	}
+	results := []storedResult{}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), resultFileSuffix) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+		results = append(results, storedResult{
+			id:      strings.TrimSuffix(entry.Name(), resultFileSuffix),
+			path:    filepath.Join(c.resultFolder, entry.Name()),
+			size:    info.Size(),
+			modTime: info.ModTime(),
+		})
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].modTime.Before(results[j].modTime)
+	})
+	return results, nil
+}
+
+func (c *Collector) isRunning(inspectionID string) bool {
+	runner := c.inspectionServer.GetInspection(inspectionID)
+	return runner != nil && runner.Started() && !runner.Finished()
+}
+
+// evictResult removes the inspection from the inspection server when it is known, and then removes the result file.
+func (c *Collector) evictResult(result storedResult) error {
+	if c.inspectionServer.GetInspection(result.id) != nil {
+		err := c.inspectionServer.DeleteInspection(result.id)
+		if err != nil && !errors.Is(err, inspection.ErrInspectionNotFound) {
+			return err
+		}
+	}
+	err := os.Remove(result.path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// collectUploads removes upload tokens and their files when the inspection issued the token no longer exists.
+// It returns the decisions with the count and the total size of the remaining uploaded files.
+func (c *Collector) collectUploads(now time.Time) ([]Decision, int, int64, error) {
+	sizes := map[string]int64{}
+	for _, id := range c.uploadStore.IssuedTokenIDs() {
+		sizes[id] = 0
+	}
+	if deletable, ok := c.uploadStore.StoreProvider.(upload.DeletableUploadFileStoreProvider); ok {
+		files, err := deletable.List()
+		if err != nil {
+			return nil, 0, 0, err
+		}
+		for _, file := range files {
+			sizes[file.ID] = file.Size
+		}
+	}
+	ids := make([]string, 0, len(sizes))
+	for id := range sizes {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	decisions := []Decision{}
+	remainingCount, remainingBytes := 0, int64(0)
+	for _, id := range ids {
+		// Upload IDs are prefixed with the inspection ID. See upload.GenerateUploadIDWithTaskContext.
+		inspectionID, _, _ := strings.Cut(id, "_")
+		if c.inspectionServer.GetInspection(inspectionID) != nil {
+			remainingCount++
+			remainingBytes += sizes[id]
+			continue
+		}
+		decision := Decision{Time: now, Target: DecisionTargetUpload, ID: id, Reason: fmt.Sprintf("orphaned from the inspection %s", inspectionID), Size: sizes[id]}
+		if err := c.uploadStore.Remove(id); err != nil {
+			decision.Error = err.Error()
+			remainingCount++
+			remainingBytes += sizes[id]
+		}
+		decisions = append(decisions, decision)
+	}
+	return decisions, remainingCount, remainingBytes, nil
+}
@@ -0,0 +1,187 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package retention
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/inspection"
+	"github.com/GoogleCloudPlatform/khi/pkg/server/upload"
+	"github.com/google/go-cmp/cmp"
+
+	_ "github.com/GoogleCloudPlatform/khi/internal/testflags"
+)
+
+var testNow = time.Date(2025, time.January, 10, 0, 0, 0, 0, time.UTC)
+
+func mustPlaceFile(t *testing.T, path string, size int, modTime time.Time) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(strings.Repeat("a", size)), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func listFileNames(t *testing.T, folder string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(folder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := []string{}
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	return names
+}
+
+func TestCollectorCollectResults(t *testing.T) {
+	testCases := []struct {
+		name   string
+		policy Policy
+		// wantFiles is the remaining files in the result folder. Files other than .khi files must not be touched.
+		wantFiles []string
+	}{
+		{
+			name:      "unlimited",
+			policy:    Policy{},
+			wantFiles: []string{"new.khi", "not-a-result.txt", "old.khi", "older.khi"},
+		},
+		{
+			name:      "max age",
+			policy:    Policy{MaxAge: 48 * time.Hour},
+			wantFiles: []string{"new.khi", "not-a-result.txt"},
+		},
+		{
+			name:      "max inspection count",
+			policy:    Policy{MaxInspectionCount: 2},
+			wantFiles: []string{"new.khi", "not-a-result.txt", "old.khi"},
+		},
+		{
+			name:      "max total bytes",
+			policy:    Policy{MaxTotalBytes: 15},
+			wantFiles: []string{"new.khi", "not-a-result.txt"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			resultFolder := t.TempDir()
+			mustPlaceFile(t, filepath.Join(resultFolder, "older.khi"), 10, testNow.Add(-96*time.Hour))
+			mustPlaceFile(t, filepath.Join(resultFolder, "old.khi"), 10, testNow.Add(-72*time.Hour))
+			mustPlaceFile(t, filepath.Join(resultFolder, "new.khi"), 10, testNow.Add(-time.Hour))
+			mustPlaceFile(t, filepath.Join(resultFolder, "not-a-result.txt"), 100, testNow.Add(-96*time.Hour))
+			inspectionServer, err := inspection.NewServer()
+			if err != nil {
+				t.Fatal(err)
+			}
+			collector := NewCollector(tc.policy, inspectionServer, resultFolder, nil, time.Minute)
+			collector.now = func() time.Time { return testNow }
+
+			if err := collector.Collect(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+
+			if diff := cmp.Diff(tc.wantFiles, listFileNames(t, resultFolder)); diff != "" {
+				t.Errorf("remaining files mismatch (-want +got):\n%s", diff)
+			}
+			wantResultCount := len(tc.wantFiles) - 1
+			status := collector.Status()
+			if status.ResultCount != wantResultCount || status.ResultBytes != int64(10*wantResultCount) {
+				t.Errorf("unexpected status %+v", status)
+			}
+			if len(status.RecentDecisions) != 3-wantResultCount {
+				t.Errorf("unexpected decisions %+v", status.RecentDecisions)
+			}
+		})
+	}
+}
+
+func TestCollectorCollectUploads(t *testing.T) {
+	inspectionServer, err := inspection.NewServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := inspectionServer.AddInspectionType(inspection.InspectionType{Id: "foo"}); err != nil {
+		t.Fatal(err)
+	}
+	inspectionID, err := inspectionServer.CreateInspection("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	uploadFolder := t.TempDir()
+	uploadStore := upload.NewUploadFileStore(upload.NewLocalUploadFileStoreProvider(uploadFolder))
+	uploadStore.GetUploadToken("deleted_task_form", &upload.NopWaitUploadFileVerifier{})
+	mustPlaceFile(t, filepath.Join(uploadFolder, inspectionID+"_task_form"), 5, testNow)
+	mustPlaceFile(t, filepath.Join(uploadFolder, "deleted_task_form"), 7, testNow)
+
+	collector := NewCollector(Policy{}, inspectionServer, t.TempDir(), uploadStore, time.Minute)
+	collector.now = func() time.Time { return testNow }
+	if err := collector.Collect(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff([]string{inspectionID + "_task_form"}, listFileNames(t, uploadFolder)); diff != "" {
+		t.Errorf("remaining files mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{}, uploadStore.IssuedTokenIDs()); diff != "" {
+		t.Errorf("remaining tokens mismatch (-want +got):\n%s", diff)
+	}
+	status := collector.Status()
+	wantDecisions := []Decision{
+		{Time: testNow, Target: DecisionTargetUpload, ID: "deleted_task_form", Reason: "orphaned from the inspection deleted", Size: 7},
+	}
+	if diff := cmp.Diff(wantDecisions, status.RecentDecisions); diff != "" {
+		t.Errorf("decisions mismatch (-want +got):\n%s", diff)
+	}
+	if status.UploadCount != 1 || status.UploadBytes != 5 {
+		t.Errorf("unexpected status %+v", status)
+	}
+}
+
+func TestCollectorRemovesKnownInspection(t *testing.T) {
+	inspectionServer, err := inspection.NewServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := inspectionServer.AddInspectionType(inspection.InspectionType{Id: "foo"}); err != nil {
+		t.Fatal(err)
+	}
+	inspectionID, err := inspectionServer.CreateInspection("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resultFolder := t.TempDir()
+	mustPlaceFile(t, filepath.Join(resultFolder, inspectionID+".khi"), 10, testNow.Add(-48*time.Hour))
+
+	collector := NewCollector(Policy{MaxAge: time.Hour}, inspectionServer, resultFolder, nil, time.Minute)
+	collector.now = func() time.Time { return testNow }
+	if err := collector.Collect(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if inspectionServer.GetInspection(inspectionID) != nil {
+		t.Errorf("the inspection %s was not removed from the inspection server", inspectionID)
+	}
+	if diff := cmp.Diff([]string{}, listFileNames(t, resultFolder)); diff != "" {
+		t.Errorf("remaining files mismatch (-want +got):\n%s", diff)
+	}
+}
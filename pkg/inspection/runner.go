@@ -26,7 +26,10 @@ import (
 	"github.com/GoogleCloudPlatform/khi/pkg/common/filter"
 	"github.com/GoogleCloudPlatform/khi/pkg/common/khictx"
 	"github.com/GoogleCloudPlatform/khi/pkg/common/typedmap"
+	"github.com/GoogleCloudPlatform/khi/pkg/inspection/budget"
 	inspection_task_contextkey "github.com/GoogleCloudPlatform/khi/pkg/inspection/contextkey"
+	"github.com/GoogleCloudPlatform/khi/pkg/inspection/credential"
+	"github.com/GoogleCloudPlatform/khi/pkg/inspection/event"
 	"github.com/GoogleCloudPlatform/khi/pkg/inspection/inspectiondata"
 	inspection_task_interface "github.com/GoogleCloudPlatform/khi/pkg/inspection/interface"
 	"github.com/GoogleCloudPlatform/khi/pkg/inspection/metadata"
@@ -37,18 +40,28 @@ import (
 	"github.com/GoogleCloudPlatform/khi/pkg/inspection/metadata/plan"
 	"github.com/GoogleCloudPlatform/khi/pkg/inspection/metadata/progress"
 	"github.com/GoogleCloudPlatform/khi/pkg/inspection/metadata/query"
+	"github.com/GoogleCloudPlatform/khi/pkg/inspection/registry"
 	inspection_task "github.com/GoogleCloudPlatform/khi/pkg/inspection/task"
 	"github.com/GoogleCloudPlatform/khi/pkg/inspection/task/serializer"
 	"github.com/GoogleCloudPlatform/khi/pkg/lifecycle"
+	"github.com/GoogleCloudPlatform/khi/pkg/log/logstore"
+	"github.com/GoogleCloudPlatform/khi/pkg/metrics"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/enum"
 	"github.com/GoogleCloudPlatform/khi/pkg/parameters"
 	"github.com/GoogleCloudPlatform/khi/pkg/task"
 	task_contextkey "github.com/GoogleCloudPlatform/khi/pkg/task/contextkey"
 	task_interface "github.com/GoogleCloudPlatform/khi/pkg/task/inteface"
 	"github.com/GoogleCloudPlatform/khi/pkg/task/taskid"
+	"github.com/GoogleCloudPlatform/khi/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 var inspectionRunnerGlobalSharedMap = typedmap.NewTypedMap()
 
+// maxBufferedInspectionEvents is the count of the latest events kept for clients resuming the event stream of an inspection.
+const maxBufferedInspectionEvents = 10000
+
 type InspectionTaskRunner struct {
 	inspectionServer      *InspectionTaskServer
 	ID                    string
@@ -61,7 +74,13 @@ type InspectionTaskRunner struct {
 	metadata              *typedmap.ReadonlyTypedMap
 	cancel                context.CancelFunc
 	inspectionSharedMap   *typedmap.TypedMap
+	credentialCache       *credential.Cache
+	events                *event.Stream
 	currentInspectionType string
+	// owner is the name of the principal who created this inspection. It is empty when the inspection has no owner.
+	owner string
+	// restoredRecord is the record this runner was restored from. Restored runners are read-only and don't have the underlying TaskRunner.
+	restoredRecord *registry.InspectionRecord
 }
 
 func NewInspectionRunner(server *InspectionTaskServer) *InspectionTaskRunner {
@@ -76,13 +95,40 @@ func NewInspectionRunner(server *InspectionTaskServer) *InspectionTaskRunner {
 		runnerLock:            sync.Mutex{},
 		metadata:              nil,
 		inspectionSharedMap:   typedmap.NewTypedMap(),
+		credentialCache:       credential.NewCache(),
 		cancel:                nil,
 		currentInspectionType: "N/A",
 	}
 }
 
 func (i *InspectionTaskRunner) Started() bool {
-	return i.runner != nil
+	return i.runner != nil || i.restoredRecord != nil
+}
+
+// Finished returns true when the inspection was started and its run has already ended regardless of its result.
+func (i *InspectionTaskRunner) Finished() bool {
+	if i.restoredRecord != nil {
+		return true
+	}
+	if i.runner == nil {
+		return false
+	}
+	select {
+	case <-i.runner.Wait():
+		return true
+	default:
+		return false
+	}
+}
+
+// Owner returns the name of the principal who created this inspection. It returns an empty string when the inspection has no owner.
+func (i *InspectionTaskRunner) Owner() string {
+	return i.owner
+}
+
+// Restored returns true when this runner was restored from the inspection registry.
+func (i *InspectionTaskRunner) Restored() bool {
+	return i.restoredRecord != nil
 }
 
 func (i *InspectionTaskRunner) SetInspectionType(inspectionType string) error {
@@ -116,6 +162,7 @@ func (i *InspectionTaskRunner) FeatureList() ([]FeatureListItem, error) {
 	for _, featureTask := range featureSet.GetAll() {
 		label := typedmap.GetOrDefault(featureTask.Labels(), inspection_task.LabelKeyFeatureTaskTitle, fmt.Sprintf("No label Set!(%s)", featureTask.UntypedID()))
 		description := typedmap.GetOrDefault(featureTask.Labels(), inspection_task.LabelKeyFeatureTaskDescription, "")
+		logType := typedmap.GetOrDefault(featureTask.Labels(), inspection_task.LabelKeyFeatureTaskTargetLogType, enum.LogTypeUnknown)
 		enabled := false
 		if v, exist := i.enabledFeatures[featureTask.UntypedID().String()]; exist && v {
 			enabled = true
@@ -125,12 +172,16 @@ func (i *InspectionTaskRunner) FeatureList() ([]FeatureListItem, error) {
 			Label:       label,
 			Description: description,
 			Enabled:     enabled,
+			LogType:     logType,
 		})
 	}
 	return features, nil
 }
 
 func (i *InspectionTaskRunner) SetFeatureList(featureList []string) error {
+	if i.restoredRecord != nil {
+		return fmt.Errorf("%w: %s", ErrReadonlyInspection, i.ID)
+	}
 	featureTasks := []task.UntypedTask{}
 	for _, featureId := range featureList {
 		featureTask, err := i.availableTasks.Get(featureId)
@@ -158,6 +209,9 @@ func (i *InspectionTaskRunner) SetFeatureList(featureList []string) error {
 // inputs:
 // featureMap: map of featureId and bool. If the value is true, the feature is enabled.
 func (i *InspectionTaskRunner) UpdateFeatureMap(featureMap map[string]bool) error {
+	if i.restoredRecord != nil {
+		return fmt.Errorf("%w: %s", ErrReadonlyInspection, i.ID)
+	}
 	for featureId := range featureMap {
 		task, err := i.availableTasks.Get(featureId)
 		if err != nil {
@@ -177,20 +231,29 @@ func (i *InspectionTaskRunner) UpdateFeatureMap(featureMap map[string]bool) erro
 }
 
 // withRunContextValues returns a context with the value specific to a single run of task.
-func (i *InspectionTaskRunner) withRunContextValues(ctx context.Context, runMode inspection_task_interface.InspectionTaskMode, taskInput map[string]any) context.Context {
+// Tokens of the credential given in the request are cached in the given credentialCache.
+func (i *InspectionTaskRunner) withRunContextValues(ctx context.Context, runMode inspection_task_interface.InspectionTaskMode, req *inspection_task.InspectionRequest, credentialCache *credential.Cache) context.Context {
 	rid := generateRandomString()
 	runCtx := khictx.WithValue(ctx, inspection_task_contextkey.InspectionTaskRunID, rid)
 	runCtx = khictx.WithValue(runCtx, inspection_task_contextkey.InspectionTaskInspectionID, i.ID)
+	runCtx = khictx.WithValue(runCtx, inspection_task_contextkey.InspectionTaskOwner, i.owner)
 	runCtx = khictx.WithValue(runCtx, inspection_task_contextkey.InspectionSharedMap, i.inspectionSharedMap)
 	runCtx = khictx.WithValue(runCtx, inspection_task_contextkey.GlobalSharedMap, inspectionRunnerGlobalSharedMap)
-	runCtx = khictx.WithValue(runCtx, inspection_task_contextkey.InspectionTaskInput, taskInput)
+	runCtx = khictx.WithValue(runCtx, inspection_task_contextkey.InspectionTaskInput, req.Values)
+	runCtx = khictx.WithValue(runCtx, inspection_task_contextkey.InspectionBudget, requestBudget(req))
+	runCtx = khictx.WithValue(runCtx, inspection_task_contextkey.InspectionVolumeEstimation, req.EstimateVolume)
+	runCtx = khictx.WithValue(runCtx, inspection_task_contextkey.InspectionSampling, req.Sampling)
+	runCtx = khictx.WithValue(runCtx, inspection_task_contextkey.InspectionCredentialCache, credentialCache)
+	if req.Credential != nil {
+		runCtx = khictx.WithValue(runCtx, inspection_task_contextkey.InspectionCredential, req.Credential)
+	}
 	return khictx.WithValue(runCtx, inspection_task_contextkey.InspectionTaskMode, runMode)
 }
 
 func (i *InspectionTaskRunner) Run(ctx context.Context, req *inspection_task.InspectionRequest) error {
 	defer i.runnerLock.Unlock()
 	i.runnerLock.Lock()
-	if i.runner != nil {
+	if i.Started() {
 		return fmt.Errorf("this task is already started")
 	}
 	currentInspectionType := i.inspectionServer.GetInspectionType(i.currentInspectionType)
@@ -199,31 +262,50 @@ func (i *InspectionTaskRunner) Run(ctx context.Context, req *inspection_task.Ins
 		return err
 	}
 
-	runCtx := i.withRunContextValues(ctx, inspection_task_interface.TaskModeRun, req.Values)
+	inspectionBudget := requestBudget(req)
+	runCtx := i.withRunContextValues(ctx, inspection_task_interface.TaskModeRun, req, i.credentialCache)
+	// Each inspection is the root of a trace. Spans of tasks are its children.
+	runCtx, span := tracing.Start(runCtx, fmt.Sprintf("inspection %s", i.currentInspectionType), trace.WithNewRoot(), trace.WithAttributes(
+		attribute.String("khi.inspection.id", i.ID),
+		attribute.String("khi.inspection.type", i.currentInspectionType),
+	))
+	i.events = event.NewStream(maxBufferedInspectionEvents)
 
 	runMetadata := i.generateMetadataForRun(runCtx, &header.Header{
 		InspectTimeUnixSeconds: time.Now().Unix(),
 		InspectionType:         currentInspectionType.Name,
 		InspectionTypeIconPath: currentInspectionType.Icon,
 		SuggestedFileName:      "unnamed.khi",
+		TraceID:                tracing.TraceID(runCtx),
 	}, runnableTaskGraph)
 
 	runCtx = khictx.WithValue(runCtx, inspection_task_contextkey.InspectionRunMetadata, runMetadata)
 
-	cancelableCtx, cancel := context.WithCancel(runCtx)
+	var cancelableCtx context.Context
+	var cancel context.CancelFunc
+	if inspectionBudget.MaxWallTime > 0 {
+		cancelableCtx, cancel = context.WithTimeout(runCtx, inspectionBudget.MaxWallTime)
+	} else {
+		cancelableCtx, cancel = context.WithCancel(runCtx)
+	}
 	i.cancel = cancel
 
 	runner, err := task.NewLocalRunner(runnableTaskGraph)
 	if err != nil {
+		tracing.EndSpan(span, err)
 		return err
 	}
 	i.runner = runner
 
 	i.metadata = runMetadata
+	startTime := time.Now()
+	metrics.InspectionsStarted.WithLabelValues(i.currentInspectionType).Inc()
+	metrics.InspectionsRunning.WithLabelValues(i.currentInspectionType).Inc()
 	lifecycle.Default.NotifyInspectionStart(khictx.MustGetValue(runCtx, inspection_task_contextkey.InspectionTaskRunID), currentInspectionType.Name)
 
 	err = i.runner.Run(cancelableCtx)
 	if err != nil {
+		tracing.EndSpan(span, err)
 		return err
 	}
 	go func() {
@@ -234,6 +316,7 @@ func (i *InspectionTaskRunner) Run(ctx context.Context, req *inspection_task.Ins
 		}
 		status := ""
 		resultSize := 0
+		var runErr error
 		if result, err := i.runner.Result(); err != nil {
 			if errors.Is(cancelableCtx.Err(), context.Canceled) {
 				progress.Cancel()
@@ -241,7 +324,12 @@ func (i *InspectionTaskRunner) Run(ctx context.Context, req *inspection_task.Ins
 			} else {
 				progress.Error()
 				status = "error"
+				if errors.Is(cancelableCtx.Err(), context.DeadlineExceeded) {
+					err = fmt.Errorf("%w\n%w", budget.ErrWallTimeExceeded, err)
+				}
+				i.addBudgetErrorMessage(inspectionBudget, err)
 			}
+			runErr = err
 			slog.WarnContext(runCtx, fmt.Sprintf("task %s was finished with an error\n%s", i.ID, err))
 		} else {
 			progress.Done()
@@ -258,14 +346,65 @@ func (i *InspectionTaskRunner) Run(ctx context.Context, req *inspection_task.Ins
 				if err != nil {
 					slog.ErrorContext(runCtx, fmt.Sprintf("Failed to get the serialized result size\n%s", err))
 				}
+				i.inspectionServer.saveRecord(runCtx, i, req.Values, history)
 			}
 		}
+		closeLogStores(runCtx, runner.TaskResults())
+		// Tokens obtained with the credential of the user must not outlive the inspection.
+		i.credentialCache.Clear()
+		i.events.Close()
+		observeInspectionMetrics(i.currentInspectionType, status, time.Since(startTime), resultSize, runnableTaskGraph, runner)
+		span.SetAttributes(attribute.String("khi.inspection.status", status), attribute.Int("khi.inspection.result_bytes", resultSize))
+		tracing.EndSpan(span, runErr)
 		lifecycle.Default.NotifyInspectionEnd(khictx.MustGetValue(runCtx, inspection_task_contextkey.InspectionTaskRunID), currentInspectionType.Name, status, resultSize)
 	}()
 	return nil
 }
 
+// closeLogStores closes every logstore.Store returned from tasks to remove its segment file. Stores are only read by tasks in the same run.
+func closeLogStores(ctx context.Context, results *typedmap.ReadonlyTypedMap) {
+	if results == nil {
+		return
+	}
+	for _, value := range results.Values() {
+		store, ok := value.(*logstore.Store)
+		if !ok || store == nil {
+			continue
+		}
+		if err := store.Close(); err != nil {
+			slog.WarnContext(ctx, fmt.Sprintf("failed to close a log store\n%v", err))
+		}
+	}
+}
+
+// observeInspectionMetrics records the metrics of a finished inspection and the durations of its tasks.
+func observeInspectionMetrics(inspectionType string, status string, duration time.Duration, resultSize int, taskGraph *task.TaskSet, runner *task.LocalRunner) {
+	metrics.InspectionsRunning.WithLabelValues(inspectionType).Dec()
+	metrics.InspectionsFinished.WithLabelValues(inspectionType, status).Inc()
+	metrics.InspectionDuration.WithLabelValues(inspectionType, status).Observe(duration.Seconds())
+	if status == "done" {
+		metrics.InspectionResultBytes.WithLabelValues(inspectionType).Observe(float64(resultSize))
+	}
+	tasks := taskGraph.GetAll()
+	for index, stat := range runner.TaskStatuses() {
+		if stat.Phase != task.LocalRunnerTaskStatPhaseStopped || index >= len(tasks) {
+			continue
+		}
+		metrics.TaskDuration.WithLabelValues(inspectionType, tasks[index].UntypedID().ReferenceIDString()).Observe(stat.EndTime.Sub(stat.StartTime).Seconds())
+	}
+}
+
 func (i *InspectionTaskRunner) Result() (*InspectionRunResult, error) {
+	if i.restoredRecord != nil {
+		md, err := metadata.GetSerializableSubsetMapFromMetadataSet(i.metadata, filter.NewEnabledFilter(metadata.LabelKeyIncludedInRunResultFlag, false))
+		if err != nil {
+			return nil, err
+		}
+		return &InspectionRunResult{
+			Metadata:    md,
+			ResultStore: inspectiondata.NewFileSystemInspectionResultRepository(i.restoredRecord.ResultPath),
+		}, nil
+	}
 	if i.runner == nil {
 		return nil, fmt.Errorf("this task is not yet started")
 	}
@@ -290,8 +429,26 @@ func (i *InspectionTaskRunner) Result() (*InspectionRunResult, error) {
 	}, nil
 }
 
+// Events returns the stream of events published during the run of this inspection.
+// The stream of a restored inspection only has the phase transition to DONE because only completed inspections are restored.
+func (i *InspectionTaskRunner) Events() (*event.Stream, error) {
+	if i.restoredRecord != nil {
+		events := event.NewStream(1)
+		events.OnPhaseChanged(progress.TASK_PHASE_DONE)
+		events.Close()
+		return events, nil
+	}
+	i.runnerLock.Lock()
+	events := i.events
+	i.runnerLock.Unlock()
+	if events == nil {
+		return nil, fmt.Errorf("this task is not yet started")
+	}
+	return events, nil
+}
+
 func (i *InspectionTaskRunner) Metadata() (map[string]any, error) {
-	if i.runner == nil {
+	if !i.Started() {
 		return nil, fmt.Errorf("this task is not yet started")
 	}
 	md, err := metadata.GetSerializableSubsetMapFromMetadataSet(i.metadata, filter.NewEnabledFilter(metadata.LabelKeyIncludedInRunResultFlag, false))
@@ -315,7 +472,10 @@ func (i *InspectionTaskRunner) DryRun(ctx context.Context, req *inspection_task.
 		return nil, err
 	}
 
-	runCtx := i.withRunContextValues(ctx, inspection_task_interface.TaskModeDryRun, req.Values)
+	// Tokens obtained in a dry run are discarded when it ends. A dry run doesn't share the cache with the run because the run may be in progress.
+	dryRunCredentialCache := credential.NewCache()
+	defer dryRunCredentialCache.Clear()
+	runCtx := i.withRunContextValues(ctx, inspection_task_interface.TaskModeDryRun, req, dryRunCredentialCache)
 
 	dryrunMetadata := i.generateMetadataForDryRun(runCtx, &header.Header{}, runnableTaskGraph)
 
@@ -326,6 +486,7 @@ func (i *InspectionTaskRunner) DryRun(ctx context.Context, req *inspection_task.
 		return nil, err
 	}
 	<-runner.Wait()
+	closeLogStores(runCtx, runner.TaskResults())
 	_, err = runner.Result()
 	if err != nil {
 		slog.ErrorContext(runCtx, err.Error())
@@ -340,8 +501,30 @@ func (i *InspectionTaskRunner) DryRun(ctx context.Context, req *inspection_task.
 	}, nil
 }
 
-func (i *InspectionTaskRunner) MakeLoggers(ctx context.Context, minLevel slog.Level, m *typedmap.ReadonlyTypedMap, tasks []task.UntypedTask) *logger.Logger {
+// addBudgetErrorMessage adds the message shown on the frontend when the inspection failed due to its budget.
+func (i *InspectionTaskRunner) addBudgetErrorMessage(inspectionBudget *budget.Budget, err error) {
+	message := inspectionBudget.ErrorMessage(err)
+	if message == nil {
+		return
+	}
+	errorMessageSet, found := typedmap.Get(i.metadata, error_metadata.ErrorMessageSetMetadataKey)
+	if !found {
+		return
+	}
+	errorMessageSet.AddErrorMessage(message)
+}
+
+// requestBudget returns the budget given in the request or the default budget.
+func requestBudget(req *inspection_task.InspectionRequest) *budget.Budget {
+	if req.Budget != nil {
+		return req.Budget
+	}
+	return budget.Default
+}
+
+func (i *InspectionTaskRunner) MakeLoggers(ctx context.Context, minLevel slog.Level, m *typedmap.ReadonlyTypedMap, tasks []task.UntypedTask, listener logger.Listener) *logger.Logger {
 	logger := logger.NewLogger()
+	logger.SetListener(listener)
 	for _, def := range tasks {
 		taskCtx := khictx.WithValue(ctx, task_contextkey.TaskImplementationIDContextKey, def.UntypedID())
 		logger.MakeTaskLogger(taskCtx, minLevel)
@@ -356,6 +539,9 @@ func (i *InspectionTaskRunner) GetCurrentMetadata() (*typedmap.ReadonlyTypedMap,
 }
 
 func (i *InspectionTaskRunner) Cancel() error {
+	if i.restoredRecord != nil {
+		return fmt.Errorf("task %s is already finished", i.ID)
+	}
 	if i.cancel == nil {
 		return fmt.Errorf("this task is not yet started")
 	}
@@ -367,6 +553,11 @@ func (i *InspectionTaskRunner) Cancel() error {
 }
 
 func (i *InspectionTaskRunner) Wait() <-chan interface{} {
+	if i.restoredRecord != nil {
+		finished := make(chan interface{})
+		close(finished)
+		return finished
+	}
 	return i.runner.Wait()
 }
 
@@ -402,23 +593,31 @@ func (i *InspectionTaskRunner) resolveTaskGraph() (*task.TaskSet, error) {
 
 func (i *InspectionTaskRunner) generateMetadataForDryRun(ctx context.Context, initHeader *header.Header, taskGraph *task.TaskSet) *typedmap.ReadonlyTypedMap {
 	writableMetadata := typedmap.NewTypedMap()
-	i.addCommonMetadata(ctx, writableMetadata, initHeader, taskGraph)
+	i.addCommonMetadata(ctx, writableMetadata, initHeader, taskGraph, nil)
 	return writableMetadata.AsReadonly()
 }
 
 func (i *InspectionTaskRunner) generateMetadataForRun(ctx context.Context, initHeader *header.Header, taskGraph *task.TaskSet) *typedmap.ReadonlyTypedMap {
 	writableMetadata := typedmap.NewTypedMap()
-	i.addCommonMetadata(ctx, writableMetadata, initHeader, taskGraph)
+	i.addCommonMetadata(ctx, writableMetadata, initHeader, taskGraph, i.events)
 	return writableMetadata.AsReadonly()
 }
 
-func (i *InspectionTaskRunner) addCommonMetadata(ctx context.Context, writableMetadata *typedmap.TypedMap, initHeader *header.Header, taskGraph *task.TaskSet) {
+// addCommonMetadata adds the metadata used in both of dry runs and runs. Changes of the metadata are published to the given event stream when it's not nil.
+func (i *InspectionTaskRunner) addCommonMetadata(ctx context.Context, writableMetadata *typedmap.TypedMap, initHeader *header.Header, taskGraph *task.TaskSet, events *event.Stream) {
+	errorMessageSet := error_metadata.NewErrorMessageSet()
+	progressMeta := progress.NewProgress()
+	var logListener logger.Listener
+	if events != nil {
+		errorMessageSet.SetListener(events)
+		progressMeta.SetListener(events)
+		logListener = events
+	}
 	typedmap.Set(writableMetadata, header.HeaderMetadataKey, initHeader)
-	typedmap.Set(writableMetadata, error_metadata.ErrorMessageSetMetadataKey, error_metadata.NewErrorMessageSet())
+	typedmap.Set(writableMetadata, error_metadata.ErrorMessageSetMetadataKey, errorMessageSet)
 	typedmap.Set(writableMetadata, form.FormFieldSetMetadataKey, form.NewFormFieldSet())
 	typedmap.Set(writableMetadata, query.QueryMetadataKey, query.NewQueryMetadata())
 
-	progressMeta := progress.NewProgress()
 	progressMeta.SetTotalTaskCount(len(task.Subset(taskGraph, filter.NewEnabledFilter(inspection_task.LabelKeyProgressReportable, false)).GetAll()))
 	typedmap.Set(writableMetadata, progress.ProgressMetadataKey, progressMeta)
 
@@ -428,7 +627,7 @@ func (i *InspectionTaskRunner) addCommonMetadata(ctx context.Context, writableMe
 	}
 	typedmap.Set(writableMetadata, plan.InspectionPlanMetadataKey, plan.NewInspectionPlan(taskGraphStr))
 
-	i.MakeLoggers(ctx, getLogLevel(), writableMetadata.AsReadonly(), taskGraph.GetAll())
+	i.MakeLoggers(ctx, getLogLevel(), writableMetadata.AsReadonly(), taskGraph.GetAll(), logListener)
 }
 
 func generateRandomString() string {
@@ -0,0 +1,384 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/inspection"
+	"github.com/GoogleCloudPlatform/khi/pkg/inspection/jobspec"
+	inspection_task "github.com/GoogleCloudPlatform/khi/pkg/inspection/task"
+	"github.com/GoogleCloudPlatform/khi/pkg/lifecycle"
+)
+
+// maxRecentRuns is the count of runs kept for the status of each job.
+const maxRecentRuns = 20
+
+// RunStatus is the status of a scheduled run.
+type RunStatus string
+
+const (
+	RunStatusRunning RunStatus = "running"
+	RunStatusDone    RunStatus = "done"
+	RunStatusError   RunStatus = "error"
+	// RunStatusSkipped is the status of a run skipped because the previous run of the job was still running.
+	RunStatusSkipped RunStatus = "skipped"
+)
+
+// Run is a run of a scheduled job.
+type Run struct {
+	ScheduledAt  time.Time `json:"scheduledAt"`
+	FinishedAt   time.Time `json:"finishedAt,omitempty"`
+	InspectionID string    `json:"inspectionId,omitempty"`
+	Status       RunStatus `json:"status"`
+	OutputPath   string    `json:"outputPath,omitempty"`
+	Error        string    `json:"error,omitempty"`
+}
+
+// JobStatus is the schedule and the recent runs of a job.
+type JobStatus struct {
+	Name           string    `json:"name"`
+	Schedule       string    `json:"schedule"`
+	InspectionType string    `json:"inspectionType"`
+	NextRunAt      time.Time `json:"nextRunAt"`
+	Outputs        []string  `json:"outputs"`
+	RecentRuns     []Run     `json:"recentRuns"`
+}
+
+// Status is the status of every job managed by the Scheduler.
+type Status struct {
+	Jobs []JobStatus `json:"jobs"`
+}
+
+// persistedJobState is the state of a job stored in the state folder to rotate outputs across restarts.
+type persistedJobState struct {
+	// Outputs is the list of output file paths written by the job from the oldest.
+	Outputs []string `json:"outputs"`
+}
+
+// job is a JobSpec with its runtime state.
+type job struct {
+	spec      *JobSpec
+	nextRunAt time.Time
+	// running is the run in progress. It is nil when the job is idle.
+	running    *Run
+	outputs    []string
+	recentRuns []Run
+}
+
+// Scheduler runs inspections defined by JobSpecs on their cron schedules.
+// Runs are regular inspections of the inspection server and appear in the inspection list.
+type Scheduler struct {
+	inspectionServer *inspection.InspectionTaskServer
+	stateFolder      string
+	now              func() time.Time
+
+	// lock guards jobs.
+	lock sync.Mutex
+	jobs []*job
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewScheduler returns a Scheduler running the given jobs. The state of jobs is stored in stateFolder.
+func NewScheduler(specs []*JobSpec, inspectionServer *inspection.InspectionTaskServer, stateFolder string) (*Scheduler, error) {
+	if err := os.MkdirAll(stateFolder, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create the scheduler state folder %s\n%w", stateFolder, err)
+	}
+	s := &Scheduler{
+		inspectionServer: inspectionServer,
+		stateFolder:      stateFolder,
+		now:              time.Now,
+		jobs:             make([]*job, 0, len(specs)),
+		stop:             make(chan struct{}),
+	}
+	for _, spec := range specs {
+		if inspectionServer.GetInspectionType(spec.InspectionType) == nil {
+			return nil, fmt.Errorf("inspection type %s of job %s is not found", spec.InspectionType, spec.Name)
+		}
+		state, err := s.loadState(spec.Name)
+		if err != nil {
+			return nil, err
+		}
+		s.jobs = append(s.jobs, &job{
+			spec:       spec,
+			outputs:    state.Outputs,
+			recentRuns: []Run{},
+		})
+	}
+	return s, nil
+}
+
+// RegisterLifecycleHandler makes the Scheduler start scheduling after the init event until the terminate event.
+func (s *Scheduler) RegisterLifecycleHandler(notifier *lifecycle.LifecycleEventNotifier) {
+	notifier.AddHandler(&lifecycle.LifecycleEventHandler{
+		OnInit: func() {
+			go s.run()
+		},
+		OnTerminate: func(sig os.Signal) {
+			s.stopOnce.Do(func() { close(s.stop) })
+		},
+	})
+}
+
+func (s *Scheduler) run() {
+	s.lock.Lock()
+	now := s.now()
+	for _, j := range s.jobs {
+		j.nextRunAt = j.spec.Next(now)
+	}
+	s.lock.Unlock()
+	for {
+		next := s.nextWakeUp()
+		if next.IsZero() {
+			slog.Warn("No scheduled job will run anymore")
+			return
+		}
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-s.stop:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		s.runDueJobs(context.Background())
+	}
+}
+
+// nextWakeUp returns the earliest next run time of the jobs. It returns the zero time when no job will run.
+func (s *Scheduler) nextWakeUp() time.Time {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	var next time.Time
+	for _, j := range s.jobs {
+		if j.nextRunAt.IsZero() {
+			continue
+		}
+		if next.IsZero() || j.nextRunAt.Before(next) {
+			next = j.nextRunAt
+		}
+	}
+	return next
+}
+
+// runDueJobs starts the jobs whose next run time has come and advances their next run time.
+// A run is skipped when the previous run of the job is still running.
+func (s *Scheduler) runDueJobs(ctx context.Context) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	now := s.now()
+	for _, j := range s.jobs {
+		if j.nextRunAt.IsZero() || j.nextRunAt.After(now) {
+			continue
+		}
+		scheduledAt := j.nextRunAt
+		j.nextRunAt = j.spec.Next(now)
+		if j.running != nil {
+			slog.WarnContext(ctx, fmt.Sprintf("Skipping the scheduled run of job %s because the previous run is still running", j.spec.Name))
+			j.addRun(Run{ScheduledAt: scheduledAt, FinishedAt: now, Status: RunStatusSkipped})
+			continue
+		}
+		j.running = &Run{ScheduledAt: scheduledAt, Status: RunStatusRunning}
+		go func(j *job) {
+			run := s.runJob(ctx, j.spec, scheduledAt)
+			s.lock.Lock()
+			defer s.lock.Unlock()
+			j.running = nil
+			j.addRun(run)
+			if run.Status == RunStatusDone && run.OutputPath != "" {
+				s.rotateOutputs(ctx, j, run.OutputPath)
+			}
+		}(j)
+	}
+}
+
+// runJob runs an inspection for the job and writes the result to the output path.
+func (s *Scheduler) runJob(ctx context.Context, spec *JobSpec, scheduledAt time.Time) Run {
+	run := Run{ScheduledAt: scheduledAt, Status: RunStatusError}
+	inspectionID, outputPath, err := s.inspect(ctx, spec, scheduledAt)
+	run.InspectionID = inspectionID
+	run.FinishedAt = s.now()
+	if err != nil {
+		slog.ErrorContext(ctx, fmt.Sprintf("Scheduled job %s failed\n%v", spec.Name, err))
+		run.Error = err.Error()
+		return run
+	}
+	slog.InfoContext(ctx, fmt.Sprintf("Scheduled job %s completed the inspection %s", spec.Name, inspectionID))
+	run.Status = RunStatusDone
+	run.OutputPath = outputPath
+	return run
+}
+
+func (s *Scheduler) inspect(ctx context.Context, spec *JobSpec, scheduledAt time.Time) (string, string, error) {
+	values, err := jobspec.ResolveValues(spec.Values, scheduledAt)
+	if err != nil {
+		return "", "", err
+	}
+	inspectionID, err := s.inspectionServer.CreateInspection(spec.InspectionType)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to create an inspection with type %s\n%w", spec.InspectionType, err)
+	}
+	runner := s.inspectionServer.GetInspection(inspectionID)
+	availableFeatures, err := runner.FeatureList()
+	if err != nil {
+		return inspectionID, "", fmt.Errorf("failed to obtain the feature list\n%w", err)
+	}
+	features, err := spec.SelectFeatures(availableFeatures)
+	if err != nil {
+		return inspectionID, "", err
+	}
+	if err := runner.SetFeatureList(features); err != nil {
+		return inspectionID, "", fmt.Errorf("failed to set features %v\n%w", features, err)
+	}
+	if err := runner.Run(ctx, &inspection_task.InspectionRequest{Values: values}); err != nil {
+		return inspectionID, "", fmt.Errorf("failed to run the inspection\n%w", err)
+	}
+	<-runner.Wait()
+	result, err := runner.Result()
+	if err != nil {
+		return inspectionID, "", fmt.Errorf("failed to get the inspection result\n%w", err)
+	}
+	outputPath, err := spec.OutputPath(inspectionID, scheduledAt)
+	if err != nil {
+		return inspectionID, "", fmt.Errorf("failed to generate the output path\n%w", err)
+	}
+	if outputPath == "" {
+		return inspectionID, "", nil
+	}
+	reader, err := result.ResultStore.GetReader()
+	if err != nil {
+		return inspectionID, "", fmt.Errorf("failed to read the inspection result\n%w", err)
+	}
+	defer reader.Close()
+	if err := writeOutput(outputPath, reader); err != nil {
+		return inspectionID, "", err
+	}
+	return inspectionID, outputPath, nil
+}
+
+// writeOutput writes the result to a temporary file and renames it not to leave a partial output.
+func writeOutput(outputPath string, reader io.Reader) error {
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+		return fmt.Errorf("failed to create the output folder\n%w", err)
+	}
+	tmpFile, err := os.CreateTemp(filepath.Dir(outputPath), "tmp-output-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpFile.Name())
+	if _, err := io.Copy(tmpFile, reader); err != nil {
+		tmpFile.Close()
+		return fmt.Errorf("failed to write the output %s\n%w", outputPath, err)
+	}
+	if err := tmpFile.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmpFile.Name(), outputPath)
+}
+
+// rotateOutputs records the new output of the job and removes the oldest outputs exceeding Keep.
+func (s *Scheduler) rotateOutputs(ctx context.Context, j *job, outputPath string) {
+	outputs := []string{}
+	for _, output := range j.outputs {
+		// An output overwritten by the new run must not be removed.
+		if output != outputPath {
+			outputs = append(outputs, output)
+		}
+	}
+	outputs = append(outputs, outputPath)
+	if j.spec.Keep > 0 && len(outputs) > j.spec.Keep {
+		for _, output := range outputs[:len(outputs)-j.spec.Keep] {
+			err := os.Remove(output)
+			if err != nil && !errors.Is(err, os.ErrNotExist) {
+				slog.ErrorContext(ctx, fmt.Sprintf("Failed to remove the old output %s of job %s\n%v", output, j.spec.Name, err))
+				continue
+			}
+			slog.InfoContext(ctx, fmt.Sprintf("Removed the old output %s of job %s", output, j.spec.Name))
+		}
+		outputs = outputs[len(outputs)-j.spec.Keep:]
+	}
+	j.outputs = outputs
+	if err := s.saveState(j.spec.Name, &persistedJobState{Outputs: outputs}); err != nil {
+		slog.ErrorContext(ctx, fmt.Sprintf("Failed to save the state of job %s\n%v", j.spec.Name, err))
+	}
+}
+
+// Status returns the schedule and the recent runs of every job.
+func (s *Scheduler) Status() Status {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	status := Status{Jobs: make([]JobStatus, 0, len(s.jobs))}
+	for _, j := range s.jobs {
+		recentRuns := append([]Run{}, j.recentRuns...)
+		if j.running != nil {
+			recentRuns = append(recentRuns, *j.running)
+		}
+		status.Jobs = append(status.Jobs, JobStatus{
+			Name:           j.spec.Name,
+			Schedule:       j.spec.Schedule,
+			InspectionType: j.spec.InspectionType,
+			NextRunAt:      j.nextRunAt,
+			Outputs:        append([]string{}, j.outputs...),
+			RecentRuns:     recentRuns,
+		})
+	}
+	return status
+}
+
+func (j *job) addRun(run Run) {
+	j.recentRuns = append(j.recentRuns, run)
+	if len(j.recentRuns) > maxRecentRuns {
+		j.recentRuns = j.recentRuns[len(j.recentRuns)-maxRecentRuns:]
+	}
+}
+
+func (s *Scheduler) statePath(name string) string {
+	return filepath.Join(s.stateFolder, name+".json")
+}
+
+func (s *Scheduler) loadState(name string) (*persistedJobState, error) {
+	state := &persistedJobState{Outputs: []string{}}
+	data, err := os.ReadFile(s.statePath(name))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return state, nil
+		}
+		return nil, fmt.Errorf("failed to read the state of job %s\n%w", name, err)
+	}
+	if err := json.Unmarshal(data, state); err != nil {
+		// A broken state only loses the outputs to rotate.
+		slog.Warn(fmt.Sprintf("ignoring the broken state of job %s\n%v", name, err))
+		return &persistedJobState{Outputs: []string{}}, nil
+	}
+	return state, nil
+}
+
+func (s *Scheduler) saveState(name string, state *persistedJobState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.statePath(name), data, 0644)
+}
@@ -0,0 +1,106 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scheduler
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/inspection/jobspec"
+	"github.com/google/go-cmp/cmp"
+
+	_ "github.com/GoogleCloudPlatform/khi/internal/testflags"
+)
+
+func newTestJob(t *testing.T, keep int) *job {
+	t.Helper()
+	spec := &JobSpec{
+		Name:     "test-job",
+		Schedule: "@hourly",
+		InspectionSpec: jobspec.InspectionSpec{
+			InspectionType: "test",
+			Features:       jobspec.FeatureSelector{All: true},
+		},
+		Keep: keep,
+	}
+	if err := spec.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	return &job{spec: spec, outputs: []string{}, recentRuns: []Run{}}
+}
+
+func TestRotateOutputs(t *testing.T) {
+	folder := t.TempDir()
+	s := &Scheduler{stateFolder: folder, now: time.Now}
+	j := newTestJob(t, 2)
+	outputs := []string{}
+	for _, name := range []string{"a.khi", "b.khi", "c.khi"} {
+		output := filepath.Join(folder, name)
+		if err := os.WriteFile(output, []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+		outputs = append(outputs, output)
+		s.rotateOutputs(context.Background(), j, output)
+	}
+	// Overwriting the latest output must not remove it.
+	s.rotateOutputs(context.Background(), j, outputs[2])
+
+	if diff := cmp.Diff(outputs[1:], j.outputs); diff != "" {
+		t.Errorf("outputs mismatch (-want +got):\n%s", diff)
+	}
+	if _, err := os.Stat(outputs[0]); !os.IsNotExist(err) {
+		t.Errorf("the oldest output was not removed: %v", err)
+	}
+	for _, output := range outputs[1:] {
+		if _, err := os.Stat(output); err != nil {
+			t.Errorf("the output %s was removed unexpectedly: %v", output, err)
+		}
+	}
+	state, err := s.loadState(j.spec.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(outputs[1:], state.Outputs); diff != "" {
+		t.Errorf("persisted outputs mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestRunDueJobsSkipsRunningJob(t *testing.T) {
+	now := time.Date(2025, time.January, 1, 10, 0, 30, 0, time.UTC)
+	s := &Scheduler{now: func() time.Time { return now }}
+	j := newTestJob(t, 0)
+	j.nextRunAt = time.Date(2025, time.January, 1, 10, 0, 0, 0, time.UTC)
+	j.running = &Run{ScheduledAt: time.Date(2025, time.January, 1, 9, 0, 0, 0, time.UTC), Status: RunStatusRunning}
+	s.jobs = []*job{j}
+
+	s.runDueJobs(context.Background())
+
+	if want := time.Date(2025, time.January, 1, 11, 0, 0, 0, time.UTC); !j.nextRunAt.Equal(want) {
+		t.Errorf("nextRunAt = %s, want %s", j.nextRunAt, want)
+	}
+	wantRuns := []Run{
+		{ScheduledAt: time.Date(2025, time.January, 1, 10, 0, 0, 0, time.UTC), FinishedAt: now, Status: RunStatusSkipped},
+	}
+	if diff := cmp.Diff(wantRuns, j.recentRuns); diff != "" {
+		t.Errorf("recent runs mismatch (-want +got):\n%s", diff)
+	}
+	status := s.Status()
+	if got := len(status.Jobs[0].RecentRuns); got != 2 {
+		t.Errorf("the status has %d runs, want 2 including the running one", got)
+	}
+}
@@ -0,0 +1,140 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scheduler
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"regexp"
+	"text/template"
+	"time"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/common/cron"
+	"github.com/GoogleCloudPlatform/khi/pkg/inspection/jobspec"
+	"gopkg.in/yaml.v3"
+)
+
+// outputTimeFormat is the format of .Time given to the output path template.
+const outputTimeFormat = "20060102-150405"
+
+var jobNamePattern = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// JobSpec is a declarative definition of an inspection run on a cron schedule.
+type JobSpec struct {
+	// Name is the unique name of the job. It must consist of alphanumerics, `-` or `_`.
+	Name string `yaml:"name" json:"name"`
+	// Schedule is the cron expression of the job. See cron.Parse for the supported syntax.
+	Schedule string `yaml:"schedule" json:"schedule"`
+	// InspectionSpec is the inspection to run. Relative time expressions in its values are resolved at each run.
+	jobspec.InspectionSpec `yaml:",inline"`
+	// Output is the template of the file path to write the .khi file. `{{.Name}}`, `{{.InspectionID}}` and `{{.Time}}` are available.
+	Output string `yaml:"output" json:"output"`
+	// Keep is the count of output files kept for the job. Older outputs are removed. 0 keeps every output.
+	Keep int `yaml:"keep" json:"keep"`
+
+	schedule       *cron.Schedule
+	outputTemplate *template.Template
+}
+
+// specFile is the root of the job spec file.
+type specFile struct {
+	Jobs []*JobSpec `yaml:"jobs"`
+}
+
+// outputTemplateValues is the values given to the output path template.
+type outputTemplateValues struct {
+	Name         string
+	InspectionID string
+	Time         string
+}
+
+// LoadJobSpecs reads and validates job specs from the YAML file at the given path.
+func LoadJobSpecs(path string) ([]*JobSpec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the schedule file %s\n%w", path, err)
+	}
+	return ParseJobSpecs(data)
+}
+
+// ParseJobSpecs parses and validates job specs from the YAML data.
+func ParseJobSpecs(data []byte) ([]*JobSpec, error) {
+	var file specFile
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&file); err != nil {
+		return nil, fmt.Errorf("failed to parse the schedule file\n%w", err)
+	}
+	names := map[string]struct{}{}
+	for i, spec := range file.Jobs {
+		if err := spec.Validate(); err != nil {
+			return nil, fmt.Errorf("job #%d is invalid\n%w", i, err)
+		}
+		if _, found := names[spec.Name]; found {
+			return nil, fmt.Errorf("job name %q is duplicated", spec.Name)
+		}
+		names[spec.Name] = struct{}{}
+	}
+	return file.Jobs, nil
+}
+
+// Validate checks the fields of the spec and prepares the parsed schedule and output template.
+func (s *JobSpec) Validate() error {
+	if !jobNamePattern.MatchString(s.Name) {
+		return fmt.Errorf("job name %q must consist of alphanumerics, `-` or `_`", s.Name)
+	}
+	if err := s.InspectionSpec.Validate(); err != nil {
+		return fmt.Errorf("job %s has an invalid inspection\n%w", s.Name, err)
+	}
+	if s.Keep < 0 {
+		return fmt.Errorf("keep of job %s must not be negative", s.Name)
+	}
+	schedule, err := cron.Parse(s.Schedule)
+	if err != nil {
+		return fmt.Errorf("job %s has an invalid schedule\n%w", s.Name, err)
+	}
+	s.schedule = schedule
+	if s.Output != "" {
+		outputTemplate, err := template.New(s.Name).Option("missingkey=error").Parse(s.Output)
+		if err != nil {
+			return fmt.Errorf("job %s has an invalid output template\n%w", s.Name, err)
+		}
+		s.outputTemplate = outputTemplate
+	}
+	return nil
+}
+
+// Next returns the next time to run the job after the given time.
+func (s *JobSpec) Next(after time.Time) time.Time {
+	return s.schedule.Next(after)
+}
+
+// OutputPath returns the output file path for the run. It returns an empty string when the job doesn't write the output.
+func (s *JobSpec) OutputPath(inspectionID string, runTime time.Time) (string, error) {
+	if s.outputTemplate == nil {
+		return "", nil
+	}
+	var buf bytes.Buffer
+	err := s.outputTemplate.Execute(&buf, outputTemplateValues{
+		Name:         s.Name,
+		InspectionID: inspectionID,
+		Time:         runTime.UTC().Format(outputTimeFormat),
+	})
+	if err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,172 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scheduler
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/inspection/jobspec"
+	"github.com/google/go-cmp/cmp"
+
+	_ "github.com/GoogleCloudPlatform/khi/internal/testflags"
+)
+
+func TestParseJobSpecs(t *testing.T) {
+	testCases := []struct {
+		name        string
+		input       string
+		wantNames   []string
+		wantErrPart string
+	}{
+		{
+			name: "valid jobs",
+			input: `
+jobs:
+- name: critical-cluster
+  schedule: "0 * * * *"
+  inspectionType: gcp-gke
+  features: {all: true}
+  values:
+    project_id: foo
+    end_time: now
+    duration: 2h
+  output: /tmp/{{.Name}}-{{.Time}}.khi
+  keep: 24
+- name: nightly
+  schedule: "@daily"
+  inspectionType: gcp-gke
+  features: {logTypes: [k8s_audit]}
+`,
+			wantNames: []string{"critical-cluster", "nightly"},
+		},
+		{
+			name: "invalid name",
+			input: `
+jobs:
+- name: "a/b"
+  schedule: "@daily"
+  inspectionType: gcp-gke
+  features: {all: true}
+`,
+			wantErrPart: "alphanumerics",
+		},
+		{
+			name: "invalid schedule",
+			input: `
+jobs:
+- name: a
+  schedule: "* * *"
+  inspectionType: gcp-gke
+  features: {all: true}
+`,
+			wantErrPart: "invalid schedule",
+		},
+		{
+			name: "unknown log type",
+			input: `
+jobs:
+- name: a
+  schedule: "@daily"
+  inspectionType: gcp-gke
+  features: {logTypes: [k8s_foo]}
+`,
+			wantErrPart: "unknown log type",
+		},
+		{
+			name: "duplicated name",
+			input: `
+jobs:
+- name: a
+  schedule: "@daily"
+  inspectionType: gcp-gke
+  features: {all: true}
+- name: a
+  schedule: "@hourly"
+  inspectionType: gcp-gke
+  features: {all: true}
+`,
+			wantErrPart: "duplicated",
+		},
+		{
+			name: "unknown field",
+			input: `
+jobs:
+- name: a
+  schedule: "@daily"
+  inspectionType: gcp-gke
+  features: {all: true}
+  keepOutputs: 3
+`,
+			wantErrPart: "keepOutputs",
+		},
+		{
+			name: "invalid output template",
+			input: `
+jobs:
+- name: a
+  schedule: "@daily"
+  inspectionType: gcp-gke
+  features: {all: true}
+  output: "{{.Name"
+`,
+			wantErrPart: "invalid output template",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			specs, err := ParseJobSpecs([]byte(tc.input))
+			if tc.wantErrPart != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErrPart) {
+					t.Fatalf("ParseJobSpecs() error = %v, want an error containing %q", err, tc.wantErrPart)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			names := []string{}
+			for _, spec := range specs {
+				names = append(names, spec.Name)
+			}
+			if diff := cmp.Diff(tc.wantNames, names); diff != "" {
+				t.Errorf("job names mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestJobSpecOutputPath(t *testing.T) {
+	spec := &JobSpec{
+		Name:     "critical",
+		Schedule: "@hourly",
+		InspectionSpec: jobspec.InspectionSpec{
+			InspectionType: "gcp-gke",
+			Features:       jobspec.FeatureSelector{All: true},
+		},
+		Output: "/data/{{.Name}}/{{.Time}}-{{.InspectionID}}.khi",
+	}
+	if err := spec.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	got, err := spec.OutputPath("abc", time.Date(2025, time.January, 2, 3, 4, 5, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "/data/critical/20250102-030405-abc.khi"; got != want {
+		t.Errorf("OutputPath() = %q, want %q", got, want)
+	}
+}
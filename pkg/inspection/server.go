@@ -15,15 +15,31 @@
 package inspection
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"log/slog"
+	"os"
 	"strings"
+	"sync"
 
 	"golang.org/x/exp/slices"
 
 	"github.com/GoogleCloudPlatform/khi/pkg/inspection/inspectiondata"
+	"github.com/GoogleCloudPlatform/khi/pkg/inspection/registry"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/enum"
 	"github.com/GoogleCloudPlatform/khi/pkg/task"
 )
 
+// ErrInspectionNotFound is returned when the requested inspection doesn't exist.
+var ErrInspectionNotFound = errors.New("inspection not found")
+
+// ErrInspectionRunning is returned when the requested operation is not allowed for running inspections.
+var ErrInspectionRunning = errors.New("inspection is running")
+
+// ErrReadonlyInspection is returned when the requested operation modifies an inspection restored from the registry.
+var ErrReadonlyInspection = errors.New("inspection is read-only")
+
 type PrepareInspectionServerFunc = func(inspectionServer *InspectionTaskServer) error
 
 type InspectionType struct {
@@ -42,6 +58,8 @@ type FeatureListItem struct {
 	Label       string `json:"label"`
 	Description string `json:"description"`
 	Enabled     bool   `json:"enabled"`
+	// LogType is the type of logs queried by the feature. It is used to select features in job specs.
+	LogType enum.LogType `json:"-"`
 }
 
 type InspectionDryRunResult struct {
@@ -61,6 +79,10 @@ type InspectionTaskServer struct {
 	inspectionTypes []*InspectionType
 	// inspections are generated inspection task runers
 	inspections map[string]*InspectionTaskRunner
+	// inspectionsLock guards inspections.
+	inspectionsLock sync.RWMutex
+	// registry persists completed inspections. Inspections are only kept on memory when it is nil.
+	registry registry.InspectionRegistry
 }
 
 func NewServer() (*InspectionTaskServer, error) {
@@ -72,6 +94,7 @@ func NewServer() (*InspectionTaskServer, error) {
 		RootTaskSet:     ns,
 		inspectionTypes: make([]*InspectionType, 0),
 		inspections:     map[string]*InspectionTaskRunner{},
+		inspectionsLock: sync.RWMutex{},
 	}, nil
 }
 
@@ -101,20 +124,106 @@ func (s *InspectionTaskServer) AddTask(task task.UntypedTask) error {
 
 // CreateInspection generates an inspection and returns inspection ID
 func (s *InspectionTaskServer) CreateInspection(inspectionType string) (string, error) {
+	return s.CreateInspectionWithOwner(inspectionType, "")
+}
+
+// CreateInspectionWithOwner generates an inspection owned by the given principal and returns inspection ID.
+func (s *InspectionTaskServer) CreateInspectionWithOwner(inspectionType string, owner string) (string, error) {
 	inspectionTask := NewInspectionRunner(s)
 	err := inspectionTask.SetInspectionType(inspectionType)
 	if err != nil {
 		return "", err
 	}
+	inspectionTask.owner = owner
+	s.inspectionsLock.Lock()
+	defer s.inspectionsLock.Unlock()
 	s.inspections[inspectionTask.ID] = inspectionTask
 	return inspectionTask.ID, nil
 }
 
 // Inspection returns an instance of an Inspection queried with given inspection ID.
 func (s *InspectionTaskServer) GetInspection(inspectionID string) *InspectionTaskRunner {
+	s.inspectionsLock.RLock()
+	defer s.inspectionsLock.RUnlock()
 	return s.inspections[inspectionID]
 }
 
+// SetRegistry sets the registry used to persist completed inspections.
+func (s *InspectionTaskServer) SetRegistry(inspectionRegistry registry.InspectionRegistry) {
+	s.registry = inspectionRegistry
+}
+
+// RestoreInspections loads completed inspections from the registry as read-only inspections.
+// Records are ignored when their result files no longer exist.
+func (s *InspectionTaskServer) RestoreInspections() error {
+	if s.registry == nil {
+		return fmt.Errorf("inspection registry is not set")
+	}
+	records, err := s.registry.List()
+	if err != nil {
+		return err
+	}
+	s.inspectionsLock.Lock()
+	defer s.inspectionsLock.Unlock()
+	for _, record := range records {
+		if _, err := os.Stat(record.ResultPath); err != nil {
+			slog.Warn(fmt.Sprintf("ignoring the inspection record %s because its result file %s is not available\n%v", record.ID, record.ResultPath, err))
+			continue
+		}
+		if _, exist := s.inspections[record.ID]; exist {
+			continue
+		}
+		s.inspections[record.ID] = newRestoredInspectionRunner(s, record)
+	}
+	return nil
+}
+
+// DeleteInspection removes the finished inspection with its result file and the record in the registry.
+func (s *InspectionTaskServer) DeleteInspection(inspectionID string) error {
+	s.inspectionsLock.Lock()
+	defer s.inspectionsLock.Unlock()
+	inspection, found := s.inspections[inspectionID]
+	if !found {
+		return fmt.Errorf("%w: %s", ErrInspectionNotFound, inspectionID)
+	}
+	if inspection.Started() && !inspection.Finished() {
+		return fmt.Errorf("%w: %s must be cancelled before deleting it", ErrInspectionRunning, inspectionID)
+	}
+	if inspection.Started() {
+		if result, err := inspection.Result(); err == nil {
+			if store, ok := result.ResultStore.(*inspectiondata.FileSystemStore); ok {
+				if err := os.Remove(store.FilePath()); err != nil && !errors.Is(err, os.ErrNotExist) {
+					return err
+				}
+			}
+		}
+	}
+	if s.registry != nil {
+		if err := s.registry.Delete(inspectionID); err != nil && !errors.Is(err, registry.ErrRecordNotFound) {
+			return err
+		}
+	}
+	delete(s.inspections, inspectionID)
+	// Tokens cached for an inspection which never ran are discarded with the inspection.
+	inspection.credentialCache.Clear()
+	return nil
+}
+
+// saveRecord persists the completed inspection to the registry when the registry is set.
+func (s *InspectionTaskServer) saveRecord(ctx context.Context, inspection *InspectionTaskRunner, values map[string]any, store inspectiondata.Store) {
+	if s.registry == nil {
+		return
+	}
+	record, err := inspection.newInspectionRecord(values, store)
+	if err != nil {
+		slog.ErrorContext(ctx, fmt.Sprintf("Failed to generate the inspection record\n%v", err))
+		return
+	}
+	if err := s.registry.Save(record); err != nil {
+		slog.ErrorContext(ctx, fmt.Sprintf("Failed to save the inspection record\n%v", err))
+	}
+}
+
 func (s *InspectionTaskServer) GetAllInspectionTypes() []*InspectionType {
 	return append([]*InspectionType{}, s.inspectionTypes...)
 }
@@ -129,6 +238,8 @@ func (s *InspectionTaskServer) GetInspectionType(inspectionTypeId string) *Inspe
 }
 
 func (s *InspectionTaskServer) GetAllRunners() []*InspectionTaskRunner {
+	s.inspectionsLock.RLock()
+	defer s.inspectionsLock.RUnlock()
 	inspections := []*InspectionTaskRunner{}
 	for _, value := range s.inspections {
 		inspections = append(inspections, value)
@@ -17,6 +17,9 @@ package inspection_task
 import (
 	"context"
 
+	"github.com/GoogleCloudPlatform/khi/pkg/common/khictx"
+	"github.com/GoogleCloudPlatform/khi/pkg/inspection/budget"
+	inspection_task_contextkey "github.com/GoogleCloudPlatform/khi/pkg/inspection/contextkey"
 	"github.com/GoogleCloudPlatform/khi/pkg/inspection/ioconfig"
 	"github.com/GoogleCloudPlatform/khi/pkg/model/history"
 	"github.com/GoogleCloudPlatform/khi/pkg/task"
@@ -27,5 +30,11 @@ var BuilderGeneratorTaskID = taskid.NewDefaultImplementationID[*history.Builder]
 
 var BuilderGeneratorTask = task.NewTask(BuilderGeneratorTaskID, []taskid.UntypedTaskReference{ioconfig.IOConfigTaskID.Ref()}, func(ctx context.Context) (*history.Builder, error) {
 	ioConfig := task.GetTaskResult(ctx, ioconfig.IOConfigTaskID.Ref())
-	return history.NewBuilder(ioConfig), nil
+	builder := history.NewBuilder(ioConfig)
+	inspectionBudget, err := khictx.GetValue(ctx, inspection_task_contextkey.InspectionBudget)
+	if err != nil {
+		inspectionBudget = budget.Default
+	}
+	builder.SetMaxBinaryBytes(inspectionBudget.MaxBinaryBytes)
+	return builder, nil
 })
@@ -18,12 +18,23 @@ import (
 	"context"
 	"time"
 
+	"github.com/GoogleCloudPlatform/khi/pkg/inspection/budget"
+	"github.com/GoogleCloudPlatform/khi/pkg/inspection/credential"
+	"github.com/GoogleCloudPlatform/khi/pkg/log/logstore"
 	common_task "github.com/GoogleCloudPlatform/khi/pkg/task"
 	"github.com/GoogleCloudPlatform/khi/pkg/task/taskid"
 )
 
 type InspectionRequest struct {
 	Values map[string]any
+	// Budget is the resource budget of the inspection. budget.Default is used when it's nil.
+	Budget *budget.Budget
+	// EstimateVolume is true when the dry run should estimate the count of logs returned from queries. Estimation sends probe queries.
+	EstimateVolume bool
+	// Sampling is the sampling settings for each feature task ID. Features not in the map parse every log.
+	Sampling map[string]*logstore.Sampling
+	// Credential is the GCP credential of the user starting the inspection. The credential of the KHI process is used when it's nil.
+	Credential *credential.Credential
 }
 
 var InspectionTimeTaskID = taskid.NewDefaultImplementationID[time.Time](InspectionTaskPrefix + "task/time")
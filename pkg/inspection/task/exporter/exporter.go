@@ -0,0 +1,249 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/model/enum"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/history"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/history/reader"
+)
+
+// Format is the file format of exported tables.
+type Format string
+
+const (
+	FormatJSONL Format = "jsonl"
+	FormatCSV   Format = "csv"
+)
+
+// Table is the kind of normalized table generated from a History.
+type Table string
+
+const (
+	TableLogs      Table = "logs"
+	TableRevisions Table = "revisions"
+	TableEvents    Table = "events"
+	TableResources Table = "resources"
+)
+
+// AllTables is the list of every table in the order written in archives.
+var AllTables = []Table{TableLogs, TableRevisions, TableEvents, TableResources}
+
+// ParseFormat returns the Format from the given string. The empty string is regarded as FormatJSONL.
+func ParseFormat(format string) (Format, error) {
+	switch Format(strings.ToLower(format)) {
+	case "", FormatJSONL:
+		return FormatJSONL, nil
+	case FormatCSV:
+		return FormatCSV, nil
+	default:
+		return "", fmt.Errorf("unsupported export format %q. supported formats are %q and %q", format, FormatJSONL, FormatCSV)
+	}
+}
+
+// ParseTable returns the Table from the given string.
+func ParseTable(table string) (Table, error) {
+	for _, t := range AllTables {
+		if string(t) == strings.ToLower(table) {
+			return t, nil
+		}
+	}
+	return "", fmt.Errorf("unknown table %q", table)
+}
+
+// Exporter writes the content of a .khi file as normalized tables.
+// Binary references in the History are resolved, thus log bodies and revision manifests are inlined in the tables.
+type Exporter struct {
+	khiFile *reader.Reader
+	// timelineResourcePaths maps timeline IDs to the path of the first resource using the timeline.
+	timelineResourcePaths map[string]string
+}
+
+func NewExporter(khiFile *reader.Reader) (*Exporter, error) {
+	timelineResourcePaths := map[string]string{}
+	err := khiFile.WalkResources(func(resource *history.Resource, depth int) error {
+		if resource.Timeline == "" {
+			return nil
+		}
+		if _, found := timelineResourcePaths[resource.Timeline]; !found {
+			timelineResourcePaths[resource.Timeline] = resource.FullResourcePath
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Exporter{
+		khiFile:               khiFile,
+		timelineResourcePaths: timelineResourcePaths,
+	}, nil
+}
+
+// FileName returns the file name used for the table in the given format.
+func FileName(table Table, format Format) string {
+	return fmt.Sprintf("%s.%s", table, format)
+}
+
+// ExportTable writes a table in the given format to the writer.
+func (e *Exporter) ExportTable(table Table, format Format, writer io.Writer) error {
+	rowWriter, err := newRowWriter(format, writer, tableColumns[table])
+	if err != nil {
+		return err
+	}
+	switch table {
+	case TableLogs:
+		err = e.writeLogs(rowWriter)
+	case TableRevisions:
+		err = e.writeRevisions(rowWriter)
+	case TableEvents:
+		err = e.writeEvents(rowWriter)
+	case TableResources:
+		err = e.writeResources(rowWriter)
+	default:
+		return fmt.Errorf("unknown table %q", table)
+	}
+	if err != nil {
+		return err
+	}
+	return rowWriter.Flush()
+}
+
+// ExportArchive writes every table in the given format to the writer as a zip archive.
+func (e *Exporter) ExportArchive(format Format, writer io.Writer) error {
+	zipWriter := zip.NewWriter(writer)
+	for _, table := range AllTables {
+		fileWriter, err := zipWriter.Create(FileName(table, format))
+		if err != nil {
+			return err
+		}
+		err = e.ExportTable(table, format, fileWriter)
+		if err != nil {
+			return fmt.Errorf("failed to export table %s\n%w", table, err)
+		}
+	}
+	return zipWriter.Close()
+}
+
+var tableColumns = map[Table][]string{
+	TableLogs:      {"id", "display_id", "timestamp", "type", "severity", "summary", "body"},
+	TableRevisions: {"timeline_id", "resource_path", "log_id", "change_time", "verb", "state", "requestor", "manifest"},
+	TableEvents:    {"timeline_id", "resource_path", "log_id", "timestamp"},
+	TableResources: {"path", "name", "parent_path", "relationship", "timeline_id"},
+}
+
+func (e *Exporter) writeLogs(w rowWriter) error {
+	for _, l := range e.khiFile.History.Logs {
+		summary, err := e.khiFile.ReadString(l.Summary)
+		if err != nil {
+			return err
+		}
+		body, err := e.khiFile.ReadString(l.Body)
+		if err != nil {
+			return err
+		}
+		err = w.WriteRow([]string{
+			l.ID,
+			l.DisplayId,
+			formatTime(l.Timestamp),
+			enum.LogTypes[l.Type].EnumKeyName,
+			enum.Severities[l.Severity].EnumKeyName,
+			summary,
+			body,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *Exporter) writeRevisions(w rowWriter) error {
+	for _, timeline := range e.khiFile.History.Timelines {
+		for _, revision := range timeline.Revisions {
+			requestor, err := e.khiFile.ReadString(revision.Requestor)
+			if err != nil {
+				return err
+			}
+			manifest, err := e.khiFile.ReadString(revision.Body)
+			if err != nil {
+				return err
+			}
+			err = w.WriteRow([]string{
+				timeline.ID,
+				e.timelineResourcePaths[timeline.ID],
+				revision.Log,
+				formatTime(revision.ChangeTime),
+				enum.RevisionVerbs[revision.Verb].EnumKeyName,
+				enum.RevisionStates[revision.State].EnumKeyName,
+				requestor,
+				manifest,
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (e *Exporter) writeEvents(w rowWriter) error {
+	for _, timeline := range e.khiFile.History.Timelines {
+		for _, event := range timeline.Events {
+			timestamp := ""
+			if l := e.khiFile.Log(event.Log); l != nil {
+				timestamp = formatTime(l.Timestamp)
+			}
+			err := w.WriteRow([]string{
+				timeline.ID,
+				e.timelineResourcePaths[timeline.ID],
+				event.Log,
+				timestamp,
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (e *Exporter) writeResources(w rowWriter) error {
+	return e.khiFile.WalkResources(func(resource *history.Resource, depth int) error {
+		parentPath := ""
+		if index := strings.LastIndex(resource.FullResourcePath, "#"); index != -1 {
+			parentPath = resource.FullResourcePath[:index]
+		}
+		return w.WriteRow([]string{
+			resource.FullResourcePath,
+			resource.ResourceName,
+			parentPath,
+			enum.ParentRelationships[resource.Relationship].EnumKeyName,
+			resource.Timeline,
+		})
+	})
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339Nano)
+}
@@ -0,0 +1,198 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"archive/zip"
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/model/binarychunk"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/enum"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/history"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/history/reader"
+	"github.com/GoogleCloudPlatform/khi/pkg/testutil/khifile"
+	"github.com/google/go-cmp/cmp"
+
+	_ "github.com/GoogleCloudPlatform/khi/internal/testflags"
+)
+
+func newTestExporter(t *testing.T) *Exporter {
+	t.Helper()
+	chunk := binarychunk.NewBuilder(binarychunk.NewFileSystemGzipCompressor("/tmp"), "/tmp")
+	mustWrite := func(s string) *binarychunk.BinaryReference {
+		ref, err := chunk.Write([]byte(s))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ref
+	}
+	h := history.NewHistory()
+	h.Logs = []*history.SerializableLog{
+		{
+			ID:        "log-1",
+			DisplayId: "insert-1",
+			Timestamp: time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC),
+			Body:      mustWrite("foo: bar"),
+			Summary:   mustWrite("create pod"),
+			Type:      enum.LogTypeAudit,
+			Severity:  enum.SeverityInfo,
+		},
+	}
+	h.Timelines = []*history.ResourceTimeline{
+		{
+			ID: "tl",
+			Revisions: []*history.ResourceRevision{
+				{
+					Log:        "log-1",
+					Verb:       enum.RevisionVerbCreate,
+					State:      enum.RevisionStateExisting,
+					Requestor:  mustWrite("user@example.com"),
+					Body:       mustWrite("kind: Pod"),
+					ChangeTime: time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC),
+				},
+			},
+			Events: []*history.ResourceEvent{{Log: "log-1"}},
+		},
+	}
+	h.Resources = []*history.Resource{
+		{
+			ResourceName:     "core/v1",
+			FullResourcePath: "core/v1",
+			Children: []*history.Resource{
+				{ResourceName: "pod", FullResourcePath: "core/v1#pod", Timeline: "tl", Relationship: enum.RelationshipChild},
+			},
+		},
+	}
+	data := khifile.MustGenerateKHIFile(h, chunk)
+	khiFile, err := reader.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	exporter, err := NewExporter(khiFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return exporter
+}
+
+func TestExportTable(t *testing.T) {
+	testCases := []struct {
+		table  Table
+		format Format
+		want   string
+	}{
+		{
+			table:  TableLogs,
+			format: FormatJSONL,
+			want:   `{"id":"log-1","display_id":"insert-1","timestamp":"2025-01-01T00:00:00Z","type":"LogTypeAudit","severity":"SeverityInfo","summary":"create pod","body":"foo: bar"}` + "\n",
+		},
+		{
+			table:  TableRevisions,
+			format: FormatJSONL,
+			want:   `{"timeline_id":"tl","resource_path":"core/v1#pod","log_id":"log-1","change_time":"2025-01-01T00:00:00Z","verb":"RevisionVerbCreate","state":"RevisionStateExisting","requestor":"user@example.com","manifest":"kind: Pod"}` + "\n",
+		},
+		{
+			table:  TableEvents,
+			format: FormatCSV,
+			want:   "timeline_id,resource_path,log_id,timestamp\ntl,core/v1#pod,log-1,2025-01-01T00:00:00Z\n",
+		},
+		{
+			table:  TableResources,
+			format: FormatCSV,
+			want:   "path,name,parent_path,relationship,timeline_id\ncore/v1,core/v1,,RelationshipChild,\ncore/v1#pod,pod,core/v1,RelationshipChild,tl\n",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(string(tc.table)+"-"+string(tc.format), func(t *testing.T) {
+			exporter := newTestExporter(t)
+			var buf bytes.Buffer
+			err := exporter.ExportTable(tc.table, tc.format, &buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.want, buf.String()); diff != "" {
+				t.Errorf("ExportTable() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestExportArchive(t *testing.T) {
+	exporter := newTestExporter(t)
+	var buf bytes.Buffer
+	err := exporter.ExportArchive(FormatCSV, &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zipReader, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fileNames := []string{}
+	for _, file := range zipReader.File {
+		fileNames = append(fileNames, file.Name)
+	}
+	if diff := cmp.Diff([]string{"logs.csv", "revisions.csv", "events.csv", "resources.csv"}, fileNames); diff != "" {
+		t.Errorf("archive entries mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	testCases := []struct {
+		input   string
+		want    Format
+		wantErr bool
+	}{
+		{input: "", want: FormatJSONL},
+		{input: "jsonl", want: FormatJSONL},
+		{input: "CSV", want: FormatCSV},
+		{input: "parquet", wantErr: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			got, err := ParseFormat(tc.input)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("ParseFormat(%q) error = %v, wantErr %v", tc.input, err, tc.wantErr)
+			}
+			if got != tc.want {
+				t.Errorf("ParseFormat(%q) = %q, want %q", tc.input, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestExportTableEscapesCSV(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := newCSVRowWriter(&buf, []string{"a", "b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.WriteRow([]string{"multi\nline", `with "quote", comma`}); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	want := "a,b\n\"multi\nline\",\"with \"\"quote\"\", comma\"\n"
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Errorf("csv output mismatch (-want +got):\n%s", diff)
+	}
+	if err := w.WriteRow([]string{"too few"}); err == nil || !strings.Contains(err.Error(), "doesn't match") {
+		t.Errorf("WriteRow() with wrong column count returned %v", err)
+	}
+}
@@ -0,0 +1,122 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// rowWriter writes rows of a table in a specific format.
+type rowWriter interface {
+	// WriteRow writes a row. The count of values must match with the columns given at the construction.
+	WriteRow(values []string) error
+	// Flush writes any buffered data to the underlying writer.
+	Flush() error
+}
+
+func newRowWriter(format Format, writer io.Writer, columns []string) (rowWriter, error) {
+	switch format {
+	case FormatJSONL:
+		return newJSONLRowWriter(writer, columns), nil
+	case FormatCSV:
+		return newCSVRowWriter(writer, columns)
+	default:
+		return nil, fmt.Errorf("unsupported export format %q", format)
+	}
+}
+
+// jsonlRowWriter writes each row as a JSON object in a line.
+type jsonlRowWriter struct {
+	columns []string
+	writer  *bufio.Writer
+}
+
+var _ rowWriter = (*jsonlRowWriter)(nil)
+
+func newJSONLRowWriter(writer io.Writer, columns []string) *jsonlRowWriter {
+	return &jsonlRowWriter{
+		columns: columns,
+		writer:  bufio.NewWriter(writer),
+	}
+}
+
+// WriteRow implements rowWriter.
+func (w *jsonlRowWriter) WriteRow(values []string) error {
+	if len(values) != len(w.columns) {
+		return fmt.Errorf("the count of values %d doesn't match with the count of columns %d", len(values), len(w.columns))
+	}
+	// Fields are written manually to keep the column order in the output.
+	w.writer.WriteByte('{')
+	for i, column := range w.columns {
+		if i > 0 {
+			w.writer.WriteByte(',')
+		}
+		key, err := json.Marshal(column)
+		if err != nil {
+			return err
+		}
+		value, err := json.Marshal(values[i])
+		if err != nil {
+			return err
+		}
+		w.writer.Write(key)
+		w.writer.WriteByte(':')
+		w.writer.Write(value)
+	}
+	w.writer.WriteString("}\n")
+	return nil
+}
+
+// Flush implements rowWriter.
+func (w *jsonlRowWriter) Flush() error {
+	return w.writer.Flush()
+}
+
+// csvRowWriter writes rows as CSV records with the header line.
+type csvRowWriter struct {
+	columnCount int
+	writer      *csv.Writer
+}
+
+var _ rowWriter = (*csvRowWriter)(nil)
+
+func newCSVRowWriter(writer io.Writer, columns []string) (*csvRowWriter, error) {
+	csvWriter := csv.NewWriter(writer)
+	if err := csvWriter.Write(columns); err != nil {
+		return nil, err
+	}
+	return &csvRowWriter{
+		columnCount: len(columns),
+		writer:      csvWriter,
+	}, nil
+}
+
+// WriteRow implements rowWriter.
+func (w *csvRowWriter) WriteRow(values []string) error {
+	if len(values) != w.columnCount {
+		return fmt.Errorf("the count of values %d doesn't match with the count of columns %d", len(values), w.columnCount)
+	}
+	return w.writer.Write(values)
+}
+
+// Flush implements rowWriter.
+func (w *csvRowWriter) Flush() error {
+	w.writer.Flush()
+	return w.writer.Error()
+}
@@ -48,6 +48,8 @@ type Log struct {
 	fields  *typedmap.TypedMap
 	ID      string
 	LogType enum.LogType
+	// ClusterName is the name of the cluster the log was queried for in a multi-cluster inspection. It's empty in single cluster inspections.
+	ClusterName string
 }
 
 // NewLog returns a log instance from NodeReader instance.
@@ -0,0 +1,430 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logstore
+
+import (
+	"bufio"
+	"bytes"
+	"cmp"
+	"fmt"
+	"hash/fnv"
+	"iter"
+	"os"
+	"runtime"
+	"slices"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/common/structurev2"
+	"github.com/GoogleCloudPlatform/khi/pkg/log"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/enum"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/history/grouper"
+)
+
+// Store keeps logs in a temporary segment file instead of memory.
+// Logs are appended as they are received and read again from the file in the timestamp order when they are used.
+// Logs read from the store are new instances with the same ID, LogType and fields read with the FieldSetReaders given to the store.
+type Store struct {
+	folder     string
+	logGrouper grouper.LogGrouper
+	readers    []log.FieldSetReader
+
+	lock       sync.Mutex
+	file       *os.File
+	writer     *bufio.Writer
+	entries    []storeEntry
+	lastOffset int64
+	sorted     bool
+	closed     bool
+	// appendedCount is the count of logs appended to the store including logs removed with Retain.
+	appendedCount int64
+	// groupKeys is the list of group keys referenced from entries by the index.
+	groupKeys []string
+	// groupIndices is the index of each key in groupKeys.
+	groupIndices map[string]int
+}
+
+type storeEntry struct {
+	idHash           uint64
+	timestampInNanos int64
+	offset           int64
+	size             int
+	groupIndex       int
+	// order sorts entries with the same timestamp.
+	order int64
+}
+
+// NewStore returns an empty Store writing its segment file in the given folder.
+// The group key of each log is computed with the given grouper when it's appended. Logs of different clusters are always in different groups because parsers keep states per group.
+// The segment file is created on the first Append and removed on Close.
+func NewStore(folder string, logGrouper grouper.LogGrouper, readers ...log.FieldSetReader) *Store {
+	return &Store{
+		folder:       folder,
+		logGrouper:   logGrouper,
+		readers:      readers,
+		entries:      []storeEntry{},
+		sorted:       true,
+		groupIndices: map[string]int{},
+	}
+}
+
+// NewStoreFromLogs returns a Store containing the given logs.
+func NewStoreFromLogs(folder string, logs []*log.Log, logGrouper grouper.LogGrouper, readers ...log.FieldSetReader) (*Store, error) {
+	store := NewStore(folder, logGrouper, readers...)
+	for _, l := range logs {
+		if err := store.Append(l); err != nil {
+			store.Close()
+			return nil, err
+		}
+	}
+	return store, nil
+}
+
+// Append writes the log to the segment file. The log must have CommonFieldSet to be sorted by its timestamp.
+// Logs with the same timestamp are read in the order they are appended.
+func (s *Store) Append(l *log.Log) error {
+	return s.append(l, nil)
+}
+
+// AppendInOrder writes the log to the segment file like Append, but logs with the same timestamp are read in the given order instead of the appended order.
+// This is for appending logs from multiple goroutines while keeping the order of logs in the original source.
+func (s *Store) AppendInOrder(l *log.Log, order int64) error {
+	return s.append(l, &order)
+}
+
+// append writes the log to the segment file. The count of logs appended before is used as the order when the order is nil.
+func (s *Store) append(l *log.Log, order *int64) error {
+	commonFieldSet, err := log.GetFieldSet(l, &log.CommonFieldSet{})
+	if err != nil {
+		return fmt.Errorf("failed to read CommonFieldSet of the log to store\n%w", err)
+	}
+	body, err := l.Serialize("", &structurev2.JSONNodeSerializer{})
+	if err != nil {
+		return err
+	}
+	record := fmt.Appendf(nil, "%s\n%d\n%s\n", l.ID, l.LogType, l.ClusterName)
+	record = append(record, body...)
+	groupKey := s.logGrouper.GroupKey(l)
+	if l.ClusterName != "" {
+		groupKey = l.ClusterName + "/" + groupKey
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.closed {
+		return fmt.Errorf("the log store is already closed")
+	}
+	if s.file == nil {
+		if err := s.createSegmentFile(); err != nil {
+			return err
+		}
+	}
+	if _, err := s.writer.Write(record); err != nil {
+		return err
+	}
+	entry := storeEntry{
+		idHash:           IDHash(l.ID),
+		timestampInNanos: commonFieldSet.Timestamp.UnixNano(),
+		offset:           s.lastOffset,
+		size:             len(record),
+		groupIndex:       s.groupIndex(groupKey),
+		order:            s.appendedCount,
+	}
+	if order != nil {
+		entry.order = *order
+	}
+	if len(s.entries) > 0 && compareEntries(s.entries[len(s.entries)-1], entry) > 0 {
+		s.sorted = false
+	}
+	s.entries = append(s.entries, entry)
+	s.appendedCount++
+	s.lastOffset += int64(len(record))
+	return nil
+}
+
+// Len returns the count of logs in the store.
+func (s *Store) Len() int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return len(s.entries)
+}
+
+// Retain removes logs from the store unless keep returns true for them.
+// keep receives the IDHash of each log so that logs can be sampled deterministically without reading them again.
+// The removed logs remain in the segment file until the store is closed.
+func (s *Store) Retain(keep func(idHash uint64) bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	retained := s.entries[:0]
+	for _, entry := range s.entries {
+		if keep(entry.idHash) {
+			retained = append(retained, entry)
+		}
+	}
+	s.entries = retained
+}
+
+// TimeRange returns the timestamps of the oldest and the newest logs in the store without reading them. It returns false when the store is empty.
+func (s *Store) TimeRange() (time.Time, time.Time, bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if len(s.entries) == 0 {
+		return time.Time{}, time.Time{}, false
+	}
+	oldest, newest := s.entries[0].timestampInNanos, s.entries[0].timestampInNanos
+	for _, entry := range s.entries {
+		oldest = min(oldest, entry.timestampInNanos)
+		newest = max(newest, entry.timestampInNanos)
+	}
+	return time.Unix(0, oldest), time.Unix(0, newest), true
+}
+
+// IDHash returns the hash of the log ID used in Retain.
+func IDHash(id string) uint64 {
+	hash := fnv.New64a()
+	hash.Write([]byte(id))
+	return hash.Sum64()
+}
+
+// All returns the iterator of logs in the timestamp order. Logs with the same timestamp are returned in the appended order.
+func (s *Store) All() iter.Seq2[*log.Log, error] {
+	return func(yield func(*log.Log, error) bool) {
+		entries, err := s.prepareRead()
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		for _, entry := range entries {
+			l, err := s.read(entry)
+			if !yield(l, err) || err != nil {
+				return
+			}
+		}
+	}
+}
+
+// ReadAll returns every log in the store in the timestamp order. This holds all logs on memory.
+func (s *Store) ReadAll() ([]*log.Log, error) {
+	result := make([]*log.Log, 0, s.Len())
+	for l, err := range s.All() {
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, l)
+	}
+	return result, nil
+}
+
+// Group returns the index of logs for each group key computed when logs were appended.
+// Only the positions of logs are kept in memory and logs in a group are read when the group is used.
+func (s *Store) Group() (*Groups, error) {
+	entries, err := s.prepareRead()
+	if err != nil {
+		return nil, err
+	}
+	groups := &Groups{
+		store:   s,
+		entries: map[string][]storeEntry{},
+	}
+	for _, entry := range entries {
+		key := s.groupKeys[entry.groupIndex]
+		if _, found := groups.entries[key]; !found {
+			groups.keys = append(groups.keys, key)
+		}
+		groups.entries[key] = append(groups.entries[key], entry)
+	}
+	sort.Strings(groups.keys)
+	return groups, nil
+}
+
+// Close removes the segment file. The store can't be used after calling this.
+// Stores returned from tasks are closed when the inspection run ends.
+func (s *Store) Close() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	if s.file == nil {
+		return nil
+	}
+	runtime.SetFinalizer(s, nil)
+	return removeSegmentFile(s.file)
+}
+
+// groupIndex returns the index of the group key in groupKeys. The caller must hold the lock.
+func (s *Store) groupIndex(groupKey string) int {
+	index, found := s.groupIndices[groupKey]
+	if !found {
+		index = len(s.groupKeys)
+		s.groupKeys = append(s.groupKeys, groupKey)
+		s.groupIndices[groupKey] = index
+	}
+	return index
+}
+
+// createSegmentFile creates the segment file. The caller must hold the lock.
+func (s *Store) createSegmentFile() error {
+	file, err := os.CreateTemp(s.folder, "khi-logstore-")
+	if err != nil {
+		return err
+	}
+	s.file = file
+	s.writer = bufio.NewWriter(file)
+	// The finalizer removes the file of a store not closed explicitly, e.g. a store created out of an inspection run.
+	runtime.SetFinalizer(s, func(s *Store) {
+		removeSegmentFile(s.file)
+	})
+	return nil
+}
+
+// prepareRead flushes the written logs and returns the entries sorted by timestamp.
+func (s *Store) prepareRead() ([]storeEntry, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.closed {
+		return nil, fmt.Errorf("the log store is already closed")
+	}
+	if s.writer != nil {
+		if err := s.writer.Flush(); err != nil {
+			return nil, err
+		}
+	}
+	if !s.sorted {
+		slices.SortStableFunc(s.entries, compareEntries)
+		s.sorted = true
+	}
+	return s.entries, nil
+}
+
+// compareEntries compares entries by the timestamp and then by the order.
+func compareEntries(a, b storeEntry) int {
+	if c := cmp.Compare(a.timestampInNanos, b.timestampInNanos); c != 0 {
+		return c
+	}
+	return cmp.Compare(a.order, b.order)
+}
+
+// read restores the log at the entry.
+func (s *Store) read(entry storeEntry) (*log.Log, error) {
+	record := make([]byte, entry.size)
+	if _, err := s.file.ReadAt(record, entry.offset); err != nil {
+		return nil, err
+	}
+	id, rest, found := bytes.Cut(record, []byte("\n"))
+	if !found {
+		return nil, fmt.Errorf("broken log record at %d", entry.offset)
+	}
+	logType, rest, found := bytes.Cut(rest, []byte("\n"))
+	if !found {
+		return nil, fmt.Errorf("broken log record at %d", entry.offset)
+	}
+	clusterName, body, found := bytes.Cut(rest, []byte("\n"))
+	if !found {
+		return nil, fmt.Errorf("broken log record at %d", entry.offset)
+	}
+	logTypeNumber, err := strconv.Atoi(string(logType))
+	if err != nil {
+		return nil, err
+	}
+	node, err := structurev2.FromYAML(string(body))
+	if err != nil {
+		return nil, err
+	}
+	l := log.NewLog(structurev2.NewNodeReader(node))
+	l.ID = string(id)
+	l.LogType = enum.LogType(logTypeNumber)
+	l.ClusterName = string(clusterName)
+	for _, reader := range s.readers {
+		if err := l.SetFieldSetReader(reader); err != nil {
+			return nil, err
+		}
+	}
+	return l, nil
+}
+
+func removeSegmentFile(file *os.File) error {
+	file.Close()
+	return os.Remove(file.Name())
+}
+
+// Groups is the index of logs in a Store for each group key.
+type Groups struct {
+	store   *Store
+	keys    []string
+	entries map[string][]storeEntry
+}
+
+// Keys returns the sorted list of group keys.
+func (g *Groups) Keys() []string {
+	return g.keys
+}
+
+// Count returns the count of logs in the group.
+func (g *Groups) Count(key string) int {
+	return len(g.entries[key])
+}
+
+// Sampling is the setting to parse only a part of logs in high volume features.
+type Sampling struct {
+	// EveryNth keeps every Nth log in each group. 0 or 1 keeps every log.
+	EveryNth int `json:"everyNth"`
+	// MaxLogsPerGroup is the maximum count of logs in each group. Logs are picked at even intervals when a group has more logs. 0 means unlimited.
+	MaxLogsPerGroup int `json:"maxLogsPerGroup"`
+}
+
+// Sample drops logs from each group with the given sampling setting and returns the count of remaining logs.
+// Logs are dropped in each group instead of the whole store to keep logs of every group.
+func (g *Groups) Sample(sampling *Sampling) int {
+	total := 0
+	for _, key := range g.keys {
+		entries := g.entries[key]
+		if sampling.EveryNth > 1 {
+			entries = pickEvery(entries, float64(sampling.EveryNth))
+		}
+		if sampling.MaxLogsPerGroup > 0 && len(entries) > sampling.MaxLogsPerGroup {
+			entries = pickEvery(entries, float64(len(entries))/float64(sampling.MaxLogsPerGroup))
+		}
+		g.entries[key] = entries
+		total += len(entries)
+	}
+	return total
+}
+
+// pickEvery returns the entries at every given interval from the first entry.
+func pickEvery(entries []storeEntry, interval float64) []storeEntry {
+	result := []storeEntry{}
+	for i := 0.0; int(i) < len(entries); i += interval {
+		result = append(result, entries[int(i)])
+	}
+	return result
+}
+
+// Read returns the logs in the group in the timestamp order.
+func (g *Groups) Read(key string) ([]*log.Log, error) {
+	entries := g.entries[key]
+	result := make([]*log.Log, 0, len(entries))
+	for _, entry := range entries {
+		l, err := g.store.read(entry)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, l)
+	}
+	return result, nil
+}
@@ -0,0 +1,324 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logstore
+
+import (
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/common/structurev2"
+	"github.com/GoogleCloudPlatform/khi/pkg/log"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/enum"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/history/grouper"
+	"github.com/google/go-cmp/cmp"
+
+	_ "github.com/GoogleCloudPlatform/khi/internal/testflags"
+)
+
+var testGrouper = grouper.NewSingleStringFieldKeyLogGrouper("group")
+
+type testCommonFieldSetReader struct{}
+
+// FieldSetKind implements log.FieldSetReader.
+func (t *testCommonFieldSetReader) FieldSetKind() string {
+	return (&log.CommonFieldSet{}).Kind()
+}
+
+// Read implements log.FieldSetReader.
+func (t *testCommonFieldSetReader) Read(reader *structurev2.NodeReader) (log.FieldSet, error) {
+	timestamp, err := reader.ReadTimestamp("timestamp")
+	if err != nil {
+		return nil, err
+	}
+	return &log.CommonFieldSet{
+		Timestamp: timestamp,
+		DisplayID: reader.ReadStringOrDefault("insertId", ""),
+	}, nil
+}
+
+func newTestLog(t *testing.T, insertID string, group string, timestamp time.Time) *log.Log {
+	t.Helper()
+	l, err := log.NewLogFromYAMLString(fmt.Sprintf("insertId: %s\ngroup: %s\ntimestamp: %s\n", insertID, group, timestamp.Format(time.RFC3339Nano)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.LogType = enum.LogTypeContainer
+	if err := l.SetFieldSetReader(&testCommonFieldSetReader{}); err != nil {
+		t.Fatal(err)
+	}
+	return l
+}
+
+func insertIDs(t *testing.T, logs []*log.Log) []string {
+	t.Helper()
+	result := []string{}
+	for _, l := range logs {
+		result = append(result, log.MustGetFieldSet(l, &log.CommonFieldSet{}).DisplayID)
+	}
+	return result
+}
+
+func TestStore(t *testing.T) {
+	baseTime := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+	original := []*log.Log{
+		newTestLog(t, "c", "pod-a", baseTime.Add(3*time.Second)),
+		newTestLog(t, "a", "pod-b", baseTime.Add(1*time.Second)),
+		newTestLog(t, "b", "pod-a", baseTime.Add(2*time.Second)),
+		newTestLog(t, "b2", "pod-b", baseTime.Add(2*time.Second)),
+	}
+	original[1].ClusterName = "cluster-a"
+	store, err := NewStoreFromLogs(t.TempDir(), original, testGrouper, &testCommonFieldSetReader{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	if store.Len() != 4 {
+		t.Errorf("Len() = %d, want 4", store.Len())
+	}
+
+	logs, err := store.ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"a", "b", "b2", "c"}, insertIDs(t, logs)); diff != "" {
+		t.Errorf("ReadAll() mismatch (-want +got):\n%s", diff)
+	}
+	if logs[0].ID != original[1].ID || logs[0].LogType != enum.LogTypeContainer || logs[0].ClusterName != "cluster-a" {
+		t.Errorf("restored log has ID %q, LogType %d and ClusterName %q, want %q, %d and %q", logs[0].ID, logs[0].LogType, logs[0].ClusterName, original[1].ID, enum.LogTypeContainer, "cluster-a")
+	}
+
+	groups, err := store.Group()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"cluster-a/pod-b", "pod-a", "pod-b"}, groups.Keys()); diff != "" {
+		t.Errorf("Keys() mismatch (-want +got):\n%s", diff)
+	}
+	// The log of cluster-a is grouped separately from the logs of the same pod name in the other cluster.
+	wantGroups := map[string][]string{
+		"cluster-a/pod-b": {"a"},
+		"pod-a":           {"b", "c"},
+		"pod-b":           {"b2"},
+	}
+	for key, want := range wantGroups {
+		groupLogs, err := groups.Read(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(want, insertIDs(t, groupLogs)); diff != "" {
+			t.Errorf("Read(%q) mismatch (-want +got):\n%s", key, diff)
+		}
+		if groups.Count(key) != len(want) {
+			t.Errorf("Count(%q) = %d, want %d", key, groups.Count(key), len(want))
+		}
+	}
+}
+
+func TestStoreClose(t *testing.T) {
+	folder := t.TempDir()
+	empty := NewStore(folder, testGrouper)
+	if empty.Len() != 0 {
+		t.Errorf("Len() of an empty store = %d, want 0", empty.Len())
+	}
+	if err := empty.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	store := NewStore(folder, testGrouper, &testCommonFieldSetReader{})
+	if err := store.Append(newTestLog(t, "a", "pod-a", time.Now())); err != nil {
+		t.Fatal(err)
+	}
+	files, err := os.ReadDir(folder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 {
+		t.Fatalf("got %d files in the folder, want 1 segment file", len(files))
+	}
+	if err := store.Close(); err != nil {
+		t.Fatal(err)
+	}
+	files, err = os.ReadDir(folder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 0 {
+		t.Errorf("got %d files in the folder after Close, want 0", len(files))
+	}
+	if _, err := store.ReadAll(); err == nil {
+		t.Errorf("ReadAll() after Close returned no error")
+	}
+}
+
+func TestStoreAppendWithoutCommonFieldSet(t *testing.T) {
+	store := NewStore(t.TempDir(), testGrouper)
+	l, err := log.NewLogFromYAMLString("foo: bar")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Append(l); err == nil {
+		t.Errorf("Append() without CommonFieldSet returned no error")
+	}
+}
+
+func TestStoreAppendInOrder(t *testing.T) {
+	baseTime := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+	store := NewStore(t.TempDir(), testGrouper, &testCommonFieldSetReader{})
+	defer store.Close()
+	for _, tc := range []struct {
+		log   *log.Log
+		order int64
+	}{
+		{log: newTestLog(t, "c", "pod-a", baseTime.Add(1*time.Second)), order: 3},
+		{log: newTestLog(t, "d", "pod-a", baseTime.Add(2*time.Second)), order: 0},
+		{log: newTestLog(t, "a", "pod-a", baseTime.Add(1*time.Second)), order: 1},
+		{log: newTestLog(t, "b", "pod-a", baseTime.Add(1*time.Second)), order: 2},
+	} {
+		if err := store.AppendInOrder(tc.log, tc.order); err != nil {
+			t.Fatal(err)
+		}
+	}
+	logs, err := store.ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"a", "b", "c", "d"}, insertIDs(t, logs)); diff != "" {
+		t.Errorf("ReadAll() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestStoreTimeRange(t *testing.T) {
+	baseTime := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+	store := NewStore(t.TempDir(), testGrouper, &testCommonFieldSetReader{})
+	defer store.Close()
+	if _, _, found := store.TimeRange(); found {
+		t.Errorf("TimeRange() of an empty store returned found")
+	}
+	for _, l := range []*log.Log{
+		newTestLog(t, "b", "pod-a", baseTime.Add(2*time.Second)),
+		newTestLog(t, "c", "pod-a", baseTime.Add(3*time.Second)),
+		newTestLog(t, "a", "pod-a", baseTime.Add(1*time.Second)),
+	} {
+		if err := store.Append(l); err != nil {
+			t.Fatal(err)
+		}
+	}
+	oldest, newest, found := store.TimeRange()
+	if !found {
+		t.Fatalf("TimeRange() returned not found")
+	}
+	if !oldest.Equal(baseTime.Add(1*time.Second)) || !newest.Equal(baseTime.Add(3*time.Second)) {
+		t.Errorf("TimeRange() returned (%v, %v), want (%v, %v)", oldest, newest, baseTime.Add(1*time.Second), baseTime.Add(3*time.Second))
+	}
+}
+
+func TestStoreRetain(t *testing.T) {
+	baseTime := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+	original := []*log.Log{
+		newTestLog(t, "a", "pod-a", baseTime.Add(1*time.Second)),
+		newTestLog(t, "b", "pod-a", baseTime.Add(2*time.Second)),
+		newTestLog(t, "c", "pod-a", baseTime.Add(3*time.Second)),
+	}
+	store, err := NewStoreFromLogs(t.TempDir(), original, testGrouper, &testCommonFieldSetReader{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	removedHash := IDHash(original[1].ID)
+	store.Retain(func(idHash uint64) bool {
+		return idHash != removedHash
+	})
+	if store.Len() != 2 {
+		t.Errorf("Len() = %d, want 2", store.Len())
+	}
+	logs, err := store.ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"a", "c"}, insertIDs(t, logs)); diff != "" {
+		t.Errorf("ReadAll() after Retain mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestGroupsSample(t *testing.T) {
+	baseTime := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+	testCases := []struct {
+		name     string
+		sampling *Sampling
+		want     map[string][]string
+	}{
+		{
+			name:     "without sampling",
+			sampling: &Sampling{},
+			want: map[string][]string{
+				"pod-a": {"a0", "a1", "a2", "a3", "a4", "a5", "a6", "a7", "a8", "a9"},
+				"pod-b": {"b0", "b1"},
+			},
+		},
+		{
+			name:     "every 3rd log",
+			sampling: &Sampling{EveryNth: 3},
+			want: map[string][]string{
+				"pod-a": {"a0", "a3", "a6", "a9"},
+				"pod-b": {"b0"},
+			},
+		},
+		{
+			name:     "max logs per group",
+			sampling: &Sampling{MaxLogsPerGroup: 4},
+			want: map[string][]string{
+				"pod-a": {"a0", "a2", "a5", "a7"},
+				"pod-b": {"b0", "b1"},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			store := NewStore(t.TempDir(), testGrouper, &testCommonFieldSetReader{})
+			defer store.Close()
+			for i := 0; i < 10; i++ {
+				if err := store.Append(newTestLog(t, fmt.Sprintf("a%d", i), "pod-a", baseTime.Add(time.Duration(i)*time.Second))); err != nil {
+					t.Fatal(err)
+				}
+			}
+			for i := 0; i < 2; i++ {
+				if err := store.Append(newTestLog(t, fmt.Sprintf("b%d", i), "pod-b", baseTime.Add(time.Duration(i)*time.Second))); err != nil {
+					t.Fatal(err)
+				}
+			}
+			groups, err := store.Group()
+			if err != nil {
+				t.Fatal(err)
+			}
+			gotCount := groups.Sample(tc.sampling)
+			wantCount := 0
+			for key, want := range tc.want {
+				wantCount += len(want)
+				groupLogs, err := groups.Read(key)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if diff := cmp.Diff(want, insertIDs(t, groupLogs)); diff != "" {
+					t.Errorf("Read(%q) mismatch (-want +got):\n%s", key, diff)
+				}
+			}
+			if gotCount != wantCount {
+				t.Errorf("Sample() = %d, want %d", gotCount, wantCount)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package metrics defines the Prometheus metrics of the KHI server exposed from the /metrics endpoint.
+// Labels are limited to values from a bounded set like inspection types, task IDs, API hosts and status codes.
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const namespace = "khi"
+
+// Registry is the registry of the metrics exposed from the /metrics endpoint.
+var Registry = prometheus.NewRegistry()
+
+var factory = promauto.With(Registry)
+
+// InspectionsStarted is the count of started inspections by the inspection type.
+var InspectionsStarted = factory.NewCounterVec(prometheus.CounterOpts{
+	Namespace: namespace,
+	Name:      "inspections_started_total",
+	Help:      "Count of started inspections.",
+}, []string{"inspection_type"})
+
+// InspectionsFinished is the count of finished inspections by the inspection type and the status `done`, `error` or `cancel`.
+var InspectionsFinished = factory.NewCounterVec(prometheus.CounterOpts{
+	Namespace: namespace,
+	Name:      "inspections_finished_total",
+	Help:      "Count of finished inspections by the status.",
+}, []string{"inspection_type", "status"})
+
+// InspectionsRunning is the count of inspections running now by the inspection type.
+var InspectionsRunning = factory.NewGaugeVec(prometheus.GaugeOpts{
+	Namespace: namespace,
+	Name:      "inspections_running",
+	Help:      "Count of inspections running now.",
+}, []string{"inspection_type"})
+
+// InspectionDuration is the duration of finished inspections by the inspection type and the status.
+var InspectionDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+	Namespace: namespace,
+	Name:      "inspection_duration_seconds",
+	Help:      "Duration of finished inspections.",
+	Buckets:   prometheus.ExponentialBuckets(1, 2, 14),
+}, []string{"inspection_type", "status"})
+
+// InspectionResultBytes is the size of .khi files generated by completed inspections by the inspection type.
+var InspectionResultBytes = factory.NewHistogramVec(prometheus.HistogramOpts{
+	Namespace: namespace,
+	Name:      "inspection_result_bytes",
+	Help:      "Size of inspection results generated by completed inspections.",
+	Buckets:   prometheus.ExponentialBuckets(1<<20, 2, 12),
+}, []string{"inspection_type"})
+
+// TaskDuration is the duration of tasks run in inspections by the inspection type and the task ID.
+var TaskDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+	Namespace: namespace,
+	Name:      "task_duration_seconds",
+	Help:      "Duration of tasks run in inspections.",
+	Buckets:   prometheus.ExponentialBuckets(0.01, 4, 10),
+}, []string{"inspection_type", "task_id"})
+
+// APIRequests is the count of responses of requests sent to external APIs like Cloud Logging by the host and the status code.
+// The code is `error` when the request failed without a response.
+var APIRequests = factory.NewCounterVec(prometheus.CounterOpts{
+	Namespace: namespace,
+	Name:      "api_requests_total",
+	Help:      "Count of requests sent to external APIs like Cloud Logging.",
+}, []string{"host", "code"})
+
+// APIRequestDuration is the latency of requests sent to external APIs by the host.
+var APIRequestDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+	Namespace: namespace,
+	Name:      "api_request_duration_seconds",
+	Help:      "Latency of requests sent to external APIs like Cloud Logging.",
+	Buckets:   prometheus.DefBuckets,
+}, []string{"host"})
+
+// APIRequestRetries is the count of retried requests to external APIs by the host and the reason `backoff` or `refresh-token`.
+var APIRequestRetries = factory.NewCounterVec(prometheus.CounterOpts{
+	Namespace: namespace,
+	Name:      "api_request_retries_total",
+	Help:      "Count of retried requests to external APIs like Cloud Logging.",
+}, []string{"host", "reason"})
+
+// QueryLogsIngested is the count of logs received from queries by the query task ID.
+var QueryLogsIngested = factory.NewCounterVec(prometheus.CounterOpts{
+	Namespace: namespace,
+	Name:      "query_logs_ingested_total",
+	Help:      "Count of logs received from queries.",
+}, []string{"task_id"})
+
+// BinaryChunkBytesWritten is the total size of log bodies and manifests written to binary chunks before compression.
+var BinaryChunkBytesWritten = factory.NewCounter(prometheus.CounterOpts{
+	Namespace: namespace,
+	Name:      "binary_chunk_bytes_written_total",
+	Help:      "Total size of log bodies and manifests written to binary chunks before compression.",
+})
+
+// UploadBytes is the size of files uploaded to the server.
+var UploadBytes = factory.NewHistogram(prometheus.HistogramOpts{
+	Namespace: namespace,
+	Name:      "upload_bytes",
+	Help:      "Size of files uploaded to the server.",
+	Buckets:   prometheus.ExponentialBuckets(1<<10, 4, 12),
+})
+
+// MemoryUsed is the memory used by the KHI process. It's updated when the metrics are scraped.
+var MemoryUsed = factory.NewGauge(prometheus.GaugeOpts{
+	Namespace: namespace,
+	Name:      "memory_used_bytes",
+	Help:      "Memory obtained from the OS by the KHI process.",
+})
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
@@ -18,16 +18,23 @@ import (
 	"context"
 	"crypto/md5"
 	"encoding/binary"
+	"errors"
 	"fmt"
 	"io"
 	"sync"
 
 	"github.com/GoogleCloudPlatform/khi/pkg/common"
 	"github.com/GoogleCloudPlatform/khi/pkg/inspection/metadata/progress"
+	"github.com/GoogleCloudPlatform/khi/pkg/metrics"
+	"github.com/GoogleCloudPlatform/khi/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 const MAXIMUM_CHUNK_SIZE = 1024 * 1024 * 500
 
+// ErrTotalSizeExceeded is returned from Builder.Write when the total size of written binaries exceeds the limit given with SetMaxTotalBytes.
+var ErrTotalSizeExceeded = errors.New("total size of binary chunks exceeded the limit")
+
 // Builder builds the list of binary data from given sequence of byte arrays.
 type Builder struct {
 	// Map between MD5 of given string and the reference of the buffer
@@ -36,7 +43,10 @@ type Builder struct {
 	bufferWriters  []LargeBinaryWriter
 	compressor     Compressor
 	maxChunkSize   int
-	lock           sync.Mutex
+	// maxTotalBytes is the limit of the total size of written binaries. 0 means unlimited.
+	maxTotalBytes int
+	totalBytes    int
+	lock          sync.Mutex
 }
 
 func NewBuilder(compressor Compressor, tmpFolderPath string) *Builder {
@@ -50,6 +60,13 @@ func NewBuilder(compressor Compressor, tmpFolderPath string) *Builder {
 	}
 }
 
+// SetMaxTotalBytes sets the limit of the total size of binaries written in this builder. 0 means unlimited.
+func (b *Builder) SetMaxTotalBytes(maxTotalBytes int) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.maxTotalBytes = maxTotalBytes
+}
+
 // Write amends the givenBinary in some binary chunk. If same body was given previously, it will return the reference from the cache.
 func (b *Builder) Write(binaryBody []byte) (*BinaryReference, error) {
 	hash := b.calcStringHash(binaryBody)
@@ -59,6 +76,10 @@ func (b *Builder) Write(binaryBody []byte) (*BinaryReference, error) {
 		return data, nil
 	}
 	b.lock.Lock()
+	if b.maxTotalBytes > 0 && b.totalBytes+len(binaryBody) > b.maxTotalBytes {
+		b.lock.Unlock()
+		return nil, fmt.Errorf("%w: %d bytes", ErrTotalSizeExceeded, b.maxTotalBytes)
+	}
 	targetIndex := len(b.bufferWriters)
 	for i := 0; i < len(b.bufferWriters); i++ {
 		if b.bufferWriters[i].CanWrite(len(binaryBody)) {
@@ -78,9 +99,12 @@ func (b *Builder) Write(binaryBody []byte) (*BinaryReference, error) {
 
 	resultReference, err := b.bufferWriters[targetIndex].Write(binaryBody)
 	if err != nil {
+		b.lock.Unlock()
 		return nil, err
 	}
+	b.totalBytes += len(binaryBody)
 	b.lock.Unlock()
+	metrics.BinaryChunkBytesWritten.Add(float64(len(binaryBody)))
 
 	refCache[hash] = resultReference
 	return resultReference, nil
@@ -97,10 +121,16 @@ func (b *Builder) Read(ref *BinaryReference) ([]byte, error) {
 }
 
 // Build amends all the binary buffers to the given writer in KHI format. Returns the written byte size.
-func (b *Builder) Build(ctx context.Context, writer io.Writer, progress *progress.TaskProgress) (int, error) {
+func (b *Builder) Build(ctx context.Context, writer io.Writer, progress *progress.TaskProgress) (size int, err error) {
+	ctx, span := tracing.Start(ctx, "binarychunk.Builder.Build")
+	defer func() {
+		span.SetAttributes(attribute.Int("khi.binarychunk.written_bytes", size))
+		tracing.EndSpan(span, err)
+	}()
 	allBinarySize := 0
 	b.lock.Lock()
 	defer b.lock.Unlock()
+	span.SetAttributes(attribute.Int("khi.binarychunk.buffer_count", len(b.bufferWriters)))
 	for i, binaryWriter := range b.bufferWriters {
 		select {
 		case <-ctx.Done():
@@ -175,3 +175,22 @@ func TestBuilder(t *testing.T) {
 		wg.Wait()
 	})
 }
+
+func TestBuilderMaxTotalBytes(t *testing.T) {
+	b := NewBuilder(NewFileSystemGzipCompressor(t.TempDir()), t.TempDir())
+	b.SetMaxTotalBytes(10)
+	if _, err := b.Write([]byte("0123456")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// Writing the same binary again is served from the cache and doesn't consume the limit.
+	if _, err := b.Write([]byte("0123456")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := b.Write([]byte("7890")); !errors.Is(err, ErrTotalSizeExceeded) {
+		t.Errorf("Write() over the limit returned %v, want ErrTotalSizeExceeded", err)
+	}
+	if _, err := b.Write([]byte("789")); err != nil {
+		t.Errorf("Write() within the limit returned %v", err)
+	}
+	b.Build(context.Background(), &bytes.Buffer{}, progress.NewTaskProgress("foo"))
+}
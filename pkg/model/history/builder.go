@@ -50,7 +50,11 @@ type Builder struct {
 	logIdToSerializableLog *common.ShardingMap[*SerializableLog]
 	historyResourceCache   *common.ShardingMap[*Resource]
 	sorter                 *ResourceSorter
-	ClusterResource        *resourceinfo.Cluster
+	// ClusterResource is the resource information of the cluster in single cluster inspections.
+	ClusterResource *resourceinfo.Cluster
+	// clusterResources holds the resource information of each cluster in multi-cluster inspections.
+	clusterResources     map[string]*resourceinfo.Cluster
+	clusterResourcesLock sync.Mutex
 }
 
 func NewBuilder(ioConfig *ioconfig.IOConfig) *Builder {
@@ -63,6 +67,7 @@ func NewBuilder(ioConfig *ioconfig.IOConfig) *Builder {
 		logIdToSerializableLog: common.NewShardingMap[*SerializableLog](common.NewSuffixShardingProvider(128, 4)),
 		historyResourceCache:   common.NewShardingMap[*Resource](common.NewSuffixShardingProvider(128, 4)),
 		ClusterResource:        resourceinfo.NewClusterResourceInfo(),
+		clusterResources:       map[string]*resourceinfo.Cluster{},
 		sorter: NewResourceSorter(
 			&FirstRevisionTimeSortStrategy{
 				TargetRelationship: enum.RelationshipPodBinding,
@@ -83,6 +88,26 @@ func NewBuilder(ioConfig *ioconfig.IOConfig) *Builder {
 	}
 }
 
+// ClusterResourceOf returns the resource information of the given cluster. It returns ClusterResource when the cluster name is empty.
+func (builder *Builder) ClusterResourceOf(clusterName string) *resourceinfo.Cluster {
+	if clusterName == "" {
+		return builder.ClusterResource
+	}
+	builder.clusterResourcesLock.Lock()
+	defer builder.clusterResourcesLock.Unlock()
+	if cluster, found := builder.clusterResources[clusterName]; found {
+		return cluster
+	}
+	cluster := resourceinfo.NewClusterResourceInfo()
+	builder.clusterResources[clusterName] = cluster
+	return cluster
+}
+
+// SetMaxBinaryBytes sets the limit of the total size of log bodies and manifests written in the binary chunk. 0 means unlimited.
+func (builder *Builder) SetMaxBinaryBytes(maxBinaryBytes int) {
+	builder.binaryChunk.SetMaxTotalBytes(maxBinaryBytes)
+}
+
 // Ensure specified resource path exists hierachicaly. Add resource history in middle or last when missing resource history was found on the path.
 // (This method will do something similar to `mkdir -p`.)
 func (builder *Builder) ensureResourcePath(resourcePath string) *Resource {
@@ -338,7 +363,6 @@ func (builder *Builder) sortData() error {
 
 // Finalize flushes the binary chunk data and serialized metadata to the given io.Writer. Returns the written data size in bytes and error.
 func (builder *Builder) Finalize(ctx context.Context, serializedMetadata map[string]interface{}, writer io.Writer, progress *progress.TaskProgress) (int, error) {
-	fileSize := 0
 	progress.Update(0, "Sorting log entries")
 	progress.MarkIndeterminate()
 	builder.history.Metadata = serializedMetadata
@@ -346,7 +370,13 @@ func (builder *Builder) Finalize(ctx context.Context, serializedMetadata map[str
 	if err != nil {
 		return 0, err
 	}
-	jsonString, err := json.Marshal(builder.history)
+	return WriteKHIFile(ctx, builder.history, builder.binaryChunk, writer, progress)
+}
+
+// WriteKHIFile writes the given History and the binary chunks referenced from it in the .khi file format. Returns the written data size in bytes and error.
+func WriteKHIFile(ctx context.Context, history *History, binaryChunk *binarychunk.Builder, writer io.Writer, progress *progress.TaskProgress) (int, error) {
+	fileSize := 0
+	jsonString, err := json.Marshal(history)
 	if err != nil {
 		return 0, err
 	}
@@ -372,7 +402,7 @@ func (builder *Builder) Finalize(ctx context.Context, serializedMetadata map[str
 		fileSize += writtenSize
 	}
 
-	if writtenSize, err := builder.binaryChunk.Build(ctx, writer, progress); err != nil {
+	if writtenSize, err := binaryChunk.Build(ctx, writer, progress); err != nil {
 		return 0, err
 	} else {
 		fileSize += writtenSize
@@ -235,6 +235,23 @@ func TestGetTimelineBuilder(t *testing.T) {
 	})
 }
 
+func TestClusterResourceOf(t *testing.T) {
+	builder := NewBuilder(&ioconfig.IOConfig{TemporaryFolder: "/tmp"})
+	if builder.ClusterResourceOf("") != builder.ClusterResource {
+		t.Errorf("ClusterResourceOf(\"\") must return ClusterResource")
+	}
+	clusterA := builder.ClusterResourceOf("cluster-a")
+	if clusterA == builder.ClusterResource {
+		t.Errorf("ClusterResourceOf(\"cluster-a\") returned ClusterResource")
+	}
+	if builder.ClusterResourceOf("cluster-a") != clusterA {
+		t.Errorf("ClusterResourceOf(\"cluster-a\") returned a different instance on the second call")
+	}
+	if builder.ClusterResourceOf("cluster-b") == clusterA {
+		t.Errorf("ClusterResourceOf(\"cluster-b\") returned the instance of cluster-a")
+	}
+}
+
 func TestGetChildResources(t *testing.T) {
 	testCases := []struct {
 		Resources         []string
@@ -64,6 +64,7 @@ func (cs *ChangeSet) RecordLogSeverity(severity enum.Severity) {
 }
 
 func (cs *ChangeSet) RecordRevision(resourcePath resourcepath.ResourcePath, revision *StagingResourceRevision) {
+	resourcePath = cs.clusterScoped(resourcePath)
 	if _, exist := cs.revisions[resourcePath.Path]; !exist {
 		cs.revisions[resourcePath.Path] = make([]*StagingResourceRevision, 0)
 	}
@@ -89,13 +90,14 @@ func (cs *ChangeSet) GetAllResourcePaths() []string {
 
 // GetRevisions returns every StagingResourceRevisions at the specified resource path.
 func (cs *ChangeSet) GetRevisions(resourcePath resourcepath.ResourcePath) []*StagingResourceRevision {
-	if revisions, exist := cs.revisions[resourcePath.Path]; exist {
+	if revisions, exist := cs.revisions[cs.clusterScoped(resourcePath).Path]; exist {
 		return revisions
 	}
 	return nil
 }
 
 func (cs *ChangeSet) RecordEvent(resourcePath resourcepath.ResourcePath) {
+	resourcePath = cs.clusterScoped(resourcePath)
 	event := ResourceEvent{
 		Log: cs.associatedLog.ID,
 	}
@@ -109,13 +111,15 @@ func (cs *ChangeSet) RecordEvent(resourcePath resourcepath.ResourcePath) {
 
 // GetEvents returns every ResourceEvents at the specified resource path.
 func (cs *ChangeSet) GetEvents(resourcePath resourcepath.ResourcePath) []*ResourceEvent {
-	if events, exist := cs.events[resourcePath.Path]; exist {
+	if events, exist := cs.events[cs.clusterScoped(resourcePath).Path]; exist {
 		return events
 	}
 	return nil
 }
 
 func (cs *ChangeSet) RecordResourceAlias(sourceResourcePath resourcepath.ResourcePath, destResourcePath resourcepath.ResourcePath) {
+	sourceResourcePath = cs.clusterScoped(sourceResourcePath)
+	destResourcePath = cs.clusterScoped(destResourcePath)
 	if _, exist := cs.aliases[sourceResourcePath.Path]; !exist {
 		cs.aliases[sourceResourcePath.Path] = make([]string, 0)
 	}
@@ -139,6 +143,14 @@ func (cs *ChangeSet) recordResourceRelationship(resourcePath resourcepath.Resour
 	return nil
 }
 
+// clusterScoped returns the resource path scoped with the cluster of the associated log. Logs of multi-cluster inspections are recorded under the timelines of their own clusters.
+func (cs *ChangeSet) clusterScoped(resourcePath resourcepath.ResourcePath) resourcepath.ResourcePath {
+	if cs.associatedLog == nil {
+		return resourcePath
+	}
+	return resourcepath.ClusterScoped(cs.associatedLog.ClusterName, resourcePath)
+}
+
 // FlushToHistory writes the recorded changeset to the history and returns resource paths where the resource modified.
 func (cs *ChangeSet) FlushToHistory(builder *Builder) ([]string, error) {
 	changedPaths := []string{}
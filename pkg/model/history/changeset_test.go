@@ -62,6 +62,24 @@ func TestRecordEvents(t *testing.T) {
 	}
 }
 
+func TestRecordEventsWithClusterName(t *testing.T) {
+	log := testlog.NewEmptyLogWithID("foo")
+	log.ClusterName = "cluster-a"
+	cs := NewChangeSet(log)
+	cs.RecordEvent(resourcepath.KindLayerGeneralItem("A", "B"))
+	if diff := cmp.Diff(cs.events, map[string][]*ResourceEvent{
+		"cluster-a:A#B": {{Log: "foo"}},
+	}); diff != "" {
+		t.Errorf("RecordEvent didn't modify ChangeSet as expected\n%s", diff)
+	}
+	if diff := cmp.Diff([]*ResourceEvent{{Log: "foo"}}, cs.GetEvents(resourcepath.KindLayerGeneralItem("A", "B"))); diff != "" {
+		t.Errorf("GetEvents() mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]LogAnnotation{NewResourceReferenceAnnotation("cluster-a:A#B")}, cs.annotations); diff != "" {
+		t.Errorf("annotations mismatch (-want +got):\n%s", diff)
+	}
+}
+
 func TestGetEvents(t *testing.T) {
 	log := testlog.NewEmptyLogWithID("foo")
 	cs := NewChangeSet(log)
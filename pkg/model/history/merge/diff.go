@@ -0,0 +1,159 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merge
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/model/enum"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/history"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/history/reader"
+)
+
+// RevisionSummary is a revision of a resource reported in a diff.
+type RevisionSummary struct {
+	ChangeTime time.Time          `json:"changeTime"`
+	Verb       enum.RevisionVerb  `json:"verb"`
+	State      enum.RevisionState `json:"state"`
+	Log        string             `json:"log"`
+}
+
+// ResourceDiff is the difference of revisions recorded for a resource between 2 .khi files.
+type ResourceDiff struct {
+	// ResourcePath is the full resource path of the resource.
+	ResourcePath string `json:"resourcePath"`
+	// OnlyInBase is the list of revisions found only in the base file.
+	OnlyInBase []*RevisionSummary `json:"onlyInBase"`
+	// OnlyInTarget is the list of revisions found only in the target file.
+	OnlyInTarget []*RevisionSummary `json:"onlyInTarget"`
+}
+
+// DiffResult is the list of resources having different revisions sorted by the resource path.
+type DiffResult struct {
+	Resources []*ResourceDiff `json:"resources"`
+}
+
+// diffRevisionKey identifies a revision regardless of the file. The body is compared with its hash because binary references differ between files.
+type diffRevisionKey struct {
+	changeTime time.Time
+	verb       enum.RevisionVerb
+	state      enum.RevisionState
+	bodyHash   string
+}
+
+type keyedRevision struct {
+	key      diffRevisionKey
+	revision *history.ResourceRevision
+}
+
+// Diff reports the resources whose revisions differ between the base and the target .khi files.
+// A resource found only in one of the files is reported with all of its revisions.
+func Diff(base, target *reader.Reader) (*DiffResult, error) {
+	baseRevisions, err := revisionsByResourcePath(base)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the base file\n%w", err)
+	}
+	targetRevisions, err := revisionsByResourcePath(target)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the target file\n%w", err)
+	}
+	paths := map[string]struct{}{}
+	for path := range baseRevisions {
+		paths[path] = struct{}{}
+	}
+	for path := range targetRevisions {
+		paths[path] = struct{}{}
+	}
+	result := &DiffResult{Resources: []*ResourceDiff{}}
+	for path := range paths {
+		onlyInBase := subtractRevisions(baseRevisions[path], targetRevisions[path])
+		onlyInTarget := subtractRevisions(targetRevisions[path], baseRevisions[path])
+		if len(onlyInBase) == 0 && len(onlyInTarget) == 0 {
+			continue
+		}
+		result.Resources = append(result.Resources, &ResourceDiff{
+			ResourcePath: path,
+			OnlyInBase:   onlyInBase,
+			OnlyInTarget: onlyInTarget,
+		})
+	}
+	sort.Slice(result.Resources, func(i, j int) bool {
+		return result.Resources[i].ResourcePath < result.Resources[j].ResourcePath
+	})
+	return result, nil
+}
+
+// revisionsByResourcePath returns the revisions of every resource having a timeline in the file keyed by its full resource path.
+func revisionsByResourcePath(source *reader.Reader) (map[string][]keyedRevision, error) {
+	result := map[string][]keyedRevision{}
+	err := source.WalkResources(func(resource *history.Resource, depth int) error {
+		if resource.Timeline == "" {
+			return nil
+		}
+		timeline := source.Timeline(resource.Timeline)
+		if timeline == nil {
+			return nil
+		}
+		revisions := []keyedRevision{}
+		for _, revision := range timeline.Revisions {
+			bodyHash := ""
+			if revision.Body != nil {
+				body, err := source.Read(revision.Body)
+				if err != nil {
+					return err
+				}
+				hash := sha256.Sum256(body)
+				bodyHash = hex.EncodeToString(hash[:])
+			}
+			revisions = append(revisions, keyedRevision{
+				key: diffRevisionKey{
+					changeTime: revision.ChangeTime,
+					verb:       revision.Verb,
+					state:      revision.State,
+					bodyHash:   bodyHash,
+				},
+				revision: revision,
+			})
+		}
+		result[resource.FullResourcePath] = revisions
+		return nil
+	})
+	return result, err
+}
+
+// subtractRevisions returns the revisions in a not found in b.
+func subtractRevisions(a, b []keyedRevision) []*RevisionSummary {
+	keys := map[diffRevisionKey]struct{}{}
+	for _, revision := range b {
+		keys[revision.key] = struct{}{}
+	}
+	result := []*RevisionSummary{}
+	for _, revision := range a {
+		if _, found := keys[revision.key]; found {
+			continue
+		}
+		result = append(result, &RevisionSummary{
+			ChangeTime: revision.revision.ChangeTime,
+			Verb:       revision.revision.Verb,
+			State:      revision.revision.State,
+			Log:        revision.revision.Log,
+		})
+	}
+	return result
+}
@@ -0,0 +1,70 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merge
+
+import (
+	"testing"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/model/enum"
+	"github.com/google/go-cmp/cmp"
+
+	_ "github.com/GoogleCloudPlatform/khi/internal/testflags"
+)
+
+func TestDiff(t *testing.T) {
+	base := newTestReader(t, 0, 10,
+		testResource{path: "pod-a", revisions: []testRevision{
+			{log: "log-1", minute: 1, verb: enum.RevisionVerbCreate, body: "a1"},
+			{log: "log-2", minute: 2, verb: enum.RevisionVerbUpdate, body: "a2"},
+		}},
+		testResource{path: "pod-b", revisions: []testRevision{
+			{log: "log-3", minute: 3, verb: enum.RevisionVerbCreate, body: "b3"},
+		}},
+	)
+	target := newTestReader(t, 0, 10,
+		testResource{path: "pod-a", revisions: []testRevision{
+			{log: "log-1", minute: 1, verb: enum.RevisionVerbCreate, body: "a1"},
+			{log: "log-2", minute: 2, verb: enum.RevisionVerbUpdate, body: "a2-changed"},
+		}},
+		testResource{path: "pod-b", revisions: []testRevision{
+			{log: "log-3", minute: 3, verb: enum.RevisionVerbCreate, body: "b3"},
+		}},
+		testResource{path: "pod-c", revisions: []testRevision{
+			{log: "log-4", minute: 4, verb: enum.RevisionVerbCreate, body: "c4"},
+		}},
+	)
+
+	got, err := Diff(base, target)
+	if err != nil {
+		t.Fatalf("Diff() returned an unexpected error: %v", err)
+	}
+	want := &DiffResult{
+		Resources: []*ResourceDiff{
+			{
+				ResourcePath: "core/v1#pod#default#pod-a",
+				OnlyInBase:   []*RevisionSummary{{ChangeTime: testTime(2), Verb: enum.RevisionVerbUpdate, State: enum.RevisionStateExisting, Log: "log-2"}},
+				OnlyInTarget: []*RevisionSummary{{ChangeTime: testTime(2), Verb: enum.RevisionVerbUpdate, State: enum.RevisionStateExisting, Log: "log-2"}},
+			},
+			{
+				ResourcePath: "core/v1#pod#default#pod-c",
+				OnlyInBase:   []*RevisionSummary{},
+				OnlyInTarget: []*RevisionSummary{{ChangeTime: testTime(4), Verb: enum.RevisionVerbCreate, State: enum.RevisionStateExisting, Log: "log-4"}},
+			},
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Diff() mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,375 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merge
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/inspection/metadata/header"
+	"github.com/GoogleCloudPlatform/khi/pkg/inspection/metadata/progress"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/binarychunk"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/enum"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/history"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/history/reader"
+)
+
+// Result is the summary of a merge.
+type Result struct {
+	// Header is the header metadata written in the merged file. Its time range covers every source.
+	Header *header.Header
+	// FileSize is the size of the merged file in bytes.
+	FileSize int
+	// LogCount is the count of logs in the merged file.
+	LogCount int
+	// DuplicatedLogCount is the count of logs dropped because a log with the same ID was found in a former source.
+	DuplicatedLogCount int
+}
+
+// timelineKey identifies a timeline in a source file.
+type timelineKey struct {
+	source     int
+	timelineID string
+}
+
+// merger accumulates histories read from .khi files into a History.
+type merger struct {
+	chunk       *binarychunk.Builder
+	history     *history.History
+	logs        map[string]*history.SerializableLog
+	resources   map[string]*history.Resource
+	timelines   map[string]*history.ResourceTimeline
+	timelineIDs map[timelineKey]string
+	// revisionKeys and eventKeys are used to drop revisions and events already merged from the other sources.
+	revisionKeys       map[string]map[revisionKey]struct{}
+	eventKeys          map[string]map[string]struct{}
+	duplicatedLogCount int
+}
+
+// revisionKey identifies a revision across sources. Revisions generated from the same log at the same time are regarded as the same revision.
+type revisionKey struct {
+	log        string
+	changeTime time.Time
+	verb       enum.RevisionVerb
+	state      enum.RevisionState
+}
+
+// Merge combines the histories in the given .khi files into a .khi file written to the writer.
+// Logs are deduplicated by their IDs, resources are unified by their full resource paths and revisions and events of the same resource are merged in the time order.
+// The metadata of the first source is used for the merged file except the time range in the header.
+func Merge(ctx context.Context, sources []*reader.Reader, temporaryFolder string, writer io.Writer) (*Result, error) {
+	if len(sources) == 0 {
+		return nil, fmt.Errorf("no .khi file to merge")
+	}
+	m := &merger{
+		chunk:        binarychunk.NewBuilder(binarychunk.NewFileSystemGzipCompressor(temporaryFolder), temporaryFolder),
+		history:      history.NewHistory(),
+		logs:         map[string]*history.SerializableLog{},
+		resources:    map[string]*history.Resource{},
+		timelines:    map[string]*history.ResourceTimeline{},
+		timelineIDs:  map[timelineKey]string{},
+		revisionKeys: map[string]map[revisionKey]struct{}{},
+		eventKeys:    map[string]map[string]struct{}{},
+	}
+	for i, source := range sources {
+		if err := m.add(i, source); err != nil {
+			return nil, fmt.Errorf("failed to merge the source #%d\n%w", i, err)
+		}
+	}
+	m.sort()
+
+	mergedHeader, err := mergeHeaders(sources)
+	if err != nil {
+		return nil, err
+	}
+	m.history.Metadata = map[string]any{}
+	for key, value := range sources[0].History.Metadata {
+		m.history.Metadata[key] = value
+	}
+	if mergedHeader != nil {
+		m.history.Metadata[header.HeaderMetadataKey.Key()] = mergedHeader
+	}
+
+	fileSize, err := history.WriteKHIFile(ctx, m.history, m.chunk, writer, progress.NewTaskProgress("merge"))
+	if err != nil {
+		return nil, err
+	}
+	if mergedHeader != nil {
+		mergedHeader.FileSize = fileSize
+	}
+	return &Result{
+		Header:             mergedHeader,
+		FileSize:           fileSize,
+		LogCount:           len(m.history.Logs),
+		DuplicatedLogCount: m.duplicatedLogCount,
+	}, nil
+}
+
+// add merges the history of a source.
+func (m *merger) add(sourceIndex int, source *reader.Reader) error {
+	for _, l := range source.History.Logs {
+		if err := m.addLog(source, l); err != nil {
+			return err
+		}
+	}
+	m.addResources(sourceIndex, source.History.Resources, &m.history.Resources)
+	for _, timeline := range source.History.Timelines {
+		timelineID, found := m.timelineIDs[timelineKey{source: sourceIndex, timelineID: timeline.ID}]
+		if !found {
+			// The timeline is not referenced from any resource.
+			continue
+		}
+		if err := m.addTimeline(source, timelineID, timeline); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *merger) addLog(source *reader.Reader, l *history.SerializableLog) error {
+	if _, found := m.logs[l.ID]; found {
+		m.duplicatedLogCount++
+		return nil
+	}
+	body, err := m.copyBinary(source, l.Body)
+	if err != nil {
+		return err
+	}
+	summary, err := m.copyBinary(source, l.Summary)
+	if err != nil {
+		return err
+	}
+	annotations := []any{}
+	for _, annotation := range l.Annotations {
+		copied, err := m.copyAnnotation(source, annotation)
+		if err != nil {
+			return err
+		}
+		annotations = append(annotations, copied)
+	}
+	merged := &history.SerializableLog{
+		Timestamp:   l.Timestamp,
+		ID:          l.ID,
+		DisplayId:   l.DisplayId,
+		Body:        body,
+		Type:        l.Type,
+		Summary:     summary,
+		Severity:    l.Severity,
+		Annotations: annotations,
+	}
+	m.logs[l.ID] = merged
+	m.history.Logs = append(m.history.Logs, merged)
+	return nil
+}
+
+// addResources unions the resource tree of a source with the merged tree by their full resource paths.
+func (m *merger) addResources(sourceIndex int, resources []*history.Resource, container *[]*history.Resource) {
+	for _, resource := range resources {
+		merged, found := m.resources[resource.FullResourcePath]
+		if !found {
+			merged = &history.Resource{
+				ResourceName:     resource.ResourceName,
+				Relationship:     resource.Relationship,
+				Children:         []*history.Resource{},
+				FullResourcePath: resource.FullResourcePath,
+			}
+			m.resources[resource.FullResourcePath] = merged
+			*container = append(*container, merged)
+		}
+		if merged.Relationship == enum.RelationshipChild {
+			merged.Relationship = resource.Relationship
+		}
+		if resource.Timeline != "" {
+			key := timelineKey{source: sourceIndex, timelineID: resource.Timeline}
+			timelineID, mapped := m.timelineIDs[key]
+			switch {
+			case mapped && merged.Timeline == "":
+				// The timeline is shared with another resource as an alias in the source.
+				merged.Timeline = timelineID
+			case !mapped && merged.Timeline != "":
+				m.timelineIDs[key] = merged.Timeline
+			case !mapped:
+				timelineID = fmt.Sprintf("m%d", len(m.timelines))
+				timeline := &history.ResourceTimeline{
+					ID:        timelineID,
+					Revisions: []*history.ResourceRevision{},
+					Events:    []*history.ResourceEvent{},
+				}
+				m.timelines[timelineID] = timeline
+				m.history.Timelines = append(m.history.Timelines, timeline)
+				m.timelineIDs[key] = timelineID
+				merged.Timeline = timelineID
+			}
+		}
+		m.addResources(sourceIndex, resource.Children, &merged.Children)
+	}
+}
+
+func (m *merger) addTimeline(source *reader.Reader, timelineID string, timeline *history.ResourceTimeline) error {
+	merged := m.timelines[timelineID]
+	if _, found := m.revisionKeys[timelineID]; !found {
+		m.revisionKeys[timelineID] = map[revisionKey]struct{}{}
+		m.eventKeys[timelineID] = map[string]struct{}{}
+	}
+	for _, revision := range timeline.Revisions {
+		key := revisionKey{log: revision.Log, changeTime: revision.ChangeTime, verb: revision.Verb, state: revision.State}
+		if _, found := m.revisionKeys[timelineID][key]; found {
+			continue
+		}
+		m.revisionKeys[timelineID][key] = struct{}{}
+		requestor, err := m.copyBinary(source, revision.Requestor)
+		if err != nil {
+			return err
+		}
+		body, err := m.copyBinary(source, revision.Body)
+		if err != nil {
+			return err
+		}
+		merged.Revisions = append(merged.Revisions, &history.ResourceRevision{
+			Log:        revision.Log,
+			Verb:       revision.Verb,
+			Requestor:  requestor,
+			Body:       body,
+			ChangeTime: revision.ChangeTime,
+			State:      revision.State,
+			Partial:    revision.Partial,
+		})
+	}
+	for _, event := range timeline.Events {
+		if _, found := m.eventKeys[timelineID][event.Log]; found {
+			continue
+		}
+		m.eventKeys[timelineID][event.Log] = struct{}{}
+		merged.Events = append(merged.Events, &history.ResourceEvent{Log: event.Log})
+	}
+	return nil
+}
+
+// sort orders logs, revisions and events merged from multiple sources by time.
+func (m *merger) sort() {
+	sort.SliceStable(m.history.Logs, func(i, j int) bool {
+		return m.history.Logs[i].Timestamp.Before(m.history.Logs[j].Timestamp)
+	})
+	for _, timeline := range m.history.Timelines {
+		sort.SliceStable(timeline.Revisions, func(i, j int) bool {
+			return timeline.Revisions[i].ChangeTime.Before(timeline.Revisions[j].ChangeTime)
+		})
+		sort.SliceStable(timeline.Events, func(i, j int) bool {
+			return m.logTimestamp(timeline.Events[i].Log).Before(m.logTimestamp(timeline.Events[j].Log))
+		})
+	}
+}
+
+func (m *merger) logTimestamp(logID string) time.Time {
+	if l, found := m.logs[logID]; found {
+		return l.Timestamp
+	}
+	return time.Time{}
+}
+
+// copyBinary writes the binary pointed by the reference in the source to the merged binary chunks.
+func (m *merger) copyBinary(source *reader.Reader, ref *binarychunk.BinaryReference) (*binarychunk.BinaryReference, error) {
+	if ref == nil {
+		return nil, nil
+	}
+	data, err := source.Read(ref)
+	if err != nil {
+		return nil, err
+	}
+	return m.chunk.Write(data)
+}
+
+// copyAnnotation returns the log annotation with its binary references rewritten to the merged binary chunks.
+// Annotations are read as generic maps from the JSON part and the fields having the shape of BinaryReference are regarded as binary references.
+func (m *merger) copyAnnotation(source *reader.Reader, annotation any) (any, error) {
+	fields, ok := annotation.(map[string]any)
+	if !ok {
+		return annotation, nil
+	}
+	copied := map[string]any{}
+	for key, value := range fields {
+		ref, isReference := asBinaryReference(value)
+		if !isReference {
+			copied[key] = value
+			continue
+		}
+		copiedRef, err := m.copyBinary(source, ref)
+		if err != nil {
+			return nil, err
+		}
+		copied[key] = copiedRef
+	}
+	return copied, nil
+}
+
+func asBinaryReference(value any) (*binarychunk.BinaryReference, bool) {
+	fields, ok := value.(map[string]any)
+	if !ok || len(fields) != 3 {
+		return nil, false
+	}
+	for _, key := range []string{"offset", "len", "buffer"} {
+		if _, found := fields[key]; !found {
+			return nil, false
+		}
+	}
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return nil, false
+	}
+	var ref binarychunk.BinaryReference
+	if err := json.Unmarshal(data, &ref); err != nil {
+		return nil, false
+	}
+	return &ref, true
+}
+
+// mergeHeaders returns the header of the first source with the time range covering every source. It returns nil when the first source has no header.
+func mergeHeaders(sources []*reader.Reader) (*header.Header, error) {
+	var merged *header.Header
+	for i, source := range sources {
+		headerAny, found := source.History.Metadata[header.HeaderMetadataKey.Key()]
+		if !found {
+			continue
+		}
+		data, err := json.Marshal(headerAny)
+		if err != nil {
+			return nil, err
+		}
+		var sourceHeader header.Header
+		if err := json.Unmarshal(data, &sourceHeader); err != nil {
+			return nil, fmt.Errorf("failed to read the header of the source #%d\n%w", i, err)
+		}
+		if merged == nil {
+			if i != 0 {
+				return nil, nil
+			}
+			merged = &sourceHeader
+			continue
+		}
+		merged.StartTimeUnixSeconds = min(merged.StartTimeUnixSeconds, sourceHeader.StartTimeUnixSeconds)
+		merged.EndTimeUnixSeconds = max(merged.EndTimeUnixSeconds, sourceHeader.EndTimeUnixSeconds)
+	}
+	if merged == nil {
+		return nil, nil
+	}
+	merged.InspectTimeUnixSeconds = time.Now().Unix()
+	merged.SuggestedFileName = fmt.Sprintf("merged-%s", merged.SuggestedFileName)
+	merged.FileSize = 0
+	return merged, nil
+}
@@ -0,0 +1,221 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merge
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/inspection/metadata/header"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/binarychunk"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/enum"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/history"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/history/reader"
+	"github.com/GoogleCloudPlatform/khi/pkg/testutil/khifile"
+	"github.com/google/go-cmp/cmp"
+
+	_ "github.com/GoogleCloudPlatform/khi/internal/testflags"
+)
+
+type testRevision struct {
+	log    string
+	minute int
+	verb   enum.RevisionVerb
+	body   string
+}
+
+type testResource struct {
+	path      string
+	revisions []testRevision
+}
+
+func testTime(minute int) time.Time {
+	return time.Date(2025, time.January, 1, 0, minute, 0, 0, time.UTC)
+}
+
+// newTestReader generates a .khi file containing the given pods under core/v1#pod#default and returns its reader.
+func newTestReader(t *testing.T, startMinute, endMinute int, resources ...testResource) *reader.Reader {
+	t.Helper()
+	chunk := binarychunk.NewBuilder(binarychunk.NewFileSystemGzipCompressor(t.TempDir()), t.TempDir())
+	mustWrite := func(s string) *binarychunk.BinaryReference {
+		ref, err := chunk.Write([]byte(s))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ref
+	}
+	h := history.NewHistory()
+	h.Metadata = map[string]any{
+		header.HeaderMetadataKey.Key(): &header.Header{
+			InspectionType:       "test",
+			StartTimeUnixSeconds: testTime(startMinute).Unix(),
+			EndTimeUnixSeconds:   testTime(endMinute).Unix(),
+			SuggestedFileName:    "test.khi",
+		},
+	}
+	namespace := &history.Resource{ResourceName: "default", FullResourcePath: "core/v1#pod#default", Children: []*history.Resource{}}
+	h.Resources = []*history.Resource{
+		{
+			ResourceName:     "core/v1",
+			FullResourcePath: "core/v1",
+			Children: []*history.Resource{
+				{ResourceName: "pod", FullResourcePath: "core/v1#pod", Children: []*history.Resource{namespace}},
+			},
+		},
+	}
+	logs := map[string]struct{}{}
+	for _, resource := range resources {
+		// Use different timeline IDs in each file to verify timelines are merged by the resource path.
+		timeline := &history.ResourceTimeline{ID: resource.path + "-" + resource.revisions[0].log, Revisions: []*history.ResourceRevision{}, Events: []*history.ResourceEvent{}}
+		for _, revision := range resource.revisions {
+			if _, found := logs[revision.log]; !found {
+				logs[revision.log] = struct{}{}
+				h.Logs = append(h.Logs, &history.SerializableLog{
+					ID:          revision.log,
+					Timestamp:   testTime(revision.minute),
+					Body:        mustWrite("body of " + revision.log),
+					Summary:     mustWrite("summary of " + revision.log),
+					Type:        enum.LogTypeAudit,
+					Severity:    enum.SeverityInfo,
+					Annotations: []any{map[string]any{"path": mustWrite("annotation of " + revision.log)}},
+				})
+			}
+			timeline.Revisions = append(timeline.Revisions, &history.ResourceRevision{
+				Log:        revision.log,
+				Verb:       revision.verb,
+				State:      enum.RevisionStateExisting,
+				Body:       mustWrite(revision.body),
+				ChangeTime: testTime(revision.minute),
+			})
+			timeline.Events = append(timeline.Events, &history.ResourceEvent{Log: revision.log})
+		}
+		h.Timelines = append(h.Timelines, timeline)
+		namespace.Children = append(namespace.Children, &history.Resource{
+			ResourceName:     resource.path,
+			FullResourcePath: "core/v1#pod#default#" + resource.path,
+			Timeline:         timeline.ID,
+		})
+	}
+	data := khifile.MustGenerateKHIFile(h, chunk)
+	r, err := reader.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func TestMerge(t *testing.T) {
+	source1 := newTestReader(t, 0, 10,
+		testResource{path: "pod-a", revisions: []testRevision{
+			{log: "log-1", minute: 1, verb: enum.RevisionVerbCreate, body: "a1"},
+			{log: "log-3", minute: 3, verb: enum.RevisionVerbUpdate, body: "a3"},
+		}},
+	)
+	source2 := newTestReader(t, 5, 20,
+		testResource{path: "pod-a", revisions: []testRevision{
+			{log: "log-2", minute: 2, verb: enum.RevisionVerbUpdate, body: "a2"},
+			{log: "log-3", minute: 3, verb: enum.RevisionVerbUpdate, body: "a3"},
+		}},
+		testResource{path: "pod-b", revisions: []testRevision{
+			{log: "log-4", minute: 4, verb: enum.RevisionVerbCreate, body: "b4"},
+		}},
+	)
+
+	var buf bytes.Buffer
+	result, err := Merge(context.Background(), []*reader.Reader{source1, source2}, t.TempDir(), &buf)
+	if err != nil {
+		t.Fatalf("Merge() returned an unexpected error: %v", err)
+	}
+	if result.LogCount != 4 || result.DuplicatedLogCount != 1 {
+		t.Errorf("Merge() got LogCount=%d DuplicatedLogCount=%d, want 4 and 1", result.LogCount, result.DuplicatedLogCount)
+	}
+	if result.FileSize != buf.Len() {
+		t.Errorf("Merge() got FileSize=%d, want %d", result.FileSize, buf.Len())
+	}
+	if result.Header.StartTimeUnixSeconds != testTime(0).Unix() || result.Header.EndTimeUnixSeconds != testTime(20).Unix() {
+		t.Errorf("Merge() got the header time range %d-%d, want %d-%d", result.Header.StartTimeUnixSeconds, result.Header.EndTimeUnixSeconds, testTime(0).Unix(), testTime(20).Unix())
+	}
+
+	merged, err := reader.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	logIDs := []string{}
+	for _, l := range merged.History.Logs {
+		logIDs = append(logIDs, l.ID)
+	}
+	if diff := cmp.Diff([]string{"log-1", "log-2", "log-3", "log-4"}, logIDs); diff != "" {
+		t.Errorf("merged logs mismatch (-want +got):\n%s", diff)
+	}
+	body, err := merged.ReadString(merged.Log("log-2").Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body != "body of log-2" {
+		t.Errorf("merged log body = %q, want %q", body, "body of log-2")
+	}
+	annotation := merged.Log("log-4").Annotations[0].(map[string]any)
+	annotationRef, isReference := asBinaryReference(annotation["path"])
+	if !isReference {
+		t.Fatalf("merged annotation %v doesn't contain a binary reference", annotation)
+	}
+	annotationBody, err := merged.ReadString(annotationRef)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if annotationBody != "annotation of log-4" {
+		t.Errorf("merged annotation = %q, want %q", annotationBody, "annotation of log-4")
+	}
+
+	paths := []string{}
+	revisionBodies := map[string][]string{}
+	err = merged.WalkResources(func(resource *history.Resource, depth int) error {
+		paths = append(paths, resource.FullResourcePath)
+		if resource.Timeline == "" {
+			return nil
+		}
+		for _, revision := range merged.Timeline(resource.Timeline).Revisions {
+			body, err := merged.ReadString(revision.Body)
+			if err != nil {
+				return err
+			}
+			revisionBodies[resource.FullResourcePath] = append(revisionBodies[resource.FullResourcePath], body)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"core/v1", "core/v1#pod", "core/v1#pod#default", "core/v1#pod#default#pod-a", "core/v1#pod#default#pod-b"}, paths); diff != "" {
+		t.Errorf("merged resources mismatch (-want +got):\n%s", diff)
+	}
+	wantBodies := map[string][]string{
+		"core/v1#pod#default#pod-a": {"a1", "a2", "a3"},
+		"core/v1#pod#default#pod-b": {"b4"},
+	}
+	if diff := cmp.Diff(wantBodies, revisionBodies); diff != "" {
+		t.Errorf("merged revisions mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestMergeWithoutSources(t *testing.T) {
+	var buf bytes.Buffer
+	_, err := Merge(context.Background(), []*reader.Reader{}, t.TempDir(), &buf)
+	if err == nil {
+		t.Errorf("Merge() with no source returned no error")
+	}
+}
@@ -0,0 +1,255 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reader
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/inspection/inspectiondata"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/binarychunk"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/history"
+)
+
+// FileMagic is the leading bytes of every .khi file.
+const FileMagic = "KHI"
+
+// ErrInvalidFormat is returned when the given data is not a valid .khi file.
+var ErrInvalidFormat = errors.New("invalid khi file format")
+
+// chunkLocation is the location of a compressed binary chunk in the .khi file.
+type chunkLocation struct {
+	offset int64
+	size   int64
+}
+
+// Reader reads a .khi file written by history.Builder.Finalize.
+// The History JSON part is parsed eagerly, but binary chunks are only decompressed when a BinaryReference pointing them is resolved.
+type Reader struct {
+	// History is the inspection data parsed from the JSON part of the file.
+	History *history.History
+
+	source    io.ReaderAt
+	closer    io.Closer
+	chunks    []chunkLocation
+	timelines map[string]*history.ResourceTimeline
+	logs      map[string]*history.SerializableLog
+
+	chunkCacheLock sync.Mutex
+	chunkCache     map[int][]byte
+}
+
+// Open opens the .khi file at the given path. Callers must call Close after use.
+func Open(filePath string) (*Reader, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	stat, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	reader, err := NewReader(file, stat.Size())
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	reader.closer = file
+	return reader, nil
+}
+
+// NewReader reads the .khi data from the given io.ReaderAt with the given size in bytes.
+func NewReader(source io.ReaderAt, size int64) (*Reader, error) {
+	header := make([]byte, len(FileMagic)+4)
+	if _, err := source.ReadAt(header, 0); err != nil {
+		return nil, fmt.Errorf("%w: failed to read the file header\n%v", ErrInvalidFormat, err)
+	}
+	if string(header[:len(FileMagic)]) != FileMagic {
+		return nil, fmt.Errorf("%w: the file doesn't start with %q", ErrInvalidFormat, FileMagic)
+	}
+	jsonSize := int64(binary.LittleEndian.Uint32(header[len(FileMagic):]))
+	jsonOffset := int64(len(header))
+	if jsonOffset+jsonSize > size {
+		return nil, fmt.Errorf("%w: the JSON part length %d exceeds the file size %d", ErrInvalidFormat, jsonSize, size)
+	}
+	var parsedHistory history.History
+	if err := json.NewDecoder(io.NewSectionReader(source, jsonOffset, jsonSize)).Decode(&parsedHistory); err != nil {
+		return nil, fmt.Errorf("%w: failed to parse the JSON part\n%v", ErrInvalidFormat, err)
+	}
+
+	chunks := []chunkLocation{}
+	chunkSizeBytes := make([]byte, 4)
+	for offset := jsonOffset + jsonSize; offset < size; {
+		if _, err := source.ReadAt(chunkSizeBytes, offset); err != nil {
+			return nil, fmt.Errorf("%w: failed to read the size of binary chunk #%d\n%v", ErrInvalidFormat, len(chunks), err)
+		}
+		chunkSize := int64(binary.BigEndian.Uint32(chunkSizeBytes))
+		offset += int64(len(chunkSizeBytes))
+		if offset+chunkSize > size {
+			return nil, fmt.Errorf("%w: binary chunk #%d exceeds the file size", ErrInvalidFormat, len(chunks))
+		}
+		chunks = append(chunks, chunkLocation{offset: offset, size: chunkSize})
+		offset += chunkSize
+	}
+
+	timelines := map[string]*history.ResourceTimeline{}
+	for _, timeline := range parsedHistory.Timelines {
+		timelines[timeline.ID] = timeline
+	}
+	logs := map[string]*history.SerializableLog{}
+	for _, l := range parsedHistory.Logs {
+		logs[l.ID] = l
+	}
+
+	return &Reader{
+		History:    &parsedHistory,
+		source:     source,
+		chunks:     chunks,
+		timelines:  timelines,
+		logs:       logs,
+		chunkCache: map[int][]byte{},
+	}, nil
+}
+
+// Timeline returns the ResourceTimeline with the given timeline ID. Returns nil when it wasn't found.
+func (r *Reader) Timeline(timelineID string) *history.ResourceTimeline {
+	return r.timelines[timelineID]
+}
+
+// Log returns the SerializableLog with the given log ID. Returns nil when it wasn't found.
+func (r *Reader) Log(logID string) *history.SerializableLog {
+	return r.logs[logID]
+}
+
+// WalkResources calls the given function for each resource in the resource tree in depth first order.
+// Walking stops when the function returns an error and the error is returned.
+func (r *Reader) WalkResources(walker func(resource *history.Resource, depth int) error) error {
+	return walkResources(r.History.Resources, 0, walker)
+}
+
+func walkResources(resources []*history.Resource, depth int, walker func(resource *history.Resource, depth int) error) error {
+	for _, resource := range resources {
+		if err := walker(resource, depth); err != nil {
+			return err
+		}
+		if err := walkResources(resource.Children, depth+1, walker); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ChunkCount returns the count of binary chunks contained in the file.
+func (r *Reader) ChunkCount() int {
+	return len(r.chunks)
+}
+
+// Read returns the bytes pointed by the given BinaryReference.
+func (r *Reader) Read(ref *binarychunk.BinaryReference) ([]byte, error) {
+	if ref == nil {
+		return nil, fmt.Errorf("binary reference is nil")
+	}
+	chunk, err := r.chunk(ref.Buffer)
+	if err != nil {
+		return nil, err
+	}
+	if ref.Offset < 0 || ref.Length < 0 || ref.Offset+ref.Length > len(chunk) {
+		return nil, fmt.Errorf("binary reference (offset:%d,len:%d) is out of the range of buffer %d (size:%d)", ref.Offset, ref.Length, ref.Buffer, len(chunk))
+	}
+	return chunk[ref.Offset : ref.Offset+ref.Length], nil
+}
+
+// ReadString returns the string pointed by the given BinaryReference. It returns an empty string when the reference is nil.
+func (r *Reader) ReadString(ref *binarychunk.BinaryReference) (string, error) {
+	if ref == nil {
+		return "", nil
+	}
+	data, err := r.Read(ref)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// Close releases the underlying file when the reader was opened with Open.
+func (r *Reader) Close() error {
+	r.chunkCacheLock.Lock()
+	r.chunkCache = map[int][]byte{}
+	r.chunkCacheLock.Unlock()
+	if r.closer == nil {
+		return nil
+	}
+	return r.closer.Close()
+}
+
+// chunk returns the decompressed binary chunk at the given index. Decompressed chunks are cached for later reads.
+func (r *Reader) chunk(index int) ([]byte, error) {
+	if index < 0 || index >= len(r.chunks) {
+		return nil, fmt.Errorf("buffer index %d is out of the range", index)
+	}
+	r.chunkCacheLock.Lock()
+	defer r.chunkCacheLock.Unlock()
+	if cached, found := r.chunkCache[index]; found {
+		return cached, nil
+	}
+	location := r.chunks[index]
+	gzipReader, err := gzip.NewReader(io.NewSectionReader(r.source, location.offset, location.size))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress binary chunk #%d\n%w", index, err)
+	}
+	defer gzipReader.Close()
+	var buf bytes.Buffer
+	if _, err := io.Copy(&buf, gzipReader); err != nil {
+		return nil, fmt.Errorf("failed to decompress binary chunk #%d\n%w", index, err)
+	}
+	r.chunkCache[index] = buf.Bytes()
+	return r.chunkCache[index], nil
+}
+
+// OpenStore opens the .khi file persisted in the given inspectiondata.Store. Callers must call Close after use.
+func OpenStore(store inspectiondata.Store) (*Reader, error) {
+	size, err := store.GetInspectionResultSizeInBytes()
+	if err != nil {
+		return nil, err
+	}
+	source, err := store.GetReader()
+	if err != nil {
+		return nil, err
+	}
+	if readerAt, ok := source.(io.ReaderAt); ok {
+		reader, err := NewReader(readerAt, int64(size))
+		if err != nil {
+			source.Close()
+			return nil, err
+		}
+		reader.closer = source
+		return reader, nil
+	}
+	// The store doesn't support random access. Read the whole data on memory instead.
+	defer source.Close()
+	data, err := io.ReadAll(source)
+	if err != nil {
+		return nil, err
+	}
+	return NewReader(bytes.NewReader(data), int64(len(data)))
+}
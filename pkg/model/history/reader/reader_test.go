@@ -0,0 +1,174 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reader
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/model/binarychunk"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/enum"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/history"
+	"github.com/GoogleCloudPlatform/khi/pkg/testutil/khifile"
+	"github.com/google/go-cmp/cmp"
+
+	_ "github.com/GoogleCloudPlatform/khi/internal/testflags"
+)
+
+func TestReader(t *testing.T) {
+	chunk := binarychunk.NewBuilder(binarychunk.NewFileSystemGzipCompressor("/tmp"), "/tmp")
+	bodyRef, err := chunk.Write([]byte("log body"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	summaryRef, err := chunk.Write([]byte("log summary"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	manifestRef, err := chunk.Write([]byte("kind: Pod"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := history.NewHistory()
+	h.Logs = append(h.Logs, &history.SerializableLog{
+		ID:        "log-1",
+		DisplayId: "log-1",
+		Timestamp: time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC),
+		Body:      bodyRef,
+		Summary:   summaryRef,
+		Type:      enum.LogTypeAudit,
+	})
+	h.Timelines = append(h.Timelines, &history.ResourceTimeline{
+		ID: "timeline-1",
+		Revisions: []*history.ResourceRevision{
+			{Log: "log-1", Verb: enum.RevisionVerbCreate, Body: manifestRef, State: enum.RevisionStateExisting},
+		},
+		Events: []*history.ResourceEvent{},
+	})
+	h.Resources = append(h.Resources, &history.Resource{
+		ResourceName:     "core/v1",
+		FullResourcePath: "core/v1",
+		Children: []*history.Resource{
+			{ResourceName: "pod", FullResourcePath: "core/v1#pod", Timeline: "timeline-1", Children: []*history.Resource{}},
+		},
+	})
+	data := khifile.MustGenerateKHIFile(h, chunk)
+
+	t.Run("parses the history JSON part", func(t *testing.T) {
+		r, err := NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(h.Logs, r.History.Logs); diff != "" {
+			t.Errorf("Logs mismatch (-want +got):\n%s", diff)
+		}
+		if r.ChunkCount() != 1 {
+			t.Errorf("ChunkCount() = %d, want 1", r.ChunkCount())
+		}
+		if r.Timeline("timeline-1") == nil {
+			t.Errorf("Timeline(timeline-1) returned nil")
+		}
+		if r.Log("log-1") == nil {
+			t.Errorf("Log(log-1) returned nil")
+		}
+	})
+
+	t.Run("resolves binary references", func(t *testing.T) {
+		r, err := NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		testCases := []struct {
+			ref  *binarychunk.BinaryReference
+			want string
+		}{
+			{ref: r.History.Logs[0].Body, want: "log body"},
+			{ref: r.History.Logs[0].Summary, want: "log summary"},
+			{ref: r.History.Timelines[0].Revisions[0].Body, want: "kind: Pod"},
+			{ref: nil, want: ""},
+		}
+		for _, tc := range testCases {
+			got, err := r.ReadString(tc.ref)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tc.want {
+				t.Errorf("ReadString() = %q, want %q", got, tc.want)
+			}
+		}
+	})
+
+	t.Run("walks resources in depth first order", func(t *testing.T) {
+		r, err := NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		paths := []string{}
+		depths := []int{}
+		err = r.WalkResources(func(resource *history.Resource, depth int) error {
+			paths = append(paths, resource.FullResourcePath)
+			depths = append(depths, depth)
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff([]string{"core/v1", "core/v1#pod"}, paths); diff != "" {
+			t.Errorf("paths mismatch (-want +got):\n%s", diff)
+		}
+		if diff := cmp.Diff([]int{0, 1}, depths); diff != "" {
+			t.Errorf("depths mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("opens a file", func(t *testing.T) {
+		filePath := filepath.Join(t.TempDir(), "test.khi")
+		if err := os.WriteFile(filePath, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+		r, err := Open(filePath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer r.Close()
+		got, err := r.ReadString(r.History.Logs[0].Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != "log body" {
+			t.Errorf("ReadString() = %q, want %q", got, "log body")
+		}
+	})
+
+	t.Run("rejects a non khi file", func(t *testing.T) {
+		invalid := []byte("FOO\x00\x00\x00\x00")
+		_, err := NewReader(bytes.NewReader(invalid), int64(len(invalid)))
+		if !errors.Is(err, ErrInvalidFormat) {
+			t.Errorf("NewReader() error = %v, want ErrInvalidFormat", err)
+		}
+	})
+
+	t.Run("rejects a truncated file", func(t *testing.T) {
+		truncated := data[:len(data)-10]
+		_, err := NewReader(bytes.NewReader(truncated), int64(len(truncated)))
+		if !errors.Is(err, ErrInvalidFormat) {
+			t.Errorf("NewReader() error = %v, want ErrInvalidFormat", err)
+		}
+	})
+}
@@ -14,7 +14,14 @@
 
 package resourcepath
 
-import "github.com/GoogleCloudPlatform/khi/pkg/model/enum"
+import (
+	"strings"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/model/enum"
+)
+
+// ClusterScopeDelimiter separates the cluster name from the API version in the root element of cluster scoped resource paths.
+const ClusterScopeDelimiter = ":"
 
 // ResourcePath contains the path representing location of a timeline in the history.
 type ResourcePath struct {
@@ -26,3 +33,24 @@ type ResourcePath struct {
 	// KHI shows various resources in a single history with mixing many types of children. It's not only like child-parent relationship, but also pseudo relationship like node-node's component relationship.
 	ParentRelationship enum.ParentRelationship
 }
+
+// ClusterScoped returns the resource path with the cluster name prepended to its root element like `cluster-a:core/v1#pod#default#nginx`.
+// The root element is reused instead of adding a new layer because the frontend expects the fixed depth of resource paths.
+// The path is returned as is when the cluster name is empty.
+func ClusterScoped(clusterName string, resourcePath ResourcePath) ResourcePath {
+	if clusterName == "" || resourcePath.Path == "" {
+		return resourcePath
+	}
+	return ResourcePath{
+		Path:               ClusterScopedPath(clusterName, resourcePath.Path),
+		ParentRelationship: resourcePath.ParentRelationship,
+	}
+}
+
+// ClusterScopedPath returns the raw resource path with the cluster name prepended to its root element. The path is returned as is when the cluster name is empty.
+func ClusterScopedPath(clusterName string, path string) string {
+	if clusterName == "" || path == "" || strings.HasPrefix(path, clusterName+ClusterScopeDelimiter) {
+		return path
+	}
+	return clusterName + ClusterScopeDelimiter + path
+}
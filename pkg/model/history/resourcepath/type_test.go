@@ -0,0 +1,60 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resourcepath
+
+import (
+	"testing"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/model/enum"
+	"github.com/google/go-cmp/cmp"
+
+	_ "github.com/GoogleCloudPlatform/khi/internal/testflags"
+)
+
+func TestClusterScoped(t *testing.T) {
+	testCases := []struct {
+		name        string
+		clusterName string
+		input       ResourcePath
+		expected    ResourcePath
+	}{
+		{
+			name:        "empty cluster name",
+			clusterName: "",
+			input:       ResourcePath{Path: "core/v1#pod#default#nginx", ParentRelationship: enum.RelationshipChild},
+			expected:    ResourcePath{Path: "core/v1#pod#default#nginx", ParentRelationship: enum.RelationshipChild},
+		},
+		{
+			name:        "cluster name specified",
+			clusterName: "cluster-a",
+			input:       ResourcePath{Path: "core/v1#pod#default#nginx", ParentRelationship: enum.RelationshipChild},
+			expected:    ResourcePath{Path: "cluster-a:core/v1#pod#default#nginx", ParentRelationship: enum.RelationshipChild},
+		},
+		{
+			name:        "already scoped",
+			clusterName: "cluster-a",
+			input:       ResourcePath{Path: "cluster-a:core/v1#pod", ParentRelationship: enum.RelationshipPodBinding},
+			expected:    ResourcePath{Path: "cluster-a:core/v1#pod", ParentRelationship: enum.RelationshipPodBinding},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := ClusterScoped(tc.clusterName, tc.input)
+			if diff := cmp.Diff(tc.expected, got); diff != "" {
+				t.Errorf("ClusterScoped() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
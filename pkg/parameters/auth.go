@@ -52,6 +52,15 @@ type AuthParameters struct {
 
 	// OAuthStateSuffix is the suffix added to the state parameter in OAuth. The state will be generated in the format of `<random-string><suffix>`.
 	OAuthStateSuffix *string
+
+	// RequireUserCredential
+	// If this flag is set, KHI won't use the credential of its own process for inspections. Inspections must be started with a credential given by the user.
+	RequireUserCredential *bool
+
+	// AllowProcessImpersonation
+	// If this flag is set, inspections can impersonate a service account with the credential of the KHI process when the user gives no access token.
+	// Any user who can start inspections can then act as any service account the KHI process can impersonate.
+	AllowProcessImpersonation *bool
 }
 
 // PostProcess implements ParameterStore.
@@ -65,6 +74,9 @@ func (a *AuthParameters) PostProcess() error {
 	if *a.OAuthClientID != "" && *a.OAuthRedirectURI == "" {
 		return fmt.Errorf("--oauth-redirect-uri must be set when --oauth-client-id is set")
 	}
+	if *a.RequireUserCredential && *a.AllowProcessImpersonation {
+		return fmt.Errorf("--allow-process-impersonation can't be set when --require-user-credential is set")
+	}
 	return nil
 }
 
@@ -79,6 +91,8 @@ func (a *AuthParameters) Prepare() error {
 	a.OAuthRedirectURI = flag.String("oauth-redirect-uri", "", "The callback URI for OAuth. This must be provided as full qualified URL.", "")
 	a.OAuthRedirectTargetServingPath = flag.String("oauth-redirect-target-serving-path", "/oauth/callback", "The path to serve the callback target.", "")
 	a.OAuthStateSuffix = flag.String("oauth-state-suffix", "", "The suffix added to the state parameter in OAuth. The state will be generated in the format of `<random-string><suffix>`.", "")
+	a.RequireUserCredential = flag.Bool("require-user-credential", false, "If this flag is set, KHI won't use the credential of its own process for inspections. Inspections must be started with an access token or OAuth.", "KHI_REQUIRE_USER_CREDENTIAL")
+	a.AllowProcessImpersonation = flag.Bool("allow-process-impersonation", false, "If this flag is set, inspections can impersonate a service account with the credential of the KHI process when no access token is given. Any user starting inspections can act as any service account KHI can impersonate.", "KHI_ALLOW_PROCESS_IMPERSONATION")
 	return nil
 }
 
@@ -52,6 +52,8 @@ func TestAuthParameters(t *testing.T) {
 				OAuthRedirectURI:               testutil.P(""),
 				OAuthRedirectTargetServingPath: testutil.P("/oauth/callback"),
 				OAuthStateSuffix:               testutil.P(""),
+				RequireUserCredential:          testutil.P(false),
+				AllowProcessImpersonation:      testutil.P(false),
 			},
 			before: func() {
 				os.Args = []string{os.Args[0]}
@@ -0,0 +1,59 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parameters
+
+import (
+	"errors"
+	"time"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/common/flag"
+)
+
+var Budget *BudgetParameters = &BudgetParameters{}
+
+// BudgetParameters is the ParameterStore for the server-wide resource budget applied to each inspection.
+// Each limit can be tightened in the request to run an inspection.
+type BudgetParameters struct {
+	// MaxLogsPerQuery is the maximum count of logs a single query task can hold. 0 means unlimited.
+	MaxLogsPerQuery *int
+	// LogCountAction is what to do when a query task exceeds MaxLogsPerQuery. `sample` keeps sampled logs and `stop` fails the inspection.
+	LogCountAction *string
+	// MaxBinaryBytes is the maximum total size of log bodies and manifests stored in an inspection result in bytes. 0 means unlimited.
+	MaxBinaryBytes *int
+	// MaxWallTime is the maximum duration of an inspection. 0 means unlimited.
+	MaxWallTime *time.Duration
+}
+
+// PostProcess implements ParameterStore.
+func (b *BudgetParameters) PostProcess() error {
+	if *b.MaxLogsPerQuery < 0 || *b.MaxBinaryBytes < 0 || *b.MaxWallTime < 0 {
+		return errors.New("`--budget-max-logs-per-query`, `--budget-max-binary-bytes` and `--budget-max-wall-time` must not be negative")
+	}
+	if *b.LogCountAction != "sample" && *b.LogCountAction != "stop" {
+		return errors.New("`--budget-log-count-action` must be `sample` or `stop`")
+	}
+	return nil
+}
+
+// Prepare implements ParameterStore.
+func (b *BudgetParameters) Prepare() error {
+	b.MaxLogsPerQuery = flag.Int("budget-max-logs-per-query", 0, "The maximum count of logs a single query task of an inspection can hold. 0 means unlimited.", "KHI_BUDGET_MAX_LOGS_PER_QUERY")
+	b.LogCountAction = flag.String("budget-log-count-action", "sample", "What to do when a query task exceeds `--budget-max-logs-per-query`. `sample` keeps sampled logs and `stop` fails the inspection.", "KHI_BUDGET_LOG_COUNT_ACTION")
+	b.MaxBinaryBytes = flag.Int("budget-max-binary-bytes", 0, "The maximum total size of log bodies and manifests stored in an inspection result in bytes. The inspection fails when it exceeds the size. 0 means unlimited.", "KHI_BUDGET_MAX_BINARY_BYTES")
+	b.MaxWallTime = flag.Duration("budget-max-wall-time", 0, "The maximum duration of an inspection. The inspection fails when it takes longer. 0 means unlimited.", "KHI_BUDGET_MAX_WALL_TIME")
+	return nil
+}
+
+var _ ParameterStore = (*BudgetParameters)(nil)
@@ -0,0 +1,102 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parameters
+
+import (
+	"flag"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/testutil"
+	"github.com/google/go-cmp/cmp"
+
+	_ "github.com/GoogleCloudPlatform/khi/internal/testflags"
+)
+
+func TestBudgetParameters(t *testing.T) {
+	testCases := []struct {
+		name    string
+		want    *BudgetParameters
+		before  func()
+		wantErr bool
+	}{
+		{
+			before: func() {
+				os.Args = []string{os.Args[0]}
+				flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+			},
+			name: "default",
+			want: &BudgetParameters{
+				MaxLogsPerQuery: testutil.P(0),
+				LogCountAction:  testutil.P("sample"),
+				MaxBinaryBytes:  testutil.P(0),
+				MaxWallTime:     testutil.P(time.Duration(0)),
+			},
+		},
+		{
+			before: func() {
+				os.Args = []string{os.Args[0], "--budget-max-logs-per-query", "100000", "--budget-log-count-action", "stop", "--budget-max-wall-time", "30m"}
+				flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+			},
+			name: "with limits",
+			want: &BudgetParameters{
+				MaxLogsPerQuery: testutil.P(100000),
+				LogCountAction:  testutil.P("stop"),
+				MaxBinaryBytes:  testutil.P(0),
+				MaxWallTime:     testutil.P(30 * time.Minute),
+			},
+		},
+		{
+			before: func() {
+				os.Args = []string{os.Args[0], "--budget-log-count-action", "drop"}
+				flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+			},
+			name:    "unknown action",
+			wantErr: true,
+		},
+		{
+			before: func() {
+				os.Args = []string{os.Args[0], "--budget-max-binary-bytes", "-1"}
+				flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+			},
+			name:    "negative size",
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			prepareFlagParsingTest(t)
+			store := &BudgetParameters{}
+			tc.before()
+			ResetStore()
+			AddStore(store)
+			err := Parse()
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("Parse() returned nil, want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.want, store); diff != "" {
+				t.Errorf("unexpected result (-want +got)\n%s", diff)
+			}
+		})
+	}
+}
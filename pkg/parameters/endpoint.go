@@ -0,0 +1,113 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parameters
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/common/flag"
+)
+
+var Endpoint *EndpointParameters = &EndpointParameters{}
+
+// EndpointParameters is the ParameterStore for the endpoints of Google APIs.
+// Endpoints not specified explicitly are derived from the universe domain.
+type EndpointParameters struct {
+	// UniverseDomain is the domain of Google APIs. (e.g. `googleapis.com`)
+	UniverseDomain *string
+	// LoggingEndpoint is the base URL of the Cloud Logging API.
+	LoggingEndpoint *string
+	// ContainerEndpoint is the base URL of the GKE API.
+	ContainerEndpoint *string
+	// GKEMultiCloudEndpoint is the template of the regional GKE Multi-Cloud API base URLs. `{location}` is replaced with each location.
+	GKEMultiCloudEndpoint *string
+	// GKEMultiCloudLocations is the comma separated list of locations queried through the GKE Multi-Cloud API.
+	GKEMultiCloudLocations *string
+	// GKEOnPremEndpoint is the base URL of the GKE On-Prem API.
+	GKEOnPremEndpoint *string
+	// GKEHubEndpoint is the base URL of the GKE Hub API.
+	GKEHubEndpoint *string
+	// ComposerEndpoint is the base URL of the Cloud Composer API.
+	ComposerEndpoint *string
+	// ComputeEndpoint is the base URL of the Compute Engine API.
+	ComputeEndpoint *string
+	// IAMCredentialsEndpoint is the base URL of the IAM Service Account Credentials API used to impersonate service accounts.
+	IAMCredentialsEndpoint *string
+	// ImpersonationScope is the OAuth scope of the access tokens issued for impersonated service accounts.
+	ImpersonationScope *string
+}
+
+// PostProcess implements ParameterStore.
+func (e *EndpointParameters) PostProcess() error {
+	if strings.Contains(*e.UniverseDomain, "/") {
+		return fmt.Errorf("--universe-domain must be a domain name but %q was given", *e.UniverseDomain)
+	}
+	endpoints := map[string]*string{
+		"logging-endpoint":        e.LoggingEndpoint,
+		"container-endpoint":      e.ContainerEndpoint,
+		"gkemulticloud-endpoint":  e.GKEMultiCloudEndpoint,
+		"gkeonprem-endpoint":      e.GKEOnPremEndpoint,
+		"gkehub-endpoint":         e.GKEHubEndpoint,
+		"composer-endpoint":       e.ComposerEndpoint,
+		"compute-endpoint":        e.ComputeEndpoint,
+		"iamcredentials-endpoint": e.IAMCredentialsEndpoint,
+	}
+	for flagName, endpoint := range endpoints {
+		if *endpoint == "" {
+			continue
+		}
+		// The placeholder is not a valid host name.
+		parsed, err := url.Parse(strings.ReplaceAll(*endpoint, "{location}", "location"))
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return fmt.Errorf("--%s must be an URL starting with http:// or https:// but %q was given", flagName, *endpoint)
+		}
+	}
+	return nil
+}
+
+// Prepare implements ParameterStore.
+func (e *EndpointParameters) Prepare() error {
+	e.UniverseDomain = flag.String("universe-domain", "googleapis.com", "The domain of Google APIs. Endpoints not specified explicitly are derived from this domain.", "GOOGLE_CLOUD_UNIVERSE_DOMAIN")
+	e.LoggingEndpoint = flag.String("logging-endpoint", "", "The base URL of the Cloud Logging API. (e.g. `https://logging-example.p.googleapis.com`)", "KHI_LOGGING_ENDPOINT")
+	e.ContainerEndpoint = flag.String("container-endpoint", "", "The base URL of the GKE API.", "KHI_CONTAINER_ENDPOINT")
+	e.GKEMultiCloudEndpoint = flag.String("gkemulticloud-endpoint", "", "The template of the regional GKE Multi-Cloud API base URLs. `{location}` is replaced with each location. (e.g. `https://{location}-gkemulticloud.googleapis.com`)", "KHI_GKEMULTICLOUD_ENDPOINT")
+	e.GKEMultiCloudLocations = flag.String("gkemulticloud-locations", "", "The comma separated list of locations queried through the GKE Multi-Cloud API. The known locations are used when this value is not specified.", "KHI_GKEMULTICLOUD_LOCATIONS")
+	e.GKEOnPremEndpoint = flag.String("gkeonprem-endpoint", "", "The base URL of the GKE On-Prem API.", "KHI_GKEONPREM_ENDPOINT")
+	e.GKEHubEndpoint = flag.String("gkehub-endpoint", "", "The base URL of the GKE Hub API.", "KHI_GKEHUB_ENDPOINT")
+	e.ComposerEndpoint = flag.String("composer-endpoint", "", "The base URL of the Cloud Composer API.", "KHI_COMPOSER_ENDPOINT")
+	e.ComputeEndpoint = flag.String("compute-endpoint", "", "The base URL of the Compute Engine API.", "KHI_COMPUTE_ENDPOINT")
+	e.IAMCredentialsEndpoint = flag.String("iamcredentials-endpoint", "", "The base URL of the IAM Service Account Credentials API used to impersonate service accounts.", "KHI_IAMCREDENTIALS_ENDPOINT")
+	e.ImpersonationScope = flag.String("impersonation-scope", "", "The OAuth scope of the access tokens issued for impersonated service accounts. `https://www.googleapis.com/auth/cloud-platform` is used when this value is not specified.", "KHI_IMPERSONATION_SCOPE")
+	return nil
+}
+
+// GKEMultiCloudLocationList returns the list of locations given in GKEMultiCloudLocations.
+func (e *EndpointParameters) GKEMultiCloudLocationList() []string {
+	if e.GKEMultiCloudLocations == nil {
+		return nil
+	}
+	locations := []string{}
+	for _, location := range strings.Split(*e.GKEMultiCloudLocations, ",") {
+		location = strings.TrimSpace(location)
+		if location != "" {
+			locations = append(locations, location)
+		}
+	}
+	return locations
+}
+
+var _ ParameterStore = (*EndpointParameters)(nil)
@@ -0,0 +1,123 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parameters
+
+import (
+	"flag"
+	"os"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/testutil"
+	"github.com/google/go-cmp/cmp"
+
+	_ "github.com/GoogleCloudPlatform/khi/internal/testflags"
+)
+
+func TestEndpointParameters(t *testing.T) {
+	testCases := []struct {
+		name    string
+		args    []string
+		want    *EndpointParameters
+		wantErr bool
+	}{
+		{
+			name: "default",
+			args: []string{},
+			want: &EndpointParameters{
+				UniverseDomain:         testutil.P("googleapis.com"),
+				LoggingEndpoint:        testutil.P(""),
+				ContainerEndpoint:      testutil.P(""),
+				GKEMultiCloudEndpoint:  testutil.P(""),
+				GKEMultiCloudLocations: testutil.P(""),
+				GKEOnPremEndpoint:      testutil.P(""),
+				GKEHubEndpoint:         testutil.P(""),
+				ComposerEndpoint:       testutil.P(""),
+				ComputeEndpoint:        testutil.P(""),
+				IAMCredentialsEndpoint: testutil.P(""),
+				ImpersonationScope:     testutil.P(""),
+			},
+		},
+		{
+			name: "with overrides",
+			args: []string{"--universe-domain", "example.com", "--logging-endpoint", "https://logging-psc.p.googleapis.com", "--gkemulticloud-endpoint", "http://localhost:8080/{location}", "--gkemulticloud-locations", "us-west1,us-east4", "--iamcredentials-endpoint", "http://localhost:8080", "--impersonation-scope", "https://www.example.com/auth/cloud-platform"},
+			want: &EndpointParameters{
+				UniverseDomain:         testutil.P("example.com"),
+				LoggingEndpoint:        testutil.P("https://logging-psc.p.googleapis.com"),
+				ContainerEndpoint:      testutil.P(""),
+				GKEMultiCloudEndpoint:  testutil.P("http://localhost:8080/{location}"),
+				GKEMultiCloudLocations: testutil.P("us-west1,us-east4"),
+				GKEOnPremEndpoint:      testutil.P(""),
+				GKEHubEndpoint:         testutil.P(""),
+				ComposerEndpoint:       testutil.P(""),
+				ComputeEndpoint:        testutil.P(""),
+				IAMCredentialsEndpoint: testutil.P("http://localhost:8080"),
+				ImpersonationScope:     testutil.P("https://www.example.com/auth/cloud-platform"),
+			},
+		},
+		{
+			name:    "endpoint without scheme",
+			args:    []string{"--container-endpoint", "container.example.com"},
+			wantErr: true,
+		},
+		{
+			name:    "universe domain with a path",
+			args:    []string{"--universe-domain", "https://example.com"},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			prepareFlagParsingTest(t)
+			os.Args = append([]string{os.Args[0]}, tc.args...)
+			flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+			store := &EndpointParameters{}
+			ResetStore()
+			AddStore(store)
+			err := Parse()
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("Parse() returned nil, want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.want, store); diff != "" {
+				t.Errorf("unexpected result (-want +got)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestGKEMultiCloudLocationList(t *testing.T) {
+	testCases := []struct {
+		input string
+		want  []string
+	}{
+		{input: "", want: []string{}},
+		{input: "us-west1", want: []string{"us-west1"}},
+		{input: " us-west1, ,us-east4 ", want: []string{"us-west1", "us-east4"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			p := &EndpointParameters{GKEMultiCloudLocations: &tc.input}
+			if diff := cmp.Diff(tc.want, p.GKEMultiCloudLocationList()); diff != "" {
+				t.Errorf("GKEMultiCloudLocationList() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -34,12 +34,23 @@ type JobParameters struct {
 	InspectionValues *string
 	// ExportDestination is the destination file path of KHI file written after the query.
 	ExportDestination *string
+	// Spec is the path of the YAML or JSON file declaring the inspection type, features, values and outputs. It replaces the other job flags.
+	Spec *string
 }
 
 // PostProcess implements ParameterStore.
 func (j *JobParameters) PostProcess() error {
+	if *j.Spec != "" {
+		if !*j.JobMode {
+			return errors.New("`--job-spec` requires `--job-mode`")
+		}
+		if *j.InspectionType != "" || *j.InspectionFeatures != "" || *j.InspectionValues != "" || *j.ExportDestination != "" {
+			return errors.New("`--job-spec` can't be used with `--job-inspection-type`, `--job-inspection-features`, `--job-inspection-values` or `--job-export-destination`")
+		}
+		return nil
+	}
 	if *j.JobMode && (*j.InspectionType == "" || *j.InspectionFeatures == "" || *j.InspectionValues == "" || *j.ExportDestination == "") {
-		return errors.New("`--job-inspection-type`, `--job-inspection-features`, `--job-inspection-values` and `--job-export-destination` are required when `--job-mode` is set")
+		return errors.New("`--job-inspection-type`, `--job-inspection-features`, `--job-inspection-values` and `--job-export-destination` are required when `--job-mode` is set without `--job-spec`")
 	}
 	return nil
 }
@@ -51,6 +62,7 @@ func (j *JobParameters) Prepare() error {
 	j.InspectionFeatures = flag.String("job-inspection-features", "", "(Job mode only)Comma separated feature list to query.", "")
 	j.InspectionValues = flag.String("job-inspection-values", "", "(Job mode only)The JSON represented parameters.", "")
 	j.ExportDestination = flag.String("job-export-destination", "", "(Job mode only)The destination file path of KHI file written after the query.", "")
+	j.Spec = flag.String("job-spec", "", "(Job mode only)The path of the YAML or JSON file declaring the inspection type, features, values and outputs. It can't be used with the other job flags.", "")
 	return nil
 }
 
@@ -27,9 +27,10 @@ import (
 
 func TestJobParameters(t *testing.T) {
 	testCases := []struct {
-		name   string
-		want   *JobParameters
-		before func()
+		name    string
+		want    *JobParameters
+		before  func()
+		wantErr bool
 	}{
 		{
 			name: "default",
@@ -39,12 +40,44 @@ func TestJobParameters(t *testing.T) {
 				InspectionFeatures: testutil.P(""),
 				InspectionValues:   testutil.P(""),
 				ExportDestination:  testutil.P(""),
+				Spec:               testutil.P(""),
 			},
 			before: func() {
 				os.Args = []string{os.Args[0]}
 				flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
 			},
 		},
+		{
+			name: "with job spec",
+			want: &JobParameters{
+				JobMode:            testutil.P(true),
+				InspectionType:     testutil.P(""),
+				InspectionFeatures: testutil.P(""),
+				InspectionValues:   testutil.P(""),
+				ExportDestination:  testutil.P(""),
+				Spec:               testutil.P("/tmp/job.yaml"),
+			},
+			before: func() {
+				os.Args = []string{os.Args[0], "--job-mode", "--job-spec", "/tmp/job.yaml"}
+				flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+			},
+		},
+		{
+			name: "job spec with the other job flags",
+			before: func() {
+				os.Args = []string{os.Args[0], "--job-mode", "--job-spec", "/tmp/job.yaml", "--job-inspection-type", "gcp-gke"}
+				flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+			},
+			wantErr: true,
+		},
+		{
+			name: "job mode without job spec nor the other job flags",
+			before: func() {
+				os.Args = []string{os.Args[0], "--job-mode"}
+				flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -55,6 +88,12 @@ func TestJobParameters(t *testing.T) {
 			ResetStore()
 			AddStore(store)
 			err := Parse()
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("Parse() returned nil, want error")
+				}
+				return
+			}
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -0,0 +1,53 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parameters
+
+import (
+	"errors"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/common/flag"
+)
+
+var QueryCache *QueryCacheParameters = &QueryCacheParameters{}
+
+// QueryCacheParameters is the ParameterStore for the on-disk cache of logs returned from Cloud Logging.
+type QueryCacheParameters struct {
+	// Folder is the folder path to store the cached logs. The concatinated path of `--data-destination-folder` and `/query-cache` is used when it's empty.
+	Folder *string
+	// MaxBytes is the maximum total size of the cached logs in bytes. The least recently used logs are removed until the total size fits. 0 disables the cache.
+	MaxBytes *int
+}
+
+// Enabled returns true when the query cache is enabled.
+func (q *QueryCacheParameters) Enabled() bool {
+	return q.MaxBytes != nil && *q.MaxBytes > 0
+}
+
+// PostProcess implements ParameterStore.
+func (q *QueryCacheParameters) PostProcess() error {
+	if *q.MaxBytes < 0 {
+		return errors.New("`--query-cache-max-bytes` must not be negative")
+	}
+	return nil
+}
+
+// Prepare implements ParameterStore.
+func (q *QueryCacheParameters) Prepare() error {
+	q.Folder = flag.String("query-cache-folder", "", "The folder path to store the cached logs returned from Cloud Logging. Use the concatinated path of `--data-destination-folder` and `/query-cache` when this value is not specified.", "KHI_QUERY_CACHE_FOLDER")
+	q.MaxBytes = flag.Int("query-cache-max-bytes", 1<<30, "The maximum total size of the cached logs returned from Cloud Logging in bytes. The least recently used logs are removed until the total size fits. 0 disables the cache.", "KHI_QUERY_CACHE_MAX_BYTES")
+	return nil
+}
+
+var _ ParameterStore = (*QueryCacheParameters)(nil)
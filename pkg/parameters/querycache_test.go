@@ -0,0 +1,95 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parameters
+
+import (
+	"flag"
+	"os"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/testutil"
+	"github.com/google/go-cmp/cmp"
+
+	_ "github.com/GoogleCloudPlatform/khi/internal/testflags"
+)
+
+func TestQueryCacheParameters(t *testing.T) {
+	testCases := []struct {
+		name        string
+		want        *QueryCacheParameters
+		wantEnabled bool
+		before      func()
+		wantErr     bool
+	}{
+		{
+			before: func() {
+				os.Args = []string{os.Args[0]}
+				flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+			},
+			name: "default",
+			want: &QueryCacheParameters{
+				Folder:   testutil.P(""),
+				MaxBytes: testutil.P(1 << 30),
+			},
+			wantEnabled: true,
+		},
+		{
+			before: func() {
+				os.Args = []string{os.Args[0], "--query-cache-folder", "/var/cache/khi", "--query-cache-max-bytes", "0"}
+				flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+			},
+			name: "disabled",
+			want: &QueryCacheParameters{
+				Folder:   testutil.P("/var/cache/khi"),
+				MaxBytes: testutil.P(0),
+			},
+			wantEnabled: false,
+		},
+		{
+			before: func() {
+				os.Args = []string{os.Args[0], "--query-cache-max-bytes", "-1"}
+				flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+			},
+			name:    "negative size",
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			prepareFlagParsingTest(t)
+			store := &QueryCacheParameters{}
+			tc.before()
+			ResetStore()
+			AddStore(store)
+			err := Parse()
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("Parse() returned nil, want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.want, store); diff != "" {
+				t.Errorf("unexpected result (-want +got)\n%s", diff)
+			}
+			if store.Enabled() != tc.wantEnabled {
+				t.Errorf("Enabled() = %v, want %v", store.Enabled(), tc.wantEnabled)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parameters
+
+import (
+	"errors"
+	"time"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/common/flag"
+)
+
+var Retention *RetentionParameters = &RetentionParameters{}
+
+// RetentionParameters is the ParameterStore for the retention of inspection results and uploaded files.
+type RetentionParameters struct {
+	// MaxAge is the maximum age of inspection results. Older results are removed. 0 means unlimited.
+	MaxAge *time.Duration
+	// MaxTotalBytes is the maximum total size of inspection results in bytes. The oldest results are removed until the total size fits. 0 means unlimited.
+	MaxTotalBytes *int
+	// MaxInspectionCount is the maximum count of inspection results. The oldest results are removed until the count fits. 0 means unlimited.
+	MaxInspectionCount *int
+	// Interval is the interval between garbage collections.
+	Interval *time.Duration
+}
+
+// PostProcess implements ParameterStore.
+func (r *RetentionParameters) PostProcess() error {
+	if *r.MaxAge < 0 || *r.MaxTotalBytes < 0 || *r.MaxInspectionCount < 0 {
+		return errors.New("`--retention-max-age`, `--retention-max-total-bytes` and `--retention-max-inspection-count` must not be negative")
+	}
+	if *r.Interval <= 0 {
+		return errors.New("`--retention-interval` must be positive")
+	}
+	return nil
+}
+
+// Prepare implements ParameterStore.
+func (r *RetentionParameters) Prepare() error {
+	r.MaxAge = flag.Duration("retention-max-age", 0, "The maximum age of inspection results. Older results are removed. 0 means unlimited.", "KHI_RETENTION_MAX_AGE")
+	r.MaxTotalBytes = flag.Int("retention-max-total-bytes", 0, "The maximum total size of inspection results in bytes. The oldest results are removed until the total size fits. 0 means unlimited.", "KHI_RETENTION_MAX_TOTAL_BYTES")
+	r.MaxInspectionCount = flag.Int("retention-max-inspection-count", 0, "The maximum count of inspection results. The oldest results are removed until the count fits. 0 means unlimited.", "KHI_RETENTION_MAX_INSPECTION_COUNT")
+	r.Interval = flag.Duration("retention-interval", 10*time.Minute, "The interval between garbage collections of inspection results and uploaded files.", "KHI_RETENTION_INTERVAL")
+	return nil
+}
+
+var _ ParameterStore = (*RetentionParameters)(nil)
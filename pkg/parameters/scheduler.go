@@ -0,0 +1,43 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parameters
+
+import "github.com/GoogleCloudPlatform/khi/pkg/common/flag"
+
+var Scheduler *SchedulerParameters = &SchedulerParameters{}
+
+// SchedulerParameters is the ParameterStore for inspections run periodically in the server.
+type SchedulerParameters struct {
+	// ScheduleFile is the path of the YAML file defining scheduled inspection jobs. Scheduling is disabled when it is empty.
+	ScheduleFile *string
+}
+
+// PostProcess implements ParameterStore.
+func (s *SchedulerParameters) PostProcess() error {
+	return nil
+}
+
+// Prepare implements ParameterStore.
+func (s *SchedulerParameters) Prepare() error {
+	s.ScheduleFile = flag.String("schedule-file", "", "The path of the YAML file defining inspection jobs run on cron schedules in the server. Scheduling is disabled when it is empty.", "KHI_SCHEDULE_FILE")
+	return nil
+}
+
+// Enabled returns true when scheduled inspection jobs are defined.
+func (s *SchedulerParameters) Enabled() bool {
+	return s.ScheduleFile != nil && *s.ScheduleFile != ""
+}
+
+var _ ParameterStore = (*SchedulerParameters)(nil)
@@ -0,0 +1,78 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parameters
+
+import (
+	"flag"
+	"os"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/testutil"
+	"github.com/google/go-cmp/cmp"
+
+	_ "github.com/GoogleCloudPlatform/khi/internal/testflags"
+)
+
+func TestSchedulerParameters(t *testing.T) {
+	testCases := []struct {
+		name        string
+		want        *SchedulerParameters
+		wantEnabled bool
+		before      func()
+	}{
+		{
+			before: func() {
+				os.Args = []string{os.Args[0]}
+				flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+			},
+			name: "default",
+			want: &SchedulerParameters{
+				ScheduleFile: testutil.P(""),
+			},
+			wantEnabled: false,
+		},
+		{
+			before: func() {
+				os.Args = []string{os.Args[0], "--schedule-file", "/etc/khi/schedule.yaml"}
+				flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+			},
+			name: "with schedule file",
+			want: &SchedulerParameters{
+				ScheduleFile: testutil.P("/etc/khi/schedule.yaml"),
+			},
+			wantEnabled: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			prepareFlagParsingTest(t)
+			store := &SchedulerParameters{}
+			tc.before()
+			ResetStore()
+			AddStore(store)
+			err := Parse()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.want, store); diff != "" {
+				t.Errorf("unexpected result (-want +got)\n%s", diff)
+			}
+			if got := store.Enabled(); got != tc.wantEnabled {
+				t.Errorf("Enabled() = %v, want %v", got, tc.wantEnabled)
+			}
+		})
+	}
+}
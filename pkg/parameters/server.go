@@ -33,6 +33,8 @@ type ServerParameters struct {
 	FrontendAssetFolder *string
 	// MaxUploadFileSizeInBytes is the maximum limit of uploaded file. Server returns 400 when the request exceeds it.
 	MaxUploadFileSizeInBytes *int
+	// MaxUploadExpandedSizeInBytes is the maximum limit of the decompressed size of an uploaded file. Server returns 413 when the uploaded file exceeds it.
+	MaxUploadExpandedSizeInBytes *int
 }
 
 // PostProcess implements ParameterStore.
@@ -54,6 +56,7 @@ func (s *ServerParameters) Prepare() error {
 	s.FrontendResourceBasePath = flag.String("frontend-resource-base-path", "", "Another base address only for frontend assets. If this value is not set, this uses `--base-path` value by default.", "KHI_FRONTEND_RESOURCE_PATH")
 	s.FrontendAssetFolder = flag.String("frontend-asset-folder", "./web", "The root folder of the assets used in frontend including index.html.", "KHI_FRONTEND_ASSET_FOLDER")
 	s.MaxUploadFileSizeInBytes = flag.Int("max-upload-file-size-in-bytes", 1024*1024*1024, "The maximum limit of uploaded file. Server returns 400 when the request exceeds it.", "")
+	s.MaxUploadExpandedSizeInBytes = flag.Int("max-upload-expanded-size-in-bytes", 16*1024*1024*1024, "The maximum limit of the decompressed size of an uploaded file. Server returns 413 when the uploaded file exceeds it.", "")
 	return nil
 }
 
@@ -38,13 +38,14 @@ func TestServerParameters(t *testing.T) {
 			},
 			name: "default",
 			want: &ServerParameters{
-				ViewerMode:               testutil.P(false),
-				Port:                     testutil.P(8080),
-				Host:                     testutil.P("localhost"),
-				BasePath:                 testutil.P("/"),
-				FrontendResourceBasePath: testutil.P("/"),
-				FrontendAssetFolder:      testutil.P("./web"),
-				MaxUploadFileSizeInBytes: testutil.P(1024 * 1024 * 1024),
+				ViewerMode:                   testutil.P(false),
+				Port:                         testutil.P(8080),
+				Host:                         testutil.P("localhost"),
+				BasePath:                     testutil.P("/"),
+				FrontendResourceBasePath:     testutil.P("/"),
+				FrontendAssetFolder:          testutil.P("./web"),
+				MaxUploadFileSizeInBytes:     testutil.P(1024 * 1024 * 1024),
+				MaxUploadExpandedSizeInBytes: testutil.P(16 * 1024 * 1024 * 1024),
 			},
 		},
 		{
@@ -54,13 +55,14 @@ func TestServerParameters(t *testing.T) {
 			},
 			name: "FrontendResourceBasePath uses BasePath when not set",
 			want: &ServerParameters{
-				ViewerMode:               testutil.P(false),
-				Port:                     testutil.P(8080),
-				Host:                     testutil.P("localhost"),
-				BasePath:                 testutil.P("/foo/bar/"),
-				FrontendResourceBasePath: testutil.P("/foo/bar/"),
-				FrontendAssetFolder:      testutil.P("./web"),
-				MaxUploadFileSizeInBytes: testutil.P(1024 * 1024 * 1024),
+				ViewerMode:                   testutil.P(false),
+				Port:                         testutil.P(8080),
+				Host:                         testutil.P("localhost"),
+				BasePath:                     testutil.P("/foo/bar/"),
+				FrontendResourceBasePath:     testutil.P("/foo/bar/"),
+				FrontendAssetFolder:          testutil.P("./web"),
+				MaxUploadFileSizeInBytes:     testutil.P(1024 * 1024 * 1024),
+				MaxUploadExpandedSizeInBytes: testutil.P(16 * 1024 * 1024 * 1024),
 			},
 		},
 		{
@@ -70,13 +72,14 @@ func TestServerParameters(t *testing.T) {
 			},
 			name: "FrontendResourceBasePath should complement the last /",
 			want: &ServerParameters{
-				ViewerMode:               testutil.P(false),
-				Port:                     testutil.P(8080),
-				Host:                     testutil.P("localhost"),
-				BasePath:                 testutil.P("/foo/bar/"),
-				FrontendResourceBasePath: testutil.P("/foo/"),
-				FrontendAssetFolder:      testutil.P("./web"),
-				MaxUploadFileSizeInBytes: testutil.P(1024 * 1024 * 1024),
+				ViewerMode:                   testutil.P(false),
+				Port:                         testutil.P(8080),
+				Host:                         testutil.P("localhost"),
+				BasePath:                     testutil.P("/foo/bar/"),
+				FrontendResourceBasePath:     testutil.P("/foo/"),
+				FrontendAssetFolder:          testutil.P("./web"),
+				MaxUploadFileSizeInBytes:     testutil.P(1024 * 1024 * 1024),
+				MaxUploadExpandedSizeInBytes: testutil.P(16 * 1024 * 1024 * 1024),
 			},
 		},
 	}
@@ -0,0 +1,141 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parameters
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/common/flag"
+)
+
+// Authentication modes of the web server.
+const (
+	ServerAuthModeNone   = "none"
+	ServerAuthModeToken  = "token"
+	ServerAuthModeHeader = "header"
+	ServerAuthModeOIDC   = "oidc"
+)
+
+var ServerAuth *ServerAuthParameters = &ServerAuthParameters{}
+
+// ServerAuthParameters is the ParameterStore for authenticating requests to the web server.
+type ServerAuthParameters struct {
+	// Mode is the authentication method of the web server. It must be one of `none`, `token`, `header` or `oidc`.
+	Mode *string
+	// Tokens is the comma separated list of `<principal>=<token>` pairs accepted as bearer tokens in the `token` mode.
+	Tokens *string
+	// UserHeader is the request header containing the principal name set by the authenticating proxy in the `header` mode.
+	UserHeader *string
+	// GroupsHeader is the request header containing the comma separated groups of the principal set by the authenticating proxy in the `header` mode.
+	GroupsHeader *string
+	// OIDCIssuer is the issuer URL of the ID tokens accepted in the `oidc` mode.
+	OIDCIssuer *string
+	// OIDCClientID is the client ID expected in the audience of the ID tokens in the `oidc` mode.
+	OIDCClientID *string
+	// OIDCUserClaim is the claim of the ID token used as the principal name in the `oidc` mode.
+	OIDCUserClaim *string
+	// OIDCGroupsClaim is the claim of the ID token used as the groups of the principal in the `oidc` mode.
+	OIDCGroupsClaim *string
+	// Admins is the comma separated list of principal names or groups allowed to access the inspections and uploads of every principal.
+	Admins *string
+	// MetricsPublic serves the metrics endpoint without authentication for scrapers not able to authenticate.
+	MetricsPublic *bool
+}
+
+// PostProcess implements ParameterStore.
+func (s *ServerAuthParameters) PostProcess() error {
+	switch *s.Mode {
+	case ServerAuthModeNone, ServerAuthModeHeader:
+	case ServerAuthModeToken:
+		if _, err := parseServerAuthTokens(*s.Tokens); err != nil {
+			return err
+		}
+	case ServerAuthModeOIDC:
+		if *s.OIDCIssuer == "" || *s.OIDCClientID == "" {
+			return fmt.Errorf("--server-auth-oidc-issuer and --server-auth-oidc-client-id must be set when --server-auth-mode is oidc")
+		}
+	default:
+		return fmt.Errorf("unsupported --server-auth-mode %q. It must be one of none, token, header or oidc", *s.Mode)
+	}
+	return nil
+}
+
+// Prepare implements ParameterStore.
+func (s *ServerAuthParameters) Prepare() error {
+	s.Mode = flag.String("server-auth-mode", ServerAuthModeNone, "The authentication method of the web server. `none`, `token` (static bearer tokens), `header` (identity headers set by an authenticating proxy) or `oidc` (OIDC ID tokens). The web UI is served only in `none` and `header` because it can't send bearer tokens. `token` and `oidc` are for API clients.", "KHI_SERVER_AUTH_MODE")
+	s.Tokens = flag.String("server-auth-tokens", "", "The comma separated list of `<principal>=<token>` pairs accepted as bearer tokens when --server-auth-mode is token.", "KHI_SERVER_AUTH_TOKENS")
+	s.UserHeader = flag.String("server-auth-user-header", "X-Forwarded-Email", "The request header containing the principal name set by the authenticating proxy when --server-auth-mode is header.", "")
+	s.GroupsHeader = flag.String("server-auth-groups-header", "X-Forwarded-Groups", "The request header containing the comma separated groups of the principal set by the authenticating proxy when --server-auth-mode is header.", "")
+	s.OIDCIssuer = flag.String("server-auth-oidc-issuer", "", "The issuer URL of the ID tokens accepted when --server-auth-mode is oidc.", "KHI_SERVER_AUTH_OIDC_ISSUER")
+	s.OIDCClientID = flag.String("server-auth-oidc-client-id", "", "The client ID expected in the audience of the ID tokens when --server-auth-mode is oidc.", "KHI_SERVER_AUTH_OIDC_CLIENT_ID")
+	s.OIDCUserClaim = flag.String("server-auth-oidc-user-claim", "email", "The claim of the ID token used as the principal name when --server-auth-mode is oidc.", "")
+	s.OIDCGroupsClaim = flag.String("server-auth-oidc-groups-claim", "groups", "The claim of the ID token used as the groups of the principal when --server-auth-mode is oidc.", "")
+	s.Admins = flag.String("server-auth-admins", "", "The comma separated list of principal names or groups allowed to access the inspections and uploads of every principal.", "KHI_SERVER_AUTH_ADMINS")
+	s.MetricsPublic = flag.Bool("metrics-public", false, "If this flag is set, the /metrics endpoint is served without authentication even when --server-auth-mode is not none. The metrics include the inspection types, task names and upload counts.", "KHI_METRICS_PUBLIC")
+	return nil
+}
+
+// Enabled returns true when the web server authenticates requests.
+func (s *ServerAuthParameters) Enabled() bool {
+	return s.Mode != nil && *s.Mode != ServerAuthModeNone
+}
+
+// APIOnly returns true when the authentication mode requires bearer tokens in the Authorization header. The bundled web UI doesn't send the header, so it is not served in these modes.
+func (s *ServerAuthParameters) APIOnly() bool {
+	return s.Mode != nil && (*s.Mode == ServerAuthModeToken || *s.Mode == ServerAuthModeOIDC)
+}
+
+// TokenMap returns the principal names keyed by the bearer tokens given in --server-auth-tokens.
+func (s *ServerAuthParameters) TokenMap() (map[string]string, error) {
+	return parseServerAuthTokens(*s.Tokens)
+}
+
+// AdminList returns the principal names or groups given in --server-auth-admins.
+func (s *ServerAuthParameters) AdminList() []string {
+	return splitCommaSeparatedList(*s.Admins)
+}
+
+func parseServerAuthTokens(tokens string) (map[string]string, error) {
+	result := map[string]string{}
+	for _, pair := range splitCommaSeparatedList(tokens) {
+		principal, token, found := strings.Cut(pair, "=")
+		principal = strings.TrimSpace(principal)
+		token = strings.TrimSpace(token)
+		if !found || principal == "" || token == "" {
+			return nil, fmt.Errorf("invalid --server-auth-tokens entry %q. It must be in the format of `<principal>=<token>`", pair)
+		}
+		if _, duplicated := result[token]; duplicated {
+			return nil, fmt.Errorf("--server-auth-tokens contains the same token for multiple principals")
+		}
+		result[token] = principal
+	}
+	if len(result) == 0 {
+		return nil, fmt.Errorf("--server-auth-tokens must be set when --server-auth-mode is token")
+	}
+	return result, nil
+}
+
+func splitCommaSeparatedList(value string) []string {
+	result := []string{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+var _ ParameterStore = (*ServerAuthParameters)(nil)
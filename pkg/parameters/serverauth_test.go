@@ -0,0 +1,136 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parameters
+
+import (
+	"flag"
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	_ "github.com/GoogleCloudPlatform/khi/internal/testflags"
+)
+
+func TestServerAuthParameters(t *testing.T) {
+	testCases := []struct {
+		name        string
+		args        []string
+		wantErr     bool
+		wantEnabled bool
+		wantAPIOnly bool
+		wantTokens  map[string]string
+		wantAdmins  []string
+		wantMetrics bool
+	}{
+		{
+			name:       "default",
+			args:       []string{},
+			wantAdmins: []string{},
+		},
+		{
+			name:        "token mode",
+			args:        []string{"--server-auth-mode", "token", "--server-auth-tokens", "alice=secret-a, bob=secret-b", "--server-auth-admins", "alice"},
+			wantEnabled: true,
+			wantAPIOnly: true,
+			wantTokens:  map[string]string{"secret-a": "alice", "secret-b": "bob"},
+			wantAdmins:  []string{"alice"},
+		},
+		{
+			name:    "token mode without tokens",
+			args:    []string{"--server-auth-mode", "token"},
+			wantErr: true,
+		},
+		{
+			name:    "token mode with a malformed token",
+			args:    []string{"--server-auth-mode", "token", "--server-auth-tokens", "secret"},
+			wantErr: true,
+		},
+		{
+			name:    "token mode with a duplicated token",
+			args:    []string{"--server-auth-mode", "token", "--server-auth-tokens", "alice=secret,bob=secret"},
+			wantErr: true,
+		},
+		{
+			name:        "header mode",
+			args:        []string{"--server-auth-mode", "header", "--server-auth-admins", "alice@example.com,khi-admins@example.com"},
+			wantEnabled: true,
+			wantAdmins:  []string{"alice@example.com", "khi-admins@example.com"},
+		},
+		{
+			name:        "header mode with public metrics",
+			args:        []string{"--server-auth-mode", "header", "--metrics-public"},
+			wantEnabled: true,
+			wantAdmins:  []string{},
+			wantMetrics: true,
+		},
+		{
+			name:    "oidc mode without issuer",
+			args:    []string{"--server-auth-mode", "oidc", "--server-auth-oidc-client-id", "khi"},
+			wantErr: true,
+		},
+		{
+			name:        "oidc mode",
+			args:        []string{"--server-auth-mode", "oidc", "--server-auth-oidc-issuer", "https://accounts.example.com", "--server-auth-oidc-client-id", "khi"},
+			wantEnabled: true,
+			wantAPIOnly: true,
+			wantAdmins:  []string{},
+		},
+		{
+			name:    "unsupported mode",
+			args:    []string{"--server-auth-mode", "basic"},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			prepareFlagParsingTest(t)
+			os.Args = append([]string{os.Args[0]}, tc.args...)
+			flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+			store := &ServerAuthParameters{}
+			ResetStore()
+			AddStore(store)
+			err := Parse()
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if tc.wantErr {
+				return
+			}
+			if got := store.Enabled(); got != tc.wantEnabled {
+				t.Errorf("Enabled() = %v, want %v", got, tc.wantEnabled)
+			}
+			if got := store.APIOnly(); got != tc.wantAPIOnly {
+				t.Errorf("APIOnly() = %v, want %v", got, tc.wantAPIOnly)
+			}
+			if tc.wantTokens != nil {
+				tokens, err := store.TokenMap()
+				if err != nil {
+					t.Fatal(err)
+				}
+				if diff := cmp.Diff(tc.wantTokens, tokens); diff != "" {
+					t.Errorf("TokenMap() mismatch (-want +got)\n%s", diff)
+				}
+			}
+			if got := *store.MetricsPublic; got != tc.wantMetrics {
+				t.Errorf("MetricsPublic = %v, want %v", got, tc.wantMetrics)
+			}
+			if diff := cmp.Diff(tc.wantAdmins, store.AdminList()); diff != "" {
+				t.Errorf("AdminList() mismatch (-want +got)\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parameters
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/common/flag"
+)
+
+var Tracing *TracingParameters = &TracingParameters{}
+
+// TracingParameters is the ParameterStore for exporting OpenTelemetry traces of inspections.
+type TracingParameters struct {
+	// OTLPEndpoint is the URL of the OTLP/HTTP endpoint to send traces to. Traces are not sent when it's empty.
+	OTLPEndpoint *string
+	// TraceFile is the path of the file to append traces in JSON. This is useful to inspect traces without any collector.
+	TraceFile *string
+}
+
+// PostProcess implements ParameterStore.
+func (t *TracingParameters) PostProcess() error {
+	if *t.OTLPEndpoint != "" {
+		endpoint, err := url.Parse(*t.OTLPEndpoint)
+		if err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+			return fmt.Errorf("`--otlp-endpoint` must be a URL starting with http:// or https://, but got %q", *t.OTLPEndpoint)
+		}
+	}
+	return nil
+}
+
+// Prepare implements ParameterStore.
+func (t *TracingParameters) Prepare() error {
+	t.OTLPEndpoint = flag.String("otlp-endpoint", "", "The URL of the OTLP/HTTP endpoint to send traces of inspections to. (e.g `http://localhost:4318/v1/traces`) Traces are not sent when this value is empty.", "KHI_OTLP_ENDPOINT")
+	t.TraceFile = flag.String("trace-file", "", "The path of the file to append traces of inspections in JSON. Traces are not written to a file when this value is empty.", "KHI_TRACE_FILE")
+	return nil
+}
+
+var _ ParameterStore = (*TracingParameters)(nil)
@@ -22,16 +22,21 @@ import (
 	"time"
 
 	"github.com/GoogleCloudPlatform/khi/pkg/common/errorreport"
+	"github.com/GoogleCloudPlatform/khi/pkg/common/khictx"
 	"github.com/GoogleCloudPlatform/khi/pkg/common/structurev2"
+	inspection_task_contextkey "github.com/GoogleCloudPlatform/khi/pkg/inspection/contextkey"
 	inspection_task_interface "github.com/GoogleCloudPlatform/khi/pkg/inspection/interface"
 	"github.com/GoogleCloudPlatform/khi/pkg/inspection/metadata/progress"
 	inspection_task "github.com/GoogleCloudPlatform/khi/pkg/inspection/task"
 	"github.com/GoogleCloudPlatform/khi/pkg/log"
+	"github.com/GoogleCloudPlatform/khi/pkg/log/logstore"
 	"github.com/GoogleCloudPlatform/khi/pkg/model/enum"
 	"github.com/GoogleCloudPlatform/khi/pkg/model/history"
-	"github.com/GoogleCloudPlatform/khi/pkg/model/history/grouper"
 	"github.com/GoogleCloudPlatform/khi/pkg/task"
 	"github.com/GoogleCloudPlatform/khi/pkg/task/taskid"
+	"github.com/GoogleCloudPlatform/khi/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/sync/errgroup"
 )
 
@@ -52,14 +57,12 @@ type Parser interface {
 	// Parser tasks are registered as a `feature task` and the description is shown on the frontend.
 	Description() string
 
-	// LogTask returns the task Id generating []*log.Log
-	LogTask() taskid.TaskReference[[]*log.Log]
+	// LogTask returns the task Id generating the logstore.Store of logs to parse.
+	// Logs are grouped with the grouper given to the store. The groups are sorted individually and parsed in parallel, then merged later.
+	LogTask() taskid.TaskReference[*logstore.Store]
 
 	// Dependencies returns the list of task Ids excluding the log task
 	Dependencies() []taskid.UntypedTaskReference
-
-	// Grouper returns LogGrouper that groups logs into multiple sets. These sets are sorted individually and parsed in parallel, then merged later.
-	Grouper() grouper.LogGrouper
 }
 
 func NewParserTaskFromParser(taskId taskid.TaskImplementationID[struct{}], parser Parser, isDefaultFeature bool, availableInspectionTypes []string, labelOpts ...task.LabelOpt) task.Task[struct{}] {
@@ -69,32 +72,26 @@ func NewParserTaskFromParser(taskId taskid.TaskImplementationID[struct{}], parse
 			return struct{}{}, nil
 		}
 		builder := task.GetTaskResult(ctx, inspection_task.BuilderGeneratorTaskID.Ref())
-		logs := task.GetTaskResult(ctx, parser.LogTask())
+		store := task.GetTaskResult(ctx, parser.LogTask())
+		logCount := store.Len()
 
-		preparedLogCount := atomic.Int32{}
-		updator := progress.NewProgressUpdator(tp, time.Second, func(tp *progress.TaskProgress) {
-			current := preparedLogCount.Load()
-			tp.Percentage = float32(current) / float32(len(logs))
-			tp.Message = fmt.Sprintf("%d/%d", current, len(logs))
-		})
-		updator.Start(ctx)
-		err := builder.PrepareParseLogs(ctx, logs, func() {
-			preparedLogCount.Add(1)
-		})
+		// Logs are read group by group from the store not to hold every log on memory.
+		groups, err := store.Group()
 		if err != nil {
 			return struct{}{}, err
 		}
-		grouper := parser.Grouper()
-		groups := grouper.Group(logs)
-		groupNames := []string{}
-		for key := range groups {
-			groupNames = append(groupNames, key)
+		if samplings, err := khictx.GetValue(ctx, inspection_task_contextkey.InspectionSampling); err == nil {
+			if sampling, found := samplings[taskId.String()]; found {
+				sampledLogCount := groups.Sample(sampling)
+				slog.InfoContext(ctx, fmt.Sprintf("Parsing %d logs sampled from %d logs", sampledLogCount, logCount))
+				logCount = sampledLogCount
+			}
 		}
+		groupNames := groups.Keys()
 		limitChannel := make(chan struct{}, PARSER_MAX_THREADS)
 		logCounterChannel := make(chan struct{})
 		currentGroup := 0
 		wg := errgroup.Group{}
-		parserStarted := false
 		threadCount := 0
 		doneThreadCount := atomic.Int32{}
 
@@ -111,11 +108,7 @@ func NewParserTaskFromParser(taskId taskid.TaskImplementationID[struct{}], parse
 						cancel()
 						return
 					case <-time.After(time.Second):
-						if !parserStarted {
-							updator.Done()
-							parserStarted = true
-						}
-						tp.Update(float32(parsedLogCount)/float32(len(logs)), fmt.Sprintf("%d lps(concurrency %d/%d)", parsedLogCount-lastLogCount, doneThreadCount.Load(), threadCount))
+						tp.Update(float32(parsedLogCount)/float32(logCount), fmt.Sprintf("%d lps(concurrency %d/%d)", parsedLogCount-lastLogCount, doneThreadCount.Load(), threadCount))
 						lastLogCount = parsedLogCount
 					}
 				}
@@ -139,11 +132,28 @@ func NewParserTaskFromParser(taskId taskid.TaskImplementationID[struct{}], parse
 				close(limitChannel)
 			default:
 				limitChannel <- struct{}{}
-				groupedLogs := groups[groupNames[currentGroup]]
+				groupName := groupNames[currentGroup]
 				threadCount += 1
-				wg.Go(func() error { // TODO: replace this with pkg/common/worker/pool
+				wg.Go(func() (err error) { // TODO: replace this with pkg/common/worker/pool
 					defer errorreport.CheckAndReportPanic()
-					err = builder.ParseLogsByGroups(ctx, groupedLogs, func(logIndex int, l *log.Log) *history.ChangeSet {
+					defer func() { <-limitChannel }()
+					ctx, span := tracing.Start(ctx, "parse log group", trace.WithAttributes(
+						attribute.String("khi.parser.name", parser.GetParserName()),
+						attribute.String("khi.parser.group", groupName),
+					))
+					defer func() {
+						tracing.EndSpan(span, err)
+					}()
+					groupedLogs, err := groups.Read(groupName)
+					if err != nil {
+						return err
+					}
+					span.SetAttributes(attribute.Int("khi.parser.log_count", len(groupedLogs)))
+					err = builder.PrepareParseLogs(ctx, groupedLogs, func() {})
+					if err != nil {
+						return err
+					}
+					return builder.ParseLogsByGroups(ctx, groupedLogs, func(logIndex int, l *log.Log) *history.ChangeSet {
 						cs := history.NewChangeSet(l)
 						err := parser.Parse(ctx, l, cs, builder)
 						logCounterChannel <- struct{}{}
@@ -161,8 +171,6 @@ func NewParserTaskFromParser(taskId taskid.TaskImplementationID[struct{}], parse
 						}
 						return cs
 					})
-					<-limitChannel
-					return err
 				})
 				currentGroup += 1
 				doneThreadCount.Add(1)
@@ -52,7 +52,7 @@ var _ PopupForm = &testPopupForm{}
 func TestPopupManager(t *testing.T) {
 	pm := NewPopupManager()
 	t.Run("GetCurrentPopup returns nil when no popup shown", func(t *testing.T) {
-		cp := pm.GetCurrentPopup()
+		cp := pm.GetCurrentPopup("")
 		if cp != nil {
 			t.Error("expected nil but something returned")
 		}
@@ -62,7 +62,7 @@ func TestPopupManager(t *testing.T) {
 		wg := sync.WaitGroup{}
 		wg.Add(1)
 		go func() {
-			popupResult, err := pm.ShowPopup(&testPopupForm{})
+			popupResult, err := pm.ShowPopup("", &testPopupForm{})
 			if err != nil {
 				t.Errorf("%s", err.Error())
 			}
@@ -72,7 +72,7 @@ func TestPopupManager(t *testing.T) {
 			wg.Done()
 		}()
 		<-time.After(time.Second)
-		cp := pm.GetCurrentPopup()
+		cp := pm.GetCurrentPopup("")
 		if diff := cmp.Diff(cp, &PopupFormRequest{
 			Title:       "foo",
 			Type:        "bar",
@@ -84,7 +84,7 @@ func TestPopupManager(t *testing.T) {
 		if cp.Id == "" {
 			t.Error("Id is empty")
 		}
-		pm.Answer(&PopupAnswerResponse{
+		pm.Answer("", &PopupAnswerResponse{
 			Id:    cp.Id,
 			Value: "ok",
 		})
@@ -94,8 +94,8 @@ func TestPopupManager(t *testing.T) {
 	t.Run("Validate returns the result obtained from the Validate method on PopupForm", func(t *testing.T) {
 		go func() {
 			<-time.After(time.Second)
-			p := pm.GetCurrentPopup()
-			result, err := pm.Validate(&PopupAnswerResponse{
+			p := pm.GetCurrentPopup("")
+			result, err := pm.Validate("", &PopupAnswerResponse{
 				Id:    p.Id,
 				Value: "ng",
 			})
@@ -106,7 +106,7 @@ func TestPopupManager(t *testing.T) {
 				t.Errorf("expected answer for test popup must contain ok but got %s", result.ValidationError)
 			}
 
-			result, err = pm.Validate(&PopupAnswerResponse{
+			result, err = pm.Validate("", &PopupAnswerResponse{
 				Id:    p.Id,
 				Value: "ok",
 			})
@@ -117,12 +117,12 @@ func TestPopupManager(t *testing.T) {
 				t.Errorf("expected empty but got %s", result.ValidationError)
 			}
 
-			pm.Answer(&PopupAnswerResponse{
+			pm.Answer("", &PopupAnswerResponse{
 				Id:    p.Id,
 				Value: "ok",
 			})
 		}()
-		result, err := pm.ShowPopup(&testPopupForm{})
+		result, err := pm.ShowPopup("", &testPopupForm{})
 		if err != nil {
 			t.Errorf("expected nil but got %s", err.Error())
 		}
@@ -134,20 +134,20 @@ func TestPopupManager(t *testing.T) {
 	t.Run("Validate returns an error when it got request for non current popup", func(t *testing.T) {
 		go func() {
 			<-time.After(time.Second)
-			p := pm.GetCurrentPopup()
-			_, err := pm.Validate(&PopupAnswerResponse{
+			p := pm.GetCurrentPopup("")
+			_, err := pm.Validate("", &PopupAnswerResponse{
 				Id:    "foo",
 				Value: "ok",
 			})
 			if err != CurrentPopupIsntMatchingWithGivenId {
 				t.Errorf("%s", err.Error())
 			}
-			pm.Answer(&PopupAnswerResponse{
+			pm.Answer("", &PopupAnswerResponse{
 				Id:    p.Id,
 				Value: "ok",
 			})
 		}()
-		result, err := pm.ShowPopup(&testPopupForm{})
+		result, err := pm.ShowPopup("", &testPopupForm{})
 		if err != nil {
 			t.Errorf("expected nil but got %s", err.Error())
 		}
@@ -159,20 +159,20 @@ func TestPopupManager(t *testing.T) {
 	t.Run("Answer returns an error when it got a request for non current popup", func(t *testing.T) {
 		go func() {
 			<-time.After(time.Second)
-			p := pm.GetCurrentPopup()
-			err := pm.Answer(&PopupAnswerResponse{
+			p := pm.GetCurrentPopup("")
+			err := pm.Answer("", &PopupAnswerResponse{
 				Id:    "foo",
 				Value: "ok",
 			})
 			if err != CurrentPopupIsntMatchingWithGivenId {
 				t.Errorf("expected %s but got %s", CurrentPopupIsntMatchingWithGivenId, err)
 			}
-			pm.Answer(&PopupAnswerResponse{
+			pm.Answer("", &PopupAnswerResponse{
 				Id:    p.Id,
 				Value: "ok",
 			})
 		}()
-		result, err := pm.ShowPopup(&testPopupForm{})
+		result, err := pm.ShowPopup("", &testPopupForm{})
 		if err != nil {
 			t.Errorf("expected nil but got %s", err.Error())
 		}
@@ -181,3 +181,33 @@ func TestPopupManager(t *testing.T) {
 		}
 	})
 }
+
+func TestPopupManagerIsolatesOwners(t *testing.T) {
+	pm := NewPopupManager()
+	done := make(chan struct{})
+	go func() {
+		result, err := pm.ShowPopup("alice", &testPopupForm{})
+		if err != nil {
+			t.Errorf("unexpected error %v", err)
+		}
+		if result != "ok" {
+			t.Errorf("expected ok but got %s", result)
+		}
+		close(done)
+	}()
+	<-time.After(time.Second)
+	if cp := pm.GetCurrentPopup("bob"); cp != nil {
+		t.Errorf("the popup shown to alice was returned for bob: %v", cp)
+	}
+	cp := pm.GetCurrentPopup("alice")
+	if cp == nil {
+		t.Fatal("the popup shown to alice was not returned")
+	}
+	if err := pm.Answer("bob", &PopupAnswerResponse{Id: cp.Id, Value: "ok"}); err != NoCurrentPopup {
+		t.Errorf("expected %v but got %v", NoCurrentPopup, err)
+	}
+	if err := pm.Answer("alice", &PopupAnswerResponse{Id: cp.Id, Value: "ok"}); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+	<-done
+}
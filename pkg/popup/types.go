@@ -71,7 +71,14 @@ type PopupAnswerValidationResult struct {
 }
 
 // PopupManager receives questions shown to user from frontend.
+// Popups are managed per owner not to show a popup requested for an inspection to the other users. The owner is an empty string when the server has no authentication.
 type PopupManager struct {
+	sessionsLock sync.Mutex
+	sessions     map[string]*popupSession
+}
+
+// popupSession holds the popup currently shown to an owner.
+type popupSession struct {
 	newPopupLock        sync.Mutex
 	popupWaiter         sync.WaitGroup
 	popupResult         string
@@ -81,22 +88,32 @@ type PopupManager struct {
 
 func NewPopupManager() *PopupManager {
 	return &PopupManager{
-		newPopupLock:        sync.Mutex{},
-		popupWaiter:         sync.WaitGroup{},
-		popupResult:         "",
-		currentPopupRequest: nil,
-		currentPopup:        nil,
+		sessionsLock: sync.Mutex{},
+		sessions:     map[string]*popupSession{},
+	}
+}
+
+// session returns the popup session of the owner. It creates the session when the owner has never seen a popup.
+func (p *PopupManager) session(owner string) *popupSession {
+	p.sessionsLock.Lock()
+	defer p.sessionsLock.Unlock()
+	session, found := p.sessions[owner]
+	if !found {
+		session = &popupSession{}
+		p.sessions[owner] = session
 	}
+	return session
 }
 
-// ShowPopup shows the popup UI on frontend side and wait until receiving the input.
-func (p *PopupManager) ShowPopup(popup PopupForm) (string, error) {
+// ShowPopup shows the popup UI on frontend side of the given owner and wait until receiving the input.
+func (p *PopupManager) ShowPopup(owner string, popup PopupForm) (string, error) {
 	id := common.NewUUID()
 	metadata := popup.GetMetadata()
-	p.newPopupLock.Lock()
-	defer p.newPopupLock.Unlock()
-	p.currentPopup = popup
-	p.currentPopupRequest = &PopupFormRequest{
+	session := p.session(owner)
+	session.newPopupLock.Lock()
+	defer session.newPopupLock.Unlock()
+	session.currentPopup = popup
+	session.currentPopupRequest = &PopupFormRequest{
 		Id:          id,
 		Title:       metadata.Title,
 		Type:        metadata.Type,
@@ -104,43 +121,45 @@ func (p *PopupManager) ShowPopup(popup PopupForm) (string, error) {
 		Placeholder: metadata.Placeholder,
 		Options:     metadata.Options,
 	}
-	p.popupWaiter = sync.WaitGroup{}
-	p.popupWaiter.Add(1)
-	p.popupWaiter.Wait()
-	return p.popupResult, nil
+	session.popupWaiter = sync.WaitGroup{}
+	session.popupWaiter.Add(1)
+	session.popupWaiter.Wait()
+	return session.popupResult, nil
 }
 
-// GetCurrentPopup returns currently active popup request data needed in frontend side to show the popup
-func (p *PopupManager) GetCurrentPopup() *PopupFormRequest {
-	return p.currentPopupRequest
+// GetCurrentPopup returns currently active popup request data for the owner needed in frontend side to show the popup
+func (p *PopupManager) GetCurrentPopup(owner string) *PopupFormRequest {
+	return p.session(owner).currentPopupRequest
 }
 
 // Validate receives form input and check if the request is valid to receive. If it was not valid, it returns validation error in string.
-func (p *PopupManager) Validate(request *PopupAnswerResponse) (*PopupAnswerValidationResult, error) {
-	if p.currentPopupRequest == nil {
+func (p *PopupManager) Validate(owner string, request *PopupAnswerResponse) (*PopupAnswerValidationResult, error) {
+	session := p.session(owner)
+	if session.currentPopupRequest == nil {
 		return nil, NoCurrentPopup
 	}
-	if p.currentPopupRequest.Id != request.Id {
+	if session.currentPopupRequest.Id != request.Id {
 		return nil, CurrentPopupIsntMatchingWithGivenId
 	}
 	return &PopupAnswerValidationResult{
 		Id:              request.Id,
-		ValidationError: p.currentPopup.Validate(request),
+		ValidationError: session.currentPopup.Validate(request),
 	}, nil
 }
 
 // Answer determine the result of the form. This method assume the request is already validated before.
-func (p *PopupManager) Answer(request *PopupAnswerResponse) error {
-	if p.currentPopupRequest == nil {
+func (p *PopupManager) Answer(owner string, request *PopupAnswerResponse) error {
+	session := p.session(owner)
+	if session.currentPopupRequest == nil {
 		return NoCurrentPopup
 	}
-	if p.currentPopupRequest.Id != request.Id {
+	if session.currentPopupRequest.Id != request.Id {
 		return CurrentPopupIsntMatchingWithGivenId
 	}
-	p.popupResult = request.Value
-	p.currentPopup = nil
-	p.currentPopupRequest = nil
-	p.popupWaiter.Done()
+	session.popupResult = request.Value
+	session.currentPopup = nil
+	session.currentPopupRequest = nil
+	session.popupWaiter.Done()
 	return nil
 }
 
@@ -0,0 +1,136 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/parameters"
+	"github.com/gin-gonic/gin"
+)
+
+// ErrUnauthenticated is returned when a request has no valid credential.
+var ErrUnauthenticated = errors.New("unauthenticated")
+
+// principalContextKey is the key of the gin context to store the Principal of the request.
+const principalContextKey = "khi.google.com/server/principal"
+
+// Principal is the authenticated user calling the KHI server.
+type Principal struct {
+	// Name identifies the principal. Inspections and uploads are owned by this name.
+	Name string
+	// Groups is the list of groups the principal belongs to.
+	Groups []string
+	// Admin is true when the principal can access the resources of every principal.
+	Admin bool
+}
+
+// CanAccess returns true when the principal can access a resource owned by the given owner.
+// Resources without an owner, such as inspections run by the scheduler, are shared with every principal.
+// A nil principal can access everything because it is used when the server has no authentication.
+func (p *Principal) CanAccess(owner string) bool {
+	if p == nil || p.Admin || owner == "" {
+		return true
+	}
+	return p.Name == owner
+}
+
+// OwnerName returns the owner name given to the resources created by the principal. It returns an empty string for a nil principal.
+func (p *Principal) OwnerName() string {
+	if p == nil {
+		return ""
+	}
+	return p.Name
+}
+
+// Authenticator verifies the credential of a request.
+type Authenticator interface {
+	// Authenticate returns the principal of the request. The error wraps ErrUnauthenticated when the request has no valid credential.
+	Authenticate(req *http.Request) (*Principal, error)
+}
+
+// Middleware returns a gin middleware rejecting requests failed to be authenticated with 401.
+// Principals whose name or one of the groups is in admins are marked as admin.
+func Middleware(authenticator Authenticator, admins []string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		principal, err := authenticator.Authenticate(ctx.Request)
+		if err != nil {
+			if errors.Is(err, ErrUnauthenticated) {
+				ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+				return
+			}
+			slog.ErrorContext(ctx, fmt.Sprintf("failed to authenticate the request\n%v", err))
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "failed to authenticate the request"})
+			return
+		}
+		principal.Admin = slices.Contains(admins, principal.Name) || slices.ContainsFunc(principal.Groups, func(group string) bool {
+			return slices.Contains(admins, group)
+		})
+		ctx.Set(principalContextKey, principal)
+		ctx.Next()
+	}
+}
+
+// PrincipalFromContext returns the principal of the request authenticated by the middleware. It returns nil when the server has no authentication.
+func PrincipalFromContext(ctx *gin.Context) *Principal {
+	value, found := ctx.Get(principalContextKey)
+	if !found {
+		return nil
+	}
+	principal, ok := value.(*Principal)
+	if !ok {
+		return nil
+	}
+	return principal
+}
+
+// NewAuthenticatorFromParameters returns the Authenticator configured with the server auth parameters. It returns nil when the authentication is disabled.
+func NewAuthenticatorFromParameters() (Authenticator, error) {
+	if !parameters.ServerAuth.Enabled() {
+		return nil, nil
+	}
+	switch *parameters.ServerAuth.Mode {
+	case parameters.ServerAuthModeToken:
+		tokens, err := parameters.ServerAuth.TokenMap()
+		if err != nil {
+			return nil, err
+		}
+		return NewStaticTokenAuthenticator(tokens), nil
+	case parameters.ServerAuthModeHeader:
+		return NewHeaderAuthenticator(*parameters.ServerAuth.UserHeader, *parameters.ServerAuth.GroupsHeader), nil
+	case parameters.ServerAuthModeOIDC:
+		return NewOIDCAuthenticator(*parameters.ServerAuth.OIDCIssuer, *parameters.ServerAuth.OIDCClientID, *parameters.ServerAuth.OIDCUserClaim, *parameters.ServerAuth.OIDCGroupsClaim), nil
+	default:
+		return nil, fmt.Errorf("unsupported server auth mode %q", *parameters.ServerAuth.Mode)
+	}
+}
+
+// bearerToken returns the token in the Authorization header of the request.
+func bearerToken(req *http.Request) (string, error) {
+	authorization := req.Header.Get("Authorization")
+	if authorization == "" {
+		return "", fmt.Errorf("%w: the Authorization header is missing", ErrUnauthenticated)
+	}
+	scheme, token, found := strings.Cut(authorization, " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") || strings.TrimSpace(token) == "" {
+		return "", fmt.Errorf("%w: the Authorization header must be a bearer token", ErrUnauthenticated)
+	}
+	return strings.TrimSpace(token), nil
+}
@@ -0,0 +1,144 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/go-cmp/cmp"
+
+	_ "github.com/GoogleCloudPlatform/khi/internal/testflags"
+)
+
+func TestPrincipalCanAccess(t *testing.T) {
+	testCases := []struct {
+		name      string
+		principal *Principal
+		owner     string
+		want      bool
+	}{
+		{name: "no authentication", principal: nil, owner: "alice", want: true},
+		{name: "owner", principal: &Principal{Name: "alice"}, owner: "alice", want: true},
+		{name: "another principal", principal: &Principal{Name: "bob"}, owner: "alice", want: false},
+		{name: "admin", principal: &Principal{Name: "bob", Admin: true}, owner: "alice", want: true},
+		{name: "shared resource", principal: &Principal{Name: "bob"}, owner: "", want: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.principal.CanAccess(tc.owner); got != tc.want {
+				t.Errorf("CanAccess(%q) = %v, want %v", tc.owner, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	testCases := []struct {
+		name          string
+		authorization string
+		wantCode      int
+		wantPrincipal *Principal
+	}{
+		{
+			name:     "without token",
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:          "with an invalid token",
+			authorization: "Bearer wrong",
+			wantCode:      http.StatusUnauthorized,
+		},
+		{
+			name:          "with a token of a principal",
+			authorization: "Bearer secret-b",
+			wantCode:      http.StatusOK,
+			wantPrincipal: &Principal{Name: "bob", Groups: []string{}},
+		},
+		{
+			name:          "with a token of an admin",
+			authorization: "bearer secret-a",
+			wantCode:      http.StatusOK,
+			wantPrincipal: &Principal{Name: "alice", Groups: []string{}, Admin: true},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var gotPrincipal *Principal
+			engine := gin.New()
+			engine.Use(Middleware(NewStaticTokenAuthenticator(map[string]string{"secret-a": "alice", "secret-b": "bob"}), []string{"alice"}))
+			engine.GET("/", func(ctx *gin.Context) {
+				gotPrincipal = PrincipalFromContext(ctx)
+				ctx.String(http.StatusOK, "ok")
+			})
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tc.authorization != "" {
+				req.Header.Set("Authorization", tc.authorization)
+			}
+			recorder := httptest.NewRecorder()
+			engine.ServeHTTP(recorder, req)
+			if recorder.Code != tc.wantCode {
+				t.Errorf("got status %d, want %d", recorder.Code, tc.wantCode)
+			}
+			if diff := cmp.Diff(tc.wantPrincipal, gotPrincipal); diff != "" {
+				t.Errorf("principal mismatch (-want +got)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestHeaderAuthenticator(t *testing.T) {
+	testCases := []struct {
+		name          string
+		headers       map[string]string
+		wantErr       bool
+		wantPrincipal *Principal
+	}{
+		{
+			name:    "without the user header",
+			headers: map[string]string{"X-Forwarded-Groups": "admins"},
+			wantErr: true,
+		},
+		{
+			name:          "with user and groups",
+			headers:       map[string]string{"X-Forwarded-Email": "alice@example.com", "X-Forwarded-Groups": "admins, devs"},
+			wantPrincipal: &Principal{Name: "alice@example.com", Groups: []string{"admins", "devs"}},
+		},
+		{
+			name:          "with the identity set by Identity-Aware Proxy",
+			headers:       map[string]string{"X-Forwarded-Email": "accounts.google.com:alice@example.com"},
+			wantPrincipal: &Principal{Name: "alice@example.com", Groups: []string{}},
+		},
+	}
+	authenticator := NewHeaderAuthenticator("X-Forwarded-Email", "X-Forwarded-Groups")
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			for key, value := range tc.headers {
+				req.Header.Set(key, value)
+			}
+			got, err := authenticator.Authenticate(req)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Authenticate() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.wantPrincipal, got); diff != "" {
+				t.Errorf("Authenticate() mismatch (-want +got)\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// identityAwareProxyPrefix is the prefix of identities in the headers set by Identity-Aware Proxy.
+const identityAwareProxyPrefix = "accounts.google.com:"
+
+// HeaderAuthenticator trusts the identity headers set by an authenticating proxy in front of KHI.
+// KHI must not be reachable without passing the proxy because any client can set these headers.
+type HeaderAuthenticator struct {
+	userHeader   string
+	groupsHeader string
+}
+
+var _ Authenticator = (*HeaderAuthenticator)(nil)
+
+// NewHeaderAuthenticator returns a HeaderAuthenticator reading the principal name and the comma separated groups from the given headers.
+// The groups are not read when groupsHeader is empty.
+func NewHeaderAuthenticator(userHeader string, groupsHeader string) *HeaderAuthenticator {
+	return &HeaderAuthenticator{
+		userHeader:   userHeader,
+		groupsHeader: groupsHeader,
+	}
+}
+
+// Authenticate implements Authenticator.
+func (h *HeaderAuthenticator) Authenticate(req *http.Request) (*Principal, error) {
+	name := strings.TrimPrefix(strings.TrimSpace(req.Header.Get(h.userHeader)), identityAwareProxyPrefix)
+	if name == "" {
+		return nil, fmt.Errorf("%w: the %s header is missing", ErrUnauthenticated, h.userHeader)
+	}
+	groups := []string{}
+	if h.groupsHeader != "" {
+		for _, group := range strings.Split(req.Header.Get(h.groupsHeader), ",") {
+			if group = strings.TrimSpace(group); group != "" {
+				groups = append(groups, group)
+			}
+		}
+	}
+	return &Principal{Name: name, Groups: groups}, nil
+}
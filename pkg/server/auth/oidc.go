@@ -0,0 +1,246 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// jwksRefreshInterval is the minimum interval to fetch the signing keys again when a token signed with an unknown key is received.
+const jwksRefreshInterval = time.Minute
+
+// OIDCAuthenticator authenticates requests with OIDC ID tokens given as bearer tokens.
+// The signing keys are discovered from the issuer and only RS256 signed tokens are accepted.
+type OIDCAuthenticator struct {
+	issuer      string
+	clientID    string
+	userClaim   string
+	groupsClaim string
+	httpClient  *http.Client
+	// now returns the current time. It is replaced in tests.
+	now func() time.Time
+
+	keysLock      sync.Mutex
+	keys          map[string]*rsa.PublicKey
+	keysFetchedAt time.Time
+}
+
+var _ Authenticator = (*OIDCAuthenticator)(nil)
+
+// NewOIDCAuthenticator returns an OIDCAuthenticator accepting ID tokens issued by the issuer for the client ID.
+// The principal name and the groups are read from userClaim and groupsClaim in the token.
+func NewOIDCAuthenticator(issuer string, clientID string, userClaim string, groupsClaim string) *OIDCAuthenticator {
+	return &OIDCAuthenticator{
+		issuer:      strings.TrimSuffix(issuer, "/"),
+		clientID:    clientID,
+		userClaim:   userClaim,
+		groupsClaim: groupsClaim,
+		httpClient:  &http.Client{Timeout: 10 * time.Second},
+		now:         time.Now,
+		keys:        map[string]*rsa.PublicKey{},
+	}
+}
+
+type idTokenHeader struct {
+	Algorithm string `json:"alg"`
+	KeyID     string `json:"kid"`
+}
+
+// Authenticate implements Authenticator.
+func (o *OIDCAuthenticator) Authenticate(req *http.Request) (*Principal, error) {
+	token, err := bearerToken(req)
+	if err != nil {
+		return nil, err
+	}
+	claims, err := o.verify(token)
+	if err != nil {
+		return nil, err
+	}
+	name, _ := claims[o.userClaim].(string)
+	if name == "" {
+		return nil, fmt.Errorf("%w: the ID token doesn't have the %s claim", ErrUnauthenticated, o.userClaim)
+	}
+	groups := []string{}
+	if groupsAny, ok := claims[o.groupsClaim].([]any); ok {
+		for _, groupAny := range groupsAny {
+			if group, ok := groupAny.(string); ok {
+				groups = append(groups, group)
+			}
+		}
+	}
+	return &Principal{Name: name, Groups: groups}, nil
+}
+
+// verify checks the signature and the standard claims of the ID token and returns its claims.
+func (o *OIDCAuthenticator) verify(token string) (map[string]any, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: the bearer token is not a JWT", ErrUnauthenticated)
+	}
+	var header idTokenHeader
+	if err := decodeJWTSegment(parts[0], &header); err != nil {
+		return nil, err
+	}
+	if header.Algorithm != "RS256" {
+		return nil, fmt.Errorf("%w: unsupported signing algorithm %q", ErrUnauthenticated, header.Algorithm)
+	}
+	key, err := o.signingKey(header.KeyID)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed signature", ErrUnauthenticated)
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature); err != nil {
+		return nil, fmt.Errorf("%w: invalid signature", ErrUnauthenticated)
+	}
+
+	var claims map[string]any
+	if err := decodeJWTSegment(parts[1], &claims); err != nil {
+		return nil, err
+	}
+	if issuer, _ := claims["iss"].(string); strings.TrimSuffix(issuer, "/") != o.issuer {
+		return nil, fmt.Errorf("%w: unexpected issuer %q", ErrUnauthenticated, issuer)
+	}
+	if !audienceContains(claims["aud"], o.clientID) {
+		return nil, fmt.Errorf("%w: the ID token is not issued for this client", ErrUnauthenticated)
+	}
+	expiry, ok := claims["exp"].(float64)
+	if !ok || o.now().Unix() >= int64(expiry) {
+		return nil, fmt.Errorf("%w: the ID token is expired", ErrUnauthenticated)
+	}
+	return claims, nil
+}
+
+// signingKey returns the public key with the key ID. The keys are fetched again when the key ID is unknown.
+func (o *OIDCAuthenticator) signingKey(keyID string) (*rsa.PublicKey, error) {
+	o.keysLock.Lock()
+	defer o.keysLock.Unlock()
+	if key, found := o.keys[keyID]; found {
+		return key, nil
+	}
+	if !o.keysFetchedAt.IsZero() && o.now().Sub(o.keysFetchedAt) < jwksRefreshInterval {
+		return nil, fmt.Errorf("%w: unknown signing key %q", ErrUnauthenticated, keyID)
+	}
+	keys, err := o.fetchKeys()
+	if err != nil {
+		return nil, err
+	}
+	o.keys = keys
+	o.keysFetchedAt = o.now()
+	if key, found := o.keys[keyID]; found {
+		return key, nil
+	}
+	return nil, fmt.Errorf("%w: unknown signing key %q", ErrUnauthenticated, keyID)
+}
+
+type openIDConfiguration struct {
+	JWKSURI string `json:"jwks_uri"`
+}
+
+type jsonWebKeySet struct {
+	Keys []jsonWebKey `json:"keys"`
+}
+
+type jsonWebKey struct {
+	KeyType string `json:"kty"`
+	KeyID   string `json:"kid"`
+	N       string `json:"n"`
+	E       string `json:"e"`
+}
+
+// fetchKeys reads the RSA public keys from the JWKS endpoint found in the discovery document of the issuer.
+func (o *OIDCAuthenticator) fetchKeys() (map[string]*rsa.PublicKey, error) {
+	var configuration openIDConfiguration
+	if err := o.getJSON(o.issuer+"/.well-known/openid-configuration", &configuration); err != nil {
+		return nil, fmt.Errorf("failed to read the OpenID configuration of %s\n%w", o.issuer, err)
+	}
+	if configuration.JWKSURI == "" {
+		return nil, fmt.Errorf("the OpenID configuration of %s has no jwks_uri", o.issuer)
+	}
+	var keySet jsonWebKeySet
+	if err := o.getJSON(configuration.JWKSURI, &keySet); err != nil {
+		return nil, fmt.Errorf("failed to read the signing keys of %s\n%w", o.issuer, err)
+	}
+	keys := map[string]*rsa.PublicKey{}
+	for _, key := range keySet.Keys {
+		if key.KeyType != "RSA" {
+			continue
+		}
+		modulus, err := base64.RawURLEncoding.DecodeString(key.N)
+		if err != nil {
+			return nil, fmt.Errorf("malformed modulus of the key %s\n%w", key.KeyID, err)
+		}
+		exponent, err := base64.RawURLEncoding.DecodeString(key.E)
+		if err != nil {
+			return nil, fmt.Errorf("malformed exponent of the key %s\n%w", key.KeyID, err)
+		}
+		keys[key.KeyID] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(modulus),
+			E: int(new(big.Int).SetBytes(exponent).Int64()),
+		}
+	}
+	return keys, nil
+}
+
+func (o *OIDCAuthenticator) getJSON(url string, dest any) error {
+	resp, err := o.httpClient.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned the status %d", url, resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(dest)
+}
+
+func decodeJWTSegment(segment string, dest any) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return fmt.Errorf("%w: malformed JWT segment", ErrUnauthenticated)
+	}
+	if err := json.Unmarshal(data, dest); err != nil {
+		return fmt.Errorf("%w: malformed JWT segment", ErrUnauthenticated)
+	}
+	return nil
+}
+
+// audienceContains returns true when the aud claim, a string or a list of strings, contains the client ID.
+func audienceContains(audience any, clientID string) bool {
+	switch aud := audience.(type) {
+	case string:
+		return aud == clientID
+	case []any:
+		for _, item := range aud {
+			if item == clientID {
+				return true
+			}
+		}
+	}
+	return false
+}
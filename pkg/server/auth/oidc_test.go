@@ -0,0 +1,186 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	_ "github.com/GoogleCloudPlatform/khi/internal/testflags"
+)
+
+func newTestIssuer(t *testing.T, key *rsa.PrivateKey) *httptest.Server {
+	t.Helper()
+	var server *httptest.Server
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{"issuer": server.URL, "jwks_uri": server.URL + "/jwks"})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{
+			"keys": []map[string]string{
+				{
+					"kty": "RSA",
+					"kid": "test-key",
+					"n":   base64.RawURLEncoding.EncodeToString(key.PublicKey.N.Bytes()),
+					"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.PublicKey.E)).Bytes()),
+				},
+			},
+		})
+	})
+	server = httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func signTestToken(t *testing.T, key *rsa.PrivateKey, keyID string, claims map[string]any) string {
+	t.Helper()
+	encode := func(value any) string {
+		data, err := json.Marshal(value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return base64.RawURLEncoding.EncodeToString(data)
+	}
+	signingInput := encode(map[string]string{"alg": "RS256", "kid": keyID}) + "." + encode(claims)
+	digest := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func TestOIDCAuthenticator(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	anotherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	issuer := newTestIssuer(t, key)
+	now := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+	validClaims := func() map[string]any {
+		return map[string]any{
+			"iss":    issuer.URL,
+			"aud":    "khi",
+			"exp":    now.Add(time.Hour).Unix(),
+			"email":  "alice@example.com",
+			"groups": []string{"admins"},
+		}
+	}
+
+	testCases := []struct {
+		name          string
+		token         func() string
+		wantErr       bool
+		wantPrincipal *Principal
+	}{
+		{
+			name: "valid token",
+			token: func() string {
+				return signTestToken(t, key, "test-key", validClaims())
+			},
+			wantPrincipal: &Principal{Name: "alice@example.com", Groups: []string{"admins"}},
+		},
+		{
+			name: "valid token with multiple audiences",
+			token: func() string {
+				claims := validClaims()
+				claims["aud"] = []string{"another-client", "khi"}
+				return signTestToken(t, key, "test-key", claims)
+			},
+			wantPrincipal: &Principal{Name: "alice@example.com", Groups: []string{"admins"}},
+		},
+		{
+			name: "expired token",
+			token: func() string {
+				claims := validClaims()
+				claims["exp"] = now.Add(-time.Minute).Unix()
+				return signTestToken(t, key, "test-key", claims)
+			},
+			wantErr: true,
+		},
+		{
+			name: "token for another client",
+			token: func() string {
+				claims := validClaims()
+				claims["aud"] = "another-client"
+				return signTestToken(t, key, "test-key", claims)
+			},
+			wantErr: true,
+		},
+		{
+			name: "token from another issuer",
+			token: func() string {
+				claims := validClaims()
+				claims["iss"] = "https://attacker.example.com"
+				return signTestToken(t, key, "test-key", claims)
+			},
+			wantErr: true,
+		},
+		{
+			name: "token signed with another key",
+			token: func() string {
+				return signTestToken(t, anotherKey, "test-key", validClaims())
+			},
+			wantErr: true,
+		},
+		{
+			name: "token without the user claim",
+			token: func() string {
+				claims := validClaims()
+				delete(claims, "email")
+				return signTestToken(t, key, "test-key", claims)
+			},
+			wantErr: true,
+		},
+		{
+			name: "not a JWT",
+			token: func() string {
+				return "not-a-jwt"
+			},
+			wantErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			authenticator := NewOIDCAuthenticator(issuer.URL, "khi", "email", "groups")
+			authenticator.now = func() time.Time { return now }
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("Authorization", "Bearer "+tc.token())
+			got, err := authenticator.Authenticate(req)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Authenticate() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.wantPrincipal, got); diff != "" {
+				t.Errorf("Authenticate() mismatch (-want +got)\n%s", diff)
+			}
+		})
+	}
+}
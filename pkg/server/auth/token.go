@@ -0,0 +1,55 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+)
+
+// StaticTokenAuthenticator authenticates requests with static bearer tokens given in the configuration.
+type StaticTokenAuthenticator struct {
+	// tokens is the map of principal names keyed by the tokens.
+	tokens map[string]string
+}
+
+var _ Authenticator = (*StaticTokenAuthenticator)(nil)
+
+// NewStaticTokenAuthenticator returns a StaticTokenAuthenticator accepting the given tokens. The map keys are tokens and the values are the principal names.
+func NewStaticTokenAuthenticator(tokens map[string]string) *StaticTokenAuthenticator {
+	return &StaticTokenAuthenticator{
+		tokens: tokens,
+	}
+}
+
+// Authenticate implements Authenticator.
+func (s *StaticTokenAuthenticator) Authenticate(req *http.Request) (*Principal, error) {
+	token, err := bearerToken(req)
+	if err != nil {
+		return nil, err
+	}
+	// Compare with every token in constant time not to leak the token through the response time.
+	name := ""
+	for candidate, principal := range s.tokens {
+		if subtle.ConstantTimeCompare([]byte(candidate), []byte(token)) == 1 {
+			name = principal
+		}
+	}
+	if name == "" {
+		return nil, fmt.Errorf("%w: the bearer token is invalid", ErrUnauthenticated)
+	}
+	return &Principal{Name: name, Groups: []string{}}, nil
+}
@@ -0,0 +1,112 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/inspection/event"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// eventTypeReset is the event sent when some events after the requested ID were already dropped. Clients need to fetch the metadata again.
+	eventTypeReset = "reset"
+	// eventTypeEnd is the event sent when the stream ended. Clients must not reconnect after receiving it.
+	eventTypeEnd = "end"
+)
+
+// eventStreamKeepAliveInterval is the interval to send comments keeping the connection open through proxies while no event happens.
+var eventStreamKeepAliveInterval = 15 * time.Second
+
+// parseLastEventID returns the ID of the last event the client received from the `Last-Event-ID` header sent on reconnects or the `lastEventId` query.
+func parseLastEventID(ctx *gin.Context) (uint64, error) {
+	lastEventID := ctx.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = ctx.Query("lastEventId")
+	}
+	if lastEventID == "" {
+		return 0, nil
+	}
+	id, err := strconv.ParseUint(lastEventID, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid last event ID %q", lastEventID)
+	}
+	return id, nil
+}
+
+// writeEventStream writes the events in the stream after the given ID as server-sent events until the stream is closed or the client disconnects.
+func writeEventStream(ctx *gin.Context, stream *event.Stream, lastEventID uint64) {
+	header := ctx.Writer.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Connection", "keep-alive")
+	// Prevent reverse proxies from buffering the stream.
+	header.Set("X-Accel-Buffering", "no")
+	ctx.Status(http.StatusOK)
+
+	keepAlive := time.NewTicker(eventStreamKeepAliveInterval)
+	defer keepAlive.Stop()
+	for {
+		batch := stream.Since(lastEventID)
+		if batch.Truncated {
+			lastEventID = 0
+			if err := writeServerSentEvent(ctx.Writer, "", eventTypeReset, struct{}{}); err != nil {
+				return
+			}
+		}
+		for _, e := range batch.Events {
+			if err := writeServerSentEvent(ctx.Writer, strconv.FormatUint(e.ID, 10), string(e.Type), e.Data); err != nil {
+				return
+			}
+			lastEventID = e.ID
+		}
+		if batch.Closed {
+			writeServerSentEvent(ctx.Writer, "", eventTypeEnd, struct{}{})
+			ctx.Writer.Flush()
+			return
+		}
+		ctx.Writer.Flush()
+		select {
+		case <-batch.Changed:
+		case <-keepAlive.C:
+			if _, err := io.WriteString(ctx.Writer, ": keep-alive\n\n"); err != nil {
+				return
+			}
+		case <-ctx.Request.Context().Done():
+			return
+		}
+	}
+}
+
+// writeServerSentEvent writes a single event in the format of server-sent events. The id field is omitted when the ID is empty.
+func writeServerSentEvent(writer io.Writer, id string, eventType string, data any) error {
+	serialized, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	message := ""
+	if id != "" {
+		message += fmt.Sprintf("id: %s\n", id)
+	}
+	message += fmt.Sprintf("event: %s\ndata: %s\n\n", eventType, serialized)
+	_, err = io.WriteString(writer, message)
+	return err
+}
@@ -0,0 +1,135 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/inspection/event"
+	"github.com/GoogleCloudPlatform/khi/pkg/inspection/metadata/progress"
+	"github.com/gin-gonic/gin"
+	"github.com/google/go-cmp/cmp"
+
+	_ "github.com/GoogleCloudPlatform/khi/internal/testflags"
+)
+
+func TestWriteEventStream(t *testing.T) {
+	testCases := []struct {
+		name        string
+		lastEventID uint64
+		want        string
+	}{
+		{
+			name:        "from the beginning",
+			lastEventID: 0,
+			want: "id: 1\nevent: task-resolved\ndata: {\"taskId\":\"foo\"}\n\n" +
+				"id: 2\nevent: phase\ndata: {\"phase\":\"DONE\"}\n\n" +
+				"event: end\ndata: {}\n\n",
+		},
+		{
+			name:        "resume after an event",
+			lastEventID: 1,
+			want: "id: 2\nevent: phase\ndata: {\"phase\":\"DONE\"}\n\n" +
+				"event: end\ndata: {}\n\n",
+		},
+		{
+			name:        "resume with an unknown event",
+			lastEventID: 10,
+			want: "event: reset\ndata: {}\n\n" +
+				"id: 1\nevent: task-resolved\ndata: {\"taskId\":\"foo\"}\n\n" +
+				"id: 2\nevent: phase\ndata: {\"phase\":\"DONE\"}\n\n" +
+				"event: end\ndata: {}\n\n",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			stream := event.NewStream(10)
+			stream.OnTaskResolved("foo")
+			stream.OnPhaseChanged(progress.TASK_PHASE_DONE)
+			stream.Close()
+			recorder := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(recorder)
+			ctx.Request = httptest.NewRequest(http.MethodGet, "/events", nil)
+
+			writeEventStream(ctx, stream, tc.lastEventID)
+
+			if diff := cmp.Diff(tc.want, recorder.Body.String()); diff != "" {
+				t.Errorf("event stream mismatch (-want +got):\n%s", diff)
+			}
+			if got := recorder.Header().Get("Content-Type"); got != "text/event-stream" {
+				t.Errorf("Content-Type = %q, want text/event-stream", got)
+			}
+		})
+	}
+}
+
+func TestWriteEventStreamWaitsNewEvents(t *testing.T) {
+	stream := event.NewStream(10)
+	recorder := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(recorder)
+	requestCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	ctx.Request = httptest.NewRequest(http.MethodGet, "/events", nil).WithContext(requestCtx)
+
+	done := make(chan struct{})
+	go func() {
+		writeEventStream(ctx, stream, 0)
+		close(done)
+	}()
+	stream.OnPhaseChanged(progress.TASK_PHASE_ERROR)
+	stream.Close()
+	<-done
+
+	want := "id: 1\nevent: phase\ndata: {\"phase\":\"ERROR\"}\n\nevent: end\ndata: {}\n\n"
+	if diff := cmp.Diff(want, recorder.Body.String()); diff != "" {
+		t.Errorf("event stream mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestParseLastEventID(t *testing.T) {
+	testCases := []struct {
+		name    string
+		header  string
+		query   string
+		want    uint64
+		wantErr bool
+	}{
+		{name: "no ID", want: 0},
+		{name: "header", header: "12", want: 12},
+		{name: "query", query: "?lastEventId=3", want: 3},
+		{name: "header is preferred", header: "5", query: "?lastEventId=3", want: 5},
+		{name: "malformed", query: "?lastEventId=foo", wantErr: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+			ctx.Request = httptest.NewRequest(http.MethodGet, "/events"+tc.query, nil)
+			if tc.header != "" {
+				ctx.Request.Header.Set("Last-Event-ID", tc.header)
+			}
+			got, err := parseLastEventID(ctx)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("parseLastEventID() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if got != tc.want {
+				t.Errorf("parseLastEventID() = %d, want %d", got, tc.want)
+			}
+		})
+	}
+}
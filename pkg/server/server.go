@@ -15,9 +15,12 @@
 package server
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"math"
+	"mime/multipart"
 	"net/http"
 	"os"
 	"path"
@@ -26,16 +29,33 @@ import (
 
 	"github.com/GoogleCloudPlatform/khi/pkg/common/filter"
 	"github.com/GoogleCloudPlatform/khi/pkg/inspection"
+	"github.com/GoogleCloudPlatform/khi/pkg/inspection/budget"
 	"github.com/GoogleCloudPlatform/khi/pkg/inspection/metadata"
+	"github.com/GoogleCloudPlatform/khi/pkg/inspection/retention"
+	"github.com/GoogleCloudPlatform/khi/pkg/inspection/scheduler"
 	inspection_task "github.com/GoogleCloudPlatform/khi/pkg/inspection/task"
+	"github.com/GoogleCloudPlatform/khi/pkg/inspection/task/exporter"
+	"github.com/GoogleCloudPlatform/khi/pkg/metrics"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/history/merge"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/history/reader"
 	"github.com/GoogleCloudPlatform/khi/pkg/parameters"
 	"github.com/GoogleCloudPlatform/khi/pkg/popup"
+	"github.com/GoogleCloudPlatform/khi/pkg/server/auth"
 	"github.com/GoogleCloudPlatform/khi/pkg/server/config"
 	"github.com/GoogleCloudPlatform/khi/pkg/server/upload"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-contrib/static"
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Keys in the request body to run an inspection to give the options of the inspection instead of form values.
+const (
+	budgetRequestKey         = "budget"
+	estimateVolumeRequestKey = "estimateVolume"
+	samplingRequestKey       = "sampling"
+	credentialRequestKey     = "credential"
 )
 
 type ServerConfig struct {
@@ -44,6 +64,17 @@ type ServerConfig struct {
 	ResourceMonitor  ResourceMonitor
 	ServerBasePath   string
 	UploadFileStore  *upload.UploadFileStore
+	// RetentionCollector removes old inspection results and uploaded files. The storage status endpoint returns 404 when it is nil.
+	RetentionCollector *retention.Collector
+	// Scheduler runs inspection jobs on cron schedules. The schedule status endpoint returns 404 when it is nil.
+	Scheduler *scheduler.Scheduler
+	// Authenticator authenticates requests to the API. Inspections, uploads and popups are scoped to the authenticated principal. The API is not authenticated when it is nil.
+	Authenticator auth.Authenticator
+	// AuthAdmins is the list of principal names or groups allowed to access the resources of every principal.
+	AuthAdmins []string
+	// APIOnly disables the bundled web UI. It is set when the authenticator requires credentials the web UI can't send.
+	APIOnly       bool // MetricsPublic serves the metrics endpoint without authentication even when the authenticator is set.
+	MetricsPublic bool
 }
 
 func redirectMiddleware(exactPath string, redirectTo string) gin.HandlerFunc {
@@ -64,28 +95,55 @@ func CreateKHIServer(inspectionServer *inspection.InspectionTaskServer, serverCo
 	appHtmlPath := path.Join(serverConfig.StaticFolderPath, "/index.html")
 
 	basePathWithoutTrailingSlash := strings.TrimSuffix(serverConfig.ServerBasePath, "/")
-	engine.Use(redirectMiddleware(basePathWithoutTrailingSlash+"/", basePathWithoutTrailingSlash+"/session/0")) // Request for `/` shouldn't be handled by `static.Serve`, redirect `/session/0` to be handled by patternToString
-	engine.Use(static.Serve(basePathWithoutTrailingSlash+"/", static.LocalFile(serverConfig.StaticFolderPath, false)))
+	if !serverConfig.APIOnly {
+		engine.Use(redirectMiddleware(basePathWithoutTrailingSlash+"/", basePathWithoutTrailingSlash+"/session/0")) // Request for `/` shouldn't be handled by `static.Serve`, redirect `/session/0` to be handled by patternToString
+		engine.Use(static.Serve(basePathWithoutTrailingSlash+"/", static.LocalFile(serverConfig.StaticFolderPath, false)))
+	}
 	engine.Use(gin.Recovery())
 	engine.Use(cors.New(corsConfig))
 	router := engine.Group(basePathWithoutTrailingSlash)
 
-	// frontend uses Angular router. All frontend routing path should return the app html
-	router.GET("/session/*wild", func(ctx *gin.Context) {
-		ctx.Header("Content-Type", "text/html")
-		file, err := os.ReadFile(appHtmlPath)
-		if err != nil {
-			ctx.String(http.StatusInternalServerError, err.Error())
-			return
+	if !serverConfig.APIOnly {
+		// frontend uses Angular router. All frontend routing path should return the app html
+		router.GET("/session/*wild", func(ctx *gin.Context) {
+			ctx.Header("Content-Type", "text/html")
+			file, err := os.ReadFile(appHtmlPath)
+			if err != nil {
+				ctx.String(http.StatusInternalServerError, err.Error())
+				return
+			}
+			originalIndexHTML := string(file)
+			replacedIndexHtml, err := replaceDynamicPartOfIndex(originalIndexHTML)
+			if err != nil {
+				ctx.String(http.StatusInternalServerError, err.Error())
+				return
+			}
+			ctx.Writer.Write([]byte(replacedIndexHtml))
+		})
+	}
+
+	// GET /metrics
+	// Returns the metrics of the server and inspections in the Prometheus exposition format.
+	// It requires authentication like the API unless the metrics are explicitly made public.
+	metricsHandler := promhttp.HandlerFor(metrics.Registry, promhttp.HandlerOpts{})
+	serveMetrics := func(ctx *gin.Context) {
+		if serverConfig.ResourceMonitor != nil {
+			metrics.MemoryUsed.Set(float64(serverConfig.ResourceMonitor.GetUsedMemory()))
 		}
-		originalIndexHTML := string(file)
-		replacedIndexHtml, err := replaceDynamicPartOfIndex(originalIndexHTML)
-		if err != nil {
-			ctx.String(http.StatusInternalServerError, err.Error())
-			return
+		metricsHandler.ServeHTTP(ctx.Writer, ctx.Request)
+	}
+	if serverConfig.Authenticator == nil || serverConfig.MetricsPublic {
+		router.GET("/metrics", serveMetrics)
+	}
+
+	// Routes registered below require authentication when the authenticator is set.
+	if serverConfig.Authenticator != nil {
+		router.Use(auth.Middleware(serverConfig.Authenticator, serverConfig.AuthAdmins))
+		if !serverConfig.MetricsPublic {
+			router.GET("/metrics", serveMetrics)
 		}
-		ctx.Writer.Write([]byte(replacedIndexHtml))
-	})
+	}
+
 	// GET /api/v3/config
 	// Returns configuration map used in frontend.
 	router.GET("/api/v3/config", func(ctx *gin.Context) {
@@ -105,9 +163,10 @@ func CreateKHIServer(inspectionServer *inspection.InspectionTaskServer, serverCo
 		// Returns the all started inspections on the inspection server.
 		router.GET("/api/v3/inspection", func(ctx *gin.Context) {
 			inspections := inspectionServer.GetAllRunners()
+			principal := auth.PrincipalFromContext(ctx)
 			responseInspections := map[string]SerializedMetadata{}
 			for _, inspection := range inspections {
-				if inspection.Started() {
+				if inspection.Started() && principal.CanAccess(inspection.Owner()) {
 					md, err := inspection.GetCurrentMetadata()
 					if err != nil {
 						ctx.String(http.StatusInternalServerError, err.Error())
@@ -134,7 +193,7 @@ func CreateKHIServer(inspectionServer *inspection.InspectionTaskServer, serverCo
 		// POST /api/v3/inspection/tasks
 		router.POST("/api/v3/inspection/types/:typeID", func(ctx *gin.Context) {
 			typeID := ctx.Param("typeID")
-			inspectionId, err := inspectionServer.CreateInspection(typeID)
+			inspectionId, err := inspectionServer.CreateInspectionWithOwner(typeID, auth.PrincipalFromContext(ctx).OwnerName())
 			if err != nil {
 				// only the not found error is expected here
 				ctx.String(http.StatusNotFound, err.Error())
@@ -145,7 +204,7 @@ func CreateKHIServer(inspectionServer *inspection.InspectionTaskServer, serverCo
 		// PUT /api/v3/inspection/<inspection-id>/features
 		router.PUT("/api/v3/inspection/:inspectionID/features", func(ctx *gin.Context) {
 			inspectionID := ctx.Param("inspectionID")
-			task := inspectionServer.GetInspection(inspectionID)
+			task := getVisibleInspection(ctx, inspectionServer, inspectionID)
 			if task == nil {
 				ctx.String(http.StatusNotFound, fmt.Sprintf("inspecton %s was not found", inspectionID))
 				return
@@ -165,7 +224,7 @@ func CreateKHIServer(inspectionServer *inspection.InspectionTaskServer, serverCo
 		// PATCH /api/v3/inspection/<inspection-id>/features
 		router.PATCH("/api/v3/inspection/:inspectionID/features", func(ctx *gin.Context) {
 			inspectionID := ctx.Param("inspectionID")
-			task := inspectionServer.GetInspection(inspectionID)
+			task := getVisibleInspection(ctx, inspectionServer, inspectionID)
 			if task == nil {
 				ctx.String(http.StatusNotFound, fmt.Sprintf("inspecton %s was not found", inspectionID))
 				return
@@ -185,7 +244,7 @@ func CreateKHIServer(inspectionServer *inspection.InspectionTaskServer, serverCo
 		// GET /api/v3/inspection/<inspection-id>/features
 		router.GET("/api/v3/inspection/:inspectionID/features", func(ctx *gin.Context) {
 			inspectionID := ctx.Param("inspectionID")
-			task := inspectionServer.GetInspection(inspectionID)
+			task := getVisibleInspection(ctx, inspectionServer, inspectionID)
 			if task == nil {
 				ctx.String(http.StatusNotFound, fmt.Sprintf("inspecton %s was not found", inspectionID))
 				return
@@ -202,7 +261,7 @@ func CreateKHIServer(inspectionServer *inspection.InspectionTaskServer, serverCo
 
 		router.POST("/api/v3/inspection/:inspectionID/dryrun", func(ctx *gin.Context) {
 			inspectionID := ctx.Param("inspectionID")
-			currentTask := inspectionServer.GetInspection(inspectionID)
+			currentTask := getVisibleInspection(ctx, inspectionServer, inspectionID)
 			if currentTask == nil {
 				ctx.String(http.StatusNotFound, fmt.Sprintf("inspecton %s was not found", inspectionID))
 				return
@@ -212,9 +271,12 @@ func CreateKHIServer(inspectionServer *inspection.InspectionTaskServer, serverCo
 				ctx.String(http.StatusBadRequest, err.Error())
 				return
 			}
-			result, err := currentTask.DryRun(ctx, &inspection_task.InspectionRequest{
-				Values: reqBody,
-			})
+			req, err := newInspectionRequest(reqBody)
+			if err != nil {
+				ctx.String(http.StatusBadRequest, err.Error())
+				return
+			}
+			result, err := currentTask.DryRun(ctx, req)
 			if err != nil {
 				ctx.String(http.StatusInternalServerError, err.Error())
 				return
@@ -224,7 +286,7 @@ func CreateKHIServer(inspectionServer *inspection.InspectionTaskServer, serverCo
 
 		router.POST("/api/v3/inspection/:inspectionID/run", func(ctx *gin.Context) {
 			inspectionID := ctx.Param("inspectionID")
-			currentTask := inspectionServer.GetInspection(inspectionID)
+			currentTask := getVisibleInspection(ctx, inspectionServer, inspectionID)
 			if currentTask == nil {
 				ctx.String(http.StatusNotFound, fmt.Sprintf("inspecton %s was not found", inspectionID))
 				return
@@ -234,9 +296,12 @@ func CreateKHIServer(inspectionServer *inspection.InspectionTaskServer, serverCo
 				ctx.String(http.StatusBadRequest, err.Error())
 				return
 			}
-			err := currentTask.Run(ctx, &inspection_task.InspectionRequest{
-				Values: reqBody,
-			})
+			req, err := newInspectionRequest(reqBody)
+			if err != nil {
+				ctx.String(http.StatusBadRequest, err.Error())
+				return
+			}
+			err = currentTask.Run(ctx, req)
 			if err != nil {
 				ctx.String(http.StatusInternalServerError, err.Error())
 				return
@@ -246,7 +311,7 @@ func CreateKHIServer(inspectionServer *inspection.InspectionTaskServer, serverCo
 
 		router.POST("/api/v3/inspection/:inspectionID/cancel", func(ctx *gin.Context) {
 			inspectionID := ctx.Param("inspectionID")
-			currentTask := inspectionServer.GetInspection(inspectionID)
+			currentTask := getVisibleInspection(ctx, inspectionServer, inspectionID)
 			if currentTask == nil {
 				ctx.String(http.StatusNotFound, fmt.Sprintf("inspecton %s was not found", inspectionID))
 				return
@@ -259,9 +324,102 @@ func CreateKHIServer(inspectionServer *inspection.InspectionTaskServer, serverCo
 			ctx.String(http.StatusOK, "ok")
 		})
 
+		router.DELETE("/api/v3/inspection/:inspectionID", func(ctx *gin.Context) {
+			inspectionID := ctx.Param("inspectionID")
+			if getVisibleInspection(ctx, inspectionServer, inspectionID) == nil {
+				ctx.String(http.StatusNotFound, fmt.Sprintf("inspecton %s was not found", inspectionID))
+				return
+			}
+			err := inspectionServer.DeleteInspection(inspectionID)
+			if err != nil {
+				if errors.Is(err, inspection.ErrInspectionNotFound) {
+					ctx.String(http.StatusNotFound, fmt.Sprintf("inspecton %s was not found", inspectionID))
+					return
+				}
+				if errors.Is(err, inspection.ErrInspectionRunning) {
+					ctx.String(http.StatusBadRequest, err.Error())
+					return
+				}
+				ctx.String(http.StatusInternalServerError, err.Error())
+				return
+			}
+			ctx.String(http.StatusOK, "ok")
+		})
+
+		// POST /api/v3/inspection/merge
+		// Merges the results of finished inspections into a new read-only inspection.
+		router.POST("/api/v3/inspection/merge", func(ctx *gin.Context) {
+			var reqBody PostInspectionMergeRequest
+			if err := ctx.ShouldBindJSON(&reqBody); err != nil {
+				ctx.String(http.StatusBadRequest, err.Error())
+				return
+			}
+			for _, id := range reqBody.InspectionIDs {
+				if getVisibleInspection(ctx, inspectionServer, id) == nil {
+					ctx.String(http.StatusNotFound, fmt.Sprintf("inspecton %s was not found", id))
+					return
+				}
+			}
+			inspectionID, err := inspectionServer.MergeInspections(ctx, reqBody.InspectionIDs, auth.PrincipalFromContext(ctx).OwnerName())
+			if err != nil {
+				if errors.Is(err, inspection.ErrInspectionNotFound) {
+					ctx.String(http.StatusNotFound, err.Error())
+					return
+				}
+				if errors.Is(err, inspection.ErrInspectionRunning) || errors.Is(err, inspection.ErrNotEnoughInspectionsToMerge) {
+					ctx.String(http.StatusBadRequest, err.Error())
+					return
+				}
+				ctx.String(http.StatusInternalServerError, err.Error())
+				return
+			}
+			ctx.JSON(http.StatusCreated, &PostInspectionResponse{InspectionID: inspectionID})
+		})
+
+		// GET /api/v3/inspection/<inspection-id>/diff?base=<inspection-id>
+		// Returns the resources whose revisions differ between the base inspection and this inspection.
+		router.GET("/api/v3/inspection/:inspectionID/diff", func(ctx *gin.Context) {
+			inspectionID := ctx.Param("inspectionID")
+			baseInspectionID := ctx.Query("base")
+			if baseInspectionID == "" {
+				ctx.String(http.StatusBadRequest, "base inspection ID is required")
+				return
+			}
+			khiFiles := []*reader.Reader{}
+			defer func() {
+				for _, khiFile := range khiFiles {
+					khiFile.Close()
+				}
+			}()
+			for _, id := range []string{baseInspectionID, inspectionID} {
+				currentTask := getVisibleInspection(ctx, inspectionServer, id)
+				if currentTask == nil {
+					ctx.String(http.StatusNotFound, fmt.Sprintf("inspecton %s was not found", id))
+					return
+				}
+				result, err := currentTask.Result()
+				if err != nil {
+					ctx.String(http.StatusBadRequest, err.Error())
+					return
+				}
+				khiFile, err := reader.OpenStore(result.ResultStore)
+				if err != nil {
+					ctx.String(http.StatusInternalServerError, err.Error())
+					return
+				}
+				khiFiles = append(khiFiles, khiFile)
+			}
+			diffResult, err := merge.Diff(khiFiles[0], khiFiles[1])
+			if err != nil {
+				ctx.String(http.StatusInternalServerError, err.Error())
+				return
+			}
+			ctx.JSON(http.StatusOK, diffResult)
+		})
+
 		router.GET("/api/v3/inspection/:inspectionID/metadata", func(ctx *gin.Context) {
 			inspectionID := ctx.Param("inspectionID")
-			currentTask := inspectionServer.GetInspection(inspectionID)
+			currentTask := getVisibleInspection(ctx, inspectionServer, inspectionID)
 			if currentTask == nil {
 				ctx.String(http.StatusNotFound, fmt.Sprintf("inspecton %s was not found", inspectionID))
 				return
@@ -274,9 +432,29 @@ func CreateKHIServer(inspectionServer *inspection.InspectionTaskServer, serverCo
 			ctx.JSON(http.StatusOK, result)
 		})
 
+		router.GET("/api/v3/inspection/:inspectionID/events", func(ctx *gin.Context) {
+			inspectionID := ctx.Param("inspectionID")
+			currentTask := getVisibleInspection(ctx, inspectionServer, inspectionID)
+			if currentTask == nil {
+				ctx.String(http.StatusNotFound, fmt.Sprintf("inspecton %s was not found", inspectionID))
+				return
+			}
+			lastEventID, err := parseLastEventID(ctx)
+			if err != nil {
+				ctx.String(http.StatusBadRequest, err.Error())
+				return
+			}
+			stream, err := currentTask.Events()
+			if err != nil {
+				ctx.String(http.StatusBadRequest, err.Error())
+				return
+			}
+			writeEventStream(ctx, stream, lastEventID)
+		})
+
 		router.GET("/api/v3/inspection/:inspectionID/data", func(ctx *gin.Context) {
 			inspectionID := ctx.Param("inspectionID")
-			currentTask := inspectionServer.GetInspection(inspectionID)
+			currentTask := getVisibleInspection(ctx, inspectionServer, inspectionID)
 			if currentTask == nil {
 				ctx.String(http.StatusNotFound, fmt.Sprintf("inspecton %s was not found", inspectionID))
 				return
@@ -323,8 +501,98 @@ func CreateKHIServer(inspectionServer *inspection.InspectionTaskServer, serverCo
 			ctx.DataFromReader(http.StatusOK, int64(math.Min(float64(maxSize), float64(fileSize-int(rangeStart)))), "application/octet-stream", inspectionDataReader, map[string]string{})
 		})
 
+		// GET /api/v3/inspection/<inspection-id>/export?format=<jsonl|csv>&table=<logs|revisions|events|resources>
+		// Returns the inspection result as normalized tables. All tables are returned in a zip archive when the table is not specified.
+		router.GET("/api/v3/inspection/:inspectionID/export", func(ctx *gin.Context) {
+			inspectionID := ctx.Param("inspectionID")
+			currentTask := getVisibleInspection(ctx, inspectionServer, inspectionID)
+			if currentTask == nil {
+				ctx.String(http.StatusNotFound, fmt.Sprintf("inspecton %s was not found", inspectionID))
+				return
+			}
+			format, err := exporter.ParseFormat(ctx.Query("format"))
+			if err != nil {
+				ctx.String(http.StatusBadRequest, err.Error())
+				return
+			}
+			var table exporter.Table
+			if tableQueryStr := ctx.Query("table"); tableQueryStr != "" {
+				table, err = exporter.ParseTable(tableQueryStr)
+				if err != nil {
+					ctx.String(http.StatusBadRequest, err.Error())
+					return
+				}
+			}
+
+			result, err := currentTask.Result()
+			if err != nil {
+				ctx.String(http.StatusBadRequest, err.Error())
+				return
+			}
+			khiFile, err := reader.OpenStore(result.ResultStore)
+			if err != nil {
+				ctx.String(http.StatusInternalServerError, err.Error())
+				return
+			}
+			defer khiFile.Close()
+			tableExporter, err := exporter.NewExporter(khiFile)
+			if err != nil {
+				ctx.String(http.StatusInternalServerError, err.Error())
+				return
+			}
+
+			if table == "" {
+				ctx.Header("Content-Type", "application/zip")
+				ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s-%s.zip\"", inspectionID, format))
+				err = tableExporter.ExportArchive(format, ctx.Writer)
+			} else {
+				contentType := "application/x-ndjson"
+				if format == exporter.FormatCSV {
+					contentType = "text/csv"
+				}
+				ctx.Header("Content-Type", contentType)
+				ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s-%s\"", inspectionID, exporter.FileName(table, format)))
+				err = tableExporter.ExportTable(table, format, ctx.Writer)
+			}
+			if err != nil {
+				// The response header may be already sent. Abort with the error to let the client know the body is incomplete.
+				ctx.AbortWithError(http.StatusInternalServerError, err)
+				return
+			}
+		})
+
+		// GET /api/v3/storage
+		// Returns the storage usage of inspection results and uploaded files with the recent eviction decisions.
+		router.GET("/api/v3/storage", func(ctx *gin.Context) {
+			if serverConfig.RetentionCollector == nil {
+				ctx.String(http.StatusNotFound, "storage retention is not enabled")
+				return
+			}
+			// The status contains the inspections and uploads of every principal.
+			if principal := auth.PrincipalFromContext(ctx); principal != nil && !principal.Admin {
+				ctx.String(http.StatusForbidden, "only admins can read the storage status")
+				return
+			}
+			ctx.JSON(http.StatusOK, serverConfig.RetentionCollector.Status())
+		})
+
+		// GET /api/v3/schedules
+		// Returns the next run time and the recent runs of scheduled inspection jobs.
+		router.GET("/api/v3/schedules", func(ctx *gin.Context) {
+			if serverConfig.Scheduler == nil {
+				ctx.String(http.StatusNotFound, "inspection scheduling is not enabled")
+				return
+			}
+			// Scheduled jobs aren't owned by any principal.
+			if principal := auth.PrincipalFromContext(ctx); principal != nil && !principal.Admin {
+				ctx.String(http.StatusForbidden, "only admins can read the schedule status")
+				return
+			}
+			ctx.JSON(http.StatusOK, serverConfig.Scheduler.Status())
+		})
+
 		router.GET("/api/v3/popup", func(ctx *gin.Context) {
-			currentPopup := popup.Instance.GetCurrentPopup()
+			currentPopup := popup.Instance.GetCurrentPopup(auth.PrincipalFromContext(ctx).OwnerName())
 			if currentPopup == nil {
 				ctx.String(http.StatusOK, "")
 				return
@@ -338,7 +606,7 @@ func CreateKHIServer(inspectionServer *inspection.InspectionTaskServer, serverCo
 				ctx.String(http.StatusBadRequest, err.Error())
 				return
 			}
-			result, err := popup.Instance.Validate(request)
+			result, err := popup.Instance.Validate(auth.PrincipalFromContext(ctx).OwnerName(), request)
 			if errors.Is(err, popup.NoCurrentPopup) {
 				ctx.String(http.StatusNotFound, err.Error())
 				return
@@ -360,7 +628,7 @@ func CreateKHIServer(inspectionServer *inspection.InspectionTaskServer, serverCo
 				ctx.String(http.StatusBadRequest, err.Error())
 				return
 			}
-			err := popup.Instance.Answer(request)
+			err := popup.Instance.Answer(auth.PrincipalFromContext(ctx).OwnerName(), request)
 			if errors.Is(err, popup.NoCurrentPopup) {
 				ctx.String(http.StatusNotFound, err.Error())
 				return
@@ -382,20 +650,34 @@ func CreateKHIServer(inspectionServer *inspection.InspectionTaskServer, serverCo
 				ctx.String(http.StatusBadRequest, "invalid operation. Current UploadFileStore.StoreProvider is not supporting to be written directly")
 				return
 			}
-			file, err := ctx.FormFile("file")
+			_, err := ctx.FormFile("file")
 			if err != nil {
 				ctx.String(http.StatusBadRequest, err.Error())
 				return
 			}
+			// Multiple files can be uploaded for a token. They are stored together in an archive.
+			files := ctx.Request.MultipartForm.File["file"]
 
 			id := ctx.Request.FormValue("upload-token-id")
 			if id == "" {
 				ctx.String(http.StatusBadRequest, "missing upload-token-id")
 				return
 			}
+			if auth.PrincipalFromContext(ctx) != nil {
+				// Upload IDs are prefixed with the inspection ID. See upload.GenerateUploadIDWithTaskContext.
+				uploadInspectionID, _, _ := strings.Cut(id, "_")
+				if getVisibleInspection(ctx, inspectionServer, uploadInspectionID) == nil {
+					ctx.String(http.StatusNotFound, fmt.Sprintf("inspecton %s was not found", uploadInspectionID))
+					return
+				}
+			}
 
 			token := &upload.DirectUploadToken{ID: id}
-			if parameters.Server.MaxUploadFileSizeInBytes != nil && *parameters.Server.MaxUploadFileSizeInBytes < int(file.Size) {
+			totalSize := 0
+			for _, file := range files {
+				totalSize += int(file.Size)
+			}
+			if parameters.Server.MaxUploadFileSizeInBytes != nil && *parameters.Server.MaxUploadFileSizeInBytes < totalSize {
 				ctx.String(http.StatusBadRequest, fmt.Sprintf("file size exceeds the limit (%d bytes)", *parameters.Server.MaxUploadFileSizeInBytes))
 				return
 			}
@@ -406,20 +688,24 @@ func CreateKHIServer(inspectionServer *inspection.InspectionTaskServer, serverCo
 				return
 			}
 
-			multipart, err := file.Open()
+			err = writeUploadedFiles(localUploadFileStoreProvider, token, files)
 			if err != nil {
-				ctx.String(http.StatusBadRequest, err.Error())
+				serverConfig.UploadFileStore.SetResultOnCompletedUpload(token, err)
+				ctx.String(http.StatusInternalServerError, err.Error())
 				return
 			}
-			defer multipart.Close()
-
-			err = localUploadFileStoreProvider.Write(token, multipart)
+			err = checkUploadedExpandedSize(localUploadFileStoreProvider, token)
 			if err != nil {
 				serverConfig.UploadFileStore.SetResultOnCompletedUpload(token, err)
+				if errors.Is(err, upload.ErrExpandedSizeExceeded) {
+					ctx.String(http.StatusRequestEntityTooLarge, err.Error())
+					return
+				}
 				ctx.String(http.StatusInternalServerError, err.Error())
 				return
 			}
 			serverConfig.UploadFileStore.SetResultOnCompletedUpload(token, nil)
+			metrics.UploadBytes.Observe(float64(totalSize))
 
 			ctx.String(http.StatusOK, "")
 		})
@@ -427,6 +713,65 @@ func CreateKHIServer(inspectionServer *inspection.InspectionTaskServer, serverCo
 	return engine
 }
 
+// getVisibleInspection returns the inspection with the given ID when the principal of the request can access it. It returns nil otherwise.
+func getVisibleInspection(ctx *gin.Context, inspectionServer *inspection.InspectionTaskServer, inspectionID string) *inspection.InspectionTaskRunner {
+	currentInspection := inspectionServer.GetInspection(inspectionID)
+	if currentInspection == nil || !auth.PrincipalFromContext(ctx).CanAccess(currentInspection.Owner()) {
+		return nil
+	}
+	return currentInspection
+}
+
+// newInspectionRequest returns the InspectionRequest from the request body to run an inspection.
+// The body is the map of form values and it can contain the options of the inspection with the keys `budget`, `estimateVolume`, `sampling` and `credential` in addition.
+func newInspectionRequest(reqBody map[string]any) (*inspection_task.InspectionRequest, error) {
+	req := &inspection_task.InspectionRequest{
+		Values: map[string]any{},
+	}
+	var override *budget.Override
+	for key, value := range reqBody {
+		var err error
+		switch key {
+		case budgetRequestKey:
+			err = decodeRequestOption(key, value, &override)
+		case estimateVolumeRequestKey:
+			err = decodeRequestOption(key, value, &req.EstimateVolume)
+		case samplingRequestKey:
+			err = decodeRequestOption(key, value, &req.Sampling)
+		case credentialRequestKey:
+			err = decodeRequestOption(key, value, &req.Credential)
+			if err == nil && req.Credential != nil {
+				err = req.Credential.Validate()
+			}
+		default:
+			req.Values[key] = value
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if override != nil {
+		inspectionBudget, err := budget.Default.WithOverride(override)
+		if err != nil {
+			return nil, err
+		}
+		req.Budget = inspectionBudget
+	}
+	return req, nil
+}
+
+// decodeRequestOption decodes the value of an option given in the request body into the given pointer.
+func decodeRequestOption(key string, value any, dest any) error {
+	serialized, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(serialized, dest); err != nil {
+		return fmt.Errorf("invalid %s in the request\n%w", key, err)
+	}
+	return nil
+}
+
 // instanciateGinServer generates a new instance of *gin.Engine with provided debug mode flag.
 func instanciateGinServer(debugMode bool) *gin.Engine {
 	if debugMode {
@@ -440,3 +785,45 @@ func instanciateGinServer(debugMode bool) *gin.Engine {
 	}
 	return engine
 }
+
+// checkUploadedExpandedSize returns upload.ErrExpandedSizeExceeded when the uploaded file for the token expands over the limit.
+func checkUploadedExpandedSize(provider *upload.LocalUploadFileStoreProvider, token upload.UploadToken) error {
+	reader, err := provider.Read(token)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	return upload.CheckExpandedSize(reader, token.GetID())
+}
+
+// writeUploadedFiles writes the files uploaded for the token. Multiple files are written in an archive read with upload.WalkUploadedMembers.
+func writeUploadedFiles(provider *upload.LocalUploadFileStoreProvider, token upload.UploadToken, files []*multipart.FileHeader) error {
+	if len(files) == 1 {
+		file, err := files[0].Open()
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		return provider.Write(token, file)
+	}
+	pipeReader, pipeWriter := io.Pipe()
+	go func() {
+		uploadedFiles := []upload.UploadedFile{}
+		for _, fileHeader := range files {
+			file, err := fileHeader.Open()
+			if err != nil {
+				pipeWriter.CloseWithError(err)
+				return
+			}
+			defer file.Close()
+			uploadedFiles = append(uploadedFiles, upload.UploadedFile{
+				Name:   fileHeader.Filename,
+				Size:   fileHeader.Size,
+				Reader: file,
+			})
+		}
+		pipeWriter.CloseWithError(upload.WriteMultiFileArchive(pipeWriter, uploadedFiles))
+	}()
+	defer pipeReader.Close()
+	return provider.Write(token, pipeReader)
+}
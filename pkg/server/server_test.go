@@ -16,6 +16,7 @@ package server
 
 import (
 	"bytes"
+	"compress/gzip"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -29,16 +30,22 @@ import (
 	"time"
 
 	"github.com/GoogleCloudPlatform/khi/pkg/inspection"
+	"github.com/GoogleCloudPlatform/khi/pkg/inspection/budget"
+	"github.com/GoogleCloudPlatform/khi/pkg/inspection/credential"
 	"github.com/GoogleCloudPlatform/khi/pkg/inspection/form"
 	inspection_task_interface "github.com/GoogleCloudPlatform/khi/pkg/inspection/interface"
 	"github.com/GoogleCloudPlatform/khi/pkg/inspection/ioconfig"
 	"github.com/GoogleCloudPlatform/khi/pkg/inspection/logger"
 	"github.com/GoogleCloudPlatform/khi/pkg/inspection/metadata/progress"
+	"github.com/GoogleCloudPlatform/khi/pkg/inspection/retention"
+	"github.com/GoogleCloudPlatform/khi/pkg/inspection/scheduler"
 	inspection_task "github.com/GoogleCloudPlatform/khi/pkg/inspection/task"
+	"github.com/GoogleCloudPlatform/khi/pkg/log/logstore"
 	"github.com/GoogleCloudPlatform/khi/pkg/model/enum"
 	"github.com/GoogleCloudPlatform/khi/pkg/model/history"
 	"github.com/GoogleCloudPlatform/khi/pkg/parameters"
 	"github.com/GoogleCloudPlatform/khi/pkg/popup"
+	"github.com/GoogleCloudPlatform/khi/pkg/server/auth"
 	"github.com/GoogleCloudPlatform/khi/pkg/server/config"
 	"github.com/GoogleCloudPlatform/khi/pkg/server/upload"
 	gcp_task "github.com/GoogleCloudPlatform/khi/pkg/source/gcp/task"
@@ -574,10 +581,10 @@ func TestApiResponses(t *testing.T) {
 			BodyValidator: bodyCompareWithStringExpectedValue(""),
 			After: func(stat map[string]string) {
 				go func() {
-					popup.Instance.ShowPopup(testPopupForm{})
+					popup.Instance.ShowPopup("", testPopupForm{})
 				}()
 				<-time.After(time.Second)
-				p := popup.Instance.GetCurrentPopup()
+				p := popup.Instance.GetCurrentPopup("")
 				stat["popup-id"] = p.Id
 			},
 		},
@@ -690,6 +697,149 @@ func TestApiResponses(t *testing.T) {
 				ViewerMode: true,
 			}),
 		},
+		{
+			// 041
+			ExpectedCode:  200,
+			RequestMethod: "GET",
+			RequestPath:   "/foo/api/v3/inspection/<task-1>/export?format=csv&table=resources",
+			BodyValidator: bodyCompareWithStringExpectedValue("path,name,parent_path,relationship,timeline_id\n"),
+		},
+		{
+			// 042
+			ExpectedCode:  200,
+			RequestMethod: "GET",
+			RequestPath:   "/foo/api/v3/inspection/<task-1>/export",
+			BodyValidator: func(t *testing.T, body string, stat map[string]string) {
+				if !strings.HasPrefix(body, "PK") {
+					t.Errorf("the exported data is not a zip archive\n%s", body)
+				}
+			},
+		},
+		{
+			// 043
+			ExpectedCode:  400,
+			RequestMethod: "GET",
+			RequestPath:   "/foo/api/v3/inspection/<task-1>/export?format=xml",
+		},
+		{
+			// 044
+			ExpectedCode:  400,
+			RequestMethod: "GET",
+			RequestPath:   "/foo/api/v3/inspection/<task-2>/export",
+			BodyValidator: bodyCompareWithStringExpectedValue("context canceled"),
+		},
+		{
+			// 045
+			ExpectedCode:  200,
+			RequestMethod: "DELETE",
+			RequestPath:   "/foo/api/v3/inspection/<task-2>",
+			BodyValidator: bodyCompareWithStringExpectedValue("ok"),
+		},
+		{
+			// 046
+			ExpectedCode:  404,
+			RequestMethod: "GET",
+			RequestPath:   "/foo/api/v3/inspection/<task-2>/metadata",
+		},
+		{
+			// 047
+			ExpectedCode:  404,
+			RequestMethod: "DELETE",
+			RequestPath:   "/foo/api/v3/inspection/<task-2>",
+		},
+		{
+			// 048
+			ExpectedCode:  400,
+			RequestMethod: "POST",
+			RequestPath:   "/foo/api/v3/inspection/merge",
+			RequestGenerator: func(t *testing.T, stat map[string]string) any {
+				return PostInspectionMergeRequest{InspectionIDs: []string{stat["task-1"]}}
+			},
+		},
+		{
+			// 049
+			ExpectedCode:  404,
+			RequestMethod: "POST",
+			RequestPath:   "/foo/api/v3/inspection/merge",
+			RequestGenerator: func(t *testing.T, stat map[string]string) any {
+				return PostInspectionMergeRequest{InspectionIDs: []string{stat["task-1"], "not-existing-inspection"}}
+			},
+		},
+		{
+			// 050
+			ExpectedCode:  201,
+			RequestMethod: "POST",
+			RequestPath:   "/foo/api/v3/inspection/merge",
+			RequestGenerator: func(t *testing.T, stat map[string]string) any {
+				return PostInspectionMergeRequest{InspectionIDs: []string{stat["task-1"], stat["task-1"]}}
+			},
+			BodyValidator: func(t *testing.T, body string, stat map[string]string) {
+				var response PostInspectionResponse
+				err := json.Unmarshal([]byte(body), &response)
+				if err != nil {
+					t.Errorf("failed to decode response json\n%v", err)
+				}
+				stat["task-4"] = response.InspectionID
+			},
+		},
+		{
+			// 051
+			ExpectedCode:  200,
+			RequestMethod: "GET",
+			RequestPath:   "/foo/api/v3/inspection/<task-4>/diff?base=<task-1>",
+			BodyValidator: bodyCompareWithStringExpectedValue(`{"resources":[]}`),
+		},
+		{
+			// 052
+			ExpectedCode:  400,
+			RequestMethod: "GET",
+			RequestPath:   "/foo/api/v3/inspection/<task-4>/diff",
+		},
+		{
+			// 053
+			ExpectedCode:  200,
+			RequestMethod: "DELETE",
+			RequestPath:   "/foo/api/v3/inspection/<task-4>",
+			BodyValidator: bodyCompareWithStringExpectedValue("ok"),
+		},
+		{
+			// 054
+			ExpectedCode:  200,
+			RequestMethod: "GET",
+			RequestPath:   "/foo/api/v3/inspection/<task-1>/events",
+			BodyValidator: func(t *testing.T, body string, stat map[string]string) {
+				for _, want := range []string{"event: task-resolved\n", "event: phase\ndata: {\"phase\":\"DONE\"}\n\n", "event: end\n"} {
+					if !strings.Contains(body, want) {
+						t.Errorf("event stream doesn't contain %q\n%s", want, body)
+					}
+				}
+			},
+		},
+		{
+			// 055
+			ExpectedCode:  400,
+			RequestMethod: "GET",
+			RequestPath:   "/foo/api/v3/inspection/<task-1>/events?lastEventId=foo",
+		},
+		{
+			// 056
+			ExpectedCode:  404,
+			RequestMethod: "GET",
+			RequestPath:   "/foo/api/v3/inspection/not-existing-inspection/events",
+		},
+		{
+			// 057
+			ExpectedCode:  200,
+			RequestMethod: "GET",
+			RequestPath:   "/foo/metrics",
+			BodyValidator: func(t *testing.T, body string, stat map[string]string) {
+				for _, want := range []string{`khi_inspections_finished_total{inspection_type="foo",status="done"}`, "khi_memory_used_bytes 1000\n"} {
+					if !strings.Contains(body, want) {
+						t.Errorf("metrics don't contain %q\n%s", want, body)
+					}
+				}
+			},
+		},
 	}
 
 	stat := map[string]string{}
@@ -706,7 +856,7 @@ func TestApiResponses(t *testing.T) {
 				requestReader = bytes.NewReader(request)
 			}
 			path := step.RequestPath
-			TASK_COUNT := 3
+			TASK_COUNT := 4
 			for i := 0; i < TASK_COUNT; i++ {
 				path = strings.ReplaceAll(path, fmt.Sprintf("<task-%d>", i+1), stat[fmt.Sprintf("task-%d", i+1)])
 			}
@@ -734,6 +884,7 @@ func TestKHIServer_EndpointExistsWithConfigs(t *testing.T) {
 		name           string
 		serverBasePath string
 		viewerMode     bool
+		apiOnly        bool
 		requestMethod  string
 		requestPath    string
 		wantCode       int
@@ -765,6 +916,13 @@ func TestKHIServer_EndpointExistsWithConfigs(t *testing.T) {
 			requestPath:    "/custom/base/path/foo/test.html",
 			wantCode:       200,
 		},
+		{
+			name:          "viewer mode should serve the metrics",
+			viewerMode:    true,
+			requestMethod: "GET",
+			requestPath:   "/metrics",
+			wantCode:      200,
+		},
 		{
 			name:          "viewer mode shouldn't serve task related endpoints",
 			viewerMode:    true,
@@ -772,6 +930,27 @@ func TestKHIServer_EndpointExistsWithConfigs(t *testing.T) {
 			requestPath:   "/api/v3/inspection",
 			wantCode:      404,
 		},
+		{
+			name:          "api only mode shouldn't serve the web UI",
+			apiOnly:       true,
+			requestMethod: "GET",
+			requestPath:   "/session/100",
+			wantCode:      404,
+		},
+		{
+			name:          "api only mode shouldn't serve the static resource",
+			apiOnly:       true,
+			requestMethod: "GET",
+			requestPath:   "/test.html",
+			wantCode:      404,
+		},
+		{
+			name:          "api only mode should serve the API",
+			apiOnly:       true,
+			requestMethod: "GET",
+			requestPath:   "/api/v3/inspection/types",
+			wantCode:      200,
+		},
 		{
 			name:           "viewer mode should serve the static resource with custom server base path",
 			viewerMode:     true,
@@ -795,6 +974,7 @@ func TestKHIServer_EndpointExistsWithConfigs(t *testing.T) {
 				StaticFolderPath: "../../dist",
 				ResourceMonitor:  &ResourceMonitorMock{UsedMemory: 1000},
 				ServerBasePath:   tc.serverBasePath,
+				APIOnly:          tc.apiOnly,
 			}
 			engine := CreateKHIServer(inspectionServer, &config)
 			req, _ := http.NewRequest(tc.requestMethod, tc.requestPath, bytes.NewReader([]byte{}))
@@ -867,10 +1047,13 @@ func TestKHIDirectFileUpload(t *testing.T) {
 		name              string
 		tokenID           string
 		content           string
+		gzipContent       bool
 		maxUploadFileSize int
-		wantCode          int
-		wantErr           bool
-		wantErrMsg        string
+		// maxUploadExpandedSize is the limit of the decompressed size. 0 means no limit.
+		maxUploadExpandedSize int
+		wantCode              int
+		wantErr               bool
+		wantErrMsg            string
 	}{
 		{
 			name:              "success",
@@ -898,6 +1081,26 @@ func TestKHIDirectFileUpload(t *testing.T) {
 			wantErr:           true,
 			wantErrMsg:        "missing upload-token-id",
 		},
+		{
+			name:                  "decompressed size exceeds the limit",
+			tokenID:               "test-token-3",
+			content:               strings.Repeat("a", 1024*1024),
+			gzipContent:           true,
+			maxUploadFileSize:     1024 * 1024 * 1024,
+			maxUploadExpandedSize: 1024,
+			wantCode:              413,
+			wantErr:               true,
+			wantErrMsg:            "the decompressed size of the uploaded file exceeds the limit",
+		},
+		{
+			name:                  "decompressed size within the limit",
+			tokenID:               "test-token-4",
+			content:               strings.Repeat("a", 1024),
+			gzipContent:           true,
+			maxUploadFileSize:     1024 * 1024 * 1024,
+			maxUploadExpandedSize: 1024,
+			wantCode:              200,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -924,6 +1127,22 @@ func TestKHIDirectFileUpload(t *testing.T) {
 			}
 			engine := CreateKHIServer(inspectionServer, &serverConfig)
 			parameters.Server.MaxUploadFileSizeInBytes = testutil.P(tc.maxUploadFileSize)
+			parameters.Server.MaxUploadExpandedSizeInBytes = testutil.P(tc.maxUploadExpandedSize)
+			defer func() {
+				parameters.Server.MaxUploadExpandedSizeInBytes = nil
+			}()
+			content := []byte(tc.content)
+			if tc.gzipContent {
+				var gzipped bytes.Buffer
+				gzipWriter := gzip.NewWriter(&gzipped)
+				if _, err := gzipWriter.Write(content); err != nil {
+					t.Fatal(err)
+				}
+				if err := gzipWriter.Close(); err != nil {
+					t.Fatal(err)
+				}
+				content = gzipped.Bytes()
+			}
 
 			var buf bytes.Buffer
 			writer := multipart.NewWriter(&buf)
@@ -932,7 +1151,7 @@ func TestKHIDirectFileUpload(t *testing.T) {
 			if err != nil {
 				t.Fatal(err)
 			}
-			_, err = fileWriter.Write([]byte(tc.content))
+			_, err = fileWriter.Write(content)
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -954,3 +1173,323 @@ func TestKHIDirectFileUpload(t *testing.T) {
 		})
 	}
 }
+
+func TestKHIServerStorageStatus(t *testing.T) {
+	testCases := []struct {
+		name          string
+		withRetention bool
+		wantCode      int
+	}{
+		{
+			name:          "retention enabled",
+			withRetention: true,
+			wantCode:      200,
+		},
+		{
+			name:     "retention disabled",
+			wantCode: 404,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			logger.InitGlobalKHILogger()
+			inspectionServer, err := createTestInspectionServer()
+			if err != nil {
+				t.Fatalf("unexpected error %s", err)
+			}
+			serverConfig := ServerConfig{
+				StaticFolderPath: "../../dist",
+				ResourceMonitor:  &ResourceMonitorMock{UsedMemory: 1000},
+				ServerBasePath:   "/foo",
+			}
+			if tc.withRetention {
+				serverConfig.RetentionCollector = retention.NewCollector(retention.Policy{MaxInspectionCount: 10}, inspectionServer, t.TempDir(), nil, time.Minute)
+			}
+			engine := CreateKHIServer(inspectionServer, &serverConfig)
+			recorder := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/foo/api/v3/storage", nil)
+			engine.ServeHTTP(recorder, req)
+			if recorder.Code != tc.wantCode {
+				t.Fatalf("got response code %d(%s), want %d", recorder.Code, recorder.Body.String(), tc.wantCode)
+			}
+			if tc.withRetention {
+				var status retention.Status
+				if err := json.Unmarshal(recorder.Body.Bytes(), &status); err != nil {
+					t.Fatal(err)
+				}
+				if status.MaxInspectionCount != 10 {
+					t.Errorf("got max inspection count %d, want 10", status.MaxInspectionCount)
+				}
+			}
+		})
+	}
+}
+
+func TestKHIDirectMultiFileUpload(t *testing.T) {
+	logger.InitGlobalKHILogger()
+	provider := upload.NewLocalUploadFileStoreProvider(t.TempDir())
+	store := upload.NewUploadFileStore(provider)
+	token := store.GetUploadToken("test-token", &upload.NopWaitUploadFileVerifier{})
+	serverConfig := ServerConfig{
+		ViewerMode:       false,
+		StaticFolderPath: "../../dist",
+		ResourceMonitor:  &ResourceMonitorMock{UsedMemory: 1000},
+		ServerBasePath:   "/foo",
+		UploadFileStore:  store,
+	}
+	inspectionServer, err := createTestInspectionServer()
+	if err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+	engine := CreateKHIServer(inspectionServer, &serverConfig)
+	parameters.Server.MaxUploadFileSizeInBytes = testutil.P(1024)
+
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	for _, name := range []string{"audit.log.1", "audit.log"} {
+		fileWriter, err := writer.CreateFormFile("file", name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := fileWriter.Write([]byte("content of " + name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writer.WriteField("upload-token-id", "test-token")
+	writer.Close()
+
+	recorder := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/foo/api/v3/upload", &buf)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	engine.ServeHTTP(recorder, req)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("got response code %d(%s), want %d", recorder.Code, recorder.Body.String(), http.StatusOK)
+	}
+
+	reader, err := provider.Read(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+	got := map[string]string{}
+	err = upload.WalkUploadedMembers(reader, "", func(name string, memberReader io.Reader) error {
+		content, err := io.ReadAll(memberReader)
+		got[name] = string(content)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"audit.log.1": "content of audit.log.1",
+		"audit.log":   "content of audit.log",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("uploaded files mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestNewInspectionRequest(t *testing.T) {
+	originalDefault := budget.Default
+	defer func() { budget.Default = originalDefault }()
+	budget.Default = &budget.Budget{MaxLogsPerQuery: 1000, LogCountAction: budget.LogCountActionSample}
+
+	testCases := []struct {
+		name    string
+		body    string
+		want    *inspection_task.InspectionRequest
+		wantErr bool
+	}{
+		{
+			name: "without budget",
+			body: `{"foo":"bar"}`,
+			want: &inspection_task.InspectionRequest{
+				Values: map[string]any{"foo": "bar"},
+			},
+		},
+		{
+			name: "with budget",
+			body: `{"foo":"bar","budget":{"maxLogsPerQuery":100,"logCountAction":"stop","maxWallTime":"15m"}}`,
+			want: &inspection_task.InspectionRequest{
+				Values: map[string]any{"foo": "bar"},
+				Budget: &budget.Budget{
+					MaxLogsPerQuery: 100,
+					LogCountAction:  budget.LogCountActionStop,
+					MaxWallTime:     15 * time.Minute,
+				},
+			},
+		},
+		{
+			name: "with volume estimation and sampling",
+			body: `{"foo":"bar","estimateVolume":true,"sampling":{"feature-a":{"everyNth":10,"maxLogsPerGroup":1000}}}`,
+			want: &inspection_task.InspectionRequest{
+				Values:         map[string]any{"foo": "bar"},
+				EstimateVolume: true,
+				Sampling: map[string]*logstore.Sampling{
+					"feature-a": {EveryNth: 10, MaxLogsPerGroup: 1000},
+				},
+			},
+		},
+		{
+			name: "with credential",
+			body: `{"foo":"bar","credential":{"impersonateServiceAccount":"khi@project.iam.gserviceaccount.com"}}`,
+			want: &inspection_task.InspectionRequest{
+				Values:     map[string]any{"foo": "bar"},
+				Credential: &credential.Credential{ImpersonateServiceAccount: "khi@project.iam.gserviceaccount.com"},
+			},
+		},
+		{
+			name:    "empty credential",
+			body:    `{"credential":{}}`,
+			wantErr: true,
+		},
+		{
+			name:    "malformed sampling",
+			body:    `{"sampling":{"feature-a":10}}`,
+			wantErr: true,
+		},
+		{
+			name:    "exceeding the server budget",
+			body:    `{"budget":{"maxLogsPerQuery":100000}}`,
+			wantErr: true,
+		},
+		{
+			name:    "malformed budget",
+			body:    `{"budget":{"maxLogsPerQuery":"many"}}`,
+			wantErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var body map[string]any
+			if err := json.Unmarshal([]byte(tc.body), &body); err != nil {
+				t.Fatal(err)
+			}
+			got, err := newInspectionRequest(body)
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("newInspectionRequest() returned nil error, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("newInspectionRequest() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestKHIServerAuthentication(t *testing.T) {
+	logger.InitGlobalKHILogger()
+	inspectionServer, err := createTestInspectionServer()
+	if err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+	jobScheduler, err := scheduler.NewScheduler(nil, inspectionServer, t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	serverConfig := ServerConfig{
+		StaticFolderPath:   "../../dist",
+		ResourceMonitor:    &ResourceMonitorMock{UsedMemory: 1000},
+		ServerBasePath:     "/foo",
+		RetentionCollector: retention.NewCollector(retention.Policy{MaxInspectionCount: 10}, inspectionServer, t.TempDir(), nil, time.Minute),
+		Scheduler:          jobScheduler,
+		Authenticator:      auth.NewStaticTokenAuthenticator(map[string]string{"alice-token": "alice", "bob-token": "bob", "admin-token": "admin"}),
+		AuthAdmins:         []string{"admin"},
+	}
+	engine := CreateKHIServer(inspectionServer, &serverConfig)
+	request := func(method string, path string, token string) *httptest.ResponseRecorder {
+		t.Helper()
+		recorder := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, path, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		engine.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	if code := request("GET", "/foo/api/v3/inspection/types", "").Code; code != http.StatusUnauthorized {
+		t.Errorf("request without token got %d, want %d", code, http.StatusUnauthorized)
+	}
+	if code := request("GET", "/foo/api/v3/inspection/types", "wrong-token").Code; code != http.StatusUnauthorized {
+		t.Errorf("request with a wrong token got %d, want %d", code, http.StatusUnauthorized)
+	}
+
+	created := request("POST", "/foo/api/v3/inspection/types/foo", "alice-token")
+	if created.Code != http.StatusAccepted {
+		t.Fatalf("creating an inspection got %d(%s), want %d", created.Code, created.Body.String(), http.StatusAccepted)
+	}
+	var response PostInspectionResponse
+	if err := json.Unmarshal(created.Body.Bytes(), &response); err != nil {
+		t.Fatal(err)
+	}
+	if owner := inspectionServer.GetInspection(response.InspectionID).Owner(); owner != "alice" {
+		t.Errorf("the created inspection is owned by %q, want alice", owner)
+	}
+
+	featuresPath := fmt.Sprintf("/foo/api/v3/inspection/%s/features", response.InspectionID)
+	testCases := []struct {
+		token    string
+		wantCode int
+	}{
+		{token: "alice-token", wantCode: http.StatusOK},
+		{token: "bob-token", wantCode: http.StatusNotFound},
+		{token: "admin-token", wantCode: http.StatusOK},
+	}
+	for _, tc := range testCases {
+		if code := request("GET", featuresPath, tc.token).Code; code != tc.wantCode {
+			t.Errorf("reading the features of alice's inspection with %s got %d, want %d", tc.token, code, tc.wantCode)
+		}
+	}
+	if code := request("DELETE", fmt.Sprintf("/foo/api/v3/inspection/%s", response.InspectionID), "bob-token").Code; code != http.StatusNotFound {
+		t.Errorf("deleting alice's inspection by bob got %d, want %d", code, http.StatusNotFound)
+	}
+
+	if code := request("GET", "/foo/api/v3/storage", "bob-token").Code; code != http.StatusForbidden {
+		t.Errorf("reading the storage status by bob got %d, want %d", code, http.StatusForbidden)
+	}
+	if code := request("GET", "/foo/api/v3/storage", "admin-token").Code; code != http.StatusOK {
+		t.Errorf("reading the storage status by admin got %d, want %d", code, http.StatusOK)
+	}
+	if code := request("GET", "/foo/metrics", "").Code; code != http.StatusUnauthorized {
+		t.Errorf("reading the metrics without token got %d, want %d", code, http.StatusUnauthorized)
+	}
+	if code := request("GET", "/foo/metrics", "bob-token").Code; code != http.StatusOK {
+		t.Errorf("reading the metrics by bob got %d, want %d", code, http.StatusOK)
+	}
+	publicMetricsConfig := serverConfig
+	publicMetricsConfig.MetricsPublic = true
+	publicMetricsRecorder := httptest.NewRecorder()
+	publicMetricsRequest, _ := http.NewRequest("GET", "/foo/metrics", nil)
+	CreateKHIServer(inspectionServer, &publicMetricsConfig).ServeHTTP(publicMetricsRecorder, publicMetricsRequest)
+	if publicMetricsRecorder.Code != http.StatusOK {
+		t.Errorf("reading the public metrics without token got %d, want %d", publicMetricsRecorder.Code, http.StatusOK)
+	}
+	if code := request("GET", "/foo/api/v3/schedules", "bob-token").Code; code != http.StatusForbidden {
+		t.Errorf("reading the schedule status by bob got %d, want %d", code, http.StatusForbidden)
+	}
+	if code := request("GET", "/foo/api/v3/schedules", "admin-token").Code; code != http.StatusOK {
+		t.Errorf("reading the schedule status by admin got %d, want %d", code, http.StatusOK)
+	}
+
+	go func() {
+		popup.Instance.ShowPopup("alice", testPopupForm{})
+	}()
+	<-time.After(time.Second)
+	if body := request("GET", "/foo/api/v3/popup", "bob-token").Body.String(); body != "" {
+		t.Errorf("the popup shown to alice was returned for bob: %s", body)
+	}
+	currentPopup := popup.Instance.GetCurrentPopup("alice")
+	if currentPopup == nil {
+		t.Fatal("the popup shown to alice was not found")
+	}
+	if body := request("GET", "/foo/api/v3/popup", "alice-token").Body.String(); !strings.Contains(body, currentPopup.Id) {
+		t.Errorf("the popup shown to alice was not returned for alice: %s", body)
+	}
+	popup.Instance.Answer("alice", &popup.PopupAnswerResponse{Id: currentPopup.Id})
+}
@@ -55,3 +55,8 @@ type GetInspectionFeatureResponse struct {
 }
 
 type PostInspectionDryRunRequest = map[string]any
+
+// PostInspectionMergeRequest is the type of the request for /api/v3/inspection/merge
+type PostInspectionMergeRequest struct {
+	InspectionIDs []string `json:"inspectionIDs"`
+}
@@ -0,0 +1,242 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package upload
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/parameters"
+	"github.com/klauspost/compress/zstd"
+)
+
+// maxArchiveDepth is the maximum nesting depth of archives. e.g. `logs.tar.gz` containing `audit.log.1.gz` has the depth 3.
+const maxArchiveDepth = 4
+
+// archiveHeaderSize is the byte count needed to detect the format. The tar magic is placed at the offset 257.
+const archiveHeaderSize = 262
+
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+	zipMagic  = []byte{'P', 'K', 0x03, 0x04}
+	tarMagic  = []byte("ustar")
+)
+
+// ErrNoUploadedMember is returned when an uploaded archive contains no regular file.
+var ErrNoUploadedMember = errors.New("no file was found in the uploaded file")
+
+// ErrExpandedSizeExceeded is returned when the decompressed size of an uploaded file exceeds the limit given with `--max-upload-expanded-size-in-bytes`.
+var ErrExpandedSizeExceeded = errors.New("the decompressed size of the uploaded file exceeds the limit")
+
+// expandedSizeLimiter counts the bytes produced by every decompressor used in a walk and fails the walk when the total exceeds the limit.
+// A limit less than or equal to 0 means no limit.
+type expandedSizeLimiter struct {
+	limit    int64
+	expanded int64
+}
+
+// wrap returns the reader counting the bytes read from the given decompressed reader.
+func (l *expandedSizeLimiter) wrap(reader io.Reader) io.Reader {
+	if l.limit <= 0 {
+		return reader
+	}
+	return &expandedSizeLimitedReader{reader: reader, limiter: l}
+}
+
+type expandedSizeLimitedReader struct {
+	reader  io.Reader
+	limiter *expandedSizeLimiter
+}
+
+// Read implements io.Reader.
+func (r *expandedSizeLimitedReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.limiter.expanded += int64(n)
+	if r.limiter.expanded > r.limiter.limit {
+		return n, fmt.Errorf("%w (%d bytes)", ErrExpandedSizeExceeded, r.limiter.limit)
+	}
+	return n, err
+}
+
+// UploadedMemberWalkFunc is called for each member of an uploaded file with the decompressed content.
+// The reader is only valid until the function returns.
+type UploadedMemberWalkFunc = func(name string, reader io.Reader) error
+
+// WalkUploadedMembers calls fn for each plain file in the uploaded file read from the reader.
+// Gzip and zstd compressed files are decompressed, and every regular file in tar and zip archives is visited in the order stored in the archive.
+// Archives can be nested, like a tar archive containing gzip compressed files. A file not in these formats is visited as is with the given name.
+// The total decompressed size is limited with `--max-upload-expanded-size-in-bytes` and ErrExpandedSizeExceeded is returned when it exceeds the limit.
+func WalkUploadedMembers(reader io.Reader, name string, fn UploadedMemberWalkFunc) error {
+	limit := int64(0)
+	if parameters.Server.MaxUploadExpandedSizeInBytes != nil {
+		limit = int64(*parameters.Server.MaxUploadExpandedSizeInBytes)
+	}
+	return walkUploadedMembersWithLimit(reader, name, limit, fn)
+}
+
+// CheckExpandedSize decompresses every member of the uploaded file read from the reader and returns ErrExpandedSizeExceeded when the total size exceeds the limit.
+// Other errors are left to the verifier of the upload and they are not returned.
+func CheckExpandedSize(reader io.Reader, name string) error {
+	err := WalkUploadedMembers(reader, name, func(name string, reader io.Reader) error {
+		_, err := io.Copy(io.Discard, reader)
+		return err
+	})
+	if errors.Is(err, ErrExpandedSizeExceeded) {
+		return err
+	}
+	return nil
+}
+
+func walkUploadedMembersWithLimit(reader io.Reader, name string, limit int64, fn UploadedMemberWalkFunc) error {
+	visited := 0
+	limiter := &expandedSizeLimiter{limit: limit}
+	err := walkUploadedMembers(reader, name, 0, limiter, func(name string, reader io.Reader) error {
+		visited++
+		return fn(name, reader)
+	})
+	if err != nil {
+		return err
+	}
+	if visited == 0 {
+		return ErrNoUploadedMember
+	}
+	return nil
+}
+
+func walkUploadedMembers(reader io.Reader, name string, depth int, limiter *expandedSizeLimiter, fn UploadedMemberWalkFunc) error {
+	if depth > maxArchiveDepth {
+		return fmt.Errorf("%s is nested too deeply. archives can be nested up to %d levels", name, maxArchiveDepth)
+	}
+	bufferedReader := bufio.NewReaderSize(reader, archiveHeaderSize)
+	header, err := bufferedReader.Peek(archiveHeaderSize)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	switch {
+	case bytes.HasPrefix(header, gzipMagic):
+		gzipReader, err := gzip.NewReader(bufferedReader)
+		if err != nil {
+			return fmt.Errorf("failed to read gzip file %s: %w", name, err)
+		}
+		defer gzipReader.Close()
+		return walkUploadedMembers(limiter.wrap(gzipReader), strings.TrimSuffix(name, ".gz"), depth+1, limiter, fn)
+	case bytes.HasPrefix(header, zstdMagic):
+		zstdReader, err := zstd.NewReader(bufferedReader)
+		if err != nil {
+			return fmt.Errorf("failed to read zstd file %s: %w", name, err)
+		}
+		defer zstdReader.Close()
+		return walkUploadedMembers(limiter.wrap(zstdReader), strings.TrimSuffix(name, ".zst"), depth+1, limiter, fn)
+	case bytes.HasPrefix(header, zipMagic):
+		return walkZipMembers(bufferedReader, name, depth, limiter, fn)
+	case len(header) >= archiveHeaderSize && bytes.Equal(header[257:262], tarMagic):
+		tarReader := tar.NewReader(bufferedReader)
+		for {
+			entry, err := tarReader.Next()
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("failed to read tar archive %s: %w", name, err)
+			}
+			if entry.Typeflag != tar.TypeReg {
+				continue
+			}
+			err = walkUploadedMembers(tarReader, path.Join(name, entry.Name), depth+1, limiter, fn)
+			if err != nil {
+				return err
+			}
+		}
+	default:
+		return fn(name, bufferedReader)
+	}
+}
+
+// walkZipMembers reads a zip archive. The archive is written to a temporary file in `--temporary-folder` because zip needs random access.
+func walkZipMembers(reader io.Reader, name string, depth int, limiter *expandedSizeLimiter, fn UploadedMemberWalkFunc) error {
+	temporaryFolder := "/tmp"
+	if parameters.Common.TemporaryFolder != nil {
+		temporaryFolder = *parameters.Common.TemporaryFolder
+	}
+	tmpFile, err := os.CreateTemp(temporaryFolder, "khi-upload-*.zip")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpFile.Name())
+	defer tmpFile.Close()
+	size, err := io.Copy(tmpFile, reader)
+	if err != nil {
+		return err
+	}
+	zipReader, err := zip.NewReader(tmpFile, size)
+	if err != nil {
+		return fmt.Errorf("failed to read zip archive %s: %w", name, err)
+	}
+	for _, file := range zipReader.File {
+		if file.FileInfo().IsDir() {
+			continue
+		}
+		err := func() error {
+			memberReader, err := file.Open()
+			if err != nil {
+				return fmt.Errorf("failed to read %s in zip archive %s: %w", file.Name, name, err)
+			}
+			defer memberReader.Close()
+			return walkUploadedMembers(limiter.wrap(memberReader), path.Join(name, file.Name), depth+1, limiter, fn)
+		}()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UploadedFile is a file given in a multi file upload.
+type UploadedFile struct {
+	Name   string
+	Size   int64
+	Reader io.Reader
+}
+
+// WriteMultiFileArchive writes the given files in a tar archive. Files uploaded together for a token are stored in this form and read with WalkUploadedMembers.
+func WriteMultiFileArchive(writer io.Writer, files []UploadedFile) error {
+	tarWriter := tar.NewWriter(writer)
+	for _, file := range files {
+		err := tarWriter.WriteHeader(&tar.Header{
+			Typeflag: tar.TypeReg,
+			Name:     path.Base(file.Name),
+			Size:     file.Size,
+			Mode:     0600,
+			Format:   tar.FormatPAX,
+		})
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(tarWriter, file.Reader); err != nil {
+			return err
+		}
+	}
+	return tarWriter.Close()
+}
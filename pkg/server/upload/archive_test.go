@@ -0,0 +1,290 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package upload
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/klauspost/compress/zstd"
+)
+
+type walkedMember struct {
+	Name    string
+	Content string
+}
+
+func mustGzip(t *testing.T, content string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write([]byte(content)); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func mustZstd(t *testing.T, content string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	writer, err := zstd.NewWriter(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := writer.Write([]byte(content)); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func mustTar(t *testing.T, files map[string][]byte, order []string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	writer := tar.NewWriter(&buf)
+	if err := writer.WriteHeader(&tar.Header{Typeflag: tar.TypeDir, Name: "logs/", Mode: 0755}); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range order {
+		if err := writer.WriteHeader(&tar.Header{Typeflag: tar.TypeReg, Name: name, Size: int64(len(files[name])), Mode: 0644}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := writer.Write(files[name]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func mustZip(t *testing.T, files map[string][]byte, order []string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	writer := zip.NewWriter(&buf)
+	for _, name := range order {
+		fileWriter, err := writer.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := fileWriter.Write(files[name]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestWalkUploadedMembers(t *testing.T) {
+	testCases := []struct {
+		name    string
+		data    func(t *testing.T) []byte
+		want    []walkedMember
+		wantErr error
+	}{
+		{
+			name: "plain file",
+			data: func(t *testing.T) []byte { return []byte("foo") },
+			want: []walkedMember{{Name: "upload", Content: "foo"}},
+		},
+		{
+			name: "empty plain file",
+			data: func(t *testing.T) []byte { return []byte{} },
+			want: []walkedMember{{Name: "upload", Content: ""}},
+		},
+		{
+			name: "gzip",
+			data: func(t *testing.T) []byte { return mustGzip(t, "foo") },
+			want: []walkedMember{{Name: "upload", Content: "foo"}},
+		},
+		{
+			name: "zstd",
+			data: func(t *testing.T) []byte { return mustZstd(t, "foo") },
+			want: []walkedMember{{Name: "upload", Content: "foo"}},
+		},
+		{
+			name: "tar.gz containing gzip files",
+			data: func(t *testing.T) []byte {
+				return mustGzip(t, string(mustTar(t, map[string][]byte{
+					"logs/audit.log":   []byte("current"),
+					"logs/audit.log.1": mustGzip(t, "rotated"),
+				}, []string{"logs/audit.log.1", "logs/audit.log"})))
+			},
+			want: []walkedMember{
+				{Name: "upload/logs/audit.log.1", Content: "rotated"},
+				{Name: "upload/logs/audit.log", Content: "current"},
+			},
+		},
+		{
+			name: "zip containing zstd files",
+			data: func(t *testing.T) []byte {
+				return mustZip(t, map[string][]byte{
+					"a.log":     []byte("a"),
+					"b.log.zst": mustZstd(t, "b"),
+				}, []string{"a.log", "b.log.zst"})
+			},
+			want: []walkedMember{
+				{Name: "upload/a.log", Content: "a"},
+				{Name: "upload/b.log", Content: "b"},
+			},
+		},
+		{
+			name: "tar without regular files",
+			data: func(t *testing.T) []byte {
+				return mustTar(t, map[string][]byte{}, []string{})
+			},
+			wantErr: ErrNoUploadedMember,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := []walkedMember{}
+			err := WalkUploadedMembers(bytes.NewReader(tc.data(t)), "upload", func(name string, reader io.Reader) error {
+				content, err := io.ReadAll(reader)
+				if err != nil {
+					return err
+				}
+				got = append(got, walkedMember{Name: name, Content: string(content)})
+				return nil
+			})
+			if tc.wantErr != nil {
+				if !errors.Is(err, tc.wantErr) {
+					t.Errorf("WalkUploadedMembers() returned %v, want %v", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("walked members mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestWriteMultiFileArchive(t *testing.T) {
+	var buf bytes.Buffer
+	err := WriteMultiFileArchive(&buf, []UploadedFile{
+		{Name: "audit.log", Size: 3, Reader: strings.NewReader("foo")},
+		{Name: "dir/audit.log.1.gz", Size: int64(len(mustGzip(t, "bar"))), Reader: bytes.NewReader(mustGzip(t, "bar"))},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := []walkedMember{}
+	err = WalkUploadedMembers(&buf, "token", func(name string, reader io.Reader) error {
+		content, err := io.ReadAll(reader)
+		if err != nil {
+			return err
+		}
+		got = append(got, walkedMember{Name: name, Content: string(content)})
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []walkedMember{
+		{Name: "token/audit.log", Content: "foo"},
+		{Name: "token/audit.log.1", Content: "bar"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("walked members mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestWalkUploadedMembersWithLimit(t *testing.T) {
+	testCases := []struct {
+		name    string
+		data    func(t *testing.T) []byte
+		limit   int64
+		wantErr bool
+	}{
+		{
+			name: "gzip within the limit",
+			data: func(t *testing.T) []byte {
+				return mustGzip(t, strings.Repeat("a", 100))
+			},
+			limit: 100,
+		},
+		{
+			name: "gzip exceeding the limit",
+			data: func(t *testing.T) []byte {
+				return mustGzip(t, strings.Repeat("a", 101))
+			},
+			limit:   100,
+			wantErr: true,
+		},
+		{
+			name: "zip members exceeding the limit in total",
+			data: func(t *testing.T) []byte {
+				return mustZip(t, map[string][]byte{
+					"a.log": []byte(strings.Repeat("a", 60)),
+					"b.log": []byte(strings.Repeat("b", 60)),
+				}, []string{"a.log", "b.log"})
+			},
+			limit:   100,
+			wantErr: true,
+		},
+		{
+			name: "zstd exceeding the limit",
+			data: func(t *testing.T) []byte {
+				return mustZstd(t, strings.Repeat("a", 101))
+			},
+			limit:   100,
+			wantErr: true,
+		},
+		{
+			name: "plain file is not limited",
+			data: func(t *testing.T) []byte {
+				return []byte(strings.Repeat("a", 101))
+			},
+			limit: 100,
+		},
+		{
+			name: "no limit",
+			data: func(t *testing.T) []byte {
+				return mustGzip(t, strings.Repeat("a", 101))
+			},
+			limit: 0,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := walkUploadedMembersWithLimit(bytes.NewReader(tc.data(t)), "upload", tc.limit, func(name string, reader io.Reader) error {
+				_, err := io.Copy(io.Discard, reader)
+				return err
+			})
+			if gotErr := errors.Is(err, ErrExpandedSizeExceeded); gotErr != tc.wantErr {
+				t.Errorf("walkUploadedMembersWithLimit() returned %v, want ErrExpandedSizeExceeded: %v", err, tc.wantErr)
+			}
+		})
+	}
+}
@@ -162,6 +162,35 @@ func (s *UploadFileStore) SetResultOnCompletedUpload(token UploadToken, uploadEr
 	return nil
 }
 
+// IssuedTokenIDs returns the IDs of every upload token issued from this store.
+func (s *UploadFileStore) IssuedTokenIDs() []string {
+	s.resultLock.RLock()
+	defer s.resultLock.RUnlock()
+	result := make([]string, 0, len(s.results))
+	for id := range s.results {
+		result = append(result, id)
+	}
+	return result
+}
+
+// Remove forgets the upload token with the given ID and removes its file when the store provider supports deletion.
+func (s *UploadFileStore) Remove(id string) error {
+	token := s.StoreProvider.GetUploadToken(id)
+	s.resultLock.Lock()
+	s.verifierLock.Lock()
+	s.tokenHashLock.Lock()
+	delete(s.results, id)
+	delete(s.verifiers, id)
+	delete(s.tokenHashes, token.GetHash())
+	s.tokenHashLock.Unlock()
+	s.verifierLock.Unlock()
+	s.resultLock.Unlock()
+	if deletable, ok := s.StoreProvider.(DeletableUploadFileStoreProvider); ok {
+		return deletable.Delete(token)
+	}
+	return nil
+}
+
 // ensureIssuedToken verify given UploadToken is issued from GetUploadToken and
 func (s *UploadFileStore) ensureIssuedToken(token UploadToken) error {
 	s.tokenHashLock.RLock()
@@ -20,6 +20,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 )
 
 type UploadFileStoreProvider interface {
@@ -35,6 +36,24 @@ type DirectWritableUploadFileStoreProvider interface {
 	Write(token UploadToken, reader io.Reader) error
 }
 
+// StoredFile is the information of a file stored in an UploadFileStoreProvider.
+type StoredFile struct {
+	// ID is the ID of the upload token associated with the file.
+	ID string
+	// Size is the size of the file in bytes.
+	Size int64
+	// ModTime is the last modified time of the file.
+	ModTime time.Time
+}
+
+// DeletableUploadFileStoreProvider is an UploadFileStoreProvider able to list and delete the stored files.
+type DeletableUploadFileStoreProvider interface {
+	// List returns the information of every file stored in the provider.
+	List() ([]StoredFile, error)
+	// Delete removes the file with the given token. It returns nil when the file doesn't exist.
+	Delete(token UploadToken) error
+}
+
 // LocalUploadFileStoreProvider is an implementation of UploadFileStore that stores files
 // in the local file system.
 type LocalUploadFileStoreProvider struct {
@@ -93,6 +112,49 @@ func (l *LocalUploadFileStoreProvider) Write(token UploadToken, reader io.Reader
 	return nil
 }
 
+// List implements DeletableUploadFileStoreProvider.
+func (l *LocalUploadFileStoreProvider) List() ([]StoredFile, error) {
+	entries, err := os.ReadDir(l.directoryPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []StoredFile{}, nil
+		}
+		return nil, err
+	}
+	result := []StoredFile{}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		result = append(result, StoredFile{
+			ID:      entry.Name(),
+			Size:    info.Size(),
+			ModTime: info.ModTime(),
+		})
+	}
+	return result, nil
+}
+
+// Delete implements DeletableUploadFileStoreProvider.
+func (l *LocalUploadFileStoreProvider) Delete(token UploadToken) error {
+	err := l.validateTokenFormat(token)
+	if err != nil {
+		return err
+	}
+	err = os.Remove(filepath.Join(l.directoryPath, token.GetID()))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
 func (l *LocalUploadFileStoreProvider) ensureFolderExists() error {
 	// Create the directory (and any parent directories) if it doesn't exist.
 	// os.MkdirAll will not return an error if the directory already exists.
@@ -109,3 +171,4 @@ func (l *LocalUploadFileStoreProvider) validateTokenFormat(token UploadToken) er
 
 var _ UploadFileStoreProvider = &LocalUploadFileStoreProvider{}
 var _ DirectWritableUploadFileStoreProvider = &LocalUploadFileStoreProvider{}
+var _ DeletableUploadFileStoreProvider = &LocalUploadFileStoreProvider{}
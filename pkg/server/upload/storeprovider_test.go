@@ -114,3 +114,44 @@ func TestLocalUploadFileStoreProvider_Essential(t *testing.T) {
 		}
 	})
 }
+
+func TestLocalUploadFileStoreProvider_ListAndDelete(t *testing.T) {
+	store := NewLocalUploadFileStoreProvider(t.TempDir())
+	token := store.GetUploadToken("test-token")
+	if err := store.Write(token, strings.NewReader("12345")); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	files, err := store.List()
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(files) != 1 || files[0].ID != "test-token" || files[0].Size != 5 {
+		t.Errorf("unexpected stored files: %+v", files)
+	}
+
+	if err := store.Delete(token); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if err := store.Delete(token); err != nil {
+		t.Errorf("Delete for a missing file must not fail: %v", err)
+	}
+	files, err = store.List()
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(files) != 0 {
+		t.Errorf("stored files remain after Delete: %+v", files)
+	}
+}
+
+func TestLocalUploadFileStoreProvider_ListWithoutFolder(t *testing.T) {
+	store := NewLocalUploadFileStoreProvider(t.TempDir() + "/not-created")
+	files, err := store.List()
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(files) != 0 {
+		t.Errorf("unexpected stored files: %+v", files)
+	}
+}
@@ -19,6 +19,8 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
+	"path"
 	"time"
 )
 
@@ -44,6 +46,8 @@ func (n *NopWaitUploadFileVerifier) Verify(storeProvider UploadFileStoreProvider
 
 var _ UploadFileVerifier = &NopWaitUploadFileVerifier{}
 
+// JSONLineUploadFileVerifier verifies every line in the uploaded file is a valid JSON.
+// Compressed files and archives are accepted and each file in them is verified. See WalkUploadedMembers for the supported formats.
 type JSONLineUploadFileVerifier struct {
 	MaxLineSizeInBytes int
 }
@@ -56,8 +60,18 @@ func (j *JSONLineUploadFileVerifier) Verify(storeProvider UploadFileStoreProvide
 	}
 	defer reader.Close()
 
+	return WalkUploadedMembers(reader, token.GetID(), func(name string, memberReader io.Reader) error {
+		err := j.verifyMember(memberReader)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path.Base(name), err)
+		}
+		return nil
+	})
+}
+
+func (j *JSONLineUploadFileVerifier) verifyMember(reader io.Reader) error {
 	scanner := bufio.NewScanner(reader)
-	scanner.Buffer(make([]byte, j.MaxLineSizeInBytes), j.MaxLineSizeInBytes)
+	scanner.Buffer(make([]byte, 0, min(j.MaxLineSizeInBytes, bufio.MaxScanTokenSize)), j.MaxLineSizeInBytes)
 	lineNumber := 0
 	for scanner.Scan() {
 		lineNumber++
@@ -88,3 +102,32 @@ func (j *JSONLineUploadFileVerifier) Verify(storeProvider UploadFileStoreProvide
 }
 
 var _ UploadFileVerifier = &JSONLineUploadFileVerifier{}
+
+// JSONDocumentUploadFileVerifier verifies the uploaded file is a sequence of JSON documents (e.g. the output of `kubectl get -o json`).
+// Compressed files and archives are accepted and each file in them is verified. See WalkUploadedMembers for the supported formats.
+type JSONDocumentUploadFileVerifier struct {
+}
+
+func (j *JSONDocumentUploadFileVerifier) Verify(storeProvider UploadFileStoreProvider, token UploadToken) error {
+	reader, err := storeProvider.Read(token)
+	if err != nil {
+		return fmt.Errorf("failed to read the uploded file")
+	}
+	defer reader.Close()
+
+	return WalkUploadedMembers(reader, token.GetID(), func(name string, memberReader io.Reader) error {
+		decoder := json.NewDecoder(memberReader)
+		for documentNumber := 1; ; documentNumber++ {
+			var document json.RawMessage
+			err := decoder.Decode(&document)
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("%s: invalid JSON document #%d: %w", path.Base(name), documentNumber, err)
+			}
+		}
+	})
+}
+
+var _ UploadFileVerifier = &JSONDocumentUploadFileVerifier{}
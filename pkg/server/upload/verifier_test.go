@@ -78,3 +78,64 @@ func TestJSONLineUploadFileVerifier(t *testing.T) {
 		})
 	}
 }
+
+func TestJSONLineUploadFileVerifierWithArchive(t *testing.T) {
+	verifier := &JSONLineUploadFileVerifier{MaxLineSizeInBytes: 1024 * 1024}
+	data := mustTar(t, map[string][]byte{
+		"audit.log":      []byte(`{"name": "Alice"}`),
+		"audit.log.1.gz": mustGzip(t, "{\"name\": \"Bob\"}\n{invalid json}"),
+	}, []string{"audit.log", "audit.log.1.gz"})
+	provider := &MockLocalUploadFileStoreProvider{Data: string(data)}
+
+	err := verifier.Verify(provider, &DirectUploadToken{ID: "test"})
+
+	wantErr := "audit.log.1: invalid JSON on line 2"
+	if err == nil || !strings.Contains(err.Error(), wantErr) {
+		t.Errorf("Expected error to contain: %q, but got: %v", wantErr, err)
+	}
+}
+
+func TestJSONDocumentUploadFileVerifier(t *testing.T) {
+	tests := []struct {
+		name        string
+		data        string
+		expectedErr string
+	}{
+		{
+			name: "Single document spanning multiple lines",
+			data: `{
+  "kind": "List",
+  "items": []
+}`,
+		},
+		{
+			name: "Concatenated documents",
+			data: `{"kind": "List"} {"kind": "List"}`,
+		},
+		{
+			name: "Empty File",
+			data: "",
+		},
+		{
+			name:        "Invalid JSON",
+			data:        `{"kind": "List"} {invalid json}`,
+			expectedErr: "test: invalid JSON document #2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verifier := &JSONDocumentUploadFileVerifier{}
+			provider := &MockLocalUploadFileStoreProvider{Data: tt.data}
+			err := verifier.Verify(provider, &DirectUploadToken{ID: "test"})
+
+			if tt.expectedErr == "" {
+				if err != nil {
+					t.Errorf("Unexpected error: %v", err)
+				}
+			} else if err == nil || !strings.Contains(err.Error(), tt.expectedErr) {
+				t.Errorf("Expected error to contain: %q, but got: %v", tt.expectedErr, err)
+			}
+		})
+	}
+}
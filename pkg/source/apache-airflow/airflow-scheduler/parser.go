@@ -20,10 +20,10 @@ import (
 	"regexp"
 
 	"github.com/GoogleCloudPlatform/khi/pkg/log"
+	"github.com/GoogleCloudPlatform/khi/pkg/log/logstore"
 	"github.com/GoogleCloudPlatform/khi/pkg/model"
 	"github.com/GoogleCloudPlatform/khi/pkg/model/enum"
 	"github.com/GoogleCloudPlatform/khi/pkg/model/history"
-	"github.com/GoogleCloudPlatform/khi/pkg/model/history/grouper"
 	"github.com/GoogleCloudPlatform/khi/pkg/model/history/resourcepath"
 	"github.com/GoogleCloudPlatform/khi/pkg/parser"
 	airflow "github.com/GoogleCloudPlatform/khi/pkg/source/apache-airflow"
@@ -68,11 +68,11 @@ var (
 // - success
 // - failed
 type AirflowSchedulerParser struct {
-	queryTaskId   taskid.TaskReference[[]*log.Log]
+	queryTaskId   taskid.TaskReference[*logstore.Store]
 	targetLogType enum.LogType
 }
 
-func NewAirflowSchedulerParser(queryTaskId taskid.TaskReference[[]*log.Log], targetLogType enum.LogType) *AirflowSchedulerParser {
+func NewAirflowSchedulerParser(queryTaskId taskid.TaskReference[*logstore.Store], targetLogType enum.LogType) *AirflowSchedulerParser {
 	return &AirflowSchedulerParser{queryTaskId: queryTaskId, targetLogType: targetLogType}
 }
 
@@ -87,10 +87,6 @@ func (*AirflowSchedulerParser) Dependencies() []taskid.UntypedTaskReference {
 	return []taskid.UntypedTaskReference{}
 }
 
-func (*AirflowSchedulerParser) Grouper() grouper.LogGrouper {
-	return grouper.AllDependentLogGrouper
-}
-
 func (*AirflowSchedulerParser) Description() string {
 	return `Airflow Scheduler logs contain information related to the scheduling of TaskInstances, making it an ideal source for understanding the lifecycle of TaskInstances.`
 }
@@ -99,7 +95,7 @@ func (*AirflowSchedulerParser) GetParserName() string {
 	return "Airflow Scheduler"
 }
 
-func (a *AirflowSchedulerParser) LogTask() taskid.TaskReference[[]*log.Log] {
+func (a *AirflowSchedulerParser) LogTask() taskid.TaskReference[*logstore.Store] {
 	return a.queryTaskId
 }
 
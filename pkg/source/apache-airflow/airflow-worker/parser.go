@@ -21,10 +21,10 @@ import (
 	"strings"
 
 	"github.com/GoogleCloudPlatform/khi/pkg/log"
+	"github.com/GoogleCloudPlatform/khi/pkg/log/logstore"
 	"github.com/GoogleCloudPlatform/khi/pkg/model"
 	"github.com/GoogleCloudPlatform/khi/pkg/model/enum"
 	"github.com/GoogleCloudPlatform/khi/pkg/model/history"
-	"github.com/GoogleCloudPlatform/khi/pkg/model/history/grouper"
 	"github.com/GoogleCloudPlatform/khi/pkg/model/history/resourcepath"
 	"github.com/GoogleCloudPlatform/khi/pkg/parser"
 	apacheairflow "github.com/GoogleCloudPlatform/khi/pkg/source/apache-airflow"
@@ -48,11 +48,11 @@ var (
 var _ parser.Parser = &AirflowWorkerParser{}
 
 type AirflowWorkerParser struct {
-	queryTaskId   taskid.TaskReference[[]*log.Log]
+	queryTaskId   taskid.TaskReference[*logstore.Store]
 	targetLogType enum.LogType
 }
 
-func NewAirflowWorkerParser(queryTaskId taskid.TaskReference[[]*log.Log], targetLogType enum.LogType) *AirflowWorkerParser {
+func NewAirflowWorkerParser(queryTaskId taskid.TaskReference[*logstore.Store], targetLogType enum.LogType) *AirflowWorkerParser {
 	return &AirflowWorkerParser{
 		queryTaskId:   queryTaskId,
 		targetLogType: targetLogType,
@@ -69,11 +69,6 @@ func (*AirflowWorkerParser) Dependencies() []taskid.UntypedTaskReference {
 	return []taskid.UntypedTaskReference{}
 }
 
-// DependsOnPast implements parser.Parser.
-func (*AirflowWorkerParser) Grouper() grouper.LogGrouper {
-	return grouper.AllDependentLogGrouper
-}
-
 // Description implements parser.Parser.
 func (*AirflowWorkerParser) Description() string {
 	return `Airflow Worker logs contain information related to the execution of TaskInstances. By including these logs, you can gain insights into where and how each TaskInstance was executed.`
@@ -85,7 +80,7 @@ func (*AirflowWorkerParser) GetParserName() string {
 }
 
 // LogTask implements parser.Parser.
-func (a *AirflowWorkerParser) LogTask() taskid.TaskReference[[]*log.Log] {
+func (a *AirflowWorkerParser) LogTask() taskid.TaskReference[*logstore.Store] {
 	return a.queryTaskId
 }
 
@@ -20,10 +20,10 @@ import (
 	"strings"
 
 	"github.com/GoogleCloudPlatform/khi/pkg/log"
+	"github.com/GoogleCloudPlatform/khi/pkg/log/logstore"
 	"github.com/GoogleCloudPlatform/khi/pkg/model"
 	"github.com/GoogleCloudPlatform/khi/pkg/model/enum"
 	"github.com/GoogleCloudPlatform/khi/pkg/model/history"
-	"github.com/GoogleCloudPlatform/khi/pkg/model/history/grouper"
 	"github.com/GoogleCloudPlatform/khi/pkg/model/history/resourcepath"
 	"github.com/GoogleCloudPlatform/khi/pkg/parser"
 	"github.com/GoogleCloudPlatform/khi/pkg/task/taskid"
@@ -31,11 +31,11 @@ import (
 
 type AirflowDagProcessorParser struct {
 	dagFilePath   string
-	logTask       taskid.TaskReference[[]*log.Log]
+	logTask       taskid.TaskReference[*logstore.Store]
 	targetLogType enum.LogType
 }
 
-func NewAirflowDagProcessorParser(dagFilePath string, logTask taskid.TaskReference[[]*log.Log], targetLogType enum.LogType) *AirflowDagProcessorParser {
+func NewAirflowDagProcessorParser(dagFilePath string, logTask taskid.TaskReference[*logstore.Store], targetLogType enum.LogType) *AirflowDagProcessorParser {
 	return &AirflowDagProcessorParser{
 		dagFilePath:   dagFilePath,
 		logTask:       logTask,
@@ -62,12 +62,7 @@ func (*AirflowDagProcessorParser) GetParserName() string {
 	return "Airflow DagProcessorManager"
 }
 
-// Grouper implements parser.Parser.
-func (*AirflowDagProcessorParser) Grouper() grouper.LogGrouper {
-	return grouper.AllDependentLogGrouper
-}
-
-func (a *AirflowDagProcessorParser) LogTask() taskid.TaskReference[[]*log.Log] {
+func (a *AirflowDagProcessorParser) LogTask() taskid.TaskReference[*logstore.Store] {
 	return a.logTask
 }
 
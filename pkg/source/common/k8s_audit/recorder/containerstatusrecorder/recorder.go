@@ -74,8 +74,8 @@ func recordChangeSetForLog(ctx context.Context, resourcePath string, l *types.Au
 		}
 		isInitContainer := i >= len(pod.Status.ContainerStatuses)
 		cpath := resourcepath.Container(l.Operation.Namespace, l.Operation.Name, status.Name)
-		changed := builder.ClusterResource.ContainerStatuses.IsNewChange(l.Operation.Namespace, l.Operation.Name, status.Name, status)
-		tb := builder.GetTimelineBuilder(cpath.Path)
+		changed := builder.ClusterResourceOf(l.Log.ClusterName).ContainerStatuses.IsNewChange(l.Operation.Namespace, l.Operation.Name, status.Name, status)
+		tb := builder.GetTimelineBuilder(resourcepath.ClusterScopedPath(l.Log.ClusterName, cpath.Path))
 		last := tb.GetLatestRevision()
 		if changed {
 			switch {
@@ -94,7 +94,7 @@ func recordChangeSetForLog(ctx context.Context, l *types.AuditLogParserInput, pr
 		// records Ips used in Pods. IP can be read from Pod manifest, but it can be ignored when users didn't turn on DATA_WRITE audit log, but endpoint slice update will be recorded always.
 		if endpointParseResult.isEndpointForPod {
 			for _, address := range endpoint.Addresses {
-				builder.ClusterResource.IPs.TouchResourceLease(address, commonFieldSet.Timestamp, resourcelease.NewK8sResourceLeaseHolder(endpoint.TargetRef.Kind, endpoint.TargetRef.Namespace, endpoint.TargetRef.Name))
+				builder.ClusterResourceOf(l.Log.ClusterName).IPs.TouchResourceLease(address, commonFieldSet.Timestamp, resourcelease.NewK8sResourceLeaseHolder(endpoint.TargetRef.Kind, endpoint.TargetRef.Namespace, endpoint.TargetRef.Name))
 			}
 		}
 
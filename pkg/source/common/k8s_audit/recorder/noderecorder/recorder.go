@@ -27,7 +27,7 @@ import (
 func Register(manager *recorder.RecorderTaskManager) error {
 	manager.AddRecorder("node-fields", []taskid.UntypedTaskReference{}, func(ctx context.Context, resourcePath string, currentLog *types.AuditLogParserInput, prevStateInGroup any, cs *history.ChangeSet, builder *history.Builder) (any, error) {
 		// record node name for querying compute engine api later.
-		builder.ClusterResource.AddNode(currentLog.Operation.Name)
+		builder.ClusterResourceOf(currentLog.Log.ClusterName).AddNode(currentLog.Operation.Name)
 		return nil, nil
 	}, recorder.ResourceKindLogGroupFilter("node"), recorder.AndLogFilter(recorder.OnlySucceedLogs(), recorder.OnlyWithResourceBody()))
 	return nil
@@ -29,7 +29,7 @@ func Register(manager *recorder.RecorderTaskManager) error {
 	manager.AddRecorder("sneg-fields", []taskid.UntypedTaskReference{}, func(ctx context.Context, resourcePath string, currentLog *types.AuditLogParserInput, prevStateInGroup any, cs *history.ChangeSet, builder *history.Builder) (any, error) {
 		commonFieldSet := log.MustGetFieldSet(currentLog.Log, &log.CommonFieldSet{})
 		// record node name for querying compute engine api later.
-		builder.ClusterResource.NEGs.TouchResourceLease(currentLog.Operation.Name, commonFieldSet.Timestamp, resourcelease.NewK8sResourceLeaseHolder(
+		builder.ClusterResourceOf(currentLog.Log.ClusterName).NEGs.TouchResourceLease(currentLog.Operation.Name, commonFieldSet.Timestamp, resourcelease.NewK8sResourceLeaseHolder(
 			currentLog.Operation.PluralKind,
 			currentLog.Operation.Namespace,
 			currentLog.Operation.Name,
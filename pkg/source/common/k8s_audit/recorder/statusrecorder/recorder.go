@@ -84,7 +84,7 @@ func recordChangeSetForLog(ctx context.Context, resourcePath string, l *types.Au
 			}
 			statusPath = resourcepath.Status(resourcepath.FromK8sOperation(parentOp), condition.Type)
 		}
-		tb := builder.GetTimelineBuilder(statusPath.Path)
+		tb := builder.GetTimelineBuilder(resourcepath.ClusterScopedPath(l.Log.ClusterName, statusPath.Path))
 		latest := tb.GetLatestRevision()
 		latestTime := time.Time{}
 		if latest != nil {
@@ -57,7 +57,9 @@ type AuditLogParserInput struct {
 
 type TimelineGrouperResult struct {
 	TimelineResourcePath string
-	PreParsedLogs        []*AuditLogParserInput
+	// ClusterName is the name of the cluster the logs in this group came from. It's empty in single cluster inspections.
+	ClusterName   string
+	PreParsedLogs []*AuditLogParserInput
 }
 
 // AuditLogFieldMapper handles log specific field mappings before passing AuditLogParserInput to the later parser steps.
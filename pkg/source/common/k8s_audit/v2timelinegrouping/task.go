@@ -27,6 +27,7 @@ import (
 	"github.com/GoogleCloudPlatform/khi/pkg/log"
 	"github.com/GoogleCloudPlatform/khi/pkg/model"
 	"github.com/GoogleCloudPlatform/khi/pkg/model/enum"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/history/resourcepath"
 	"github.com/GoogleCloudPlatform/khi/pkg/source/common/k8s_audit/rtype"
 	common_k8saudit_taskid "github.com/GoogleCloudPlatform/khi/pkg/source/common/k8s_audit/taskid"
 	"github.com/GoogleCloudPlatform/khi/pkg/source/common/k8s_audit/types"
@@ -48,12 +49,16 @@ var Task = inspection_task.NewProgressReportableInspectionTask(common_k8saudit_t
 	}
 	defer progressUpdater.Done()
 
-	timelineGrouper := grouper.NewBasicGrouper(func(input *types.AuditLogParserInput) string { return input.Operation.CovertToResourcePath() })
+	// Logs are grouped by the cluster scoped path not to mix the same resource path in different clusters in multi-cluster inspections.
+	timelineGrouper := grouper.NewBasicGrouper(func(input *types.AuditLogParserInput) string {
+		return resourcepath.ClusterScopedPath(input.Log.ClusterName, input.Operation.CovertToResourcePath())
+	})
 	groups := timelineGrouper.Group(preStepParseResult)
 	result := []*types.TimelineGrouperResult{}
-	for key, group := range groups {
+	for _, group := range groups {
 		result = append(result, &types.TimelineGrouperResult{
-			TimelineResourcePath: key,
+			TimelineResourcePath: group[0].Operation.CovertToResourcePath(),
+			ClusterName:          group[0].Log.ClusterName,
 			PreParsedLogs:        group,
 		})
 	}
@@ -70,7 +75,7 @@ func createDeletionRequestsByDeleteColection(groups []*types.TimelineGrouperResu
 				if l.Operation.Verb == enum.RevisionVerbDeleteCollection {
 					for _, childGroup := range groups {
 						// find any timelines under current timeline
-						if childGroup.TimelineResourcePath != group.TimelineResourcePath && strings.HasPrefix(childGroup.TimelineResourcePath, group.TimelineResourcePath) {
+						if childGroup.ClusterName == group.ClusterName && childGroup.TimelineResourcePath != group.TimelineResourcePath && strings.HasPrefix(childGroup.TimelineResourcePath, group.TimelineResourcePath) {
 							refLog := childGroup.PreParsedLogs[0]
 							k8sOp := model.KubernetesObjectOperation{
 								APIVersion: refLog.Operation.APIVersion,
@@ -98,7 +103,7 @@ func createDeletionRequestsByDeleteColection(groups []*types.TimelineGrouperResu
 								ResponseType:                           rtype.RTypeUnknown,
 								GeneratedFromDeleteCollectionOperation: true,
 							})
-							requireSortTimelinePaths[childGroup.TimelineResourcePath] = struct{}{}
+							requireSortTimelinePaths[resourcepath.ClusterScopedPath(childGroup.ClusterName, childGroup.TimelineResourcePath)] = struct{}{}
 						}
 					}
 				}
@@ -107,7 +112,7 @@ func createDeletionRequestsByDeleteColection(groups []*types.TimelineGrouperResu
 	}
 	// sort logs with additional deletion logs in timeline
 	for _, group := range groups {
-		if _, found := requireSortTimelinePaths[group.TimelineResourcePath]; found {
+		if _, found := requireSortTimelinePaths[resourcepath.ClusterScopedPath(group.ClusterName, group.TimelineResourcePath)]; found {
 			sort.Slice(group.PreParsedLogs, func(i, j int) bool {
 				logICommonField := log.MustGetFieldSet(group.PreParsedLogs[i].Log, &log.CommonFieldSet{})
 				logJCommonField := log.MustGetFieldSet(group.PreParsedLogs[j].Log, &log.CommonFieldSet{})
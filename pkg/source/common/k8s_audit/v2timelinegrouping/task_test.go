@@ -29,6 +29,7 @@ import (
 	"github.com/GoogleCloudPlatform/khi/pkg/task"
 	task_test "github.com/GoogleCloudPlatform/khi/pkg/task/test"
 	"github.com/GoogleCloudPlatform/khi/pkg/testutil/testlog"
+	"github.com/google/go-cmp/cmp"
 
 	_ "github.com/GoogleCloudPlatform/khi/internal/testflags"
 )
@@ -133,4 +134,48 @@ timestamp: 2024-01-01T00:00:00+09:00`
 			}
 		}
 	})
+	t.Run("it groups logs of the same resource in different clusters separately", func(t *testing.T) {
+		tl := testlog.New(testlog.YAML(`insertId: foo
+timestamp: 2024-01-01T00:00:00+09:00`))
+		logs := []*log.Log{}
+		for _, clusterName := range []string{"cluster-a", "cluster-b", "cluster-a"} {
+			l := tl.MustBuildLogEntity()
+			l.ClusterName = clusterName
+			logs = append(logs, l)
+		}
+		ctx := inspection_task_test.WithDefaultTestInspectionTaskContext(context.Background())
+		result, _, err := inspection_task_test.RunInspectionTaskWithDependency(ctx, Task, []task.UntypedTask{
+			v2commonlogparse.Task,
+			task_test.StubTaskFromReferenceID(common_k8saudit_taskid.CommonAuitLogSource, &types.AuditLogParserLogSource{
+				Logs: logs,
+				Extractor: &stubAuditLogFieldExtractor{
+					Extractor: func(ctx context.Context, log *log.Log) (*types.AuditLogParserInput, error) {
+						return &types.AuditLogParserInput{
+							Log: log,
+							Operation: &model.KubernetesObjectOperation{
+								APIVersion: "core/v1",
+								PluralKind: "pods",
+								Namespace:  "default",
+								Name:       "foo",
+								Verb:       enum.RevisionVerbCreate,
+							},
+						}, nil
+					},
+				},
+			}, nil),
+		}, inspection_task_interface.TaskModeRun, map[string]any{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		gotLogCounts := map[string]int{}
+		for _, group := range result {
+			if group.TimelineResourcePath != "core/v1#pod#default#foo" {
+				t.Errorf("unexpected timeline %s", group.TimelineResourcePath)
+			}
+			gotLogCounts[group.ClusterName] = len(group.PreParsedLogs)
+		}
+		if diff := cmp.Diff(map[string]int{"cluster-a": 2, "cluster-b": 1}, gotLogCounts); diff != "" {
+			t.Errorf("log counts by cluster mismatch (-want +got):\n%s", diff)
+		}
+	})
 }
@@ -27,11 +27,12 @@ var RetriableHttpResponseCodes = []int{
 	429, 500, 501, 502, 503,
 }
 
+// DefaultOAuthTokenResolver resolves tokens of users via OAuth. Tokens obtained with it are bound to inspections and never stored in DefaultAccessTokenStore.
 var DefaultOAuthTokenResolver = NewOAuthTokenResolver()
 
+// DefaultAccessTokenStore is the store of the access token of the KHI process itself.
 var DefaultAccessTokenStore = token.NewBasicTokenStore(
 	"accesstoken", token.NewMultiTokenResolver(
-		DefaultOAuthTokenResolver,
 		token.NewOnceTokenResolver(func() string {
 			if parameters.Auth.AccessToken == nil {
 				return ""
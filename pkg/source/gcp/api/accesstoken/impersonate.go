@@ -0,0 +1,98 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package accesstoken
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/common/httpclient"
+	"github.com/GoogleCloudPlatform/khi/pkg/common/token"
+)
+
+// ImpersonationResponse is the response of the generateAccessToken API of IAM Service Account Credentials.
+type ImpersonationResponse struct {
+	AccessToken string    `json:"accessToken"`
+	ExpireTime  time.Time `json:"expireTime"`
+}
+
+// IAMCredentialsConfig is the destination of the requests to impersonate service accounts.
+type IAMCredentialsConfig struct {
+	// Endpoint is the base URL of the IAM Service Account Credentials API. The URL doesn't end with `/`.
+	Endpoint string
+	// Scope is the OAuth scope of the access tokens issued for impersonated service accounts.
+	Scope string
+}
+
+// ImpersonationTokenResolver resolves an access token of a service account by impersonating it.
+type ImpersonationTokenResolver struct {
+	serviceAccount string
+	iamCredentials IAMCredentialsConfig
+	client         *httpclient.JSONReponseHttpClient[ImpersonationResponse]
+}
+
+// NewImpersonationTokenResolver returns an ImpersonationTokenResolver obtaining the token of the service account from the given IAM Service Account Credentials API.
+// The given client must authenticate requests with a principal allowed to create tokens of the service account.
+func NewImpersonationTokenResolver(serviceAccount string, iamCredentials IAMCredentialsConfig, client *httpclient.JSONReponseHttpClient[ImpersonationResponse]) *ImpersonationTokenResolver {
+	return &ImpersonationTokenResolver{
+		serviceAccount: serviceAccount,
+		iamCredentials: iamCredentials,
+		client:         client,
+	}
+}
+
+// newImpersonationTokenResolverWithBaseStore returns an ImpersonationTokenResolver sending requests with the token in the given store.
+func newImpersonationTokenResolverWithBaseStore(serviceAccount string, iamCredentials IAMCredentialsConfig, base token.TokenStore) *ImpersonationTokenResolver {
+	baseClient := httpclient.NewBasicHttpClient().WithHeaderProvider(NewHeaderProvider(base))
+	return NewImpersonationTokenResolver(serviceAccount, iamCredentials, httpclient.NewJsonResponseHttpClient[ImpersonationResponse](httpclient.NewRetryHttpClient(baseClient, MinWaitTimeOnRetriableError, MaxWaitTimeOnRetriableError, MaxRetryCount, RetriableHttpResponseCodes, []int{}, &token.NopTokenRefresher{})))
+}
+
+// Resolve implements token.TokenResolver.
+func (i *ImpersonationTokenResolver) Resolve(ctx context.Context) (*token.Token, error) {
+	body, err := json.Marshal(map[string]any{
+		"scope":    []string{i.iamCredentials.Scope},
+		"lifetime": impersonatedTokenLifetime,
+	})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", fmt.Sprintf("%s/v1/projects/-/serviceAccounts/%s:generateAccessToken", i.iamCredentials.Endpoint, url.PathEscape(i.serviceAccount)), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	response, httpResp, err := i.client.DoWithContext(ctx, req)
+	if httpResp != nil && httpResp.Body != nil {
+		defer httpResp.Body.Close()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to impersonate the service account %s\n%w", i.serviceAccount, err)
+	}
+	if response.AccessToken == "" {
+		return nil, fmt.Errorf("failed to impersonate the service account %s: the response has no access token", i.serviceAccount)
+	}
+	slog.InfoContext(ctx, fmt.Sprintf("obtained access token of the service account %s", i.serviceAccount))
+	return token.NewWithExpiry(response.AccessToken, response.ExpireTime), nil
+}
+
+const impersonatedTokenLifetime = "3600s"
+
+var _ token.TokenResolver = (*ImpersonationTokenResolver)(nil)
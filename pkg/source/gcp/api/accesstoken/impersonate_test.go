@@ -0,0 +1,112 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package accesstoken
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/common/httpclient"
+	"github.com/GoogleCloudPlatform/khi/pkg/testutil"
+	"github.com/google/go-cmp/cmp"
+
+	_ "github.com/GoogleCloudPlatform/khi/internal/testflags"
+)
+
+type spyImpersonationHttpClient struct {
+	response    string
+	statusCode  int
+	requestURL  string
+	requestBody string
+}
+
+// DoWithContext implements httpclient.HttpClient.
+func (s *spyImpersonationHttpClient) DoWithContext(ctx context.Context, request *http.Request) (*http.Response, error) {
+	s.requestURL = request.URL.String()
+	body, err := io.ReadAll(request.Body)
+	if err != nil {
+		return nil, err
+	}
+	s.requestBody = string(body)
+	return testutil.ResponseFromString(s.statusCode, s.response), nil
+}
+
+var _ httpclient.HTTPClient[*http.Response] = (*spyImpersonationHttpClient)(nil)
+
+func TestImpersonationTokenResolver(t *testing.T) {
+	testCases := []struct {
+		name       string
+		response   string
+		statusCode int
+		want       string
+		wantExpiry time.Time
+		wantErr    bool
+	}{
+		{
+			name:       "returns the token of the service account",
+			response:   `{"accessToken":"impersonated-token","expireTime":"2025-01-01T01:00:00Z"}`,
+			statusCode: http.StatusOK,
+			want:       "impersonated-token",
+			wantExpiry: time.Date(2025, time.January, 1, 1, 0, 0, 0, time.UTC),
+		},
+		{
+			name:       "returns an error when the base principal isn't allowed",
+			response:   `{"error":{"code":403}}`,
+			statusCode: http.StatusForbidden,
+			wantErr:    true,
+		},
+		{
+			name:       "returns an error when the response has no token",
+			response:   `{}`,
+			statusCode: http.StatusOK,
+			wantErr:    true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			spy := &spyImpersonationHttpClient{response: tc.response, statusCode: tc.statusCode}
+			iamCredentials := IAMCredentialsConfig{
+				Endpoint: "https://iamcredentials.example.com",
+				Scope:    "https://www.example.com/auth/cloud-platform",
+			}
+			resolver := NewImpersonationTokenResolver("khi@project.iam.gserviceaccount.com", iamCredentials, httpclient.NewJsonResponseHttpClient[ImpersonationResponse](spy))
+			got, err := resolver.Resolve(context.Background())
+			if wantURL := "https://iamcredentials.example.com/v1/projects/-/serviceAccounts/khi@project.iam.gserviceaccount.com:generateAccessToken"; spy.requestURL != wantURL {
+				t.Errorf("request URL = %q, want %q", spy.requestURL, wantURL)
+			}
+			if wantBody := `{"lifetime":"3600s","scope":["https://www.example.com/auth/cloud-platform"]}`; spy.requestBody != wantBody {
+				t.Errorf("request body = %q, want %q", spy.requestBody, wantBody)
+			}
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("Resolve() returned nil error, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.want, got.RawToken); diff != "" {
+				t.Errorf("token mismatch (-want +got):\n%s", diff)
+			}
+			if !got.ValidAtLeastUntil.Equal(tc.wantExpiry) {
+				t.Errorf("token expiry = %v, want %v", got.ValidAtLeastUntil, tc.wantExpiry)
+			}
+		})
+	}
+}
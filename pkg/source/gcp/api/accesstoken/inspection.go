@@ -0,0 +1,83 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package accesstoken
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/common/khictx"
+	"github.com/GoogleCloudPlatform/khi/pkg/common/token"
+	inspection_task_contextkey "github.com/GoogleCloudPlatform/khi/pkg/inspection/contextkey"
+	"github.com/GoogleCloudPlatform/khi/pkg/inspection/credential"
+	"github.com/GoogleCloudPlatform/khi/pkg/parameters"
+)
+
+// InspectionTokenStore returns the access token store used for GCP requests in the inspection of the given context and the identity of its credential.
+// Token stores created from the credential of the user are cached only during the lifetime of the inspection.
+// The identity is empty when the credential of the KHI process is used. Service accounts are impersonated through the given IAM Service Account Credentials API.
+func InspectionTokenStore(ctx context.Context, iamCredentials IAMCredentialsConfig) (token.TokenStore, string, error) {
+	cache, err := khictx.GetValue(ctx, inspection_task_contextkey.InspectionCredentialCache)
+	if err != nil {
+		return DefaultAccessTokenStore, "", nil
+	}
+	if userCredential, err := khictx.GetValue(ctx, inspection_task_contextkey.InspectionCredential); err == nil {
+		if err := userCredential.Validate(); err != nil {
+			return nil, "", err
+		}
+		if userCredential.AccessToken == "" && !allowProcessImpersonation() {
+			return nil, "", fmt.Errorf("%w: impersonating a service account requires the access token of the user", credential.ErrUserCredentialRequired)
+		}
+		identity := userCredential.Identity()
+		return credential.GetOrCreate(cache, identity, func() token.TokenStore {
+			return newCredentialTokenStore(userCredential, iamCredentials)
+		}), identity, nil
+	}
+	if parameters.Auth.OAuthEnabled() {
+		owner, _ := khictx.GetValue(ctx, inspection_task_contextkey.InspectionTaskOwner)
+		identity := "oauth:" + owner
+		return credential.GetOrCreate(cache, identity, func() token.TokenStore {
+			return token.NewBasicTokenStore("oauth", DefaultOAuthTokenResolver)
+		}), identity, nil
+	}
+	if requireUserCredential() {
+		return nil, "", credential.ErrUserCredentialRequired
+	}
+	return DefaultAccessTokenStore, "", nil
+}
+
+// newCredentialTokenStore returns the token store resolving the access token from the given credential.
+func newCredentialTokenStore(userCredential *credential.Credential, iamCredentials IAMCredentialsConfig) token.TokenStore {
+	var base token.TokenStore = DefaultAccessTokenStore
+	if userCredential.AccessToken != "" {
+		accessToken := userCredential.AccessToken
+		base = token.NewBasicTokenStore("user-accesstoken", token.NewOnceTokenResolver(func() string {
+			return accessToken
+		}))
+	}
+	if userCredential.ImpersonateServiceAccount == "" {
+		return base
+	}
+	return token.NewBasicTokenStore("impersonated-accesstoken", newImpersonationTokenResolverWithBaseStore(userCredential.ImpersonateServiceAccount, iamCredentials, base))
+}
+
+func requireUserCredential() bool {
+	return parameters.Auth.RequireUserCredential != nil && *parameters.Auth.RequireUserCredential
+}
+
+// allowProcessImpersonation returns true when service accounts can be impersonated with the credential of the KHI process.
+func allowProcessImpersonation() bool {
+	return !requireUserCredential() && parameters.Auth.AllowProcessImpersonation != nil && *parameters.Auth.AllowProcessImpersonation
+}
@@ -0,0 +1,158 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package accesstoken
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/common/khictx"
+	inspection_task_contextkey "github.com/GoogleCloudPlatform/khi/pkg/inspection/contextkey"
+	"github.com/GoogleCloudPlatform/khi/pkg/inspection/credential"
+	"github.com/GoogleCloudPlatform/khi/pkg/parameters"
+	"github.com/GoogleCloudPlatform/khi/pkg/testutil"
+
+	_ "github.com/GoogleCloudPlatform/khi/internal/testflags"
+)
+
+var testIAMCredentials = IAMCredentialsConfig{
+	Endpoint: "https://iamcredentials.googleapis.com",
+	Scope:    "https://www.googleapis.com/auth/cloud-platform",
+}
+
+func inspectionContext(userCredential *credential.Credential, cache *credential.Cache) context.Context {
+	ctx := khictx.WithValue(context.Background(), inspection_task_contextkey.InspectionCredentialCache, cache)
+	if userCredential != nil {
+		ctx = khictx.WithValue(ctx, inspection_task_contextkey.InspectionCredential, userCredential)
+	}
+	return ctx
+}
+
+func TestInspectionTokenStore(t *testing.T) {
+	originalAuth := parameters.Auth
+	defer func() { parameters.Auth = originalAuth }()
+
+	testCases := []struct {
+		name                  string
+		ctx                   context.Context
+		requireUserCredential bool
+		allowImpersonation    bool
+		wantIdentity          string
+		wantToken             string
+		wantDefaultStore      bool
+		wantErr               error
+	}{
+		{
+			name:             "out of inspections",
+			ctx:              context.Background(),
+			wantDefaultStore: true,
+		},
+		{
+			name:             "inspection without credential",
+			ctx:              inspectionContext(nil, credential.NewCache()),
+			wantDefaultStore: true,
+		},
+		{
+			name:                  "inspection without credential when the user credential is required",
+			ctx:                   inspectionContext(nil, credential.NewCache()),
+			requireUserCredential: true,
+			wantErr:               credential.ErrUserCredentialRequired,
+		},
+		{
+			name:                  "inspection with access token",
+			ctx:                   inspectionContext(&credential.Credential{AccessToken: "user-token"}, credential.NewCache()),
+			requireUserCredential: true,
+			wantIdentity:          (&credential.Credential{AccessToken: "user-token"}).Identity(),
+			wantToken:             "user-token",
+		},
+		{
+			name:                  "impersonation with the credential of KHI when the user credential is required",
+			ctx:                   inspectionContext(&credential.Credential{ImpersonateServiceAccount: "khi@project.iam.gserviceaccount.com"}, credential.NewCache()),
+			requireUserCredential: true,
+			wantErr:               credential.ErrUserCredentialRequired,
+		},
+		{
+			name:    "impersonation with the credential of KHI without the opt-in",
+			ctx:     inspectionContext(&credential.Credential{ImpersonateServiceAccount: "khi@project.iam.gserviceaccount.com"}, credential.NewCache()),
+			wantErr: credential.ErrUserCredentialRequired,
+		},
+		{
+			name:               "impersonation with the credential of KHI",
+			ctx:                inspectionContext(&credential.Credential{ImpersonateServiceAccount: "khi@project.iam.gserviceaccount.com"}, credential.NewCache()),
+			allowImpersonation: true,
+			wantIdentity:       "impersonate:khi@project.iam.gserviceaccount.com#process",
+		},
+		{
+			name:         "impersonation with access token",
+			ctx:          inspectionContext(&credential.Credential{AccessToken: "user-token", ImpersonateServiceAccount: "khi@project.iam.gserviceaccount.com"}, credential.NewCache()),
+			wantIdentity: (&credential.Credential{AccessToken: "user-token", ImpersonateServiceAccount: "khi@project.iam.gserviceaccount.com"}).Identity(),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			parameters.Auth = &parameters.AuthParameters{RequireUserCredential: testutil.P(tc.requireUserCredential), AllowProcessImpersonation: testutil.P(tc.allowImpersonation)}
+			store, identity, err := InspectionTokenStore(tc.ctx, testIAMCredentials)
+			if tc.wantErr != nil {
+				if !errors.Is(err, tc.wantErr) {
+					t.Errorf("InspectionTokenStore() error = %v, want %v", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if identity != tc.wantIdentity {
+				t.Errorf("identity = %q, want %q", identity, tc.wantIdentity)
+			}
+			if (store == DefaultAccessTokenStore) != tc.wantDefaultStore {
+				t.Errorf("store is the default store = %v, want %v", store == DefaultAccessTokenStore, tc.wantDefaultStore)
+			}
+			if tc.wantToken != "" {
+				got, err := store.GetToken(tc.ctx)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got.RawToken != tc.wantToken {
+					t.Errorf("token = %q, want %q", got.RawToken, tc.wantToken)
+				}
+			}
+		})
+	}
+}
+
+func TestInspectionTokenStoreIsCachedDuringInspection(t *testing.T) {
+	cache := credential.NewCache()
+	ctx := inspectionContext(&credential.Credential{AccessToken: "user-token"}, cache)
+	first, _, err := InspectionTokenStore(ctx, testIAMCredentials)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, _, err := InspectionTokenStore(ctx, testIAMCredentials)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first != second {
+		t.Errorf("InspectionTokenStore() returned a different store for the same credential in an inspection")
+	}
+	cache.Clear()
+	afterClear, _, err := InspectionTokenStore(ctx, testIAMCredentials)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first == afterClear {
+		t.Errorf("InspectionTokenStore() returned the store created before the inspection ended")
+	}
+}
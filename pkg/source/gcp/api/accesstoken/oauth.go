@@ -20,8 +20,11 @@ import (
 	"fmt"
 	"log/slog"
 	"net/http"
+	"sync"
 
+	"github.com/GoogleCloudPlatform/khi/pkg/common/khictx"
 	"github.com/GoogleCloudPlatform/khi/pkg/common/token"
+	inspection_task_contextkey "github.com/GoogleCloudPlatform/khi/pkg/inspection/contextkey"
 	"github.com/GoogleCloudPlatform/khi/pkg/parameters"
 	"github.com/GoogleCloudPlatform/khi/pkg/popup"
 	"github.com/gin-gonic/gin"
@@ -64,16 +67,23 @@ func newOauthTokenPopup(redirectTarget string) *OAuthTokenPopup {
 
 var _ popup.PopupForm = (*OAuthTokenPopup)(nil)
 
-type OAuthTokenResolver struct {
-	server        *gin.Engine
+// oauthFlow is a single OAuth authorization started from a call of OAuthTokenResolver.Resolve.
+type oauthFlow struct {
 	popup         *OAuthTokenPopup
-	stateCodes    map[string]struct{}
 	resolvedToken *oauth2.Token
 }
 
+// OAuthTokenResolver resolves the token of the user via OAuth.
+// Each call of Resolve starts its own flow identified with the state code. The resolved token is returned only to the caller starting the flow.
+type OAuthTokenResolver struct {
+	server    *gin.Engine
+	flowsLock sync.Mutex
+	flows     map[string]*oauthFlow
+}
+
 func NewOAuthTokenResolver() *OAuthTokenResolver {
 	return &OAuthTokenResolver{
-		stateCodes: map[string]struct{}{},
+		flows: map[string]*oauthFlow{},
 	}
 }
 
@@ -99,7 +109,10 @@ func (o *OAuthTokenResolver) SetServer(server *gin.Engine) error {
 			return
 		}
 		state := ctx.Query("state")
-		if _, found := o.stateCodes[state]; !found {
+		o.flowsLock.Lock()
+		flow, found := o.flows[state]
+		o.flowsLock.Unlock()
+		if !found {
 			ctx.String(http.StatusBadRequest, "invalid state code")
 			return
 		}
@@ -109,13 +122,10 @@ func (o *OAuthTokenResolver) SetServer(server *gin.Engine) error {
 			ctx.String(http.StatusInternalServerError, err.Error())
 			return
 		}
-		if o.popup == nil {
-			ctx.String(http.StatusInternalServerError, "popup is not initialized")
-			return
-		}
-		delete(o.stateCodes, state)
-		o.resolvedToken = token
-		o.popup.popupClosable = true
+		o.flowsLock.Lock()
+		flow.resolvedToken = token
+		flow.popup.popupClosable = true
+		o.flowsLock.Unlock()
 		// Return the HTML closing the window itself.
 		ctx.Writer.Write([]byte(`<html>
 	<head>
@@ -139,14 +149,31 @@ func (o *OAuthTokenResolver) Resolve(ctx context.Context) (*token.Token, error)
 	if err != nil {
 		return nil, err
 	}
-	o.stateCodes[stateCode] = struct{}{}
-	o.popup = newOauthTokenPopup(oauthConfig.AuthCodeURL(stateCode))
-	_, err = popup.Instance.ShowPopup(o.popup)
+	flow := &oauthFlow{
+		popup: newOauthTokenPopup(oauthConfig.AuthCodeURL(stateCode)),
+	}
+	o.flowsLock.Lock()
+	o.flows[stateCode] = flow
+	o.flowsLock.Unlock()
+	defer func() {
+		o.flowsLock.Lock()
+		delete(o.flows, stateCode)
+		o.flowsLock.Unlock()
+	}()
+	// The popup is shown to the owner of the inspection requesting the token. The owner is empty when the token is requested out of inspections.
+	owner, _ := khictx.GetValue(ctx, inspection_task_contextkey.InspectionTaskOwner)
+	_, err = popup.Instance.ShowPopup(owner, flow.popup)
 	if err != nil {
 		return nil, err
 	}
+	o.flowsLock.Lock()
+	resolvedToken := flow.resolvedToken
+	o.flowsLock.Unlock()
+	if resolvedToken == nil {
+		return nil, fmt.Errorf("OAuth flow was closed before obtaining the token")
+	}
 	slog.InfoContext(ctx, "obtained access token with OAuth")
-	return token.NewWithExpiry(o.resolvedToken.AccessToken, o.resolvedToken.Expiry), nil
+	return token.NewWithExpiry(resolvedToken.AccessToken, resolvedToken.Expiry), nil
 }
 
 func (o *OAuthTokenResolver) generateStateCode() (string, error) {
@@ -15,6 +15,9 @@
 package api
 
 import (
+	"context"
+	"net/http"
+
 	"github.com/GoogleCloudPlatform/khi/pkg/common/httpclient"
 	"github.com/GoogleCloudPlatform/khi/pkg/common/token"
 	"github.com/GoogleCloudPlatform/khi/pkg/source/gcp/api/accesstoken"
@@ -23,6 +26,13 @@ import (
 type GCPClientFactory struct {
 	HeaderProviders []httpclient.HTTPHeaderProvider
 	TokenStores     []token.TokenStore
+	// AccessTokenStore is the store of the access token used when no credential of the user is bound to the client.
+	// The factory doesn't set the Authorization header by itself when it's nil.
+	AccessTokenStore token.TokenStore
+	// Transport is the RoundTripper used in clients instantiated from this factory. http.DefaultTransport is used when it's nil.
+	Transport http.RoundTripper
+	// Endpoints is the set of base URLs of the APIs called from clients instantiated from this factory. The endpoints in googleapis.com are used when it's nil.
+	Endpoints *Endpoints
 }
 
 func NewGCPClientFactory() *GCPClientFactory {
@@ -34,7 +44,41 @@ func NewGCPClientFactory() *GCPClientFactory {
 
 // NewClient instanciate a new GCPClient from current factory config.
 func (f *GCPClientFactory) NewClient() (GCPClient, error) {
-	return NewGCPClient(token.NewMultiTokenStoreRefresher(f.TokenStores...), f.HeaderProviders)
+	return f.newClientWithAccessTokenStore(f.AccessTokenStore, "")
+}
+
+// NewClientFromContext instanciate a new GCPClient sending requests with the credential of the inspection in the given context.
+// Clients for different credentials return different digests not to share cached query results among users.
+func (f *GCPClientFactory) NewClientFromContext(ctx context.Context) (GCPClient, error) {
+	if f.AccessTokenStore == nil {
+		return f.NewClient()
+	}
+	endpoints := f.Endpoints
+	if endpoints == nil {
+		endpoints = NewEndpoints(DefaultUniverseDomain)
+	}
+	store, identity, err := accesstoken.InspectionTokenStore(ctx, endpoints.iamCredentialsConfig())
+	if err != nil {
+		return nil, err
+	}
+	return f.newClientWithAccessTokenStore(store, identity)
+}
+
+func (f *GCPClientFactory) newClientWithAccessTokenStore(store token.TokenStore, identity string) (GCPClient, error) {
+	headerProviders := f.HeaderProviders
+	tokenStores := f.TokenStores
+	if store != nil {
+		headerProviders = append([]httpclient.HTTPHeaderProvider{accesstoken.NewHeaderProvider(store)}, headerProviders...)
+		tokenStores = append([]token.TokenStore{store}, tokenStores...)
+	}
+	client, err := NewGCPClient(token.NewMultiTokenStoreRefresher(tokenStores...), headerProviders, f.Transport, f.Endpoints)
+	if err != nil {
+		return nil, err
+	}
+	if impl, ok := client.(*GCPClientImpl); ok {
+		impl.Identity = identity
+	}
+	return client, nil
 }
 
 // RegisterHeaderProvider adds a new HeaderProvider on factory config.
@@ -49,8 +93,7 @@ func (f *GCPClientFactory) RegisterRefreshableTokenStore(store token.TokenStore)
 
 var DefaultGCPClientFactory *GCPClientFactory = NewGCPClientFactory()
 
-// set the default header providers on the default factory.
+// set the default access token store on the default factory.
 func init() {
-	DefaultGCPClientFactory.RegisterHeaderProvider(accesstoken.NewHeaderProvider(accesstoken.DefaultAccessTokenStore))
-	DefaultGCPClientFactory.RegisterRefreshableTokenStore(accesstoken.DefaultAccessTokenStore)
+	DefaultGCPClientFactory.AccessTokenStore = accesstoken.DefaultAccessTokenStore
 }
@@ -0,0 +1,60 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"context"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/common/khictx"
+	"github.com/GoogleCloudPlatform/khi/pkg/common/token"
+	inspection_task_contextkey "github.com/GoogleCloudPlatform/khi/pkg/inspection/contextkey"
+	"github.com/GoogleCloudPlatform/khi/pkg/inspection/credential"
+
+	_ "github.com/GoogleCloudPlatform/khi/internal/testflags"
+)
+
+func TestGCPClientFactoryNewClientFromContext(t *testing.T) {
+	factory := NewGCPClientFactory()
+	factory.AccessTokenStore = token.NewBasicTokenStore("test", token.NewSpyTokenResolver())
+	newContext := func(userCredential *credential.Credential) context.Context {
+		ctx := khictx.WithValue(context.Background(), inspection_task_contextkey.InspectionCredentialCache, credential.NewCache())
+		if userCredential != nil {
+			ctx = khictx.WithValue(ctx, inspection_task_contextkey.InspectionCredential, userCredential)
+		}
+		return ctx
+	}
+	digest := func(ctx context.Context) string {
+		t.Helper()
+		client, err := factory.NewClientFromContext(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return client.(*GCPClientImpl).Digest()
+	}
+
+	processDigest := digest(newContext(nil))
+	if processDigest != "https://logging.googleapis.com" {
+		t.Errorf("Digest() of the client without user credential = %q, want the logging endpoint", processDigest)
+	}
+	userA := digest(newContext(&credential.Credential{AccessToken: "token-a"}))
+	userB := digest(newContext(&credential.Credential{AccessToken: "token-b"}))
+	if userA == processDigest || userA == userB {
+		t.Errorf("clients with different credentials returned the same digest: process=%q, a=%q, b=%q", processDigest, userA, userB)
+	}
+	if again := digest(newContext(&credential.Credential{AccessToken: "token-a"})); again != userA {
+		t.Errorf("Digest() = %q for the same credential, want %q", again, userA)
+	}
+}
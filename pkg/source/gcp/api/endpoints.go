@@ -0,0 +1,155 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/parameters"
+	"github.com/GoogleCloudPlatform/khi/pkg/source/gcp/api/accesstoken"
+)
+
+// DefaultUniverseDomain is the domain of Google APIs used when no universe domain is given.
+const DefaultUniverseDomain = "googleapis.com"
+
+// DefaultImpersonationScope is the OAuth scope of the access tokens issued for impersonated service accounts when no scope is given.
+const DefaultImpersonationScope = "https://www.googleapis.com/auth/cloud-platform"
+
+// LocationPlaceholder is replaced with each location in the GKE Multi-Cloud API endpoint template.
+const LocationPlaceholder = "{location}"
+
+// DefaultGKEMultiCloudLocations is the list of locations where the GKE Multi-Cloud API is available.
+// ref: https://cloud.google.com/kubernetes-engine/multi-cloud/docs/aws/reference/supported-regions
+var DefaultGKEMultiCloudLocations = []string{
+	"asia-east2",
+	"asia-northeast2",
+	"asia-south1",
+	"asia-southeast1",
+	"asia-southeast2",
+	"australia-southeast1",
+	"europe-north1",
+	"europe-west1",
+	"europe-west2",
+	"europe-west3",
+	"europe-west4",
+	"europe-west6",
+	"europe-west9",
+	"northamerica-northeast1",
+	"southamerica-east1",
+	"us-east4",
+	"us-west1",
+}
+
+// Endpoints is the set of base URLs of the Google APIs called from GCPClientImpl. The URLs don't end with `/`.
+type Endpoints struct {
+	// Logging is the base URL of the Cloud Logging API.
+	Logging string
+	// Container is the base URL of the GKE API.
+	Container string
+	// GKEMultiCloud is the template of the regional GKE Multi-Cloud API base URLs. LocationPlaceholder in it is replaced with each location of GKEMultiCloudLocations.
+	GKEMultiCloud string
+	// GKEMultiCloudLocations is the list of locations queried through the GKE Multi-Cloud API.
+	GKEMultiCloudLocations []string
+	// GKEOnPrem is the base URL of the GKE On-Prem API.
+	GKEOnPrem string
+	// GKEHub is the base URL of the GKE Hub API.
+	GKEHub string
+	// Composer is the base URL of the Cloud Composer API.
+	Composer string
+	// Compute is the base URL of the Compute Engine API.
+	Compute string
+	// IAMCredentials is the base URL of the IAM Service Account Credentials API.
+	IAMCredentials string
+	// ImpersonationScope is the OAuth scope of the access tokens issued for impersonated service accounts.
+	ImpersonationScope string
+}
+
+// NewEndpoints returns the Endpoints of Google APIs served in the given universe domain.
+func NewEndpoints(universeDomain string) *Endpoints {
+	if universeDomain == "" {
+		universeDomain = DefaultUniverseDomain
+	}
+	return &Endpoints{
+		Logging:                fmt.Sprintf("https://logging.%s", universeDomain),
+		Container:              fmt.Sprintf("https://container.%s", universeDomain),
+		GKEMultiCloud:          fmt.Sprintf("https://%s-gkemulticloud.%s", LocationPlaceholder, universeDomain),
+		GKEMultiCloudLocations: slices.Clone(DefaultGKEMultiCloudLocations),
+		GKEOnPrem:              fmt.Sprintf("https://gkeonprem.%s", universeDomain),
+		GKEHub:                 fmt.Sprintf("https://gkehub.%s", universeDomain),
+		Composer:               fmt.Sprintf("https://composer.%s", universeDomain),
+		Compute:                fmt.Sprintf("https://compute.%s", universeDomain),
+		IAMCredentials:         fmt.Sprintf("https://iamcredentials.%s", universeDomain),
+		ImpersonationScope:     DefaultImpersonationScope,
+	}
+}
+
+// NewEndpointsFromParameters returns the Endpoints of the universe domain given in the parameters with the overrides of each API applied.
+func NewEndpointsFromParameters(p *parameters.EndpointParameters) *Endpoints {
+	endpoints := NewEndpoints(valueOrEmpty(p.UniverseDomain))
+	overrides := []struct {
+		field *string
+		value *string
+	}{
+		{&endpoints.Logging, p.LoggingEndpoint},
+		{&endpoints.Container, p.ContainerEndpoint},
+		{&endpoints.GKEMultiCloud, p.GKEMultiCloudEndpoint},
+		{&endpoints.GKEOnPrem, p.GKEOnPremEndpoint},
+		{&endpoints.GKEHub, p.GKEHubEndpoint},
+		{&endpoints.Composer, p.ComposerEndpoint},
+		{&endpoints.Compute, p.ComputeEndpoint},
+		{&endpoints.IAMCredentials, p.IAMCredentialsEndpoint},
+	}
+	for _, override := range overrides {
+		if value := valueOrEmpty(override.value); value != "" {
+			*override.field = strings.TrimSuffix(value, "/")
+		}
+	}
+	if scope := valueOrEmpty(p.ImpersonationScope); scope != "" {
+		endpoints.ImpersonationScope = scope
+	}
+	if locations := p.GKEMultiCloudLocationList(); len(locations) > 0 {
+		endpoints.GKEMultiCloudLocations = locations
+	}
+	return endpoints
+}
+
+// iamCredentialsConfig returns the destination of the requests to impersonate service accounts.
+func (e *Endpoints) iamCredentialsConfig() accesstoken.IAMCredentialsConfig {
+	return accesstoken.IAMCredentialsConfig{
+		Endpoint: e.IAMCredentials,
+		Scope:    e.ImpersonationScope,
+	}
+}
+
+// gkeMultiCloudEndpoints returns the base URL of the GKE Multi-Cloud API for each location.
+func (e *Endpoints) gkeMultiCloudEndpoints() []multicloudAPIEndpoint {
+	result := make([]multicloudAPIEndpoint, 0, len(e.GKEMultiCloudLocations))
+	for _, location := range e.GKEMultiCloudLocations {
+		result = append(result, multicloudAPIEndpoint{
+			Endpoint: strings.ReplaceAll(e.GKEMultiCloud, LocationPlaceholder, location),
+			Location: location,
+		})
+	}
+	return result
+}
+
+func valueOrEmpty(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
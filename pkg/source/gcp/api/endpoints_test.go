@@ -0,0 +1,101 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"testing"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/parameters"
+	"github.com/GoogleCloudPlatform/khi/pkg/testutil"
+	"github.com/google/go-cmp/cmp"
+
+	_ "github.com/GoogleCloudPlatform/khi/internal/testflags"
+)
+
+func TestNewEndpointsFromParameters(t *testing.T) {
+	testCases := []struct {
+		name       string
+		parameters *parameters.EndpointParameters
+		want       *Endpoints
+	}{
+		{
+			name:       "without parameters",
+			parameters: &parameters.EndpointParameters{},
+			want:       NewEndpoints(DefaultUniverseDomain),
+		},
+		{
+			name: "universe domain",
+			parameters: &parameters.EndpointParameters{
+				UniverseDomain: testutil.P("example.com"),
+			},
+			want: &Endpoints{
+				Logging:                "https://logging.example.com",
+				Container:              "https://container.example.com",
+				GKEMultiCloud:          "https://{location}-gkemulticloud.example.com",
+				GKEMultiCloudLocations: DefaultGKEMultiCloudLocations,
+				GKEOnPrem:              "https://gkeonprem.example.com",
+				GKEHub:                 "https://gkehub.example.com",
+				Composer:               "https://composer.example.com",
+				Compute:                "https://compute.example.com",
+				IAMCredentials:         "https://iamcredentials.example.com",
+				ImpersonationScope:     DefaultImpersonationScope,
+			},
+		},
+		{
+			name: "overrides",
+			parameters: &parameters.EndpointParameters{
+				UniverseDomain:         testutil.P("googleapis.com"),
+				LoggingEndpoint:        testutil.P("https://logging-psc.p.googleapis.com/"),
+				GKEMultiCloudEndpoint:  testutil.P("http://localhost:8080"),
+				GKEMultiCloudLocations: testutil.P("us-west1"),
+				ComputeEndpoint:        testutil.P(""),
+				IAMCredentialsEndpoint: testutil.P("http://localhost:8081/"),
+				ImpersonationScope:     testutil.P("https://www.example.com/auth/cloud-platform"),
+			},
+			want: &Endpoints{
+				Logging:                "https://logging-psc.p.googleapis.com",
+				Container:              "https://container.googleapis.com",
+				GKEMultiCloud:          "http://localhost:8080",
+				GKEMultiCloudLocations: []string{"us-west1"},
+				GKEOnPrem:              "https://gkeonprem.googleapis.com",
+				GKEHub:                 "https://gkehub.googleapis.com",
+				Composer:               "https://composer.googleapis.com",
+				Compute:                "https://compute.googleapis.com",
+				IAMCredentials:         "http://localhost:8081",
+				ImpersonationScope:     "https://www.example.com/auth/cloud-platform",
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := NewEndpointsFromParameters(tc.parameters)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("NewEndpointsFromParameters() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestGKEMultiCloudEndpoints(t *testing.T) {
+	endpoints := NewEndpoints(DefaultUniverseDomain)
+	endpoints.GKEMultiCloudLocations = []string{"us-west1", "europe-west1"}
+	want := []multicloudAPIEndpoint{
+		{Endpoint: "https://us-west1-gkemulticloud.googleapis.com", Location: "us-west1"},
+		{Endpoint: "https://europe-west1-gkemulticloud.googleapis.com", Location: "europe-west1"},
+	}
+	if diff := cmp.Diff(want, endpoints.gkeMultiCloudEndpoints()); diff != "" {
+		t.Errorf("gkeMultiCloudEndpoints() mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -53,100 +53,48 @@ type multicloudAPIEndpoint struct {
 	Location string
 }
 
-var multicloudAPIEndpoints = []multicloudAPIEndpoint{
-	{
-		Endpoint: "https://asia-east2-gkemulticloud.googleapis.com",
-		Location: "asia-east2",
-	},
-	{
-		Endpoint: "https://asia-northeast2-gkemulticloud.googleapis.com",
-		Location: "asia-northeast2",
-	},
-	{
-		Endpoint: "https://asia-south1-gkemulticloud.googleapis.com",
-		Location: "asia-south1",
-	},
-	{
-		Endpoint: "https://asia-southeast1-gkemulticloud.googleapis.com",
-		Location: "asia-southeast1",
-	},
-	{
-		Endpoint: "https://asia-southeast2-gkemulticloud.googleapis.com",
-		Location: "asia-southeast2",
-	},
-	{
-		Endpoint: "https://australia-southeast1-gkemulticloud.googleapis.com",
-		Location: "australia-southeast1",
-	},
-	{
-		Endpoint: "https://europe-north1-gkemulticloud.googleapis.com",
-		Location: "europe-north1",
-	},
-	{
-		Endpoint: "https://europe-west1-gkemulticloud.googleapis.com",
-		Location: "europe-west1",
-	},
-	{
-		Endpoint: "https://europe-west2-gkemulticloud.googleapis.com",
-		Location: "europe-west2",
-	},
-	{
-		Endpoint: "https://europe-west3-gkemulticloud.googleapis.com",
-		Location: "europe-west3",
-	},
-	{
-		Endpoint: "https://europe-west4-gkemulticloud.googleapis.com",
-		Location: "europe-west4",
-	},
-	{
-		Endpoint: "https://europe-west6-gkemulticloud.googleapis.com",
-		Location: "europe-west6",
-	},
-	{
-		Endpoint: "https://europe-west9-gkemulticloud.googleapis.com",
-		Location: "europe-west9",
-	},
-	{
-		Endpoint: "https://northamerica-northeast1-gkemulticloud.googleapis.com",
-		Location: "northamerica-northeast1",
-	},
-	{
-		Endpoint: "https://southamerica-east1-gkemulticloud.googleapis.com",
-		Location: "southamerica-east1",
-	},
-	{
-		Endpoint: "https://us-east4-gkemulticloud.googleapis.com",
-		Location: "us-east4",
-	},
-	{
-		Endpoint: "https://us-west1-gkemulticloud.googleapis.com",
-		Location: "us-west1",
-	},
-}
-
 type GCPClientImpl struct {
 	BaseClient httpclient.HTTPClient[*http.Response]
+	// Endpoints is the set of base URLs of the APIs called from this client. The endpoints in googleapis.com are used when it's nil.
+	Endpoints *Endpoints
 	// This is a parameter for limiting the result length of List log entries api call for testing purpose.
 	MaxLogEntries int
+	// Identity identifies the credential of the user used in this client. It's empty when the credential of the KHI process is used.
+	Identity string
 }
 
 // Digest implements task.CachableDependency.
+// Clients sending requests to the same Cloud Logging endpoint with the same credential return the same digest.
 func (pi *GCPClientImpl) Digest() string {
-	return "singleton"
+	if pi.Identity == "" {
+		return pi.endpoints().Logging
+	}
+	return pi.endpoints().Logging + "#" + pi.Identity
 }
 
 var _ cache.CacheDependency = (*GCPClientImpl)(nil)
 
 var _ GCPClient = (*GCPClientImpl)(nil)
 
-func NewGCPClient(refresher token.TokenRefresher, headerProviders []httpclient.HTTPHeaderProvider) (GCPClient, error) {
+// NewGCPClient returns a GCPClient sending requests to the given endpoints with the given transport. http.DefaultTransport is used when the transport is nil.
+func NewGCPClient(refresher token.TokenRefresher, headerProviders []httpclient.HTTPHeaderProvider, transport http.RoundTripper, endpoints *Endpoints) (GCPClient, error) {
+	baseClient := httpclient.NewBasicHttpClient().WithHeaderProvider(headerProviders...)
+	baseClient.Transport = transport
 	return &GCPClientImpl{
-		BaseClient: httpclient.NewRetryHttpClient(httpclient.NewBasicHttpClient().WithHeaderProvider(headerProviders...), MinWaitTimeOnRetriableError, MaxWaitTimeOnRetriableError, MaxRetryCount, RetriableHttpResponseCodes, RetriableWithRefreshingTokenHttpResponseCodes,
-			refresher),
+		BaseClient: httpclient.NewTracingHttpClient(httpclient.NewRetryHttpClient(baseClient, MinWaitTimeOnRetriableError, MaxWaitTimeOnRetriableError, MaxRetryCount, RetriableHttpResponseCodes, RetriableWithRefreshingTokenHttpResponseCodes,
+			refresher)),
+		Endpoints:     endpoints,
 		MaxLogEntries: math.MaxInt,
 	}, nil
 }
 
+func (c *GCPClientImpl) endpoints() *Endpoints {
+	if c.Endpoints == nil {
+		return NewEndpoints(DefaultUniverseDomain)
+	}
+	return c.Endpoints
+}
+
 func (c *GCPClientImpl) CreateGCPHttpRequest(ctx context.Context, method string, url string, body io.Reader) (*http.Request, error) {
 	req, err := http.NewRequestWithContext(ctx, method, url, body)
 	if err != nil {
@@ -184,7 +132,7 @@ func (c *GCPClientImpl) GetClusters(ctx context.Context, projectId string) ([]Cl
 	pc := NewPageClient[clusterListResponse](c.BaseClient)
 	clusterListResponses, err := pc.GetAll(ctx, func(hasToken bool, nextPageToken string) (*http.Request, error) {
 		// location="-" is a special literal to express "all locations"
-		endpoint := fmt.Sprintf("https://container.googleapis.com/v1/projects/%s/locations/-/clusters", projectId)
+		endpoint := fmt.Sprintf("%s/v1/projects/%s/locations/-/clusters", c.endpoints().Container, projectId)
 		if nextPageToken != "-" {
 			endpoint += "?pageToken=" + nextPageToken
 		}
@@ -219,7 +167,7 @@ func (c *GCPClientImpl) GetAnthosAWSClusterNames(ctx context.Context, projectId
 	var lock sync.Mutex
 	var wg sync.WaitGroup
 
-	for _, endpoint := range multicloudAPIEndpoints {
+	for _, endpoint := range c.endpoints().gkeMultiCloudEndpoints() {
 		wg.Add(1)
 		go func(endpoint multicloudAPIEndpoint) {
 			defer wg.Done()
@@ -268,7 +216,7 @@ func (c *GCPClientImpl) GetAnthosAzureClusterNames(ctx context.Context, projectI
 	var lock sync.Mutex
 	var wg sync.WaitGroup
 
-	for _, endpoint := range multicloudAPIEndpoints {
+	for _, endpoint := range c.endpoints().gkeMultiCloudEndpoints() {
 		wg.Add(1)
 		go func(endpoint multicloudAPIEndpoint) {
 			defer wg.Done()
@@ -340,7 +288,7 @@ func (c *GCPClientImpl) GetAnthosOnBaremetalClusterNames(ctx context.Context, pr
 		defer wg.Done()
 		pc := NewPageClient[clusterListResponse](c.BaseClient)
 		clusterLists, err := pc.GetAll(ctx, func(hasToken bool, nextPageToken string) (*http.Request, error) {
-			endpoint := fmt.Sprintf("https://gkeonprem.googleapis.com/v1/projects/%s/locations/-/bareMetalClusters", projectId)
+			endpoint := fmt.Sprintf("%s/v1/projects/%s/locations/-/bareMetalClusters", c.endpoints().GKEOnPrem, projectId)
 			if hasToken {
 				endpoint += "?pageToken=" + nextPageToken
 			}
@@ -364,7 +312,7 @@ func (c *GCPClientImpl) GetAnthosOnBaremetalClusterNames(ctx context.Context, pr
 		defer wg.Done()
 		pac := NewPageClient[clusterAdminListResponse](c.BaseClient)
 		clusterAdminLists, err := pac.GetAll(ctx, func(hasToken bool, nextPageToken string) (*http.Request, error) {
-			endpoint := fmt.Sprintf("https://gkeonprem.googleapis.com/v1/projects/%s/locations/-/bareMetalAdminClusters", projectId)
+			endpoint := fmt.Sprintf("%s/v1/projects/%s/locations/-/bareMetalAdminClusters", c.endpoints().GKEOnPrem, projectId)
 			if hasToken {
 				endpoint += "?pageToken=" + nextPageToken
 			}
@@ -425,7 +373,7 @@ func (c *GCPClientImpl) GetAnthosOnVMWareClusterNames(ctx context.Context, proje
 		defer wg.Done()
 		pc := NewPageClient[clusterListResponse](c.BaseClient)
 		clusterLists, err := pc.GetAll(ctx, func(hasToken bool, nextPageToken string) (*http.Request, error) {
-			endpoint := fmt.Sprintf("https://gkeonprem.googleapis.com/v1/projects/%s/locations/-/vmwareClusters", projectId)
+			endpoint := fmt.Sprintf("%s/v1/projects/%s/locations/-/vmwareClusters", c.endpoints().GKEOnPrem, projectId)
 			if hasToken {
 				endpoint += "?pageToken=" + nextPageToken
 			}
@@ -449,7 +397,7 @@ func (c *GCPClientImpl) GetAnthosOnVMWareClusterNames(ctx context.Context, proje
 		defer wg.Done()
 		pac := NewPageClient[clusterAdminListResponse](c.BaseClient)
 		clusterAdminLists, err := pac.GetAll(ctx, func(hasToken bool, nextPageToken string) (*http.Request, error) {
-			endpoint := fmt.Sprintf("https://gkeonprem.googleapis.com/v1/projects/%s/locations/-/vmwareAdminClusters", projectId)
+			endpoint := fmt.Sprintf("%s/v1/projects/%s/locations/-/vmwareAdminClusters", c.endpoints().GKEOnPrem, projectId)
 			if hasToken {
 				endpoint += "?pageToken=" + nextPageToken
 			}
@@ -484,7 +432,7 @@ func (c *GCPClientImpl) GetFleetMembershipNames(ctx context.Context, projectId s
 	}
 	pc := NewPageClient[clusterAdminListResponse](c.BaseClient)
 	membershipLists, err := pc.GetAll(ctx, func(hasToken bool, nextPageToken string) (*http.Request, error) {
-		endpoint := fmt.Sprintf("https://gkehub.googleapis.com/v1/projects/%s/locations/-/memberships", projectId)
+		endpoint := fmt.Sprintf("%s/v1/projects/%s/locations/-/memberships", c.endpoints().GKEHub, projectId)
 		if hasToken {
 			endpoint += "?pageToken=" + nextPageToken
 		}
@@ -525,7 +473,7 @@ func (c *GCPClientImpl) GetComposerEnvironmentNames(ctx context.Context, project
 			}
 			continue
 		default:
-			endpoint := fmt.Sprintf("https://composer.googleapis.com/v1/projects/%s/locations/%s/environments", projectId, location)
+			endpoint := fmt.Sprintf("%s/v1/projects/%s/locations/%s/environments", c.endpoints().Composer, projectId, location)
 			if nextPageToken != "-" {
 				endpoint += "?pageToken=" + nextPageToken
 			}
@@ -572,7 +520,7 @@ func (c *GCPClientImpl) ListRegions(ctx context.Context, projectId string) ([]st
 		Items []item `json:"items"`
 	}
 
-	endpoint := fmt.Sprintf("https://compute.googleapis.com/compute/v1/projects/%s/regions", projectId)
+	endpoint := fmt.Sprintf("%s/compute/v1/projects/%s/regions", c.endpoints().Compute, projectId)
 	req, err := c.CreateGCPHttpRequest(ctx, "GET", endpoint, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create GCP HTTP request: %w", err)
@@ -608,7 +556,7 @@ func (c *GCPClientImpl) ListLogEntries(ctx context.Context, resourceNames []stri
 	}
 	defer close(logSink)
 
-	ENDPOINT := "https://logging.googleapis.com/v2/entries:list"
+	ENDPOINT := c.endpoints().Logging + "/v2/entries:list"
 	MAXIMUM_PAGE_SIZE := 1000
 
 	nextPageToken := ""
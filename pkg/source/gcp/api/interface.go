@@ -28,10 +28,17 @@ type GCPClient interface {
 	GetAnthosOnBaremetalClusterNames(ctx context.Context, projectId string) ([]string, error)
 	GetAnthosOnVMWareClusterNames(ctx context.Context, projectId string) ([]string, error)
 	GetComposerEnvironmentNames(ctx context.Context, projectId string, location string) ([]string, error)
+	GetFleetMembershipNames(ctx context.Context, projectId string) ([]string, error)
 	ListLogEntries(ctx context.Context, resourceNames []string, filter string, logSink chan *log.Log) error
 	ListRegions(ctx context.Context, projectId string) ([]string, error)
 }
 
+// LogEntryLister lists log entries matching the filter in the Cloud Logging query language.
+type LogEntryLister interface {
+	// ListLogEntries sends log entries matching the filter to the logSink in the timestamp order and closes the logSink at the end.
+	ListLogEntries(ctx context.Context, resourceNames []string, filter string, logSink chan *log.Log) error
+}
+
 type RefreshableToken interface {
 	Refresh() (string, error)
 }
@@ -0,0 +1,332 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gcp
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/common/cache"
+	"github.com/GoogleCloudPlatform/khi/pkg/common/token"
+	"github.com/GoogleCloudPlatform/khi/pkg/inspection"
+	inspection_common "github.com/GoogleCloudPlatform/khi/pkg/inspection/common"
+	"github.com/GoogleCloudPlatform/khi/pkg/inspection/credential"
+	"github.com/GoogleCloudPlatform/khi/pkg/inspection/logger"
+	query_metadata "github.com/GoogleCloudPlatform/khi/pkg/inspection/metadata/query"
+	inspection_task "github.com/GoogleCloudPlatform/khi/pkg/inspection/task"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/history/reader"
+	"github.com/GoogleCloudPlatform/khi/pkg/parameters"
+	common "github.com/GoogleCloudPlatform/khi/pkg/source/common/k8s_audit"
+	"github.com/GoogleCloudPlatform/khi/pkg/source/gcp/api"
+	"github.com/GoogleCloudPlatform/khi/pkg/source/gcp/query"
+	"github.com/GoogleCloudPlatform/khi/pkg/source/gcp/query/querycache"
+	"github.com/GoogleCloudPlatform/khi/pkg/source/gcp/task/gke"
+	gke_audit_taskid "github.com/GoogleCloudPlatform/khi/pkg/source/gcp/task/gke/gke_audit/taskid"
+	k8s_event_taskid "github.com/GoogleCloudPlatform/khi/pkg/source/gcp/task/gke/k8s_event/taskid"
+	"github.com/GoogleCloudPlatform/khi/pkg/testutil"
+	"github.com/GoogleCloudPlatform/khi/pkg/testutil/gcp/fakegcp"
+	"github.com/google/go-cmp/cmp"
+
+	_ "github.com/GoogleCloudPlatform/khi/internal/testflags"
+)
+
+// useFakeGCPServer replaces the default GCPClientFactory and the retry waits for the test.
+func useFakeGCPServer(t *testing.T, server *fakegcp.Server) {
+	t.Helper()
+	originalFactory := api.DefaultGCPClientFactory
+	originalMinWait, originalMaxWait := api.MinWaitTimeOnRetriableError, api.MaxWaitTimeOnRetriableError
+	api.DefaultGCPClientFactory = server.NewClientFactory()
+	api.MinWaitTimeOnRetriableError, api.MaxWaitTimeOnRetriableError = 0, 0
+	t.Cleanup(func() {
+		api.DefaultGCPClientFactory = originalFactory
+		api.MinWaitTimeOnRetriableError, api.MaxWaitTimeOnRetriableError = originalMinWait, originalMaxWait
+	})
+}
+
+// useTemporaryDataFolders makes inspections write their files in a temporary folder.
+func useTemporaryDataFolders(t *testing.T) {
+	t.Helper()
+	originalDataDestinationFolder, originalTemporaryFolder := parameters.Common.DataDestinationFolder, parameters.Common.TemporaryFolder
+	folder := t.TempDir()
+	parameters.Common.DataDestinationFolder = &folder
+	parameters.Common.TemporaryFolder = &folder
+	t.Cleanup(func() {
+		parameters.Common.DataDestinationFolder, parameters.Common.TemporaryFolder = originalDataDestinationFolder, originalTemporaryFolder
+	})
+}
+
+// newInspectionRunner returns the runner of a new inspection with the given features on an inspection server registering GCP tasks.
+func newInspectionRunner(t *testing.T, inspectionType string, features []string) *inspection.InspectionTaskRunner {
+	t.Helper()
+	logger.InitGlobalKHILogger()
+	inspectionServer, err := inspection.NewServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, prepare := range []inspection.PrepareInspectionServerFunc{inspection_common.PrepareInspectionServer, common.Register, PrepareInspectionServer} {
+		if err := prepare(inspectionServer); err != nil {
+			t.Fatal(err)
+		}
+	}
+	inspectionID, err := inspectionServer.CreateInspection(inspectionType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	runner := inspectionServer.GetInspection(inspectionID)
+	if err := runner.SetFeatureList(features); err != nil {
+		t.Fatal(err)
+	}
+	return runner
+}
+
+func runInspection(t *testing.T, inspectionType string, features []string, values map[string]any) *reader.Reader {
+	t.Helper()
+	runner := newInspectionRunner(t, inspectionType, features)
+	if err := runner.Run(context.Background(), &inspection_task.InspectionRequest{Values: values}); err != nil {
+		t.Fatal(err)
+	}
+	<-runner.Wait()
+	result, err := runner.Result()
+	if err != nil {
+		t.Fatal(err)
+	}
+	khiFile, err := reader.OpenStore(result.ResultStore)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { khiFile.Close() })
+	return khiFile
+}
+
+func TestGKEInspectionWithFakeGCPServer(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping the end-to-end inspection test in short mode")
+	}
+	testutil.InitTestIO()
+	eventLog := testutil.MustReadText("test/logs/k8s_event/sample.yaml")
+	server := fakegcp.NewServer()
+	defer server.Close()
+	server.SetClusters("project-id", api.Cluster{Name: "gke-basic-1"})
+	err := server.AddLogEntries(
+		eventLog,
+		// A log from another cluster must not be included.
+		strings.ReplaceAll(strings.ReplaceAll(eventLog, "gke-basic-1", "gke-basic-2"), "1h0dswnf2ms3tx", "other-cluster-event"),
+		// A log out of the time range must not be included.
+		testutil.MustReadText("test/logs/gke_audit/cluster_creation_started.yaml"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The first request to the logging API fails and is retried.
+	server.InjectFault(fakegcp.Fault{PathContains: "entries:list", StatusCode: 429, Count: 1})
+	useFakeGCPServer(t, server)
+	useTemporaryDataFolders(t)
+
+	khiFile := runInspection(t, gke.InspectionTypeId, []string{
+		k8s_event_taskid.GKEK8sEventLogParserTaskID.String(),
+		gke_audit_taskid.GKEAuditParserTaskID.String(),
+	}, map[string]any{
+		"cloud.google.com/input/project-id":   "project-id",
+		"cloud.google.com/input/cluster-name": "gke-basic-1",
+		"cloud.google.com/input/end-time":     "2024-09-13T02:00:00Z",
+		"cloud.google.com/input/duration":     "1h",
+	})
+
+	gotLogIDs := []string{}
+	for _, l := range khiFile.History.Logs {
+		gotLogIDs = append(gotLogIDs, l.DisplayId)
+	}
+	if diff := cmp.Diff([]string{"1h0dswnf2ms3tx"}, gotLogIDs); diff != "" {
+		t.Errorf("logs in the inspection result mismatch (-want +got):\n%s", diff)
+	}
+	for _, request := range server.Requests() {
+		if request.Path != "/v2/entries:list" {
+			continue
+		}
+		if !strings.Contains(request.Body, "projects/project-id") {
+			t.Errorf("entries:list was requested without the project resource name: %s", request.Body)
+		}
+	}
+	if !slices.ContainsFunc(server.Requests(), func(r fakegcp.RecordedRequest) bool { return r.Host == "logging.googleapis.com" }) {
+		t.Errorf("no request was sent to logging.googleapis.com")
+	}
+}
+
+func TestGKEInspectionWithQueryCache(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping the end-to-end inspection test in short mode")
+	}
+	testutil.InitTestIO()
+	server := fakegcp.NewServer()
+	defer server.Close()
+	server.SetClusters("project-id", api.Cluster{Name: "gke-basic-1"})
+	if err := server.AddLogEntries(testutil.MustReadText("test/logs/k8s_event/sample.yaml")); err != nil {
+		t.Fatal(err)
+	}
+	useFakeGCPServer(t, server)
+	useTemporaryDataFolders(t)
+	storage, err := cache.NewFileSystemCacheItemStorageProvider(t.TempDir(), 1<<20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	originalCache := query.DefaultQueryResultCache
+	query.DefaultQueryResultCache = querycache.NewQueryResultCache(cache.NewGZipCacheItemStorageProvider(storage))
+	t.Cleanup(func() { query.DefaultQueryResultCache = originalCache })
+
+	countListRequests := func() int {
+		count := 0
+		for _, request := range server.Requests() {
+			if request.Path == "/v2/entries:list" {
+				count++
+			}
+		}
+		return count
+	}
+	values := map[string]any{
+		"cloud.google.com/input/project-id":   "project-id",
+		"cloud.google.com/input/cluster-name": "gke-basic-1",
+		"cloud.google.com/input/end-time":     "2024-09-13T02:00:00Z",
+		"cloud.google.com/input/duration":     "1h",
+	}
+	features := []string{k8s_event_taskid.GKEK8sEventLogParserTaskID.String()}
+
+	firstRunCount := 0
+	for i := 0; i < 2; i++ {
+		khiFile := runInspection(t, gke.InspectionTypeId, features, values)
+		if len(khiFile.History.Logs) != 1 {
+			t.Errorf("run #%d: got %d logs, want 1", i, len(khiFile.History.Logs))
+		}
+		if i == 0 {
+			firstRunCount = countListRequests()
+			if firstRunCount == 0 {
+				t.Fatalf("no entries:list request was sent in the first run")
+			}
+		}
+	}
+	if count := countListRequests() - firstRunCount; count != 0 {
+		t.Errorf("entries:list was requested %d times in the second run, want logs served from the cache", count)
+	}
+}
+
+func TestGKEDryRunEstimatesLogVolume(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping the end-to-end inspection test in short mode")
+	}
+	testutil.InitTestIO()
+	server := fakegcp.NewServer()
+	defer server.Close()
+	server.SetClusters("project-id", api.Cluster{Name: "gke-basic-1"})
+	if err := server.AddLogEntries(testutil.MustReadText("test/logs/k8s_event/sample.yaml")); err != nil {
+		t.Fatal(err)
+	}
+	useFakeGCPServer(t, server)
+	useTemporaryDataFolders(t)
+
+	values := map[string]any{
+		"cloud.google.com/input/project-id":   "project-id",
+		"cloud.google.com/input/cluster-name": "gke-basic-1",
+		"cloud.google.com/input/end-time":     "2024-09-13T01:52:00Z",
+		// Logs in a time range shorter than the probes are counted exactly.
+		"cloud.google.com/input/duration": "5m",
+	}
+	runner := newInspectionRunner(t, gke.InspectionTypeId, []string{k8s_event_taskid.GKEK8sEventLogParserTaskID.String()})
+	for _, estimateVolume := range []bool{false, true} {
+		result, err := runner.DryRun(context.Background(), &inspection_task.InspectionRequest{Values: values, EstimateVolume: estimateVolume})
+		if err != nil {
+			t.Fatal(err)
+		}
+		queries := result.Metadata.(map[string]any)["query"].([]*query_metadata.QueryItem)
+		var eventQuery *query_metadata.QueryItem
+		for _, item := range queries {
+			if item.Id == k8s_event_taskid.GKEK8sEventLogQueryTaskID.String() {
+				eventQuery = item
+			}
+		}
+		if eventQuery == nil {
+			t.Fatalf("query metadata of the event log query was not found in %v", queries)
+		}
+		if !estimateVolume {
+			if eventQuery.EstimatedLogCount != nil {
+				t.Errorf("EstimatedLogCount = %d without the estimation, want nil", *eventQuery.EstimatedLogCount)
+			}
+			continue
+		}
+		if eventQuery.EstimatedLogCount == nil || *eventQuery.EstimatedLogCount != 1 {
+			t.Errorf("EstimatedLogCount = %v, want 1", eventQuery.EstimatedLogCount)
+		}
+	}
+}
+
+func TestGKEAutocompleteIsNotSharedAmongCredentials(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping the end-to-end inspection test in short mode")
+	}
+	testutil.InitTestIO()
+	server := fakegcp.NewServer()
+	defer server.Close()
+	server.SetClusters("project-id", api.Cluster{Name: "gke-basic-1"})
+	useFakeGCPServer(t, server)
+	useTemporaryDataFolders(t)
+	// Clients are bound to the credential of the inspection only when the factory has the default access token store.
+	api.DefaultGCPClientFactory.AccessTokenStore = token.NewBasicTokenStore("test", token.NewSpyTokenResolver())
+
+	countClusterListRequests := func() int {
+		count := 0
+		for _, request := range server.Requests() {
+			if request.Host == "container.googleapis.com" {
+				count++
+			}
+		}
+		return count
+	}
+	values := map[string]any{
+		"cloud.google.com/input/project-id": "project-id",
+	}
+	testCases := []struct {
+		name            string
+		credential      *credential.Credential
+		wantNewRequests int
+	}{
+		{
+			name:            "first user",
+			credential:      &credential.Credential{AccessToken: "token-a"},
+			wantNewRequests: 1,
+		},
+		{
+			name:            "first user again",
+			credential:      &credential.Credential{AccessToken: "token-a"},
+			wantNewRequests: 0,
+		},
+		{
+			name:            "another user",
+			credential:      &credential.Credential{AccessToken: "token-b"},
+			wantNewRequests: 1,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			before := countClusterListRequests()
+			runner := newInspectionRunner(t, gke.InspectionTypeId, []string{k8s_event_taskid.GKEK8sEventLogParserTaskID.String()})
+			if _, err := runner.DryRun(context.Background(), &inspection_task.InspectionRequest{Values: values, Credential: tc.credential}); err != nil {
+				t.Fatal(err)
+			}
+			if got := countClusterListRequests() - before; got != tc.wantNewRequests {
+				t.Errorf("the cluster list was requested %d times, want %d", got, tc.wantNewRequests)
+			}
+		})
+	}
+}
@@ -19,18 +19,19 @@ import (
 	baremetal "github.com/GoogleCloudPlatform/khi/pkg/source/gcp/task/gdcv-for-baremetal"
 	vmware "github.com/GoogleCloudPlatform/khi/pkg/source/gcp/task/gdcv-for-vmware"
 	"github.com/GoogleCloudPlatform/khi/pkg/source/gcp/task/gke"
+	logexport "github.com/GoogleCloudPlatform/khi/pkg/source/gcp/task/gke-logging-export"
 	aws "github.com/GoogleCloudPlatform/khi/pkg/source/gcp/task/gke-on-aws"
 	azure "github.com/GoogleCloudPlatform/khi/pkg/source/gcp/task/gke-on-azure"
 )
 
 // GCPK8sClusterInspectionTypes is the list of inspection types of k8s clusters from Google Cloud.
 var GCPK8sClusterInspectionTypes = []string{
-	gke.InspectionTypeId, composer_inspection_type.InspectionTypeId, vmware.InspectionTypeId, baremetal.InspectionTypeId, aws.InspectionTypeId, azure.InspectionTypeId,
+	gke.InspectionTypeId, composer_inspection_type.InspectionTypeId, vmware.InspectionTypeId, baremetal.InspectionTypeId, aws.InspectionTypeId, azure.InspectionTypeId, logexport.InspectionTypeId,
 }
 
 // GKEBasedClusterInspectionTypes is the list of inspection types of GKE.
 var GKEBasedClusterInspectionTypes = []string{
-	gke.InspectionTypeId, composer_inspection_type.InspectionTypeId,
+	gke.InspectionTypeId, composer_inspection_type.InspectionTypeId, logexport.InspectionTypeId,
 }
 
 // GKEMultiCloudClusterInspectionTypes is the list of inspection types of GKE multicloud.
@@ -42,3 +43,8 @@ var GKEMultiCloudClusterInspectionTypes = []string{
 var GDCClusterInspectionTypes = []string{
 	baremetal.InspectionTypeId, vmware.InspectionTypeId,
 }
+
+// CloudLoggingAPIInspectionTypes is the list of inspection types querying logs through the Cloud Logging API.
+var CloudLoggingAPIInspectionTypes = []string{
+	gke.InspectionTypeId, composer_inspection_type.InspectionTypeId, vmware.InspectionTypeId, baremetal.InspectionTypeId, aws.InspectionTypeId, azure.InspectionTypeId,
+}
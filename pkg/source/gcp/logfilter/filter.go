@@ -0,0 +1,378 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package logfilter evaluates filters written in the Cloud Logging query language against log entries locally.
+package logfilter
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/common/structurev2"
+)
+
+// Filter is a parsed Cloud Logging filter.
+type Filter struct {
+	expression expression
+}
+
+// Parse parses the given filter written in the Cloud Logging query language.
+// An empty filter matches every log entry.
+func Parse(filter string) (*Filter, error) {
+	tokens, err := tokenize(filter)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 1 {
+		return &Filter{expression: &andExpression{}}, nil
+	}
+	p := &parser{tokens: tokens}
+	expression, err := p.parseAnd(p.parseRestriction)
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokenEOF {
+		return nil, p.unexpected(t, "end of filter")
+	}
+	return &Filter{expression: expression}, nil
+}
+
+// Match returns true when the given LogEntry matches the filter.
+func (f *Filter) Match(entry *structurev2.NodeReader) bool {
+	return f.expression.evaluate(entry)
+}
+
+// expression is a node of the parsed filter.
+type expression interface {
+	evaluate(entry *structurev2.NodeReader) bool
+}
+
+type andExpression struct {
+	children []expression
+}
+
+func (a *andExpression) evaluate(entry *structurev2.NodeReader) bool {
+	for _, child := range a.children {
+		if !child.evaluate(entry) {
+			return false
+		}
+	}
+	return true
+}
+
+type orExpression struct {
+	children []expression
+}
+
+func (o *orExpression) evaluate(entry *structurev2.NodeReader) bool {
+	for _, child := range o.children {
+		if child.evaluate(entry) {
+			return true
+		}
+	}
+	return false
+}
+
+type notExpression struct {
+	child expression
+}
+
+func (n *notExpression) evaluate(entry *structurev2.NodeReader) bool {
+	return !n.child.evaluate(entry)
+}
+
+// comparison compares a field of the log entry with the value.
+// When the field is repeated, the comparison is true if any of the elements matches.
+type comparison struct {
+	fieldPath []string
+	operator  string
+	value     string
+	pattern   *regexp.Regexp
+}
+
+func (c *comparison) evaluate(entry *structurev2.NodeReader) bool {
+	for _, node := range resolveFieldPath(entry.Node, c.fieldPath) {
+		if c.evaluateNode(node) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *comparison) evaluateNode(node structurev2.Node) bool {
+	if node.Type() != structurev2.ScalarNodeType {
+		// `field:*` tests the existence of the field.
+		return c.operator == ":" && c.value == "*"
+	}
+	scalar, err := node.NodeScalarValue()
+	if err != nil || scalar == nil {
+		return false
+	}
+	if c.operator == ":" && c.value == "*" {
+		return true
+	}
+	fieldValue := scalarToString(scalar)
+	switch c.operator {
+	case ":":
+		return strings.Contains(strings.ToLower(fieldValue), strings.ToLower(c.value))
+	case "=~":
+		return c.pattern.MatchString(fieldValue)
+	case "!~":
+		return !c.pattern.MatchString(fieldValue)
+	}
+	order, comparable := compareValues(scalar, fieldValue, c.value)
+	if len(c.fieldPath) == 1 && c.fieldPath[0] == "severity" {
+		order, comparable = compareSeverities(fieldValue, c.value)
+	}
+	if !comparable {
+		return false
+	}
+	switch c.operator {
+	case "=":
+		return order == 0
+	case "!=":
+		return order != 0
+	case "<":
+		return order < 0
+	case "<=":
+		return order <= 0
+	case ">":
+		return order > 0
+	case ">=":
+		return order >= 0
+	}
+	return false
+}
+
+// resolveFieldPath returns nodes at the field path. Sequences in the middle of the path are expanded to their elements.
+func resolveFieldPath(node structurev2.Node, fieldPath []string) []structurev2.Node {
+	if node.Type() == structurev2.SequenceNodeType {
+		result := []structurev2.Node{}
+		for _, child := range node.Children() {
+			result = append(result, resolveFieldPath(child, fieldPath)...)
+		}
+		return result
+	}
+	if len(fieldPath) == 0 {
+		return []structurev2.Node{node}
+	}
+	if node.Type() != structurev2.MapNodeType {
+		return nil
+	}
+	for key, child := range node.Children() {
+		if key.Key == fieldPath[0] {
+			return resolveFieldPath(child, fieldPath[1:])
+		}
+	}
+	return nil
+}
+
+// compareValues compares the field value with the value in the filter as timestamps, numbers or strings in this order.
+// The second result is false when these values can't be compared.
+func compareValues(scalar any, fieldValue string, filterValue string) (int, bool) {
+	fieldTime, isFieldTime := scalar.(time.Time)
+	if !isFieldTime {
+		fieldTime, isFieldTime = parseTimestamp(fieldValue)
+	}
+	if isFieldTime {
+		filterTime, isFilterTime := parseTimestamp(filterValue)
+		if isFilterTime {
+			return fieldTime.Compare(filterTime), true
+		}
+	}
+	fieldNumber, fieldErr := strconv.ParseFloat(fieldValue, 64)
+	filterNumber, filterErr := strconv.ParseFloat(filterValue, 64)
+	if fieldErr == nil && filterErr == nil {
+		switch {
+		case fieldNumber < filterNumber:
+			return -1, true
+		case fieldNumber > filterNumber:
+			return 1, true
+		default:
+			return 0, true
+		}
+	}
+	return strings.Compare(fieldValue, filterValue), true
+}
+
+// severityLevels is the order of LogSeverity values. Severities are compared in this order instead of the alphabetical order.
+var severityLevels = map[string]int{
+	"DEFAULT":   0,
+	"DEBUG":     100,
+	"INFO":      200,
+	"NOTICE":    300,
+	"WARNING":   400,
+	"ERROR":     500,
+	"CRITICAL":  600,
+	"ALERT":     700,
+	"EMERGENCY": 800,
+}
+
+// compareSeverities compares severities given as their names or their numeric values.
+func compareSeverities(fieldValue string, filterValue string) (int, bool) {
+	fieldLevel, fieldOk := severityLevel(fieldValue)
+	filterLevel, filterOk := severityLevel(filterValue)
+	if !fieldOk || !filterOk {
+		return 0, false
+	}
+	return fieldLevel - filterLevel, true
+}
+
+func severityLevel(value string) (int, bool) {
+	if level, found := severityLevels[strings.ToUpper(value)]; found {
+		return level, true
+	}
+	level, err := strconv.Atoi(value)
+	return level, err == nil
+}
+
+var timestampLayouts = []string{time.RFC3339Nano, "2006-01-02T15:04:05.999999999Z0700", "2006-01-02T15:04:05Z0700", "2006-01-02"}
+
+func parseTimestamp(value string) (time.Time, bool) {
+	for _, layout := range timestampLayouts {
+		t, err := time.Parse(layout, value)
+		if err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func scalarToString(scalar any) string {
+	switch v := scalar.(type) {
+	case string:
+		return v
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+// filterFunction instantiates the expression of a function call from its arguments.
+type filterFunction = func(args []token) (expression, error)
+
+var functions = map[string]filterFunction{
+	"LOG_ID": newLogIDFunction,
+	"SEARCH": newSearchFunction,
+}
+
+// logIDFunction matches the log entries with the given log ID regardless of the parent resource in the logName.
+type logIDFunction struct {
+	logIDs []string
+}
+
+func newLogIDFunction(args []token) (expression, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("LOG_ID requires exactly 1 argument but %d were given", len(args))
+	}
+	logID, err := url.PathUnescape(args[0].value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid log ID %q: %w", args[0].value, err)
+	}
+	return &logIDFunction{
+		logIDs: []string{logID, url.PathEscape(logID)},
+	}, nil
+}
+
+func (l *logIDFunction) evaluate(entry *structurev2.NodeReader) bool {
+	logName := entry.ReadStringOrDefault("logName", "")
+	for _, logID := range l.logIDs {
+		if strings.HasSuffix(logName, "/logs/"+logID) {
+			return true
+		}
+	}
+	return false
+}
+
+// globalRestriction matches the log entries containing the value in any field. Values are compared case insensitively.
+type globalRestriction struct {
+	value string
+}
+
+func (g *globalRestriction) evaluate(entry *structurev2.NodeReader) bool {
+	value := strings.ToLower(g.value)
+	return anyScalar(entry.Node, func(fieldValue string) bool {
+		return strings.Contains(strings.ToLower(fieldValue), value)
+	})
+}
+
+// searchFunction matches the log entries containing every token in the query like `SEARCH("foo bar")` or `SEARCH(textPayload, "foo")`.
+// Values are split into tokens at characters other than letters and digits, and tokens are compared case insensitively.
+type searchFunction struct {
+	fieldPath []string
+	tokens    []string
+}
+
+func newSearchFunction(args []token) (expression, error) {
+	switch len(args) {
+	case 1:
+		return &searchFunction{tokens: searchTokens(args[0].value)}, nil
+	case 2:
+		if args[0].kind != tokenText {
+			return nil, fmt.Errorf("the first argument of SEARCH must be a field path but %q was given", args[0].value)
+		}
+		return &searchFunction{fieldPath: args[0].segments, tokens: searchTokens(args[1].value)}, nil
+	default:
+		return nil, fmt.Errorf("SEARCH requires 1 or 2 arguments but %d were given", len(args))
+	}
+}
+
+func (s *searchFunction) evaluate(entry *structurev2.NodeReader) bool {
+	found := map[string]struct{}{}
+	for _, node := range resolveFieldPath(entry.Node, s.fieldPath) {
+		anyScalar(node, func(fieldValue string) bool {
+			for _, token := range searchTokens(fieldValue) {
+				found[token] = struct{}{}
+			}
+			return false
+		})
+	}
+	for _, token := range s.tokens {
+		if _, ok := found[token]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func searchTokens(value string) []string {
+	return strings.FieldsFunc(strings.ToLower(value), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// anyScalar returns true when the predicate returns true for any scalar value under the node.
+func anyScalar(node structurev2.Node, predicate func(fieldValue string) bool) bool {
+	if node.Type() == structurev2.ScalarNodeType {
+		scalar, err := node.NodeScalarValue()
+		if err != nil || scalar == nil {
+			return false
+		}
+		return predicate(scalarToString(scalar))
+	}
+	for _, child := range node.Children() {
+		if anyScalar(child, predicate) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,138 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logfilter
+
+import (
+	"testing"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/common/structurev2"
+
+	_ "github.com/GoogleCloudPlatform/khi/internal/testflags"
+)
+
+const testLogEntry = `{
+  "insertId": "abc",
+  "logName": "projects/test-project/logs/cloudaudit.googleapis.com%2Factivity",
+  "timestamp": "2025-01-01T00:00:10.123Z",
+  "severity": "INFO",
+  "resource": {
+    "type": "k8s_cluster",
+    "labels": {"cluster_name": "test-cluster", "project_id": "test-project"}
+  },
+  "labels": {"compute.googleapis.com/resource_name": "gke-test-cluster-node-1"},
+  "protoPayload": {
+    "methodName": "io.k8s.core.v1.pods.create",
+    "resourceName": "core/v1/namespaces/default/pods/nginx"
+  },
+  "jsonPayload": {
+    "count": 3,
+    "containers": [{"name": "nginx"}, {"name": "sidecar"}]
+  }
+}`
+
+func mustReadTestLogEntry(t *testing.T) *structurev2.NodeReader {
+	t.Helper()
+	node, err := structurev2.FromYAML(testLogEntry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return structurev2.NewNodeReader(node)
+}
+
+func TestFilterMatch(t *testing.T) {
+	testCases := []struct {
+		name   string
+		filter string
+		want   bool
+	}{
+		{name: "empty filter", filter: "", want: true},
+		{name: "only comments", filter: "-- nothing to filter", want: true},
+		{name: "equal", filter: `resource.type="k8s_cluster"`, want: true},
+		{name: "equal with different value", filter: `resource.type="k8s_container"`, want: false},
+		{name: "not equal", filter: `resource.type!="k8s_container"`, want: true},
+		{name: "missing field", filter: `resource.labels.location="us-central1"`, want: false},
+		{name: "negated missing field", filter: `-resource.labels.location="us-central1"`, want: true},
+		{name: "has is case insensitive substring match", filter: `protoPayload.methodName:"PODS"`, want: true},
+		{name: "has any", filter: `protoPayload:*`, want: true},
+		{name: "has any on missing field", filter: `httpRequest:*`, want: false},
+		{name: "bare value", filter: `severity=INFO`, want: true},
+		{name: "implicit AND", filter: "resource.type=\"k8s_cluster\"\nresource.labels.cluster_name=\"other-cluster\"", want: false},
+		{name: "explicit AND", filter: `resource.type="k8s_cluster" AND resource.labels.cluster_name="test-cluster"`, want: true},
+		{name: "OR", filter: `resource.type="k8s_container" OR resource.type="k8s_cluster"`, want: true},
+		{name: "OR has higher precedence than AND", filter: `resource.type="k8s_container" AND resource.type="k8s_cluster" OR severity="INFO"`, want: false},
+		{name: "NOT", filter: `NOT resource.type="k8s_cluster"`, want: false},
+		{name: "parentheses", filter: `(resource.type="k8s_container" OR severity="INFO") resource.labels.cluster_name="test-cluster"`, want: true},
+		{name: "value list", filter: `resource.type=("gke_cluster" OR "k8s_cluster")`, want: true},
+		{name: "negated value list", filter: `-protoPayload.methodName:("list" OR "get" OR "watch")`, want: true},
+		{name: "quoted field path segment", filter: `labels."compute.googleapis.com/resource_name":("node-1")`, want: true},
+		{name: "regular expression", filter: `protoPayload.methodName=~"\.(pods|services)\."`, want: true},
+		{name: "negated regular expression", filter: `protoPayload.methodName!~"\.(pods|services)\."`, want: false},
+		{name: "timestamp range", filter: "timestamp >= \"2025-01-01T00:00:00+0000\"\ntimestamp <= \"2025-01-01T00:00:10+0000\"", want: false},
+		{name: "timestamp range including the log", filter: `timestamp >= "2025-01-01T00:00:00Z" timestamp < "2025-01-01T00:01:00Z"`, want: true},
+		{name: "unquoted timestamp range", filter: `timestamp >= 2025-01-01T00:00:00Z AND timestamp < 2025-01-01T00:01:00Z`, want: true},
+		{name: "number comparison", filter: `jsonPayload.count > 2`, want: true},
+		{name: "repeated field", filter: `jsonPayload.containers.name="sidecar"`, want: true},
+		{name: "LOG_ID", filter: `LOG_ID("cloudaudit.googleapis.com/activity")`, want: true},
+		{name: "LOG_ID with encoded ID", filter: `LOG_ID("cloudaudit.googleapis.com%2Factivity")`, want: true},
+		{name: "LOG_ID with other ID", filter: `LOG_ID("events")`, want: false},
+		{name: "comment at the end of line", filter: "resource.type=\"k8s_cluster\" -- the cluster log\nseverity=\"INFO\"", want: true},
+		{name: "severity is compared in the level order", filter: `severity>=NOTICE`, want: false},
+		{name: "severity lower than the threshold", filter: `severity<WARNING`, want: true},
+		{name: "numeric severity", filter: `severity=200`, want: true},
+		{name: "global restriction", filter: `nginx`, want: true},
+		{name: "quoted global restriction", filter: `"PODS.CREATE"`, want: true},
+		{name: "global restriction without matching field", filter: `apache`, want: false},
+		{name: "global restriction with comparison", filter: `nginx resource.type="k8s_container"`, want: false},
+		{name: "SEARCH", filter: `SEARCH("pods nginx")`, want: true},
+		{name: "SEARCH matches only whole tokens", filter: `SEARCH("ngin")`, want: false},
+		{name: "SEARCH with field", filter: `SEARCH(protoPayload.resourceName, "default")`, want: true},
+		{name: "SEARCH with other field", filter: `SEARCH(resource, "default")`, want: false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			filter, err := Parse(tc.filter)
+			if err != nil {
+				t.Fatalf("Parse(%q) returned an error: %v", tc.filter, err)
+			}
+			if got := filter.Match(mustReadTestLogEntry(t)); got != tc.want {
+				t.Errorf("Match() with %q = %v, want %v", tc.filter, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestParseError(t *testing.T) {
+	testCases := []struct {
+		name   string
+		filter string
+	}{
+		{name: "unterminated string", filter: `resource.type="k8s_cluster`},
+		{name: "missing closing parenthesis", filter: `(resource.type="k8s_cluster"`},
+		{name: "missing value", filter: `resource.type=`},
+		{name: "invalid regular expression", filter: `protoPayload.methodName=~"("`},
+		{name: "unknown function", filter: `UNKNOWN("foo")`},
+		{name: "SEARCH without arguments", filter: `SEARCH()`},
+		{name: "extra closing parenthesis", filter: `resource.type="k8s_cluster")`},
+		{name: "exclamation mark without operator", filter: `foo !bar`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Parse(tc.filter)
+			if err == nil {
+				t.Errorf("Parse(%q) returned nil error, want an error", tc.filter)
+			}
+		})
+	}
+}
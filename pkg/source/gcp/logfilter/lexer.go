@@ -0,0 +1,187 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logfilter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenLeftParen
+	tokenRightParen
+	tokenMinus
+	tokenAnd
+	tokenOr
+	tokenNot
+	tokenOperator
+	// tokenText is a bare word. It is used as a field path or a value not surrounded by quotes.
+	tokenText
+	// tokenString is a value surrounded by double quotes.
+	tokenString
+)
+
+// token is a lexical token in a filter.
+type token struct {
+	kind tokenKind
+	// value is the operator for tokenOperator, the unquoted value for tokenString and the raw text for tokenText.
+	value string
+	// segments is the field path segments of tokenText. Quoted segments like `labels."k8s-pod/app"` are kept as a segment.
+	segments []string
+	// position is the byte offset of the token in the filter.
+	position int
+}
+
+// operators supported in comparisons. Longer operators must come first.
+var operators = []string{"=~", "!~", "!=", "<=", ">=", "=", ":", "<", ">"}
+
+// tokenize splits the filter into tokens. Comments starting with `--` are discarded.
+func tokenize(filter string) ([]token, error) {
+	tokens := []token{}
+	cursor := 0
+	for cursor < len(filter) {
+		c := filter[cursor]
+		switch {
+		case unicode.IsSpace(rune(c)):
+			cursor++
+		case strings.HasPrefix(filter[cursor:], "--"):
+			lineEnd := strings.IndexByte(filter[cursor:], '\n')
+			if lineEnd == -1 {
+				cursor = len(filter)
+			} else {
+				cursor += lineEnd
+			}
+		case c == '(':
+			tokens = append(tokens, token{kind: tokenLeftParen, value: "(", position: cursor})
+			cursor++
+		case c == ')':
+			tokens = append(tokens, token{kind: tokenRightParen, value: ")", position: cursor})
+			cursor++
+		case c == '-':
+			tokens = append(tokens, token{kind: tokenMinus, value: "-", position: cursor})
+			cursor++
+		case c == '"':
+			value, next, err := readQuotedString(filter, cursor)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{kind: tokenString, value: value, position: cursor})
+			cursor = next
+		default:
+			if operator := operatorAt(filter, cursor); operator != "" {
+				tokens = append(tokens, token{kind: tokenOperator, value: operator, position: cursor})
+				cursor += len(operator)
+				continue
+			}
+			if c == '!' {
+				return nil, fmt.Errorf("unexpected `!` at %d. expecting `!=` or `!~`", cursor)
+			}
+			textToken, next, err := readText(filter, cursor)
+			if err != nil {
+				return nil, err
+			}
+			switch textToken.value {
+			case "AND":
+				textToken.kind = tokenAnd
+			case "OR":
+				textToken.kind = tokenOr
+			case "NOT":
+				textToken.kind = tokenNot
+			}
+			tokens = append(tokens, textToken)
+			cursor = next
+		}
+	}
+	tokens = append(tokens, token{kind: tokenEOF, position: len(filter)})
+	return tokens, nil
+}
+
+func operatorAt(filter string, cursor int) string {
+	for _, operator := range operators {
+		if strings.HasPrefix(filter[cursor:], operator) {
+			return operator
+		}
+	}
+	return ""
+}
+
+// isTextDelimiter returns true when the character terminates a bare word.
+func isTextDelimiter(c byte) bool {
+	return unicode.IsSpace(rune(c)) || c == '(' || c == ')' || c == '"' || operatorAt(string(c), 0) != "" || c == '!'
+}
+
+// unquotedTimestampPrefix matches the beginning of an RFC3339 timestamp written without quotes. `:` in it is not regarded as the has operator.
+var unquotedTimestampPrefix = regexp.MustCompile(`^[0-9]{4}-[0-9]{2}-[0-9]{2}[Tt]`)
+
+// readText reads a bare word from the cursor. A quoted string right after `.` is read as a segment of the field path.
+func readText(filter string, cursor int) (token, int, error) {
+	start := cursor
+	segments := []string{}
+	var segment strings.Builder
+	for cursor < len(filter) {
+		c := filter[cursor]
+		if c == '"' && cursor > start && filter[cursor-1] == '.' {
+			value, next, err := readQuotedString(filter, cursor)
+			if err != nil {
+				return token{}, 0, err
+			}
+			segment.WriteString(value)
+			cursor = next
+			continue
+		}
+		if isTextDelimiter(c) && !(c == ':' && unquotedTimestampPrefix.MatchString(filter[start:cursor])) {
+			break
+		}
+		if c == '.' {
+			segments = append(segments, segment.String())
+			segment.Reset()
+		} else {
+			segment.WriteByte(c)
+		}
+		cursor++
+	}
+	segments = append(segments, segment.String())
+	return token{kind: tokenText, value: filter[start:cursor], segments: segments, position: start}, cursor, nil
+}
+
+// readQuotedString reads a string surrounded by double quotes from the cursor and returns the unquoted value and the offset after the closing quote.
+// `\"` and `\\` are unescaped. Other escape sequences are kept as is to pass them to regular expressions.
+func readQuotedString(filter string, cursor int) (string, int, error) {
+	var value strings.Builder
+	for i := cursor + 1; i < len(filter); i++ {
+		c := filter[i]
+		switch {
+		case c == '\\' && i+1 < len(filter):
+			next := filter[i+1]
+			if next == '"' || next == '\\' {
+				value.WriteByte(next)
+			} else {
+				value.WriteByte(c)
+				value.WriteByte(next)
+			}
+			i++
+		case c == '"':
+			return value.String(), i + 1, nil
+		default:
+			value.WriteByte(c)
+		}
+	}
+	return "", 0, fmt.Errorf("unterminated string starting at %d", cursor)
+}
@@ -0,0 +1,86 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logfilter
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	_ "github.com/GoogleCloudPlatform/khi/internal/testflags"
+)
+
+func TestTokenize(t *testing.T) {
+	testCases := []struct {
+		name   string
+		filter string
+		want   []string
+	}{
+		{
+			name:   "comparison",
+			filter: `resource.type!="k8s_cluster"`,
+			want:   []string{"resource.type", "!=", "k8s_cluster", ""},
+		},
+		{
+			name:   "unquoted timestamp",
+			filter: `timestamp >= 2024-01-01T00:00:00Z`,
+			want:   []string{"timestamp", ">=", "2024-01-01T00:00:00Z", ""},
+		},
+		{
+			name:   "unquoted timestamp with offset and fraction",
+			filter: `timestamp<2024-01-01T09:00:00.5+09:00`,
+			want:   []string{"timestamp", "<", "2024-01-01T09:00:00.5+09:00", ""},
+		},
+		{
+			name:   "has operator after a word",
+			filter: `labels:foo`,
+			want:   []string{"labels", ":", "foo", ""},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tokens, err := tokenize(tc.filter)
+			if err != nil {
+				t.Fatalf("tokenize(%q) returned an error: %v", tc.filter, err)
+			}
+			got := []string{}
+			for _, token := range tokens {
+				got = append(got, token.value)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("tokenize(%q) mismatch (-want +got):\n%s", tc.filter, diff)
+			}
+		})
+	}
+}
+
+func TestTokenizeError(t *testing.T) {
+	testCases := []struct {
+		name   string
+		filter string
+	}{
+		{name: "exclamation mark before a word", filter: `foo !bar`},
+		{name: "trailing exclamation mark", filter: `foo !`},
+		{name: "unterminated string", filter: `foo="bar`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := tokenize(tc.filter)
+			if err == nil {
+				t.Errorf("tokenize(%q) returned nil error, want an error", tc.filter)
+			}
+		})
+	}
+}
@@ -0,0 +1,202 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logfilter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// parser is a recursive descent parser of the Cloud Logging query language.
+// The precedence of operators follows Cloud Logging: NOT is evaluated first, then OR, and AND(including the implicit AND between terms) is evaluated last.
+type parser struct {
+	tokens []token
+	cursor int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.cursor]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.cursor]
+	if t.kind != tokenEOF {
+		p.cursor++
+	}
+	return t
+}
+
+func (p *parser) expect(kind tokenKind, description string) (token, error) {
+	t := p.next()
+	if t.kind != kind {
+		return token{}, p.unexpected(t, description)
+	}
+	return t, nil
+}
+
+func (p *parser) unexpected(t token, description string) error {
+	if t.kind == tokenEOF {
+		return fmt.Errorf("unexpected end of filter. expecting %s", description)
+	}
+	return fmt.Errorf("unexpected %q at %d. expecting %s", t.value, t.position, description)
+}
+
+// termParser parses a term in the expression. It is different between the top level expression and the value list of a comparison like `field:("a" OR "b")`.
+type termParser = func() (expression, error)
+
+// parseAnd parses terms joined with AND or whitespace.
+func (p *parser) parseAnd(term termParser) (expression, error) {
+	children := []expression{}
+	for {
+		child, err := p.parseOr(term)
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, child)
+		switch p.peek().kind {
+		case tokenAnd:
+			p.next()
+		case tokenEOF, tokenRightParen:
+			if len(children) == 1 {
+				return children[0], nil
+			}
+			return &andExpression{children: children}, nil
+		}
+	}
+}
+
+// parseOr parses terms joined with OR.
+func (p *parser) parseOr(term termParser) (expression, error) {
+	children := []expression{}
+	for {
+		child, err := p.parseNot(term)
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, child)
+		if p.peek().kind != tokenOr {
+			break
+		}
+		p.next()
+	}
+	if len(children) == 1 {
+		return children[0], nil
+	}
+	return &orExpression{children: children}, nil
+}
+
+// parseNot parses a term optionally negated with NOT or `-`.
+func (p *parser) parseNot(term termParser) (expression, error) {
+	switch p.peek().kind {
+	case tokenNot, tokenMinus:
+		p.next()
+		child, err := p.parseNot(term)
+		if err != nil {
+			return nil, err
+		}
+		return &notExpression{child: child}, nil
+	case tokenLeftParen:
+		p.next()
+		child, err := p.parseAnd(term)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(tokenRightParen, "`)`"); err != nil {
+			return nil, err
+		}
+		return child, nil
+	}
+	return term()
+}
+
+// parseRestriction parses a comparison, a function call or a global restriction in the top level expression.
+func (p *parser) parseRestriction() (expression, error) {
+	t := p.next()
+	if t.kind != tokenText && t.kind != tokenString {
+		return nil, p.unexpected(t, "a field path, a function or a value")
+	}
+	// A text immediately followed by `(` is a function call.
+	if t.kind == tokenText && p.peek().kind == tokenLeftParen && p.peek().position == t.position+len(t.value) {
+		function, found := functions[t.value]
+		if !found {
+			return nil, fmt.Errorf("function %s at %d is not supported", t.value, t.position)
+		}
+		return p.parseFunction(t.value, function)
+	}
+	if t.kind == tokenString || p.peek().kind != tokenOperator {
+		return &globalRestriction{value: t.value}, nil
+	}
+	operator := p.next()
+	fieldPath := t.segments
+	if p.peek().kind == tokenLeftParen {
+		p.next()
+		valueList, err := p.parseAnd(func() (expression, error) {
+			return p.parseComparisonValue(fieldPath, operator.value)
+		})
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(tokenRightParen, "`)`"); err != nil {
+			return nil, err
+		}
+		return valueList, nil
+	}
+	return p.parseComparisonValue(fieldPath, operator.value)
+}
+
+// parseComparisonValue parses the value compared with the field.
+func (p *parser) parseComparisonValue(fieldPath []string, operator string) (expression, error) {
+	t := p.next()
+	if t.kind != tokenString && t.kind != tokenText {
+		return nil, p.unexpected(t, "a value")
+	}
+	result := &comparison{
+		fieldPath: fieldPath,
+		operator:  operator,
+		value:     t.value,
+	}
+	if operator == "=~" || operator == "!~" {
+		pattern, err := regexp.Compile(t.value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regular expression at %d: %w", t.position, err)
+		}
+		result.pattern = pattern
+	}
+	return result, nil
+}
+
+// parseFunction parses a function call like `LOG_ID("cloudaudit.googleapis.com/activity")`.
+// Arguments are separated with commas or whitespaces.
+func (p *parser) parseFunction(name string, function filterFunction) (expression, error) {
+	p.next()
+	args := []token{}
+	for p.peek().kind != tokenRightParen {
+		t := p.next()
+		if t.kind != tokenString && t.kind != tokenText {
+			return nil, p.unexpected(t, fmt.Sprintf("an argument of %s", name))
+		}
+		if t.kind == tokenText {
+			t.value = strings.TrimSuffix(t.value, ",")
+			t.segments[len(t.segments)-1] = strings.TrimSuffix(t.segments[len(t.segments)-1], ",")
+			if t.value == "" {
+				continue
+			}
+		}
+		args = append(args, t)
+	}
+	p.next()
+	return function(args)
+}
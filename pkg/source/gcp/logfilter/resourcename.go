@@ -0,0 +1,42 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logfilter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/common/structurev2"
+)
+
+// MatchResourceNames returns true when the logName of the LogEntry belongs to one of the resource names given to `entries:list`.
+// Log bucket views (e.g. `projects/foo/locations/global/buckets/bar/views/baz`) are regarded as the project containing them.
+// Entries without logName and the empty resource name list match always because exported logs may not contain the field.
+func MatchResourceNames(entry *structurev2.NodeReader, resourceNames []string) bool {
+	logName := entry.ReadStringOrDefault("logName", "")
+	if logName == "" || len(resourceNames) == 0 {
+		return true
+	}
+	for _, resourceName := range resourceNames {
+		segments := strings.Split(strings.TrimSpace(resourceName), "/")
+		if len(segments) < 2 {
+			continue
+		}
+		if strings.HasPrefix(logName, fmt.Sprintf("%s/%s/logs/", segments[0], segments[1])) {
+			return true
+		}
+	}
+	return false
+}
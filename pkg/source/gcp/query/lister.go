@@ -0,0 +1,31 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package query
+
+import (
+	"context"
+
+	inspection_task_interface "github.com/GoogleCloudPlatform/khi/pkg/inspection/interface"
+	inspection_task "github.com/GoogleCloudPlatform/khi/pkg/inspection/task"
+	"github.com/GoogleCloudPlatform/khi/pkg/source/gcp/api"
+	"github.com/GoogleCloudPlatform/khi/pkg/source/gcp/inspectiontype"
+	gcp_taskid "github.com/GoogleCloudPlatform/khi/pkg/source/gcp/taskid"
+	"github.com/GoogleCloudPlatform/khi/pkg/task/taskid"
+)
+
+// CloudLoggingAPILogEntryListerTask returns the GCP client to list log entries through the Cloud Logging API.
+var CloudLoggingAPILogEntryListerTask = inspection_task.NewInspectionTask(taskid.NewImplementationID(gcp_taskid.LogEntryListerTaskID, "api"), []taskid.UntypedTaskReference{}, func(ctx context.Context, taskMode inspection_task_interface.InspectionTaskMode) (api.LogEntryLister, error) {
+	return api.DefaultGCPClientFactory.NewClientFromContext(ctx)
+}, inspection_task.InspectionTypeLabel(inspectiontype.CloudLoggingAPIInspectionTypes...))
@@ -18,23 +18,29 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
-	"slices"
 	"strings"
+	"time"
 
 	"github.com/GoogleCloudPlatform/khi/pkg/common/khictx"
 	"github.com/GoogleCloudPlatform/khi/pkg/common/typedmap"
 	"github.com/GoogleCloudPlatform/khi/pkg/common/worker"
+	"github.com/GoogleCloudPlatform/khi/pkg/inspection/budget"
 	inspection_task_contextkey "github.com/GoogleCloudPlatform/khi/pkg/inspection/contextkey"
 	inspection_task_interface "github.com/GoogleCloudPlatform/khi/pkg/inspection/interface"
+	"github.com/GoogleCloudPlatform/khi/pkg/inspection/ioconfig"
 	error_metadata "github.com/GoogleCloudPlatform/khi/pkg/inspection/metadata/error"
 	"github.com/GoogleCloudPlatform/khi/pkg/inspection/metadata/progress"
 	"github.com/GoogleCloudPlatform/khi/pkg/inspection/metadata/query"
 	inspection_task "github.com/GoogleCloudPlatform/khi/pkg/inspection/task"
 	"github.com/GoogleCloudPlatform/khi/pkg/inspection/task/label"
 	"github.com/GoogleCloudPlatform/khi/pkg/log"
+	"github.com/GoogleCloudPlatform/khi/pkg/log/logstore"
+	"github.com/GoogleCloudPlatform/khi/pkg/metrics"
 	"github.com/GoogleCloudPlatform/khi/pkg/model/enum"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/history/grouper"
 	"github.com/GoogleCloudPlatform/khi/pkg/source/gcp/api"
 	gcp_log "github.com/GoogleCloudPlatform/khi/pkg/source/gcp/log"
+	"github.com/GoogleCloudPlatform/khi/pkg/source/gcp/query/querycache"
 	"github.com/GoogleCloudPlatform/khi/pkg/source/gcp/query/queryutil"
 	gcp_task "github.com/GoogleCloudPlatform/khi/pkg/source/gcp/task"
 	gcp_taskid "github.com/GoogleCloudPlatform/khi/pkg/source/gcp/taskid"
@@ -76,18 +82,73 @@ var _ DefaultResourceNamesGenerator = (*ProjectIDDefaultResourceNamesGenerator)(
 
 var queryThreadPool = worker.NewPool(16)
 
-func NewQueryGeneratorTask(taskId taskid.TaskImplementationID[[]*log.Log], readableQueryName string, logType enum.LogType, dependencies []taskid.UntypedTaskReference, resourceNamesGenerator DefaultResourceNamesGenerator, generator QueryGeneratorFunc, sampleQuery string) task.Task[[]*log.Log] {
+// DefaultQueryResultCache is the cache of logs returned from Cloud Logging. Queries are not cached when it's nil.
+var DefaultQueryResultCache *querycache.QueryResultCache
+
+// NewQueryGeneratorTask returns a task querying logs from Cloud Logging with the queries returned from the generator.
+// Logs are written to a logstore.Store as they are received not to hold every log on memory. They are grouped with the logGrouper for the parser reading the store.
+func NewQueryGeneratorTask(taskId taskid.TaskImplementationID[*logstore.Store], readableQueryName string, logType enum.LogType, logGrouper grouper.LogGrouper, dependencies []taskid.UntypedTaskReference, resourceNamesGenerator DefaultResourceNamesGenerator, generator QueryGeneratorFunc, sampleQuery string) task.Task[*logstore.Store] {
+	return newQueryGeneratorTask(taskId, readableQueryName, logType, logGrouper, dependencies, resourceNamesGenerator, generator, sampleQuery, false)
+}
+
+// NewClusterScopedQueryGeneratorTask returns a task similar to NewQueryGeneratorTask but it calls the generator for each cluster when multiple clusters are given in the cluster name form.
+// The generator must read the cluster name with gcp_task.GetClusterName. Logs are tagged with the name of the cluster they were queried for.
+func NewClusterScopedQueryGeneratorTask(taskId taskid.TaskImplementationID[*logstore.Store], readableQueryName string, logType enum.LogType, logGrouper grouper.LogGrouper, dependencies []taskid.UntypedTaskReference, resourceNamesGenerator DefaultResourceNamesGenerator, generator QueryGeneratorFunc, sampleQuery string) task.Task[*logstore.Store] {
+	return newQueryGeneratorTask(taskId, readableQueryName, logType, logGrouper, append(dependencies, gcp_task.ClusterNamesTaskID.Ref()), resourceNamesGenerator, generator, sampleQuery, true)
+}
+
+// clusterQuery is a query generated for a cluster. clusterName is empty in single cluster inspections.
+type clusterQuery struct {
+	clusterName string
+	query       string
+	// readableName is the name of the query shown in the query metadata.
+	readableName string
+}
+
+// generateClusterQueries calls the generator for each cluster to inspect and returns the queries with their readable names.
+func generateClusterQueries(ctx context.Context, taskMode inspection_task_interface.InspectionTaskMode, generator QueryGeneratorFunc, readableQueryName string, clusterNames []string) ([]clusterQuery, error) {
+	// The generator is called only once without the cluster scope in single cluster inspections.
+	if len(clusterNames) <= 1 {
+		clusterNames = []string{""}
+	}
+	result := []clusterQuery{}
+	for _, clusterName := range clusterNames {
+		generatorCtx := ctx
+		readableQueryNameForCluster := readableQueryName
+		if clusterName != "" {
+			generatorCtx = gcp_task.WithClusterScope(ctx, clusterName)
+			readableQueryNameForCluster = fmt.Sprintf("%s-%s", readableQueryName, clusterName)
+		}
+		queryStrings, err := generator(generatorCtx, taskMode)
+		if err != nil {
+			return nil, err
+		}
+		for queryIndex, queryString := range queryStrings {
+			readableName := readableQueryNameForCluster
+			if len(queryStrings) > 1 {
+				readableName = fmt.Sprintf("%s-%d", readableQueryNameForCluster, queryIndex)
+			}
+			result = append(result, clusterQuery{
+				clusterName:  clusterName,
+				query:        queryString,
+				readableName: readableName,
+			})
+		}
+	}
+	return result, nil
+}
+
+func newQueryGeneratorTask(taskId taskid.TaskImplementationID[*logstore.Store], readableQueryName string, logType enum.LogType, logGrouper grouper.LogGrouper, dependencies []taskid.UntypedTaskReference, resourceNamesGenerator DefaultResourceNamesGenerator, generator QueryGeneratorFunc, sampleQuery string, clusterScoped bool) task.Task[*logstore.Store] {
 	return inspection_task.NewProgressReportableInspectionTask(taskId, append(
 		append(dependencies, resourceNamesGenerator.GetDependentTasks()...),
 		gcp_task.InputStartTimeTaskID.Ref(),
 		gcp_task.InputEndTimeTaskID.Ref(),
 		gcp_taskid.LoggingFilterResourceNameInputTaskID.Ref(),
-	), func(ctx context.Context, taskMode inspection_task_interface.InspectionTaskMode, progress *progress.TaskProgress) ([]*log.Log, error) {
-		client, err := api.DefaultGCPClientFactory.NewClient()
-		if err != nil {
-			return nil, err
-		}
-
+		gcp_taskid.LogEntryListerTaskID,
+		ioconfig.IOConfigTaskID.Ref(),
+	), func(ctx context.Context, taskMode inspection_task_interface.InspectionTaskMode, progress *progress.TaskProgress) (*logstore.Store, error) {
+		lister := task.GetTaskResult(ctx, gcp_taskid.LogEntryListerTaskID)
+		ioConfig := task.GetTaskResult(ctx, ioconfig.IOConfigTaskID.Ref())
 		metadata := khictx.MustGetValue(ctx, inspection_task_contextkey.InspectionRunMetadata)
 		resourceNames := task.GetTaskResult(ctx, gcp_taskid.LoggingFilterResourceNameInputTaskID.Ref())
 		taskInput := khictx.MustGetValue(ctx, inspection_task_contextkey.InspectionTaskInput)
@@ -122,37 +183,66 @@ func NewQueryGeneratorTask(taskId taskid.TaskImplementationID[[]*log.Log], reada
 		startTime := task.GetTaskResult(ctx, gcp_task.InputStartTimeTaskID.Ref())
 		endTime := task.GetTaskResult(ctx, gcp_task.InputEndTimeTaskID.Ref())
 
-		queryStrings, err := generator(ctx, taskMode)
+		clusterNames := []string{}
+		if clusterScoped {
+			clusterNames = task.GetTaskResult(ctx, gcp_task.ClusterNamesTaskID.Ref())
+		}
+		queries, err := generateClusterQueries(ctx, taskMode, generator, readableQueryName, clusterNames)
 		if err != nil {
 			return nil, err
 		}
-		if len(queryStrings) == 0 {
+		if len(queries) == 0 {
 			slog.InfoContext(ctx, fmt.Sprintf("Query generator `%s` decided to skip.", taskId))
-			return []*log.Log{}, nil
+			return newLogStore(ioConfig, logGrouper), nil
 		}
 		queryInfo, found := typedmap.Get(metadata, query.QueryMetadataKey)
 		if !found {
 			return nil, fmt.Errorf("query metadata was not found")
 		}
 
-		allLogs := []*log.Log{}
-		for queryIndex, queryString := range queryStrings {
-			// Record query information in metadat a
-			readableQueryNameForQueryIndex := readableQueryName
-			if len(queryStrings) > 1 {
-				readableQueryNameForQueryIndex = fmt.Sprintf("%s-%d", readableQueryName, queryIndex)
+		inspectionBudget, err := khictx.GetValue(ctx, inspection_task_contextkey.InspectionBudget)
+		if err != nil {
+			inspectionBudget = budget.Default
+		}
+		store := newLogStore(ioConfig, logGrouper)
+		limiter := inspectionBudget.NewLogLimiter(store)
+		estimateVolume, err := khictx.GetValue(ctx, inspection_task_contextkey.InspectionVolumeEstimation)
+		if err != nil {
+			estimateVolume = false
+		}
+		estimatedLogCount := 0
+		estimated := false
+		ingestedLogs := metrics.QueryLogsIngested.WithLabelValues(taskId.ReferenceIDString())
+		for _, clusterQuery := range queries {
+			queryString := clusterQuery.query
+			readableQueryNameForQueryIndex := clusterQuery.readableName
+			sink := func(l *log.Log) error {
+				ingestedLogs.Inc()
+				l.LogType = logType
+				l.ClusterName = clusterQuery.clusterName
+				return limiter.Append(l)
 			}
+			// Record query information in metadat a
 			finalQuery := fmt.Sprintf("%s\n%s", queryString, queryutil.TimeRangeQuerySection(startTime, endTime, true))
 			if len(finalQuery) > 20000 {
 				slog.WarnContext(ctx, fmt.Sprintf("Logging filter is exceeding Cloud Logging limitation 20000 charactors\n%s", finalQuery))
 			}
 			queryInfo.SetQuery(taskId.String(), readableQueryNameForQueryIndex, finalQuery)
-			// TODO: not to store whole logs on memory to avoid OOM
+			if taskMode == inspection_task_interface.TaskModeDryRun && estimateVolume {
+				count, err := queryutil.NewVolumeEstimator(lister, queryString).Estimate(ctx, resourceNamesFromInput, startTime, endTime)
+				if err != nil {
+					// The estimation is only a hint. The dry run must not fail due to probe queries.
+					slog.WarnContext(ctx, fmt.Sprintf("Failed to estimate the count of logs returned from `%s`\n%v", readableQueryNameForQueryIndex, err))
+				} else {
+					estimatedLogCount += count
+					estimated = true
+				}
+			}
 			// Run query only when thetask mode is for running
 			if taskMode == inspection_task_interface.TaskModeRun {
-				worker := queryutil.NewParallelQueryWorker(queryThreadPool, client, queryString, startTime, endTime, 5)
-				queryLogs, queryErr := worker.Query(ctx, resourceNamesFromInput, progress)
+				queryErr := runQuery(ctx, lister, queryString, resourceNamesFromInput, startTime, endTime, progress, sink)
 				if queryErr != nil {
+					store.Close()
 					errorMessageSet, found := typedmap.Get(metadata, error_metadata.ErrorMessageSetMetadataKey)
 					if !found {
 						return nil, fmt.Errorf("error message set metadata was not found")
@@ -173,29 +263,47 @@ func NewQueryGeneratorTask(taskId taskid.TaskImplementationID[[]*log.Log], reada
 							Message: queryErr.Error(),
 						})
 					}
+					if message := inspectionBudget.ErrorMessage(queryErr); message != nil {
+						errorMessageSet.AddErrorMessage(message)
+					}
 					return nil, queryErr
 				}
-				allLogs = append(allLogs, queryLogs...)
 			}
 		}
-
-		for _, l := range allLogs {
-			l.SetFieldSetReader(&gcp_log.GCPCommonFieldSetReader{})
-			l.SetFieldSetReader(&gcp_log.GCPMainMessageFieldSetReader{})
+		if estimated {
+			queryInfo.SetEstimatedLogCount(taskId.String(), estimatedLogCount)
 		}
-
-		if taskMode == inspection_task_interface.TaskModeRun {
-			slices.SortFunc(allLogs, func(a, b *log.Log) int {
-				commonFieldSetForA, _ := log.GetFieldSet(a, &log.CommonFieldSet{}) // errors are safely ignored because this field set is required in previous steps
-				commonFieldSetForB, _ := log.GetFieldSet(b, &log.CommonFieldSet{})
-				return int(commonFieldSetForA.Timestamp.Sub(commonFieldSetForB.Timestamp))
-			})
-			for _, l := range allLogs {
-				l.LogType = logType
+		if message := limiter.ErrorMessage(); message != nil {
+			slog.WarnContext(ctx, fmt.Sprintf("Logs returned from the query exceeded the budget of %d logs. Only %.4f of them are used.", inspectionBudget.MaxLogsPerQuery, limiter.SamplingRate()))
+			errorMessageSet, found := typedmap.Get(metadata, error_metadata.ErrorMessageSetMetadataKey)
+			if !found {
+				return nil, fmt.Errorf("error message set metadata was not found")
 			}
-			return allLogs, err
+			errorMessageSet.AddErrorMessage(message)
 		}
-
-		return []*log.Log{}, err
+		return store, nil
 	}, label.NewQueryTaskLabelOpt(logType, sampleQuery))
 }
+
+// newLogStore returns the store of logs returned from Cloud Logging. The store sorts logs by timestamp.
+func newLogStore(ioConfig *ioconfig.IOConfig, logGrouper grouper.LogGrouper) *logstore.Store {
+	return logstore.NewStore(ioConfig.TemporaryFolder, logGrouper, &gcp_log.GCPCommonFieldSetReader{}, &gcp_log.GCPMainMessageFieldSetReader{})
+}
+
+// runQuery sends logs queried with ParallelQueryWorker to the sink. Logs already stored in DefaultQueryResultCache are not queried again when the lister supports caching.
+func runQuery(ctx context.Context, lister api.LogEntryLister, queryString string, resourceNames []string, startTime time.Time, endTime time.Time, progress *progress.TaskProgress, sink queryutil.LogSink) error {
+	source, cacheable := querycache.SourceOf(lister)
+	if DefaultQueryResultCache == nil || !cacheable {
+		worker := queryutil.NewParallelQueryWorker(queryThreadPool, lister, queryString, startTime, endTime, 5)
+		return worker.QueryTo(ctx, resourceNames, progress, sink)
+	}
+	key := querycache.QueryKey{
+		Source:        source,
+		Filter:        queryString,
+		ResourceNames: resourceNames,
+	}
+	return DefaultQueryResultCache.Query(ctx, key, startTime, endTime, func(ctx context.Context, begin, end time.Time, fetchSink func(l *log.Log) error) error {
+		worker := queryutil.NewParallelQueryWorker(queryThreadPool, lister, queryString, begin, end, 5).ExcludeEndTime()
+		return worker.QueryTo(ctx, resourceNames, progress, fetchSink)
+	}, sink)
+}
@@ -0,0 +1,71 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package query
+
+import (
+	"context"
+	"testing"
+
+	inspection_task_interface "github.com/GoogleCloudPlatform/khi/pkg/inspection/interface"
+	gcp_task "github.com/GoogleCloudPlatform/khi/pkg/source/gcp/task"
+	"github.com/google/go-cmp/cmp"
+
+	_ "github.com/GoogleCloudPlatform/khi/internal/testflags"
+)
+
+func TestGenerateClusterQueries(t *testing.T) {
+	generator := func(ctx context.Context, taskMode inspection_task_interface.InspectionTaskMode) ([]string, error) {
+		clusterName := gcp_task.ClusterScope(ctx)
+		if clusterName == "skipped" {
+			return []string{}, nil
+		}
+		return []string{"query-1 " + clusterName, "query-2 " + clusterName}, nil
+	}
+	testCases := []struct {
+		name         string
+		clusterNames []string
+		want         []clusterQuery
+	}{
+		{
+			name:         "single cluster",
+			clusterNames: []string{"cluster-a"},
+			want: []clusterQuery{
+				{clusterName: "", query: "query-1 ", readableName: "test-0"},
+				{clusterName: "", query: "query-2 ", readableName: "test-1"},
+			},
+		},
+		{
+			name:         "multiple clusters",
+			clusterNames: []string{"cluster-a", "skipped", "cluster-b"},
+			want: []clusterQuery{
+				{clusterName: "cluster-a", query: "query-1 cluster-a", readableName: "test-cluster-a-0"},
+				{clusterName: "cluster-a", query: "query-2 cluster-a", readableName: "test-cluster-a-1"},
+				{clusterName: "cluster-b", query: "query-1 cluster-b", readableName: "test-cluster-b-0"},
+				{clusterName: "cluster-b", query: "query-2 cluster-b", readableName: "test-cluster-b-1"},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := generateClusterQueries(context.Background(), inspection_task_interface.TaskModeRun, generator, "test", tc.clusterNames)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.want, got, cmp.AllowUnexported(clusterQuery{})); diff != "" {
+				t.Errorf("generateClusterQueries() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
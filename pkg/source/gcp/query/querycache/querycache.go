@@ -0,0 +1,422 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package querycache
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/common/cache"
+	"github.com/GoogleCloudPlatform/khi/pkg/common/structurev2"
+	"github.com/GoogleCloudPlatform/khi/pkg/log"
+	gcp_log "github.com/GoogleCloudPlatform/khi/pkg/source/gcp/log"
+)
+
+// DefaultFreshnessMargin is the duration before the current time not stored in the cache because logs in the range can still be ingested.
+const DefaultFreshnessMargin = 10 * time.Minute
+
+// windowPrecision is the precision of time windows. Timestamps in filters are in seconds.
+const windowPrecision = time.Second
+
+// maxWindowDuration is the longest time range fetched at once. A window is added to the index after every log in it is stored.
+const maxWindowDuration = time.Hour
+
+// FormatVersion is the version of the format of stored items. Callers store items of each version in a separate folder not to read items written in another format.
+const FormatVersion = "v2"
+
+// defaultPartSize is the size of serialized logs buffered before they are stored as a part of the window.
+const defaultPartSize = 4 * 1024 * 1024
+
+// QueryKey identifies the series of cached time windows of a query.
+type QueryKey struct {
+	// Source identifies where the logs are from. The same filter can return different logs from different sources.
+	Source string
+	// Filter is the logging filter without the time range section.
+	Filter string
+	// ResourceNames is the list of resource names the query is run on.
+	ResourceNames []string
+}
+
+// digest returns the hash of the key. The order of resource names doesn't change the digest.
+func (k QueryKey) digest() string {
+	resourceNames := slices.Clone(k.ResourceNames)
+	slices.Sort(resourceNames)
+	hash := sha256.New()
+	for _, part := range append([]string{k.Source, k.Filter}, resourceNames...) {
+		fmt.Fprintf(hash, "%d:%s\n", len(part), part)
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// FetchFunc sends logs in [begin, end) to the sink. Logs at the end time must not be included. The sink must not be called concurrently.
+type FetchFunc = func(ctx context.Context, begin time.Time, end time.Time, sink func(l *log.Log) error) error
+
+// cachedWindow is a time range [Begin, End) whose logs are stored in the cache.
+type cachedWindow struct {
+	Begin time.Time `json:"begin"`
+	End   time.Time `json:"end"`
+	// Parts is the count of items storing the logs of the window.
+	Parts int `json:"parts"`
+	// WriteID distinguishes the parts from the ones written by another query fetching the same window at the same time.
+	WriteID string `json:"writeId,omitempty"`
+}
+
+// windowIndex is the list of time windows stored for a QueryKey.
+type windowIndex struct {
+	Windows []cachedWindow `json:"windows"`
+}
+
+// QueryResultCache stores logs returned from queries for each time window.
+// A query overlapping with cached windows only fetches the missing windows.
+type QueryResultCache struct {
+	storage         cache.CacheItemStorageProvider
+	freshnessMargin time.Duration
+	now             func() time.Time
+	// partSize is the size of serialized logs stored in an item.
+	partSize int
+	// lock guards the read-modify-write of window indices.
+	lock sync.Mutex
+}
+
+// NewQueryResultCache returns a QueryResultCache storing logs in the given storage.
+func NewQueryResultCache(storage cache.CacheItemStorageProvider) *QueryResultCache {
+	return &QueryResultCache{
+		storage:         storage,
+		freshnessMargin: DefaultFreshnessMargin,
+		now:             time.Now,
+		partSize:        defaultPartSize,
+	}
+}
+
+// Query sends logs from startTime to endTime to the sink. Logs in the cached windows are read from the cache and the others are fetched with the given function.
+// Times are aligned to seconds and the range includes the second of endTime. Logs are not sent in the timestamp order.
+func (c *QueryResultCache) Query(ctx context.Context, key QueryKey, startTime time.Time, endTime time.Time, fetch FetchFunc, sink func(l *log.Log) error) error {
+	digest := key.digest()
+	begin := startTime.Truncate(windowPrecision)
+	end := endTime.Truncate(windowPrecision).Add(windowPrecision)
+
+	c.lock.Lock()
+	index := c.readIndex(digest)
+	c.lock.Unlock()
+
+	cachedLogCount := 0
+	// sentLogKeys is the set of keys of logs sent from windows failed to be read in the middle. They are not sent again when the windows are fetched.
+	sentLogKeys := map[string]struct{}{}
+	missing := []cachedWindow{}
+	brokenWindows := []cachedWindow{}
+	cursor := begin
+	for _, window := range index.Windows {
+		if !window.End.After(cursor) || !window.Begin.Before(end) {
+			continue
+		}
+		if window.Begin.After(cursor) {
+			missing = appendWindow(missing, cachedWindow{Begin: cursor, End: window.Begin})
+			cursor = window.Begin
+		}
+		usedEnd := minTime(window.End, end)
+		var sinkErr error
+		windowLogKeys := []string{}
+		err := c.readWindow(digest, window, cursor, usedEnd, func(l *log.Log) error {
+			if window.Parts > 1 {
+				windowLogKeys = append(windowLogKeys, cachedLogKey(l))
+			}
+			sinkErr = sink(l)
+			return sinkErr
+		})
+		if sinkErr != nil {
+			return sinkErr
+		}
+		if err != nil {
+			// Windows can be evicted from the storage before the index.
+			if !errors.Is(err, cache.ErrNotFoundInStorageErr) {
+				slog.WarnContext(ctx, fmt.Sprintf("failed to read cached logs. The range is queried again.\n%v", err))
+			}
+			for _, key := range windowLogKeys {
+				sentLogKeys[key] = struct{}{}
+			}
+			brokenWindows = append(brokenWindows, window)
+			missing = appendWindow(missing, cachedWindow{Begin: cursor, End: usedEnd})
+		} else {
+			cachedLogCount += len(windowLogKeys)
+		}
+		cursor = usedEnd
+	}
+	if cursor.Before(end) {
+		missing = appendWindow(missing, cachedWindow{Begin: cursor, End: end})
+	}
+	if len(index.Windows) > 0 {
+		slog.DebugContext(ctx, fmt.Sprintf("query cache: %d logs read from the cache, %d windows to fetch", cachedLogCount, len(missing)))
+	}
+	if len(brokenWindows) > 0 {
+		c.updateIndex(ctx, digest, nil, brokenWindows)
+	}
+
+	storableEnd := c.now().Add(-c.freshnessMargin).Truncate(windowPrecision)
+	for _, window := range splitWindows(missing, maxWindowDuration) {
+		storedWindow := cachedWindow{Begin: window.Begin, End: minTime(window.End, storableEnd)}
+		var writer *windowWriter
+		if storedWindow.End.After(storedWindow.Begin) {
+			storedWindow.WriteID = newWriteID()
+			writer = &windowWriter{storage: c.storage, digest: digest, window: storedWindow, partSize: c.partSize}
+		}
+		err := fetch(ctx, window.Begin, window.End, func(l *log.Log) error {
+			if writer != nil {
+				if err := writer.Write(l); err != nil {
+					slog.WarnContext(ctx, fmt.Sprintf("failed to store logs in the query cache\n%v", err))
+					writer = nil
+				}
+			}
+			if len(sentLogKeys) > 0 {
+				if _, found := sentLogKeys[cachedLogKey(l)]; found {
+					return nil
+				}
+			}
+			return sink(l)
+		})
+		if err != nil {
+			return err
+		}
+		if writer == nil {
+			continue
+		}
+		if err := writer.Close(); err != nil {
+			slog.WarnContext(ctx, fmt.Sprintf("failed to store logs in the query cache\n%v", err))
+			continue
+		}
+		storedWindow.Parts = writer.parts
+		c.updateIndex(ctx, digest, []cachedWindow{storedWindow}, nil)
+	}
+	return nil
+}
+
+// updateIndex adds and removes windows in the stored index.
+func (c *QueryResultCache) updateIndex(ctx context.Context, digest string, added []cachedWindow, removed []cachedWindow) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	// Read the index again because another query may have updated it.
+	index := c.readIndex(digest)
+	index.Windows = slices.DeleteFunc(index.Windows, func(w cachedWindow) bool {
+		return slices.Contains(removed, w)
+	})
+	index.Windows = append(index.Windows, added...)
+	slices.SortFunc(index.Windows, func(a, b cachedWindow) int {
+		return a.Begin.Compare(b.Begin)
+	})
+	if err := c.writeIndex(digest, index); err != nil {
+		slog.WarnContext(ctx, fmt.Sprintf("failed to store the index of the query cache\n%v", err))
+	}
+}
+
+// readIndex returns the stored index of the digest. An empty index is returned when it's not stored or broken.
+func (c *QueryResultCache) readIndex(digest string) *windowIndex {
+	index := &windowIndex{}
+	data, err := c.storage.Get(indexKey(digest))
+	if err != nil {
+		if !errors.Is(err, cache.ErrNotFoundInStorageErr) {
+			slog.Warn(fmt.Sprintf("failed to read the index of the query cache\n%v", err))
+		}
+		return index
+	}
+	if err := json.Unmarshal(data, index); err != nil {
+		slog.Warn(fmt.Sprintf("ignoring a broken index of the query cache\n%v", err))
+		return &windowIndex{}
+	}
+	return index
+}
+
+func (c *QueryResultCache) writeIndex(digest string, index *windowIndex) error {
+	data, err := json.Marshal(index)
+	if err != nil {
+		return err
+	}
+	return c.storage.Set(indexKey(digest), data)
+}
+
+// readWindow sends the cached logs of the window in [begin, end) to the sink. Logs are read part by part.
+func (c *QueryResultCache) readWindow(digest string, window cachedWindow, begin time.Time, end time.Time, sink func(l *log.Log) error) error {
+	for part := 0; part < window.Parts; part++ {
+		data, err := c.storage.Get(partKey(digest, window, part))
+		if err != nil {
+			return err
+		}
+		logs, err := parseCachedLogs(data, begin, end)
+		if err != nil {
+			return err
+		}
+		for _, l := range logs {
+			if err := sink(l); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// parseCachedLogs returns the logs in [begin, end) from the JSON lines stored in the cache.
+func parseCachedLogs(data []byte, begin time.Time, end time.Time) ([]*log.Log, error) {
+	result := []*log.Log{}
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		if len(line) == 0 {
+			continue
+		}
+		node, err := structurev2.FromYAML(string(line))
+		if err != nil {
+			return nil, err
+		}
+		l := log.NewLog(structurev2.NewNodeReader(node))
+		if err := l.SetFieldSetReader(&gcp_log.GCPCommonFieldSetReader{}); err != nil {
+			return nil, err
+		}
+		if err := l.SetFieldSetReader(&gcp_log.GCPMainMessageFieldSetReader{}); err != nil {
+			return nil, err
+		}
+		commonFieldSet, err := log.GetFieldSet(l, &log.CommonFieldSet{})
+		if err != nil {
+			return nil, err
+		}
+		if commonFieldSet.Timestamp.Before(begin) || !commonFieldSet.Timestamp.Before(end) {
+			continue
+		}
+		result = append(result, l)
+	}
+	return result, nil
+}
+
+// windowWriter stores the logs of a window as JSON lines in parts while they are fetched. Logs out of the window are ignored.
+type windowWriter struct {
+	storage  cache.CacheItemStorageProvider
+	digest   string
+	window   cachedWindow
+	partSize int
+	buf      bytes.Buffer
+	// parts is the count of parts already stored.
+	parts int
+}
+
+// Write adds the log to the current part and stores the part when it reaches the part size.
+func (w *windowWriter) Write(l *log.Log) error {
+	commonFieldSet, err := log.GetFieldSet(l, &log.CommonFieldSet{})
+	if err != nil {
+		return err
+	}
+	if commonFieldSet.Timestamp.Before(w.window.Begin) || !commonFieldSet.Timestamp.Before(w.window.End) {
+		return nil
+	}
+	serialized, err := l.Serialize("", &structurev2.JSONNodeSerializer{})
+	if err != nil {
+		return err
+	}
+	w.buf.Write(serialized)
+	w.buf.WriteByte('\n')
+	if w.buf.Len() >= w.partSize {
+		return w.flush()
+	}
+	return nil
+}
+
+// Close stores the remaining logs. A window without logs is stored as an empty part.
+func (w *windowWriter) Close() error {
+	if w.buf.Len() == 0 && w.parts > 0 {
+		return nil
+	}
+	return w.flush()
+}
+
+func (w *windowWriter) flush() error {
+	if err := w.storage.Set(partKey(w.digest, w.window, w.parts), w.buf.Bytes()); err != nil {
+		return err
+	}
+	// The storage may keep the given slice. A new buffer is used for the next part.
+	w.buf = bytes.Buffer{}
+	w.parts++
+	return nil
+}
+
+// cachedLogKey identifies a log not to send it twice when a window failed to be read in the middle is fetched again.
+func cachedLogKey(l *log.Log) string {
+	commonFieldSet, err := log.GetFieldSet(l, &log.CommonFieldSet{})
+	if err == nil && commonFieldSet.DisplayID != "" {
+		return fmt.Sprintf("%s/%s", commonFieldSet.Timestamp.Format(time.RFC3339Nano), commonFieldSet.DisplayID)
+	}
+	serialized, err := l.Serialize("", &structurev2.JSONNodeSerializer{})
+	if err != nil {
+		return l.ID
+	}
+	return string(serialized)
+}
+
+func indexKey(digest string) string {
+	return fmt.Sprintf("index/%s", digest)
+}
+
+func partKey(digest string, window cachedWindow, part int) string {
+	return fmt.Sprintf("window/%s/%d-%d/%s/%d", digest, window.Begin.Unix(), window.End.Unix(), window.WriteID, part)
+}
+
+// newWriteID returns a random ID given to the parts of a window written by a query.
+func newWriteID() string {
+	id := make([]byte, 8)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
+// appendWindow appends the window to the list merging it with the last window when they are adjacent.
+func appendWindow(windows []cachedWindow, window cachedWindow) []cachedWindow {
+	if len(windows) > 0 && windows[len(windows)-1].End.Equal(window.Begin) {
+		windows[len(windows)-1].End = window.End
+		return windows
+	}
+	return append(windows, window)
+}
+
+// splitWindows splits the windows at the multiples of the given duration.
+func splitWindows(windows []cachedWindow, duration time.Duration) []cachedWindow {
+	result := []cachedWindow{}
+	for _, window := range windows {
+		begin := window.Begin
+		for begin.Before(window.End) {
+			end := minTime(begin.Truncate(duration).Add(duration), window.End)
+			result = append(result, cachedWindow{Begin: begin, End: end})
+			begin = end
+		}
+	}
+	return result
+}
+
+func minTime(a time.Time, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
+
+// SourceOf returns the Source of QueryKey for the log entry lister. It returns false when logs from the lister must not be cached.
+func SourceOf(lister any) (string, bool) {
+	dependency, ok := lister.(cache.CacheDependency)
+	if !ok {
+		return "", false
+	}
+	return dependency.Digest(), true
+}
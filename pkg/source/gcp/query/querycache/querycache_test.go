@@ -0,0 +1,243 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package querycache
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/common/cache"
+	"github.com/GoogleCloudPlatform/khi/pkg/log"
+	gcp_log "github.com/GoogleCloudPlatform/khi/pkg/source/gcp/log"
+	"github.com/google/go-cmp/cmp"
+
+	_ "github.com/GoogleCloudPlatform/khi/internal/testflags"
+)
+
+type onMemoryStorage struct {
+	lock  sync.Mutex
+	items map[string][]byte
+}
+
+func newOnMemoryStorage() *onMemoryStorage {
+	return &onMemoryStorage{items: map[string][]byte{}}
+}
+
+// Get implements cache.CacheItemStorageProvider.
+func (s *onMemoryStorage) Get(key string) ([]byte, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	value, found := s.items[key]
+	if !found {
+		return nil, cache.ErrNotFoundInStorageErr
+	}
+	return value, nil
+}
+
+// Set implements cache.CacheItemStorageProvider.
+func (s *onMemoryStorage) Set(key string, value []byte) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.items[key] = value
+	return nil
+}
+
+var _ cache.CacheItemStorageProvider = (*onMemoryStorage)(nil)
+
+var baseTime = time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// fakeSource returns a log every 10 minutes and records the fetched windows.
+type fakeSource struct {
+	fetched []cachedWindow
+}
+
+func (f *fakeSource) fetch(t *testing.T) FetchFunc {
+	return func(ctx context.Context, begin, end time.Time, sink func(l *log.Log) error) error {
+		f.fetched = append(f.fetched, cachedWindow{Begin: begin, End: end})
+		for ts := baseTime; ts.Before(baseTime.Add(24 * time.Hour)); ts = ts.Add(10 * time.Minute) {
+			if ts.Before(begin) || !ts.Before(end) {
+				continue
+			}
+			l, err := log.NewLogFromYAMLString(fmt.Sprintf("insertId: \"%s\"\ntimestamp: %s\n", ts.Format("1504"), ts.Format(time.RFC3339Nano)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := l.SetFieldSetReader(&gcp_log.GCPCommonFieldSetReader{}); err != nil {
+				t.Fatal(err)
+			}
+			if err := sink(l); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+func query(t *testing.T, c *QueryResultCache, key QueryKey, startTime, endTime time.Time, source *fakeSource) []*log.Log {
+	t.Helper()
+	logs := []*log.Log{}
+	err := c.Query(context.Background(), key, startTime, endTime, source.fetch(t), func(l *log.Log) error {
+		logs = append(logs, l)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return logs
+}
+
+func insertIDs(logs []*log.Log) []string {
+	result := []string{}
+	for _, l := range logs {
+		result = append(result, l.ReadStringOrDefault("insertId", ""))
+	}
+	slices.Sort(result)
+	return result
+}
+
+func TestQueryResultCache(t *testing.T) {
+	storage := newOnMemoryStorage()
+	c := NewQueryResultCache(storage)
+	c.now = func() time.Time { return baseTime.Add(48 * time.Hour) }
+	key := QueryKey{Source: "test", Filter: `resource.type="k8s_cluster"`, ResourceNames: []string{"projects/foo", "projects/bar"}}
+	source := &fakeSource{}
+
+	logs := query(t, c, key, baseTime, baseTime.Add(time.Hour), source)
+	if diff := cmp.Diff([]string{"0000", "0010", "0020", "0030", "0040", "0050", "0100"}, insertIDs(logs)); diff != "" {
+		t.Errorf("first query result mismatch (-want +got):\n%s", diff)
+	}
+
+	// The order of resource names doesn't matter and only the range after the cached window is fetched.
+	key.ResourceNames = []string{"projects/bar", "projects/foo"}
+	logs = query(t, c, key, baseTime.Add(30*time.Minute), baseTime.Add(90*time.Minute), source)
+	if diff := cmp.Diff([]string{"0030", "0040", "0050", "0100", "0110", "0120", "0130"}, insertIDs(logs)); diff != "" {
+		t.Errorf("second query result mismatch (-want +got):\n%s", diff)
+	}
+	// Windows are split at each hour.
+	wantFetched := []cachedWindow{
+		{Begin: baseTime, End: baseTime.Add(time.Hour)},
+		{Begin: baseTime.Add(time.Hour), End: baseTime.Add(time.Hour + time.Second)},
+		{Begin: baseTime.Add(time.Hour + time.Second), End: baseTime.Add(90*time.Minute + time.Second)},
+	}
+	if diff := cmp.Diff(wantFetched, source.fetched); diff != "" {
+		t.Errorf("fetched windows mismatch (-want +got):\n%s", diff)
+	}
+
+	// A fully cached range is served without fetching.
+	source.fetched = nil
+	logs = query(t, c, key, baseTime.Add(10*time.Minute), baseTime.Add(80*time.Minute), source)
+	if len(source.fetched) != 0 {
+		t.Errorf("fetched %v, want no fetch", source.fetched)
+	}
+	if diff := cmp.Diff([]string{"0010", "0020", "0030", "0040", "0050", "0100", "0110", "0120"}, insertIDs(logs)); diff != "" {
+		t.Errorf("cached query result mismatch (-want +got):\n%s", diff)
+	}
+
+	// Another filter doesn't share the cache.
+	source.fetched = nil
+	key.Filter = `resource.type="k8s_node"`
+	query(t, c, key, baseTime, baseTime.Add(time.Hour), source)
+	if len(source.fetched) != 2 {
+		t.Errorf("fetched %v, want fetches for another filter", source.fetched)
+	}
+}
+
+func TestQueryResultCacheDoesNotStoreRecentLogs(t *testing.T) {
+	c := NewQueryResultCache(newOnMemoryStorage())
+	c.now = func() time.Time { return baseTime.Add(time.Hour) }
+	key := QueryKey{Source: "test", Filter: "foo"}
+	source := &fakeSource{}
+
+	for i := 0; i < 2; i++ {
+		query(t, c, key, baseTime, baseTime.Add(time.Hour), source)
+	}
+	wantFetched := []cachedWindow{
+		{Begin: baseTime, End: baseTime.Add(time.Hour)},
+		{Begin: baseTime.Add(time.Hour), End: baseTime.Add(time.Hour + time.Second)},
+		{Begin: baseTime.Add(time.Hour - DefaultFreshnessMargin), End: baseTime.Add(time.Hour)},
+		{Begin: baseTime.Add(time.Hour), End: baseTime.Add(time.Hour + time.Second)},
+	}
+	if diff := cmp.Diff(wantFetched, source.fetched); diff != "" {
+		t.Errorf("fetched windows mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestQueryResultCacheFetchesEvictedWindows(t *testing.T) {
+	storage := newOnMemoryStorage()
+	c := NewQueryResultCache(storage)
+	c.now = func() time.Time { return baseTime.Add(48 * time.Hour) }
+	key := QueryKey{Source: "test", Filter: "foo"}
+	source := &fakeSource{}
+
+	query(t, c, key, baseTime, baseTime.Add(time.Hour), source)
+	for itemKey := range storage.items {
+		if itemKey != indexKey(key.digest()) {
+			delete(storage.items, itemKey)
+		}
+	}
+	logs := query(t, c, key, baseTime, baseTime.Add(time.Hour), source)
+	if len(logs) != 7 {
+		t.Errorf("got %d logs, want 7", len(logs))
+	}
+	if len(source.fetched) != 4 {
+		t.Errorf("fetched %v, want the evicted windows to be fetched again", source.fetched)
+	}
+}
+
+func TestQueryResultCacheStoresWindowsInParts(t *testing.T) {
+	storage := newOnMemoryStorage()
+	c := NewQueryResultCache(storage)
+	c.now = func() time.Time { return baseTime.Add(48 * time.Hour) }
+	// Every log is stored in its own part.
+	c.partSize = 1
+	key := QueryKey{Source: "test", Filter: "foo"}
+	source := &fakeSource{}
+
+	query(t, c, key, baseTime, baseTime.Add(50*time.Minute), source)
+	index := c.readIndex(key.digest())
+	if len(index.Windows) != 1 || index.Windows[0].Parts != 6 {
+		t.Fatalf("got windows %v, want a window stored in 6 parts", index.Windows)
+	}
+
+	source.fetched = nil
+	logs := query(t, c, key, baseTime, baseTime.Add(50*time.Minute), source)
+	if len(source.fetched) != 0 {
+		t.Errorf("fetched %v, want no fetch", source.fetched)
+	}
+	if diff := cmp.Diff([]string{"0000", "0010", "0020", "0030", "0040", "0050"}, insertIDs(logs)); diff != "" {
+		t.Errorf("cached query result mismatch (-want +got):\n%s", diff)
+	}
+
+	// Logs read before an evicted part are not sent again when the window is fetched.
+	delete(storage.items, partKey(key.digest(), index.Windows[0], 3))
+	source.fetched = nil
+	logs = query(t, c, key, baseTime, baseTime.Add(50*time.Minute), source)
+	if len(source.fetched) == 0 {
+		t.Errorf("the window with an evicted part was not fetched again")
+	}
+	if diff := cmp.Diff([]string{"0000", "0010", "0020", "0030", "0040", "0050"}, insertIDs(logs)); diff != "" {
+		t.Errorf("query result with an evicted part mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestSourceOf(t *testing.T) {
+	if _, ok := SourceOf(struct{}{}); ok {
+		t.Errorf("SourceOf() returned true for a lister not implementing cache.CacheDependency")
+	}
+}
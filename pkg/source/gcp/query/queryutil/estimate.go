@@ -0,0 +1,119 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package queryutil
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/log"
+	"github.com/GoogleCloudPlatform/khi/pkg/source/gcp/api"
+	gcp_log "github.com/GoogleCloudPlatform/khi/pkg/source/gcp/log"
+)
+
+const (
+	defaultProbeCount         = 5
+	defaultProbeDuration      = time.Minute
+	defaultMaxEntriesPerProbe = 1000
+)
+
+// VolumeEstimator estimates the count of logs matching a query in a time range without receiving every log.
+// It sends probe queries over sub-windows spread evenly across the time range and extrapolates the count from the density of logs in them.
+type VolumeEstimator struct {
+	apiClient          api.LogEntryLister
+	baseQuery          string
+	probeCount         int
+	probeDuration      time.Duration
+	maxEntriesPerProbe int
+}
+
+func NewVolumeEstimator(apiClient api.LogEntryLister, baseQuery string) *VolumeEstimator {
+	return &VolumeEstimator{
+		apiClient:          apiClient,
+		baseQuery:          baseQuery,
+		probeCount:         defaultProbeCount,
+		probeDuration:      defaultProbeDuration,
+		maxEntriesPerProbe: defaultMaxEntriesPerProbe,
+	}
+}
+
+// Estimate returns the estimated count of logs between startTime and endTime.
+// Each probe stops receiving logs after a page of logs and the density is computed from the time range it received.
+func (v *VolumeEstimator) Estimate(ctx context.Context, resourceNames []string, startTime time.Time, endTime time.Time) (int, error) {
+	total := endTime.Sub(startTime)
+	if total <= 0 {
+		return 0, nil
+	}
+	if total <= time.Duration(v.probeCount)*v.probeDuration {
+		density, err := v.probe(ctx, resourceNames, startTime, endTime)
+		if err != nil {
+			return 0, err
+		}
+		return int(density * total.Seconds()), nil
+	}
+	densitySum := 0.0
+	interval := total / time.Duration(v.probeCount)
+	for i := 0; i < v.probeCount; i++ {
+		// Place each probe at the center of its interval.
+		begin := startTime.Add(interval*time.Duration(i) + (interval-v.probeDuration)/2)
+		density, err := v.probe(ctx, resourceNames, begin, begin.Add(v.probeDuration))
+		if err != nil {
+			return 0, err
+		}
+		densitySum += density
+	}
+	return int(densitySum / float64(v.probeCount) * total.Seconds()), nil
+}
+
+// probe returns the count of logs per second in the time range.
+func (v *VolumeEstimator) probe(ctx context.Context, resourceNames []string, begin time.Time, end time.Time) (float64, error) {
+	probeCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	query := fmt.Sprintf("%s\n%s", v.baseQuery, TimeRangeQuerySection(begin, end, false))
+	logSink := make(chan *log.Log)
+	listErrCh := make(chan error, 1)
+	go func() {
+		listErrCh <- v.apiClient.ListLogEntries(probeCtx, resourceNames, query, logSink)
+	}()
+	count := 0
+	var lastTimestamp time.Time
+	for l := range logSink {
+		// Logs sent after reaching the limit are ignored until the lister notices the cancellation.
+		if count >= v.maxEntriesPerProbe {
+			continue
+		}
+		count++
+		if err := l.SetFieldSetReader(&gcp_log.GCPCommonFieldSetReader{}); err == nil {
+			lastTimestamp = log.MustGetFieldSet(l, &log.CommonFieldSet{}).Timestamp
+		}
+		if count >= v.maxEntriesPerProbe {
+			cancel()
+		}
+	}
+	err := <-listErrCh
+	if count < v.maxEntriesPerProbe {
+		if err != nil {
+			return 0, err
+		}
+		return float64(count) / end.Sub(begin).Seconds(), nil
+	}
+	// The probe was stopped in the middle. Logs are received in the timestamp order, thus they cover the range until the last timestamp.
+	covered := lastTimestamp.Sub(begin)
+	if covered < minSegmentDuration {
+		covered = minSegmentDuration
+	}
+	return float64(count) / covered.Seconds(), nil
+}
@@ -0,0 +1,73 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package queryutil
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	_ "github.com/GoogleCloudPlatform/khi/internal/testflags"
+)
+
+func TestVolumeEstimator(t *testing.T) {
+	startTime := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+	testCases := []struct {
+		name        string
+		duration    time.Duration
+		interval    time.Duration
+		wantQueries int
+	}{
+		{
+			name:        "sparse logs in a short range are counted exactly",
+			duration:    2 * time.Minute,
+			interval:    time.Second,
+			wantQueries: 1,
+		},
+		{
+			name:        "sparse logs in a long range",
+			duration:    2 * time.Hour,
+			interval:    3 * time.Second,
+			wantQueries: 5,
+		},
+		{
+			name:        "dense logs in a long range",
+			duration:    time.Hour,
+			interval:    50 * time.Millisecond,
+			wantQueries: 5,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			timestamps := []time.Time{}
+			for current := startTime; current.Before(startTime.Add(tc.duration)); current = current.Add(tc.interval) {
+				timestamps = append(timestamps, current)
+			}
+			lister := newFakeLogEntryLister(t, timestamps)
+			estimator := NewVolumeEstimator(lister, `insertId:"log"`)
+			got, err := estimator.Estimate(context.Background(), []string{"projects/test-project"}, startTime, startTime.Add(tc.duration))
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := len(timestamps)
+			if got < want*9/10 || got > want*11/10 {
+				t.Errorf("Estimate() = %d, want around %d", got, want)
+			}
+			if len(lister.queries) != tc.wantQueries {
+				t.Errorf("got %d probe queries, want %d", len(lister.queries), tc.wantQueries)
+			}
+		})
+	}
+}
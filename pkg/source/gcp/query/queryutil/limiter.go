@@ -0,0 +1,154 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package queryutil
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/common/httpclient"
+)
+
+// concurrencyLimiter limits the count of queries running at once.
+// The limit starts from the initial limit and grows by one every rampUpInterval up to the max limit. It's halved when a query is rate limited,
+// and no query is started until the backoff ends. The backoff doubles while queries keep being rate limited.
+type concurrencyLimiter struct {
+	maxLimit       int
+	rampUpInterval time.Duration
+	minBackoff     time.Duration
+	maxBackoff     time.Duration
+
+	lock         sync.Mutex
+	limit        int
+	running      int
+	lastChange   time.Time
+	backoff      time.Duration
+	backoffUntil time.Time
+	// changed is closed and replaced every time the state changes to wake up waiting Acquire calls.
+	changed chan struct{}
+}
+
+var _ httpclient.RateLimitListener = (*concurrencyLimiter)(nil)
+
+func newConcurrencyLimiter(initialLimit int, maxLimit int, rampUpInterval time.Duration, minBackoff time.Duration, maxBackoff time.Duration) *concurrencyLimiter {
+	return &concurrencyLimiter{
+		maxLimit:       maxLimit,
+		rampUpInterval: rampUpInterval,
+		minBackoff:     minBackoff,
+		maxBackoff:     maxBackoff,
+		limit:          min(max(initialLimit, 1), maxLimit),
+		lastChange:     time.Now(),
+		changed:        make(chan struct{}),
+	}
+}
+
+// Acquire blocks until a query can be started. The caller must call Release after the query.
+func (c *concurrencyLimiter) Acquire(ctx context.Context) error {
+	for {
+		c.lock.Lock()
+		now := time.Now()
+		c.rampUp(now)
+		if c.running < c.limit && !now.Before(c.backoffUntil) {
+			c.running++
+			c.lock.Unlock()
+			return nil
+		}
+		var wakeAt time.Time
+		if now.Before(c.backoffUntil) {
+			wakeAt = c.backoffUntil
+		} else if c.limit < c.maxLimit {
+			wakeAt = c.lastChange.Add(c.rampUpInterval)
+		}
+		changed := c.changed
+		c.lock.Unlock()
+
+		var timer <-chan time.Time
+		if !wakeAt.IsZero() {
+			timer = time.After(time.Until(wakeAt))
+		}
+		select {
+		case <-ctx.Done():
+			return context.Cause(ctx)
+		case <-changed:
+		case <-timer:
+		}
+	}
+}
+
+// Release marks a query started with Acquire as finished.
+func (c *concurrencyLimiter) Release() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.running--
+	c.notifyChange()
+}
+
+// Idle returns the count of queries that can be started now in addition to the running ones.
+func (c *concurrencyLimiter) Idle() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	now := time.Now()
+	c.rampUp(now)
+	if now.Before(c.backoffUntil) {
+		return 0
+	}
+	return max(c.limit-c.running, 0)
+}
+
+// Status returns the count of running queries and the current limit.
+func (c *concurrencyLimiter) Status() (running int, limit int) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.rampUp(time.Now())
+	return c.running, c.limit
+}
+
+// OnRateLimited implements httpclient.RateLimitListener.
+func (c *concurrencyLimiter) OnRateLimited() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	now := time.Now()
+	// Rate limited responses from queries started before the last backoff are the result of the same burst.
+	if now.Before(c.backoffUntil) {
+		return
+	}
+	if c.backoff == 0 || now.After(c.backoffUntil.Add(c.backoff*2)) {
+		c.backoff = c.minBackoff
+	} else {
+		c.backoff = min(c.backoff*2, c.maxBackoff)
+	}
+	c.backoffUntil = now.Add(c.backoff)
+	c.limit = max(c.limit/2, 1)
+	c.lastChange = c.backoffUntil
+	c.notifyChange()
+}
+
+// rampUp increases the limit when rampUpInterval passed since the last change. The caller must hold the lock.
+func (c *concurrencyLimiter) rampUp(now time.Time) {
+	for c.limit < c.maxLimit && !now.Before(c.lastChange.Add(c.rampUpInterval)) {
+		c.limit++
+		c.lastChange = c.lastChange.Add(c.rampUpInterval)
+	}
+	if c.limit == c.maxLimit && c.lastChange.Before(now) {
+		c.lastChange = now
+	}
+}
+
+// notifyChange wakes up waiting Acquire calls. The caller must hold the lock.
+func (c *concurrencyLimiter) notifyChange() {
+	close(c.changed)
+	c.changed = make(chan struct{})
+}
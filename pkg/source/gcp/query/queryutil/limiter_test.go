@@ -0,0 +1,97 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package queryutil
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	_ "github.com/GoogleCloudPlatform/khi/internal/testflags"
+)
+
+func TestConcurrencyLimiterRampUp(t *testing.T) {
+	limiter := newConcurrencyLimiter(1, 3, 50*time.Millisecond, time.Second, time.Second)
+	if err := limiter.Acquire(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if idle := limiter.Idle(); idle != 0 {
+		t.Errorf("Idle() = %d right after acquiring the initial limit, want 0", idle)
+	}
+
+	startTime := time.Now()
+	if err := limiter.Acquire(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(startTime); elapsed < 40*time.Millisecond {
+		t.Errorf("Acquire() returned after %v, want to wait for the ramp up", elapsed)
+	}
+	time.Sleep(60 * time.Millisecond)
+	running, limit := limiter.Status()
+	if running != 2 || limit != 3 {
+		t.Errorf("Status() = (%d, %d), want (2, 3)", running, limit)
+	}
+}
+
+func TestConcurrencyLimiterBacksOffOnRateLimited(t *testing.T) {
+	limiter := newConcurrencyLimiter(4, 4, time.Hour, 100*time.Millisecond, time.Second)
+	for i := 0; i < 4; i++ {
+		if err := limiter.Acquire(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	limiter.OnRateLimited()
+	// Rate limited responses during the backoff don't shrink the limit further.
+	limiter.OnRateLimited()
+	limiter.Release()
+	limiter.Release()
+	limiter.Release()
+	if _, limit := limiter.Status(); limit != 2 {
+		t.Errorf("limit = %d after rate limited, want 2", limit)
+	}
+	if idle := limiter.Idle(); idle != 0 {
+		t.Errorf("Idle() = %d during the backoff, want 0", idle)
+	}
+
+	startTime := time.Now()
+	if err := limiter.Acquire(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(startTime); elapsed < 90*time.Millisecond {
+		t.Errorf("Acquire() returned after %v, want to wait for the backoff", elapsed)
+	}
+
+	limiter.OnRateLimited()
+	if limiter.backoff != 200*time.Millisecond {
+		t.Errorf("backoff = %v after consecutive rate limits, want 200ms", limiter.backoff)
+	}
+}
+
+func TestConcurrencyLimiterAcquireCancelled(t *testing.T) {
+	limiter := newConcurrencyLimiter(1, 1, time.Hour, time.Second, time.Second)
+	if err := limiter.Acquire(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancelCause(context.Background())
+	wantErr := errors.New("cancelled")
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cancel(wantErr)
+	}()
+	if err := limiter.Acquire(ctx); !errors.Is(err, wantErr) {
+		t.Errorf("Acquire() = %v, want %v", err, wantErr)
+	}
+}
@@ -20,129 +20,391 @@ import (
 	"fmt"
 	"log/slog"
 	"sync"
-	"sync/atomic"
 	"time"
 
+	"github.com/GoogleCloudPlatform/khi/pkg/common/httpclient"
+	"github.com/GoogleCloudPlatform/khi/pkg/common/structurev2"
 	"github.com/GoogleCloudPlatform/khi/pkg/common/worker"
 	"github.com/GoogleCloudPlatform/khi/pkg/inspection/metadata/progress"
 	"github.com/GoogleCloudPlatform/khi/pkg/log"
 	"github.com/GoogleCloudPlatform/khi/pkg/source/gcp/api"
 	gcp_log "github.com/GoogleCloudPlatform/khi/pkg/source/gcp/log"
+	"github.com/GoogleCloudPlatform/khi/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
+const (
+	// defaultSplitThreshold is the count of log entries a segment can receive before its remaining range is split. This is 5 pages of `entries:list`.
+	defaultSplitThreshold = 5000
+	// minEntriesBeforeSplit is the count of log entries a segment must receive before estimating its density. This is a page of `entries:list`.
+	minEntriesBeforeSplit = 1000
+	// minSegmentDuration is the shortest time range of a segment. Timestamps in filters are in seconds.
+	minSegmentDuration = time.Second
+
+	initialConcurrency = 1
+	rampUpInterval     = time.Second
+	minBackoff         = 5 * time.Second
+	maxBackoff         = 60 * time.Second
+)
+
+// ParallelQueryWorker queries logs in a time range by dividing it into segments queried in parallel.
+// A segment receiving many logs is split again and the remaining range is handed to idle workers.
+// The count of concurrent queries starts small and backs off when queries are rate limited.
 type ParallelQueryWorker struct {
-	workerCount int
-	baseQuery   string
-	startTime   time.Time
-	endTime     time.Time
-	apiClient   api.GCPClient
-	pool        *worker.Pool
+	workerCount    int
+	baseQuery      string
+	startTime      time.Time
+	endTime        time.Time
+	apiClient      api.LogEntryLister
+	pool           *worker.Pool
+	splitThreshold int
+	limiter        *concurrencyLimiter
+	// includeEnd is true when logs at the end time are included in the result.
+	includeEnd bool
 }
 
-func NewParallelQueryWorker(pool *worker.Pool, apiClient api.GCPClient, baseQuery string, startTime time.Time, endTime time.Time, workerCount int) *ParallelQueryWorker {
+func NewParallelQueryWorker(pool *worker.Pool, apiClient api.LogEntryLister, baseQuery string, startTime time.Time, endTime time.Time, workerCount int) *ParallelQueryWorker {
 	return &ParallelQueryWorker{
-		baseQuery:   baseQuery,
-		startTime:   startTime,
-		endTime:     endTime,
-		workerCount: workerCount,
-		apiClient:   apiClient,
-		pool:        pool,
+		baseQuery:      baseQuery,
+		startTime:      startTime,
+		endTime:        endTime,
+		workerCount:    workerCount,
+		apiClient:      apiClient,
+		pool:           pool,
+		splitThreshold: defaultSplitThreshold,
+		limiter:        newConcurrencyLimiter(initialConcurrency, workerCount, rampUpInterval, minBackoff, maxBackoff),
+		includeEnd:     true,
 	}
 }
 
+// ExcludeEndTime makes the worker exclude logs at the end time. This is used to query adjacent time ranges without duplicated logs.
+func (p *ParallelQueryWorker) ExcludeEndTime() *ParallelQueryWorker {
+	p.includeEnd = false
+	return p
+}
+
+// querySegment is a time range queried by a worker.
+type querySegment struct {
+	begin      time.Time
+	end        time.Time
+	includeEnd bool
+	// skippedLogKeys is the set of logKey of logs already received by the segment this segment was split from.
+	skippedLogKeys map[string]struct{}
+	// covered is the time range from begin already received. This is guarded by the lock of queryState.
+	covered time.Duration
+}
+
+// queryState is the state shared between the workers of a Query call.
+type queryState struct {
+	lock    sync.Mutex
+	cond    *sync.Cond
+	pending []*querySegment
+	running map[*querySegment]struct{}
+	// completed is the sum of time ranges already received.
+	completed time.Duration
+	// receivedCount is the count of logs received including logs of running segments.
+	receivedCount int
+	// sinkLock serializes the calls of sink.
+	sinkLock sync.Mutex
+	sink     LogSink
+}
+
+// LogSink receives logs from ParallelQueryWorker.
+type LogSink = func(l *log.Log) error
+
+// Query returns every log in the time range.
 func (p *ParallelQueryWorker) Query(ctx context.Context, resourceNames []string, progress *progress.TaskProgress) ([]*log.Log, error) {
-	timeSegments := divideTimeSegments(p.startTime, p.endTime, p.workerCount)
-	percentages := make([]float32, p.workerCount)
-	logSink := make(chan *log.Log)
-	logEntries := []*log.Log{}
-	wg := sync.WaitGroup{}
-	queryStartTime := time.Now()
-	threadCount := atomic.Int32{}
-	threadCount.Add(1)
-	go func() {
-		cancellable, cancel := context.WithCancel(ctx)
-		go func() {
-			for {
-				select {
-				case <-cancellable.Done():
-					return
-				case <-time.After(time.Second):
-					currentTime := time.Now()
-
-					speed := float64(len(logEntries)) / currentTime.Sub(queryStartTime).Seconds()
-					s := float32(0)
-					for _, p := range percentages {
-						s += p
-					}
-					progressRatio := s / float32(len(percentages))
-					progress.Update(progressRatio, fmt.Sprintf("%.2f lps(concurrency %d)", speed, threadCount.Load()))
-				}
-			}
-		}()
-		for logEntry := range logSink {
-			logEntries = append(logEntries, logEntry)
-		}
-		cancel()
+	logs := []*log.Log{}
+	err := p.QueryTo(ctx, resourceNames, progress, func(l *log.Log) error {
+		logs = append(logs, l)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return logs, nil
+}
+
+// QueryTo sends logs in the time range to the sink as they are received instead of returning every log at once.
+// The sink is not called concurrently. Logs are not sent in the timestamp order.
+func (p *ParallelQueryWorker) QueryTo(ctx context.Context, resourceNames []string, progress *progress.TaskProgress, sink LogSink) (err error) {
+	ctx, span := tracing.Start(ctx, "ParallelQueryWorker.QueryTo", trace.WithAttributes(
+		attribute.String("khi.query.start_time", p.startTime.Format(time.RFC3339)),
+		attribute.String("khi.query.end_time", p.endTime.Format(time.RFC3339)),
+		attribute.Int("khi.query.worker_count", p.workerCount),
+	))
+	defer func() {
+		tracing.EndSpan(span, err)
 	}()
+	state := &queryState{
+		running: map[*querySegment]struct{}{},
+		sink:    sink,
+	}
+	state.cond = sync.NewCond(&state.lock)
+	timeSegments := divideTimeSegments(p.startTime, p.endTime, p.workerCount)
+	for i := 0; i < len(timeSegments)-1; i++ {
+		state.pending = append(state.pending, &querySegment{
+			begin:      timeSegments[i],
+			end:        timeSegments[i+1],
+			includeEnd: i == len(timeSegments)-2 && p.includeEnd,
+		})
+	}
 
 	cancellableCtx, cancel := context.WithCancelCause(ctx)
 	defer cancel(errors.New("query completed"))
+	queryStartTime := time.Now()
+	progressCtx, cancelProgress := context.WithCancel(ctx)
+	defer cancelProgress()
+	go p.reportProgress(progressCtx, state, queryStartTime, progress)
 
-	for i := 0; i < len(timeSegments)-1; i++ {
-		workerIndex := i
-		begin := timeSegments[i]
-		end := timeSegments[i+1]
-		includeEnd := i == len(timeSegments)-1
-		query := fmt.Sprintf("%s\n%s", p.baseQuery, TimeRangeQuerySection(begin, end, includeEnd))
-		subLogSink := make(chan *log.Log)
-		wg.Add(1)
+	state.lock.Lock()
+	for {
+		for len(state.pending) == 0 && len(state.running) > 0 {
+			state.cond.Wait()
+		}
+		if len(state.pending) == 0 {
+			break
+		}
+		segment := state.pending[0]
+		state.pending = state.pending[1:]
+		state.running[segment] = struct{}{}
+		state.lock.Unlock()
+
+		if err := p.limiter.Acquire(cancellableCtx); err != nil {
+			state.lock.Lock()
+			delete(state.running, segment)
+			break
+		}
 		p.pool.Run(func() {
-			defer wg.Done()
-			go func() {
-				threadCount.Add(1)
-				err := p.apiClient.ListLogEntries(cancellableCtx, resourceNames, query, subLogSink)
-				if err != nil && !errors.Is(err, context.Canceled) {
-					slog.WarnContext(cancellableCtx, fmt.Sprintf("query thread failed with an error\n%s", err))
-					cancel(err)
-				}
-			}()
-			for l := range subLogSink {
-				err := l.SetFieldSetReader(&gcp_log.GCPCommonFieldSetReader{})
-				if err != nil {
-					slog.WarnContext(ctx, fmt.Sprintf("failed to read CommonFieldSet from obtained log %s", err.Error()))
-					continue
-				}
-				err = l.SetFieldSetReader(&gcp_log.GCPMainMessageFieldSetReader{})
-				if err != nil {
-					slog.WarnContext(ctx, fmt.Sprintf("failed to read MainMessageFieldSet from obtained log %s", err.Error()))
-					continue
-				}
-				commonFieldSet, err := log.GetFieldSet(l, &log.CommonFieldSet{})
-				if err != nil {
-					slog.WarnContext(ctx, fmt.Sprintf("failed to read GCPCommonFieldSet from obtained log %s", err.Error()))
-					continue
-				}
-				percentages[workerIndex] = float32(commonFieldSet.Timestamp.Sub(begin)) / float32(end.Sub(begin))
-				logSink <- l
+			defer p.limiter.Release()
+			pieces, err := p.querySegment(cancellableCtx, resourceNames, segment, state)
+			if err != nil && !errors.Is(err, context.Canceled) {
+				slog.WarnContext(cancellableCtx, fmt.Sprintf("query thread failed with an error\n%s", err))
+				cancel(err)
+			}
+			state.lock.Lock()
+			defer state.lock.Unlock()
+			delete(state.running, segment)
+			if len(pieces) > 0 {
+				state.completed += pieces[0].begin.Sub(segment.begin)
+				state.pending = append(state.pending, pieces...)
+			} else {
+				state.completed += segment.end.Sub(segment.begin)
 			}
-			percentages[workerIndex] = 1
-			threadCount.Add(-1)
+			state.cond.Broadcast()
 		})
-		if errors.Is(cancellableCtx.Err(), context.Canceled) {
-			break
-		}
-		// To avoid being rate limited by accessing all at once, the access timing is shifted by 3000ms.
-		<-time.After(time.Second * 3)
+		state.lock.Lock()
+	}
+	// Wait for the running segments after the query was cancelled.
+	for len(state.running) > 0 {
+		state.cond.Wait()
 	}
-	wg.Wait()
-	close(logSink)
-	err := context.Cause(cancellableCtx)
+	state.lock.Unlock()
+
+	span.SetAttributes(attribute.Int("khi.query.received_logs", state.receivedCount))
+	err = context.Cause(cancellableCtx)
 	if err != nil {
 		cancel(err)
-		return nil, err
+		return err
 	}
 	cancel(nil)
-	return logEntries, nil
+	return nil
+}
+
+// querySegment receives logs in the segment. It returns the pieces of the remaining range when the segment is split.
+func (p *ParallelQueryWorker) querySegment(ctx context.Context, resourceNames []string, segment *querySegment, state *queryState) (pieces []*querySegment, err error) {
+	ctx, span := tracing.Start(ctx, "ParallelQueryWorker.querySegment", trace.WithAttributes(
+		attribute.String("khi.query.segment_begin", segment.begin.Format(time.RFC3339)),
+		attribute.String("khi.query.segment_end", segment.end.Format(time.RFC3339)),
+	))
+	defer func() {
+		span.SetAttributes(attribute.Int("khi.query.split_pieces", len(pieces)))
+		tracing.EndSpan(span, err)
+	}()
+	segmentCtx, cancelSegment := context.WithCancel(ctx)
+	defer cancelSegment()
+	query := fmt.Sprintf("%s\n%s", p.baseQuery, TimeRangeQuerySection(segment.begin, segment.end, segment.includeEnd))
+	logSink := make(chan *log.Log)
+	listErrCh := make(chan error, 1)
+	go func() {
+		listErrCh <- p.apiClient.ListLogEntries(httpclient.WithRateLimitListener(segmentCtx, p.limiter), resourceNames, query, logSink)
+	}()
+
+	receivedCount := 0
+	// Logs are listed in the timestamp order. Only the logs in the second of the last received log are kept to skip them in the first piece when the segment is split.
+	lastSecondLogs := []*log.Log{}
+	lastSecond := time.Time{}
+	var sinkErr error
+	for l := range logSink {
+		// Logs sent after deciding to split are queried again in the pieces.
+		if pieces != nil || sinkErr != nil {
+			continue
+		}
+		err := l.SetFieldSetReader(&gcp_log.GCPCommonFieldSetReader{})
+		if err != nil {
+			slog.WarnContext(ctx, fmt.Sprintf("failed to read CommonFieldSet from obtained log %s", err.Error()))
+			continue
+		}
+		err = l.SetFieldSetReader(&gcp_log.GCPMainMessageFieldSetReader{})
+		if err != nil {
+			slog.WarnContext(ctx, fmt.Sprintf("failed to read MainMessageFieldSet from obtained log %s", err.Error()))
+			continue
+		}
+		commonFieldSet, err := log.GetFieldSet(l, &log.CommonFieldSet{})
+		if err != nil {
+			slog.WarnContext(ctx, fmt.Sprintf("failed to read GCPCommonFieldSet from obtained log %s", err.Error()))
+			continue
+		}
+		if len(segment.skippedLogKeys) > 0 {
+			if _, found := segment.skippedLogKeys[logKey(l, commonFieldSet)]; found {
+				continue
+			}
+		}
+		state.sinkLock.Lock()
+		err = state.sink(l)
+		state.sinkLock.Unlock()
+		if err != nil {
+			sinkErr = err
+			cancelSegment()
+			continue
+		}
+		receivedCount++
+		second := commonFieldSet.Timestamp.Truncate(minSegmentDuration)
+		if !second.Equal(lastSecond) {
+			lastSecond = second
+			lastSecondLogs = lastSecondLogs[:0]
+		}
+		lastSecondLogs = append(lastSecondLogs, l)
+		state.lock.Lock()
+		segment.covered = commonFieldSet.Timestamp.Sub(segment.begin)
+		state.receivedCount++
+		state.lock.Unlock()
+
+		if p.shouldSplit(segment, receivedCount, commonFieldSet.Timestamp, state) {
+			pieces = p.splitRemaining(segment, lastSecond, lastSecondLogs)
+			if pieces != nil {
+				cancelSegment()
+			}
+		}
+	}
+	err = <-listErrCh
+	if sinkErr != nil {
+		return nil, sinkErr
+	}
+	if err != nil && pieces == nil {
+		return nil, err
+	}
+	span.SetAttributes(attribute.Int("khi.query.received_logs", receivedCount))
+	return pieces, nil
+}
+
+// shouldSplit returns true when the segment is dense and there are idle workers to hand the remaining range.
+func (p *ParallelQueryWorker) shouldSplit(segment *querySegment, receivedCount int, lastTimestamp time.Time, state *queryState) bool {
+	if receivedCount < minEntriesBeforeSplit || receivedCount%minEntriesBeforeSplit != 0 {
+		return false
+	}
+	if segment.end.Sub(lastTimestamp.Truncate(minSegmentDuration)) < 2*minSegmentDuration {
+		return false
+	}
+	state.lock.Lock()
+	pendingCount := len(state.pending)
+	state.lock.Unlock()
+	if pendingCount > 0 || p.limiter.Idle() == 0 {
+		return false
+	}
+	if receivedCount >= p.splitThreshold {
+		return true
+	}
+	// Estimate the count of logs in the whole segment from the density of the received range.
+	covered := lastTimestamp.Sub(segment.begin)
+	if covered <= 0 {
+		return true
+	}
+	estimated := float64(receivedCount) * float64(segment.end.Sub(segment.begin)) / float64(covered)
+	return estimated >= float64(p.splitThreshold)
+}
+
+// splitRemaining divides the range after the last received log into pieces for the idle workers and the current worker.
+// The first piece starts from lastSecond, the beginning of the second of the last received log, because timestamps in filters are in seconds.
+// lastSecondLogs are the logs in that second already received. They are skipped in the first piece.
+func (p *ParallelQueryWorker) splitRemaining(segment *querySegment, lastSecond time.Time, lastSecondLogs []*log.Log) []*querySegment {
+	splitBegin := lastSecond
+	if !splitBegin.After(segment.begin.Truncate(minSegmentDuration)) {
+		splitBegin = segment.begin
+	}
+	pieceCount := min(p.limiter.Idle()+1, int(segment.end.Sub(splitBegin)/minSegmentDuration))
+	if pieceCount < 2 {
+		return nil
+	}
+	boundaries := divideTimeSegments(splitBegin, segment.end, pieceCount)
+	pieces := []*querySegment{}
+	for i := 0; i < len(boundaries)-1; i++ {
+		begin := boundaries[i].Truncate(minSegmentDuration)
+		end := boundaries[i+1].Truncate(minSegmentDuration)
+		if i == 0 {
+			begin = splitBegin
+		}
+		if i == len(boundaries)-2 {
+			end = segment.end
+		}
+		if !end.After(begin) {
+			continue
+		}
+		pieces = append(pieces, &querySegment{
+			begin:      begin,
+			end:        end,
+			includeEnd: i == len(boundaries)-2 && segment.includeEnd,
+		})
+	}
+	pieces[0].skippedLogKeys = map[string]struct{}{}
+	for _, l := range lastSecondLogs {
+		commonFieldSet, err := log.GetFieldSet(l, &log.CommonFieldSet{})
+		if err == nil {
+			pieces[0].skippedLogKeys[logKey(l, commonFieldSet)] = struct{}{}
+		}
+	}
+	for key := range segment.skippedLogKeys {
+		pieces[0].skippedLogKeys[key] = struct{}{}
+	}
+	return pieces
+}
+
+func (p *ParallelQueryWorker) reportProgress(ctx context.Context, state *queryState, queryStartTime time.Time, progress *progress.TaskProgress) {
+	total := p.endTime.Sub(p.startTime)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(time.Second):
+			state.lock.Lock()
+			covered := state.completed
+			for segment := range state.running {
+				covered += segment.covered
+			}
+			logCount := state.receivedCount
+			state.lock.Unlock()
+			running, limit := p.limiter.Status()
+			speed := float64(logCount) / time.Since(queryStartTime).Seconds()
+			progressRatio := float32(1)
+			if total > 0 {
+				progressRatio = float32(min(float64(covered)/float64(total), 1))
+			}
+			progress.Update(progressRatio, fmt.Sprintf("%.2f lps(concurrency %d/%d)", speed, running, limit))
+		}
+	}
+}
+
+// logKey returns the key identifying a log to avoid adding a log twice when a segment is split.
+func logKey(l *log.Log, commonFieldSet *log.CommonFieldSet) string {
+	if commonFieldSet.DisplayID != "" {
+		return fmt.Sprintf("%s/%s", commonFieldSet.Timestamp.Format(time.RFC3339Nano), commonFieldSet.DisplayID)
+	}
+	serialized, err := l.Serialize("", &structurev2.JSONNodeSerializer{})
+	if err != nil {
+		return fmt.Sprintf("%s/%s", commonFieldSet.Timestamp.Format(time.RFC3339Nano), l.ID)
+	}
+	return string(serialized)
 }
 
 func divideTimeSegments(startTime time.Time, endTime time.Time, count int) []time.Time {
@@ -15,12 +15,20 @@
 package queryutil
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"reflect"
+	"slices"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/GoogleCloudPlatform/khi/pkg/common/structurev2"
 	"github.com/GoogleCloudPlatform/khi/pkg/common/worker"
+	"github.com/GoogleCloudPlatform/khi/pkg/inspection/metadata/progress"
+	"github.com/GoogleCloudPlatform/khi/pkg/log"
+	"github.com/GoogleCloudPlatform/khi/pkg/source/gcp/logfilter"
 
 	_ "github.com/GoogleCloudPlatform/khi/internal/testflags"
 )
@@ -135,3 +143,196 @@ func max(a, b int) int {
 	}
 	return b
 }
+
+// fakeLogEntryLister serves log entries matching the filter in the timestamp order in pages like `entries:list`.
+type fakeLogEntryLister struct {
+	entries  []*structurev2.NodeReader
+	pageSize int
+	lock     sync.Mutex
+	queries  []string
+}
+
+func newFakeLogEntryLister(t *testing.T, timestamps []time.Time) *fakeLogEntryLister {
+	t.Helper()
+	timestamps = slices.Clone(timestamps)
+	slices.SortFunc(timestamps, time.Time.Compare)
+	entries := []*structurev2.NodeReader{}
+	for i, timestamp := range timestamps {
+		node, err := structurev2.FromYAML(fmt.Sprintf(`{"insertId":"log-%d","timestamp":"%s"}`, i, timestamp.Format(time.RFC3339Nano)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		entries = append(entries, structurev2.NewNodeReader(node))
+	}
+	return &fakeLogEntryLister{entries: entries, pageSize: 1000}
+}
+
+// ListLogEntries implements api.LogEntryLister.
+func (f *fakeLogEntryLister) ListLogEntries(ctx context.Context, resourceNames []string, filter string, logSink chan *log.Log) error {
+	defer close(logSink)
+	f.lock.Lock()
+	f.queries = append(f.queries, filter)
+	f.lock.Unlock()
+	parsed, err := logfilter.Parse(filter)
+	if err != nil {
+		return err
+	}
+	for i, entry := range f.entries {
+		if i%f.pageSize == 0 {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			// Paginating takes time.
+			time.Sleep(time.Millisecond)
+		}
+		if parsed.Match(entry) {
+			logSink <- log.NewLog(entry)
+		}
+	}
+	return nil
+}
+
+func TestParallelQueryWorkerSplitsDenseSegment(t *testing.T) {
+	testCases := []struct {
+		name     string
+		interval time.Duration
+	}{
+		{name: "logs in 2 minutes", interval: 10 * time.Millisecond},
+		// Many logs are in the same second around the split points.
+		{name: "logs in 12 seconds", interval: time.Millisecond},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			startTime := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+			endTime := startTime.Add(time.Hour)
+			timestamps := []time.Time{}
+			for i := 0; i < 10; i++ {
+				timestamps = append(timestamps, startTime.Add(time.Duration(i)*6*time.Minute))
+			}
+			for i := 0; i < 12000; i++ {
+				timestamps = append(timestamps, startTime.Add(15*time.Minute+time.Duration(i)*tc.interval))
+			}
+			timestamps = append(timestamps, endTime)
+			lister := newFakeLogEntryLister(t, timestamps)
+
+			w := NewParallelQueryWorker(worker.NewPool(16), lister, `insertId:"log"`, startTime, endTime, 5)
+			w.limiter = newConcurrencyLimiter(5, 5, time.Millisecond, time.Millisecond, time.Millisecond)
+			logs, err := w.Query(context.Background(), []string{"projects/test-project"}, progress.NewTaskProgress("test"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			gotIDs := map[string]int{}
+			for _, l := range logs {
+				gotIDs[l.ReadStringOrDefault("insertId", "")]++
+			}
+			for i := range timestamps {
+				id := fmt.Sprintf("log-%d", i)
+				if gotIDs[id] != 1 {
+					t.Errorf("log %s was returned %d times, want 1", id, gotIDs[id])
+				}
+			}
+			if len(lister.queries) <= 5 {
+				t.Errorf("got %d queries, want the dense segment to be split into more queries", len(lister.queries))
+			}
+		})
+	}
+}
+
+func TestParallelQueryWorkerWithoutSplit(t *testing.T) {
+	startTime := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+	endTime := startTime.Add(time.Hour)
+	lister := newFakeLogEntryLister(t, []time.Time{startTime, startTime.Add(30 * time.Minute), endTime})
+
+	w := NewParallelQueryWorker(worker.NewPool(16), lister, `insertId:"log"`, startTime, endTime, 5)
+	logs, err := w.Query(context.Background(), []string{"projects/test-project"}, progress.NewTaskProgress("test"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(logs) != 3 {
+		t.Errorf("got %d logs, want 3", len(logs))
+	}
+	if len(lister.queries) != 5 {
+		t.Errorf("got %d queries, want 5", len(lister.queries))
+	}
+}
+
+func TestParallelQueryWorkerExcludeEndTime(t *testing.T) {
+	startTime := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+	endTime := startTime.Add(time.Hour)
+	lister := newFakeLogEntryLister(t, []time.Time{startTime, startTime.Add(30 * time.Minute), endTime})
+
+	w := NewParallelQueryWorker(worker.NewPool(16), lister, `insertId:"log"`, startTime, endTime, 5).ExcludeEndTime()
+	logs, err := w.Query(context.Background(), []string{"projects/test-project"}, progress.NewTaskProgress("test"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(logs) != 2 {
+		t.Errorf("got %d logs, want 2", len(logs))
+	}
+}
+
+// streamingLogEntryLister sends a log and waits for the sink to receive it before completing the query.
+type streamingLogEntryLister struct {
+	received chan struct{}
+}
+
+// ListLogEntries implements api.LogEntryLister.
+func (s *streamingLogEntryLister) ListLogEntries(ctx context.Context, resourceNames []string, filter string, logSink chan *log.Log) error {
+	defer close(logSink)
+	node, err := structurev2.FromYAML(`{"insertId":"log-0","timestamp":"2025-01-01T00:00:00Z"}`)
+	if err != nil {
+		return err
+	}
+	logSink <- log.NewLog(structurev2.NewNodeReader(node))
+	select {
+	case <-s.received:
+		return nil
+	case <-time.After(10 * time.Second):
+		return errors.New("the log was not sent to the sink before the segment completed")
+	}
+}
+
+func TestParallelQueryWorkerSendsLogsAsReceived(t *testing.T) {
+	startTime := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+	lister := &streamingLogEntryLister{received: make(chan struct{})}
+	w := NewParallelQueryWorker(worker.NewPool(1), lister, `insertId:"log"`, startTime, startTime.Add(time.Hour), 1)
+
+	var once sync.Once
+	count := 0
+	err := w.QueryTo(context.Background(), []string{"projects/test-project"}, progress.NewTaskProgress("test"), func(l *log.Log) error {
+		count++
+		once.Do(func() { close(lister.received) })
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Errorf("got %d logs, want 1", count)
+	}
+}
+
+func TestParallelQueryWorkerReturnsSinkError(t *testing.T) {
+	startTime := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+	endTime := startTime.Add(time.Hour)
+	timestamps := []time.Time{}
+	for i := 0; i < 100; i++ {
+		timestamps = append(timestamps, startTime.Add(time.Duration(i)*time.Second))
+	}
+	lister := newFakeLogEntryLister(t, timestamps)
+	w := NewParallelQueryWorker(worker.NewPool(1), lister, `insertId:"log"`, startTime, endTime, 1)
+
+	sinkErr := errors.New("test error")
+	count := 0
+	err := w.QueryTo(context.Background(), []string{"projects/test-project"}, progress.NewTaskProgress("test"), func(l *log.Log) error {
+		count++
+		return sinkErr
+	})
+	if !errors.Is(err, sinkErr) {
+		t.Errorf("QueryTo() returned %v, want %v", err, sinkErr)
+	}
+	if count != 1 {
+		t.Errorf("the sink was called %d times after returning an error, want 1", count)
+	}
+}
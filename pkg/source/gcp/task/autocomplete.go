@@ -16,8 +16,9 @@ package task
 
 import (
 	"context"
-	"fmt"
+	"strings"
 
+	"github.com/GoogleCloudPlatform/khi/pkg/common/cache"
 	inspection_cached_task "github.com/GoogleCloudPlatform/khi/pkg/inspection/cached_task"
 	"github.com/GoogleCloudPlatform/khi/pkg/source/gcp/api"
 	"github.com/GoogleCloudPlatform/khi/pkg/task"
@@ -31,6 +32,16 @@ type AutocompleteClusterNameList struct {
 	Error        string
 }
 
+// AutocompleteDependencyDigest returns the DependencyDigest of an autocomplete task from its inputs and the credential the client sends requests with.
+// The results of autocomplete tasks are shared among inspections, so a result obtained with a credential must not be reused for the other credentials.
+func AutocompleteDependencyDigest(client api.GCPClient, inputs ...string) string {
+	credentialDigest := ""
+	if dependency, ok := client.(cache.CacheDependency); ok {
+		credentialDigest = dependency.Digest()
+	}
+	return strings.Join(append([]string{credentialDigest}, inputs...), "-")
+}
+
 var AutocompleteLocationTaskID taskid.TaskImplementationID[[]string] = taskid.NewDefaultImplementationID[[]string](GCPPrefix + "autocomplete/location")
 
 // default implementation for "Location" field
@@ -39,12 +50,12 @@ var AutocompleteLocationTask = inspection_cached_task.NewCachedTask(Autocomplete
 		InputProjectIdTaskID.Ref(), // for API restriction
 	},
 	func(ctx context.Context, prevValue inspection_cached_task.PreviousTaskResult[[]string]) (inspection_cached_task.PreviousTaskResult[[]string], error) {
-		client, err := api.DefaultGCPClientFactory.NewClient()
+		client, err := api.DefaultGCPClientFactory.NewClientFromContext(ctx)
 		if err != nil {
 			return inspection_cached_task.PreviousTaskResult[[]string]{}, err
 		}
 		projectID := task.GetTaskResult(ctx, InputProjectIdTaskID.Ref())
-		dependencyDigest := fmt.Sprintf("location-%s", projectID)
+		dependencyDigest := AutocompleteDependencyDigest(client, "location", projectID)
 
 		if prevValue.DependencyDigest == dependencyDigest {
 			return prevValue, nil
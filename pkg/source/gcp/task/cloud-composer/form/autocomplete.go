@@ -16,7 +16,6 @@ package composer_form
 
 import (
 	"context"
-	"fmt"
 
 	inspection_cached_task "github.com/GoogleCloudPlatform/khi/pkg/inspection/cached_task"
 	inspection_task "github.com/GoogleCloudPlatform/khi/pkg/inspection/task"
@@ -35,14 +34,14 @@ var AutocompleteClusterNames = inspection_cached_task.NewCachedTask(composer_tas
 	composer_taskid.InputComposerEnvironmentTaskID.Ref(),
 }, func(ctx context.Context, prevValue inspection_cached_task.PreviousTaskResult[*gcp_task.AutocompleteClusterNameList]) (inspection_cached_task.PreviousTaskResult[*gcp_task.AutocompleteClusterNameList], error) {
 
-	client, err := api.DefaultGCPClientFactory.NewClient()
+	client, err := api.DefaultGCPClientFactory.NewClientFromContext(ctx)
 	if err != nil {
 		return inspection_cached_task.PreviousTaskResult[*gcp_task.AutocompleteClusterNameList]{}, err
 	}
 
 	projectID := task.GetTaskResult(ctx, gcp_task.InputProjectIdTaskID.Ref())
 	environment := task.GetTaskResult(ctx, composer_taskid.InputComposerEnvironmentTaskID.Ref())
-	dependencyDigest := fmt.Sprintf("%s-%s", projectID, environment)
+	dependencyDigest := gcp_task.AutocompleteDependencyDigest(client, projectID, environment)
 
 	// when the user is inputing these information, abort
 	isWIP := projectID == "" || environment == ""
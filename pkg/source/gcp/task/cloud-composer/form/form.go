@@ -16,7 +16,6 @@ package composer_form
 
 import (
 	"context"
-	"fmt"
 
 	"github.com/GoogleCloudPlatform/khi/pkg/common"
 	inspection_cached_task "github.com/GoogleCloudPlatform/khi/pkg/inspection/cached_task"
@@ -32,13 +31,13 @@ var AutocompleteComposerEnvironmentNames = inspection_cached_task.NewCachedTask(
 	gcp_task.InputLocationsTaskID.Ref(),
 	gcp_task.InputProjectIdTaskID.Ref(),
 }, func(ctx context.Context, prevValue inspection_cached_task.PreviousTaskResult[[]string]) (inspection_cached_task.PreviousTaskResult[[]string], error) {
-	client, err := api.DefaultGCPClientFactory.NewClient()
+	client, err := api.DefaultGCPClientFactory.NewClientFromContext(ctx)
 	if err != nil {
 		return inspection_cached_task.PreviousTaskResult[[]string]{}, err
 	}
 	projectID := task.GetTaskResult(ctx, gcp_task.InputProjectIdTaskID.Ref())
 	location := task.GetTaskResult(ctx, gcp_task.InputLocationsTaskID.Ref())
-	dependencyDigest := fmt.Sprintf("%s-%s", projectID, location)
+	dependencyDigest := gcp_task.AutocompleteDependencyDigest(client, projectID, location)
 
 	if prevValue.DependencyDigest == dependencyDigest {
 		return prevValue, nil
@@ -20,6 +20,7 @@ import (
 
 	inspection_task_interface "github.com/GoogleCloudPlatform/khi/pkg/inspection/interface"
 	"github.com/GoogleCloudPlatform/khi/pkg/model/enum"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/history/grouper"
 	"github.com/GoogleCloudPlatform/khi/pkg/source/gcp/query"
 	gcp_task "github.com/GoogleCloudPlatform/khi/pkg/source/gcp/task"
 	composer_form "github.com/GoogleCloudPlatform/khi/pkg/source/gcp/task/cloud-composer/form"
@@ -32,6 +33,7 @@ var ComposerSchedulerLogQueryTask = query.NewQueryGeneratorTask(
 	composer_taskid.ComposerSchedulerLogQueryTaskID,
 	"Composer Environment/Airflow Scheduler",
 	enum.LogTypeComposerEnvironment,
+	grouper.AllDependentLogGrouper,
 	[]taskid.UntypedTaskReference{
 		gcp_task.InputProjectIdTaskID.Ref(),
 		composer_taskid.InputComposerEnvironmentTaskID.Ref(),
@@ -45,6 +47,7 @@ var ComposerDagProcessorManagerLogQueryTask = query.NewQueryGeneratorTask(
 	composer_taskid.ComposerDagProcessorManagerLogQueryTaskID,
 	"Composer Environment/DAG Processor Manager",
 	enum.LogTypeComposerEnvironment,
+	grouper.AllDependentLogGrouper,
 	[]taskid.UntypedTaskReference{
 		gcp_task.InputProjectIdTaskID.Ref(),
 		composer_taskid.InputComposerEnvironmentTaskID.Ref(),
@@ -58,6 +61,7 @@ var ComposerMonitoringLogQueryTask = query.NewQueryGeneratorTask(
 	composer_taskid.ComposerMonitoringLogQueryTaskID,
 	"Composer Environment/Airflow Monitoring",
 	enum.LogTypeComposerEnvironment,
+	grouper.AllDependentLogGrouper,
 	[]taskid.UntypedTaskReference{
 		gcp_task.InputProjectIdTaskID.Ref(),
 		composer_taskid.InputComposerEnvironmentTaskID.Ref(),
@@ -71,6 +75,7 @@ var ComposerWorkerLogQueryTask = query.NewQueryGeneratorTask(
 	composer_taskid.ComposerWorkerLogQueryTaskID,
 	"Composer Environment/Airflow Worker",
 	enum.LogTypeComposerEnvironment,
+	grouper.AllDependentLogGrouper,
 	[]taskid.UntypedTaskReference{
 		gcp_task.InputProjectIdTaskID.Ref(),
 		composer_taskid.InputComposerEnvironmentTaskID.Ref(),
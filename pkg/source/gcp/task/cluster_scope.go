@@ -0,0 +1,125 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package task
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/common"
+	"github.com/GoogleCloudPlatform/khi/pkg/common/khictx"
+	"github.com/GoogleCloudPlatform/khi/pkg/common/typedmap"
+	inspection_task_interface "github.com/GoogleCloudPlatform/khi/pkg/inspection/interface"
+	inspection_task "github.com/GoogleCloudPlatform/khi/pkg/inspection/task"
+	"github.com/GoogleCloudPlatform/khi/pkg/source/gcp/api"
+	"github.com/GoogleCloudPlatform/khi/pkg/task"
+	"github.com/GoogleCloudPlatform/khi/pkg/task/taskid"
+)
+
+// FleetClusterNameToken is the token in the cluster name form expanded to the every fleet membership in the project.
+const FleetClusterNameToken = "@fleet"
+
+// clusterNameDelimiter separates cluster names in the cluster name form to inspect multiple clusters in a run.
+const clusterNameDelimiter = ","
+
+var clusterScopeContextKey = typedmap.NewTypedKey[string]("khi.google.com/gcp/cluster-scope")
+
+var ClusterNamesTaskID = taskid.NewDefaultImplementationID[[]string](GCPPrefix + "cluster-names")
+
+// ClusterNamesTask returns the list of cluster names given in the cluster name form. The fleet token is expanded to the fleet memberships of the project.
+var ClusterNamesTask = inspection_task.NewInspectionTask(ClusterNamesTaskID, []taskid.UntypedTaskReference{
+	InputClusterNameTaskID.Ref(),
+	InputProjectIdTaskID.Ref(),
+	ClusterNamePrefixTaskID,
+}, func(ctx context.Context, taskMode inspection_task_interface.InspectionTaskMode) ([]string, error) {
+	clusterNames := task.GetTaskResult(ctx, InputClusterNameTaskID.Ref())
+	projectID := task.GetTaskResult(ctx, InputProjectIdTaskID.Ref())
+	prefix := task.GetTaskResult(ctx, ClusterNamePrefixTaskID)
+	return expandClusterNames(clusterNames, prefix, func() ([]string, error) {
+		client, err := api.DefaultGCPClientFactory.NewClientFromContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return client.GetFleetMembershipNames(ctx, projectID)
+	})
+})
+
+// expandClusterNames splits the converted value of the cluster name form and replaces the fleet token with the names returned from fleetMembershipNames.
+func expandClusterNames(clusterNames string, prefix string, fleetMembershipNames func() ([]string, error)) ([]string, error) {
+	result := []string{}
+	for _, clusterName := range splitClusterNames(clusterNames) {
+		if clusterName != FleetClusterNameToken {
+			result = append(result, clusterName)
+			continue
+		}
+		fleetNames, err := fleetMembershipNames()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list the fleet memberships\n%w", err)
+		}
+		for _, fleetName := range fleetNames {
+			result = append(result, prefix+fleetName)
+		}
+	}
+	result = common.DedupStringArray(result)
+	if len(result) == 0 {
+		return nil, fmt.Errorf("no cluster was found to inspect")
+	}
+	return result, nil
+}
+
+// splitClusterNames returns the trimmed non empty cluster names in the comma separated list.
+func splitClusterNames(clusterNames string) []string {
+	result := []string{}
+	for _, clusterName := range strings.Split(clusterNames, clusterNameDelimiter) {
+		clusterName = strings.TrimSpace(clusterName)
+		if clusterName != "" {
+			result = append(result, clusterName)
+		}
+	}
+	return result
+}
+
+// WithClusterScope returns the context used to generate queries for the given cluster in multi-cluster inspections.
+func WithClusterScope(ctx context.Context, clusterName string) context.Context {
+	return khictx.WithValue(ctx, clusterScopeContextKey, clusterName)
+}
+
+// ClusterScope returns the cluster name bound with WithClusterScope. It returns an empty string in single cluster inspections.
+func ClusterScope(ctx context.Context) string {
+	clusterName, err := khictx.GetValue(ctx, clusterScopeContextKey)
+	if err != nil {
+		return ""
+	}
+	return clusterName
+}
+
+// GetClusterName returns the name of the cluster to query. Tasks calling this must depend on ClusterNamesTaskID.
+// It returns the expanded cluster name in single cluster inspections, so the fleet token or a duplicated list never reaches queries.
+func GetClusterName(ctx context.Context) string {
+	if clusterName := ClusterScope(ctx); clusterName != "" {
+		return clusterName
+	}
+	return SingleClusterName(ctx)
+}
+
+// SingleClusterName returns the cluster name when only a cluster is inspected. It returns an empty string in multi-cluster inspections. Tasks calling this must depend on ClusterNamesTaskID.
+func SingleClusterName(ctx context.Context) string {
+	clusterNames := task.GetTaskResult(ctx, ClusterNamesTaskID.Ref())
+	if len(clusterNames) != 1 {
+		return ""
+	}
+	return clusterNames[0]
+}
@@ -0,0 +1,151 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package task
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/task"
+	"github.com/GoogleCloudPlatform/khi/pkg/task/taskid"
+	task_test "github.com/GoogleCloudPlatform/khi/pkg/task/test"
+
+	_ "github.com/GoogleCloudPlatform/khi/internal/testflags"
+)
+
+func TestExpandClusterNames(t *testing.T) {
+	fleetMembershipNames := func() ([]string, error) {
+		return []string{"fleet-a", "fleet-b"}, nil
+	}
+	testCases := []struct {
+		name                 string
+		clusterNames         string
+		prefix               string
+		fleetMembershipNames func() ([]string, error)
+		want                 []string
+		wantErr              bool
+	}{
+		{
+			name:                 "single cluster",
+			clusterNames:         "foo",
+			fleetMembershipNames: fleetMembershipNames,
+			want:                 []string{"foo"},
+		},
+		{
+			name:                 "multiple clusters",
+			clusterNames:         "foo, bar,,foo",
+			fleetMembershipNames: fleetMembershipNames,
+			want:                 []string{"bar", "foo"},
+		},
+		{
+			name:                 "fleet token with prefix",
+			clusterNames:         "awsClusters/foo,@fleet",
+			prefix:               "awsClusters/",
+			fleetMembershipNames: fleetMembershipNames,
+			want:                 []string{"awsClusters/fleet-a", "awsClusters/fleet-b", "awsClusters/foo"},
+		},
+		{
+			name:         "fleet with a membership",
+			clusterNames: "@fleet",
+			prefix:       "awsClusters/",
+			fleetMembershipNames: func() ([]string, error) {
+				return []string{"fleet-a"}, nil
+			},
+			want: []string{"awsClusters/fleet-a"},
+		},
+		{
+			name:         "failed to list fleet memberships",
+			clusterNames: "@fleet",
+			fleetMembershipNames: func() ([]string, error) {
+				return nil, errors.New("test error")
+			},
+			wantErr: true,
+		},
+		{
+			name:         "empty fleet",
+			clusterNames: "@fleet",
+			fleetMembershipNames: func() ([]string, error) {
+				return []string{}, nil
+			},
+			wantErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := expandClusterNames(tc.clusterNames, tc.prefix, tc.fleetMembershipNames)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("expandClusterNames() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("expandClusterNames() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestClusterScope(t *testing.T) {
+	ctx := context.Background()
+	if got := ClusterScope(ctx); got != "" {
+		t.Errorf("ClusterScope() = %q, want empty", got)
+	}
+	scoped := WithClusterScope(ctx, "cluster-a")
+	if got := ClusterScope(scoped); got != "cluster-a" {
+		t.Errorf("ClusterScope() = %q, want %q", got, "cluster-a")
+	}
+	if got := GetClusterName(scoped); got != "cluster-a" {
+		t.Errorf("GetClusterName() = %q, want %q", got, "cluster-a")
+	}
+}
+
+func TestGetClusterName(t *testing.T) {
+	testCases := []struct {
+		name         string
+		clusterNames []string
+		want         string
+	}{
+		{
+			name:         "single cluster",
+			clusterNames: []string{"cluster-a"},
+			want:         "cluster-a",
+		},
+		{
+			name:         "fleet with a membership",
+			clusterNames: []string{"awsClusters/fleet-a"},
+			want:         "awsClusters/fleet-a",
+		},
+		{
+			name:         "multiple clusters without scope",
+			clusterNames: []string{"cluster-a", "cluster-b"},
+			want:         "",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			getClusterNameTask := task.NewTask(taskid.NewDefaultImplementationID[string]("test-get-cluster-name"), []taskid.UntypedTaskReference{ClusterNamesTaskID.Ref()}, func(ctx context.Context) (string, error) {
+				return GetClusterName(ctx), nil
+			})
+			got, err := task_test.RunTask(context.Background(), getClusterNameTask, task_test.NewTaskDependencyValuePair(ClusterNamesTaskID.Ref(), tc.clusterNames))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tc.want {
+				t.Errorf("GetClusterName() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
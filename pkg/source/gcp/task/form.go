@@ -76,11 +76,11 @@ var InputProjectIdTask = form.NewTextFormTaskBuilder(InputProjectIdTaskID, Prior
 
 var InputClusterNameTaskID = taskid.NewDefaultImplementationID[string](GCPPrefix + "input/cluster-name")
 
-var clusterNameValidator = regexp.MustCompile(`^\s*[0-9a-z\-]+\s*$`)
+var clusterNameValidator = regexp.MustCompile(`^\s*(@fleet|[0-9a-z\-]+)(\s*,\s*(@fleet|[0-9a-z\-]+))*\s*$`)
 
 var InputClusterNameTask = form.NewTextFormTaskBuilder(InputClusterNameTaskID, PriorityForResourceIdentifierGroup+4000, "Cluster name").
 	WithDependencies([]taskid.UntypedTaskReference{AutocompleteClusterNamesTaskID, ClusterNamePrefixTaskID}).
-	WithDescription("The cluster name to gather logs.").
+	WithDescription("The cluster name to gather logs. Multiple clusters can be inspected on a timeline with the comma separated list of names or `@fleet` for every fleet membership. Resources of multi-cluster inspections are shown under their API version prefixed with the cluster name like `cluster-a:core/v1`.").
 	WithDefaultValueFunc(func(ctx context.Context, previousValues []string) (string, error) {
 		clusters := task.GetTaskResult(ctx, AutocompleteClusterNamesTaskID)
 		// If the previous value is included in the list of cluster names, the name is used as the default value.
@@ -104,24 +104,34 @@ var InputClusterNameTask = form.NewTextFormTaskBuilder(InputClusterNameTaskID, P
 		if clusters.Error != "" {
 			return fmt.Sprintf("Failed to obtain the cluster list due to the error '%s'.\n The suggestion list won't popup", clusters.Error), form_metadata.Warning, nil
 		}
-		convertedWithoutPrefix := strings.TrimPrefix(convertedValue.(string), prefix)
-		for _, suggestedCluster := range clusters.ClusterNames {
-			if suggestedCluster == convertedWithoutPrefix {
-				return "", form_metadata.Info, nil
+		for _, clusterName := range splitClusterNames(convertedValue.(string)) {
+			if clusterName == FleetClusterNameToken {
+				continue
+			}
+			convertedWithoutPrefix := strings.TrimPrefix(clusterName, prefix)
+			if slices.Index(clusters.ClusterNames, convertedWithoutPrefix) == -1 {
+				return fmt.Sprintf("Cluster `%s` was not found in the specified project at this time. It works for the clusters existed in the past but make sure the cluster name is right if you believe the cluster should be there.", convertedWithoutPrefix), form_metadata.Warning, nil
 			}
 		}
-		return fmt.Sprintf("Cluster `%s` was not found in the specified project at this time. It works for the clusters existed in the past but make sure the cluster name is right if you believe the cluster should be there.", value), form_metadata.Warning, nil
+		return "", form_metadata.Info, nil
 	}).
 	WithValidator(func(ctx context.Context, value string) (string, error) {
 		if !clusterNameValidator.Match([]byte(value)) {
-			return "Cluster name must match `^[0-9a-z:\\-]+$`", nil
+			return "Cluster name must match `^[0-9a-z\\-]+$` or be `@fleet`. Multiple names can be given as a comma separated list.", nil
 		}
 		return "", nil
 	}).
 	WithConverter(func(ctx context.Context, value string) (string, error) {
 		prefix := task.GetTaskResult(ctx, ClusterNamePrefixTaskID)
 
-		return prefix + strings.TrimSpace(value), nil
+		clusterNames := []string{}
+		for _, clusterName := range splitClusterNames(value) {
+			if clusterName != FleetClusterNameToken {
+				clusterName = prefix + clusterName
+			}
+			clusterNames = append(clusterNames, clusterName)
+		}
+		return strings.Join(clusterNames, clusterNameDelimiter), nil
 	}).
 	Build()
 
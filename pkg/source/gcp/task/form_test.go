@@ -138,7 +138,7 @@ func TestProjectIdInput(t *testing.T) {
 }
 
 func TestClusterNameInput(t *testing.T) {
-	wantDescription := "The cluster name to gather logs."
+	wantDescription := "The cluster name to gather logs. Multiple clusters can be inspected on a timeline with the comma separated list of names or `@fleet` for every fleet membership. Resources of multi-cluster inspections are shown under their API version prefixed with the cluster name like `cluster-a:core/v1`."
 	testClusterNamePrefix := task_test.StubTaskFromReferenceID(ClusterNamePrefixTaskID, "", nil)
 	mockClusterNamesTask1 := task_test.StubTaskFromReferenceID(AutocompleteClusterNamesTaskID, &AutocompleteClusterNameList{
 		ClusterNames: []string{"foo-cluster", "bar-cluster"},
@@ -191,12 +191,47 @@ func TestClusterNameInput(t *testing.T) {
 					Label:       "Cluster name",
 					Description: wantDescription,
 					HintType:    form.Error,
-					Hint:        "Cluster name must match `^[0-9a-z:\\-]+$`",
+					Hint:        "Cluster name must match `^[0-9a-z\\-]+$` or be `@fleet`. Multiple names can be given as a comma separated list.",
 				},
 				Suggestions: common.SortForAutocomplete("An invalid cluster name", []string{"foo-cluster", "bar-cluster"}),
 				Default:     "foo-cluster",
 			},
 		},
+		{
+			Name:          "multiple cluster names and fleet token",
+			Input:         "foo-cluster, bar-cluster,@fleet",
+			ExpectedValue: "foo-cluster,bar-cluster,@fleet",
+			Dependencies:  []task.UntypedTask{mockClusterNamesTask1, testClusterNamePrefix},
+			ExpectedFormField: form.TextParameterFormField{
+				ParameterFormFieldBase: form.ParameterFormFieldBase{
+					ID:          GCPPrefix + "input/cluster-name",
+					Type:        "Text",
+					Label:       "Cluster name",
+					Description: wantDescription,
+					HintType:    form.None,
+				},
+				Suggestions: common.SortForAutocomplete("foo-cluster, bar-cluster,@fleet", []string{"foo-cluster", "bar-cluster"}),
+				Default:     "foo-cluster",
+			},
+		},
+		{
+			Name:          "non existing cluster in multiple cluster names should show a hint",
+			Input:         "foo-cluster,nonexisting-cluster",
+			ExpectedValue: "foo-cluster,nonexisting-cluster",
+			Dependencies:  []task.UntypedTask{mockClusterNamesTask1, testClusterNamePrefix},
+			ExpectedFormField: form.TextParameterFormField{
+				ParameterFormFieldBase: form.ParameterFormFieldBase{
+					ID:          GCPPrefix + "input/cluster-name",
+					Type:        "Text",
+					Label:       "Cluster name",
+					Description: wantDescription,
+					Hint:        "Cluster `nonexisting-cluster` was not found in the specified project at this time. It works for the clusters existed in the past but make sure the cluster name is right if you believe the cluster should be there.",
+					HintType:    form.Warning,
+				},
+				Suggestions: common.SortForAutocomplete("foo-cluster,nonexisting-cluster", []string{"foo-cluster", "bar-cluster"}),
+				Default:     "foo-cluster",
+			},
+		},
 		{
 			Name:          "non existing cluster should show a hint",
 			Input:         "nonexisting-cluster",
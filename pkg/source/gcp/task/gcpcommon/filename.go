@@ -17,6 +17,7 @@ package gcpcommon
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/GoogleCloudPlatform/khi/pkg/common/khictx"
@@ -39,11 +40,16 @@ var HeaderSuggestedFileNameTask = inspection_task.NewInspectionTask(HeaderSugges
 	gcp_task.InputStartTimeTaskID.Ref(),
 	gcp_task.InputEndTimeTaskID.Ref(),
 	gcp_task.InputClusterNameTaskID.Ref(),
+	gcp_task.ClusterNamesTaskID.Ref(),
 }, func(ctx context.Context, taskMode inspection_task_interface.InspectionTaskMode) (struct{}, error) {
 	metadataSet := khictx.MustGetValue(ctx, inspection_task_contextkey.InspectionRunMetadata)
 	header := typedmap.GetOrDefault(metadataSet, header.HeaderMetadataKey, &header.Header{})
 
-	clusterName := task.GetTaskResult(ctx, gcp_task.InputClusterNameTaskID.Ref())
+	// The expanded name is used in single cluster inspections to name the file after the fleet membership or the deduplicated cluster.
+	clusterName := gcp_task.SingleClusterName(ctx)
+	if clusterName == "" {
+		clusterName = task.GetTaskResult(ctx, gcp_task.InputClusterNameTaskID.Ref())
+	}
 	endTime := task.GetTaskResult(ctx, gcp_task.InputEndTimeTaskID.Ref())
 	startTime := task.GetTaskResult(ctx, gcp_task.InputStartTimeTaskID.Ref())
 
@@ -52,6 +58,9 @@ var HeaderSuggestedFileNameTask = inspection_task.NewInspectionTask(HeaderSugges
 	return struct{}{}, nil
 }, inspection_task.NewRequiredTaskLabel(), inspection_task.InspectionTypeLabel(inspectiontype.GCPK8sClusterInspectionTypes...))
 
+// clusterNamesReplacer rewrites the comma separated cluster names of multi-cluster inspections to be used in a file name.
+var clusterNamesReplacer = strings.NewReplacer(",", "_", gcp_task.FleetClusterNameToken, "fleet")
+
 func getSuggestedFileName(clusterName string, startTime, endTime time.Time) string {
-	return fmt.Sprintf("%s-%s-%s.khi", clusterName, startTime.Format("2006_01_02_1504"), endTime.Format("2006_01_02_1504"))
+	return fmt.Sprintf("%s-%s-%s.khi", clusterNamesReplacer.Replace(clusterName), startTime.Format("2006_01_02_1504"), endTime.Format("2006_01_02_1504"))
 }
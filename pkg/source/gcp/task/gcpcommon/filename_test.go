@@ -35,6 +35,7 @@ func TestHeaderSuggestedFileNameTask(t *testing.T) {
 	testCases := []struct {
 		Name              string
 		ClusterName       string
+		ClusterNames      []string
 		StartTime         time.Time
 		EndTime           time.Time
 		SuggestedFileName string
@@ -42,10 +43,27 @@ func TestHeaderSuggestedFileNameTask(t *testing.T) {
 		{
 			Name:              "normal case",
 			ClusterName:       "test-cluster",
+			ClusterNames:      []string{"test-cluster"},
 			StartTime:         time.Date(2023, time.January, 1, 10, 0, 0, 0, time.UTC),
 			EndTime:           time.Date(2023, time.January, 1, 11, 0, 0, 0, time.UTC),
 			SuggestedFileName: "test-cluster-2023_01_01_1000-2023_01_01_1100.khi",
 		},
+		{
+			Name:              "multiple clusters",
+			ClusterName:       "cluster-a,cluster-b,@fleet",
+			ClusterNames:      []string{"cluster-a", "cluster-b", "cluster-c"},
+			StartTime:         time.Date(2023, time.January, 1, 10, 0, 0, 0, time.UTC),
+			EndTime:           time.Date(2023, time.January, 1, 11, 0, 0, 0, time.UTC),
+			SuggestedFileName: "cluster-a_cluster-b_fleet-2023_01_01_1000-2023_01_01_1100.khi",
+		},
+		{
+			Name:              "fleet with a membership",
+			ClusterName:       "@fleet",
+			ClusterNames:      []string{"cluster-a"},
+			StartTime:         time.Date(2023, time.January, 1, 10, 0, 0, 0, time.UTC),
+			EndTime:           time.Date(2023, time.January, 1, 11, 0, 0, 0, time.UTC),
+			SuggestedFileName: "cluster-a-2023_01_01_1000-2023_01_01_1100.khi",
+		},
 	}
 
 	for _, tc := range testCases {
@@ -54,6 +72,7 @@ func TestHeaderSuggestedFileNameTask(t *testing.T) {
 			ctx := inspection_task_test.WithDefaultTestInspectionTaskContext(t.Context())
 			inspection_task_test.RunInspectionTask(ctx, HeaderSuggestedFileNameTask, inspection_task_interface.TaskModeRun, map[string]any{},
 				task_test.NewTaskDependencyValuePair(gcp_task.InputClusterNameTaskID.Ref(), tc.ClusterName),
+				task_test.NewTaskDependencyValuePair(gcp_task.ClusterNamesTaskID.Ref(), tc.ClusterNames),
 				task_test.NewTaskDependencyValuePair(gcp_task.InputStartTimeTaskID.Ref(), tc.StartTime),
 				task_test.NewTaskDependencyValuePair(gcp_task.InputEndTimeTaskID.Ref(), tc.EndTime),
 			)
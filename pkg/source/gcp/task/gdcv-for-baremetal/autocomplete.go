@@ -30,13 +30,14 @@ import (
 var AutocompleteClusterNames = inspection_cached_task.NewCachedTask(taskid.NewImplementationID(gcp_task.AutocompleteClusterNamesTaskID, "anthos-on-baremetal"), []taskid.UntypedTaskReference{
 	gcp_task.InputProjectIdTaskID.Ref(),
 }, func(ctx context.Context, prevValue inspection_cached_task.PreviousTaskResult[*gcp_task.AutocompleteClusterNameList]) (inspection_cached_task.PreviousTaskResult[*gcp_task.AutocompleteClusterNameList], error) {
-	client, err := api.DefaultGCPClientFactory.NewClient()
+	client, err := api.DefaultGCPClientFactory.NewClientFromContext(ctx)
 	if err != nil {
 		return inspection_cached_task.PreviousTaskResult[*gcp_task.AutocompleteClusterNameList]{}, err
 	}
 
 	projectID := task.GetTaskResult(ctx, gcp_task.InputProjectIdTaskID.Ref())
-	if projectID != "" && projectID == prevValue.DependencyDigest {
+	dependencyDigest := gcp_task.AutocompleteDependencyDigest(client, projectID)
+	if projectID != "" && dependencyDigest == prevValue.DependencyDigest {
 		return prevValue, nil
 	}
 
@@ -45,7 +46,7 @@ var AutocompleteClusterNames = inspection_cached_task.NewCachedTask(taskid.NewIm
 		if err != nil {
 			slog.WarnContext(ctx, fmt.Sprintf("Failed to read the cluster names in the project %s\n%s", projectID, err))
 			return inspection_cached_task.PreviousTaskResult[*gcp_task.AutocompleteClusterNameList]{
-				DependencyDigest: projectID,
+				DependencyDigest: dependencyDigest,
 				Value: &gcp_task.AutocompleteClusterNameList{
 					ClusterNames: []string{},
 					Error:        "Failed to get the list from API",
@@ -53,7 +54,7 @@ var AutocompleteClusterNames = inspection_cached_task.NewCachedTask(taskid.NewIm
 			}, nil
 		}
 		return inspection_cached_task.PreviousTaskResult[*gcp_task.AutocompleteClusterNameList]{
-			DependencyDigest: projectID,
+			DependencyDigest: dependencyDigest,
 			Value: &gcp_task.AutocompleteClusterNameList{
 				ClusterNames: clusterNames,
 				Error:        "",
@@ -61,7 +62,7 @@ var AutocompleteClusterNames = inspection_cached_task.NewCachedTask(taskid.NewIm
 		}, nil
 	}
 	return inspection_cached_task.PreviousTaskResult[*gcp_task.AutocompleteClusterNameList]{
-		DependencyDigest: projectID,
+		DependencyDigest: dependencyDigest,
 		Value: &gcp_task.AutocompleteClusterNameList{
 			ClusterNames: []string{},
 			Error:        "Project ID is empty",
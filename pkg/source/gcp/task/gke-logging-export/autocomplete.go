@@ -0,0 +1,34 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logexport
+
+import (
+	"context"
+
+	inspection_cached_task "github.com/GoogleCloudPlatform/khi/pkg/inspection/cached_task"
+	inspection_task "github.com/GoogleCloudPlatform/khi/pkg/inspection/task"
+	gcp_task "github.com/GoogleCloudPlatform/khi/pkg/source/gcp/task"
+	"github.com/GoogleCloudPlatform/khi/pkg/task/taskid"
+)
+
+// AutocompleteClusterNames returns no cluster names because the project may not be accessible when logs are given as exported files.
+var AutocompleteClusterNames = inspection_cached_task.NewCachedTask(taskid.NewImplementationID(gcp_task.AutocompleteClusterNamesTaskID, "gke-logging-export"), []taskid.UntypedTaskReference{}, func(ctx context.Context, prevValue inspection_cached_task.PreviousTaskResult[*gcp_task.AutocompleteClusterNameList]) (inspection_cached_task.PreviousTaskResult[*gcp_task.AutocompleteClusterNameList], error) {
+	return inspection_cached_task.PreviousTaskResult[*gcp_task.AutocompleteClusterNameList]{
+		Value: &gcp_task.AutocompleteClusterNameList{
+			ClusterNames: []string{},
+			Error:        "",
+		},
+	}, nil
+}, inspection_task.InspectionTypeLabel(InspectionTypeId))
@@ -0,0 +1,28 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logexport
+
+import (
+	"github.com/GoogleCloudPlatform/khi/pkg/inspection/form"
+	"github.com/GoogleCloudPlatform/khi/pkg/server/upload"
+	gcp_task "github.com/GoogleCloudPlatform/khi/pkg/source/gcp/task"
+	"github.com/GoogleCloudPlatform/khi/pkg/task/taskid"
+)
+
+var LogEntryFilesInputTaskID = taskid.NewDefaultImplementationID[upload.UploadResult](gcp_task.GCPPrefix + "input/logging-export-files")
+
+var LogEntryFilesForm = form.NewFileFormTaskBuilder(LogEntryFilesInputTaskID, gcp_task.PriorityForResourceIdentifierGroup+6000, "Cloud Logging Export Files", &upload.JSONDocumentUploadFileVerifier{}).
+	WithDescription("Upload `LogEntry` JSON files exported from Cloud Logging, such as the output of `gcloud logging read --format=json` or JSONL files written by a Cloud Storage log sink. Multiple files can be uploaded together as a gzip, zstd, tar or zip archive. Logs are selected from these files with the same filters used to query Cloud Logging.").
+	Build()
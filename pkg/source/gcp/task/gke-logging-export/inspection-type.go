@@ -0,0 +1,32 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logexport
+
+import (
+	"math"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/inspection"
+)
+
+var InspectionTypeId = "gcp-gke-logging-export"
+
+var GKELoggingExportInspectionType = inspection.InspectionType{
+	Id:   InspectionTypeId,
+	Name: "Google Kubernetes Engine (Cloud Logging export files)",
+	Description: `Visualize logs of a GKE cluster from files exported from Cloud Logging without accessing the project.
+Supporting the output of ` + "`gcloud logging read --format=json`" + ` and JSON files written by Cloud Storage log sinks.`,
+	Icon:     "assets/icons/gke.png",
+	Priority: math.MaxInt - 5,
+}
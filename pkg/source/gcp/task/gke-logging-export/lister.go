@@ -0,0 +1,148 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logexport
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"slices"
+	"time"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/common/structurev2"
+	inspection_task_interface "github.com/GoogleCloudPlatform/khi/pkg/inspection/interface"
+	"github.com/GoogleCloudPlatform/khi/pkg/inspection/metadata/progress"
+	inspection_task "github.com/GoogleCloudPlatform/khi/pkg/inspection/task"
+	"github.com/GoogleCloudPlatform/khi/pkg/log"
+	"github.com/GoogleCloudPlatform/khi/pkg/server/upload"
+	"github.com/GoogleCloudPlatform/khi/pkg/source/gcp/api"
+	"github.com/GoogleCloudPlatform/khi/pkg/source/gcp/logfilter"
+	gcp_taskid "github.com/GoogleCloudPlatform/khi/pkg/source/gcp/taskid"
+	"github.com/GoogleCloudPlatform/khi/pkg/task"
+	"github.com/GoogleCloudPlatform/khi/pkg/task/taskid"
+)
+
+// LocalLogEntryListerTask reads log entries from the uploaded export files and returns the LogEntryLister selecting logs from them.
+var LocalLogEntryListerTask = inspection_task.NewProgressReportableInspectionTask(taskid.NewImplementationID(gcp_taskid.LogEntryListerTaskID, "logging-export"), []taskid.UntypedTaskReference{
+	LogEntryFilesInputTaskID.Ref(),
+}, func(ctx context.Context, taskMode inspection_task_interface.InspectionTaskMode, tp *progress.TaskProgress) (api.LogEntryLister, error) {
+	if taskMode == inspection_task_interface.TaskModeDryRun {
+		return NewLocalLogEntryLister([]*structurev2.NodeReader{}), nil
+	}
+	result := task.GetTaskResult(ctx, LogEntryFilesInputTaskID.Ref())
+	reader, err := result.GetReader()
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	tp.MarkIndeterminate()
+	tp.Message = "Reading log entries from the uploaded file"
+	entries, err := readLogEntries(ctx, reader, result.Token.GetID())
+	if err != nil {
+		return nil, err
+	}
+	return NewLocalLogEntryLister(entries), nil
+}, inspection_task.InspectionTypeLabel(InspectionTypeId))
+
+// LocalLogEntryLister is a LogEntryLister selecting log entries from the ones loaded on memory instead of calling the Cloud Logging API.
+type LocalLogEntryLister struct {
+	// entries are sorted in the timestamp order.
+	entries []*structurev2.NodeReader
+}
+
+var _ api.LogEntryLister = (*LocalLogEntryLister)(nil)
+
+// NewLocalLogEntryLister returns a LocalLogEntryLister selecting logs from the given LogEntries.
+func NewLocalLogEntryLister(entries []*structurev2.NodeReader) *LocalLogEntryLister {
+	sorted := slices.Clone(entries)
+	slices.SortStableFunc(sorted, func(a, b *structurev2.NodeReader) int {
+		return entryTimestamp(a).Compare(entryTimestamp(b))
+	})
+	return &LocalLogEntryLister{
+		entries: sorted,
+	}
+}
+
+// ListLogEntries implements api.LogEntryLister.
+// Entries are selected with the filter evaluated locally and with the logName belonging to one of the resource names.
+func (l *LocalLogEntryLister) ListLogEntries(ctx context.Context, resourceNames []string, filter string, logSink chan *log.Log) error {
+	defer close(logSink)
+	parsedFilter, err := logfilter.Parse(filter)
+	if err != nil {
+		return fmt.Errorf("failed to parse the filter to select logs from the export files\n%w", err)
+	}
+	for _, entry := range l.entries {
+		if !logfilter.MatchResourceNames(entry, resourceNames) || !parsedFilter.Match(entry) {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case logSink <- log.NewLog(entry):
+		}
+	}
+	return nil
+}
+
+func entryTimestamp(entry *structurev2.NodeReader) time.Time {
+	timestamp, err := entry.ReadTimestamp("timestamp")
+	if err != nil {
+		return time.Time{}
+	}
+	return timestamp
+}
+
+// readLogEntries reads LogEntries from every file in the uploaded file.
+// Each file can contain JSON arrays of LogEntries (e.g. `gcloud logging read --format=json`) or a sequence of LogEntry objects (e.g. JSONL files from log sinks).
+func readLogEntries(ctx context.Context, reader io.Reader, name string) ([]*structurev2.NodeReader, error) {
+	entries := []*structurev2.NodeReader{}
+	err := upload.WalkUploadedMembers(reader, name, func(memberName string, memberReader io.Reader) error {
+		decoder := json.NewDecoder(memberReader)
+		for documentNumber := 1; ; documentNumber++ {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			var document json.RawMessage
+			err := decoder.Decode(&document)
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("failed to read JSON document #%d in %s: %w", documentNumber, path.Base(memberName), err)
+			}
+			rawEntries := []json.RawMessage{document}
+			if bytes.HasPrefix(bytes.TrimSpace(document), []byte("[")) {
+				if err := json.Unmarshal(document, &rawEntries); err != nil {
+					return fmt.Errorf("failed to read JSON document #%d in %s: %w", documentNumber, path.Base(memberName), err)
+				}
+			}
+			for _, rawEntry := range rawEntries {
+				node, err := structurev2.FromYAML(string(rawEntry))
+				if err != nil {
+					return fmt.Errorf("failed to read a log entry in JSON document #%d in %s: %w", documentNumber, path.Base(memberName), err)
+				}
+				entries = append(entries, structurev2.NewNodeReader(node))
+			}
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
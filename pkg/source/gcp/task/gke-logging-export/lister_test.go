@@ -0,0 +1,114 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logexport
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/log"
+	"github.com/GoogleCloudPlatform/khi/pkg/source/gcp/query/queryutil"
+	"github.com/google/go-cmp/cmp"
+
+	_ "github.com/GoogleCloudPlatform/khi/internal/testflags"
+)
+
+const testLogEntries = `[
+  {"insertId": "3", "logName": "projects/foo/logs/events", "timestamp": "2025-01-01T00:03:00Z", "resource": {"type": "k8s_cluster", "labels": {"cluster_name": "c1"}}},
+  {"insertId": "1", "logName": "projects/foo/logs/cloudaudit.googleapis.com%2Factivity", "timestamp": "2025-01-01T00:01:00Z", "resource": {"type": "k8s_cluster", "labels": {"cluster_name": "c1"}}}
+]
+{"insertId": "2", "logName": "projects/foo/logs/stdout", "timestamp": "2025-01-01T00:02:00Z", "resource": {"type": "k8s_container", "labels": {"cluster_name": "c1"}}}
+{"insertId": "4", "logName": "projects/bar/logs/stdout", "timestamp": "2025-01-01T00:04:00Z", "resource": {"type": "k8s_container", "labels": {"cluster_name": "c2"}}}
+`
+
+func listInsertIDs(t *testing.T, lister *LocalLogEntryLister, resourceNames []string, filter string) []string {
+	t.Helper()
+	logSink := make(chan *log.Log)
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- lister.ListLogEntries(context.Background(), resourceNames, filter, logSink)
+	}()
+	ids := []string{}
+	for l := range logSink {
+		ids = append(ids, l.ReadStringOrDefault("insertId", ""))
+	}
+	if err := <-errCh; err != nil {
+		t.Fatalf("ListLogEntries() returned an unexpected error: %v", err)
+	}
+	return ids
+}
+
+func TestLocalLogEntryLister(t *testing.T) {
+	entries, err := readLogEntries(context.Background(), strings.NewReader(testLogEntries), "logs.json")
+	if err != nil {
+		t.Fatalf("readLogEntries() returned an unexpected error: %v", err)
+	}
+	lister := NewLocalLogEntryLister(entries)
+	testCases := []struct {
+		name          string
+		resourceNames []string
+		filter        string
+		want          []string
+	}{
+		{
+			name:   "empty filter",
+			filter: "",
+			want:   []string{"1", "2", "3", "4"},
+		},
+		{
+			name:          "resource names",
+			resourceNames: []string{"projects/foo"},
+			want:          []string{"1", "2", "3"},
+		},
+		{
+			name:          "bucket view resource name",
+			resourceNames: []string{"projects/bar/locations/global/buckets/b/views/v"},
+			want:          []string{"4"},
+		},
+		{
+			name:   "filter with log ID",
+			filter: `resource.labels.cluster_name="c1" AND LOG_ID("cloudaudit.googleapis.com/activity")`,
+			want:   []string{"1"},
+		},
+		{
+			name: "filter with time range",
+			filter: `resource.type="k8s_container"
+` + queryutil.TimeRangeQuerySection(time.Date(2025, time.January, 1, 0, 1, 30, 0, time.UTC), time.Date(2025, time.January, 1, 0, 4, 0, 0, time.UTC), false),
+			want: []string{"2"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := listInsertIDs(t, lister, tc.resourceNames, tc.filter)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("ListLogEntries() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestLocalLogEntryListerInvalidFilter(t *testing.T) {
+	lister := NewLocalLogEntryLister(nil)
+	logSink := make(chan *log.Log)
+	err := lister.ListLogEntries(context.Background(), nil, `resource.type="k8s_container`, logSink)
+	if err == nil {
+		t.Errorf("ListLogEntries() with an invalid filter returned no error")
+	}
+	if _, open := <-logSink; open {
+		t.Errorf("ListLogEntries() didn't close the log sink")
+	}
+}
@@ -22,15 +22,14 @@ import (
 	"github.com/GoogleCloudPlatform/khi/pkg/common"
 	"github.com/GoogleCloudPlatform/khi/pkg/common/structurev2"
 	"github.com/GoogleCloudPlatform/khi/pkg/log"
+	"github.com/GoogleCloudPlatform/khi/pkg/log/logstore"
 	"github.com/GoogleCloudPlatform/khi/pkg/model/enum"
 	"github.com/GoogleCloudPlatform/khi/pkg/model/history"
-	"github.com/GoogleCloudPlatform/khi/pkg/model/history/grouper"
 	"github.com/GoogleCloudPlatform/khi/pkg/model/history/resourcepath"
 	"github.com/GoogleCloudPlatform/khi/pkg/parser"
 	"github.com/GoogleCloudPlatform/khi/pkg/source/gcp/inspectiontype"
 	gcp_task "github.com/GoogleCloudPlatform/khi/pkg/source/gcp/task"
 	gke_autoscaler_taskid "github.com/GoogleCloudPlatform/khi/pkg/source/gcp/task/gke/autoscaler/taskid"
-	"github.com/GoogleCloudPlatform/khi/pkg/task"
 	"github.com/GoogleCloudPlatform/khi/pkg/task/taskid"
 )
 
@@ -45,7 +44,7 @@ func (p *autoscalerLogParser) TargetLogType() enum.LogType {
 // Dependencies implements parser.Parser.
 func (*autoscalerLogParser) Dependencies() []taskid.UntypedTaskReference {
 	return []taskid.UntypedTaskReference{
-		gcp_task.InputClusterNameTaskID.Ref(),
+		gcp_task.ClusterNamesTaskID.Ref(),
 	}
 }
 
@@ -60,17 +59,16 @@ func (*autoscalerLogParser) GetParserName() string {
 }
 
 // LogTask implements parser.Parser.
-func (*autoscalerLogParser) LogTask() taskid.TaskReference[[]*log.Log] {
+func (*autoscalerLogParser) LogTask() taskid.TaskReference[*logstore.Store] {
 	return gke_autoscaler_taskid.AutoscalerQueryTaskID.Ref()
 }
 
-func (*autoscalerLogParser) Grouper() grouper.LogGrouper {
-	return grouper.AllDependentLogGrouper
-}
-
 // Parse implements parser.Parser.
 func (p *autoscalerLogParser) Parse(ctx context.Context, l *log.Log, cs *history.ChangeSet, builder *history.Builder) error {
-	clusterName := task.GetTaskResult(ctx, gcp_task.InputClusterNameTaskID.Ref())
+	clusterName := l.ClusterName
+	if clusterName == "" {
+		clusterName = gcp_task.SingleClusterName(ctx)
+	}
 
 	// scaleUp,scaleDown,nodePoolCreated,nodePoolDeleted
 	if l.Has("jsonPayload.decision") {
@@ -15,11 +15,11 @@
 package gke_autoscaler_taskid
 
 import (
-	"github.com/GoogleCloudPlatform/khi/pkg/log"
+	"github.com/GoogleCloudPlatform/khi/pkg/log/logstore"
 	"github.com/GoogleCloudPlatform/khi/pkg/source/gcp/query"
 	gcp_task "github.com/GoogleCloudPlatform/khi/pkg/source/gcp/task"
 	"github.com/GoogleCloudPlatform/khi/pkg/task/taskid"
 )
 
-var AutoscalerQueryTaskID = taskid.NewDefaultImplementationID[[]*log.Log](query.GKEQueryPrefix + "autoscaler")
+var AutoscalerQueryTaskID = taskid.NewDefaultImplementationID[*logstore.Store](query.GKEQueryPrefix + "autoscaler")
 var AutoscalerParserTaskID = taskid.NewDefaultImplementationID[struct{}](gcp_task.GCPPrefix + "feature/autoscaler-parser")
@@ -21,9 +21,9 @@ import (
 
 	"github.com/GoogleCloudPlatform/khi/pkg/common/structurev2"
 	"github.com/GoogleCloudPlatform/khi/pkg/log"
+	"github.com/GoogleCloudPlatform/khi/pkg/log/logstore"
 	"github.com/GoogleCloudPlatform/khi/pkg/model/enum"
 	"github.com/GoogleCloudPlatform/khi/pkg/model/history"
-	"github.com/GoogleCloudPlatform/khi/pkg/model/history/grouper"
 	"github.com/GoogleCloudPlatform/khi/pkg/model/history/resourcepath"
 	"github.com/GoogleCloudPlatform/khi/pkg/parser"
 	"github.com/GoogleCloudPlatform/khi/pkg/source/gcp/inspectiontype"
@@ -55,12 +55,9 @@ func (*computeAPIParser) GetParserName() string {
 }
 
 // LogTask implements parser.Parser.
-func (*computeAPIParser) LogTask() taskid.TaskReference[[]*log.Log] {
+func (*computeAPIParser) LogTask() taskid.TaskReference[*logstore.Store] {
 	return gke_compute_api_taskid.ComputeAPIQueryTaskID.Ref()
 }
-func (*computeAPIParser) Grouper() grouper.LogGrouper {
-	return grouper.AllDependentLogGrouper
-}
 
 // Parse implements parser.Parser.
 func (*computeAPIParser) Parse(ctx context.Context, l *log.Log, cs *history.ChangeSet, builder *history.Builder) error {
@@ -22,8 +22,10 @@ import (
 	inspection_task_interface "github.com/GoogleCloudPlatform/khi/pkg/inspection/interface"
 	inspection_task "github.com/GoogleCloudPlatform/khi/pkg/inspection/task"
 	"github.com/GoogleCloudPlatform/khi/pkg/model/enum"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/history/grouper"
 	"github.com/GoogleCloudPlatform/khi/pkg/source/gcp/query"
 	"github.com/GoogleCloudPlatform/khi/pkg/source/gcp/query/queryutil"
+	gcp_task "github.com/GoogleCloudPlatform/khi/pkg/source/gcp/task"
 	gke_compute_api_taskid "github.com/GoogleCloudPlatform/khi/pkg/source/gcp/task/gke/compute_api/taskid"
 	gke_k8saudit_taskid "github.com/GoogleCloudPlatform/khi/pkg/source/gcp/task/gke/k8s_audit/taskid"
 	"github.com/GoogleCloudPlatform/khi/pkg/task"
@@ -57,12 +59,12 @@ func generateComputeAPIQueryWithInstanceNameFilter(instanceNameFilter string) st
 `, instanceNameFilter)
 }
 
-var ComputeAPIQueryTask = query.NewQueryGeneratorTask(gke_compute_api_taskid.ComputeAPIQueryTaskID, "Compute API Logs", enum.LogTypeComputeApi, []taskid.UntypedTaskReference{
+var ComputeAPIQueryTask = query.NewClusterScopedQueryGeneratorTask(gke_compute_api_taskid.ComputeAPIQueryTaskID, "Compute API Logs", enum.LogTypeComputeApi, grouper.AllDependentLogGrouper, []taskid.UntypedTaskReference{
 	gke_k8saudit_taskid.K8sAuditParseTaskID.Ref(),
 }, &query.ProjectIDDefaultResourceNamesGenerator{}, func(ctx context.Context, i inspection_task_interface.InspectionTaskMode) ([]string, error) {
 	builder := task.GetTaskResult(ctx, inspection_task.BuilderGeneratorTaskID.Ref())
 
-	return GenerateComputeAPIQuery(i, builder.ClusterResource.GetNodes()), nil
+	return GenerateComputeAPIQuery(i, builder.ClusterResourceOf(gcp_task.ClusterScope(ctx)).GetNodes()), nil
 }, GenerateComputeAPIQuery(inspection_task_interface.TaskModeRun, []string{
 	"gke-test-cluster-node-1",
 	"gke-test-cluster-node-2",
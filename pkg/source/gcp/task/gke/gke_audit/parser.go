@@ -21,9 +21,9 @@ import (
 
 	"github.com/GoogleCloudPlatform/khi/pkg/common/structurev2"
 	"github.com/GoogleCloudPlatform/khi/pkg/log"
+	"github.com/GoogleCloudPlatform/khi/pkg/log/logstore"
 	"github.com/GoogleCloudPlatform/khi/pkg/model/enum"
 	"github.com/GoogleCloudPlatform/khi/pkg/model/history"
-	"github.com/GoogleCloudPlatform/khi/pkg/model/history/grouper"
 	"github.com/GoogleCloudPlatform/khi/pkg/model/history/resourcepath"
 	"github.com/GoogleCloudPlatform/khi/pkg/parser"
 	"github.com/GoogleCloudPlatform/khi/pkg/source/gcp/inspectiontype"
@@ -55,14 +55,10 @@ func (*gkeAuditLogParser) GetParserName() string {
 }
 
 // LogTask implements parser.Parser.
-func (*gkeAuditLogParser) LogTask() taskid.TaskReference[[]*log.Log] {
+func (*gkeAuditLogParser) LogTask() taskid.TaskReference[*logstore.Store] {
 	return gke_audit_taskid.GKEAuditLogQueryTaskID.Ref()
 }
 
-func (*gkeAuditLogParser) Grouper() grouper.LogGrouper {
-	return grouper.AllDependentLogGrouper
-}
-
 // Parse implements parser.Parser.
 func (p *gkeAuditLogParser) Parse(ctx context.Context, l *log.Log, cs *history.ChangeSet, builder *history.Builder) error {
 	commonFieldSet, err := log.GetFieldSet(l, &log.CommonFieldSet{})
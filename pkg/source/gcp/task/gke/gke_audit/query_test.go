@@ -65,3 +65,43 @@ func TestGeneratedGKEAuditQueryIsValid(t *testing.T) {
 		})
 	}
 }
+
+func TestGenerateGKEAuditQueryMatchesLogs(t *testing.T) {
+	testCases := []struct {
+		name        string
+		projectID   string
+		clusterName string
+		logFile     string
+		want        bool
+	}{
+		{
+			name:        "cluster operation",
+			projectID:   "project-id",
+			clusterName: "gke-basic-1",
+			logFile:     "test/logs/gke_audit/cluster_creation_started.yaml",
+			want:        true,
+		},
+		{
+			name:        "nodepool operation",
+			projectID:   "your-project-id",
+			clusterName: "gke-basic-1",
+			logFile:     "test/logs/gke_audit/nodepool_creation_started.yaml",
+			want:        true,
+		},
+		{
+			name:        "operation in other project",
+			projectID:   "other-project-id",
+			clusterName: "gke-basic-1",
+			logFile:     "test/logs/gke_audit/cluster_creation_started.yaml",
+			want:        false,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			query := GenerateGKEAuditQuery(tc.projectID, tc.clusterName)
+			if got := gcp_test.MatchLogQueryWithYamlLogFile(t, query, tc.logFile); got != tc.want {
+				t.Errorf("the query matched %s = %v, want %v\n%s", tc.logFile, got, tc.want, query)
+			}
+		})
+	}
+}
@@ -45,7 +45,11 @@ var GCPK8sAuditLogSourceTask = inspection_task.NewInspectionTask(gke_k8saudit_ta
 	if taskMode == inspection_task_interface.TaskModeDryRun {
 		return nil, nil
 	}
-	logs := task.GetTaskResult(ctx, gke_k8saudit_taskid.K8sAuditQueryTaskID.Ref())
+	store := task.GetTaskResult(ctx, gke_k8saudit_taskid.K8sAuditQueryTaskID.Ref())
+	logs, err := store.ReadAll()
+	if err != nil {
+		return nil, err
+	}
 
 	return &types.AuditLogParserLogSource{
 		Logs:      logs,
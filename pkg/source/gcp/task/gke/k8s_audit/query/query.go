@@ -22,6 +22,7 @@ import (
 
 	inspection_task_interface "github.com/GoogleCloudPlatform/khi/pkg/inspection/interface"
 	"github.com/GoogleCloudPlatform/khi/pkg/model/enum"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/history/grouper"
 	"github.com/GoogleCloudPlatform/khi/pkg/source/gcp/query"
 	"github.com/GoogleCloudPlatform/khi/pkg/source/gcp/query/queryutil"
 	gcp_task "github.com/GoogleCloudPlatform/khi/pkg/source/gcp/task"
@@ -31,12 +32,12 @@ import (
 	"github.com/GoogleCloudPlatform/khi/pkg/task"
 )
 
-var Task = query.NewQueryGeneratorTask(gke_k8saudit_taskid.K8sAuditQueryTaskID, "K8s audit logs", enum.LogTypeAudit, []taskid.UntypedTaskReference{
+var Task = query.NewClusterScopedQueryGeneratorTask(gke_k8saudit_taskid.K8sAuditQueryTaskID, "K8s audit logs", enum.LogTypeAudit, grouper.AllDependentLogGrouper, []taskid.UntypedTaskReference{
 	gcp_task.InputClusterNameTaskID.Ref(),
 	gcp_task.InputKindFilterTaskID.Ref(),
 	gcp_task.InputNamespaceFilterTaskID.Ref(),
 }, &query.ProjectIDDefaultResourceNamesGenerator{}, func(ctx context.Context, i inspection_task_interface.InspectionTaskMode) ([]string, error) {
-	clusterName := task.GetTaskResult(ctx, gcp_task.InputClusterNameTaskID.Ref())
+	clusterName := gcp_task.GetClusterName(ctx)
 	kindFilter := task.GetTaskResult(ctx, gcp_task.InputKindFilterTaskID.Ref())
 	namespaceFilter := task.GetTaskResult(ctx, gcp_task.InputNamespaceFilterTaskID.Ref())
 
@@ -21,9 +21,9 @@ import (
 
 	"github.com/GoogleCloudPlatform/khi/pkg/common/structurev2"
 	"github.com/GoogleCloudPlatform/khi/pkg/log"
+	"github.com/GoogleCloudPlatform/khi/pkg/log/logstore"
 	"github.com/GoogleCloudPlatform/khi/pkg/model/enum"
 	"github.com/GoogleCloudPlatform/khi/pkg/model/history"
-	"github.com/GoogleCloudPlatform/khi/pkg/model/history/grouper"
 	"github.com/GoogleCloudPlatform/khi/pkg/model/history/resourcepath"
 	"github.com/GoogleCloudPlatform/khi/pkg/parser"
 	"github.com/GoogleCloudPlatform/khi/pkg/source/gcp/inspectiontype"
@@ -53,14 +53,10 @@ func (*k8sContainerParser) Dependencies() []taskid.UntypedTaskReference {
 	return []taskid.UntypedTaskReference{}
 }
 
-func (*k8sContainerParser) LogTask() taskid.TaskReference[[]*log.Log] {
+func (*k8sContainerParser) LogTask() taskid.TaskReference[*logstore.Store] {
 	return gke_k8s_container_taskid.GKEContainerLogQueryTaskID.Ref()
 }
 
-func (*k8sContainerParser) Grouper() grouper.LogGrouper {
-	return grouper.NewSingleStringFieldKeyLogGrouper("resource.labels.pod_name")
-}
-
 // Parse implements parser.Parser.
 func (*k8sContainerParser) Parse(ctx context.Context, l *log.Log, cs *history.ChangeSet, builder *history.Builder) error {
 	mainMessageFieldSet := log.MustGetFieldSet(l, &log.MainMessageFieldSet{})
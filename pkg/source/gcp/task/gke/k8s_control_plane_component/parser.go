@@ -18,9 +18,9 @@ import (
 	"context"
 
 	"github.com/GoogleCloudPlatform/khi/pkg/log"
+	"github.com/GoogleCloudPlatform/khi/pkg/log/logstore"
 	"github.com/GoogleCloudPlatform/khi/pkg/model/enum"
 	"github.com/GoogleCloudPlatform/khi/pkg/model/history"
-	"github.com/GoogleCloudPlatform/khi/pkg/model/history/grouper"
 	"github.com/GoogleCloudPlatform/khi/pkg/parser"
 	"github.com/GoogleCloudPlatform/khi/pkg/source/gcp/inspectiontype"
 	"github.com/GoogleCloudPlatform/khi/pkg/source/gcp/task/gke/k8s_control_plane_component/componentparser"
@@ -51,13 +51,8 @@ func (k *k8sControlPlaneComponentParser) GetParserName() string {
 	return `Kubernetes Control plane component logs`
 }
 
-// Grouper implements parser.Parser.
-func (k *k8sControlPlaneComponentParser) Grouper() grouper.LogGrouper {
-	return grouper.NewSingleStringFieldKeyLogGrouper("resource.labels.component_name")
-}
-
 // LogTask implements parser.Parser.
-func (k *k8sControlPlaneComponentParser) LogTask() taskid.TaskReference[[]*log.Log] {
+func (k *k8sControlPlaneComponentParser) LogTask() taskid.TaskReference[*logstore.Store] {
 	return k8s_control_plane_component_taskid.GKEK8sControlPlaneComponentQueryTaskID.Ref()
 }
 
@@ -15,7 +15,7 @@
 package k8s_control_plane_component_taskid
 
 import (
-	"github.com/GoogleCloudPlatform/khi/pkg/log"
+	"github.com/GoogleCloudPlatform/khi/pkg/log/logstore"
 	"github.com/GoogleCloudPlatform/khi/pkg/source/gcp/query"
 	"github.com/GoogleCloudPlatform/khi/pkg/source/gcp/query/queryutil"
 	gcp_task "github.com/GoogleCloudPlatform/khi/pkg/source/gcp/task"
@@ -23,5 +23,5 @@ import (
 )
 
 var InputControlPlaneComponentNameFilterTaskID = taskid.NewDefaultImplementationID[*queryutil.SetFilterParseResult](gcp_task.GCPPrefix + "input/component-names")
-var GKEK8sControlPlaneComponentQueryTaskID = taskid.NewDefaultImplementationID[[]*log.Log](query.GKEQueryPrefix + "k8s-controlplane")
+var GKEK8sControlPlaneComponentQueryTaskID = taskid.NewDefaultImplementationID[*logstore.Store](query.GKEQueryPrefix + "k8s-controlplane")
 var GKEK8sControlPlaneComponentParserTaskID = taskid.NewDefaultImplementationID[struct{}](gcp_task.GCPPrefix + "feature/controlplane-component-parser")
@@ -20,9 +20,9 @@ import (
 	"strings"
 
 	"github.com/GoogleCloudPlatform/khi/pkg/log"
+	"github.com/GoogleCloudPlatform/khi/pkg/log/logstore"
 	"github.com/GoogleCloudPlatform/khi/pkg/model/enum"
 	"github.com/GoogleCloudPlatform/khi/pkg/model/history"
-	"github.com/GoogleCloudPlatform/khi/pkg/model/history/grouper"
 	"github.com/GoogleCloudPlatform/khi/pkg/model/history/resourcepath"
 	"github.com/GoogleCloudPlatform/khi/pkg/parser"
 	"github.com/GoogleCloudPlatform/khi/pkg/source/gcp/inspectiontype"
@@ -54,14 +54,10 @@ func (*k8sEventParser) Dependencies() []taskid.UntypedTaskReference {
 	return []taskid.UntypedTaskReference{}
 }
 
-func (*k8sEventParser) LogTask() taskid.TaskReference[[]*log.Log] {
+func (*k8sEventParser) LogTask() taskid.TaskReference[*logstore.Store] {
 	return k8s_event_taskid.GKEK8sEventLogQueryTaskID.Ref()
 }
 
-func (*k8sEventParser) Grouper() grouper.LogGrouper {
-	return grouper.AllDependentLogGrouper
-}
-
 // Parse implements parser.Parser.
 func (*k8sEventParser) Parse(ctx context.Context, l *log.Log, cs *history.ChangeSet, builder *history.Builder) error {
 	if kind, err := l.ReadString("jsonPayload.kind"); err != nil {
@@ -111,3 +111,39 @@ func TestGenerateK8sEventQueryIsValid(t *testing.T) {
 	}
 
 }
+
+func TestGenerateK8sEventQueryMatchesLogs(t *testing.T) {
+	testCases := []struct {
+		name            string
+		namespaceFilter *queryutil.SetFilterParseResult
+		logFile         string
+		want            bool
+	}{
+		{
+			name:            "event of a node with cluster scoped filter",
+			namespaceFilter: &queryutil.SetFilterParseResult{Additives: []string{"#cluster-scoped"}},
+			logFile:         "test/logs/k8s_event/sample.yaml",
+			want:            true,
+		},
+		{
+			name:            "event of a node with namespaced filter",
+			namespaceFilter: &queryutil.SetFilterParseResult{Additives: []string{"#namespaced"}},
+			logFile:         "test/logs/k8s_event/sample.yaml",
+			want:            false,
+		},
+		{
+			name:            "event exporter log without namespace filter",
+			namespaceFilter: &queryutil.SetFilterParseResult{Additives: []string{"#cluster-scoped", "#namespaced"}},
+			logFile:         "test/logs/k8s_event/cluster-scoped.yaml",
+			want:            true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			query := GenerateK8sEventQuery("gke-basic-1", "project-id", tc.namespaceFilter)
+			if got := gcp_test.MatchLogQueryWithYamlLogFile(t, query, tc.logFile); got != tc.want {
+				t.Errorf("the query matched %s = %v, want %v\n%s", tc.logFile, got, tc.want, query)
+			}
+		})
+	}
+}
@@ -22,9 +22,9 @@ import (
 
 	"github.com/GoogleCloudPlatform/khi/pkg/inspection/logger"
 	"github.com/GoogleCloudPlatform/khi/pkg/log"
+	"github.com/GoogleCloudPlatform/khi/pkg/log/logstore"
 	"github.com/GoogleCloudPlatform/khi/pkg/model/enum"
 	"github.com/GoogleCloudPlatform/khi/pkg/model/history"
-	"github.com/GoogleCloudPlatform/khi/pkg/model/history/grouper"
 	"github.com/GoogleCloudPlatform/khi/pkg/model/history/resourceinfo/noderesource"
 	"github.com/GoogleCloudPlatform/khi/pkg/model/history/resourcepath"
 	"github.com/GoogleCloudPlatform/khi/pkg/parser"
@@ -65,14 +65,10 @@ func (*k8sNodeParser) Dependencies() []taskid.UntypedTaskReference {
 	return []taskid.UntypedTaskReference{}
 }
 
-func (*k8sNodeParser) LogTask() taskid.TaskReference[[]*log.Log] {
+func (*k8sNodeParser) LogTask() taskid.TaskReference[*logstore.Store] {
 	return k8s_node_taskid.GKENodeLogQueryTaskID.Ref()
 }
 
-func (*k8sNodeParser) Grouper() grouper.LogGrouper {
-	return grouper.NewSingleStringFieldKeyLogGrouper("resource.labels.node_name")
-}
-
 func (*k8sNodeParser) GetSyslogIdentifier(l *log.Log) string {
 	syslogIdentiefier := l.ReadStringOrDefault("jsonPayload.SYSLOG_IDENTIFIER", "Unknown")
 	if strings.HasPrefix(syslogIdentiefier, "(") && strings.HasSuffix(syslogIdentiefier, ")") { // dockerd can be "(dockerd)" in SYSLOG_IDENTIFIER field.
@@ -225,7 +221,7 @@ func (p *k8sNodeParser) Parse(ctx context.Context, l *log.Log, cs *history.Chang
 
 	// Add inferred revision at the beginning when parse logics written before is not supporting lifetime visualization
 	if !supportsLifetimeParse {
-		tb := builder.GetTimelineBuilder(nodeComponentPath.Path)
+		tb := builder.GetTimelineBuilder(resourcepath.ClusterScopedPath(l.ClusterName, nodeComponentPath.Path))
 		if tb.GetLatestRevision() == nil {
 			cs.RecordRevision(nodeComponentPath,
 				&history.StagingResourceRevision{
@@ -244,7 +240,7 @@ func (p *k8sNodeParser) Parse(ctx context.Context, l *log.Log, cs *history.Chang
 		cs.RecordEvent(resourcepath.Node(klognode))
 	}
 
-	resourceBindings := builder.ClusterResource.NodeResourceLogBinder.GetBoundResourcesForLogBody(nodeName, mainMessageFieldSet.MainMessage)
+	resourceBindings := builder.ClusterResourceOf(l.ClusterName).NodeResourceLogBinder.GetBoundResourcesForLogBody(nodeName, mainMessageFieldSet.MainMessage)
 	for _, rb := range resourceBindings {
 		cs.RecordEvent(rb.GetResourcePath())
 		summary = rb.RewriteLogSummary(summary)
@@ -327,7 +323,7 @@ func (*k8sNodeParser) handleContainerdSandboxLogs(ctx context.Context, l *log.Lo
 			return err
 		}
 		if podSandbox.PodSandboxID != "" {
-			builder.ClusterResource.NodeResourceLogBinder.AddResourceBinding(nodeName, noderesource.NewPodResourceBinding(
+			builder.ClusterResourceOf(l.ClusterName).NodeResourceLogBinder.AddResourceBinding(nodeName, noderesource.NewPodResourceBinding(
 				podSandbox.PodSandboxID,
 				podSandbox.PodNamespace,
 				podSandbox.PodName,
@@ -350,7 +346,7 @@ func (*k8sNodeParser) handleContainerdSandboxLogs(ctx context.Context, l *log.Lo
 			slog.WarnContext(ctx, fmt.Sprintf("container name is empty for pod sandbox id %s", container.PodSandboxID), logger.LogKind("empty-container-name"))
 			return nil
 		}
-		bindingsForPodSandboxID := builder.ClusterResource.NodeResourceLogBinder.GetBoundResourcesForLogBody(nodeName, container.PodSandboxID)
+		bindingsForPodSandboxID := builder.ClusterResourceOf(l.ClusterName).NodeResourceLogBinder.GetBoundResourcesForLogBody(nodeName, container.PodSandboxID)
 		if len(bindingsForPodSandboxID) == 0 {
 			slog.DebugContext(ctx, fmt.Sprintf("pod sandbox %s was not found. It would be created before the log query start time", container.PodSandboxID), logger.LogKind("pod-sandbox-not-found"))
 			return nil
@@ -363,7 +359,7 @@ func (*k8sNodeParser) handleContainerdSandboxLogs(ctx context.Context, l *log.Lo
 			return fmt.Errorf("pod sandbox ID %s is not associated with a PodResourceBinding reference. %v was given", container.PodSandboxID, bindingsForPodSandboxID[0])
 		}
 		containerResourceBinding := podResourceBinding.NewContainerResourceBinding(container.ContainerID, container.ContainerName)
-		builder.ClusterResource.NodeResourceLogBinder.AddResourceBinding(nodeName, containerResourceBinding)
+		builder.ClusterResourceOf(l.ClusterName).NodeResourceLogBinder.AddResourceBinding(nodeName, containerResourceBinding)
 		return nil
 	}
 	return nil
@@ -89,3 +89,45 @@ func TestGenerateNodeNameSubstringLogFilter(t *testing.T) {
 		})
 	}
 }
+
+func TestGenerateK8sNodeQueryMatchesLogs(t *testing.T) {
+	testCases := []struct {
+		name               string
+		clusterName        string
+		nodeNameSubstrings []string
+		want               bool
+	}{
+		{
+			name:               "without node name filter",
+			clusterName:        "sample-cluster",
+			nodeNameSubstrings: []string{},
+			want:               true,
+		},
+		{
+			name:               "with matching node name filter",
+			clusterName:        "sample-cluster",
+			nodeNameSubstrings: []string{"default-abcdefgh"},
+			want:               true,
+		},
+		{
+			name:               "with other node name filter",
+			clusterName:        "sample-cluster",
+			nodeNameSubstrings: []string{"other-pool"},
+			want:               false,
+		},
+		{
+			name:               "with other cluster",
+			clusterName:        "other-cluster",
+			nodeNameSubstrings: []string{},
+			want:               false,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			query := GenerateK8sNodeLogQuery("sample-project", tc.clusterName, tc.nodeNameSubstrings)
+			if got := gcp_test.MatchLogQueryWithYamlLogFile(t, query, "test/logs/k8s_node/containerd_create_container.yaml"); got != tc.want {
+				t.Errorf("the query matched the log = %v, want %v\n%s", got, tc.want, query)
+			}
+		})
+	}
+}
@@ -22,9 +22,9 @@ import (
 
 	"github.com/GoogleCloudPlatform/khi/pkg/common/structurev2"
 	"github.com/GoogleCloudPlatform/khi/pkg/log"
+	"github.com/GoogleCloudPlatform/khi/pkg/log/logstore"
 	"github.com/GoogleCloudPlatform/khi/pkg/model/enum"
 	"github.com/GoogleCloudPlatform/khi/pkg/model/history"
-	"github.com/GoogleCloudPlatform/khi/pkg/model/history/grouper"
 	"github.com/GoogleCloudPlatform/khi/pkg/model/history/resourcepath"
 	"github.com/GoogleCloudPlatform/khi/pkg/parser"
 	"github.com/GoogleCloudPlatform/khi/pkg/source/gcp/inspectiontype"
@@ -56,14 +56,10 @@ func (*gceNetworkParser) GetParserName() string {
 }
 
 // LogTask implements parser.Parser.
-func (*gceNetworkParser) LogTask() taskid.TaskReference[[]*log.Log] {
+func (*gceNetworkParser) LogTask() taskid.TaskReference[*logstore.Store] {
 	return network_api_taskid.GCPNetworkLogQueryTaskID.Ref()
 }
 
-func (*gceNetworkParser) Grouper() grouper.LogGrouper {
-	return grouper.AllDependentLogGrouper
-}
-
 // Parse implements parser.Parser.
 func (*gceNetworkParser) Parse(ctx context.Context, l *log.Log, cs *history.ChangeSet, builder *history.Builder) error {
 	commonFieldSet := log.MustGetFieldSet(l, &log.CommonFieldSet{})
@@ -77,7 +73,7 @@ func (*gceNetworkParser) Parse(ctx context.Context, l *log.Log, cs *history.Chan
 	negName := resourceNameSplitted[len(resourceNameSplitted)-1]
 	principal := l.ReadStringOrDefault("protoPayload.authenticationInfo.principalEmail", "unknown")
 	var negResourcePath resourcepath.ResourcePath
-	lease, err := builder.ClusterResource.NEGs.GetResourceLeaseHolderAt(negName, commonFieldSet.Timestamp)
+	lease, err := builder.ClusterResourceOf(l.ClusterName).NEGs.GetResourceLeaseHolderAt(negName, commonFieldSet.Timestamp)
 	if err == nil {
 		negResourcePath = resourcepath.NetworkEndpointGroup(lease.Holder.Namespace, negName)
 	} else {
@@ -115,7 +111,7 @@ func (*gceNetworkParser) Parse(ctx context.Context, l *log.Log, cs *history.Chan
 				return err
 			}
 			for _, endpoint := range negRequest.NetworkEndpoints {
-				lease, err := builder.ClusterResource.IPs.GetResourceLeaseHolderAt(endpoint.IpAddress, commonFieldSet.Timestamp)
+				lease, err := builder.ClusterResourceOf(l.ClusterName).IPs.GetResourceLeaseHolderAt(endpoint.IpAddress, commonFieldSet.Timestamp)
 				if err != nil {
 					slog.WarnContext(ctx, fmt.Sprintf("Failed to identify the holder of the IP %s.\n This might be because the IP holder resource wasn't updated during the log period ", endpoint.IpAddress))
 					continue
@@ -19,9 +19,9 @@ import (
 
 	"github.com/GoogleCloudPlatform/khi/pkg/common/parserutil"
 	"github.com/GoogleCloudPlatform/khi/pkg/log"
+	"github.com/GoogleCloudPlatform/khi/pkg/log/logstore"
 	"github.com/GoogleCloudPlatform/khi/pkg/model/enum"
 	"github.com/GoogleCloudPlatform/khi/pkg/model/history"
-	"github.com/GoogleCloudPlatform/khi/pkg/model/history/grouper"
 	"github.com/GoogleCloudPlatform/khi/pkg/model/history/resourcepath"
 	"github.com/GoogleCloudPlatform/khi/pkg/parser"
 	serialport_taskid "github.com/GoogleCloudPlatform/khi/pkg/source/gcp/task/gke/serialport/taskid"
@@ -60,14 +60,10 @@ func (*SerialPortLogParser) Dependencies() []taskid.UntypedTaskReference {
 	return []taskid.UntypedTaskReference{}
 }
 
-func (*SerialPortLogParser) LogTask() taskid.TaskReference[[]*log.Log] {
+func (*SerialPortLogParser) LogTask() taskid.TaskReference[*logstore.Store] {
 	return serialport_taskid.SerialPortLogQueryTaskID.Ref()
 }
 
-func (*SerialPortLogParser) Grouper() grouper.LogGrouper {
-	return grouper.NewSingleStringFieldKeyLogGrouper("resource.labels.instance_id")
-}
-
 // Parse implements parser.Parser.
 func (*SerialPortLogParser) Parse(ctx context.Context, l *log.Log, cs *history.ChangeSet, builder *history.Builder) error {
 	nodeName := l.ReadStringOrDefault("labels.compute\\.googleapis\\.com/resource_name", "unknown")
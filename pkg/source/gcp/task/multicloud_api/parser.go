@@ -22,9 +22,9 @@ import (
 
 	"github.com/GoogleCloudPlatform/khi/pkg/common/structurev2"
 	"github.com/GoogleCloudPlatform/khi/pkg/log"
+	"github.com/GoogleCloudPlatform/khi/pkg/log/logstore"
 	"github.com/GoogleCloudPlatform/khi/pkg/model/enum"
 	"github.com/GoogleCloudPlatform/khi/pkg/model/history"
-	"github.com/GoogleCloudPlatform/khi/pkg/model/history/grouper"
 	"github.com/GoogleCloudPlatform/khi/pkg/model/history/resourcepath"
 	"github.com/GoogleCloudPlatform/khi/pkg/parser"
 	"github.com/GoogleCloudPlatform/khi/pkg/source/gcp/inspectiontype"
@@ -56,14 +56,10 @@ func (*multiCloudAuditLogParser) GetParserName() string {
 }
 
 // LogTask implements parser.Parser.
-func (*multiCloudAuditLogParser) LogTask() taskid.TaskReference[[]*log.Log] {
+func (*multiCloudAuditLogParser) LogTask() taskid.TaskReference[*logstore.Store] {
 	return multicloud_api_taskid.MultiCloudAPIQueryTaskID.Ref()
 }
 
-func (*multiCloudAuditLogParser) Grouper() grouper.LogGrouper {
-	return grouper.AllDependentLogGrouper
-}
-
 // Parse implements parser.Parser.
 func (*multiCloudAuditLogParser) Parse(ctx context.Context, l *log.Log, cs *history.ChangeSet, builder *history.Builder) error {
 	resourceName := l.ReadStringOrDefault("protoPayload.resourceName", "")
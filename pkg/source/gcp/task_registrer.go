@@ -16,6 +16,7 @@ package gcp
 
 import (
 	"github.com/GoogleCloudPlatform/khi/pkg/inspection"
+	"github.com/GoogleCloudPlatform/khi/pkg/source/gcp/query"
 	"github.com/GoogleCloudPlatform/khi/pkg/source/gcp/task"
 	composer_task "github.com/GoogleCloudPlatform/khi/pkg/source/gcp/task/cloud-composer"
 	composer_form "github.com/GoogleCloudPlatform/khi/pkg/source/gcp/task/cloud-composer/form"
@@ -25,6 +26,7 @@ import (
 	baremetal "github.com/GoogleCloudPlatform/khi/pkg/source/gcp/task/gdcv-for-baremetal"
 	vmware "github.com/GoogleCloudPlatform/khi/pkg/source/gcp/task/gdcv-for-vmware"
 	"github.com/GoogleCloudPlatform/khi/pkg/source/gcp/task/gke"
+	logexport "github.com/GoogleCloudPlatform/khi/pkg/source/gcp/task/gke-logging-export"
 	aws "github.com/GoogleCloudPlatform/khi/pkg/source/gcp/task/gke-on-aws"
 	azure "github.com/GoogleCloudPlatform/khi/pkg/source/gcp/task/gke-on-azure"
 	"github.com/GoogleCloudPlatform/khi/pkg/source/gcp/task/gke/autoscaler"
@@ -63,6 +65,11 @@ func commonPreparation(inspectionServer *inspection.InspectionTaskServer) error
 		return err
 	}
 
+	err = inspectionServer.AddTask(logexport.AutocompleteClusterNames)
+	if err != nil {
+		return err
+	}
+
 	err = inspectionServer.AddTask(azure.AutocompleteClusterNames)
 	if err != nil {
 		return err
@@ -96,6 +103,10 @@ func commonPreparation(inspectionServer *inspection.InspectionTaskServer) error
 	if err != nil {
 		return err
 	}
+	err = inspectionServer.AddTask(task.ClusterNamesTask)
+	if err != nil {
+		return err
+	}
 	err = inspectionServer.AddTask(task.InputDurationTask)
 	if err != nil {
 		return err
@@ -144,6 +155,21 @@ func commonPreparation(inspectionServer *inspection.InspectionTaskServer) error
 	if err != nil {
 		return err
 	}
+
+	err = inspectionServer.AddTask(query.CloudLoggingAPILogEntryListerTask)
+	if err != nil {
+		return err
+	}
+
+	err = inspectionServer.AddTask(logexport.LogEntryFilesForm)
+	if err != nil {
+		return err
+	}
+
+	err = inspectionServer.AddTask(logexport.LocalLogEntryListerTask)
+	if err != nil {
+		return err
+	}
 	err = inspectionServer.AddTask(k8sauditquery.Task)
 	if err != nil {
 		return err
@@ -248,6 +274,11 @@ func commonPreparation(inspectionServer *inspection.InspectionTaskServer) error
 	if err != nil {
 		return err
 	}
+
+	err = inspectionServer.AddTask(logexport.GKELoggingExportClusterNamePrefixTask)
+	if err != nil {
+		return err
+	}
 	err = inspectionServer.AddTask(aws.AnthosOnAWSClusterNamePrefixTask)
 	if err != nil {
 		return err
@@ -270,6 +301,11 @@ func commonPreparation(inspectionServer *inspection.InspectionTaskServer) error
 	if err != nil {
 		return err
 	}
+
+	err = inspectionServer.AddInspectionType(logexport.GKELoggingExportInspectionType)
+	if err != nil {
+		return err
+	}
 	err = inspectionServer.AddInspectionType(aws.AnthosOnAWSInspectionType)
 	if err != nil {
 		return err
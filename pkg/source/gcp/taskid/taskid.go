@@ -15,8 +15,13 @@
 package gcp_taskid
 
 import (
+	"github.com/GoogleCloudPlatform/khi/pkg/source/gcp/api"
 	gcp_types "github.com/GoogleCloudPlatform/khi/pkg/source/gcp/types"
 	"github.com/GoogleCloudPlatform/khi/pkg/task/taskid"
 )
 
 var LoggingFilterResourceNameInputTaskID = taskid.NewDefaultImplementationID[*gcp_types.ResourceNamesInput]("logging-filter-resource-name-input")
+
+// LogEntryListerTaskID is the reference to the task returning the LogEntryLister used in query tasks.
+// Its implementation is chosen by the inspection type to read logs from the Cloud Logging API or from exported files.
+var LogEntryListerTaskID = taskid.NewTaskReference[api.LogEntryLister]("cloud.google.com/log-entry-lister")
@@ -23,5 +23,6 @@ import (
 var AuditLogFilesForm = form.NewFileFormTaskBuilder(oss_taskid.OSSAPIServerAuditLogFileInputTask, 1000, "Audit Log Files", &upload.JSONLineUploadFileVerifier{
 	MaxLineSizeInBytes: 1024 * 1024 * 1024,
 }).
-	WithDescription(`Upload JSONLine format kube-apiserver audit log`).
+	WithDescription(`Upload JSONLine format kube-apiserver audit log. Rotated logs can be uploaded together as multiple files or as a gzip, zstd, tar or zip archive.`).
+	WithMultipleFiles().
 	Build()
@@ -0,0 +1,36 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package form
+
+import (
+	"github.com/GoogleCloudPlatform/khi/pkg/inspection/form"
+	"github.com/GoogleCloudPlatform/khi/pkg/server/upload"
+	oss_parser "github.com/GoogleCloudPlatform/khi/pkg/source/oss/parser"
+	oss_taskid "github.com/GoogleCloudPlatform/khi/pkg/source/oss/taskid"
+)
+
+var K8sEventFilesForm = form.NewFileFormTaskBuilder(oss_taskid.OSSK8sEventFileInputTask, 2000, "Kubernetes Event Files", &upload.JSONDocumentUploadFileVerifier{}).
+	WithDescription("Upload the output of `kubectl get events -A -o json`. Multiple dumps can be uploaded together as multiple files or as a gzip, zstd, tar or zip archive.").
+	Build()
+
+var NodeLogFilesForm = form.NewFileFormTaskBuilder(oss_taskid.OSSNodeLogFileInputTask, 3000, "Node Journal Files", &upload.JSONLineUploadFileVerifier{
+	MaxLineSizeInBytes: 1024 * 1024 * 1024,
+}).
+	WithDescription("Upload journald logs of kubelet and container runtime exported with `journalctl -o json -u kubelet -u containerd` on each node. Files from multiple nodes can be uploaded together as multiple files or as a gzip, zstd, tar or zip archive.").
+	Build()
+
+var ContainerLogFilesForm = form.NewFileFormTaskBuilder(oss_taskid.OSSContainerLogFileInputTask, 4000, "Container Log Files", &oss_parser.ContainerLogUploadFileVerifier{}).
+	WithDescription("Upload container log files under `/var/log/pods` of nodes as a tar or zip archive keeping the directory structure. (Example: `tar czf pods.tar.gz /var/log/pods`) Namespace, Pod and container names are read from the file paths.").
+	Build()
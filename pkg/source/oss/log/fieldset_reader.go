@@ -16,6 +16,8 @@ package log
 
 import (
 	"fmt"
+	"strconv"
+	"time"
 
 	"github.com/GoogleCloudPlatform/khi/pkg/common/structurev2"
 	"github.com/GoogleCloudPlatform/khi/pkg/log"
@@ -43,3 +45,145 @@ func (o *OSSK8sAuditLogCommonFieldSetReader) Read(reader *structurev2.NodeReader
 }
 
 var _ log.FieldSetReader = (*OSSK8sAuditLogCommonFieldSetReader)(nil)
+
+// OSSK8sEventCommonFieldSetReader reads CommonFieldSet from an Event resource dumped with `kubectl get events -o json`.
+type OSSK8sEventCommonFieldSetReader struct{}
+
+func (o *OSSK8sEventCommonFieldSetReader) FieldSetKind() string {
+	return (&log.CommonFieldSet{}).Kind()
+}
+
+func (o *OSSK8sEventCommonFieldSetReader) Read(reader *structurev2.NodeReader) (log.FieldSet, error) {
+	result := &log.CommonFieldSet{}
+	result.DisplayID = reader.ReadStringOrDefault("metadata.uid", "unknown")
+	// eventTime is only set by the events.k8s.io API, lastTimestamp and firstTimestamp are only set by the core/v1 API.
+	timestampFields := []string{"lastTimestamp", "eventTime", "deprecatedLastTimestamp", "firstTimestamp", "metadata.creationTimestamp"}
+	found := false
+	for _, field := range timestampFields {
+		timestamp, err := reader.ReadTimestamp(field)
+		if err == nil && !timestamp.IsZero() {
+			result.Timestamp = timestamp
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("failed to read timestamp from given event")
+	}
+	switch reader.ReadStringOrDefault("type", "") {
+	case "Normal":
+		result.Severity = enum.SeverityInfo
+	case "Warning":
+		result.Severity = enum.SeverityWarning
+	default:
+		result.Severity = enum.SeverityUnknown
+	}
+	return result, nil
+}
+
+var _ log.FieldSetReader = (*OSSK8sEventCommonFieldSetReader)(nil)
+
+// OSSK8sEventMainMessageFieldSetReader reads MainMessageFieldSet from an Event resource dumped with `kubectl get events -o json`.
+type OSSK8sEventMainMessageFieldSetReader struct{}
+
+func (o *OSSK8sEventMainMessageFieldSetReader) FieldSetKind() string {
+	return (&log.MainMessageFieldSet{}).Kind()
+}
+
+func (o *OSSK8sEventMainMessageFieldSetReader) Read(reader *structurev2.NodeReader) (log.FieldSet, error) {
+	return &log.MainMessageFieldSet{
+		MainMessage: reader.ReadStringOrDefault("message", reader.ReadStringOrDefault("note", "")),
+	}, nil
+}
+
+var _ log.FieldSetReader = (*OSSK8sEventMainMessageFieldSetReader)(nil)
+
+// OSSJournalCommonFieldSetReader reads CommonFieldSet from a journald log exported with `journalctl -o json`.
+type OSSJournalCommonFieldSetReader struct{}
+
+func (o *OSSJournalCommonFieldSetReader) FieldSetKind() string {
+	return (&log.CommonFieldSet{}).Kind()
+}
+
+func (o *OSSJournalCommonFieldSetReader) Read(reader *structurev2.NodeReader) (log.FieldSet, error) {
+	result := &log.CommonFieldSet{}
+	result.DisplayID = reader.ReadStringOrDefault("__CURSOR", "unknown")
+	// journalctl writes every field as string. __REALTIME_TIMESTAMP is the microseconds since the epoch.
+	realtime, err := strconv.ParseInt(reader.ReadStringOrDefault("__REALTIME_TIMESTAMP", ""), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read __REALTIME_TIMESTAMP from given log")
+	}
+	result.Timestamp = time.UnixMicro(realtime).UTC()
+	result.Severity = journalPriorityToSeverity(reader.ReadStringOrDefault("PRIORITY", ""))
+	return result, nil
+}
+
+var _ log.FieldSetReader = (*OSSJournalCommonFieldSetReader)(nil)
+
+// journalPriorityToSeverity converts the syslog priority level used in journald to the Severity.
+func journalPriorityToSeverity(priority string) enum.Severity {
+	switch priority {
+	case "0", "1", "2":
+		return enum.SeverityFatal
+	case "3":
+		return enum.SeverityError
+	case "4":
+		return enum.SeverityWarning
+	case "5", "6", "7":
+		return enum.SeverityInfo
+	default:
+		return enum.SeverityUnknown
+	}
+}
+
+// OSSJournalMainMessageFieldSetReader reads MainMessageFieldSet from a journald log exported with `journalctl -o json`.
+type OSSJournalMainMessageFieldSetReader struct{}
+
+func (o *OSSJournalMainMessageFieldSetReader) FieldSetKind() string {
+	return (&log.MainMessageFieldSet{}).Kind()
+}
+
+func (o *OSSJournalMainMessageFieldSetReader) Read(reader *structurev2.NodeReader) (log.FieldSet, error) {
+	// MESSAGE can be an array of bytes when the message contains non-printable characters. These messages are regarded as empty.
+	return &log.MainMessageFieldSet{
+		MainMessage: reader.ReadStringOrDefault("MESSAGE", ""),
+	}, nil
+}
+
+var _ log.FieldSetReader = (*OSSJournalMainMessageFieldSetReader)(nil)
+
+// OSSContainerLogCommonFieldSetReader reads CommonFieldSet from a container log line read from a file under /var/log/pods.
+type OSSContainerLogCommonFieldSetReader struct{}
+
+func (o *OSSContainerLogCommonFieldSetReader) FieldSetKind() string {
+	return (&log.CommonFieldSet{}).Kind()
+}
+
+func (o *OSSContainerLogCommonFieldSetReader) Read(reader *structurev2.NodeReader) (log.FieldSet, error) {
+	var err error
+	result := &log.CommonFieldSet{}
+	result.DisplayID = reader.ReadStringOrDefault("file", "unknown")
+	result.Timestamp, err = reader.ReadTimestamp("time")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read timestamp from given log")
+	}
+	result.Severity = enum.SeverityUnknown
+	return result, nil
+}
+
+var _ log.FieldSetReader = (*OSSContainerLogCommonFieldSetReader)(nil)
+
+// OSSContainerLogMainMessageFieldSetReader reads MainMessageFieldSet from a container log line read from a file under /var/log/pods.
+type OSSContainerLogMainMessageFieldSetReader struct{}
+
+func (o *OSSContainerLogMainMessageFieldSetReader) FieldSetKind() string {
+	return (&log.MainMessageFieldSet{}).Kind()
+}
+
+func (o *OSSContainerLogMainMessageFieldSetReader) Read(reader *structurev2.NodeReader) (log.FieldSet, error) {
+	return &log.MainMessageFieldSet{
+		MainMessage: reader.ReadStringOrDefault("message", ""),
+	}, nil
+}
+
+var _ log.FieldSetReader = (*OSSContainerLogMainMessageFieldSetReader)(nil)
@@ -44,7 +44,11 @@ var OSSK8sAuditLogSourceTask = inspection_task.NewInspectionTask(oss_taskid.OSSK
 	if taskMode == inspection_task_interface.TaskModeDryRun {
 		return nil, nil
 	}
-	logs := task.GetTaskResult(ctx, oss_taskid.OSSAPIServerAuditLogFilterAuditTaskID.Ref())
+	store := task.GetTaskResult(ctx, oss_taskid.OSSAPIServerAuditLogFilterAuditTaskID.Ref())
+	logs, err := store.ReadAll()
+	if err != nil {
+		return nil, err
+	}
 
 	return &types.AuditLogParserLogSource{
 		Logs:      logs,
@@ -0,0 +1,294 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parser
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/common/structurev2"
+	inspection_task_interface "github.com/GoogleCloudPlatform/khi/pkg/inspection/interface"
+	"github.com/GoogleCloudPlatform/khi/pkg/inspection/ioconfig"
+	"github.com/GoogleCloudPlatform/khi/pkg/inspection/metadata/progress"
+	inspection_task "github.com/GoogleCloudPlatform/khi/pkg/inspection/task"
+	"github.com/GoogleCloudPlatform/khi/pkg/log"
+	"github.com/GoogleCloudPlatform/khi/pkg/log/logstore"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/enum"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/history"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/history/grouper"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/history/resourcepath"
+	"github.com/GoogleCloudPlatform/khi/pkg/parser"
+	"github.com/GoogleCloudPlatform/khi/pkg/server/upload"
+	oss_constant "github.com/GoogleCloudPlatform/khi/pkg/source/oss/constant"
+	oss_log "github.com/GoogleCloudPlatform/khi/pkg/source/oss/log"
+	oss_taskid "github.com/GoogleCloudPlatform/khi/pkg/source/oss/taskid"
+	"github.com/GoogleCloudPlatform/khi/pkg/task"
+	"github.com/GoogleCloudPlatform/khi/pkg/task/taskid"
+)
+
+// OSSContainerLogFileReader reads container logs from files under /var/log/pods in the uploaded archive.
+var OSSContainerLogFileReader = inspection_task.NewProgressReportableInspectionTask(
+	oss_taskid.OSSContainerLogFileReader,
+	[]taskid.UntypedTaskReference{
+		ioconfig.IOConfigTaskID.Ref(),
+		oss_taskid.OSSContainerLogFileInputTask.Ref(),
+	},
+	func(ctx context.Context, taskMode inspection_task_interface.InspectionTaskMode, tp *progress.TaskProgress) (*logstore.Store, error) {
+		if taskMode == inspection_task_interface.TaskModeDryRun {
+			return nil, nil
+		}
+		ioConfig := task.GetTaskResult(ctx, ioconfig.IOConfigTaskID.Ref())
+		result := task.GetTaskResult(ctx, oss_taskid.OSSContainerLogFileInputTask.Ref())
+		store := newContainerLogStore(ioConfig.TemporaryFolder)
+		err := readUploadedLogFile(ctx, tp, result, store, readContainerLogs)
+		if err != nil {
+			store.Close()
+			return nil, err
+		}
+		return store, nil
+	},
+)
+
+// containerLogFile is the container identified from the path of a container log file.
+type containerLogFile struct {
+	Namespace string
+	Pod       string
+	Container string
+}
+
+// parseContainerLogFilePath identifies the container from the path of log file.
+// It supports `<namespace>_<pod>_<pod uid>/<container>/<restart count>.log` used in /var/log/pods and
+// `<pod>_<namespace>_<container>-<container id>.log` used in /var/log/containers.
+func parseContainerLogFilePath(filePath string) (*containerLogFile, error) {
+	segments := strings.Split(filePath, "/")
+	if len(segments) >= 3 {
+		podSegments := strings.Split(segments[len(segments)-3], "_")
+		if len(podSegments) == 3 && strings.Contains(segments[len(segments)-1], ".log") {
+			return &containerLogFile{
+				Namespace: podSegments[0],
+				Pod:       podSegments[1],
+				Container: segments[len(segments)-2],
+			}, nil
+		}
+	}
+	fileName := strings.TrimSuffix(path.Base(filePath), ".log")
+	fileSegments := strings.Split(fileName, "_")
+	if len(fileSegments) == 3 {
+		lastHyphen := strings.LastIndex(fileSegments[2], "-")
+		if lastHyphen > 0 {
+			return &containerLogFile{
+				Namespace: fileSegments[1],
+				Pod:       fileSegments[0],
+				Container: fileSegments[2][:lastHyphen],
+			}, nil
+		}
+	}
+	return nil, fmt.Errorf("failed to identify the container from the file path %s. container log files must be uploaded in an archive keeping the directory structure of /var/log/pods", filePath)
+}
+
+// containerLogLine is a line of container log file.
+type containerLogLine struct {
+	Time    string
+	Stream  string
+	Partial bool
+	Message string
+}
+
+// parseContainerLogLine parses a line written by the container runtime in the CRI logging format or the docker json-file format.
+func parseContainerLogLine(line []byte) (*containerLogLine, error) {
+	if bytes.HasPrefix(line, []byte("{")) {
+		var dockerLine struct {
+			Log    string `json:"log"`
+			Stream string `json:"stream"`
+			Time   string `json:"time"`
+		}
+		if err := json.Unmarshal(line, &dockerLine); err != nil {
+			return nil, err
+		}
+		return &containerLogLine{
+			Time:    dockerLine.Time,
+			Stream:  dockerLine.Stream,
+			Partial: !strings.HasSuffix(dockerLine.Log, "\n"),
+			Message: strings.TrimSuffix(dockerLine.Log, "\n"),
+		}, nil
+	}
+	// CRI logging format: `<RFC3339Nano timestamp> <stdout|stderr> <P|F> <message>`
+	fields := strings.SplitN(string(line), " ", 4)
+	if len(fields) < 3 || (fields[2] != "P" && fields[2] != "F") {
+		return nil, fmt.Errorf("unsupported container log format")
+	}
+	message := ""
+	if len(fields) == 4 {
+		message = fields[3]
+	}
+	return &containerLogLine{
+		Time:    fields[0],
+		Stream:  fields[1],
+		Partial: fields[2] == "P",
+		Message: message,
+	}, nil
+}
+
+// newContainerLogStore returns an empty store for container logs grouped by the pod.
+func newContainerLogStore(folder string) *logstore.Store {
+	return logstore.NewStore(folder, grouper.NewSingleStringFieldKeyLogGrouper("pod"), &oss_log.OSSContainerLogCommonFieldSetReader{}, &oss_log.OSSContainerLogMainMessageFieldSetReader{})
+}
+
+// readContainerLogs reads container log files in the uploaded file into the store. Partial lines split by the container runtime are joined into a log.
+func readContainerLogs(ctx context.Context, reader io.Reader, name string, store *logstore.Store) error {
+	return walkContainerLogs(ctx, reader, name, store.Append)
+}
+
+// walkContainerLogs calls onLog with each log read from the container log files in the uploaded file.
+func walkContainerLogs(ctx context.Context, reader io.Reader, name string, onLog func(l *log.Log) error) error {
+	return upload.WalkUploadedMembers(reader, name, func(memberName string, memberReader io.Reader) error {
+		container, err := parseContainerLogFilePath(memberName)
+		if err != nil {
+			return err
+		}
+		lineReader := bufio.NewReaderSize(memberReader, logLineReaderBufferSize)
+		var partialMessage strings.Builder
+		for lineNumber := 1; ; lineNumber++ {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			line, readErr := lineReader.ReadBytes('\n')
+			if readErr != nil && readErr != io.EOF {
+				return readErr
+			}
+			line = bytes.TrimRight(line, "\r\n")
+			if len(line) > 0 {
+				parsed, err := parseContainerLogLine(line)
+				if err != nil {
+					return fmt.Errorf("failed to read a log at line %d in %s: %w", lineNumber, path.Base(memberName), err)
+				}
+				partialMessage.WriteString(parsed.Message)
+				if !parsed.Partial {
+					l, err := newContainerLog(container, parsed, partialMessage.String(), fmt.Sprintf("%s:%d", memberName, lineNumber))
+					if err != nil {
+						return fmt.Errorf("failed to read a log at line %d in %s: %w", lineNumber, path.Base(memberName), err)
+					}
+					if err := onLog(l); err != nil {
+						return err
+					}
+					partialMessage.Reset()
+				}
+			}
+			if readErr == io.EOF {
+				return nil
+			}
+		}
+	})
+}
+
+// ContainerLogUploadFileVerifier verifies every file in the uploaded archive is a container log file readable with OSSContainerLogFileReader.
+type ContainerLogUploadFileVerifier struct {
+}
+
+// Verify implements upload.UploadFileVerifier.
+func (c *ContainerLogUploadFileVerifier) Verify(storeProvider upload.UploadFileStoreProvider, token upload.UploadToken) error {
+	reader, err := storeProvider.Read(token)
+	if err != nil {
+		return fmt.Errorf("failed to read the uploded file")
+	}
+	defer reader.Close()
+
+	return walkContainerLogs(context.Background(), reader, token.GetID(), func(l *log.Log) error { return nil })
+}
+
+var _ upload.UploadFileVerifier = &ContainerLogUploadFileVerifier{}
+
+func newContainerLog(container *containerLogFile, line *containerLogLine, message string, position string) (*log.Log, error) {
+	node, err := structurev2.FromGoValue(map[string]any{
+		"namespace": container.Namespace,
+		"pod":       container.Pod,
+		"container": container.Container,
+		"stream":    line.Stream,
+		"time":      line.Time,
+		"message":   message,
+		"file":      position,
+	}, &structurev2.AlphabeticalGoMapKeyOrderProvider{})
+	if err != nil {
+		return nil, err
+	}
+	l := log.NewLog(structurev2.NewNodeReader(node))
+	l.LogType = enum.LogTypeContainer
+	err = l.SetFieldSetReader(&oss_log.OSSContainerLogCommonFieldSetReader{})
+	if err != nil {
+		return nil, err
+	}
+	err = l.SetFieldSetReader(&oss_log.OSSContainerLogMainMessageFieldSetReader{})
+	if err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// OSSContainerLogParser generates events on containers from container log files.
+type OSSContainerLogParser struct {
+}
+
+func (o *OSSContainerLogParser) Dependencies() []taskid.UntypedTaskReference {
+	return []taskid.UntypedTaskReference{}
+}
+
+func (o *OSSContainerLogParser) Description() string {
+	return `Gather stdout/stderr logs of containers from the uploaded files under /var/log/pods to visualize them on the timeline under an associated Pod.`
+}
+
+func (o *OSSContainerLogParser) GetParserName() string {
+	return "OSS Kubernetes container logs"
+}
+
+func (o *OSSContainerLogParser) LogTask() taskid.TaskReference[*logstore.Store] {
+	return oss_taskid.OSSContainerLogFileReader.Ref()
+}
+
+func (o *OSSContainerLogParser) Parse(ctx context.Context, l *log.Log, cs *history.ChangeSet, builder *history.Builder) error {
+	mainMessageFieldSet := log.MustGetFieldSet(l, &log.MainMessageFieldSet{})
+	namespace := l.ReadStringOrDefault("namespace", "unknown")
+	podName := l.ReadStringOrDefault("pod", "unknown")
+	containerName := l.ReadStringOrDefault("container", "unknown")
+
+	mainMessage := mainMessageFieldSet.MainMessage
+	if mainMessage == "" {
+		mainMessage = "(empty)"
+	}
+	cs.RecordEvent(resourcepath.Container(namespace, podName, containerName))
+	cs.RecordLogSummary(mainMessage)
+	if severity := mainMessageFieldSet.KLogSeverity(); severity != enum.SeverityUnknown {
+		cs.RecordLogSeverity(severity)
+	}
+	return nil
+}
+
+func (o *OSSContainerLogParser) TargetLogType() enum.LogType {
+	return enum.LogTypeContainer
+}
+
+var _ parser.Parser = (*OSSContainerLogParser)(nil)
+
+var OSSContainerLogParserTask = parser.NewParserTaskFromParser(
+	oss_taskid.OSSContainerLogParserTaskID,
+	&OSSContainerLogParser{}, false, []string{
+		oss_constant.OSSInspectionTypeID,
+	},
+)
@@ -0,0 +1,198 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parser
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/log"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/history"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/history/resourcepath"
+	"github.com/GoogleCloudPlatform/khi/pkg/server/upload"
+	"github.com/google/go-cmp/cmp"
+
+	_ "github.com/GoogleCloudPlatform/khi/internal/testflags"
+)
+
+// mustTarWithPaths returns a tar archive containing files at the given paths in the alphabetical order of paths.
+func mustTarWithPaths(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	paths := slices.Sorted(maps.Keys(files))
+	var buf bytes.Buffer
+	writer := tar.NewWriter(&buf)
+	for _, filePath := range paths {
+		err := writer.WriteHeader(&tar.Header{Name: filePath, Mode: 0644, Size: int64(len(files[filePath])), Typeflag: tar.TypeReg})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := writer.Write([]byte(files[filePath])); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestParseContainerLogFilePath(t *testing.T) {
+	testCases := []struct {
+		path    string
+		want    *containerLogFile
+		wantErr bool
+	}{
+		{
+			path: "token/var/log/pods/kube-system_coredns-abcde_0123-4567/coredns/0.log",
+			want: &containerLogFile{Namespace: "kube-system", Pod: "coredns-abcde", Container: "coredns"},
+		},
+		{
+			path: "token/var/log/pods/default_nginx_0123-4567/nginx/1.log.20250101-000000",
+			want: &containerLogFile{Namespace: "default", Pod: "nginx", Container: "nginx"},
+		},
+		{
+			path: "var/log/containers/nginx_default_nginx-sidecar-0123456789abcdef.log",
+			want: &containerLogFile{Namespace: "default", Pod: "nginx", Container: "nginx-sidecar"},
+		},
+		{
+			path:    "token",
+			wantErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.path, func(t *testing.T) {
+			got, err := parseContainerLogFilePath(tc.path)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("parseContainerLogFilePath(%q) error = %v, wantErr %v", tc.path, err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("parseContainerLogFilePath(%q) mismatch (-want +got):\n%s", tc.path, diff)
+			}
+		})
+	}
+}
+
+func TestReadContainerLogs(t *testing.T) {
+	criLog := strings.Join([]string{
+		"2025-01-01T00:00:02.000000000Z stdout F second",
+		"2025-01-01T00:00:00.000000000Z stderr P split ",
+		"2025-01-01T00:00:00.100000000Z stderr F line",
+		"2025-01-01T00:00:03.000000000Z stdout F",
+	}, "\n")
+	dockerLog := `{"log":"from docker\n","stream":"stdout","time":"2025-01-01T00:00:01Z"}`
+	data := mustTarWithPaths(t, map[string]string{
+		"var/log/pods/default_nginx_uid/nginx/0.log":   criLog,
+		"var/log/pods/default_nginx_uid/sidecar/0.log": dockerLog,
+	})
+
+	logs, err := readIntoStore(t, newContainerLogStore, readContainerLogs, context.Background(), bytes.NewReader(data), "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := []string{}
+	for _, l := range logs {
+		got = append(got, l.ReadStringOrDefault("container", "")+":"+log.MustGetFieldSet(l, &log.MainMessageFieldSet{}).MainMessage)
+	}
+	want := []string{"nginx:split line", "sidecar:from docker", "nginx:second", "nginx:"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("container logs mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestReadContainerLogsWithUnsupportedLine(t *testing.T) {
+	data := mustTarWithPaths(t, map[string]string{
+		"var/log/pods/default_nginx_uid/nginx/0.log": "not a container log",
+	})
+	_, err := readIntoStore(t, newContainerLogStore, readContainerLogs, context.Background(), bytes.NewReader(data), "token")
+	if err == nil || !strings.Contains(err.Error(), "line 1 in 0.log") {
+		t.Errorf("readContainerLogs() returned %v, want an error for line 1", err)
+	}
+}
+
+func TestContainerLogUploadFileVerifier(t *testing.T) {
+	testCases := []struct {
+		name    string
+		files   map[string]string
+		wantErr string
+	}{
+		{
+			name: "valid container logs",
+			files: map[string]string{
+				"var/log/pods/default_nginx_uid/nginx/0.log": "2025-01-01T00:00:00.000000000Z stdout F hello",
+			},
+		},
+		{
+			name: "unsupported line",
+			files: map[string]string{
+				"var/log/pods/default_nginx_uid/nginx/0.log": "not a container log",
+			},
+			wantErr: "line 1 in 0.log",
+		},
+		{
+			name: "file out of the directory structure",
+			files: map[string]string{
+				"nginx.log": "2025-01-01T00:00:00.000000000Z stdout F hello",
+			},
+			wantErr: "failed to identify the container",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			provider := upload.NewLocalUploadFileStoreProvider(t.TempDir())
+			token := provider.GetUploadToken("test")
+			if err := provider.Write(token, bytes.NewReader(mustTarWithPaths(t, tc.files))); err != nil {
+				t.Fatal(err)
+			}
+
+			err := (&ContainerLogUploadFileVerifier{}).Verify(provider, token)
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Errorf("Verify() returned an unexpected error: %v", err)
+				}
+			} else if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("Verify() returned %v, want an error containing %q", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestOSSContainerLogParser(t *testing.T) {
+	l, err := newContainerLog(&containerLogFile{Namespace: "default", Pod: "nginx", Container: "nginx"}, &containerLogLine{
+		Time:   "2025-01-01T00:00:00Z",
+		Stream: "stdout",
+	}, "hello", "0.log:1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cs := history.NewChangeSet(l)
+
+	err = (&OSSContainerLogParser{}).Parse(context.Background(), l, cs, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if events := cs.GetEvents(resourcepath.Container("default", "nginx", "nginx")); len(events) != 1 {
+		t.Errorf("got %d events, want 1", len(events))
+	}
+	if got := cs.GetLogSummary(); got != "hello" {
+		t.Errorf("got %q log summary, want %q", got, "hello")
+	}
+}
@@ -20,9 +20,9 @@ import (
 	"strings"
 
 	"github.com/GoogleCloudPlatform/khi/pkg/log"
+	"github.com/GoogleCloudPlatform/khi/pkg/log/logstore"
 	"github.com/GoogleCloudPlatform/khi/pkg/model/enum"
 	"github.com/GoogleCloudPlatform/khi/pkg/model/history"
-	"github.com/GoogleCloudPlatform/khi/pkg/model/history/grouper"
 	"github.com/GoogleCloudPlatform/khi/pkg/model/history/resourcepath"
 	"github.com/GoogleCloudPlatform/khi/pkg/parser"
 	oss_constant "github.com/GoogleCloudPlatform/khi/pkg/source/oss/constant"
@@ -48,13 +48,8 @@ func (o *OSSK8sEventFromK8sAudit) GetParserName() string {
 	return "OSS Kubernetes Event logs from JSONL audit log"
 }
 
-// Grouper implements parser.Parser.
-func (o *OSSK8sEventFromK8sAudit) Grouper() grouper.LogGrouper {
-	return grouper.AllDependentLogGrouper
-}
-
 // LogTask implements parser.Parser.
-func (o *OSSK8sEventFromK8sAudit) LogTask() taskid.TaskReference[[]*log.Log] {
+func (o *OSSK8sEventFromK8sAudit) LogTask() taskid.TaskReference[*logstore.Store] {
 	return oss_taskid.OSSAPIServerAuditLogFilterNonAuditTaskID.Ref()
 }
 
@@ -0,0 +1,202 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parser
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	inspection_task_interface "github.com/GoogleCloudPlatform/khi/pkg/inspection/interface"
+	"github.com/GoogleCloudPlatform/khi/pkg/inspection/ioconfig"
+	"github.com/GoogleCloudPlatform/khi/pkg/inspection/metadata/progress"
+	inspection_task "github.com/GoogleCloudPlatform/khi/pkg/inspection/task"
+	"github.com/GoogleCloudPlatform/khi/pkg/log"
+	"github.com/GoogleCloudPlatform/khi/pkg/log/logstore"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/enum"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/history"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/history/grouper"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/history/resourcepath"
+	"github.com/GoogleCloudPlatform/khi/pkg/parser"
+	"github.com/GoogleCloudPlatform/khi/pkg/server/upload"
+	oss_constant "github.com/GoogleCloudPlatform/khi/pkg/source/oss/constant"
+	oss_log "github.com/GoogleCloudPlatform/khi/pkg/source/oss/log"
+	oss_taskid "github.com/GoogleCloudPlatform/khi/pkg/source/oss/taskid"
+	"github.com/GoogleCloudPlatform/khi/pkg/task"
+	"github.com/GoogleCloudPlatform/khi/pkg/task/taskid"
+)
+
+// OSSK8sEventFileReader reads Event resources from the uploaded output of `kubectl get events -o json`.
+var OSSK8sEventFileReader = inspection_task.NewProgressReportableInspectionTask(
+	oss_taskid.OSSK8sEventFileReader,
+	[]taskid.UntypedTaskReference{
+		ioconfig.IOConfigTaskID.Ref(),
+		oss_taskid.OSSK8sEventFileInputTask.Ref(),
+	},
+	func(ctx context.Context, taskMode inspection_task_interface.InspectionTaskMode, tp *progress.TaskProgress) (*logstore.Store, error) {
+		if taskMode == inspection_task_interface.TaskModeDryRun {
+			return nil, nil
+		}
+		ioConfig := task.GetTaskResult(ctx, ioconfig.IOConfigTaskID.Ref())
+		result := task.GetTaskResult(ctx, oss_taskid.OSSK8sEventFileInputTask.Ref())
+		store := newK8sEventStore(ioConfig.TemporaryFolder)
+		err := readUploadedLogFile(ctx, tp, result, store, readK8sEvents)
+		if err != nil {
+			store.Close()
+			return nil, err
+		}
+		return store, nil
+	},
+)
+
+// newK8sEventStore returns an empty store for Event resources.
+func newK8sEventStore(folder string) *logstore.Store {
+	return logstore.NewStore(folder, grouper.AllDependentLogGrouper, &oss_log.OSSK8sEventCommonFieldSetReader{}, &oss_log.OSSK8sEventMainMessageFieldSetReader{})
+}
+
+// readK8sEvents reads Event resources from JSON documents in the uploaded file.
+// Each document can be a list of Events (e.g. `kubectl get events -o json`) or a single Event. Events are appended to the store in the order of the file.
+func readK8sEvents(ctx context.Context, reader io.Reader, name string, store *logstore.Store) error {
+	return upload.WalkUploadedMembers(reader, name, func(memberName string, memberReader io.Reader) error {
+		decoder := json.NewDecoder(memberReader)
+		for documentNumber := 1; ; documentNumber++ {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			var document struct {
+				Kind  string            `json:"kind"`
+				Items []json.RawMessage `json:"items"`
+			}
+			var raw json.RawMessage
+			err := decoder.Decode(&raw)
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("failed to read JSON document #%d in %s: %w", documentNumber, path.Base(memberName), err)
+			}
+			if err := json.Unmarshal(raw, &document); err != nil {
+				return fmt.Errorf("JSON document #%d in %s is not a Kubernetes resource: %w", documentNumber, path.Base(memberName), err)
+			}
+			items := document.Items
+			if document.Kind == "Event" {
+				items = []json.RawMessage{raw}
+			}
+			for _, item := range items {
+				l, err := decodeK8sEvent(item)
+				if err != nil {
+					return fmt.Errorf("failed to read an event in %s: %w", path.Base(memberName), err)
+				}
+				if err := store.Append(l); err != nil {
+					return err
+				}
+			}
+		}
+	})
+}
+
+func decodeK8sEvent(item json.RawMessage) (*log.Log, error) {
+	l, err := log.NewLogFromYAMLString(string(item))
+	if err != nil {
+		return nil, err
+	}
+	l.LogType = enum.LogTypeEvent
+	err = l.SetFieldSetReader(&oss_log.OSSK8sEventCommonFieldSetReader{})
+	if err != nil {
+		return nil, err
+	}
+	err = l.SetFieldSetReader(&oss_log.OSSK8sEventMainMessageFieldSetReader{})
+	if err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// OSSK8sEventFileParser generates events on the involved object from Event resources read from uploaded files.
+type OSSK8sEventFileParser struct {
+}
+
+func (o *OSSK8sEventFileParser) Dependencies() []taskid.UntypedTaskReference {
+	return []taskid.UntypedTaskReference{}
+}
+
+func (o *OSSK8sEventFileParser) Description() string {
+	return `Gather Kubernetes events from the uploaded output of kubectl get events. This is useful when the audit log doesn't contain events.`
+}
+
+func (o *OSSK8sEventFileParser) GetParserName() string {
+	return "OSS Kubernetes Event logs from kubectl output"
+}
+
+func (o *OSSK8sEventFileParser) LogTask() taskid.TaskReference[*logstore.Store] {
+	return oss_taskid.OSSK8sEventFileReader.Ref()
+}
+
+func (o *OSSK8sEventFileParser) Parse(ctx context.Context, l *log.Log, cs *history.ChangeSet, builder *history.Builder) error {
+	// events.k8s.io/v1 Events have `regarding` instead of `involvedObject`.
+	objectField := "involvedObject"
+	if !l.Has(objectField) {
+		objectField = "regarding"
+	}
+	apiVersion := l.ReadStringOrDefault(objectField+".apiVersion", "core/v1")
+	if apiVersion == "v1" {
+		apiVersion = "core/v1"
+	}
+	kind := strings.ToLower(l.ReadStringOrDefault(objectField+".kind", "unknown"))
+	namespace := l.ReadStringOrDefault(objectField+".namespace", "cluster-scope")
+	name := l.ReadStringOrDefault(objectField+".name", "unknown")
+	fieldPath := l.ReadStringOrDefault(objectField+".fieldPath", "")
+
+	cs.RecordEvent(resourcepath.NameLayerGeneralItem(apiVersion, kind, namespace, name))
+	if kind == "pod" {
+		if containerName := containerNameFromFieldPath(fieldPath); containerName != "" {
+			cs.RecordEvent(resourcepath.Container(namespace, name, containerName))
+		}
+	}
+
+	reason := l.ReadStringOrDefault("reason", "???")
+	message := log.MustGetFieldSet(l, &log.MainMessageFieldSet{}).MainMessage
+	summary := fmt.Sprintf("【%s】%s", reason, message)
+	if count := l.ReadIntOrDefault("count", 0); count > 1 {
+		summary = fmt.Sprintf("%s (x%d)", summary, count)
+	}
+	cs.RecordLogSummary(summary)
+	return nil
+}
+
+// containerNameFromFieldPath returns the container name from the fieldPath of the involved object like `spec.containers{nginx}`.
+func containerNameFromFieldPath(fieldPath string) string {
+	start := strings.Index(fieldPath, "{")
+	if start == -1 || !strings.HasSuffix(fieldPath, "}") {
+		return ""
+	}
+	return fieldPath[start+1 : len(fieldPath)-1]
+}
+
+func (o *OSSK8sEventFileParser) TargetLogType() enum.LogType {
+	return enum.LogTypeEvent
+}
+
+var _ parser.Parser = (*OSSK8sEventFileParser)(nil)
+
+var OSSK8sEventFileParserTask = parser.NewParserTaskFromParser(
+	oss_taskid.OSSK8sEventFileParserTaskID,
+	&OSSK8sEventFileParser{}, false, []string{
+		oss_constant.OSSInspectionTypeID,
+	},
+)
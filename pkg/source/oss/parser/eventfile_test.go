@@ -0,0 +1,110 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parser
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/log"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/enum"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/history"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/history/resourcepath"
+	"github.com/google/go-cmp/cmp"
+
+	_ "github.com/GoogleCloudPlatform/khi/internal/testflags"
+)
+
+const testEventList = `{
+  "apiVersion": "v1",
+  "kind": "List",
+  "items": [
+    {
+      "apiVersion": "v1",
+      "kind": "Event",
+      "metadata": {"name": "nginx.2", "namespace": "default", "uid": "uid-2"},
+      "involvedObject": {"apiVersion": "v1", "kind": "Pod", "namespace": "default", "name": "nginx", "fieldPath": "spec.containers{nginx}"},
+      "reason": "BackOff",
+      "message": "Back-off restarting failed container",
+      "type": "Warning",
+      "count": 3,
+      "lastTimestamp": "2025-01-01T00:00:02Z"
+    },
+    {
+      "apiVersion": "v1",
+      "kind": "Event",
+      "metadata": {"name": "nginx.1", "namespace": "default", "uid": "uid-1"},
+      "involvedObject": {"apiVersion": "v1", "kind": "Pod", "namespace": "default", "name": "nginx"},
+      "reason": "Scheduled",
+      "message": "Successfully assigned default/nginx to node-1",
+      "type": "Normal",
+      "eventTime": "2025-01-01T00:00:01.000000Z"
+    }
+  ]
+}`
+
+func TestReadK8sEvents(t *testing.T) {
+	logs, err := readIntoStore(t, newK8sEventStore, readK8sEvents, context.Background(), strings.NewReader(testEventList), "events.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gotIDs := []string{}
+	gotSeverities := []enum.Severity{}
+	for _, l := range logs {
+		commonFieldSet := log.MustGetFieldSet(l, &log.CommonFieldSet{})
+		gotIDs = append(gotIDs, commonFieldSet.DisplayID)
+		gotSeverities = append(gotSeverities, commonFieldSet.Severity)
+	}
+	if diff := cmp.Diff([]string{"uid-1", "uid-2"}, gotIDs); diff != "" {
+		t.Errorf("event IDs mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]enum.Severity{enum.SeverityInfo, enum.SeverityWarning}, gotSeverities); diff != "" {
+		t.Errorf("event severities mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestReadK8sEventsWithInvalidDocument(t *testing.T) {
+	_, err := readIntoStore(t, newK8sEventStore, readK8sEvents, context.Background(), strings.NewReader(`{"kind":"List","items":[]} {broken`), "events.json")
+	if err == nil || !strings.Contains(err.Error(), "JSON document #2 in events.json") {
+		t.Errorf("readK8sEvents() returned %v, want an error for the second document", err)
+	}
+}
+
+func TestOSSK8sEventFileParser(t *testing.T) {
+	logs, err := readIntoStore(t, newK8sEventStore, readK8sEvents, context.Background(), strings.NewReader(testEventList), "events.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := logs[1]
+	cs := history.NewChangeSet(l)
+
+	err = (&OSSK8sEventFileParser{}).Parse(context.Background(), l, cs, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if events := cs.GetEvents(resourcepath.NameLayerGeneralItem("core/v1", "pod", "default", "nginx")); len(events) != 1 {
+		t.Errorf("got %d events on the Pod, want 1", len(events))
+	}
+	if events := cs.GetEvents(resourcepath.Container("default", "nginx", "nginx")); len(events) != 1 {
+		t.Errorf("got %d events on the container, want 1", len(events))
+	}
+	wantSummary := "【BackOff】Back-off restarting failed container (x3)"
+	if got := cs.GetLogSummary(); got != wantSummary {
+		t.Errorf("got %q log summary, want %q", got, wantSummary)
+	}
+}
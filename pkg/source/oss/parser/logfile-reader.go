@@ -18,18 +18,21 @@ import (
 	"context"
 	"fmt"
 	"io"
-	"slices"
-	"strings"
+	"sync/atomic"
+	"time"
 
 	"github.com/GoogleCloudPlatform/khi/pkg/common/khictx"
 	"github.com/GoogleCloudPlatform/khi/pkg/common/typedmap"
 	inspection_task_contextkey "github.com/GoogleCloudPlatform/khi/pkg/inspection/contextkey"
 	inspection_task_interface "github.com/GoogleCloudPlatform/khi/pkg/inspection/interface"
+	"github.com/GoogleCloudPlatform/khi/pkg/inspection/ioconfig"
 	"github.com/GoogleCloudPlatform/khi/pkg/inspection/metadata/header"
 	"github.com/GoogleCloudPlatform/khi/pkg/inspection/metadata/progress"
 	inspection_task "github.com/GoogleCloudPlatform/khi/pkg/inspection/task"
-	"github.com/GoogleCloudPlatform/khi/pkg/log"
+	"github.com/GoogleCloudPlatform/khi/pkg/log/logstore"
 	"github.com/GoogleCloudPlatform/khi/pkg/model/enum"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/history/grouper"
+	"github.com/GoogleCloudPlatform/khi/pkg/server/upload"
 	oss_log "github.com/GoogleCloudPlatform/khi/pkg/source/oss/log"
 	oss_taskid "github.com/GoogleCloudPlatform/khi/pkg/source/oss/taskid"
 	"github.com/GoogleCloudPlatform/khi/pkg/task"
@@ -39,86 +42,92 @@ import (
 var OSSLogFileReader = inspection_task.NewProgressReportableInspectionTask(
 	oss_taskid.OSSAPIServerAuditLogFileReader,
 	[]taskid.UntypedTaskReference{
+		ioconfig.IOConfigTaskID.Ref(),
 		oss_taskid.OSSAPIServerAuditLogFileInputTask.Ref(),
 	},
-	func(ctx context.Context, taskMode inspection_task_interface.InspectionTaskMode, progress *progress.TaskProgress) ([]*log.Log, error) {
+	func(ctx context.Context, taskMode inspection_task_interface.InspectionTaskMode, tp *progress.TaskProgress) (*logstore.Store, error) {
 		if taskMode == inspection_task_interface.TaskModeDryRun {
-			return []*log.Log{}, nil
+			return nil, nil
 		}
+		ioConfig := task.GetTaskResult(ctx, ioconfig.IOConfigTaskID.Ref())
 		result := task.GetTaskResult(ctx, oss_taskid.OSSAPIServerAuditLogFileInputTask.Ref())
 
-		reader, err := result.GetReader()
+		store := newAuditLogStore(ioConfig.TemporaryFolder)
+		err := readUploadedLogFile(ctx, tp, result, store, readAuditLogs)
 		if err != nil {
+			store.Close()
 			return nil, err
 		}
-		defer reader.Close()
-
-		logData, err := io.ReadAll(reader)
-		if err != nil {
-			return nil, err
-		}
-
-		logLines := strings.Split(string(logData), "\n")
-		var logs []*log.Log
-
-		for _, line := range logLines {
-			if strings.TrimSpace(line) == "" {
-				continue
-			}
-
-			l, err := log.NewLogFromYAMLString(line)
-			if err != nil {
-				return nil, fmt.Errorf("failed to read a log: %w", err)
-			}
-
-			err = l.SetFieldSetReader(&oss_log.OSSK8sAuditLogCommonFieldSetReader{})
-			if err != nil {
-				return nil, err
-			}
-
-			// TODO: we may need to consider processing logs not with ResponseComplete stage. All logs not on the ResponseComplete stage will be ignored for now.
-			if l.ReadStringOrDefault("stage", "") != "ResponseComplete" {
-				continue
-			}
-
-			logs = append(logs, l)
-		}
-		slices.SortFunc(logs, func(a, b *log.Log) int {
-			logACommonField := log.MustGetFieldSet(a, &log.CommonFieldSet{})
-			logBCommonField := log.MustGetFieldSet(b, &log.CommonFieldSet{})
-			return int(logACommonField.Timestamp.UnixNano() - logBCommonField.Timestamp.UnixNano())
-		})
 		metadataSet := khictx.MustGetValue(ctx, inspection_task_contextkey.InspectionRunMetadata)
 		header := typedmap.GetOrDefault(metadataSet, header.HeaderMetadataKey, &header.Header{})
 
-		if len(logs) > 0 {
-			startLogCommonField := log.MustGetFieldSet(logs[0], &log.CommonFieldSet{})
-			lastLogCommonField := log.MustGetFieldSet(logs[len(logs)-1], &log.CommonFieldSet{})
-
-			header.StartTimeUnixSeconds = startLogCommonField.Timestamp.Unix()
-			header.EndTimeUnixSeconds = lastLogCommonField.Timestamp.Unix()
+		if startTime, endTime, found := store.TimeRange(); found {
+			header.StartTimeUnixSeconds = startTime.Unix()
+			header.EndTimeUnixSeconds = endTime.Unix()
 		}
 
-		return logs, nil
+		return store, nil
 	},
 )
 
+// newAuditLogStore returns an empty store for OSS audit logs.
+func newAuditLogStore(folder string) *logstore.Store {
+	return logstore.NewStore(folder, grouper.AllDependentLogGrouper, &oss_log.OSSK8sAuditLogCommonFieldSetReader{})
+}
+
+// readUploadedLogFile reads logs from the uploaded file into the store with the given read function while reporting the progress as the bytes read from the file.
+func readUploadedLogFile(ctx context.Context, tp *progress.TaskProgress, result upload.UploadResult, store *logstore.Store, read func(ctx context.Context, reader io.Reader, name string, store *logstore.Store) error) error {
+	reader, err := result.GetReader()
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	fileSize := sizeOfReader(reader)
+	var readBytes atomic.Int64
+	updator := progress.NewProgressUpdator(tp, time.Second, func(tp *progress.TaskProgress) {
+		current := readBytes.Load()
+		if fileSize <= 0 {
+			tp.MarkIndeterminate()
+			tp.Message = fmt.Sprintf("%d bytes read", current)
+			return
+		}
+		tp.Percentage = float32(current) / float32(fileSize)
+		tp.Message = fmt.Sprintf("%d/%d bytes", current, fileSize)
+	})
+	err = updator.Start(ctx)
+	if err != nil {
+		return err
+	}
+	defer updator.Done()
+
+	return read(ctx, &countingReader{reader: reader, count: &readBytes}, result.Token.GetID(), store)
+}
+
 var OSSEventLogFilter = inspection_task.NewProgressReportableInspectionTask(
 	oss_taskid.OSSAPIServerAuditLogFilterNonAuditTaskID,
 	[]taskid.UntypedTaskReference{
+		ioconfig.IOConfigTaskID.Ref(),
 		oss_taskid.OSSAuditLogFileReader.GetUntypedReference(),
-	}, func(ctx context.Context, taskMode inspection_task_interface.InspectionTaskMode, progress *progress.TaskProgress) ([]*log.Log, error) {
+	}, func(ctx context.Context, taskMode inspection_task_interface.InspectionTaskMode, progress *progress.TaskProgress) (*logstore.Store, error) {
 		if taskMode == inspection_task_interface.TaskModeDryRun {
-			return []*log.Log{}, nil
+			return nil, nil
 		}
+		ioConfig := task.GetTaskResult(ctx, ioconfig.IOConfigTaskID.Ref())
 		logs := task.GetTaskResult(ctx, oss_taskid.OSSAuditLogFileReader.Ref())
 
-		var eventLogs []*log.Log
-
-		for _, l := range logs {
+		eventLogs := newAuditLogStore(ioConfig.TemporaryFolder)
+		for l, err := range logs.All() {
+			if err != nil {
+				eventLogs.Close()
+				return nil, err
+			}
 			if l.ReadStringOrDefault("kind", "") == "Event" && l.ReadStringOrDefault("responseObject.kind", "") == "Event" {
 				l.LogType = enum.LogTypeEvent
-				eventLogs = append(eventLogs, l)
+				if err := eventLogs.Append(l); err != nil {
+					eventLogs.Close()
+					return nil, err
+				}
 			}
 		}
 
@@ -128,24 +137,31 @@ var OSSEventLogFilter = inspection_task.NewProgressReportableInspectionTask(
 var OSSNonEventLogFilter = inspection_task.NewProgressReportableInspectionTask(
 	oss_taskid.OSSAPIServerAuditLogFilterAuditTaskID,
 	[]taskid.UntypedTaskReference{
+		ioconfig.IOConfigTaskID.Ref(),
 		oss_taskid.OSSAuditLogFileReader.GetUntypedReference(),
-	}, func(ctx context.Context, taskMode inspection_task_interface.InspectionTaskMode, progress *progress.TaskProgress) ([]*log.Log, error) {
+	}, func(ctx context.Context, taskMode inspection_task_interface.InspectionTaskMode, progress *progress.TaskProgress) (*logstore.Store, error) {
 		if taskMode == inspection_task_interface.TaskModeDryRun {
-			return []*log.Log{}, nil
+			return nil, nil
 		}
-
+		ioConfig := task.GetTaskResult(ctx, ioconfig.IOConfigTaskID.Ref())
 		logs := task.GetTaskResult(ctx, oss_taskid.OSSAuditLogFileReader.Ref())
 
-		var auditLogs []*log.Log
-
-		for _, l := range logs {
+		auditLogs := newAuditLogStore(ioConfig.TemporaryFolder)
+		for l, err := range logs.All() {
+			if err != nil {
+				auditLogs.Close()
+				return nil, err
+			}
 			verb := l.ReadStringOrDefault("verb", "")
 			if l.ReadStringOrDefault("kind", "") == "Event" && l.ReadStringOrDefault("responseObject.kind", "") != "Event" && l.Has("objectRef") {
 				if verb == "" || verb == "get" || verb == "watch" || verb == "list" {
 					continue
 				}
 				l.LogType = enum.LogTypeAudit
-				auditLogs = append(auditLogs, l)
+				if err := auditLogs.Append(l); err != nil {
+					auditLogs.Close()
+					return nil, err
+				}
 			}
 		}
 
@@ -0,0 +1,156 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parser
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sync"
+	"sync/atomic"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/common/worker"
+	"github.com/GoogleCloudPlatform/khi/pkg/log"
+	"github.com/GoogleCloudPlatform/khi/pkg/log/logstore"
+	"github.com/GoogleCloudPlatform/khi/pkg/server/upload"
+	oss_log "github.com/GoogleCloudPlatform/khi/pkg/source/oss/log"
+)
+
+// logLineDecodeParallelism is the maximum count of log lines decoded concurrently.
+// This also bounds the count of lines held in memory before they are decoded.
+const logLineDecodeParallelism = 16
+
+// logLineReaderBufferSize is the buffer size used to read lines from the log file.
+const logLineReaderBufferSize = 1024 * 1024
+
+// responseCompleteStage is the only stage of audit logs used in KHI for now.
+const responseCompleteStage = "ResponseComplete"
+
+// countingReader is an io.Reader counting the bytes read from the underlying reader.
+type countingReader struct {
+	reader io.Reader
+	count  *atomic.Int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.reader.Read(p)
+	c.count.Add(int64(n))
+	return n, err
+}
+
+// logLineDecoder decodes a line read from a file in the uploaded file. It returns nil without error when the line must be ignored.
+type logLineDecoder = func(memberName string, lineNumber int, line []byte) (*log.Log, error)
+
+// readAuditLogs reads JSONL audit logs from the uploaded file line by line and decodes lines in parallel.
+// Every file in compressed files or archives is read, and logs from these files are merged in the timestamp order.
+// Lines not containing the ResponseComplete stage are discarded before decoding.
+func readAuditLogs(ctx context.Context, reader io.Reader, name string, store *logstore.Store) error {
+	return readLogLines(ctx, reader, name, store, func(memberName string, lineNumber int, line []byte) (*log.Log, error) {
+		if !bytes.Contains(line, []byte(responseCompleteStage)) {
+			return nil, nil
+		}
+		return decodeAuditLogLine(line)
+	})
+}
+
+// readLogLines reads the uploaded file line by line and decodes non empty lines in parallel with the given decoder.
+// Decoded logs are appended to the store as they are decoded. The store reads them in the timestamp order, and logs with the same timestamp in the order of lines in the uploaded file.
+// Decoded logs must have CommonFieldSet.
+func readLogLines(ctx context.Context, reader io.Reader, name string, store *logstore.Store, decoder logLineDecoder) error {
+	pool := worker.NewPool(logLineDecodeParallelism)
+
+	var errLock sync.Mutex
+	var decodeErr error
+	var failed atomic.Bool
+	// order is the index of non empty lines across every file to keep the original order of logs with the same timestamp.
+	var order int64
+
+	err := upload.WalkUploadedMembers(reader, name, func(memberName string, memberReader io.Reader) error {
+		lineReader := bufio.NewReaderSize(memberReader, logLineReaderBufferSize)
+		for lineNumber := 1; ; lineNumber++ {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if failed.Load() {
+				return nil
+			}
+			line, readErr := lineReader.ReadBytes('\n')
+			if readErr != nil && readErr != io.EOF {
+				return readErr
+			}
+			if len(bytes.TrimSpace(line)) > 0 {
+				currentLineNumber := lineNumber
+				currentOrder := order
+				order++
+				pool.Run(func() {
+					l, err := decoder(memberName, currentLineNumber, line)
+					if err == nil && l != nil {
+						err = store.AppendInOrder(l, currentOrder)
+					}
+					if err != nil {
+						errLock.Lock()
+						defer errLock.Unlock()
+						if decodeErr == nil {
+							decodeErr = fmt.Errorf("failed to read a log at line %d in %s: %w", currentLineNumber, path.Base(memberName), err)
+						}
+						failed.Store(true)
+					}
+				})
+			}
+			if readErr == io.EOF {
+				return nil
+			}
+		}
+	})
+	pool.Wait()
+	if err != nil {
+		return err
+	}
+	return decodeErr
+}
+
+// decodeAuditLogLine decodes a line of the audit log. It returns nil without error when the log is not on the ResponseComplete stage.
+func decodeAuditLogLine(line []byte) (*log.Log, error) {
+	l, err := log.NewLogFromYAMLString(string(line))
+	if err != nil {
+		return nil, err
+	}
+	// TODO: we may need to consider processing logs not with ResponseComplete stage. All logs not on the ResponseComplete stage will be ignored for now.
+	if l.ReadStringOrDefault("stage", "") != responseCompleteStage {
+		return nil, nil
+	}
+	err = l.SetFieldSetReader(&oss_log.OSSK8sAuditLogCommonFieldSetReader{})
+	if err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// sizeOfReader returns the size of the file read from the given reader. It returns -1 when the size is unknown.
+func sizeOfReader(reader io.Reader) int64 {
+	file, ok := reader.(interface{ Stat() (os.FileInfo, error) })
+	if !ok {
+		return -1
+	}
+	info, err := file.Stat()
+	if err != nil {
+		return -1
+	}
+	return info.Size()
+}
@@ -0,0 +1,119 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parser
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/log"
+	"github.com/GoogleCloudPlatform/khi/pkg/log/logstore"
+	"github.com/GoogleCloudPlatform/khi/pkg/server/upload"
+	"github.com/google/go-cmp/cmp"
+
+	_ "github.com/GoogleCloudPlatform/khi/internal/testflags"
+)
+
+// readIntoStore reads logs with the given read function into a new store and returns all logs read from the store.
+func readIntoStore(t *testing.T, newStore func(folder string) *logstore.Store, read func(ctx context.Context, reader io.Reader, name string, store *logstore.Store) error, ctx context.Context, reader io.Reader, name string) ([]*log.Log, error) {
+	t.Helper()
+	store := newStore(t.TempDir())
+	t.Cleanup(func() { store.Close() })
+	if err := read(ctx, reader, name, store); err != nil {
+		return nil, err
+	}
+	return store.ReadAll()
+}
+
+func TestReadAuditLogs(t *testing.T) {
+	input := strings.Join([]string{
+		`{"auditID":"3","stage":"ResponseComplete","stageTimestamp":"2025-01-01T00:00:03Z"}`,
+		`{"auditID":"1","stage":"ResponseStarted","stageTimestamp":"2025-01-01T00:00:00Z"}`,
+		``,
+		`{"auditID":"2","stage":"ResponseComplete","stageTimestamp":"2025-01-01T00:00:01Z"}`,
+		`{"auditID":"4","stage":"ResponseComplete","stageTimestamp":"2025-01-01T00:00:01Z"}`,
+		`{"auditID":"5","stage":"RequestReceived","message":"ResponseComplete","stageTimestamp":"2025-01-01T00:00:00Z"}`,
+	}, "\n")
+	var readBytes atomic.Int64
+
+	logs, err := readIntoStore(t, newAuditLogStore, readAuditLogs, context.Background(), &countingReader{reader: strings.NewReader(input), count: &readBytes}, "audit.log")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gotIDs := []string{}
+	for _, l := range logs {
+		gotIDs = append(gotIDs, l.ReadStringOrDefault("auditID", ""))
+	}
+	if diff := cmp.Diff([]string{"2", "4", "3"}, gotIDs); diff != "" {
+		t.Errorf("audit IDs mismatch (-want +got):\n%s", diff)
+	}
+	if readBytes.Load() != int64(len(input)) {
+		t.Errorf("read bytes = %d, want %d", readBytes.Load(), len(input))
+	}
+}
+
+func TestReadAuditLogsWithBrokenLine(t *testing.T) {
+	input := strings.Join([]string{
+		`{"auditID":"1","stage":"ResponseComplete","stageTimestamp":"2025-01-01T00:00:00Z"}`,
+		`{"auditID":"2","stage":"ResponseComplete"`,
+	}, "\n")
+	_, err := readIntoStore(t, newAuditLogStore, readAuditLogs, context.Background(), strings.NewReader(input), "audit.log")
+	if err == nil || !strings.Contains(err.Error(), "line 2 in audit.log") {
+		t.Errorf("readAuditLogs() returned %v, want an error for line 2", err)
+	}
+}
+
+func TestReadAuditLogsCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := readIntoStore(t, newAuditLogStore, readAuditLogs, ctx, strings.NewReader(`{"stage":"ResponseComplete"}`), "audit.log")
+	if err != context.Canceled {
+		t.Errorf("readAuditLogs() returned %v, want context.Canceled", err)
+	}
+}
+
+func TestReadAuditLogsFromMultipleFiles(t *testing.T) {
+	rotated := strings.Join([]string{
+		`{"auditID":"1","stage":"ResponseComplete","stageTimestamp":"2025-01-01T00:00:00Z"}`,
+		`{"auditID":"3","stage":"ResponseComplete","stageTimestamp":"2025-01-01T00:00:02Z"}`,
+	}, "\n")
+	current := `{"auditID":"2","stage":"ResponseComplete","stageTimestamp":"2025-01-01T00:00:01Z"}`
+	var buf bytes.Buffer
+	err := upload.WriteMultiFileArchive(&buf, []upload.UploadedFile{
+		{Name: "audit.log.1", Size: int64(len(rotated)), Reader: strings.NewReader(rotated)},
+		{Name: "audit.log", Size: int64(len(current)), Reader: strings.NewReader(current)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logs, err := readIntoStore(t, newAuditLogStore, readAuditLogs, context.Background(), &buf, "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gotIDs := []string{}
+	for _, l := range logs {
+		gotIDs = append(gotIDs, l.ReadStringOrDefault("auditID", ""))
+	}
+	if diff := cmp.Diff([]string{"1", "2", "3"}, gotIDs); diff != "" {
+		t.Errorf("audit IDs mismatch (-want +got):\n%s", diff)
+	}
+}
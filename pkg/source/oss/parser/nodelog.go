@@ -0,0 +1,200 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parser
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	inspection_task_interface "github.com/GoogleCloudPlatform/khi/pkg/inspection/interface"
+	"github.com/GoogleCloudPlatform/khi/pkg/inspection/ioconfig"
+	"github.com/GoogleCloudPlatform/khi/pkg/inspection/metadata/progress"
+	inspection_task "github.com/GoogleCloudPlatform/khi/pkg/inspection/task"
+	"github.com/GoogleCloudPlatform/khi/pkg/log"
+	"github.com/GoogleCloudPlatform/khi/pkg/log/logstore"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/enum"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/history"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/history/grouper"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/history/resourcepath"
+	"github.com/GoogleCloudPlatform/khi/pkg/parser"
+	oss_constant "github.com/GoogleCloudPlatform/khi/pkg/source/oss/constant"
+	oss_log "github.com/GoogleCloudPlatform/khi/pkg/source/oss/log"
+	oss_taskid "github.com/GoogleCloudPlatform/khi/pkg/source/oss/taskid"
+	"github.com/GoogleCloudPlatform/khi/pkg/task"
+	"github.com/GoogleCloudPlatform/khi/pkg/task/taskid"
+)
+
+const containerdStartingMsg = "starting containerd"
+
+// OSSNodeLogFileReader reads journald logs exported with `journalctl -o json` from the uploaded file.
+var OSSNodeLogFileReader = inspection_task.NewProgressReportableInspectionTask(
+	oss_taskid.OSSNodeLogFileReader,
+	[]taskid.UntypedTaskReference{
+		ioconfig.IOConfigTaskID.Ref(),
+		oss_taskid.OSSNodeLogFileInputTask.Ref(),
+	},
+	func(ctx context.Context, taskMode inspection_task_interface.InspectionTaskMode, tp *progress.TaskProgress) (*logstore.Store, error) {
+		if taskMode == inspection_task_interface.TaskModeDryRun {
+			return nil, nil
+		}
+		ioConfig := task.GetTaskResult(ctx, ioconfig.IOConfigTaskID.Ref())
+		result := task.GetTaskResult(ctx, oss_taskid.OSSNodeLogFileInputTask.Ref())
+		store := newJournalLogStore(ioConfig.TemporaryFolder)
+		err := readUploadedLogFile(ctx, tp, result, store, readJournalLogs)
+		if err != nil {
+			store.Close()
+			return nil, err
+		}
+		return store, nil
+	},
+)
+
+// newJournalLogStore returns an empty store for journald logs grouped by the host.
+func newJournalLogStore(folder string) *logstore.Store {
+	return logstore.NewStore(folder, grouper.NewSingleStringFieldKeyLogGrouper("_HOSTNAME"), &oss_log.OSSJournalCommonFieldSetReader{}, &oss_log.OSSJournalMainMessageFieldSetReader{})
+}
+
+// readJournalLogs reads JSONL journald logs from the uploaded file.
+func readJournalLogs(ctx context.Context, reader io.Reader, name string, store *logstore.Store) error {
+	return readLogLines(ctx, reader, name, store, func(memberName string, lineNumber int, line []byte) (*log.Log, error) {
+		l, err := log.NewLogFromYAMLString(string(line))
+		if err != nil {
+			return nil, err
+		}
+		l.LogType = enum.LogTypeNode
+		err = l.SetFieldSetReader(&oss_log.OSSJournalCommonFieldSetReader{})
+		if err != nil {
+			return nil, err
+		}
+		err = l.SetFieldSetReader(&oss_log.OSSJournalMainMessageFieldSetReader{})
+		if err != nil {
+			return nil, err
+		}
+		return l, nil
+	})
+}
+
+// OSSNodeLogParser generates events on node components from journald logs of kubelet and container runtimes.
+type OSSNodeLogParser struct {
+}
+
+func (o *OSSNodeLogParser) Dependencies() []taskid.UntypedTaskReference {
+	return []taskid.UntypedTaskReference{}
+}
+
+func (o *OSSNodeLogParser) Description() string {
+	return `Gather node components(e.g kubelet/containerd) logs from the uploaded journald logs.`
+}
+
+func (o *OSSNodeLogParser) GetParserName() string {
+	return "OSS Kubernetes Node logs from journald"
+}
+
+func (o *OSSNodeLogParser) LogTask() taskid.TaskReference[*logstore.Store] {
+	return oss_taskid.OSSNodeLogFileReader.Ref()
+}
+
+// componentName returns the name of node component writing the log.
+func (o *OSSNodeLogParser) componentName(l *log.Log) string {
+	syslogIdentifier := l.ReadStringOrDefault("SYSLOG_IDENTIFIER", "")
+	if syslogIdentifier != "" {
+		return syslogIdentifier
+	}
+	unit := l.ReadStringOrDefault("_SYSTEMD_UNIT", "")
+	if unit != "" {
+		return strings.TrimSuffix(unit, ".service")
+	}
+	return "unknown"
+}
+
+func (o *OSSNodeLogParser) Parse(ctx context.Context, l *log.Log, cs *history.ChangeSet, builder *history.Builder) error {
+	commonFieldSet := log.MustGetFieldSet(l, &log.CommonFieldSet{})
+	mainMessageFieldSet := log.MustGetFieldSet(l, &log.MainMessageFieldSet{})
+	nodeName := l.ReadStringOrDefault("_HOSTNAME", "")
+	if nodeName == "" {
+		return fmt.Errorf("parser couldn't lookup the node name")
+	}
+	component := o.componentName(l)
+	nodeComponentPath := resourcepath.NodeComponent(nodeName, component)
+
+	summary, err := mainMessageFieldSet.KLogField("")
+	if err != nil {
+		return err
+	}
+	if summary == "" {
+		summary = mainMessageFieldSet.MainMessage
+	}
+	cs.RecordLogSummary(summary)
+	if severity := mainMessageFieldSet.KLogSeverity(); severity != enum.SeverityUnknown {
+		cs.RecordLogSeverity(severity)
+	}
+
+	if component == "containerd" && summary == containerdStartingMsg {
+		cs.RecordRevision(nodeComponentPath, &history.StagingResourceRevision{
+			Verb:       enum.RevisionVerbCreate,
+			State:      enum.RevisionStateExisting,
+			Requestor:  component,
+			ChangeTime: commonFieldSet.Timestamp,
+		})
+	} else {
+		tb := builder.GetTimelineBuilder(resourcepath.ClusterScopedPath(l.ClusterName, nodeComponentPath.Path))
+		if tb.GetLatestRevision() == nil {
+			cs.RecordRevision(nodeComponentPath, &history.StagingResourceRevision{
+				Verb:       enum.RevisionVerbCreate,
+				State:      enum.RevisionStateInferred,
+				Requestor:  component,
+				ChangeTime: commonFieldSet.Timestamp,
+			})
+		}
+	}
+	cs.RecordEvent(nodeComponentPath)
+
+	klogNode, err := mainMessageFieldSet.KLogField("node")
+	if err == nil && klogNode != "" {
+		cs.RecordEvent(resourcepath.Node(klogNode))
+	}
+
+	podNameWithNamespace, err := mainMessageFieldSet.KLogField("pod")
+	if err == nil && podNameWithNamespace != "" {
+		podNamespace, podName, found := strings.Cut(podNameWithNamespace, "/")
+		if !found {
+			podNamespace, podName = "unknown", "unknown"
+		}
+		containerName, err := mainMessageFieldSet.KLogField("containerName")
+		if err == nil && containerName != "" {
+			cs.RecordEvent(resourcepath.Container(podNamespace, podName, containerName))
+			cs.RecordLogSummary(fmt.Sprintf("%s【%s in %s/%s】", summary, containerName, podNamespace, podName))
+		} else {
+			cs.RecordEvent(resourcepath.Pod(podNamespace, podName))
+			cs.RecordLogSummary(fmt.Sprintf("%s【%s/%s】", summary, podNamespace, podName))
+		}
+	}
+	return nil
+}
+
+func (o *OSSNodeLogParser) TargetLogType() enum.LogType {
+	return enum.LogTypeNode
+}
+
+var _ parser.Parser = (*OSSNodeLogParser)(nil)
+
+var OSSNodeLogParserTask = parser.NewParserTaskFromParser(
+	oss_taskid.OSSNodeLogParserTaskID,
+	&OSSNodeLogParser{}, false, []string{
+		oss_constant.OSSInspectionTypeID,
+	},
+)
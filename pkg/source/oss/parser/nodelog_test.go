@@ -0,0 +1,130 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parser
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/inspection/ioconfig"
+	"github.com/GoogleCloudPlatform/khi/pkg/log"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/enum"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/history"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/history/resourcepath"
+	"github.com/google/go-cmp/cmp"
+
+	_ "github.com/GoogleCloudPlatform/khi/internal/testflags"
+)
+
+func TestReadJournalLogs(t *testing.T) {
+	input := strings.Join([]string{
+		`{"__CURSOR":"c2","__REALTIME_TIMESTAMP":"1735689602000000","_HOSTNAME":"node-1","SYSLOG_IDENTIFIER":"kubelet","PRIORITY":"4","MESSAGE":"second"}`,
+		`{"__CURSOR":"c1","__REALTIME_TIMESTAMP":"1735689601000000","_HOSTNAME":"node-1","SYSLOG_IDENTIFIER":"containerd","PRIORITY":"6","MESSAGE":"first"}`,
+	}, "\n")
+
+	logs, err := readIntoStore(t, newJournalLogStore, readJournalLogs, context.Background(), strings.NewReader(input), "kubelet.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type fields struct {
+		DisplayID string
+		Timestamp time.Time
+		Severity  enum.Severity
+		Message   string
+	}
+	got := []fields{}
+	for _, l := range logs {
+		commonFieldSet := log.MustGetFieldSet(l, &log.CommonFieldSet{})
+		got = append(got, fields{
+			DisplayID: commonFieldSet.DisplayID,
+			Timestamp: commonFieldSet.Timestamp,
+			Severity:  commonFieldSet.Severity,
+			Message:   log.MustGetFieldSet(l, &log.MainMessageFieldSet{}).MainMessage,
+		})
+	}
+	want := []fields{
+		{DisplayID: "c1", Timestamp: time.Date(2025, time.January, 1, 0, 0, 1, 0, time.UTC), Severity: enum.SeverityInfo, Message: "first"},
+		{DisplayID: "c2", Timestamp: time.Date(2025, time.January, 1, 0, 0, 2, 0, time.UTC), Severity: enum.SeverityWarning, Message: "second"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("journal logs mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestOSSNodeLogParser(t *testing.T) {
+	testCases := []struct {
+		name          string
+		line          string
+		wantSummary   string
+		wantEventPath []resourcepath.ResourcePath
+		wantVerb      enum.RevisionVerb
+		wantState     enum.RevisionState
+	}{
+		{
+			name:          "containerd starting",
+			line:          `{"__REALTIME_TIMESTAMP":"1735689601000000","_HOSTNAME":"node-1","SYSLOG_IDENTIFIER":"containerd","MESSAGE":"time=\"2025-01-01T00:00:01Z\" level=info msg=\"starting containerd\" revision=abc"}`,
+			wantSummary:   "starting containerd",
+			wantEventPath: []resourcepath.ResourcePath{resourcepath.NodeComponent("node-1", "containerd")},
+			wantVerb:      enum.RevisionVerbCreate,
+			wantState:     enum.RevisionStateExisting,
+		},
+		{
+			name:        "kubelet log with pod and container",
+			line:        `{"__REALTIME_TIMESTAMP":"1735689601000000","_HOSTNAME":"node-1","_SYSTEMD_UNIT":"kubelet.service","MESSAGE":"I0101 00:00:01.000000    1234 kuberuntime_container.go:779] \"Killing container with a grace period\" pod=\"default/nginx\" containerName=\"nginx\""}`,
+			wantSummary: "Killing container with a grace period【nginx in default/nginx】",
+			wantEventPath: []resourcepath.ResourcePath{
+				resourcepath.NodeComponent("node-1", "kubelet"),
+				resourcepath.Container("default", "nginx", "nginx"),
+			},
+			wantVerb:  enum.RevisionVerbCreate,
+			wantState: enum.RevisionStateInferred,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			logs, err := readIntoStore(t, newJournalLogStore, readJournalLogs, context.Background(), strings.NewReader(tc.line), "node.json")
+			if err != nil {
+				t.Fatal(err)
+			}
+			l := logs[0]
+			cs := history.NewChangeSet(l)
+			builder := history.NewBuilder(&ioconfig.IOConfig{TemporaryFolder: "/tmp/"})
+
+			err = (&OSSNodeLogParser{}).Parse(context.Background(), l, cs, builder)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := cs.GetLogSummary(); got != tc.wantSummary {
+				t.Errorf("got %q log summary, want %q", got, tc.wantSummary)
+			}
+			for _, path := range tc.wantEventPath {
+				if events := cs.GetEvents(path); len(events) != 1 {
+					t.Errorf("got %d events on %s, want 1", len(events), path.Path)
+				}
+			}
+			revisions := cs.GetRevisions(tc.wantEventPath[0])
+			if len(revisions) != 1 {
+				t.Fatalf("got %d revisions, want 1", len(revisions))
+			}
+			if revisions[0].Verb != tc.wantVerb || revisions[0].State != tc.wantState {
+				t.Errorf("got revision %v/%v, want %v/%v", revisions[0].Verb, revisions[0].State, tc.wantVerb, tc.wantState)
+			}
+		})
+	}
+}
@@ -50,6 +50,42 @@ func Prepare(inspetionServer *inspection.InspectionTaskServer) error {
 	if err != nil {
 		return err
 	}
+	err = inspetionServer.AddTask(form.K8sEventFilesForm)
+	if err != nil {
+		return err
+	}
+	err = inspetionServer.AddTask(parser.OSSK8sEventFileReader)
+	if err != nil {
+		return err
+	}
+	err = inspetionServer.AddTask(parser.OSSK8sEventFileParserTask)
+	if err != nil {
+		return err
+	}
+	err = inspetionServer.AddTask(form.NodeLogFilesForm)
+	if err != nil {
+		return err
+	}
+	err = inspetionServer.AddTask(parser.OSSNodeLogFileReader)
+	if err != nil {
+		return err
+	}
+	err = inspetionServer.AddTask(parser.OSSNodeLogParserTask)
+	if err != nil {
+		return err
+	}
+	err = inspetionServer.AddTask(form.ContainerLogFilesForm)
+	if err != nil {
+		return err
+	}
+	err = inspetionServer.AddTask(parser.OSSContainerLogFileReader)
+	if err != nil {
+		return err
+	}
+	err = inspetionServer.AddTask(parser.OSSContainerLogParserTask)
+	if err != nil {
+		return err
+	}
 
 	return nil
 }
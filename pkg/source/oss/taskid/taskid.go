@@ -15,7 +15,7 @@
 package oss_taskid
 
 import (
-	"github.com/GoogleCloudPlatform/khi/pkg/log"
+	"github.com/GoogleCloudPlatform/khi/pkg/log/logstore"
 	"github.com/GoogleCloudPlatform/khi/pkg/server/upload"
 	common_k8saudit_taskid "github.com/GoogleCloudPlatform/khi/pkg/source/common/k8s_audit/taskid"
 	"github.com/GoogleCloudPlatform/khi/pkg/task/taskid"
@@ -26,9 +26,19 @@ const OSSTaskPrefix = "khi.google.com/oss/"
 
 var OSSK8sAuditLogSourceTaskID = taskid.NewImplementationID(common_k8saudit_taskid.CommonAuitLogSource, "oss")
 var OSSAPIServerAuditLogFileInputTask = taskid.NewDefaultImplementationID[upload.UploadResult](OSSTaskPrefix + "form/kube-apiserver-audit-log-files")
-var OSSAPIServerAuditLogFileReader = taskid.NewDefaultImplementationID[[]*log.Log](OSSTaskPrefix + "log-reader")
-var OSSAPIServerAuditLogFilterAuditTaskID = taskid.NewDefaultImplementationID[[]*log.Log](OSSTaskPrefix + "log-filter/audit")
-var OSSAPIServerAuditLogFilterNonAuditTaskID = taskid.NewDefaultImplementationID[[]*log.Log](OSSTaskPrefix + "log-filter/non-audit")
-var OSSAuditLogFileReader = taskid.NewDefaultImplementationID[[]*log.Log](OSSTaskPrefix + "log-reader")
+var OSSAPIServerAuditLogFileReader = taskid.NewDefaultImplementationID[*logstore.Store](OSSTaskPrefix + "log-reader")
+var OSSAPIServerAuditLogFilterAuditTaskID = taskid.NewDefaultImplementationID[*logstore.Store](OSSTaskPrefix + "log-filter/audit")
+var OSSAPIServerAuditLogFilterNonAuditTaskID = taskid.NewDefaultImplementationID[*logstore.Store](OSSTaskPrefix + "log-filter/non-audit")
+var OSSAuditLogFileReader = taskid.NewDefaultImplementationID[*logstore.Store](OSSTaskPrefix + "log-reader")
 var OSSK8sAuditLogParserTaskID = taskid.NewDefaultImplementationID[struct{}](OSSTaskPrefix + "audit-parser")
 var OSSK8sEventLogParserTaskID = taskid.NewDefaultImplementationID[struct{}](OSSTaskPrefix + "event-parser")
+
+var OSSK8sEventFileInputTask = taskid.NewDefaultImplementationID[upload.UploadResult](OSSTaskPrefix + "form/kubernetes-event-files")
+var OSSK8sEventFileReader = taskid.NewDefaultImplementationID[*logstore.Store](OSSTaskPrefix + "event-file-reader")
+var OSSK8sEventFileParserTaskID = taskid.NewDefaultImplementationID[struct{}](OSSTaskPrefix + "event-file-parser")
+var OSSNodeLogFileInputTask = taskid.NewDefaultImplementationID[upload.UploadResult](OSSTaskPrefix + "form/node-journal-files")
+var OSSNodeLogFileReader = taskid.NewDefaultImplementationID[*logstore.Store](OSSTaskPrefix + "node-log-reader")
+var OSSNodeLogParserTaskID = taskid.NewDefaultImplementationID[struct{}](OSSTaskPrefix + "node-parser")
+var OSSContainerLogFileInputTask = taskid.NewDefaultImplementationID[upload.UploadResult](OSSTaskPrefix + "form/container-log-files")
+var OSSContainerLogFileReader = taskid.NewDefaultImplementationID[*logstore.Store](OSSTaskPrefix + "container-log-reader")
+var OSSContainerLogParserTaskID = taskid.NewDefaultImplementationID[struct{}](OSSTaskPrefix + "container-parser")
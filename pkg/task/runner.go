@@ -27,6 +27,9 @@ import (
 	task_contextkey "github.com/GoogleCloudPlatform/khi/pkg/task/contextkey"
 	task_interface "github.com/GoogleCloudPlatform/khi/pkg/task/inteface"
 	"github.com/GoogleCloudPlatform/khi/pkg/task/taskid"
+	"github.com/GoogleCloudPlatform/khi/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/sync/errgroup"
 )
 
@@ -41,6 +44,7 @@ type LocalRunner struct {
 	taskWaiters     *sync.Map // sync.Map[string(taskRefID), sync.RWMutex], runner acquire the write lock at the beginning. All dependents will acquire read lock, it will be released when the task run finished.
 	waiter          chan interface{}
 	taskStatuses    []*LocalRunnerTaskStat
+	taskSpans       *sync.Map // sync.Map[string(taskRefID), trace.SpanContext], the span context of each started task to be linked from its dependents.
 }
 
 type LocalRunnerTaskStat struct {
@@ -71,6 +75,14 @@ func (r *LocalRunner) Result() (*typedmap.ReadonlyTypedMap, error) {
 	return r.resultVariable.AsReadonly(), nil
 }
 
+// TaskResults returns the results of tasks finished in the run even when the run ended with an error. It returns nil when the runner hasn't finished yet.
+func (r *LocalRunner) TaskResults() *typedmap.ReadonlyTypedMap {
+	if !r.stopped {
+		return nil
+	}
+	return r.resultVariable.AsReadonly()
+}
+
 // Run implements Runner.
 func (r *LocalRunner) Run(ctx context.Context) error {
 	if r.started {
@@ -118,11 +130,18 @@ func (r *LocalRunner) runTask(graphCtx context.Context, taskDefIndex int) error
 		return context.Canceled
 	}
 
+	taskCtx, span := tracing.Start(taskCtx, task.UntypedID().String(),
+		trace.WithLinks(r.dependencySpanLinks(sources)...),
+		trace.WithAttributes(attribute.String("khi.task.id", task.UntypedID().String())),
+	)
+	r.taskSpans.Store(task.UntypedID().ReferenceIDString(), span.SpanContext())
+
 	taskStatus.StartTime = time.Now()
 	taskStatus.Phase = LocalRunnerTaskStatPhaseRunning
 	slog.DebugContext(taskCtx, fmt.Sprintf("task %s started", task.UntypedID()))
 
 	result, err := task.UntypedRun(taskCtx)
+	tracing.EndSpan(span, err)
 
 	taskStatus.Phase = LocalRunnerTaskStatPhaseStopped
 	taskStatus.EndTime = time.Now()
@@ -143,6 +162,22 @@ func (r *LocalRunner) runTask(graphCtx context.Context, taskDefIndex int) error
 	return nil
 }
 
+// dependencySpanLinks returns the links to the spans of the given dependencies.
+func (r *LocalRunner) dependencySpanLinks(dependencies []taskid.UntypedTaskReference) []trace.Link {
+	links := []trace.Link{}
+	for _, dependency := range dependencies {
+		spanContext, found := r.taskSpans.Load(dependency.ReferenceIDString())
+		if !found {
+			continue
+		}
+		links = append(links, trace.Link{
+			SpanContext: spanContext.(trace.SpanContext),
+			Attributes:  []attribute.KeyValue{attribute.String("khi.task.id", dependency.ReferenceIDString())},
+		})
+	}
+	return links
+}
+
 func (r *LocalRunner) TaskStatuses() []*LocalRunnerTaskStat {
 	return r.taskStatuses
 }
@@ -176,6 +211,7 @@ func NewLocalRunner(taskSet *TaskSet) (*LocalRunner, error) {
 		taskWaiters:     &taskWaiters,
 		waiter:          make(chan interface{}),
 		taskStatuses:    taskStatuses,
+		taskSpans:       &sync.Map{},
 	}, nil
 }
 
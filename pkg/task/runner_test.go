@@ -24,6 +24,11 @@ import (
 
 	_ "github.com/GoogleCloudPlatform/khi/internal/testflags"
 	"github.com/GoogleCloudPlatform/khi/pkg/task/taskid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace/noop"
 )
 
 func createMockTask(id string, dependencies []string, runFunc func(ctx context.Context) (any, error)) UntypedTask {
@@ -238,3 +243,60 @@ func TestLocalRunner_ContextCancellation(t *testing.T) {
 		t.Errorf("Expected error containing '%s', got '%s'", context.Canceled.Error(), err.Error())
 	}
 }
+
+func TestLocalRunner_TaskSpans(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() {
+		otel.SetTracerProvider(noop.NewTracerProvider())
+	})
+
+	task1 := createMockTask("task1", nil, func(ctx context.Context) (any, error) {
+		return "result1", nil
+	})
+	task2 := createMockTask("task2", []string{"task1"}, func(ctx context.Context) (any, error) {
+		return nil, errors.New("task2 failed")
+	})
+	taskSet, err := NewTaskSet([]UntypedTask{task1, task2})
+	if err != nil {
+		t.Fatalf("Failed to create task set: %v", err)
+	}
+	sortResult := taskSet.sortTaskGraph()
+	runnableSet := &TaskSet{tasks: sortResult.TopologicalSortedTasks, runnable: true}
+	runner, err := NewLocalRunner(runnableSet)
+	if err != nil {
+		t.Fatalf("Failed to create runner: %v", err)
+	}
+
+	rootCtx, rootSpan := otel.Tracer("test").Start(context.Background(), "root")
+	err = runner.Run(rootCtx)
+	if err != nil {
+		t.Fatalf("Failed to run task: %v", err)
+	}
+	<-runner.Wait()
+	rootSpan.End()
+
+	spans := map[string]sdktrace.ReadOnlySpan{}
+	for _, span := range recorder.Ended() {
+		spans[span.Name()] = span
+	}
+	span1, found := spans[task1.UntypedID().String()]
+	if !found {
+		t.Fatalf("span of task1 was not found")
+	}
+	span2, found := spans[task2.UntypedID().String()]
+	if !found {
+		t.Fatalf("span of task2 was not found")
+	}
+	for _, span := range []sdktrace.ReadOnlySpan{span1, span2} {
+		if span.Parent().SpanID() != rootSpan.SpanContext().SpanID() {
+			t.Errorf("span %s is not a child of the root span", span.Name())
+		}
+	}
+	if len(span2.Links()) != 1 || span2.Links()[0].SpanContext.SpanID() != span1.SpanContext().SpanID() {
+		t.Errorf("span of task2 must be linked to the span of task1, got %v", span2.Links())
+	}
+	if span2.Status().Code != codes.Error {
+		t.Errorf("span of task2 must have the error status, got %v", span2.Status())
+	}
+}
@@ -94,6 +94,14 @@ func (m *MockApiClient) GetComposerEnvironmentNames(ctx context.Context, project
 	return m.GetClusterNamesFunc(ctx, projectId)
 }
 
+// GetFleetMembershipNames implements api.GCPClient.
+func (m *MockApiClient) GetFleetMembershipNames(ctx context.Context, projectId string) ([]string, error) {
+	if m.GetClusterNamesFunc == nil {
+		return []string{"fleet-cluster-foo", "fleet-cluster-bar"}, nil
+	}
+	return m.GetClusterNamesFunc(ctx, projectId)
+}
+
 // ListLogEntries implements api.GCPClient.
 func (m *MockApiClient) ListLogEntries(ctx context.Context, resourceNames []string, filter string, logSink chan *log.Log) error {
 	if m.ListLogEntriesFunc == nil {
@@ -0,0 +1,484 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package fakegcp provides an in-process stand-in of the Google Cloud APIs used by KHI.
+// It lets tests run whole inspections without network access.
+package fakegcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/common/structurev2"
+	"github.com/GoogleCloudPlatform/khi/pkg/source/gcp/api"
+	"github.com/GoogleCloudPlatform/khi/pkg/source/gcp/logfilter"
+)
+
+// FakeAccessToken is the access token sent by clients created with Server.NewClientFactory.
+const FakeAccessToken = "fake-access-token"
+
+// FakeImpersonatedAccessToken is the access token returned from `generateAccessToken` of the IAM Service Account Credentials API.
+const FakeImpersonatedAccessToken = "fake-impersonated-access-token"
+
+const (
+	defaultLogEntriesPageSize = 50
+	maxLogEntriesPageSize     = 1000
+	logEntriesListPath        = "/v2/entries:list"
+	generateAccessTokenSuffix = ":generateAccessToken"
+)
+
+// Collection names of the on-prem cluster listing APIs in gkeonprem.googleapis.com.
+const (
+	BareMetalClusters      = "bareMetalClusters"
+	BareMetalAdminClusters = "bareMetalAdminClusters"
+	VMWareClusters         = "vmwareClusters"
+	VMWareAdminClusters    = "vmwareAdminClusters"
+)
+
+// Fault is an error response returned instead of the normal response.
+type Fault struct {
+	// PathContains is a substring of the request path the fault applies to. The fault applies to every request when it's empty.
+	PathContains string
+	// StatusCode is the HTTP status code of the error response.
+	StatusCode int
+	// Count is the number of requests answered with the fault.
+	Count int
+}
+
+// RecordedRequest is a request received by the server.
+type RecordedRequest struct {
+	Method string
+	// Host is the host name of the original request URL before it was rewritten to the server.
+	Host string
+	Path string
+	Body string
+}
+
+// resourceList is the response of a resource listing API.
+type resourceList struct {
+	field string
+	items []any
+}
+
+// Server is a fake of Cloud Logging `entries:list`, the cluster and environment listing APIs and `generateAccessToken` of the IAM Service Account Credentials API.
+// Requests are routed only by the path, thus any host name can be used with the transport returned from Transport.
+type Server struct {
+	// PageSize is the maximum number of resources in a page of listing APIs other than `entries:list`. Every resource is returned in a page when it's 0.
+	PageSize int
+
+	httpServer *httptest.Server
+	lock       sync.Mutex
+	logEntries []*structurev2.NodeReader
+	resources  map[string]*resourceList
+	faults     []*Fault
+	requests   []RecordedRequest
+}
+
+// NewServer starts a new fake server. Callers must call Close after use.
+func NewServer() *Server {
+	s := &Server{
+		logEntries: []*structurev2.NodeReader{},
+		resources:  map[string]*resourceList{},
+		faults:     []*Fault{},
+		requests:   []RecordedRequest{},
+	}
+	s.httpServer = httptest.NewServer(http.HandlerFunc(s.handle))
+	return s
+}
+
+// Close shuts down the server.
+func (s *Server) Close() {
+	s.httpServer.Close()
+}
+
+// URL returns the base URL of the server.
+func (s *Server) URL() string {
+	return s.httpServer.URL
+}
+
+// Transport returns a http.RoundTripper sending every request to this server regardless of the host in the request URL.
+func (s *Server) Transport() http.RoundTripper {
+	target, _ := url.Parse(s.httpServer.URL)
+	return &rewriteTransport{
+		target: target,
+		base:   s.httpServer.Client().Transport,
+	}
+}
+
+// Endpoints returns the endpoints of every API pointing to this server. Clients using them don't need the transport returned from Transport.
+func (s *Server) Endpoints() *api.Endpoints {
+	endpoints := api.NewEndpoints(api.DefaultUniverseDomain)
+	endpoints.Logging = s.httpServer.URL
+	endpoints.Container = s.httpServer.URL
+	endpoints.GKEMultiCloud = s.httpServer.URL
+	endpoints.GKEOnPrem = s.httpServer.URL
+	endpoints.GKEHub = s.httpServer.URL
+	endpoints.Composer = s.httpServer.URL
+	endpoints.Compute = s.httpServer.URL
+	endpoints.IAMCredentials = s.httpServer.URL
+	return endpoints
+}
+
+// NewClientFactory returns a GCPClientFactory creating clients connected to this server with a static access token.
+func (s *Server) NewClientFactory() *api.GCPClientFactory {
+	factory := api.NewGCPClientFactory()
+	factory.RegisterHeaderProvider(&staticAccessTokenHeaderProvider{})
+	factory.Transport = s.Transport()
+	return factory
+}
+
+// AddLogEntries adds LogEntries served from `entries:list`. Each argument is a LogEntry or a list of LogEntries in YAML or JSON.
+func (s *Server) AddLogEntries(logEntries ...string) error {
+	readers := []*structurev2.NodeReader{}
+	for _, logEntry := range logEntries {
+		node, err := structurev2.FromYAML(logEntry)
+		if err != nil {
+			return fmt.Errorf("failed to parse the log entry\n%w", err)
+		}
+		if node.Type() == structurev2.SequenceNodeType {
+			for _, child := range node.Children() {
+				readers = append(readers, structurev2.NewNodeReader(child))
+			}
+			continue
+		}
+		readers = append(readers, structurev2.NewNodeReader(node))
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.logEntries = append(s.logEntries, readers...)
+	slices.SortStableFunc(s.logEntries, func(a, b *structurev2.NodeReader) int {
+		return a.ReadTimestampOrDefault("timestamp", time.Time{}).Compare(b.ReadTimestampOrDefault("timestamp", time.Time{}))
+	})
+	return nil
+}
+
+// AddLogEntriesFromFiles adds LogEntries read from the given YAML or JSON files.
+func (s *Server) AddLogEntriesFromFiles(filePaths ...string) error {
+	logEntries := []string{}
+	for _, filePath := range filePaths {
+		data, err := os.ReadFile(filePath)
+		if err != nil {
+			return err
+		}
+		logEntries = append(logEntries, string(data))
+	}
+	return s.AddLogEntries(logEntries...)
+}
+
+// SetClusters sets the GKE clusters returned from container.googleapis.com.
+func (s *Server) SetClusters(projectID string, clusters ...api.Cluster) {
+	items := []any{}
+	for _, cluster := range clusters {
+		items = append(items, cluster)
+	}
+	s.setResources(fmt.Sprintf("/v1/projects/%s/locations/-/clusters", projectID), "clusters", items)
+}
+
+// SetAWSClusterNames sets the GKE on AWS clusters returned from the gkemulticloud.googleapis.com endpoint of the location.
+func (s *Server) SetAWSClusterNames(projectID string, location string, clusterNames ...string) {
+	s.setNamedResources(fmt.Sprintf("/v1/projects/%s/locations/%s/awsClusters", projectID, location), "awsClusters", clusterNames)
+}
+
+// SetAzureClusterNames sets the GKE on Azure clusters returned from the gkemulticloud.googleapis.com endpoint of the location.
+func (s *Server) SetAzureClusterNames(projectID string, location string, clusterNames ...string) {
+	s.setNamedResources(fmt.Sprintf("/v1/projects/%s/locations/%s/azureClusters", projectID, location), "azureClusters", clusterNames)
+}
+
+// SetOnPremClusterNames sets the clusters returned from the gkeonprem.googleapis.com collection. collection must be one of BareMetalClusters, BareMetalAdminClusters, VMWareClusters or VMWareAdminClusters.
+func (s *Server) SetOnPremClusterNames(projectID string, collection string, clusterNames ...string) {
+	s.setNamedResources(fmt.Sprintf("/v1/projects/%s/locations/-/%s", projectID, collection), collection, clusterNames)
+}
+
+// SetFleetMembershipNames sets the memberships returned from gkehub.googleapis.com.
+func (s *Server) SetFleetMembershipNames(projectID string, membershipNames ...string) {
+	s.setNamedResources(fmt.Sprintf("/v1/projects/%s/locations/-/memberships", projectID), "resources", membershipNames)
+}
+
+// SetComposerEnvironmentNames sets the environments returned from composer.googleapis.com for the location.
+func (s *Server) SetComposerEnvironmentNames(projectID string, location string, environmentNames ...string) {
+	s.setNamedResources(fmt.Sprintf("/v1/projects/%s/locations/%s/environments", projectID, location), "environments", environmentNames)
+}
+
+// SetRegions sets the regions returned from compute.googleapis.com.
+func (s *Server) SetRegions(projectID string, regions ...string) {
+	s.setNamedResources(fmt.Sprintf("/compute/v1/projects/%s/regions", projectID), "items", regions)
+}
+
+// InjectFault makes the server respond the following requests matching the fault with an error.
+// Faults are evaluated in the injected order.
+func (s *Server) InjectFault(fault Fault) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.faults = append(s.faults, &fault)
+}
+
+// Requests returns the requests received by the server in the received order.
+func (s *Server) Requests() []RecordedRequest {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return slices.Clone(s.requests)
+}
+
+func (s *Server) setNamedResources(path string, field string, names []string) {
+	items := []any{}
+	for _, name := range names {
+		items = append(items, map[string]string{"name": name})
+	}
+	s.setResources(path, field, items)
+}
+
+func (s *Server) setResources(path string, field string, items []any) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.resources[path] = &resourceList{field: field, items: items}
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	s.lock.Lock()
+	s.requests = append(s.requests, RecordedRequest{
+		Method: r.Method,
+		Host:   r.Host,
+		Path:   r.URL.Path,
+		Body:   string(body),
+	})
+	fault := s.consumeFault(r.URL.Path)
+	s.lock.Unlock()
+
+	if fault != nil {
+		writeError(w, fault.StatusCode, fmt.Sprintf("injected fault for %s", r.URL.Path))
+		return
+	}
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "Bearer ") {
+		writeError(w, http.StatusUnauthorized, "request is missing the access token")
+		return
+	}
+	if r.URL.Path == logEntriesListPath {
+		if r.Method != http.MethodPost {
+			writeError(w, http.StatusMethodNotAllowed, "entries:list only accepts POST")
+			return
+		}
+		s.handleListLogEntries(w, body)
+		return
+	}
+	if strings.HasSuffix(r.URL.Path, generateAccessTokenSuffix) {
+		if r.Method != http.MethodPost {
+			writeError(w, http.StatusMethodNotAllowed, "generateAccessToken only accepts POST")
+			return
+		}
+		writeJSON(w, map[string]any{
+			"accessToken": FakeImpersonatedAccessToken,
+			"expireTime":  time.Now().Add(time.Hour).UTC().Format(time.RFC3339),
+		})
+		return
+	}
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "listing APIs only accept GET")
+		return
+	}
+	s.handleListResources(w, r)
+}
+
+// consumeFault returns the first fault applicable to the path and decrements its count. The caller must hold the lock.
+func (s *Server) consumeFault(path string) *Fault {
+	for _, fault := range s.faults {
+		if fault.Count > 0 && strings.Contains(path, fault.PathContains) {
+			fault.Count--
+			return fault
+		}
+	}
+	return nil
+}
+
+func (s *Server) handleListLogEntries(w http.ResponseWriter, body []byte) {
+	var request struct {
+		ResourceNames []string `json:"resourceNames"`
+		Filter        string   `json:"filter"`
+		OrderBy       string   `json:"orderBy"`
+		PageSize      int      `json:"pageSize"`
+		PageToken     string   `json:"pageToken"`
+	}
+	if err := json.Unmarshal(body, &request); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid request body: %v", err))
+		return
+	}
+	filter, err := logfilter.Parse(request.Filter)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid filter: %v", err))
+		return
+	}
+	offset, err := parsePageToken(request.PageToken)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	pageSize := request.PageSize
+	if pageSize <= 0 {
+		pageSize = defaultLogEntriesPageSize
+	}
+	pageSize = min(pageSize, maxLogEntriesPageSize)
+
+	s.lock.Lock()
+	matched := []*structurev2.NodeReader{}
+	for _, entry := range s.logEntries {
+		if logfilter.MatchResourceNames(entry, request.ResourceNames) && filter.Match(entry) {
+			matched = append(matched, entry)
+		}
+	}
+	s.lock.Unlock()
+	switch strings.ToLower(strings.TrimSpace(request.OrderBy)) {
+	case "", "timestamp asc", "timestamp":
+	case "timestamp desc":
+		slices.Reverse(matched)
+	default:
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("unsupported orderBy %q", request.OrderBy))
+		return
+	}
+
+	page, nextPageToken := paginate(matched, offset, pageSize)
+	entries := []json.RawMessage{}
+	for _, entry := range page {
+		serialized, err := entry.Serialize("", &structurev2.JSONNodeSerializer{})
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		entries = append(entries, serialized)
+	}
+	response := map[string]any{}
+	// entries:list omits the fields when the page is empty.
+	if len(entries) > 0 {
+		response["entries"] = entries
+	}
+	if nextPageToken != "" {
+		response["nextPageToken"] = nextPageToken
+	}
+	writeJSON(w, response)
+}
+
+func (s *Server) handleListResources(w http.ResponseWriter, r *http.Request) {
+	offset, err := parsePageToken(r.URL.Query().Get("pageToken"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	s.lock.Lock()
+	list, found := s.resources[r.URL.Path]
+	pageSize := s.PageSize
+	s.lock.Unlock()
+	response := map[string]any{}
+	// Listing APIs return an empty object when no resource exists.
+	if !found {
+		writeJSON(w, response)
+		return
+	}
+	if pageSize <= 0 {
+		pageSize = len(list.items)
+	}
+	page, nextPageToken := paginate(list.items, offset, pageSize)
+	if len(page) > 0 {
+		response[list.field] = page
+	}
+	if nextPageToken != "" {
+		response["nextPageToken"] = nextPageToken
+	}
+	writeJSON(w, response)
+}
+
+// paginate returns the items in the page starting from the offset and the page token of the next page.
+func paginate[T any](items []T, offset int, pageSize int) ([]T, string) {
+	if offset >= len(items) {
+		return []T{}, ""
+	}
+	end := min(offset+max(pageSize, 1), len(items))
+	nextPageToken := ""
+	if end < len(items) {
+		nextPageToken = strconv.Itoa(end)
+	}
+	return items[offset:end], nextPageToken
+}
+
+// parsePageToken returns the offset encoded in the page token. The server uses the offset itself as the page token.
+func parsePageToken(pageToken string) (int, error) {
+	if pageToken == "" {
+		return 0, nil
+	}
+	offset, err := strconv.Atoi(pageToken)
+	if err != nil || offset < 0 {
+		return 0, fmt.Errorf("invalid page token %q", pageToken)
+	}
+	return offset, nil
+}
+
+func writeJSON(w http.ResponseWriter, response any) {
+	data, err := json.Marshal(response)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
+
+// writeError writes an error response in the format of Google APIs.
+func writeError(w http.ResponseWriter, statusCode int, message string) {
+	data, _ := json.Marshal(map[string]any{
+		"error": map[string]any{
+			"code":    statusCode,
+			"message": message,
+			"status":  http.StatusText(statusCode),
+		},
+	})
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	w.Write(data)
+}
+
+// rewriteTransport sends requests to the target server while keeping the original host in the Host header.
+type rewriteTransport struct {
+	target *url.URL
+	base   http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *rewriteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	rewritten := req.Clone(req.Context())
+	rewritten.URL.Scheme = t.target.Scheme
+	rewritten.URL.Host = t.target.Host
+	rewritten.Host = req.URL.Host
+	return t.base.RoundTrip(rewritten)
+}
+
+// staticAccessTokenHeaderProvider sets FakeAccessToken to the Authorization header.
+type staticAccessTokenHeaderProvider struct{}
+
+// AddHeader implements httpclient.HTTPHeaderProvider.
+func (p *staticAccessTokenHeaderProvider) AddHeader(req *http.Request) error {
+	req.Header.Set("Authorization", "Bearer "+FakeAccessToken)
+	return nil
+}
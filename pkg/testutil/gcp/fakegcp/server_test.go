@@ -0,0 +1,325 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fakegcp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/common/khictx"
+	"github.com/GoogleCloudPlatform/khi/pkg/common/token"
+	inspection_task_contextkey "github.com/GoogleCloudPlatform/khi/pkg/inspection/contextkey"
+	"github.com/GoogleCloudPlatform/khi/pkg/inspection/credential"
+	"github.com/GoogleCloudPlatform/khi/pkg/log"
+	"github.com/GoogleCloudPlatform/khi/pkg/source/gcp/api"
+	"github.com/google/go-cmp/cmp"
+
+	_ "github.com/GoogleCloudPlatform/khi/internal/testflags"
+)
+
+func newTestClient(t *testing.T, server *Server) *api.GCPClientImpl {
+	t.Helper()
+	originalMinWait, originalMaxWait := api.MinWaitTimeOnRetriableError, api.MaxWaitTimeOnRetriableError
+	api.MinWaitTimeOnRetriableError, api.MaxWaitTimeOnRetriableError = 0, 0
+	t.Cleanup(func() {
+		api.MinWaitTimeOnRetriableError, api.MaxWaitTimeOnRetriableError = originalMinWait, originalMaxWait
+	})
+	client, err := server.NewClientFactory().NewClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client.(*api.GCPClientImpl)
+}
+
+func testLogEntry(insertID string, timestamp string, clusterName string) string {
+	return fmt.Sprintf(`{"insertId":"%s","timestamp":"%s","logName":"projects/test-project/logs/events","resource":{"type":"k8s_cluster","labels":{"project_id":"test-project","cluster_name":"%s"}}}`, insertID, timestamp, clusterName)
+}
+
+func listInsertIDs(t *testing.T, client *api.GCPClientImpl, resourceNames []string, filter string) ([]string, error) {
+	t.Helper()
+	logSink := make(chan *log.Log)
+	insertIDs := []string{}
+	done := make(chan struct{})
+	go func() {
+		for l := range logSink {
+			insertIDs = append(insertIDs, l.ReadStringOrDefault("insertId", ""))
+		}
+		close(done)
+	}()
+	err := client.ListLogEntries(context.Background(), resourceNames, filter, logSink)
+	<-done
+	return insertIDs, err
+}
+
+func TestListLogEntries(t *testing.T) {
+	testCases := []struct {
+		name          string
+		resourceNames []string
+		filter        string
+		want          []string
+		wantErr       bool
+	}{
+		{
+			name:          "without filter",
+			resourceNames: []string{"projects/test-project"},
+			want:          []string{"a", "b", "c"},
+		},
+		{
+			name:          "resource labels and timestamp range",
+			resourceNames: []string{"projects/test-project"},
+			filter:        `resource.labels.cluster_name="foo" AND timestamp>="2025-01-01T00:00:00Z" AND timestamp<"2025-01-01T02:00:00Z"`,
+			want:          []string{"a"},
+		},
+		{
+			name:          "logName",
+			resourceNames: []string{"projects/test-project"},
+			filter:        `logName="projects/test-project/logs/events"`,
+			want:          []string{"a", "b", "c"},
+		},
+		{
+			name:          "other project",
+			resourceNames: []string{"projects/other-project"},
+			want:          []string{},
+		},
+		{
+			name:          "invalid filter",
+			resourceNames: []string{"projects/test-project"},
+			filter:        `resource.labels.cluster_name=`,
+			want:          []string{},
+			wantErr:       true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := NewServer()
+			defer server.Close()
+			err := server.AddLogEntries(
+				testLogEntry("c", "2025-01-01T03:00:00Z", "foo"),
+				fmt.Sprintf("[%s,%s]", testLogEntry("a", "2025-01-01T01:00:00Z", "foo"), testLogEntry("b", "2025-01-01T01:30:00Z", "bar")),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := listInsertIDs(t, newTestClient(t, server), tc.resourceNames, tc.filter)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("ListLogEntries() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("ListLogEntries() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestListLogEntriesWithPaging(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+	logEntries := []string{}
+	want := []string{}
+	for i := 0; i < 2500; i++ {
+		insertID := fmt.Sprintf("entry-%04d", i)
+		logEntries = append(logEntries, testLogEntry(insertID, fmt.Sprintf("2025-01-01T00:00:%02d.%04dZ", i/1000, i%1000), "foo"))
+		want = append(want, insertID)
+	}
+	if err := server.AddLogEntries(logEntries...); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := listInsertIDs(t, newTestClient(t, server), []string{"projects/test-project"}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("ListLogEntries() mismatch (-want +got):\n%s", diff)
+	}
+	if len(server.Requests()) != 3 {
+		t.Errorf("got %d requests, want 3", len(server.Requests()))
+	}
+}
+
+func TestListResourcesWithPaging(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+	server.PageSize = 1
+	server.SetClusters("test-project", api.Cluster{Name: "foo"}, api.Cluster{Name: "bar"}, api.Cluster{Name: "baz"})
+	server.SetOnPremClusterNames("test-project", BareMetalClusters, "projects/test-project/locations/us-central1/bareMetalClusters/bm-user")
+	server.SetOnPremClusterNames("test-project", BareMetalAdminClusters, "projects/test-project/locations/us-central1/bareMetalAdminClusters/bm-admin")
+	server.SetAWSClusterNames("test-project", "us-east4", "projects/test-project/locations/us-east4/awsClusters/aws-cluster")
+	server.SetComposerEnvironmentNames("test-project", "us-central1", "projects/test-project/locations/us-central1/environments/env-1", "projects/test-project/locations/us-central1/environments/env-2")
+	client := newTestClient(t, server)
+
+	clusterNames, err := client.GetClusterNames(context.Background(), "test-project")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"foo", "bar", "baz"}, clusterNames); diff != "" {
+		t.Errorf("GetClusterNames() mismatch (-want +got):\n%s", diff)
+	}
+
+	baremetalClusterNames, err := client.GetAnthosOnBaremetalClusterNames(context.Background(), "test-project")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Clusters and admin clusters are listed concurrently.
+	slices.Sort(baremetalClusterNames)
+	if diff := cmp.Diff([]string{"bm-admin", "bm-user"}, baremetalClusterNames); diff != "" {
+		t.Errorf("GetAnthosOnBaremetalClusterNames() mismatch (-want +got):\n%s", diff)
+	}
+
+	awsClusterNames, err := client.GetAnthosAWSClusterNames(context.Background(), "test-project")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"aws-cluster"}, awsClusterNames); diff != "" {
+		t.Errorf("GetAnthosAWSClusterNames() mismatch (-want +got):\n%s", diff)
+	}
+
+	environmentNames, err := client.GetComposerEnvironmentNames(context.Background(), "test-project", "us-central1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"env-1", "env-2"}, environmentNames); diff != "" {
+		t.Errorf("GetComposerEnvironmentNames() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestInjectFault(t *testing.T) {
+	testCases := []struct {
+		name         string
+		statusCode   int
+		count        int
+		wantErr      bool
+		wantRequests int
+	}{
+		{name: "401 is retried", statusCode: http.StatusUnauthorized, count: 2, wantRequests: 3},
+		{name: "403 is retried", statusCode: http.StatusForbidden, count: 1, wantRequests: 2},
+		{name: "429 is retried", statusCode: http.StatusTooManyRequests, count: 3, wantRequests: 4},
+		{name: "500 is retried", statusCode: http.StatusInternalServerError, count: 1, wantRequests: 2},
+		{name: "400 is not retried", statusCode: http.StatusBadRequest, count: 1, wantErr: true, wantRequests: 1},
+		{name: "retry count exceeded", statusCode: http.StatusTooManyRequests, count: 100, wantErr: true, wantRequests: api.MaxRetryCount},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := NewServer()
+			defer server.Close()
+			server.SetClusters("test-project", api.Cluster{Name: "foo"})
+			server.InjectFault(Fault{PathContains: "/clusters", StatusCode: tc.statusCode, Count: tc.count})
+			client := newTestClient(t, server)
+
+			clusterNames, err := client.GetClusterNames(context.Background(), "test-project")
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("GetClusterNames() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if !tc.wantErr {
+				if diff := cmp.Diff([]string{"foo"}, clusterNames); diff != "" {
+					t.Errorf("GetClusterNames() mismatch (-want +got):\n%s", diff)
+				}
+			}
+			requests := server.Requests()
+			if len(requests) != tc.wantRequests {
+				t.Errorf("got %d requests, want %d", len(requests), tc.wantRequests)
+			}
+			for _, request := range requests {
+				if request.Host != "container.googleapis.com" {
+					t.Errorf("request was sent to %q, want container.googleapis.com", request.Host)
+				}
+			}
+		})
+	}
+}
+
+func TestClientWithEndpoints(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+	server.SetClusters("test-project", api.Cluster{Name: "foo"})
+	server.SetAzureClusterNames("test-project", "us-west1", "projects/test-project/locations/us-west1/azureClusters/azure-cluster")
+	factory := server.NewClientFactory()
+	factory.Transport = nil
+	factory.Endpoints = server.Endpoints()
+	factory.Endpoints.GKEMultiCloudLocations = []string{"us-west1"}
+	client, err := factory.NewClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	clusterNames, err := client.GetClusterNames(context.Background(), "test-project")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"foo"}, clusterNames); diff != "" {
+		t.Errorf("GetClusterNames() mismatch (-want +got):\n%s", diff)
+	}
+	azureClusterNames, err := client.GetAnthosAzureClusterNames(context.Background(), "test-project")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"azure-cluster"}, azureClusterNames); diff != "" {
+		t.Errorf("GetAnthosAzureClusterNames() mismatch (-want +got):\n%s", diff)
+	}
+	if len(server.Requests()) != 2 {
+		t.Errorf("got %d requests, want 2", len(server.Requests()))
+	}
+}
+
+func TestRequestWithoutAccessToken(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+	response, err := http.Post(server.URL()+logEntriesListPath, "application/json", strings.NewReader(`{}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusUnauthorized {
+		t.Errorf("got status %d, want %d", response.StatusCode, http.StatusUnauthorized)
+	}
+}
+
+func TestImpersonationWithEndpoints(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+	server.SetClusters("test-project", api.Cluster{Name: "foo"})
+	factory := server.NewClientFactory()
+	factory.Transport = nil
+	factory.Endpoints = server.Endpoints()
+	factory.Endpoints.ImpersonationScope = "https://www.example.com/auth/cloud-platform"
+	factory.AccessTokenStore = token.NewBasicTokenStore("test", token.NewSpyTokenResolver())
+	ctx := khictx.WithValue(context.Background(), inspection_task_contextkey.InspectionCredentialCache, credential.NewCache())
+	ctx = khictx.WithValue(ctx, inspection_task_contextkey.InspectionCredential, &credential.Credential{
+		AccessToken:               "user-token",
+		ImpersonateServiceAccount: "khi@test-project.iam.gserviceaccount.com",
+	})
+	client, err := factory.NewClientFromContext(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := client.GetClusterNames(ctx, "test-project"); err != nil {
+		t.Fatal(err)
+	}
+	requests := server.Requests()
+	if len(requests) != 2 {
+		t.Fatalf("got %d requests, want 2", len(requests))
+	}
+	if want := "/v1/projects/-/serviceAccounts/khi@test-project.iam.gserviceaccount.com:generateAccessToken"; requests[0].Path != want {
+		t.Errorf("the first request path = %q, want %q", requests[0].Path, want)
+	}
+	if !strings.Contains(requests[0].Body, "https://www.example.com/auth/cloud-platform") {
+		t.Errorf("the impersonation request doesn't contain the scope: %s", requests[0].Body)
+	}
+}
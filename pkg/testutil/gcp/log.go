@@ -21,14 +21,22 @@ import (
 	"testing"
 
 	"github.com/GoogleCloudPlatform/khi/internal/testflags"
+	"github.com/GoogleCloudPlatform/khi/pkg/common/structurev2"
 	"github.com/GoogleCloudPlatform/khi/pkg/log"
 	"github.com/GoogleCloudPlatform/khi/pkg/parameters"
 	"github.com/GoogleCloudPlatform/khi/pkg/source/gcp/api"
+	"github.com/GoogleCloudPlatform/khi/pkg/source/gcp/logfilter"
+	"github.com/GoogleCloudPlatform/khi/pkg/testutil"
 )
 
+// IsValidLogQuery returns an error when the query can't be parsed locally or is rejected by Cloud Logging.
+// The query is sent to Cloud Logging only when the tests using Cloud Logging are not skipped.
 func IsValidLogQuery(t *testing.T, query string) error {
 	t.Helper()
 
+	if _, err := logfilter.Parse(query); err != nil {
+		return fmt.Errorf("failed to parse the query locally\n%w", err)
+	}
 	if *testflags.SkipCloudLogging {
 		t.Skip("cloud logging tests are skipped")
 	}
@@ -46,3 +54,19 @@ timestamp <= "2024-01-01T00:00:01Z"`, query)
 
 	return gcpApi.ListLogEntries(context.Background(), []string{"projects/kubernetes-history-inspector"}, query, make(chan *log.Log))
 }
+
+// MatchLogQueryWithYamlLogFile returns true when the log in the given YAML file matches the query evaluated locally.
+func MatchLogQueryWithYamlLogFile(t *testing.T, query string, testFile string) bool {
+	t.Helper()
+
+	filter, err := logfilter.Parse(query)
+	if err != nil {
+		t.Fatalf("failed to parse the query locally\n%s", err)
+	}
+	testutil.InitTestIO()
+	node, err := structurev2.FromYAML(testutil.MustReadText(testFile))
+	if err != nil {
+		t.Fatalf("failed to read the log in %s\n%s", testFile, err)
+	}
+	return filter.Match(structurev2.NewNodeReader(node))
+}
@@ -0,0 +1,44 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package khifile
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+
+	"github.com/GoogleCloudPlatform/khi/pkg/inspection/metadata/progress"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/binarychunk"
+	"github.com/GoogleCloudPlatform/khi/pkg/model/history"
+)
+
+// MustGenerateKHIFile returns the .khi file data containing the given History and binary chunks in the same layout as history.Builder.Finalize.
+func MustGenerateKHIFile(h *history.History, chunk *binarychunk.Builder) []byte {
+	jsonBytes, err := json.Marshal(h)
+	if err != nil {
+		panic(err)
+	}
+	var buf bytes.Buffer
+	buf.WriteString("KHI")
+	sizeBytes := make([]byte, 4)
+	binary.LittleEndian.PutUint32(sizeBytes, uint32(len(jsonBytes)))
+	buf.Write(sizeBytes)
+	buf.Write(jsonBytes)
+	if _, err := chunk.Build(context.Background(), &buf, progress.NewTaskProgress("test")); err != nil {
+		panic(err)
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,117 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package tracing provides the OpenTelemetry tracer used to trace inspections.
+// Each inspection is the root span of a trace and tasks run in the inspection are its child spans.
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/GoogleCloudPlatform/khi"
+
+const serviceName = "khi"
+
+// Start starts a span with the tracer of KHI from the global tracer provider. Spans are not exported until Setup is called.
+func Start(ctx context.Context, spanName string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, spanName, opts...)
+}
+
+// Config is the set of destinations to export spans.
+type Config struct {
+	// OTLPEndpoint is the URL of the OTLP/HTTP endpoint receiving spans. Spans are not sent with OTLP when it's empty.
+	OTLPEndpoint string
+	// FilePath is the path of the file to append spans in JSON. Spans are not written to a file when it's empty.
+	FilePath string
+}
+
+// Enabled returns true when any destination is configured.
+func (c *Config) Enabled() bool {
+	return c.OTLPEndpoint != "" || c.FilePath != ""
+}
+
+// Setup registers the global tracer provider exporting spans to the destinations in the config.
+// The returned function flushes the remaining spans and must be called before KHI exits.
+func Setup(ctx context.Context, config *Config) (func(context.Context) error, error) {
+	if !config.Enabled() {
+		return func(ctx context.Context) error { return nil }, nil
+	}
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(serviceName)))
+	if err != nil {
+		return nil, err
+	}
+	options := []sdktrace.TracerProviderOption{sdktrace.WithResource(res)}
+	closers := []io.Closer{}
+	if config.OTLPEndpoint != "" {
+		exporter, err := otlptracehttp.New(ctx, otlptracehttp.WithEndpointURL(config.OTLPEndpoint))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create the OTLP exporter\n%w", err)
+		}
+		options = append(options, sdktrace.WithBatcher(exporter))
+	}
+	if config.FilePath != "" {
+		file, err := os.OpenFile(config.FilePath, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open the trace file %s\n%w", config.FilePath, err)
+		}
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(file))
+		if err != nil {
+			file.Close()
+			return nil, fmt.Errorf("failed to create the file exporter\n%w", err)
+		}
+		options = append(options, sdktrace.WithBatcher(exporter))
+		closers = append(closers, file)
+	}
+	provider := sdktrace.NewTracerProvider(options...)
+	otel.SetTracerProvider(provider)
+	return func(ctx context.Context) error {
+		errs := []error{provider.Shutdown(ctx)}
+		for _, closer := range closers {
+			errs = append(errs, closer.Close())
+		}
+		return errors.Join(errs...)
+	}, nil
+}
+
+// EndSpan ends the span after recording the error when it's not nil.
+func EndSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// TraceID returns the trace ID of the span in the context. It returns an empty string when the span is not recorded.
+func TraceID(ctx context.Context) string {
+	spanContext := trace.SpanContextFromContext(ctx)
+	if !spanContext.HasTraceID() {
+		return ""
+	}
+	return spanContext.TraceID().String()
+}
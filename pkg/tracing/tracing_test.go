@@ -0,0 +1,102 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracing
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	_ "github.com/GoogleCloudPlatform/khi/internal/testflags"
+)
+
+func TestSetupWritesSpansToFile(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "trace.jsonl")
+	shutdown, err := Setup(context.Background(), &Config{FilePath: filePath})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, span := Start(context.Background(), "test-span")
+	span.End()
+	if err := shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"Name":"test-span"`) {
+		t.Errorf("the trace file doesn't contain the span\n%s", string(data))
+	}
+}
+
+func TestEndSpan(t *testing.T) {
+	testCases := []struct {
+		name       string
+		err        error
+		wantStatus codes.Code
+		wantEvents int
+	}{
+		{
+			name:       "without error",
+			wantStatus: codes.Unset,
+		},
+		{
+			name:       "with error",
+			err:        errors.New("foo"),
+			wantStatus: codes.Error,
+			wantEvents: 1,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := tracetest.NewSpanRecorder()
+			provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+			_, span := provider.Tracer("test").Start(context.Background(), "test-span")
+			EndSpan(span, tc.err)
+
+			spans := recorder.Ended()
+			if len(spans) != 1 {
+				t.Fatalf("got %d ended spans, want 1", len(spans))
+			}
+			if diff := cmp.Diff(tc.wantStatus, spans[0].Status().Code); diff != "" {
+				t.Errorf("status mismatch (-want +got)\n%s", diff)
+			}
+			if len(spans[0].Events()) != tc.wantEvents {
+				t.Errorf("got %d events, want %d", len(spans[0].Events()), tc.wantEvents)
+			}
+		})
+	}
+}
+
+func TestTraceID(t *testing.T) {
+	if got := TraceID(context.Background()); got != "" {
+		t.Errorf("TraceID() without span = %q, want empty", got)
+	}
+	provider := sdktrace.NewTracerProvider()
+	ctx, span := provider.Tracer("test").Start(context.Background(), "test-span")
+	defer span.End()
+	if got, want := TraceID(ctx), span.SpanContext().TraceID().String(); got != want {
+		t.Errorf("TraceID() = %q, want %q", got, want)
+	}
+}
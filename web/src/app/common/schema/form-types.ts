@@ -137,6 +137,11 @@ export interface FileParameterFormField extends ParameterFormFieldBase {
    * The status of file reported from the server side.
    */
   status: UploadStatus;
+
+  /**
+   * True when several files can be uploaded at once for the token.
+   */
+  multiple: boolean;
 }
 
 export type ParameterFormField =
@@ -30,6 +30,10 @@ export type InspectionMetadataQuery = {
   id: string;
   name: string;
   query: string;
+  /**
+   * The estimated count of logs returned from the query. It's set only in the dry run requested to estimate the log volume.
+   */
+  estimatedLogCount?: number;
 };
 
 export type InspectionMetadataErrorSet = {
@@ -50,6 +54,7 @@ export type InspectionMetadataHeader = {
   endTimeUnixSeconds: number;
   suggestedFilename: string;
   fileSize?: number;
+  traceId?: string;
 };
 
 export type InspectionMetadataProgressPhase =
@@ -51,6 +51,7 @@ describe('FileParameterComponent', () => {
     id: 'test-id',
     token: fakeUploadToken,
     status: UploadStatus.Waiting,
+    multiple: false,
   } as FileParameterFormField;
 
   let fixture: ComponentFixture<FileParameterComponent>;
@@ -107,6 +108,36 @@ describe('FileParameterComponent', () => {
     expect(uploadButton.attributes['disabled']).toBeFalsy();
   });
 
+  it('keeps only the first file when the field accepts a file', () => {
+    fixture.componentInstance.processReceivedFileInfo([
+      new File([], 'audit.log'),
+      new File([], 'audit.log.1.gz'),
+    ]);
+    fixture.detectChanges();
+
+    expect(fixture.componentInstance.filename()).toBe('audit.log');
+    expect(fixture.componentInstance.selectedFiles.length).toBe(1);
+  });
+
+  it('selects every file when the field accepts multiple files', () => {
+    fixture.componentRef.setInput('parameter', {
+      ...defaultFileParameterForm,
+      multiple: true,
+    });
+    fixture.componentInstance.processReceivedFileInfo([
+      new File([], 'audit.log'),
+      new File([], 'audit.log.1.gz'),
+    ]);
+    fixture.detectChanges();
+
+    expect(fixture.componentInstance.filename()).toBe(
+      'audit.log, audit.log.1.gz',
+    );
+    expect(fixture.componentInstance.selectedFiles.length).toBe(2);
+    const fileInput = fixture.debugElement.query(By.css('input[type=file]'));
+    expect(fileInput.nativeElement.multiple).toBeTrue();
+  });
+
   it('shows progress bar with upload status', async () => {
     mockFileUploader.statusProvider = () =>
       of({
@@ -119,7 +150,7 @@ describe('FileParameterComponent', () => {
       ...defaultFileParameterForm,
       status: UploadStatus.Uploading,
     });
-    fixture.componentInstance.selectedFile = new File([], 'a mock file');
+    fixture.componentInstance.selectedFiles = [new File([], 'a mock file')];
     fixture.componentInstance.onClickUploadButton();
     fixture.detectChanges();
     const harnessLoader = TestbedHarnessEnvironment.loader(fixture);
@@ -142,7 +173,7 @@ describe('FileParameterComponent', () => {
       ...defaultFileParameterForm,
       status: UploadStatus.Verifying,
     });
-    fixture.componentInstance.selectedFile = new File([], 'a mock file');
+    fixture.componentInstance.selectedFiles = [new File([], 'a mock file')];
     fixture.componentInstance.onClickUploadButton();
     fixture.detectChanges();
     const harnessLoader = TestbedHarnessEnvironment.loader(fixture);
@@ -177,7 +208,7 @@ describe('FileParameterComponent', () => {
       ...defaultFileParameterForm,
       status: UploadStatus.Verifying,
     });
-    fixture.componentInstance.selectedFile = new File([], 'a mock file');
+    fixture.componentInstance.selectedFiles = [new File([], 'a mock file')];
     fixture.componentInstance.isSelectedFileUploaded.set(false);
     fixture.detectChanges();
     const uploadButton = fixture.debugElement.query(By.css('.upload-button'));
@@ -100,7 +100,10 @@ export class FileParameterComponent implements OnDestroy {
   @ViewChild('fileInput')
   fileInput!: ElementRef<HTMLInputElement>;
 
-  selectedFile: File | null = null;
+  /**
+   * The files selected to be uploaded. It contains several files only when the field accepts multiple files.
+   */
+  selectedFiles: File[] = [];
 
   private formStoreRefreshCancel = new Subject();
 
@@ -162,12 +165,12 @@ export class FileParameterComponent implements OnDestroy {
    * Eventhandler for the upload button.
    */
   onClickUploadButton() {
-    if (this.selectedFile === null) {
+    if (this.selectedFiles.length === 0) {
       return;
     }
     this.isSelectedFileUploading.set(true);
     this.uploader
-      .upload(this.parameter().token, this.selectedFile)
+      .upload(this.parameter().token, this.selectedFiles)
       .subscribe((status) => {
         if (status.completeRatioUnknown) {
           this.uploadRatio.set(undefined);
@@ -185,13 +188,16 @@ export class FileParameterComponent implements OnDestroy {
   }
 
   processReceivedFileInfo(files: File[]) {
-    if (files.length > 1) {
+    if (files.length === 0) {
+      return;
+    }
+    if (files.length > 1 && !this.parameter().multiple) {
       this.snackBar.open('2 or more files are specified at once.');
+      files = files.slice(0, 1);
     }
-    const file = files[0];
-    this.filename.set(file.name);
+    this.filename.set(files.map((file) => file.name).join(', '));
     this.isSelectedFileUploaded.set(false);
-    this.selectedFile = file;
+    this.selectedFiles = files;
   }
 
   /**
@@ -42,9 +42,12 @@ export const FILE_UPLOADER = new InjectionToken<FileUploader>('FILE_UPLOADER');
  */
 export interface FileUploader {
   /**
-   * Upload a file tied with the UploadToken.
+   * Upload files tied with the UploadToken.
    */
-  upload(token: UploadToken, file: File): Observable<FileUploaderStatus>;
+  upload(
+    token: UploadToken,
+    files: File[],
+  ): Observable<FileUploaderStatus>;
 }
 
 /**
@@ -72,8 +75,8 @@ export class MockFileUploader implements FileUploader {
 export class KHIServerFileUploader implements FileUploader {
   private readonly backendAPI: BackendAPI = inject(BACKEND_API);
 
-  upload(token: UploadToken, file: File): Observable<FileUploaderStatus> {
-    return this.backendAPI.uploadFile(token, file).pipe(
+  upload(token: UploadToken, files: File[]): Observable<FileUploaderStatus> {
+    return this.backendAPI.uploadFile(token, files).pipe(
       filter(
         (status) =>
           status.type !== HttpEventType.User &&
@@ -171,7 +171,7 @@ export interface BackendAPI {
   answerPopup(answer: PopupAnswerResponse): Observable<void>;
 
   /**
-   * Upload the file as the one bound to the token.
+   * Upload the files as the ones bound to the token.
    */
-  uploadFile(token: UploadToken, file: File): Observable<HttpEvent<unknown>>;
+  uploadFile(token: UploadToken, files: File[]): Observable<HttpEvent<unknown>>;
 }
@@ -259,12 +259,14 @@ export class BackendAPIImpl implements BackendAPI {
 
   public uploadFile(
     token: UploadToken,
-    file: File,
+    files: File[],
   ): Observable<HttpEvent<unknown>> {
     const url = this.baseUrl + `/upload`;
     const formData = new FormData();
     formData.append('upload-token-id', token.id);
-    formData.append('file', file, file.name);
+    for (const file of files) {
+      formData.append('file', file, file.name);
+    }
     return this.http.post(url, formData, {
       reportProgress: true,
       observe: 'events',